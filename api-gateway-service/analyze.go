@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"api-gateway-service/placement"
+	"api-gateway-service/store"
+)
+
+// Analysis types accepted by the analyze endpoint; each is also the type of
+// the recommendations it produces
+const (
+	analysisCost        = "cost"
+	analysisPerformance = "performance"
+	analysisCompliance  = "compliance"
+)
+
+// analysisTypes are run in this order when a request names none
+var analysisTypes = []string{analysisCost, analysisPerformance, analysisCompliance}
+
+// AnalyzeRequest is the body accepted by the analyze endpoint. Without
+// analysis types every analysis is run. Alternatives in the excluded
+// providers or regions, or not supporting every compliance framework, are
+// not recommended.
+type AnalyzeRequest struct {
+	ResourceIDs          []string `json:"resource_ids" binding:"required,min=1"`
+	AnalysisTypes        []string `json:"analysis_types" binding:"omitempty,dive,oneof=cost performance compliance"`
+	ExcludedProviders    []string `json:"excluded_providers"`
+	ExcludedRegions      []string `json:"excluded_regions"`
+	ComplianceFrameworks []string `json:"compliance_frameworks"`
+}
+
+// analyzeResources evaluates each inventory resource's deployment against its
+// alternatives and saves a recommendation for every requested analysis that
+// finds a better one: the cheapest cheaper alternative for cost, the best
+// performing faster one for performance, and the best-ranked alternative
+// meeting the compliance frameworks the current region does not. Analyzing a
+// resource again replaces its earlier recommendations of the same type.
+func analyzeResources(c *gin.Context) {
+	var req AnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	types := req.AnalysisTypes
+	if len(types) == 0 {
+		types = analysisTypes
+	}
+	opts := placement.EvaluateOptions{
+		ExcludedProviders:    req.ExcludedProviders,
+		ExcludedRegions:      req.ExcludedRegions,
+		ComplianceFrameworks: req.ComplianceFrameworks,
+	}
+
+	ctx := c.Request.Context()
+	engine := tenantEngine(ctx)
+	recs := make([]*store.Recommendation, 0)
+	for _, id := range req.ResourceIDs {
+		r, err := resourceStore.Get(ctx, id)
+		if err != nil {
+			respondStoreError(c, err, "resource not found: "+id)
+			return
+		}
+
+		eval, err := engine.EvaluateWith(r.Provider, r.Region, r.InstanceType, opts)
+		if err != nil {
+			respondError(c, http.StatusUnprocessableEntity, "resource "+id+": "+err.Error())
+			return
+		}
+		recs = append(recs, analyzeEvaluation(r, eval, types, len(req.ComplianceFrameworks) > 0)...)
+	}
+
+	for _, rec := range recs {
+		if err := recommendationStore.Save(ctx, rec); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, recs)
+}
+
+// analyzeEvaluation returns the recommendations the requested analyses make
+// for the resource's evaluation. The compliance analysis runs only when
+// frameworks were required.
+func analyzeEvaluation(r *store.Resource, eval *placement.Evaluation, types []string, frameworksRequired bool) []*store.Recommendation {
+	current := eval.Current
+	var recs []*store.Recommendation
+	for _, t := range types {
+		var target *placement.Option
+		switch t {
+		case analysisCost:
+			for i, o := range eval.Alternatives {
+				if o.MonthlyCost < current.MonthlyCost && (target == nil || o.MonthlyCost < target.MonthlyCost) {
+					target = &eval.Alternatives[i]
+				}
+			}
+		case analysisPerformance:
+			for i, o := range eval.Alternatives {
+				if o.PerformanceScore > current.PerformanceScore && (target == nil || o.PerformanceScore > target.PerformanceScore) {
+					target = &eval.Alternatives[i]
+				}
+			}
+		case analysisCompliance:
+			// Alternatives are ranked and all meet the frameworks
+			if frameworksRequired && current.ComplianceScore < 1 && len(eval.Alternatives) > 0 {
+				target = &eval.Alternatives[0]
+			}
+		}
+		if target != nil {
+			recs = append(recs, newAnalysisRecommendation(r, t, current, *target))
+		}
+	}
+	return recs
+}
+
+// newAnalysisRecommendation recommends moving the resource from its current
+// deployment to target: a resize within its provider and region, otherwise a
+// migration. The estimated savings are the monthly cost difference, negative
+// when target costs more.
+func newAnalysisRecommendation(r *store.Resource, analysisType string, current, target placement.Option) *store.Recommendation {
+	savings := current.MonthlyCost - target.MonthlyCost
+	rec := &store.Recommendation{
+		ID:               analysisRecommendationID(r.ID, analysisType),
+		Type:             analysisType,
+		Action:           store.ActionResize,
+		Priority:         "medium",
+		ResourceID:       r.ID,
+		EstimatedSavings: savings,
+		Details: map[string]interface{}{
+			"target_instance_type": target.InstanceType,
+			"current_monthly_cost": current.MonthlyCost,
+			"target_monthly_cost":  target.MonthlyCost,
+		},
+	}
+	if target.Provider != current.Provider || target.Region != current.Region {
+		rec.Action = store.ActionMigrate
+		rec.Details[store.DetailTargetProvider] = target.Provider
+		rec.Details[store.DetailTargetRegion] = target.Region
+	}
+
+	switch analysisType {
+	case analysisCost:
+		rec.Description = fmt.Sprintf("Move %s to %s %s (%s) to save %.2f per month", r.ID, target.Provider, target.Region, target.InstanceType, savings)
+		rec.Priority = savingsPriority(savings, current.MonthlyCost)
+	case analysisPerformance:
+		rec.Description = fmt.Sprintf("Move %s to %s %s (%s) to raise its performance score from %.2f to %.2f", r.ID, target.Provider, target.Region, target.InstanceType, current.PerformanceScore, target.PerformanceScore)
+	case analysisCompliance:
+		rec.Description = fmt.Sprintf("Move %s to %s %s (%s), which supports every required compliance framework", r.ID, target.Provider, target.Region, target.InstanceType)
+		rec.Priority = "high"
+	}
+	return rec
+}
+
+// savingsPriority is high for savings of at least 30% of the current monthly
+// cost, medium for at least 10% and low otherwise
+func savingsPriority(savings, monthlyCost float64) string {
+	switch share := savings / monthlyCost; {
+	case monthlyCost <= 0:
+		return "low"
+	case share >= 0.3:
+		return "high"
+	case share >= 0.1:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// analysisRecommendationID is the same for every analysis of a type for one
+// resource, so analyzing it again replaces its recommendation
+func analysisRecommendationID(resourceID, analysisType string) string {
+	sum := sha256.Sum256([]byte(analysisType + "\x00" + resourceID))
+	return "rec-" + hex.EncodeToString(sum[:8])
+}
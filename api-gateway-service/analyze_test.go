@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"api-gateway-service/store"
+)
+
+// useInventory replaces the resource and recommendation stores for the test,
+// with the resources saved for the default tenant
+func useInventory(t *testing.T, resources ...*store.Resource) {
+	t.Helper()
+	prevResources, prevRecs := resourceStore, recommendationStore
+	resourceStore, recommendationStore = store.NewResourceStore(), store.NewRecommendationStore()
+	t.Cleanup(func() { resourceStore, recommendationStore = prevResources, prevRecs })
+
+	for _, r := range resources {
+		if err := resourceStore.Save(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// postAnalyze posts the body to the analyze endpoint
+func postAnalyze(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/optimize/analyze", analyzeResources)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/optimize/analyze", bytes.NewBufferString(body)))
+	return w
+}
+
+func TestAnalyzeResources(t *testing.T) {
+	vm := &store.Resource{ID: "i-eu", Type: "compute", Provider: "aws", Region: "eu-west-1", InstanceType: "m5.xlarge"}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       map[string]string // recommendation type to target provider/region
+	}{
+		{
+			name:       "cost and performance",
+			body:       `{"resource_ids":["i-eu"]}`,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{analysisCost: "azure/eastus", analysisPerformance: "azure/eastus"},
+		},
+		{
+			name:       "one type",
+			body:       `{"resource_ids":["i-eu"],"analysis_types":["cost"]}`,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{analysisCost: "azure/eastus"},
+		},
+		{
+			name:       "excluded provider",
+			body:       `{"resource_ids":["i-eu"],"analysis_types":["cost"],"excluded_providers":["azure"]}`,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{analysisCost: "aws/us-east-1"},
+		},
+		{
+			name:       "compliance frameworks the region lacks",
+			body:       `{"resource_ids":["i-eu"],"analysis_types":["compliance"],"compliance_frameworks":["FedRAMP"]}`,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{analysisCompliance: "azure/eastus"},
+		},
+		{
+			name:       "compliance frameworks the region has",
+			body:       `{"resource_ids":["i-eu"],"analysis_types":["compliance"],"compliance_frameworks":["GDPR"]}`,
+			wantStatus: http.StatusOK,
+			want:       map[string]string{},
+		},
+		{name: "unknown resource", body: `{"resource_ids":["i-missing"]}`, wantStatus: http.StatusNotFound},
+		{name: "unknown analysis type", body: `{"resource_ids":["i-eu"],"analysis_types":["security"]}`, wantStatus: http.StatusBadRequest},
+		{name: "no resources", body: `{"resource_ids":[]}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useInventory(t, vm)
+			w := postAnalyze(t, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var recs []*store.Recommendation
+			if err := json.Unmarshal(w.Body.Bytes(), &recs); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string, len(recs))
+			for _, rec := range recs {
+				provider, region := rec.Target()
+				got[rec.Type] = provider + "/" + region
+				if rec.ResourceID != vm.ID || rec.Action != store.ActionMigrate {
+					t.Errorf("%s recommendation %+v, want a migration of %s", rec.Type, rec, vm.ID)
+				}
+				if rec.Type == analysisCost && rec.EstimatedSavings <= 0 {
+					t.Errorf("cost recommendation saves %v", rec.EstimatedSavings)
+				}
+				if _, err := recommendationStore.Get(context.Background(), rec.ID); err != nil {
+					t.Errorf("%s recommendation not saved: %v", rec.Type, err)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("recommendations %v, want %v", got, tt.want)
+			}
+			for typ, target := range tt.want {
+				if got[typ] != target {
+					t.Errorf("%s recommendation moves to %q, want %s", typ, got[typ], target)
+				}
+			}
+		})
+	}
+}
+
+// Analyzing a resource again replaces its recommendations rather than adding
+// more
+func TestAnalyzeResourcesReplacesRecommendations(t *testing.T) {
+	useInventory(t, &store.Resource{ID: "i-eu", Provider: "aws", Region: "eu-west-1", InstanceType: "m5.xlarge"})
+
+	for i := 0; i < 2; i++ {
+		if w := postAnalyze(t, `{"resource_ids":["i-eu"]}`); w.Code != http.StatusOK {
+			t.Fatalf("analysis %d: status %d: %s", i+1, w.Code, w.Body)
+		}
+	}
+	if recs := recommendationStore.List(context.Background()); len(recs) != 2 {
+		t.Errorf("%d recommendations after analyzing twice, want 2", len(recs))
+	}
+}
+
+func TestSavingsPriority(t *testing.T) {
+	tests := []struct {
+		savings, cost float64
+		want          string
+	}{
+		{savings: 40, cost: 100, want: "high"},
+		{savings: 10, cost: 100, want: "medium"},
+		{savings: 9, cost: 100, want: "low"},
+		{savings: 5, cost: 0, want: "low"},
+	}
+	for _, tt := range tests {
+		if got := savingsPriority(tt.savings, tt.cost); got != tt.want {
+			t.Errorf("savingsPriority(%v, %v) = %s, want %s", tt.savings, tt.cost, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"api-gateway-service/audit"
+	"api-gateway-service/tenant"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+	// auditStreamInterval is how often an audit stream checks for new entries
+	auditStreamInterval = time.Second
+)
+
+// AuditPage is one page of audit entries, newest first. NextPageToken is set
+// when older entries remain.
+type AuditPage struct {
+	Entries       []audit.Entry `json:"entries"`
+	NextPageToken string        `json:"next_page_token,omitempty"`
+}
+
+// listAuditEntries pages through the caller's tenant audit entries, newest
+// first, optionally filtered by user and a since timestamp
+func listAuditEntries(c *gin.Context) {
+	q := audit.Query{UserID: c.Query("user"), Limit: defaultAuditPageSize}
+
+	if v := c.Query("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid since: %s (use RFC 3339)", v))
+			return
+		}
+		q.Since = since
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxAuditPageSize {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid limit: %s (must be 1-%d)", v, maxAuditPageSize))
+			return
+		}
+		q.Limit = limit
+	}
+	if v := c.Query("page_token"); v != "" {
+		before, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || before == 0 {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid page_token: %s", v))
+			return
+		}
+		q.Before = before
+	}
+
+	entries, more := auditLog.List(tenant.FromContext(c.Request.Context()), q)
+	page := AuditPage{Entries: entries}
+	if more {
+		page.NextPageToken = strconv.FormatUint(entries[len(entries)-1].Seq, 10)
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// streamAuditEntries streams the caller's tenant audit entries as server-sent
+// "entry" events as they are recorded, optionally filtered by user. Entries
+// after the seq in the after parameter are sent first, so a client that
+// reconnects with the last seq it saw misses nothing; without it only new
+// entries are sent. Each event extends the write deadline, and a keepalive
+// comment is sent while no entries are recorded.
+func streamAuditEntries(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenantID := tenant.FromContext(ctx)
+	userID := c.Query("user")
+
+	after := auditLog.Seq()
+	if v := c.Query("after"); v != "" {
+		seq, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid after: %s", v))
+			return
+		}
+		after = seq
+	}
+
+	stream := startSSE(c)
+	if err := stream.flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(auditStreamInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, e := range auditLog.After(tenantID, after, userID) {
+			stream.event("entry", e)
+			after = e.Seq
+		}
+		if err := stream.flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
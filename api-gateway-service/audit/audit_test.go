@@ -0,0 +1,137 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-service/redact"
+)
+
+const secretRequest = `{"name":"ci","api_key":"k-123","credentials":{"password":"hunter2"}}`
+
+func testEntry() Entry {
+	return Entry{
+		Time:     time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		TenantID: "acme",
+		Method:   "POST",
+		Path:     "/api/v1/keys",
+		Status:   201,
+		Request:  json.RawMessage(secretRequest),
+	}
+}
+
+// assertRedacted fails when the recorded request holds a secret or lost its
+// other fields
+func assertRedacted(t *testing.T, recorded string) {
+	t.Helper()
+	for _, secret := range []string{"k-123", "hunter2"} {
+		if strings.Contains(recorded, secret) {
+			t.Errorf("recorded %s contains %q", recorded, secret)
+		}
+	}
+	if !strings.Contains(recorded, `"name":"ci"`) {
+		t.Errorf("recorded %s, want the other fields kept", recorded)
+	}
+}
+
+func TestFileSinkRedacts(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	sink, err := NewFileSink(path, redactor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Record(testEntry()); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRedacted(t, string(data))
+}
+
+func TestLogRedacts(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLog(10, redactor)
+	if err := l.Record(testEntry()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := l.List("acme", Query{})
+	if len(entries) != 1 {
+		t.Fatalf("%d entries, want 1", len(entries))
+	}
+	assertRedacted(t, string(entries[0].Request))
+}
+
+// List pages newest first by user and time, and After resumes a stream
+func TestLogListAndAfter(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLog(4, redactor)
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range []Entry{
+		{TenantID: "acme", UserID: "alice"},
+		{TenantID: "acme", UserID: "bob"},
+		{TenantID: "other", UserID: "alice"},
+		{TenantID: "acme", UserID: "alice"},
+		{TenantID: "acme", UserID: "alice"},
+		{TenantID: "acme", UserID: "alice"},
+	} {
+		e.Time = start.Add(time.Duration(i) * time.Hour)
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	seqs := func(entries []Entry) []uint64 {
+		got := make([]uint64, len(entries))
+		for i, e := range entries {
+			got[i] = e.Seq
+		}
+		return got
+	}
+	// Seq 1 was dropped beyond acme's capacity of 4
+	tests := []struct {
+		name     string
+		q        Query
+		want     []uint64
+		wantMore bool
+	}{
+		{name: "all", want: []uint64{6, 5, 4, 2}},
+		{name: "user", q: Query{UserID: "alice", Limit: 2}, want: []uint64{6, 5}, wantMore: true},
+		{name: "next page", q: Query{UserID: "alice", Limit: 2, Before: 5}, want: []uint64{4}},
+		{name: "since", q: Query{Since: start.Add(4 * time.Hour)}, want: []uint64{6, 5}},
+	}
+	for _, tt := range tests {
+		entries, more := l.List("acme", tt.q)
+		if got := seqs(entries); !reflect.DeepEqual(got, tt.want) || more != tt.wantMore {
+			t.Errorf("%s: List() = %v, %v, want %v, %v", tt.name, got, more, tt.want, tt.wantMore)
+		}
+	}
+
+	if got := seqs(l.After("acme", 4, "alice")); !reflect.DeepEqual(got, []uint64{5, 6}) {
+		t.Errorf("After(4, alice) = %v, want [5 6]", got)
+	}
+	if got := l.After("acme", l.Seq(), ""); len(got) != 0 {
+		t.Errorf("After(latest) = %v, want none", seqs(got))
+	}
+}
@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"api-gateway-service/redact"
+)
+
+// Query selects entries from a Log
+type Query struct {
+	// UserID, if set, keeps only the entries of that user
+	UserID string
+	// Since, if set, keeps only the entries recorded at or after it
+	Since time.Time
+	// Before, if set, keeps only the entries with a lower Seq; it is the
+	// cursor of the next page
+	Before uint64
+	// Limit caps the number of entries returned
+	Limit int
+}
+
+// Log keeps the most recent entries of each tenant in memory so they can be
+// listed and followed through the API. Like FileSink it redacts request
+// bodies before storing them.
+type Log struct {
+	mu       sync.RWMutex
+	capacity int
+	redactor *redact.Redactor
+	// seq is the Seq of the last recorded entry across all tenants
+	seq uint64
+	// entries maps tenant ID to that tenant's entries, oldest first
+	entries map[string][]Entry
+}
+
+// NewLog creates a log keeping at most capacity entries per tenant
+func NewLog(capacity int, redactor *redact.Redactor) *Log {
+	return &Log{
+		capacity: capacity,
+		redactor: redactor,
+		entries:  make(map[string][]Entry),
+	}
+}
+
+// Record numbers the entry, redacts its request body and appends it to its
+// tenant's entries, dropping the oldest beyond the capacity
+func (l *Log) Record(e Entry) error {
+	if len(e.Request) > 0 {
+		e.Request = l.redactor.JSON(e.Request)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	e.Seq = l.seq
+	entries := append(l.entries[e.TenantID], e)
+	if len(entries) > l.capacity {
+		entries = append([]Entry(nil), entries[len(entries)-l.capacity:]...)
+	}
+	l.entries[e.TenantID] = entries
+	return nil
+}
+
+// Seq returns the Seq of the last recorded entry
+func (l *Log) Seq() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.seq
+}
+
+// List returns the tenant's entries matching the query, newest first, and
+// whether older matching entries remain beyond the limit
+func (l *Log) List(tenantID string, q Query) ([]Entry, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	entries := make([]Entry, 0)
+	all := l.entries[tenantID]
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if q.Before > 0 && e.Seq >= q.Before {
+			continue
+		}
+		if !q.Since.IsZero() && e.Time.Before(q.Since) {
+			break
+		}
+		if q.UserID != "" && e.UserID != q.UserID {
+			continue
+		}
+		if q.Limit > 0 && len(entries) == q.Limit {
+			return entries, true
+		}
+		entries = append(entries, e)
+	}
+	return entries, false
+}
+
+// After returns the tenant's entries with a Seq above seq, oldest first,
+// keeping only those of userID when it is set
+func (l *Log) After(tenantID string, seq uint64, userID string) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var entries []Entry
+	for _, e := range l.entries[tenantID] {
+		if e.Seq <= seq || (userID != "" && e.UserID != userID) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrAPIKeyNotFound is returned when no API key matches the presented key
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// apiKeyHeader carries an API key in place of a bearer token
+const apiKeyHeader = "X-API-Key"
+
+// APIKey is a long-lived credential issued to a tenant. Tier selects its rate
+// limits; Roles are granted to requests made with it.
+type APIKey struct {
+	ID        string    `json:"id" mapstructure:"id"`
+	Hash      string    `json:"-" mapstructure:"key_sha256"`
+	Tier      string    `json:"tier" mapstructure:"tier"`
+	TenantID  string    `json:"tenant_id,omitempty" mapstructure:"tenant_id"`
+	Roles     []string  `json:"roles,omitempty" mapstructure:"roles"`
+	CreatedAt time.Time `json:"created_at" mapstructure:"-"`
+}
+
+// APIKeyStore looks up API keys by their raw value
+type APIKeyStore interface {
+	GetAPIKey(key string) (*APIKey, error)
+}
+
+// apiKeys is the store API keys are authenticated against; when nil API keys
+// are not accepted
+var apiKeys APIKeyStore
+
+// SetAPIKeyStore sets the store used to authenticate API keys
+func SetAPIKeyStore(s APIKeyStore) {
+	apiKeys = s
+}
+
+// HashAPIKey returns the hex SHA-256 digest under which a key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryAPIKeyStore holds API keys in memory, keyed by the hash of the key
+type MemoryAPIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*APIKey
+}
+
+// NewMemoryAPIKeyStore creates a new in-memory API key store
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{keys: make(map[string]*APIKey)}
+}
+
+// AddAPIKey stores a key by its Hash
+func (s *MemoryAPIKeyStore) AddAPIKey(key *APIKey) error {
+	if key.ID == "" || key.Hash == "" {
+		return fmt.Errorf("API key needs an id and a key hash")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.keys[key.Hash]; exists {
+		return fmt.Errorf("API key %s already exists", key.ID)
+	}
+
+	k := *key
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now().UTC()
+	}
+	s.keys[k.Hash] = &k
+	return nil
+}
+
+// GetAPIKey returns a copy of the API key with the given raw value
+func (s *MemoryAPIKeyStore) GetAPIKey(key string) (*APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	k, ok := s.keys[HashAPIKey(key)]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	copied := *k
+	return &copied, nil
+}
+
+// authenticateAPIKey resolves an API key to the claims of requests made
+// with it
+func authenticateAPIKey(key string) (*APIKey, *Claims, error) {
+	if apiKeys == nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	k, err := apiKeys.GetAPIKey(key)
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, fmt.Errorf("failed to look up API key: %v", err)
+	}
+
+	claims := &Claims{
+		UserID:   "apikey:" + k.ID,
+		Roles:    k.Roles,
+		TenantID: k.TenantID,
+	}
+	return k, claims, nil
+}
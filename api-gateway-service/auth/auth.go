@@ -11,6 +11,8 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/spf13/viper"
 	"golang.org/x/crypto/bcrypt"
+
+	"api-gateway-service/tenant"
 )
 
 var (
@@ -18,6 +20,7 @@ var (
 	ErrInvalidToken      = errors.New("invalid token")
 	ErrMissingToken      = errors.New("missing token")
 	ErrExpiredToken      = errors.New("token has expired")
+	ErrInvalidCSRFToken  = errors.New("missing or invalid CSRF token")
 )
 
 // Claims represents the JWT claims
@@ -26,6 +29,7 @@ type Claims struct {
 	Username  string   `json:"username"`
 	Email     string   `json:"email"`
 	Roles     []string `json:"roles"`
+	TenantID  string   `json:"tenant_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -42,14 +46,35 @@ type User struct {
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"`
 	Roles        []string  `json:"roles"`
+	TenantID     string    `json:"tenant_id,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
-// AuthMiddleware creates a Gin middleware for JWT authentication
+// AuthMiddleware creates a Gin middleware for JWT authentication. The token
+// is read from the Authorization header or, when it is absent, from the
+// auth.cookie_name cookie; cookie-authenticated requests that change state
+// must also pass the CSRF check. Requests may instead send an API key in the
+// X-API-Key header when an API key store is set.
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, err := extractToken(c.Request)
+		// API keys are sent instead of a token and are not cookies, so they
+		// skip the CSRF check
+		if key := c.GetHeader(apiKeyHeader); key != "" {
+			apiKey, claims, err := authenticateAPIKey(key)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+			c.Set("api_key", apiKey)
+			c.Set("claims", claims)
+			c.Set(authenticatedKey, true)
+			c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), claims.TenantID))
+			c.Next()
+			return
+		}
+
+		token, fromCookie, err := extractToken(c.Request)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
@@ -61,12 +86,43 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if fromCookie {
+			if err := checkCSRF(c.Request); err != nil {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		// Store claims in context for handlers to use
 		c.Set("claims", claims)
+		c.Set(authenticatedKey, true)
+
+		// Scope the request to the caller's tenant so stores only see its data
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), claims.TenantID))
 		c.Next()
 	}
 }
 
+// authenticatedKey marks, in the gin context, a request whose API key or
+// token AuthMiddleware verified
+const authenticatedKey = "authenticated"
+
+// Authenticated reports whether AuthMiddleware verified the request's API
+// key or token. Middleware running before it can check once c.Next returns.
+func Authenticated(c *gin.Context) bool {
+	return c.GetBool(authenticatedKey)
+}
+
+// HasCredentials reports whether the request presents an API key or a
+// token, in the Authorization header or the auth cookie, valid or not
+func HasCredentials(r *http.Request) bool {
+	if r.Header.Get(apiKeyHeader) != "" {
+		return true
+	}
+	_, _, err := extractToken(r)
+	return err != ErrMissingToken
+}
+
 // RoleMiddleware creates a Gin middleware for role-based access control
 func RoleMiddleware(requiredRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -91,8 +147,43 @@ func RoleMiddleware(requiredRoles ...string) gin.HandlerFunc {
 	}
 }
 
-// Login authenticates a user and returns a JWT token
+// AnyRoleMiddleware creates a Gin middleware that admits callers holding at
+// least one of the roles
+func AnyRoleMiddleware(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, exists := c.Get("claims")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		userClaims, ok := claims.(*Claims)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "invalid claims"})
+			return
+		}
+
+		for _, role := range roles {
+			if hasRequiredRoles(userClaims.Roles, []string{role}) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+	}
+}
+
+// Login authenticates a user and returns a JWT token. Passwords hashed at a
+// lower bcrypt cost than auth.bcrypt_cost are rehashed at the configured cost.
 func Login(creds *Credentials) (string, error) {
+	if users != nil {
+		user, err := authenticate(users, creds)
+		if err != nil {
+			return "", err
+		}
+		return createToken(user)
+	}
+
 	// TODO: Implement actual user lookup and password verification
 	// This is a placeholder implementation
 	user := &User{
@@ -124,6 +215,7 @@ func Refresh(oldToken string) (string, error) {
 		Username: claims.Username,
 		Email:    claims.Email,
 		Roles:    claims.Roles,
+		TenantID: claims.TenantID,
 	}
 
 	return createToken(user)
@@ -131,9 +223,13 @@ func Refresh(oldToken string) (string, error) {
 
 func createToken(user *User) (string, error) {
 	// Get JWT configuration
-	secret := []byte(viper.GetString("auth.jwt_secret"))
-	if len(secret) == 0 {
-		return "", fmt.Errorf("JWT secret not configured")
+	km, err := activeKeys()
+	if err != nil {
+		return "", err
+	}
+	method, signKey, err := km.signingKey()
+	if err != nil {
+		return "", err
 	}
 
 	expiry := viper.GetDuration("auth.token_expiry")
@@ -147,6 +243,7 @@ func createToken(user *User) (string, error) {
 		Username: user.Username,
 		Email:    user.Email,
 		Roles:    user.Roles,
+		TenantID: user.TenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -157,47 +254,67 @@ func createToken(user *User) (string, error) {
 	}
 
 	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(method, claims)
 
 	// Sign and return token
-	return token.SignedString(secret)
+	return token.SignedString(signKey)
 }
 
 func validateToken(tokenString string) (*Claims, error) {
-	secret := []byte(viper.GetString("auth.jwt_secret"))
-	if len(secret) == 0 {
-		return nil, fmt.Errorf("JWT secret not configured")
+	km, err := activeKeys()
+	if err != nil {
+		return nil, err
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	// Try the current keys first, then the pre-rotation keys while they are
+	// still inside the overlap window
+	for _, ks := range km.verificationKeys() {
+		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+			if token.Method.Alg() != ks.method.Alg() {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return ks.verifyKey, nil
+		}, jwt.WithoutClaimsValidation())
+
+		if err != nil {
+			continue
 		}
-		return secret, nil
-	})
 
-	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return nil, ErrExpiredToken
+		claims, ok := token.Claims.(*Claims)
+		if !ok || !token.Valid {
+			return nil, ErrInvalidToken
+		}
+		if err := validateTimeClaims(&claims.RegisteredClaims, time.Now(), clockSkewLeeway()); err != nil {
+			return nil, err
 		}
-		return nil, ErrInvalidToken
-	}
 
-	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
-		return nil, ErrInvalidToken
+		return claims, nil
 	}
 
-	return claims, nil
+	return nil, ErrInvalidToken
 }
 
-func extractToken(r *http.Request) (string, error) {
+// extractToken returns the bearer token from the Authorization header or,
+// when the header is absent, from the auth.cookie_name cookie, and whether it
+// came from the cookie
+func extractToken(r *http.Request) (string, bool, error) {
 	authHeader := r.Header.Get("Authorization")
 	if authHeader == "" {
-		return "", ErrMissingToken
+		if name := viper.GetString("auth.cookie_name"); name != "" {
+			if cookie, err := r.Cookie(name); err == nil && cookie.Value != "" {
+				return cookie.Value, true, nil
+			}
+		}
+		return "", false, ErrMissingToken
 	}
 
-	parts := strings.Split(authHeader, " ")
+	token, err := parseBearer(authHeader)
+	return token, false, err
+}
+
+// parseBearer returns the token of a "Bearer <token>" authorization value
+func parseBearer(value string) (string, error) {
+	parts := strings.Split(value, " ")
 	if len(parts) != 2 || parts[0] != "Bearer" {
 		return "", ErrInvalidToken
 	}
@@ -205,8 +322,27 @@ func extractToken(r *http.Request) (string, error) {
 	return parts[1], nil
 }
 
+// bcryptCost returns the configured bcrypt cost, falling back to the default
+// when it is unset or out of range
+func bcryptCost() int {
+	cost := viper.GetInt("auth.bcrypt_cost")
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return bcrypt.DefaultCost
+	}
+	return cost
+}
+
+// needsRehash reports whether a hash was made at a lower cost than configured
+func needsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false
+	}
+	return cost < bcryptCost()
+}
+
 func hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost())
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %v", err)
 	}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+const (
+	testCookieName     = "cloudopt_token"
+	testCSRFCookieName = "cloudopt_csrf"
+	testCSRFHeader     = "X-CSRF-Token"
+)
+
+// useCookieAuth configures token cookies with the CSRF cookie and header
+// main.go defaults to
+func useCookieAuth(t *testing.T) {
+	t.Helper()
+	viper.Set("auth.cookie_name", testCookieName)
+	viper.Set("auth.csrf_cookie_name", testCSRFCookieName)
+	viper.Set("auth.csrf_header", testCSRFHeader)
+	t.Cleanup(func() {
+		viper.Set("auth.cookie_name", nil)
+		viper.Set("auth.csrf_cookie_name", nil)
+		viper.Set("auth.csrf_header", nil)
+	})
+}
+
+func TestCheckCSRF(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		cookie  string
+		header  string
+		wantErr bool
+	}{
+		{name: "safe method without a token", method: http.MethodGet},
+		{name: "head without a token", method: http.MethodHead},
+		{name: "options without a token", method: http.MethodOptions},
+		{name: "matching token", method: http.MethodPost, cookie: "abc", header: "abc"},
+		{name: "missing header", method: http.MethodPost, cookie: "abc", wantErr: true},
+		{name: "missing cookie", method: http.MethodDelete, header: "abc", wantErr: true},
+		{name: "mismatched token", method: http.MethodPut, cookie: "abc", header: "abd", wantErr: true},
+		{name: "both empty", method: http.MethodPatch, wantErr: true},
+	}
+
+	useCookieAuth(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: testCSRFCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				r.Header.Set(testCSRFHeader, tt.header)
+			}
+
+			if err := checkCSRF(r); (err != nil) != tt.wantErr {
+				t.Errorf("checkCSRF error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewCSRFToken(t *testing.T) {
+	first, err := NewCSRFToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewCSRFToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 43 || first == second {
+		t.Errorf("tokens %q and %q, want distinct 32-byte base64 tokens", first, second)
+	}
+}
+
+// Only requests authenticated by the token cookie need the CSRF token
+func TestAuthMiddlewareCSRF(t *testing.T) {
+	useKeyManager(t, "secret", 0)
+	useCookieAuth(t)
+	token, err := createToken(&User{ID: "u1", Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		bearer     bool
+		csrfCookie string
+		csrfHeader string
+		wantStatus int
+	}{
+		{name: "cookie read", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "cookie write with the CSRF token", method: http.MethodPost, csrfCookie: "abc", csrfHeader: "abc", wantStatus: http.StatusOK},
+		{name: "cookie write without the CSRF token", method: http.MethodPost, wantStatus: http.StatusForbidden},
+		{name: "cookie write with a mismatched CSRF token", method: http.MethodPost, csrfCookie: "abc", csrfHeader: "xyz", wantStatus: http.StatusForbidden},
+		{name: "bearer write without the CSRF token", method: http.MethodPost, bearer: true, wantStatus: http.StatusOK},
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(AuthMiddleware())
+	router.Any("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			if tt.bearer {
+				r.Header.Set("Authorization", "Bearer "+token)
+			} else {
+				r.AddCookie(&http.Cookie{Name: testCookieName, Value: token})
+			}
+			if tt.csrfCookie != "" {
+				r.AddCookie(&http.Cookie{Name: testCSRFCookieName, Value: tt.csrfCookie})
+			}
+			if tt.csrfHeader != "" {
+				r.Header.Set(testCSRFHeader, tt.csrfHeader)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"api-gateway-service/tenant"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	useKeyManager(t, "grpc-secret", time.Hour)
+	token, err := createToken(&User{ID: "u1", TenantID: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := NewMemoryAPIKeyStore()
+	if err := keys.AddAPIKey(&APIKey{ID: "k1", Hash: HashAPIKey("grpc-key"), Tier: "pro", TenantID: "globex"}); err != nil {
+		t.Fatal(err)
+	}
+	SetAPIKeyStore(keys)
+	t.Cleanup(func() { SetAPIKeyStore(nil) })
+
+	tests := []struct {
+		name       string
+		md         metadata.MD
+		wantCode   codes.Code
+		wantUser   string
+		wantTenant string
+		wantAPIKey string
+	}{
+		{name: "bearer token", md: metadata.Pairs("authorization", "Bearer "+token), wantUser: "u1", wantTenant: "acme"},
+		{name: "API key", md: metadata.Pairs("x-api-key", "grpc-key"), wantUser: "apikey:k1", wantTenant: "globex", wantAPIKey: "k1"},
+		{name: "API key preferred over token", md: metadata.Pairs("x-api-key", "grpc-key", "authorization", "Bearer "+token), wantUser: "apikey:k1", wantTenant: "globex", wantAPIKey: "k1"},
+		{name: "unknown API key", md: metadata.Pairs("x-api-key", "guess"), wantCode: codes.Unauthenticated},
+		{name: "invalid token", md: metadata.Pairs("authorization", "Bearer guess"), wantCode: codes.Unauthenticated},
+		{name: "no credentials", md: metadata.MD{}, wantCode: codes.Unauthenticated},
+	}
+
+	interceptor := UnaryServerInterceptor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			var got context.Context
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Call"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				got = ctx
+				return nil, nil
+			})
+
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code %s, want %s", code, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			claims, ok := ClaimsFromContext(got)
+			if !ok || claims.UserID != tt.wantUser {
+				t.Errorf("claims %+v, want user %s", claims, tt.wantUser)
+			}
+			if id := tenant.FromContext(got); id != tt.wantTenant {
+				t.Errorf("tenant %s, want %s", id, tt.wantTenant)
+			}
+			key, ok := APIKeyFromContext(got)
+			if tt.wantAPIKey == "" && ok {
+				t.Errorf("API key %s in context of a token call", key.ID)
+			}
+			if tt.wantAPIKey != "" && (!ok || key.ID != tt.wantAPIKey) {
+				t.Errorf("API key %+v, want %s", key, tt.wantAPIKey)
+			}
+		})
+	}
+}
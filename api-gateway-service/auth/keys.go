@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+)
+
+// keySet is one generation of token signing and verification keys
+type keySet struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	// fingerprint identifies the key material so rotations can be detected
+	fingerprint [sha256.Size]byte
+}
+
+// KeyManager resolves token keys from a SecretProvider and keeps them current.
+// When a refresh finds rotated key material, the previous keys remain valid for
+// verification during the overlap window so tokens issued before the rotation
+// keep working.
+type KeyManager struct {
+	mu       sync.RWMutex
+	provider SecretProvider
+	method   string
+	overlap  time.Duration
+
+	current       *keySet
+	previous      *keySet
+	previousUntil time.Time
+}
+
+// NewKeyManager creates a key manager for the given signing method (HS256 or RS256)
+func NewKeyManager(provider SecretProvider, method string, overlap time.Duration) *KeyManager {
+	if method == "" {
+		method = jwt.SigningMethodHS256.Alg()
+	}
+	return &KeyManager{
+		provider: provider,
+		method:   method,
+		overlap:  overlap,
+	}
+}
+
+// Refresh resolves the keys from the secret provider, rotating the current keys
+// into the overlap window if the key material has changed
+func (km *KeyManager) Refresh(ctx context.Context) error {
+	ks, err := km.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	km.mu.Lock()
+	defer km.mu.Unlock()
+
+	if km.current != nil && km.current.fingerprint != ks.fingerprint {
+		km.previous = km.current
+		km.previousUntil = time.Now().Add(km.overlap)
+	}
+	km.current = ks
+	return nil
+}
+
+// Run refreshes the keys every interval until ctx is cancelled. Refresh
+// failures are logged and the last resolved keys stay in use.
+func (km *KeyManager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := km.Refresh(ctx); err != nil {
+				log.Printf("Failed to refresh token keys: %v", err)
+			}
+		}
+	}
+}
+
+// signingKey returns the current signing method and key
+func (km *KeyManager) signingKey() (jwt.SigningMethod, interface{}, error) {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	if km.current == nil {
+		return nil, nil, fmt.Errorf("JWT secret not configured")
+	}
+	return km.current.method, km.current.signKey, nil
+}
+
+// verificationKeys returns the current keys followed by the previous keys if
+// they are still within the rotation overlap window
+func (km *KeyManager) verificationKeys() []*keySet {
+	km.mu.RLock()
+	defer km.mu.RUnlock()
+
+	var keys []*keySet
+	if km.current != nil {
+		keys = append(keys, km.current)
+	}
+	if km.previous != nil && time.Now().Before(km.previousUntil) {
+		keys = append(keys, km.previous)
+	}
+	return keys
+}
+
+func (km *KeyManager) load(ctx context.Context) (*keySet, error) {
+	switch km.method {
+	case jwt.SigningMethodHS256.Alg():
+		secret, err := km.provider.GetSecret(ctx, SecretJWT)
+		if err != nil {
+			return nil, fmt.Errorf("JWT secret not configured: %v", err)
+		}
+		return &keySet{
+			method:      jwt.SigningMethodHS256,
+			signKey:     secret,
+			verifyKey:   secret,
+			fingerprint: sha256.Sum256(secret),
+		}, nil
+
+	case jwt.SigningMethodRS256.Alg():
+		privatePEM, err := km.provider.GetSecret(ctx, SecretPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("JWT private key not configured: %v", err)
+		}
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT private key: %v", err)
+		}
+
+		// The public key is optional since it can be derived from the private key
+		publicKey := &privateKey.PublicKey
+		publicPEM, err := km.provider.GetSecret(ctx, SecretPublicKey)
+		switch {
+		case err == nil:
+			if publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM); err != nil {
+				return nil, fmt.Errorf("invalid JWT public key: %v", err)
+			}
+		case !errors.Is(err, ErrSecretNotFound):
+			return nil, err
+		}
+
+		return &keySet{
+			method:      jwt.SigningMethodRS256,
+			signKey:     privateKey,
+			verifyKey:   publicKey,
+			fingerprint: sha256.Sum256(privatePEM),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported signing method: %s", km.method)
+	}
+}
+
+// keys is the key manager used by createToken and validateToken
+var (
+	keysMu sync.RWMutex
+	keys   *KeyManager
+)
+
+// InitSecrets resolves the token keys from the configured secret provider and
+// keeps them refreshed until ctx is cancelled
+func InitSecrets(ctx context.Context) error {
+	provider, err := NewSecretProvider()
+	if err != nil {
+		return err
+	}
+
+	km := NewKeyManager(provider, viper.GetString("auth.signing_method"), viper.GetDuration("auth.secrets.rotation_overlap"))
+	if err := km.Refresh(ctx); err != nil {
+		return err
+	}
+	SetKeyManager(km)
+
+	if interval := viper.GetDuration("auth.secrets.refresh_interval"); interval > 0 {
+		go km.Run(ctx, interval)
+	}
+	return nil
+}
+
+// SetKeyManager installs the key manager used to sign and verify tokens
+func SetKeyManager(km *KeyManager) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	keys = km
+}
+
+// activeKeys returns the installed key manager, falling back to one backed by
+// the plaintext config secret when InitSecrets has not been called
+func activeKeys() (*KeyManager, error) {
+	keysMu.RLock()
+	km := keys
+	keysMu.RUnlock()
+	if km != nil {
+		return km, nil
+	}
+
+	km = NewKeyManager(&ConfigSecretProvider{}, jwt.SigningMethodHS256.Alg(), 0)
+	if err := km.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// staticSecrets is a SecretProvider serving secrets from a map that tests
+// change to simulate rotation
+type staticSecrets struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+func (p *staticSecrets) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	value, ok := p.secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return []byte(value), nil
+}
+
+func (p *staticSecrets) set(name, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.secrets[name] = value
+}
+
+// useKeyManager installs a key manager signing with HS256 secret for the test
+func useKeyManager(t *testing.T, secret string, overlap time.Duration) (*KeyManager, *staticSecrets) {
+	t.Helper()
+	provider := &staticSecrets{secrets: map[string]string{SecretJWT: secret}}
+	km := NewKeyManager(provider, "HS256", overlap)
+	if err := km.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	SetKeyManager(km)
+	t.Cleanup(func() { SetKeyManager(nil) })
+	return km, provider
+}
+
+func TestKeyRotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		overlap time.Duration
+		// rotations are the secrets rotated to, in order, after the token is
+		// issued
+		rotations []string
+		// expire moves the rotation overlap window into the past
+		expire    bool
+		wantValid bool
+	}{
+		{name: "no rotation", overlap: time.Hour, wantValid: true},
+		{name: "refresh without rotation", overlap: time.Hour, rotations: []string{"first"}, wantValid: true},
+		{name: "rotated within the overlap window", overlap: time.Hour, rotations: []string{"second"}, wantValid: true},
+		{name: "rotated past the overlap window", overlap: time.Hour, rotations: []string{"second"}, expire: true},
+		{name: "rotated without an overlap window", rotations: []string{"second"}},
+		{name: "rotated twice", overlap: time.Hour, rotations: []string{"second", "third"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km, provider := useKeyManager(t, "first", tt.overlap)
+			token, err := createToken(&User{ID: "u1", Username: "alice"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, secret := range tt.rotations {
+				provider.set(SecretJWT, secret)
+				if err := km.Refresh(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.expire {
+				km.mu.Lock()
+				km.previousUntil = time.Now().Add(-time.Second)
+				km.mu.Unlock()
+			}
+
+			_, err = validateToken(token)
+			if valid := err == nil; valid != tt.wantValid {
+				t.Errorf("validateToken error = %v, want valid %v", err, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestKeyRotationSignsWithNewKey(t *testing.T) {
+	km, provider := useKeyManager(t, "first", time.Hour)
+	provider.set(SecretJWT, "second")
+	if err := km.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := createToken(&User{ID: "u1", Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the overlap ends, only the new key verifies the token
+	km.mu.Lock()
+	km.previousUntil = time.Now().Add(-time.Second)
+	km.mu.Unlock()
+	if _, err := validateToken(token); err != nil {
+		t.Errorf("token signed after rotation: %v", err)
+	}
+}
+
+func TestKeyManagerRefreshFailureKeepsKeys(t *testing.T) {
+	km, provider := useKeyManager(t, "first", time.Hour)
+	token, err := createToken(&User{ID: "u1", Username: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider.mu.Lock()
+	delete(provider.secrets, SecretJWT)
+	provider.mu.Unlock()
+	if err := km.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh succeeded without a secret")
+	}
+
+	if _, err := validateToken(token); err != nil {
+		t.Errorf("token rejected after a failed refresh: %v", err)
+	}
+}
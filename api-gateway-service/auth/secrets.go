@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Names of the secrets resolved from a SecretProvider
+const (
+	SecretJWT        = "jwt_secret"
+	SecretPrivateKey = "jwt_private_key"
+	SecretPublicKey  = "jwt_public_key"
+)
+
+// ErrSecretNotFound is returned when a provider has no value for a secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves named secrets from a backing store
+type SecretProvider interface {
+	GetSecret(ctx context.Context, name string) ([]byte, error)
+}
+
+// EnvSecretProvider reads secrets from environment variables named
+// Prefix + upper-cased secret name (e.g. CLOUDOPT_JWT_SECRET)
+type EnvSecretProvider struct {
+	Prefix string
+}
+
+// GetSecret returns the value of the secret's environment variable
+func (p *EnvSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	value := os.Getenv(p.Prefix + strings.ToUpper(name))
+	if value == "" {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return []byte(value), nil
+}
+
+// FileSecretProvider reads each secret from a file named after it in Dir,
+// as mounted by Kubernetes or Docker secrets
+type FileSecretProvider struct {
+	Dir string
+}
+
+// GetSecret returns the contents of the secret's file with surrounding
+// whitespace removed
+func (p *FileSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(p.Dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+		}
+		return nil, fmt.Errorf("failed to read secret %s: %v", name, err)
+	}
+
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return []byte(value), nil
+}
+
+// VaultSecretProvider reads secrets from a HashiCorp Vault KV version 2 engine.
+// Each secret is a key in the data of the secret at Path (e.g.
+// "secret/data/cloud-optimizer").
+type VaultSecretProvider struct {
+	Address    string
+	Token      string
+	Path       string
+	HTTPClient *http.Client
+}
+
+// GetSecret returns the named key from the Vault secret
+func (p *VaultSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimRight(p.Address, "/"), strings.TrimLeft(p.Path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %v", err)
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach vault: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault request failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %v", err)
+	}
+
+	value, exists := body.Data.Data[name]
+	if !exists || value == "" {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return []byte(value), nil
+}
+
+// ConfigSecretProvider reads secrets from the auth section of the gateway
+// config. It exists for backward compatibility; prefer env, file or vault.
+type ConfigSecretProvider struct{}
+
+// GetSecret returns auth.<name> from the config
+func (p *ConfigSecretProvider) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	value := viper.GetString("auth." + name)
+	if value == "" {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return []byte(value), nil
+}
+
+// NewSecretProvider creates the secret provider selected by auth.secrets.provider
+func NewSecretProvider() (SecretProvider, error) {
+	switch kind := viper.GetString("auth.secrets.provider"); kind {
+	case "", "config":
+		return &ConfigSecretProvider{}, nil
+	case "env":
+		return &EnvSecretProvider{Prefix: viper.GetString("auth.secrets.env_prefix")}, nil
+	case "file":
+		dir := viper.GetString("auth.secrets.file_dir")
+		if dir == "" {
+			return nil, fmt.Errorf("auth.secrets.file_dir is required for the file secret provider")
+		}
+		return &FileSecretProvider{Dir: dir}, nil
+	case "vault":
+		token := viper.GetString("auth.secrets.vault.token")
+		if token == "" {
+			token = os.Getenv("VAULT_TOKEN")
+		}
+		provider := &VaultSecretProvider{
+			Address: viper.GetString("auth.secrets.vault.address"),
+			Token:   token,
+			Path:    viper.GetString("auth.secrets.vault.path"),
+		}
+		if provider.Address == "" || provider.Path == "" || provider.Token == "" {
+			return nil, fmt.Errorf("vault secret provider requires an address, path and token")
+		}
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("unknown secret provider: %s", kind)
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+)
+
+// clockSkewLeeway returns how far a token's time claims may be off the local
+// clock, configured as auth.clock_skew_leeway
+func clockSkewLeeway() time.Duration {
+	leeway := viper.GetDuration("auth.clock_skew_leeway")
+	if leeway < 0 {
+		return 0
+	}
+	return leeway
+}
+
+// validateTimeClaims checks the expiry, not-before and issued-at claims at
+// now, allowing leeway either way for clock skew between services. jwt/v4's
+// parser has no leeway option, so tokens are parsed without claims
+// validation and checked here instead.
+func validateTimeClaims(c *jwt.RegisteredClaims, now time.Time, leeway time.Duration) error {
+	if !c.VerifyExpiresAt(now.Add(-leeway), false) {
+		return ErrExpiredToken
+	}
+	if !c.VerifyNotBefore(now.Add(leeway), false) || !c.VerifyIssuedAt(now.Add(leeway), false) {
+		return ErrInvalidToken
+	}
+	return nil
+}
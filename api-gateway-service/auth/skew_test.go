@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/spf13/viper"
+)
+
+func TestValidateTimeClaims(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *jwt.NumericDate { return jwt.NewNumericDate(now.Add(d)) }
+
+	tests := []struct {
+		name    string
+		claims  jwt.RegisteredClaims
+		leeway  time.Duration
+		wantErr error
+	}{
+		{name: "current token", claims: jwt.RegisteredClaims{ExpiresAt: at(time.Hour), NotBefore: at(-time.Minute), IssuedAt: at(-time.Minute)}},
+		{name: "no time claims", claims: jwt.RegisteredClaims{}},
+		{name: "expired", claims: jwt.RegisteredClaims{ExpiresAt: at(-10 * time.Second)}, wantErr: ErrExpiredToken},
+		{name: "expired within the leeway", claims: jwt.RegisteredClaims{ExpiresAt: at(-10 * time.Second)}, leeway: 30 * time.Second},
+		{name: "expired beyond the leeway", claims: jwt.RegisteredClaims{ExpiresAt: at(-time.Minute)}, leeway: 30 * time.Second, wantErr: ErrExpiredToken},
+		{name: "not yet valid", claims: jwt.RegisteredClaims{NotBefore: at(10 * time.Second)}, wantErr: ErrInvalidToken},
+		{name: "not yet valid within the leeway", claims: jwt.RegisteredClaims{NotBefore: at(10 * time.Second)}, leeway: 30 * time.Second},
+		{name: "not yet valid beyond the leeway", claims: jwt.RegisteredClaims{NotBefore: at(time.Minute)}, leeway: 30 * time.Second, wantErr: ErrInvalidToken},
+		{name: "issued in the future", claims: jwt.RegisteredClaims{IssuedAt: at(10 * time.Second)}, wantErr: ErrInvalidToken},
+		{name: "issued in the future within the leeway", claims: jwt.RegisteredClaims{IssuedAt: at(10 * time.Second)}, leeway: 30 * time.Second},
+		{name: "issued in the future beyond the leeway", claims: jwt.RegisteredClaims{IssuedAt: at(time.Minute)}, leeway: 30 * time.Second, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTimeClaims(&tt.claims, now, tt.leeway); err != tt.wantErr {
+				t.Errorf("validateTimeClaims error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClockSkewLeeway(t *testing.T) {
+	tests := []struct {
+		configured time.Duration
+		want       time.Duration
+	}{
+		{configured: 0, want: 0},
+		{configured: 30 * time.Second, want: 30 * time.Second},
+		{configured: -time.Second, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.configured.String(), func(t *testing.T) {
+			viper.Set("auth.clock_skew_leeway", tt.configured)
+			t.Cleanup(func() { viper.Set("auth.clock_skew_leeway", nil) })
+
+			if got := clockSkewLeeway(); got != tt.want {
+				t.Errorf("clockSkewLeeway() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// The leeway configured applies to tokens validated by the middleware
+func TestValidateTokenAppliesLeeway(t *testing.T) {
+	km, _ := useKeyManager(t, "first", 0)
+	method, key, err := km.signingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.NewWithClaims(method, &Claims{
+		UserID:           "u1",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-10 * time.Second))},
+	}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		leeway  time.Duration
+		wantErr error
+	}{
+		{leeway: 0, wantErr: ErrExpiredToken},
+		{leeway: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.leeway.String(), func(t *testing.T) {
+			viper.Set("auth.clock_skew_leeway", tt.leeway)
+			t.Cleanup(func() { viper.Set("auth.clock_skew_leeway", nil) })
+
+			if _, err := validateToken(token); err != tt.wantErr {
+				t.Errorf("validateToken error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
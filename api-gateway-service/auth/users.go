@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrUserNotFound is returned when no user has the requested username
+var ErrUserNotFound = errors.New("user not found")
+
+// UserStore looks up users for login and persists rehashed passwords
+type UserStore interface {
+	GetUserByUsername(username string) (*User, error)
+	UpdatePasswordHash(userID, hash string) error
+}
+
+// users is the store Login authenticates against; when nil Login falls back
+// to the placeholder user
+var users UserStore
+
+// SetUserStore sets the store used to authenticate logins
+func SetUserStore(s UserStore) {
+	users = s
+}
+
+// authenticate verifies the credentials against the store, upgrading the
+// stored hash when it was made at a lower cost than configured. A failed
+// upgrade is logged and does not fail the login.
+func authenticate(s UserStore, creds *Credentials) (*User, error) {
+	user, err := s.GetUserByUsername(creds.Username)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to look up user: %v", err)
+	}
+
+	if !verifyPassword(creds.Password, user.PasswordHash) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if needsRehash(user.PasswordHash) {
+		hash, err := hashPassword(creds.Password)
+		if err == nil {
+			err = s.UpdatePasswordHash(user.ID, hash)
+		}
+		if err != nil {
+			log.Printf("Failed to rehash password for user %s: %v", user.ID, err)
+		} else {
+			user.PasswordHash = hash
+		}
+	}
+
+	return user, nil
+}
+
+// MemoryUserStore holds users in memory, keyed by username
+type MemoryUserStore struct {
+	mu    sync.RWMutex
+	users map[string]*User
+}
+
+// NewMemoryUserStore creates a new in-memory user store
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{users: make(map[string]*User)}
+}
+
+// CreateUser adds a user with the given password, hashed at the configured cost
+func (s *MemoryUserStore) CreateUser(user *User, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.Username]; exists {
+		return fmt.Errorf("user %s already exists", user.Username)
+	}
+
+	u := *user
+	u.PasswordHash = hash
+	u.CreatedAt = time.Now().UTC()
+	u.UpdatedAt = u.CreatedAt
+	s.users[u.Username] = &u
+	return nil
+}
+
+// GetUserByUsername returns a copy of the user with the given username
+func (s *MemoryUserStore) GetUserByUsername(username string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+// UpdatePasswordHash replaces the stored password hash of a user
+func (s *MemoryUserStore) UpdatePasswordHash(userID, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.ID == userID {
+			u.PasswordHash = hash
+			u.UpdatedAt = time.Now().UTC()
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
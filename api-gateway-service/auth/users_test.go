@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// useBcryptCost sets auth.bcrypt_cost for the test
+func useBcryptCost(t *testing.T, cost int) {
+	t.Helper()
+	viper.Set("auth.bcrypt_cost", cost)
+	t.Cleanup(func() { viper.Set("auth.bcrypt_cost", nil) })
+}
+
+// A password hashed at a lower cost still logs in and is rehashed at the
+// configured cost; later logins verify against the new hash
+func TestLoginRehashesPassword(t *testing.T) {
+	useKeyManager(t, "test-secret", 0)
+	store := NewMemoryUserStore()
+	SetUserStore(store)
+	t.Cleanup(func() { SetUserStore(nil) })
+
+	useBcryptCost(t, bcrypt.MinCost)
+	if err := store.CreateUser(&User{ID: "u1", Username: "alice", Roles: []string{"viewer"}}, "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+
+	useBcryptCost(t, bcrypt.MinCost+1)
+	for i := 0; i < 2; i++ {
+		if _, err := Login(&Credentials{Username: "alice", Password: "s3cret"}); err != nil {
+			t.Fatalf("login %d: %v", i+1, err)
+		}
+		user, err := store.GetUserByUsername("alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cost, err := bcrypt.Cost([]byte(user.PasswordHash)); err != nil || cost != bcrypt.MinCost+1 {
+			t.Errorf("login %d: stored hash cost %d (%v), want %d", i+1, cost, err, bcrypt.MinCost+1)
+		}
+	}
+
+	if _, err := Login(&Credentials{Username: "alice", Password: "wrong"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("login with a wrong password = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := Login(&Credentials{Username: "bob", Password: "s3cret"}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("login of an unknown user = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestVerifyPasswordAcrossCosts(t *testing.T) {
+	for _, cost := range []int{bcrypt.MinCost, bcrypt.MinCost + 2} {
+		useBcryptCost(t, cost)
+		hash, err := hashPassword("s3cret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := bcrypt.Cost([]byte(hash)); got != cost {
+			t.Errorf("hash cost %d, want %d", got, cost)
+		}
+
+		useBcryptCost(t, bcrypt.MinCost+1)
+		if !verifyPassword("s3cret", hash) || verifyPassword("other", hash) {
+			t.Errorf("cost %d hash does not verify at cost %d", cost, bcrypt.MinCost+1)
+		}
+		if want := cost < bcrypt.MinCost+1; needsRehash(hash) != want {
+			t.Errorf("needsRehash of a cost %d hash = %v, want %v", cost, !want, want)
+		}
+	}
+}
+
+func TestBcryptCostOutOfRange(t *testing.T) {
+	for _, cost := range []int{0, bcrypt.MaxCost + 1} {
+		useBcryptCost(t, cost)
+		if got := bcryptCost(); got != bcrypt.DefaultCost {
+			t.Errorf("bcryptCost() with auth.bcrypt_cost %d = %d, want %d", cost, got, bcrypt.DefaultCost)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"api-gateway-service/compliance"
+	"api-gateway-service/store"
+)
+
+var complianceCatalog = compliance.DefaultCatalog()
+
+const (
+	// Export formats of compliance evidence
+	evidenceFormatJSON = "json"
+	evidenceFormatPDF  = "pdf"
+)
+
+// ComplianceViolation is a compliance framework required by a placement that
+// applying a recommendation would move to a region not supporting it.
+// Controls are the framework's controls the placement would no longer meet.
+type ComplianceViolation struct {
+	RecommendationID string               `json:"recommendation_id"`
+	PlacementID      string               `json:"placement_id"`
+	Provider         string               `json:"provider"`
+	Region           string               `json:"region"`
+	Framework        string               `json:"framework"`
+	Controls         []compliance.Control `json:"controls"`
+}
+
+func listComplianceFrameworks(c *gin.Context) {
+	c.JSON(http.StatusOK, complianceCatalog.List())
+}
+
+// getComplianceEvidence returns the compliance evidence bundle of the
+// caller's tenant placement, as JSON or, with ?format=pdf, as a PDF document
+func getComplianceEvidence(c *gin.Context) {
+	placementID := c.Query("placement_id")
+	if placementID == "" {
+		respondError(c, http.StatusBadRequest, "placement_id is required")
+		return
+	}
+	format := c.DefaultQuery("format", evidenceFormatJSON)
+	if format != evidenceFormatJSON && format != evidenceFormatPDF {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid format: %s (must be %s or %s)", format, evidenceFormatJSON, evidenceFormatPDF))
+		return
+	}
+
+	var p *store.Placement
+	for _, candidate := range placementStore.List(c.Request.Context(), "", "", false) {
+		if candidate.ID == placementID {
+			p = candidate
+			break
+		}
+	}
+	if p == nil {
+		respondError(c, http.StatusNotFound, "placement not found")
+		return
+	}
+
+	evidence := complianceCatalog.Evidence(evidenceSubject(p), time.Now())
+	if format == evidenceFormatPDF {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"evidence-%s.pdf\"", p.ID))
+		c.Data(http.StatusOK, "application/pdf", evidence.PDF())
+		return
+	}
+	c.JSON(http.StatusOK, evidence)
+}
+
+// evidenceSubject describes the placement for compliance evidence: its
+// required frameworks and every region it runs in, which for a multi-region
+// placement are its region allocations
+func evidenceSubject(p *store.Placement) compliance.Subject {
+	s := compliance.Subject{
+		PlacementID:  p.ID,
+		ResourceType: p.ResourceType,
+		Frameworks:   requiredFrameworks(p),
+	}
+	if len(p.RegionAllocations) == 0 {
+		s.Regions = append(s.Regions, regionAttestation(p.SelectedProvider, p.SelectedRegion))
+	}
+	for _, a := range p.RegionAllocations {
+		s.Regions = append(s.Regions, regionAttestation(a.Provider, a.Region))
+	}
+	return s
+}
+
+// regionAttestation returns the region with the compliance frameworks it
+// supports
+func regionAttestation(providerName, regionName string) compliance.RegionAttestation {
+	return compliance.RegionAttestation{
+		Provider:   providerName,
+		Region:     regionName,
+		Frameworks: certifiedFrameworks(providerName, regionName),
+	}
+}
+
+// certifiedFrameworks returns the compliance frameworks the region supports,
+// none when it is not in the placement catalog
+func certifiedFrameworks(providerName, regionName string) []string {
+	frameworks := make([]string, 0)
+	if provider, err := placementEngine.Catalog().Provider(providerName); err == nil {
+		if region, err := provider.Region(regionName); err == nil {
+			frameworks = append(frameworks, region.ComplianceFrameworks...)
+		}
+	}
+	return frameworks
+}
+
+// regionFrameworks returns the compliance frameworks the region supports,
+// none when it is not in the placement catalog
+func regionFrameworks(providerName, regionName string) map[string]bool {
+	supported := make(map[string]bool)
+	for _, f := range certifiedFrameworks(providerName, regionName) {
+		supported[f] = true
+	}
+	return supported
+}
+
+// requiredFrameworks returns the compliance frameworks the placement was
+// required to meet
+func requiredFrameworks(p *store.Placement) []string {
+	var ids []string
+	required, _ := p.Requirements["compliance_frameworks"].([]interface{})
+	for _, v := range required {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// checkApplyCompliance returns the compliance frameworks each recommendation
+// would violate once applied. Only recommendations moving a placement to
+// another provider or region are checked; the placement's required frameworks
+// must all be supported by the region it would end up in.
+func checkApplyCompliance(ctx context.Context, recs []*store.Recommendation) []ComplianceViolation {
+	placements := make(map[string]*store.Placement)
+	for _, p := range placementStore.List(ctx, "", "", false) {
+		placements[p.ID] = p
+	}
+
+	violations := make([]ComplianceViolation, 0)
+	for _, rec := range recs {
+		p, ok := placements[rec.ResourceID]
+		if !ok {
+			continue
+		}
+		provider, region := rec.Target()
+		if provider == "" {
+			provider = p.SelectedProvider
+		}
+		if region == "" {
+			region = p.SelectedRegion
+		}
+		if provider == p.SelectedProvider && region == p.SelectedRegion {
+			continue
+		}
+
+		supported := regionFrameworks(provider, region)
+		for _, id := range requiredFrameworks(p) {
+			if supported[id] {
+				continue
+			}
+			v := ComplianceViolation{
+				RecommendationID: rec.ID,
+				PlacementID:      p.ID,
+				Provider:         provider,
+				Region:           region,
+				Framework:        id,
+			}
+			if f, ok := complianceCatalog.Get(id); ok {
+				v.Controls = f.Controls
+			}
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,132 @@
+// Package compliance catalogs the compliance frameworks placements can require.
+package compliance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Control is a requirement within a compliance framework
+type Control struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// Framework is a supported compliance framework. ID is the exact value
+// accepted in compliance_frameworks.
+type Framework struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Controls []Control `json:"controls"`
+}
+
+// Catalog holds the supported frameworks by ID
+type Catalog struct {
+	frameworks map[string]Framework
+}
+
+// NewCatalog creates a catalog of the given frameworks
+func NewCatalog(frameworks []Framework) *Catalog {
+	c := &Catalog{frameworks: make(map[string]Framework, len(frameworks))}
+	for _, f := range frameworks {
+		c.frameworks[f.ID] = f
+	}
+	return c
+}
+
+// List returns the frameworks ordered by ID
+func (c *Catalog) List() []Framework {
+	frameworks := make([]Framework, 0, len(c.frameworks))
+	for _, f := range c.frameworks {
+		frameworks = append(frameworks, f)
+	}
+
+	sort.Slice(frameworks, func(i, j int) bool {
+		return frameworks[i].ID < frameworks[j].ID
+	})
+	return frameworks
+}
+
+// Get returns the framework with the given ID
+func (c *Catalog) Get(id string) (Framework, bool) {
+	f, ok := c.frameworks[id]
+	return f, ok
+}
+
+// Validate checks that every ID names a supported framework. IDs are matched
+// exactly; a case-insensitive match is suggested in the error.
+func (c *Catalog) Validate(ids []string) error {
+	for _, id := range ids {
+		if _, ok := c.frameworks[id]; ok {
+			continue
+		}
+
+		for known := range c.frameworks {
+			if strings.EqualFold(known, id) {
+				return fmt.Errorf("unknown compliance framework %q (did you mean %q?)", id, known)
+			}
+		}
+		return fmt.Errorf("unknown compliance framework %q", id)
+	}
+	return nil
+}
+
+// DefaultCatalog returns the built-in framework catalog, covering every
+// framework referenced by the placement catalog
+func DefaultCatalog() *Catalog {
+	return NewCatalog([]Framework{
+		{
+			ID:   "FedRAMP",
+			Name: "Federal Risk and Authorization Management Program",
+			Controls: []Control{
+				{ID: "AC-2", Description: "Account management"},
+				{ID: "AU-2", Description: "Audit events"},
+				{ID: "SC-13", Description: "Cryptographic protection"},
+			},
+		},
+		{
+			ID:   "GDPR",
+			Name: "General Data Protection Regulation",
+			Controls: []Control{
+				{ID: "Art.32", Description: "Security of processing"},
+				{ID: "Art.44", Description: "Transfers of personal data to third countries"},
+			},
+		},
+		{
+			ID:   "HIPAA",
+			Name: "Health Insurance Portability and Accountability Act",
+			Controls: []Control{
+				{ID: "164.312(a)", Description: "Access control"},
+				{ID: "164.312(b)", Description: "Audit controls"},
+				{ID: "164.312(e)", Description: "Transmission security"},
+			},
+		},
+		{
+			ID:   "ISO27001",
+			Name: "ISO/IEC 27001",
+			Controls: []Control{
+				{ID: "A.9", Description: "Access control"},
+				{ID: "A.10", Description: "Cryptography"},
+				{ID: "A.12", Description: "Operations security"},
+			},
+		},
+		{
+			ID:   "PCI-DSS",
+			Name: "Payment Card Industry Data Security Standard",
+			Controls: []Control{
+				{ID: "3", Description: "Protect stored account data"},
+				{ID: "4", Description: "Encrypt transmission of cardholder data"},
+				{ID: "10", Description: "Log and monitor all access"},
+			},
+		},
+		{
+			ID:   "SOC2",
+			Name: "SOC 2",
+			Controls: []Control{
+				{ID: "CC6.1", Description: "Logical access security"},
+				{ID: "CC7.2", Description: "System monitoring"},
+			},
+		},
+	})
+}
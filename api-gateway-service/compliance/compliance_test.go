@@ -0,0 +1,49 @@
+package compliance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogList(t *testing.T) {
+	frameworks := DefaultCatalog().List()
+	if len(frameworks) == 0 {
+		t.Fatal("no frameworks")
+	}
+	for i, f := range frameworks {
+		if i > 0 && frameworks[i-1].ID >= f.ID {
+			t.Errorf("%s listed after %s, want frameworks ordered by ID", f.ID, frameworks[i-1].ID)
+		}
+		if f.Name == "" || len(f.Controls) == 0 {
+			t.Errorf("framework %s has no name or controls", f.ID)
+		}
+	}
+}
+
+func TestCatalogValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "known", ids: []string{"GDPR", "PCI-DSS"}},
+		{name: "unknown", ids: []string{"GDPR", "NIST"}, wantErr: `unknown compliance framework "NIST"`},
+		{name: "wrong case", ids: []string{"gdpr"}, wantErr: `(did you mean "GDPR"?)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultCatalog().Validate(tt.ids)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate(%v) = %v", tt.ids, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate(%v) = %v, want %q", tt.ids, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package compliance
+
+import (
+	"sort"
+	"time"
+)
+
+// Results of a control evaluation
+const (
+	ResultPass = "pass"
+	ResultFail = "fail"
+)
+
+// RegionAttestation is a region a placement runs in, with the frameworks the
+// placement catalog lists it as certified for
+type RegionAttestation struct {
+	Provider   string   `json:"provider"`
+	Region     string   `json:"region"`
+	Frameworks []string `json:"frameworks"`
+}
+
+// certifies reports whether the region is certified for the framework
+func (r RegionAttestation) certifies(framework string) bool {
+	for _, f := range r.Frameworks {
+		if f == framework {
+			return true
+		}
+	}
+	return false
+}
+
+// Subject is the placement evidence is gathered for: the frameworks it
+// claims and every region it runs in
+type Subject struct {
+	PlacementID  string
+	ResourceType string
+	Frameworks   []string
+	Regions      []RegionAttestation
+}
+
+// ControlEvidence is the data a control's result is based on. Regions are
+// every region the placement runs in; Uncertified are those not certified
+// for the control's framework.
+type ControlEvidence struct {
+	Regions     []RegionAttestation `json:"regions"`
+	Uncertified []string            `json:"uncertified,omitempty"`
+	Reason      string              `json:"reason"`
+}
+
+// ControlResult is the evaluation of one control of a claimed framework
+type ControlResult struct {
+	Framework   string          `json:"framework"`
+	ControlID   string          `json:"control_id"`
+	Description string          `json:"description"`
+	Result      string          `json:"result"`
+	Evidence    ControlEvidence `json:"evidence"`
+}
+
+// Evidence is the compliance evidence bundle of a placement: the frameworks
+// it claims and each of their controls evaluated. Passed is set when every
+// control passes.
+type Evidence struct {
+	PlacementID  string          `json:"placement_id"`
+	ResourceType string          `json:"resource_type"`
+	Frameworks   []string        `json:"frameworks"`
+	Controls     []ControlResult `json:"controls"`
+	Passed       bool            `json:"passed"`
+	GeneratedAt  time.Time       `json:"generated_at"`
+}
+
+// Evidence evaluates every control of the frameworks the subject claims, in
+// framework order. Controls are met through the certifications of the
+// regions the placement runs in, so a control passes when every region is
+// certified for its framework. A framework missing from the catalog fails
+// as a single control without an ID.
+func (c *Catalog) Evidence(s Subject, now time.Time) *Evidence {
+	frameworks := append([]string(nil), s.Frameworks...)
+	sort.Strings(frameworks)
+
+	e := &Evidence{
+		PlacementID:  s.PlacementID,
+		ResourceType: s.ResourceType,
+		Frameworks:   make([]string, 0, len(frameworks)),
+		Controls:     make([]ControlResult, 0),
+		Passed:       true,
+		GeneratedAt:  now.UTC(),
+	}
+	e.Frameworks = append(e.Frameworks, frameworks...)
+
+	for _, id := range frameworks {
+		evidence, certified := regionEvidence(id, s.Regions)
+
+		f, ok := c.Get(id)
+		if !ok {
+			e.Passed = false
+			e.Controls = append(e.Controls, ControlResult{
+				Framework: id,
+				Result:    ResultFail,
+				Evidence: ControlEvidence{
+					Regions:     evidence.Regions,
+					Uncertified: evidence.Uncertified,
+					Reason:      "framework is not in the compliance catalog",
+				},
+			})
+			continue
+		}
+
+		result := ResultPass
+		if !certified {
+			result = ResultFail
+			e.Passed = false
+		}
+		for _, control := range f.Controls {
+			e.Controls = append(e.Controls, ControlResult{
+				Framework:   id,
+				ControlID:   control.ID,
+				Description: control.Description,
+				Result:      result,
+				Evidence:    evidence,
+			})
+		}
+	}
+	return e
+}
+
+// regionEvidence checks the framework's certification in each region,
+// reporting whether the placement runs in at least one region and all are
+// certified
+func regionEvidence(framework string, regions []RegionAttestation) (ControlEvidence, bool) {
+	evidence := ControlEvidence{Regions: make([]RegionAttestation, 0, len(regions))}
+	evidence.Regions = append(evidence.Regions, regions...)
+	for _, r := range regions {
+		if !r.certifies(framework) {
+			evidence.Uncertified = append(evidence.Uncertified, r.Provider+"/"+r.Region)
+		}
+	}
+
+	switch {
+	case len(regions) == 0:
+		evidence.Reason = "placement runs in no region"
+		return evidence, false
+	case len(evidence.Uncertified) > 0:
+		evidence.Reason = "not every region is certified for " + framework
+		return evidence, false
+	}
+	evidence.Reason = "every region is certified for " + framework
+	return evidence, true
+}
@@ -0,0 +1,166 @@
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCatalogEvidence(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	eastUS := RegionAttestation{Provider: "aws", Region: "us-east-1", Frameworks: []string{"SOC2", "HIPAA"}}
+	euWest := RegionAttestation{Provider: "aws", Region: "eu-west-1", Frameworks: []string{"SOC2", "HIPAA", "GDPR"}}
+
+	tests := []struct {
+		name        string
+		subject     Subject
+		wantResults []string
+		wantPassed  bool
+	}{
+		{
+			name:        "certified",
+			subject:     Subject{Frameworks: []string{"SOC2", "GDPR"}, Regions: []RegionAttestation{euWest}},
+			wantResults: []string{"GDPR Art.32 pass", "GDPR Art.44 pass", "SOC2 CC6.1 pass", "SOC2 CC7.2 pass"},
+			wantPassed:  true,
+		},
+		{
+			name:        "one region uncertified",
+			subject:     Subject{Frameworks: []string{"GDPR", "HIPAA"}, Regions: []RegionAttestation{eastUS, euWest}},
+			wantResults: []string{"GDPR Art.32 fail", "GDPR Art.44 fail", "HIPAA 164.312(a) pass", "HIPAA 164.312(b) pass", "HIPAA 164.312(e) pass"},
+		},
+		{
+			name:        "framework not in catalog",
+			subject:     Subject{Frameworks: []string{"NIST"}, Regions: []RegionAttestation{eastUS}},
+			wantResults: []string{"NIST  fail"},
+		},
+		{
+			name:        "no region",
+			subject:     Subject{Frameworks: []string{"SOC2"}},
+			wantResults: []string{"SOC2 CC6.1 fail", "SOC2 CC7.2 fail"},
+		},
+		{
+			name:        "no frameworks",
+			subject:     Subject{Regions: []RegionAttestation{eastUS}},
+			wantResults: []string{},
+			wantPassed:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.subject.PlacementID = "plc-1"
+			e := DefaultCatalog().Evidence(tt.subject, now)
+
+			results := make([]string, 0, len(e.Controls))
+			for _, c := range e.Controls {
+				results = append(results, fmt.Sprintf("%s %s %s", c.Framework, c.ControlID, c.Result))
+			}
+			if !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("controls %v, want %v", results, tt.wantResults)
+			}
+			if e.Passed != tt.wantPassed {
+				t.Errorf("passed %v, want %v", e.Passed, tt.wantPassed)
+			}
+			if e.PlacementID != "plc-1" || !e.GeneratedAt.Equal(now) || e.GeneratedAt.Location() != time.UTC {
+				t.Errorf("placement %s generated at %v", e.PlacementID, e.GeneratedAt)
+			}
+		})
+	}
+
+	// Each control carries the regions behind its result
+	e := DefaultCatalog().Evidence(Subject{Frameworks: []string{"GDPR"}, Regions: []RegionAttestation{eastUS, euWest}}, now)
+	want := ControlEvidence{
+		Regions:     []RegionAttestation{eastUS, euWest},
+		Uncertified: []string{"aws/us-east-1"},
+		Reason:      "not every region is certified for GDPR",
+	}
+	if !reflect.DeepEqual(e.Controls[0].Evidence, want) {
+		t.Errorf("evidence %+v, want %+v", e.Controls[0].Evidence, want)
+	}
+}
+
+func TestEvidencePDF(t *testing.T) {
+	e := DefaultCatalog().Evidence(Subject{
+		PlacementID:  "plc-1",
+		ResourceType: "compute",
+		Frameworks:   []string{"HIPAA"},
+		Regions:      []RegionAttestation{{Provider: "aws", Region: "us-east-1", Frameworks: []string{"HIPAA"}}},
+	}, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	lines := e.Lines()
+	for _, want := range []string{
+		"Placement:     plc-1",
+		"Result:        PASS",
+		"Generated at:  2026-03-01T12:00:00Z",
+		"[PASS] HIPAA 164.312(a) Access control",
+		"    aws/us-east-1 certified for: HIPAA",
+	} {
+		if indexOf(lines, want) < 0 {
+			t.Errorf("lines missing %q", want)
+		}
+	}
+
+	doc := e.PDF()
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF document: %q", doc)
+	}
+	// Parentheses in control IDs are escaped in the text
+	if !bytes.Contains(doc, []byte(`([PASS] HIPAA 164.312\(a\) Access control) '`)) {
+		t.Error("PDF missing the escaped control line")
+	}
+	checkXref(t, doc)
+}
+
+func TestRenderPDFPages(t *testing.T) {
+	lines := make([]string, pdfLinesPerPage*2+1)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d é", i)
+	}
+	doc := renderPDF(lines)
+
+	if !bytes.Contains(doc, []byte("/Count 3 >>")) {
+		t.Error("want 3 pages")
+	}
+	if !bytes.Contains(doc, []byte("(line 0 ?) '")) {
+		t.Error("non-ASCII text not replaced")
+	}
+	checkXref(t, doc)
+}
+
+// checkXref checks the document's cross-reference table points at each of
+// its objects
+func checkXref(t *testing.T, doc []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := strings.Split(string(doc[xref:]), "\n")[3:]
+	for i, entry := range entries {
+		if !strings.HasSuffix(entry, " n ") {
+			break
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, doc[offset:offset+10])
+		}
+	}
+}
+
+func indexOf(lines []string, s string) int {
+	for i, line := range lines {
+		if line == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,130 @@
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PDF page layout, in points on an A4 page
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 10
+	pdfLeading      = 14
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// Lines renders the evidence as plain text lines, one control and its
+// supporting data per block
+func (e *Evidence) Lines() []string {
+	result := "PASS"
+	if !e.Passed {
+		result = "FAIL"
+	}
+	frameworks := strings.Join(e.Frameworks, ", ")
+	if frameworks == "" {
+		frameworks = "(none)"
+	}
+
+	lines := []string{
+		"Compliance evidence",
+		"",
+		"Placement:     " + e.PlacementID,
+		"Resource type: " + e.ResourceType,
+		"Frameworks:    " + frameworks,
+		"Result:        " + result,
+		"Generated at:  " + e.GeneratedAt.Format(time.RFC3339),
+	}
+	for _, control := range e.Controls {
+		// Fields drops the gaps left by a control without an ID
+		header := fmt.Sprintf("[%s] %s %s %s", strings.ToUpper(control.Result), control.Framework, control.ControlID, control.Description)
+		lines = append(lines, "", strings.Join(strings.Fields(header), " "))
+		lines = append(lines, "    "+control.Evidence.Reason)
+		for _, r := range control.Evidence.Regions {
+			certified := strings.Join(r.Frameworks, ", ")
+			if certified == "" {
+				certified = "(none)"
+			}
+			lines = append(lines, fmt.Sprintf("    %s/%s certified for: %s", r.Provider, r.Region, certified))
+		}
+	}
+	return lines
+}
+
+// PDF renders the evidence as a PDF document of its text lines
+func (e *Evidence) PDF() []byte {
+	return renderPDF(e.Lines())
+}
+
+// renderPDF lays the lines out in Courier over as many pages as needed. Text
+// outside printable ASCII is replaced with '?', as the standard fonts are
+// used without embedding.
+func renderPDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// Objects 1-3 are the catalog, page tree and font; each page is then a
+	// page object followed by its content stream
+	objects := make([]string, 3, 3+2*len(pages))
+	objects[0] = "<< /Type /Catalog /Pages 2 0 R >>"
+	objects[2] = "<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>"
+
+	kids := make([]string, 0, len(pages))
+	for _, page := range pages {
+		pageObj := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return doc.Bytes()
+}
+
+// pdfEscape escapes a line for a PDF string literal
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > '~':
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
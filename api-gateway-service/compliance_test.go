@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"api-gateway-service/compliance"
+	"api-gateway-service/store"
+)
+
+func TestGetComplianceEvidence(t *testing.T) {
+	router := tenantRouter(t, "acme", "globex")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute",
+		`{"name":"web","vcpus":2,"memory_gb":8,"regions":["eu-west-1"],"excluded_providers":["azure","gcp"],"compliance_frameworks":["GDPR","SOC2"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status %d: %s", w.Code, w.Body)
+	}
+	var p store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+
+	w = callAs(router, "acme", http.MethodGet, "/api/v1/compliance/evidence?placement_id="+p.ID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var evidence compliance.Evidence
+	if err := json.Unmarshal(w.Body.Bytes(), &evidence); err != nil {
+		t.Fatal(err)
+	}
+	var controls []string
+	for _, c := range evidence.Controls {
+		controls = append(controls, c.Framework+" "+c.ControlID+" "+c.Result)
+	}
+	if want := []string{"GDPR Art.32 pass", "GDPR Art.44 pass", "SOC2 CC6.1 pass", "SOC2 CC7.2 pass"}; !reflect.DeepEqual(controls, want) {
+		t.Errorf("controls %v, want %v", controls, want)
+	}
+	if !evidence.Passed || evidence.PlacementID != p.ID {
+		t.Errorf("evidence for %s passed %v", evidence.PlacementID, evidence.Passed)
+	}
+	if regions := evidence.Controls[0].Evidence.Regions; len(regions) != 1 || regions[0].Region != "eu-west-1" {
+		t.Errorf("evidence regions %+v, want eu-west-1", regions)
+	}
+
+	w = callAs(router, "acme", http.MethodGet, "/api/v1/compliance/evidence?placement_id="+p.ID+"&format=pdf", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("pdf status %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("content type %q", ct)
+	}
+	if cd, want := w.Header().Get("Content-Disposition"), `attachment; filename="evidence-`+p.ID+`.pdf"`; cd != want {
+		t.Errorf("content disposition %q, want %q", cd, want)
+	}
+	if !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Errorf("body is not a PDF: %.20q", w.Body)
+	}
+
+	tests := []struct {
+		name       string
+		tenant     string
+		query      string
+		wantStatus int
+	}{
+		{name: "missing placement id", tenant: "acme", query: "", wantStatus: http.StatusBadRequest},
+		{name: "invalid format", tenant: "acme", query: "?placement_id=" + p.ID + "&format=csv", wantStatus: http.StatusBadRequest},
+		{name: "unknown placement", tenant: "acme", query: "?placement_id=plc-missing", wantStatus: http.StatusNotFound},
+		{name: "other tenant", tenant: "globex", query: "?placement_id=" + p.ID, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := callAs(router, tt.tenant, http.MethodGet, "/api/v1/compliance/evidence"+tt.query, ""); w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
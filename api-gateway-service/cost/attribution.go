@@ -0,0 +1,138 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExchangeRates converts amounts into a base currency. Rates holds the value
+// of one unit of each currency in the base currency; the base itself needs no
+// rate.
+type ExchangeRates struct {
+	Base  string
+	Rates map[string]float64
+}
+
+// Convert returns the amount in the base currency. Items without a currency
+// are taken to be in DefaultCurrency.
+func (r ExchangeRates) Convert(amount float64, currency string) (float64, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if strings.EqualFold(currency, r.Base) {
+		return amount, nil
+	}
+	rate, ok := r.Rates[strings.ToUpper(currency)]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no exchange rate from %s to %s", currency, r.Base)
+	}
+	return amount * rate, nil
+}
+
+// AttributedCost is the spend attributed to one value of the dimension
+type AttributedCost struct {
+	Value      string             `json:"value"`
+	Cost       float64            `json:"cost"`
+	Share      float64            `json:"share"`
+	ByProvider map[string]float64 `json:"by_provider"`
+}
+
+// Attribution is the spend of a period across all providers attributed to the
+// values of one tag, largest first. Spend without the tag is attributed to
+// "(untagged)".
+type Attribution struct {
+	Dimension   string             `json:"dimension"`
+	TotalCost   float64            `json:"total_cost"`
+	Currency    string             `json:"currency"`
+	PeriodStart time.Time          `json:"period_start"`
+	PeriodEnd   time.Time          `json:"period_end"`
+	ByProvider  map[string]float64 `json:"by_provider"`
+	Values      []AttributedCost   `json:"values"`
+}
+
+// Attribution attributes the line items matching the filter to the values of
+// the dimension tag, converting every amount to the base currency of rates
+func (s *Service) Attribution(ctx context.Context, f Filter, dimension string, rates ExchangeRates) (*Attribution, error) {
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension is required")
+	}
+	if rates.Base == "" {
+		rates.Base = DefaultCurrency
+	}
+
+	a := &Attribution{
+		Dimension:   dimension,
+		Currency:    strings.ToUpper(rates.Base),
+		PeriodStart: f.Start,
+		PeriodEnd:   f.End,
+		ByProvider:  make(map[string]float64),
+		Values:      make([]AttributedCost, 0),
+	}
+	values := make(map[string]*AttributedCost)
+	for _, item := range s.Items(ctx, f) {
+		amount, err := rates.Convert(item.Amount, item.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		key := dimensionValue(item.Tags, dimension)
+		v, ok := values[key]
+		if !ok {
+			v = &AttributedCost{Value: key, ByProvider: make(map[string]float64)}
+			values[key] = v
+		}
+		v.Cost += amount
+		v.ByProvider[item.Provider] += amount
+		a.ByProvider[item.Provider] += amount
+		a.TotalCost += amount
+	}
+
+	for _, v := range values {
+		if a.TotalCost > 0 {
+			v.Share = v.Cost / a.TotalCost
+		}
+		a.Values = append(a.Values, *v)
+	}
+	sort.Slice(a.Values, func(i, j int) bool {
+		if a.Values[i].Cost != a.Values[j].Cost {
+			return a.Values[i].Cost > a.Values[j].Cost
+		}
+		return a.Values[i].Value < a.Values[j].Value
+	})
+	return a, nil
+}
+
+// dimensionValue returns the value of the dimension tag. Providers spell the
+// same tag differently (AWS "CostCenter", GCP "cost_center" or "costcenter"),
+// so keys match ignoring case and separators, preferring an exact match.
+func dimensionValue(tags map[string]string, dimension string) string {
+	if value, ok := tags[dimension]; ok {
+		return value
+	}
+	want := foldTagKey(dimension)
+	var matches []string
+	for key := range tags {
+		if foldTagKey(key) == want {
+			matches = append(matches, key)
+		}
+	}
+	if len(matches) == 0 {
+		return untaggedKey
+	}
+	sort.Strings(matches)
+	return tags[matches[0]]
+}
+
+// foldTagKey lowercases a tag key and drops separators
+func foldTagKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', ' ', '.', ':':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
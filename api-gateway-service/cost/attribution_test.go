@@ -0,0 +1,114 @@
+package cost
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+func TestExchangeRatesConvert(t *testing.T) {
+	rates := ExchangeRates{Base: "USD", Rates: map[string]float64{"EUR": 1.1, "JPY": 0.0067}}
+	tests := []struct {
+		amount   float64
+		currency string
+		want     float64
+		wantErr  bool
+	}{
+		{amount: 10, currency: "USD", want: 10},
+		{amount: 10, currency: "usd", want: 10},
+		{amount: 10, currency: "", want: 10},
+		{amount: 10, currency: "eur", want: 11},
+		{amount: 1000, currency: "JPY", want: 6.7},
+		{amount: 10, currency: "GBP", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := rates.Convert(tt.amount, tt.currency)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Convert(%v, %q) error = %v, wantErr %v", tt.amount, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Convert(%v, %q) = %v, want %v", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+// Spend in several currencies across every provider is attributed to one
+// tag dimension, however each provider spells the tag
+func TestAttribution(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := NewService()
+	if err := s.Ingest(ctx, []LineItem{
+		{Date: day, Provider: "aws", Amount: 100, Currency: "USD", Tags: map[string]string{"CostCenter": "cc-1"}},
+		{Date: day, Provider: "azure", Amount: 100, Currency: "EUR", Tags: map[string]string{"cost-center": "cc-1"}},
+		{Date: day, Provider: "gcp", Amount: 50, Currency: "USD", Tags: map[string]string{"cost_center": "cc-2"}},
+		{Date: day, Provider: "gcp", Amount: 10000, Currency: "JPY", Tags: map[string]string{"team": "data"}},
+		{Date: day, Provider: "aws", Amount: 30, Tags: nil},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rates := ExchangeRates{Base: "usd", Rates: map[string]float64{"EUR": 1.1, "JPY": 0.0067}}
+
+	a, err := s.Attribution(ctx, Filter{}, "cost-center", rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	if a.Currency != "USD" || !approx(a.TotalCost, 357) {
+		t.Errorf("total %v %s, want 357 USD", a.TotalCost, a.Currency)
+	}
+	if !approx(a.ByProvider["aws"], 130) || !approx(a.ByProvider["azure"], 110) || !approx(a.ByProvider["gcp"], 117) {
+		t.Errorf("by provider %v, want aws 130, azure 110 and gcp 117", a.ByProvider)
+	}
+
+	want := []struct {
+		value      string
+		cost       float64
+		byProvider map[string]float64
+	}{
+		{value: "cc-1", cost: 210, byProvider: map[string]float64{"aws": 100, "azure": 110}},
+		{value: untaggedKey, cost: 97, byProvider: map[string]float64{"gcp": 67, "aws": 30}},
+		{value: "cc-2", cost: 50, byProvider: map[string]float64{"gcp": 50}},
+	}
+	if len(a.Values) != len(want) {
+		t.Fatalf("values %+v, want %d", a.Values, len(want))
+	}
+	share := 0.0
+	for i, w := range want {
+		got := a.Values[i]
+		if got.Value != w.value || !approx(got.Cost, w.cost) || !approx(got.Share, w.cost/357) {
+			t.Errorf("value %d = %+v, want %s costing %v", i, got, w.value, w.cost)
+		}
+		for provider, cost := range w.byProvider {
+			if !approx(got.ByProvider[provider], cost) {
+				t.Errorf("%s from %s = %v, want %v", w.value, provider, got.ByProvider[provider], cost)
+			}
+		}
+		share += got.Share
+	}
+	if !approx(share, 1) {
+		t.Errorf("shares add up to %v, want 1", share)
+	}
+}
+
+func TestAttributionErrors(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	s := NewService()
+	if err := s.Ingest(ctx, []LineItem{{Date: time.Now(), Provider: "azure", Amount: 10, Currency: "GBP"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Attribution(ctx, Filter{}, "", ExchangeRates{}); err == nil {
+		t.Error("attribution without a dimension succeeded")
+	}
+	if _, err := s.Attribution(ctx, Filter{}, "team", ExchangeRates{}); err == nil || !strings.Contains(err.Error(), "no exchange rate from GBP to USD") {
+		t.Errorf("error %v, want the missing rate", err)
+	}
+}
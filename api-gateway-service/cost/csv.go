@@ -0,0 +1,227 @@
+package cost
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVMapping names the CSV columns holding each line item field. Date,
+// Service and Amount must be present in the header; the other columns are
+// read when present.
+type CSVMapping struct {
+	Date       string
+	Service    string
+	Region     string
+	Amount     string
+	Currency   string
+	Provider   string
+	ResourceID string
+	// TagPrefix marks tag columns: a column named TagPrefix+key holds the
+	// value of tag key
+	TagPrefix string
+	// DateFormat is the Go time layout of the date column
+	DateFormat string
+}
+
+// DefaultCSVMapping returns the mapping for CSVs whose headers match the
+// line item JSON field names, with tags in tag:<key> columns
+func DefaultCSVMapping() CSVMapping {
+	return CSVMapping{
+		Date:       "date",
+		Service:    "service",
+		Region:     "region",
+		Amount:     "amount",
+		Currency:   "currency",
+		Provider:   "provider",
+		ResourceID: "resource_id",
+		TagPrefix:  "tag:",
+		DateFormat: "2006-01-02",
+	}
+}
+
+// CSVProvider reads line items from an exported billing CSV
+type CSVProvider struct {
+	r        io.Reader
+	mapping  CSVMapping
+	provider string
+}
+
+// NewCSVProvider creates a provider reading the CSV from r. provider is
+// recorded on rows without a provider column value.
+func NewCSVProvider(r io.Reader, mapping CSVMapping, provider string) *CSVProvider {
+	return &CSVProvider{
+		r:        r,
+		mapping:  mapping,
+		provider: provider,
+	}
+}
+
+// csvColumns holds the header index of each mapped column, or -1 when the
+// column is absent
+type csvColumns struct {
+	date, service, region, amount, currency, provider, resourceID int
+	tags                                                          map[int]string
+}
+
+// LineItems parses the CSV. Rows that are malformed or have invalid values
+// are skipped and reported by their line number; a missing or invalid header
+// fails the import.
+func (p *CSVProvider) LineItems(ctx context.Context) ([]LineItem, []RowError, error) {
+	r := csv.NewReader(p.r)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("CSV is empty")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	cols, err := p.columns(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]LineItem, 0)
+	var skipped []RowError
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, fmt.Errorf("failed to read CSV: %v", err)
+			}
+			skipped = append(skipped, RowError{Row: parseErr.StartLine, Error: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := r.FieldPos(0)
+		if len(record) != len(header) {
+			skipped = append(skipped, RowError{
+				Row:   line,
+				Error: fmt.Sprintf("expected %d fields, got %d", len(header), len(record)),
+			})
+			continue
+		}
+
+		item, err := p.lineItem(record, cols)
+		if err != nil {
+			skipped = append(skipped, RowError{Row: line, Error: err.Error()})
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, skipped, nil
+}
+
+// columns resolves the mapping against the header
+func (p *CSVProvider) columns(header []string) (csvColumns, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	lookup := func(name string) int {
+		if i, ok := index[name]; ok && name != "" {
+			return i
+		}
+		return -1
+	}
+
+	cols := csvColumns{
+		date:       lookup(p.mapping.Date),
+		service:    lookup(p.mapping.Service),
+		region:     lookup(p.mapping.Region),
+		amount:     lookup(p.mapping.Amount),
+		currency:   lookup(p.mapping.Currency),
+		provider:   lookup(p.mapping.Provider),
+		resourceID: lookup(p.mapping.ResourceID),
+		tags:       make(map[int]string),
+	}
+	required := []struct {
+		name  string
+		index int
+	}{
+		{p.mapping.Date, cols.date},
+		{p.mapping.Service, cols.service},
+		{p.mapping.Amount, cols.amount},
+	}
+	for _, col := range required {
+		if col.index < 0 {
+			return cols, fmt.Errorf("CSV header has no %q column", col.name)
+		}
+	}
+
+	if p.mapping.TagPrefix != "" {
+		for i, name := range header {
+			name = strings.TrimSpace(name)
+			if key := strings.TrimPrefix(name, p.mapping.TagPrefix); key != name && key != "" {
+				cols.tags[i] = key
+			}
+		}
+	}
+	return cols, nil
+}
+
+// lineItem converts a record into a line item
+func (p *CSVProvider) lineItem(record []string, cols csvColumns) (LineItem, error) {
+	field := func(i int) string {
+		if i < 0 {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	date, err := time.Parse(p.mapping.DateFormat, field(cols.date))
+	if err != nil {
+		return LineItem{}, fmt.Errorf("invalid date %q", field(cols.date))
+	}
+
+	amount, err := strconv.ParseFloat(field(cols.amount), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return LineItem{}, fmt.Errorf("invalid amount %q", field(cols.amount))
+	}
+
+	service := field(cols.service)
+	if service == "" {
+		return LineItem{}, fmt.Errorf("service is empty")
+	}
+
+	item := LineItem{
+		Date:       truncateDay(date),
+		Provider:   field(cols.provider),
+		Service:    service,
+		Region:     field(cols.region),
+		ResourceID: field(cols.resourceID),
+		Amount:     amount,
+		Currency:   strings.ToUpper(field(cols.currency)),
+	}
+	if item.Provider == "" {
+		item.Provider = p.provider
+	}
+
+	for i, key := range cols.tags {
+		if v := field(i); v != "" {
+			if item.Tags == nil {
+				item.Tags = make(map[string]string)
+			}
+			item.Tags[key] = v
+		}
+	}
+	return item, nil
+}
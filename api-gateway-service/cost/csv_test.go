@@ -0,0 +1,92 @@
+package cost
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVProviderLineItems(t *testing.T) {
+	data := strings.Join([]string{
+		"date,service,region,amount,currency,resource_id,tag:team",
+		"2026-09-01,ec2,us-east-1,12.50,usd,i-1,web",
+		"2026-09-01,s3,us-east-1,not-a-number,USD,b-1,",
+		"2026-09-02,ec2,us-east-1,13",
+		`2026-09-02,ec"2,us-east-1,13,USD,i-1,web`,
+		"09/02/2026,ec2,us-east-1,13,USD,i-1,web",
+		"2026-09-03,s3,eu-west-1,4,USD,b-2,",
+	}, "\n")
+
+	items, skipped, err := NewCSVProvider(strings.NewReader(data), DefaultCSVMapping(), "aws").LineItems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []LineItem{
+		{Date: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), Provider: "aws", Service: "ec2", Region: "us-east-1", ResourceID: "i-1", Amount: 12.5, Currency: "USD", Tags: map[string]string{"team": "web"}},
+		{Date: time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC), Provider: "aws", Service: "s3", Region: "eu-west-1", ResourceID: "b-2", Amount: 4, Currency: "USD"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items %+v, want %+v", items, want)
+	}
+
+	wantRows := []int{3, 4, 5, 6}
+	if len(skipped) != len(wantRows) {
+		t.Fatalf("skipped %+v, want rows %v", skipped, wantRows)
+	}
+	for i, row := range wantRows {
+		if skipped[i].Row != row || skipped[i].Error == "" {
+			t.Errorf("skipped[%d] = %+v, want row %d with its error", i, skipped[i], row)
+		}
+	}
+}
+
+func TestCSVProviderMapping(t *testing.T) {
+	data := "Usage Date,Product,Cost,Cloud\n01/09/2026,Compute Engine,7.25,gcp\n"
+	mapping := CSVMapping{Date: "Usage Date", Service: "Product", Amount: "Cost", Provider: "Cloud", DateFormat: "02/01/2006"}
+
+	items, skipped, err := NewCSVProvider(strings.NewReader(data), mapping, "").LineItems(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 || len(items) != 1 {
+		t.Fatalf("items %+v and skipped %+v, want one item", items, skipped)
+	}
+	if got := items[0]; got.Service != "Compute Engine" || got.Provider != "gcp" || got.Amount != 7.25 || got.Date.Day() != 1 || got.Date.Month() != time.September {
+		t.Errorf("item %+v, want the mapped columns", got)
+	}
+}
+
+func TestCSVProviderInvalidHeader(t *testing.T) {
+	for _, data := range []string{"", "date,region,amount\n2026-09-01,us-east-1,1\n"} {
+		if _, _, err := NewCSVProvider(strings.NewReader(data), DefaultCSVMapping(), "aws").LineItems(context.Background()); err == nil {
+			t.Errorf("LineItems(%q) succeeded, want a header error", data)
+		}
+	}
+}
+
+// Imported line items are available to the cost summaries
+func TestImportCSV(t *testing.T) {
+	data := "date,service,amount\n2026-09-01,ec2,10\n2026-09-02,ec2,oops\n2026-09-02,s3,5\n"
+	s := NewService()
+	report, err := s.Import(context.Background(), NewCSVProvider(strings.NewReader(data), DefaultCSVMapping(), "aws"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Imported != 2 || len(report.Skipped) != 1 || report.Skipped[0].Row != 3 {
+		t.Errorf("report %+v, want 2 imported and row 3 skipped", report)
+	}
+
+	summary, err := s.Summary(context.Background(), Filter{
+		Start: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}, GroupByService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.TotalCost != 15 || len(summary.Groups) != 2 {
+		t.Errorf("summary %+v, want 15 across ec2 and s3", summary)
+	}
+}
@@ -0,0 +1,169 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTopIncreases is how many of the largest increases a diff reports
+const DefaultTopIncreases = 5
+
+// Period layouts accepted by ParsePeriod
+const (
+	monthLayout = "2006-01"
+	dayLayout   = "2006-01-02"
+)
+
+// GroupDelta is the change in cost of one group between two periods.
+// PercentChange is nil when the group had no cost in the earlier period.
+type GroupDelta struct {
+	Key           string   `json:"key"`
+	FromCost      float64  `json:"from_cost"`
+	ToCost        float64  `json:"to_cost"`
+	Delta         float64  `json:"delta"`
+	PercentChange *float64 `json:"percent_change"`
+}
+
+// Diff compares the cost of two periods grouped along one dimension. Added
+// and Removed list the groups present in only the later or earlier period.
+type Diff struct {
+	GroupBy            string       `json:"group_by"`
+	Currency           string       `json:"currency"`
+	FromStart          time.Time    `json:"from_start"`
+	FromEnd            time.Time    `json:"from_end"`
+	ToStart            time.Time    `json:"to_start"`
+	ToEnd              time.Time    `json:"to_end"`
+	FromTotal          float64      `json:"from_total"`
+	ToTotal            float64      `json:"to_total"`
+	TotalDelta         float64      `json:"total_delta"`
+	TotalPercentChange *float64     `json:"total_percent_change"`
+	Groups             []GroupDelta `json:"groups"`
+	Added              []string     `json:"added"`
+	Removed            []string     `json:"removed"`
+	TopIncreases       []GroupDelta `json:"top_increases"`
+}
+
+// ParsePeriod parses a period as a calendar month (YYYY-MM), a single day
+// (YYYY-MM-DD) or an inclusive day range (YYYY-MM-DD..YYYY-MM-DD) and returns
+// its [start, end) bounds in UTC
+func ParsePeriod(period string) (time.Time, time.Time, error) {
+	if month, err := time.Parse(monthLayout, period); err == nil {
+		return month, month.AddDate(0, 1, 0), nil
+	}
+
+	first, last, isRange := strings.Cut(period, "..")
+	if !isRange {
+		last = first
+	}
+
+	start, err := time.Parse(dayLayout, first)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s (expected YYYY-MM, YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD)", period)
+	}
+	end, err := time.Parse(dayLayout, last)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s (expected YYYY-MM, YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD)", period)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period: %s (end is before start)", period)
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// Diff compares the cost of the from and to periods by the given grouping,
+// reporting at most top of the largest increases
+func (s *Service) Diff(ctx context.Context, from, to Filter, groupBy string, top int) (*Diff, error) {
+	if top < 0 {
+		return nil, fmt.Errorf("top must not be negative")
+	}
+
+	before, err := s.Summary(ctx, from, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.Summary(ctx, to, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Diff{
+		GroupBy:            groupBy,
+		Currency:           after.Currency,
+		FromStart:          from.Start,
+		FromEnd:            from.End,
+		ToStart:            to.Start,
+		ToEnd:              to.End,
+		FromTotal:          before.TotalCost,
+		ToTotal:            after.TotalCost,
+		TotalDelta:         after.TotalCost - before.TotalCost,
+		TotalPercentChange: percentChange(before.TotalCost, after.TotalCost),
+		Groups:             make([]GroupDelta, 0),
+		Added:              make([]string, 0),
+		Removed:            make([]string, 0),
+		TopIncreases:       make([]GroupDelta, 0),
+	}
+
+	fromCosts := make(map[string]float64, len(before.Groups))
+	for _, g := range before.Groups {
+		fromCosts[g.Key] = g.Cost
+	}
+	toCosts := make(map[string]float64, len(after.Groups))
+	for _, g := range after.Groups {
+		toCosts[g.Key] = g.Cost
+		if _, ok := fromCosts[g.Key]; !ok {
+			d.Added = append(d.Added, g.Key)
+		}
+	}
+	for _, g := range before.Groups {
+		if _, ok := toCosts[g.Key]; !ok {
+			d.Removed = append(d.Removed, g.Key)
+		}
+	}
+
+	keys := make(map[string]bool, len(fromCosts)+len(toCosts))
+	for key := range fromCosts {
+		keys[key] = true
+	}
+	for key := range toCosts {
+		keys[key] = true
+	}
+	for key := range keys {
+		d.Groups = append(d.Groups, GroupDelta{
+			Key:           key,
+			FromCost:      fromCosts[key],
+			ToCost:        toCosts[key],
+			Delta:         toCosts[key] - fromCosts[key],
+			PercentChange: percentChange(fromCosts[key], toCosts[key]),
+		})
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Slice(d.Groups, func(i, j int) bool {
+		if d.Groups[i].Delta != d.Groups[j].Delta {
+			return d.Groups[i].Delta > d.Groups[j].Delta
+		}
+		return d.Groups[i].Key < d.Groups[j].Key
+	})
+
+	for _, g := range d.Groups {
+		if len(d.TopIncreases) == top || g.Delta <= 0 {
+			break
+		}
+		d.TopIncreases = append(d.TopIncreases, g)
+	}
+	return d, nil
+}
+
+// percentChange returns the change from before to after as a percentage, or
+// nil when before is zero
+func percentChange(before, after float64) *float64 {
+	if before == 0 {
+		return nil
+	}
+	p := (after - before) / before * 100
+	return &p
+}
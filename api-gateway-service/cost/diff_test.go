@@ -0,0 +1,96 @@
+package cost
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		period    string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{period: "2026-09", wantStart: day(1), wantEnd: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{period: "2026-09-15", wantStart: day(15), wantEnd: day(16)},
+		{period: "2026-09-01..2026-09-07", wantStart: day(1), wantEnd: day(8)},
+		{period: "2026-09-07..2026-09-01", wantErr: true},
+		{period: "September", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			start, end, err := ParsePeriod(tt.period)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePeriod(%s) error = %v, want error %v", tt.period, err, tt.wantErr)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("ParsePeriod(%s) = [%v, %v), want [%v, %v)", tt.period, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	aug := time.Date(2026, 8, 10, 0, 0, 0, 0, time.UTC)
+	sep := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	s := NewService()
+	err := s.Ingest(context.Background(), []LineItem{
+		{Date: aug, Provider: "aws", Service: "ec2", Amount: 100, Currency: "USD"},
+		{Date: aug, Provider: "aws", Service: "s3", Amount: 50, Currency: "USD"},
+		{Date: aug, Provider: "aws", Service: "rds", Amount: 40, Currency: "USD"},
+		{Date: sep, Provider: "aws", Service: "ec2", Amount: 130, Currency: "USD"},
+		{Date: sep, Provider: "aws", Service: "s3", Amount: 20, Currency: "USD"},
+		{Date: sep, Provider: "aws", Service: "lambda", Amount: 10, Currency: "USD"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var from, to Filter
+	from.Start, from.End, _ = ParsePeriod("2026-08")
+	to.Start, to.End, _ = ParsePeriod("2026-09")
+	d, err := s.Diff(context.Background(), from, to, GroupByService, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.FromTotal != 190 || d.ToTotal != 160 || d.TotalDelta != -30 {
+		t.Errorf("totals %v -> %v (%v), want 190 -> 160 (-30)", d.FromTotal, d.ToTotal, d.TotalDelta)
+	}
+	if !reflect.DeepEqual(d.Added, []string{"lambda"}) || !reflect.DeepEqual(d.Removed, []string{"rds"}) {
+		t.Errorf("added %v and removed %v, want [lambda] and [rds]", d.Added, d.Removed)
+	}
+
+	pct := func(v float64) *float64 { return &v }
+	want := map[string]struct {
+		delta   float64
+		percent *float64
+	}{
+		"ec2":    {delta: 30, percent: pct(30)},
+		"lambda": {delta: 10},
+		"s3":     {delta: -30, percent: pct(-60)},
+		"rds":    {delta: -40, percent: pct(-100)},
+	}
+	if len(d.Groups) != len(want) {
+		t.Fatalf("%d groups, want %d: %+v", len(d.Groups), len(want), d.Groups)
+	}
+	for _, g := range d.Groups {
+		w := want[g.Key]
+		if g.Delta != w.delta {
+			t.Errorf("group %s delta %v, want %v", g.Key, g.Delta, w.delta)
+		}
+		if (g.PercentChange == nil) != (w.percent == nil) || (w.percent != nil && math.Abs(*g.PercentChange-*w.percent) > 1e-9) {
+			t.Errorf("group %s percent change %v, want %v", g.Key, g.PercentChange, w.percent)
+		}
+	}
+
+	if len(d.TopIncreases) != 1 || d.TopIncreases[0].Key != "ec2" {
+		t.Errorf("top increases %+v, want only ec2", d.TopIncreases)
+	}
+}
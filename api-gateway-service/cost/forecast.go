@@ -0,0 +1,245 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxHorizonDays caps how far ahead a forecast may project
+const MaxHorizonDays = 365
+
+// Forecast models
+const (
+	// ModelLinear fits a least-squares trend line to the daily totals
+	ModelLinear = "linear"
+	// ModelEMA projects the exponential moving average of the daily totals
+	// flat, so a single spike moves the forecast by at most alpha times the
+	// spike
+	ModelEMA = "ema"
+)
+
+// ConfidenceLevel is the coverage of a forecast's lower and upper bounds, and
+// confidenceZ the matching quantile of the standard normal distribution
+const (
+	ConfidenceLevel = 0.95
+	confidenceZ     = 1.959964
+)
+
+// DefaultAlpha is the EMA smoothing factor used when none is given. Alpha must
+// be in (0, 1]; lower values smooth more and 1 disables smoothing.
+const DefaultAlpha = 0.3
+
+// ForecastOptions selects the forecast model. Smoothing applies an EMA with
+// Alpha to the daily totals before any model is fitted; Alpha also sets the
+// smoothing of ModelEMA.
+type ForecastOptions struct {
+	Model     string
+	Alpha     float64
+	Smoothing bool
+}
+
+// Validate checks the model and alpha, defaulting an empty model to
+// ModelLinear and a zero alpha to DefaultAlpha
+func (o *ForecastOptions) Validate() error {
+	switch o.Model {
+	case "":
+		o.Model = ModelLinear
+	case ModelLinear, ModelEMA:
+	default:
+		return fmt.Errorf("invalid model: %s (must be %s or %s)", o.Model, ModelLinear, ModelEMA)
+	}
+
+	if o.Alpha == 0 {
+		o.Alpha = DefaultAlpha
+	}
+	if !(o.Alpha > 0 && o.Alpha <= 1) {
+		return fmt.Errorf("invalid alpha: %g (must be greater than 0 and at most 1)", o.Alpha)
+	}
+	return nil
+}
+
+// DailyCost is the cost of a single day
+type DailyCost struct {
+	Date time.Time `json:"date"`
+	Cost float64   `json:"cost"`
+}
+
+// Forecast projects daily costs beyond the end of the history period
+type Forecast struct {
+	Model string `json:"model"`
+	// Alpha is the EMA smoothing factor, set when the model or smoothing used it
+	Alpha         float64     `json:"alpha,omitempty"`
+	Smoothed      bool        `json:"smoothed"`
+	Currency      string      `json:"currency"`
+	HorizonDays   int         `json:"horizon_days"`
+	HistoryDays   int         `json:"history_days"`
+	ForecastTotal float64     `json:"forecast_total"`
+	Daily         []DailyCost `json:"daily"`
+	// Lower and Upper bound each day's forecast at ConfidenceLevel, from the
+	// spread of the history around the fitted model
+	ConfidenceLevel float64     `json:"confidence_level"`
+	Lower           []DailyCost `json:"lower"`
+	Upper           []DailyCost `json:"upper"`
+	// History is the daily totals the forecast was projected from. It is
+	// left out of the JSON forecast but included in its time series.
+	History []DailyCost `json:"-"`
+}
+
+// Forecast projects the daily cost for horizonDays days after f.End from the
+// daily totals of the line items matching the filter. f.Start and f.End must
+// be set.
+func (s *Service) Forecast(ctx context.Context, f Filter, horizonDays int, opts ForecastOptions) (*Forecast, error) {
+	if horizonDays < 1 || horizonDays > MaxHorizonDays {
+		return nil, fmt.Errorf("horizon must be between 1 and %d days", MaxHorizonDays)
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if f.Start.IsZero() || f.End.IsZero() || !f.Start.Before(f.End) {
+		return nil, fmt.Errorf("forecast requires a history period with start before end")
+	}
+
+	items := s.Items(ctx, f)
+	history := DailyTotals(items, f.Start, f.End)
+	series := history
+	if opts.Smoothing {
+		series = smoothEMA(history, opts.Alpha)
+	}
+
+	var slope, intercept, spread float64
+	switch opts.Model {
+	case ModelEMA:
+		// A flat projection of the last smoothed level
+		smoothed := smoothEMA(series, opts.Alpha)
+		if len(smoothed) > 0 {
+			intercept = smoothed[len(smoothed)-1].Cost
+		}
+		// Each smoothed level predicts the next day's cost
+		var residuals []float64
+		for i := 1; i < len(series); i++ {
+			residuals = append(residuals, series[i].Cost-smoothed[i-1].Cost)
+		}
+		spread = residualStdDev(residuals, 1)
+	default:
+		slope, intercept = linearFit(series)
+		residuals := make([]float64, len(series))
+		for i, d := range series {
+			residuals[i] = d.Cost - (intercept + slope*float64(i))
+		}
+		spread = residualStdDev(residuals, 2)
+	}
+
+	forecast := &Forecast{
+		Model:       opts.Model,
+		Smoothed:    opts.Smoothing,
+		Currency:    currency(items),
+		HorizonDays: horizonDays,
+		HistoryDays: len(history),
+		Daily:       make([]DailyCost, horizonDays),
+
+		ConfidenceLevel: ConfidenceLevel,
+		Lower:           make([]DailyCost, horizonDays),
+		Upper:           make([]DailyCost, horizonDays),
+		History:         history,
+	}
+	end := truncateDay(f.End)
+	margin := confidenceZ * spread
+	for i := 0; i < horizonDays; i++ {
+		date := end.AddDate(0, 0, i)
+		cost := intercept + slope*float64(len(history)+i)
+		forecast.Daily[i] = DailyCost{Date: date, Cost: nonNegative(cost)}
+		forecast.Lower[i] = DailyCost{Date: date, Cost: nonNegative(cost - margin)}
+		forecast.Upper[i] = DailyCost{Date: date, Cost: nonNegative(cost + margin)}
+		forecast.ForecastTotal += forecast.Daily[i].Cost
+	}
+	if opts.Model == ModelEMA || opts.Smoothing {
+		forecast.Alpha = opts.Alpha
+	}
+	return forecast, nil
+}
+
+// smoothEMA returns the exponential moving average of the daily costs, seeded
+// with the first day's cost
+func smoothEMA(days []DailyCost, alpha float64) []DailyCost {
+	smoothed := make([]DailyCost, len(days))
+	for i, d := range days {
+		smoothed[i] = d
+		if i > 0 {
+			smoothed[i].Cost = alpha*d.Cost + (1-alpha)*smoothed[i-1].Cost
+		}
+	}
+	return smoothed
+}
+
+// DailyTotals sums the line items per day over [start, end). Days without
+// line items are reported with zero cost.
+func DailyTotals(items []LineItem, start, end time.Time) []DailyCost {
+	start, end = truncateDay(start), truncateDay(end)
+
+	byDay := make(map[time.Time]float64)
+	for _, item := range items {
+		byDay[truncateDay(item.Date)] += item.Amount
+	}
+
+	var totals []DailyCost
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		totals = append(totals, DailyCost{Date: day, Cost: byDay[day]})
+	}
+	return totals
+}
+
+// linearFit returns the least-squares slope and intercept of the daily costs
+// against their day index
+func linearFit(days []DailyCost) (slope, intercept float64) {
+	n := float64(len(days))
+	if n == 0 {
+		return 0, 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	for i, d := range days {
+		x := float64(i)
+		sumX += x
+		sumY += d.Cost
+		sumXY += x * d.Cost
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, sumY / n
+	}
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept = (sumY - slope*sumX) / n
+	return slope, intercept
+}
+
+// residualStdDev returns the standard deviation of the residuals of a model
+// with params fitted parameters, or 0 when there are too few residuals to
+// estimate it
+func residualStdDev(residuals []float64, params int) float64 {
+	if len(residuals) <= params {
+		return 0
+	}
+	var sum float64
+	for _, r := range residuals {
+		sum += r * r
+	}
+	return math.Sqrt(sum / float64(len(residuals)-params))
+}
+
+// nonNegative returns cost, or 0 when it is negative
+func nonNegative(cost float64) float64 {
+	if cost < 0 {
+		return 0
+	}
+	return cost
+}
+
+// truncateDay returns midnight UTC of the day containing t
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
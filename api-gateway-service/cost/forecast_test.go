@@ -0,0 +1,195 @@
+package cost
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+var forecastStart = time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+// forecastService returns a service with one line item per day of costs,
+// starting at forecastStart, and the filter covering those days
+func forecastService(t *testing.T, costs []float64) (*Service, Filter) {
+	t.Helper()
+	items := make([]LineItem, len(costs))
+	for i, c := range costs {
+		items[i] = LineItem{Date: forecastStart.AddDate(0, 0, i).Add(6 * time.Hour), Provider: "aws", Amount: c, Currency: "USD"}
+	}
+	s := NewService()
+	if err := s.Ingest(context.Background(), items); err != nil {
+		t.Fatal(err)
+	}
+	return s, Filter{Start: forecastStart, End: forecastStart.AddDate(0, 0, len(costs))}
+}
+
+func costs(days []DailyCost) []float64 {
+	out := make([]float64, len(days))
+	for i, d := range days {
+		out[i] = d.Cost
+	}
+	return out
+}
+
+func approxEqual(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestForecastModels(t *testing.T) {
+	tests := []struct {
+		name      string
+		history   []float64
+		horizon   int
+		opts      ForecastOptions
+		want      []float64
+		wantAlpha float64
+		// wantExactFit is set when the model fits the history without error,
+		// so the bounds equal the forecast
+		wantExactFit bool
+	}{
+		{
+			name:         "linear trend is extended",
+			history:      []float64{10, 12, 14, 16},
+			horizon:      2,
+			want:         []float64{18, 20},
+			wantExactFit: true,
+		},
+		{
+			name:         "linear forecast never drops below zero",
+			history:      []float64{6, 4, 2},
+			horizon:      2,
+			opts:         ForecastOptions{Model: ModelLinear},
+			want:         []float64{0, 0},
+			wantExactFit: true,
+		},
+		{
+			name:         "flat history forecasts the same cost",
+			history:      []float64{5, 5, 5},
+			horizon:      3,
+			opts:         ForecastOptions{Model: ModelEMA},
+			want:         []float64{5, 5, 5},
+			wantAlpha:    DefaultAlpha,
+			wantExactFit: true,
+		},
+		{
+			name:      "EMA moves by at most alpha times a spike",
+			history:   []float64{10, 10, 10, 20},
+			horizon:   2,
+			opts:      ForecastOptions{Model: ModelEMA, Alpha: 0.5},
+			want:      []float64{15, 15},
+			wantAlpha: 0.5,
+		},
+		{
+			name:      "EMA with alpha 1 projects the last day",
+			history:   []float64{10, 30, 20},
+			horizon:   1,
+			opts:      ForecastOptions{Model: ModelEMA, Alpha: 1},
+			want:      []float64{20},
+			wantAlpha: 1,
+		},
+		{
+			name:    "smoothing with alpha 1 leaves the linear fit unchanged",
+			history: []float64{10, 12, 14, 16},
+			horizon: 1,
+			opts:    ForecastOptions{Model: ModelLinear, Alpha: 1, Smoothing: true},
+			want:    []float64{18},
+			// Alpha is reported since the smoothing used it
+			wantAlpha:    1,
+			wantExactFit: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, f := forecastService(t, tt.history)
+			forecast, err := s.Forecast(context.Background(), f, tt.horizon, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := costs(forecast.Daily); !approxEqual(got, tt.want) {
+				t.Errorf("daily forecast = %v, want %v", got, tt.want)
+			}
+			if forecast.Alpha != tt.wantAlpha {
+				t.Errorf("alpha = %g, want %g", forecast.Alpha, tt.wantAlpha)
+			}
+			if forecast.HistoryDays != len(tt.history) || len(forecast.Daily) != tt.horizon {
+				t.Errorf("history %d days and forecast %d days, want %d and %d", forecast.HistoryDays, len(forecast.Daily), len(tt.history), tt.horizon)
+			}
+			if first := forecast.Daily[0].Date; !first.Equal(f.End) {
+				t.Errorf("forecast starts on %s, want %s", first, f.End)
+			}
+
+			var total float64
+			for i, d := range forecast.Daily {
+				total += d.Cost
+				lower, upper := forecast.Lower[i].Cost, forecast.Upper[i].Cost
+				if lower > d.Cost || upper < d.Cost {
+					t.Errorf("day %d: %g outside its bounds [%g, %g]", i, d.Cost, lower, upper)
+				}
+				if exact := lower == d.Cost && upper == d.Cost; exact != tt.wantExactFit {
+					t.Errorf("day %d: bounds [%g, %g] around %g, want exact %v", i, lower, upper, d.Cost, tt.wantExactFit)
+				}
+			}
+			if math.Abs(total-forecast.ForecastTotal) > 1e-9 {
+				t.Errorf("forecast total = %g, want the sum of the days %g", forecast.ForecastTotal, total)
+			}
+		})
+	}
+}
+
+func TestForecastValidation(t *testing.T) {
+	s, f := forecastService(t, []float64{1, 2, 3})
+	tests := []struct {
+		name    string
+		filter  Filter
+		horizon int
+		opts    ForecastOptions
+	}{
+		{name: "no horizon", filter: f, horizon: 0},
+		{name: "horizon too far", filter: f, horizon: MaxHorizonDays + 1},
+		{name: "unknown model", filter: f, horizon: 1, opts: ForecastOptions{Model: "arima"}},
+		{name: "alpha above 1", filter: f, horizon: 1, opts: ForecastOptions{Alpha: 1.5}},
+		{name: "negative alpha", filter: f, horizon: 1, opts: ForecastOptions{Alpha: -0.1}},
+		{name: "no history period", filter: Filter{}, horizon: 1},
+		{name: "history period ending before it starts", filter: Filter{Start: f.End, End: f.Start}, horizon: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Forecast(context.Background(), tt.filter, tt.horizon, tt.opts); err == nil {
+				t.Error("Forecast succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestDailyTotals(t *testing.T) {
+	day := func(n int) time.Time { return forecastStart.AddDate(0, 0, n) }
+	items := []LineItem{
+		{Date: day(0).Add(time.Hour), Amount: 1},
+		{Date: day(0).Add(20 * time.Hour), Amount: 2},
+		{Date: day(2), Amount: 4},
+		// Outside the period
+		{Date: day(3), Amount: 8},
+	}
+
+	got := DailyTotals(items, day(0), day(3))
+	if want := []float64{3, 0, 4}; !approxEqual(costs(got), want) {
+		t.Errorf("daily totals = %v, want %v", costs(got), want)
+	}
+	for i, d := range got {
+		if !d.Date.Equal(day(i)) {
+			t.Errorf("day %d dated %s, want %s", i, d.Date, day(i))
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package cost aggregates, summarizes and forecasts cloud cost data.
+package cost
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultCurrency is reported when no line items carry a currency
+const DefaultCurrency = "USD"
+
+// Supported summary groupings. Tags are grouped with "tag:<key>".
+const (
+	GroupByService  = "service"
+	GroupByRegion   = "region"
+	GroupByProvider = "provider"
+	groupByTag      = "tag:"
+)
+
+// untaggedKey groups line items that lack the requested tag
+const untaggedKey = "(untagged)"
+
+// LineItem is a single cost record for one resource over an hour or day, or
+// a longer period once rolled up by a retention policy
+type LineItem struct {
+	Date       time.Time         `json:"date"`
+	Provider   string            `json:"provider"`
+	Service    string            `json:"service"`
+	Region     string            `json:"region"`
+	ResourceID string            `json:"resource_id,omitempty"`
+	Amount     float64           `json:"amount"`
+	Currency   string            `json:"currency"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	// Granularity is set on items rolled up by a retention policy
+	Granularity string `json:"granularity,omitempty"`
+}
+
+// Filter selects line items within [Start, End) and optionally by provider
+// and region
+type Filter struct {
+	Start    time.Time
+	End      time.Time
+	Provider string
+	Region   string
+}
+
+// Matches reports whether the line item satisfies the filter
+func (f Filter) Matches(item LineItem) bool {
+	if !f.Start.IsZero() && item.Date.Before(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && !item.Date.Before(f.End) {
+		return false
+	}
+	if f.Provider != "" && item.Provider != f.Provider {
+		return false
+	}
+	if f.Region != "" && item.Region != f.Region {
+		return false
+	}
+	return true
+}
+
+// Breakdown totals costs along several dimensions
+type Breakdown struct {
+	ByService map[string]float64 `json:"by_service"`
+	ByRegion  map[string]float64 `json:"by_region"`
+	ByTag     map[string]float64 `json:"by_tag"`
+}
+
+// Analysis is the cost of the line items in a period
+type Analysis struct {
+	TotalCost   float64    `json:"total_cost"`
+	Currency    string     `json:"currency"`
+	PeriodStart time.Time  `json:"period_start"`
+	PeriodEnd   time.Time  `json:"period_end"`
+	Breakdown   Breakdown  `json:"breakdown"`
+	Items       []LineItem `json:"items"`
+}
+
+// GroupCost is the total cost of one summary group
+type GroupCost struct {
+	Key   string  `json:"key"`
+	Cost  float64 `json:"cost"`
+	Share float64 `json:"share"`
+}
+
+// Summary is the cost of a period grouped along one dimension, largest first
+type Summary struct {
+	GroupBy     string      `json:"group_by"`
+	TotalCost   float64     `json:"total_cost"`
+	Currency    string      `json:"currency"`
+	PeriodStart time.Time   `json:"period_start"`
+	PeriodEnd   time.Time   `json:"period_end"`
+	Groups      []GroupCost `json:"groups"`
+}
+
+// ValidateGroupBy checks that groupBy names a supported grouping
+func ValidateGroupBy(groupBy string) error {
+	switch groupBy {
+	case GroupByService, GroupByRegion, GroupByProvider:
+		return nil
+	}
+	if strings.HasPrefix(groupBy, groupByTag) && len(groupBy) > len(groupByTag) {
+		return nil
+	}
+	return fmt.Errorf("invalid group_by: %s (must be service, region, provider or tag:<key>)", groupBy)
+}
+
+// groupKey returns the key of the item under the grouping
+func groupKey(item LineItem, groupBy string) string {
+	switch groupBy {
+	case GroupByService:
+		return item.Service
+	case GroupByRegion:
+		return item.Region
+	case GroupByProvider:
+		return item.Provider
+	}
+
+	if value, ok := item.Tags[strings.TrimPrefix(groupBy, groupByTag)]; ok {
+		return value
+	}
+	return untaggedKey
+}
@@ -0,0 +1,44 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+)
+
+// Provider supplies cost line items from a billing source, such as a billing
+// API or an exported file
+type Provider interface {
+	// LineItems returns the source's line items. Records that cannot be read
+	// are reported as skipped rather than failing the whole fetch.
+	LineItems(ctx context.Context) ([]LineItem, []RowError, error)
+}
+
+// RowError describes a source record that was skipped
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportReport summarizes an import from a provider
+type ImportReport struct {
+	Imported int        `json:"imported"`
+	Skipped  []RowError `json:"skipped"`
+}
+
+// Import fetches the provider's line items and ingests them for the caller's
+// tenant
+func (s *Service) Import(ctx context.Context, p Provider) (*ImportReport, error) {
+	items, skipped, err := p.LineItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Ingest(ctx, items); err != nil {
+		return nil, fmt.Errorf("failed to ingest line items: %v", err)
+	}
+
+	if skipped == nil {
+		skipped = make([]RowError, 0)
+	}
+	return &ImportReport{Imported: len(items), Skipped: skipped}, nil
+}
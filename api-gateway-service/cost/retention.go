@@ -0,0 +1,138 @@
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Granularities of rolled-up line items. Ingested items have no granularity
+// and may be hourly or daily.
+const (
+	GranularityDaily   = "daily"
+	GranularityMonthly = "monthly"
+)
+
+// granularityRank orders granularities from finest to coarsest
+var granularityRank = map[string]int{"": 0, GranularityDaily: 1, GranularityMonthly: 2}
+
+// RetentionPolicy sets when line items are rolled up into coarser ones.
+// Items older than DailyAfter are summed into one item per day, and items
+// older than MonthlyAfter into one item per month; zero disables a tier.
+// Only whole days and months older than the threshold are rolled up.
+type RetentionPolicy struct {
+	DailyAfter   time.Duration
+	MonthlyAfter time.Duration
+}
+
+// Validate checks that the monthly threshold, when set, is after the daily one
+func (p RetentionPolicy) Validate() error {
+	if p.DailyAfter < 0 || p.MonthlyAfter < 0 {
+		return fmt.Errorf("retention thresholds must not be negative")
+	}
+	if p.DailyAfter > 0 && p.MonthlyAfter > 0 && p.MonthlyAfter < p.DailyAfter {
+		return fmt.Errorf("monthly rollup threshold %s is before the daily threshold %s", p.MonthlyAfter, p.DailyAfter)
+	}
+	return nil
+}
+
+// RollupReport counts the line items replaced by a rollup and the items
+// that replaced them
+type RollupReport struct {
+	Rolled  int `json:"rolled"`
+	Created int `json:"created"`
+}
+
+// Rollup applies the retention policy to every tenant's line items as of
+// now. Rolled-up items keep the provider, service, region, resource, currency
+// and tags of the items they replace, so totals along every dimension are
+// preserved. Queries read rolled-up items like any other, dated at the start
+// of their day or month.
+func (s *Service) Rollup(now time.Time, p RetentionPolicy) (RollupReport, error) {
+	if err := p.Validate(); err != nil {
+		return RollupReport{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var report RollupReport
+	for tenantID, items := range s.items {
+		if p.DailyAfter > 0 {
+			items = rollup(items, now.Add(-p.DailyAfter), GranularityDaily, &report)
+		}
+		if p.MonthlyAfter > 0 {
+			items = rollup(items, now.Add(-p.MonthlyAfter), GranularityMonthly, &report)
+		}
+		s.items[tenantID] = items
+	}
+	return report, nil
+}
+
+// rollup sums the items of periods ending by cutoff into one item per period
+// and dimensions. It returns a new slice so snapshots taken by Each are
+// never modified.
+func rollup(items []LineItem, cutoff time.Time, granularity string, report *RollupReport) []LineItem {
+	kept := make([]LineItem, 0, len(items))
+	groups := make(map[string]*LineItem)
+	var order []string
+
+	for _, item := range items {
+		start, end := period(item.Date, granularity)
+		if granularityRank[item.Granularity] >= granularityRank[granularity] || end.After(cutoff) {
+			kept = append(kept, item)
+			continue
+		}
+
+		key := rollupKey(start, item)
+		g, ok := groups[key]
+		if !ok {
+			g = &LineItem{
+				Date:        start,
+				Provider:    item.Provider,
+				Service:     item.Service,
+				Region:      item.Region,
+				ResourceID:  item.ResourceID,
+				Currency:    item.Currency,
+				Tags:        item.Tags,
+				Granularity: granularity,
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.Amount += item.Amount
+		report.Rolled++
+	}
+
+	for _, key := range order {
+		kept = append(kept, *groups[key])
+	}
+	report.Created += len(order)
+	return kept
+}
+
+// period returns the UTC day or month containing t
+func period(t time.Time, granularity string) (time.Time, time.Time) {
+	t = t.UTC()
+	if granularity == GranularityMonthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// rollupKey identifies the rolled-up item an item is summed into
+func rollupKey(start time.Time, item LineItem) string {
+	tags := make([]string, 0, len(item.Tags))
+	for k, v := range item.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	sort.Strings(tags)
+
+	return strings.Join([]string{
+		start.Format(time.RFC3339), item.Provider, item.Service, item.Region,
+		item.ResourceID, item.Currency, strings.Join(tags, "\x00"),
+	}, "\x01")
+}
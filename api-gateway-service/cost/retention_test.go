@@ -0,0 +1,132 @@
+package cost
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name    string
+		policy  RetentionPolicy
+		wantErr bool
+	}{
+		{name: "disabled"},
+		{name: "daily only", policy: RetentionPolicy{DailyAfter: 7 * day}},
+		{name: "monthly only", policy: RetentionPolicy{MonthlyAfter: 90 * day}},
+		{name: "both", policy: RetentionPolicy{DailyAfter: 7 * day, MonthlyAfter: 90 * day}},
+		{name: "monthly before daily", policy: RetentionPolicy{DailyAfter: 90 * day, MonthlyAfter: 7 * day}, wantErr: true},
+		{name: "negative", policy: RetentionPolicy{DailyAfter: -day}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Hourly items past the thresholds are rolled up into daily and monthly
+// items whose totals match the hourly sums, while recent items are kept
+func TestRollup(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	now := time.Date(2026, 9, 15, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	var hourly []LineItem
+	for ts := start; ts.Before(now); ts = ts.Add(time.Hour) {
+		hourly = append(hourly,
+			LineItem{Date: ts, Provider: "aws", Service: "ec2", Region: "us-east-1", Amount: 0.5, Currency: "USD", Tags: map[string]string{"team": "web"}},
+			LineItem{Date: ts, Provider: "gcp", Service: "gce", Region: "us-east1", Amount: 0.25, Currency: "USD"},
+		)
+	}
+	s := NewService()
+	if err := s.Ingest(ctx, hourly); err != nil {
+		t.Fatal(err)
+	}
+
+	// sums totals the items by granularity and by the period containing them
+	sums := func(items []LineItem, granularity string) (map[string]float64, map[time.Time]float64) {
+		byGranularity := make(map[string]float64)
+		byPeriod := make(map[time.Time]float64)
+		for _, item := range items {
+			byGranularity[item.Granularity] += item.Amount
+			p, _ := period(item.Date, granularity)
+			byPeriod[p] += item.Amount
+		}
+		return byGranularity, byPeriod
+	}
+	_, wantDaily := sums(hourly, GranularityDaily)
+	_, wantMonthly := sums(hourly, GranularityMonthly)
+
+	policy := RetentionPolicy{DailyAfter: 7 * 24 * time.Hour, MonthlyAfter: 90 * 24 * time.Hour}
+	report, err := s.Rollup(now, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Rolled == 0 || report.Created == 0 || report.Created >= report.Rolled {
+		t.Errorf("report %+v, want many items rolled into fewer", report)
+	}
+
+	items := s.Items(ctx, Filter{})
+	dailyCutoff, monthlyCutoff := now.Add(-policy.DailyAfter), now.Add(-policy.MonthlyAfter)
+	for _, item := range items {
+		_, dayEnd := period(item.Date, GranularityDaily)
+		_, monthEnd := period(item.Date, GranularityMonthly)
+		switch item.Granularity {
+		case "":
+			if !dayEnd.After(dailyCutoff) {
+				t.Fatalf("hourly item of %v kept past the daily threshold", item.Date)
+			}
+		case GranularityDaily:
+			if dayEnd.After(dailyCutoff) || !monthEnd.After(monthlyCutoff) {
+				t.Fatalf("daily item of %v outside the daily tier", item.Date)
+			}
+			if item.Provider == "aws" && (item.Amount != 12 || item.Tags["team"] != "web") {
+				t.Fatalf("daily aws item %+v, want 24 hours of 0.5 with its tags", item)
+			}
+		case GranularityMonthly:
+			if monthEnd.After(monthlyCutoff) || !item.Date.Equal(time.Date(item.Date.Year(), item.Date.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("monthly item of %v outside the monthly tier", item.Date)
+			}
+		}
+	}
+
+	byGranularity, gotDaily := sums(items, GranularityDaily)
+	for _, g := range []string{"", GranularityDaily, GranularityMonthly} {
+		if byGranularity[g] == 0 {
+			t.Errorf("no cost in the %q tier", g)
+		}
+	}
+	_, gotMonthly := sums(items, GranularityMonthly)
+	for month, want := range wantMonthly {
+		if gotMonthly[month] != want {
+			t.Errorf("total of %s = %v, want %v", month.Format("2006-01"), gotMonthly[month], want)
+		}
+	}
+	for day, want := range wantDaily {
+		if _, monthEnd := period(day, GranularityMonthly); monthEnd.After(monthlyCutoff) && gotDaily[day] != want {
+			t.Errorf("total of %s = %v, want %v", day.Format("2006-01-02"), gotDaily[day], want)
+		}
+	}
+
+	// Queries read rolled-up items, so a query's total is unchanged
+	june := Filter{Start: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)}
+	if got, want := s.Costs(ctx, june).TotalCost, 30*24*0.75; got != want {
+		t.Errorf("June total %v, want %v", got, want)
+	}
+
+	again, err := s.Rollup(now, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Rolled != 0 || again.Created != 0 {
+		t.Errorf("second rollup %+v, want nothing left to roll up", again)
+	}
+}
@@ -0,0 +1,211 @@
+package cost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"api-gateway-service/tenant"
+)
+
+// Service holds cost line items in memory, partitioned by tenant, and answers
+// cost queries over them
+type Service struct {
+	mu sync.RWMutex
+	// items maps tenant ID to that tenant's line items
+	items map[string][]LineItem
+}
+
+// NewService creates a new cost service
+func NewService() *Service {
+	return &Service{
+		items: make(map[string][]LineItem),
+	}
+}
+
+// Ingest adds line items for the caller's tenant
+func (s *Service) Ingest(ctx context.Context, items []LineItem) error {
+	for i, item := range items {
+		if item.Date.IsZero() {
+			return fmt.Errorf("line item %d has no date", i)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	s.items[tenantID] = append(s.items[tenantID], items...)
+	return nil
+}
+
+// Items returns the caller's tenant line items matching the filter, ordered by date
+func (s *Service) Items(ctx context.Context, f Filter) []LineItem {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	items := make([]LineItem, 0)
+	for _, item := range s.items[tenant.FromContext(ctx)] {
+		if f.Matches(item) {
+			items = append(items, item)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Date.Before(items[j].Date)
+	})
+	return items
+}
+
+// Each calls fn for each of the caller's tenant line items matching the
+// filter, in date order, stopping at the first error. Unlike Items it does not
+// copy the matching items, so memory stays bounded for large result sets.
+func (s *Service) Each(ctx context.Context, f Filter, fn func(LineItem) error) error {
+	// Ingest appends and Rollup replaces the slice, so the snapshot's
+	// elements are never modified
+	s.mu.RLock()
+	all := s.items[tenant.FromContext(ctx)]
+	s.mu.RUnlock()
+
+	var matched []int
+	for i := range all {
+		if f.Matches(all[i]) {
+			matched = append(matched, i)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return all[matched[i]].Date.Before(all[matched[j]].Date)
+	})
+
+	for _, i := range matched {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(all[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Costs returns the line items matching the filter with their totals
+func (s *Service) Costs(ctx context.Context, f Filter) *Analysis {
+	items := s.Items(ctx, f)
+
+	a := &Analysis{
+		Currency:    currency(items),
+		PeriodStart: f.Start,
+		PeriodEnd:   f.End,
+		Breakdown: Breakdown{
+			ByService: make(map[string]float64),
+			ByRegion:  make(map[string]float64),
+			ByTag:     make(map[string]float64),
+		},
+		Items: items,
+	}
+	for _, item := range items {
+		a.TotalCost += item.Amount
+		a.Breakdown.ByService[item.Service] += item.Amount
+		a.Breakdown.ByRegion[item.Region] += item.Amount
+		for key, value := range item.Tags {
+			a.Breakdown.ByTag[key+"="+value] += item.Amount
+		}
+	}
+	return a
+}
+
+// Summary totals the line items matching the filter by the given grouping
+func (s *Service) Summary(ctx context.Context, f Filter, groupBy string) (*Summary, error) {
+	if err := ValidateGroupBy(groupBy); err != nil {
+		return nil, err
+	}
+
+	items := s.Items(ctx, f)
+
+	totals := make(map[string]float64)
+	summary := &Summary{
+		GroupBy:     groupBy,
+		Currency:    currency(items),
+		PeriodStart: f.Start,
+		PeriodEnd:   f.End,
+		Groups:      make([]GroupCost, 0),
+	}
+	for _, item := range items {
+		totals[groupKey(item, groupBy)] += item.Amount
+		summary.TotalCost += item.Amount
+	}
+
+	for key, total := range totals {
+		group := GroupCost{Key: key, Cost: total}
+		if summary.TotalCost > 0 {
+			group.Share = total / summary.TotalCost
+		}
+		summary.Groups = append(summary.Groups, group)
+	}
+
+	sort.Slice(summary.Groups, func(i, j int) bool {
+		if summary.Groups[i].Cost != summary.Groups[j].Cost {
+			return summary.Groups[i].Cost > summary.Groups[j].Cost
+		}
+		return summary.Groups[i].Key < summary.Groups[j].Key
+	})
+	return summary, nil
+}
+
+// currency returns the currency of the line items, or DefaultCurrency if none is set
+func currency(items []LineItem) string {
+	for _, item := range items {
+		if item.Currency != "" {
+			return item.Currency
+		}
+	}
+	return DefaultCurrency
+}
+
+// SpendTotal is one tenant's spend on a provider's service in a region, in
+// one currency
+type SpendTotal struct {
+	TenantID string
+	Provider string
+	Service  string
+	Region   string
+	Currency string
+	Amount   float64
+}
+
+// Totals sums the line items of every tenant matching the filter by tenant,
+// provider, service, region and currency. It is meant for exporting metrics,
+// so unlike the other queries it is not limited to the caller's tenant.
+func (s *Service) Totals(f Filter) []SpendTotal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type key struct{ tenant, provider, service, region, currency string }
+	sums := make(map[key]float64)
+	for tenantID, items := range s.items {
+		for _, item := range items {
+			if !f.Matches(item) {
+				continue
+			}
+			c := item.Currency
+			if c == "" {
+				c = DefaultCurrency
+			}
+			sums[key{tenantID, item.Provider, item.Service, item.Region, c}] += item.Amount
+		}
+	}
+
+	totals := make([]SpendTotal, 0, len(sums))
+	for k, amount := range sums {
+		totals = append(totals, SpendTotal{
+			TenantID: k.tenant,
+			Provider: k.provider,
+			Service:  k.service,
+			Region:   k.region,
+			Currency: k.currency,
+			Amount:   amount,
+		})
+	}
+	return totals
+}
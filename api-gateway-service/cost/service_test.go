@@ -0,0 +1,92 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+func TestServiceEach(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := NewService()
+	items := []LineItem{
+		{Date: day.AddDate(0, 0, 2), Provider: "aws", Service: "ec2", Amount: 3},
+		{Date: day, Provider: "aws", Service: "s3", Amount: 1},
+		{Date: day.AddDate(0, 0, 1), Provider: "gcp", Service: "gce", Amount: 2},
+		{Date: day.AddDate(0, 0, 1), Provider: "aws", Service: "rds", Amount: 4},
+	}
+	if err := s.Ingest(ctx, items); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ingest(tenant.NewContext(context.Background(), "globex"), []LineItem{{Date: day, Provider: "aws", Amount: 99}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []float64
+	}{
+		{name: "all by date, stable within a day", want: []float64{1, 2, 4, 3}},
+		{name: "provider", filter: Filter{Provider: "aws"}, want: []float64{1, 4, 3}},
+		{name: "period", filter: Filter{Start: day.AddDate(0, 0, 1), End: day.AddDate(0, 0, 2)}, want: []float64{2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []float64
+			if err := s.Each(ctx, tt.filter, func(item LineItem) error {
+				got = append(got, item.Amount)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("amounts %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("amounts %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// Each stops at the first error of the callback or of the context
+func TestServiceEachStops(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := NewService()
+	if err := s.Ingest(ctx, []LineItem{{Date: day}, {Date: day}, {Date: day}}); err != nil {
+		t.Fatal(err)
+	}
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := s.Each(ctx, Filter{}, func(LineItem) error {
+		calls++
+		if calls == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || calls != 2 {
+		t.Errorf("Each returned %v after %d calls, want %v after 2", err, calls, errStop)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	err = s.Each(cancelled, Filter{}, func(LineItem) error {
+		calls++
+		return nil
+	})
+	if err != context.Canceled || calls != 0 {
+		t.Errorf("Each on a cancelled context returned %v after %d calls, want %v", err, calls, context.Canceled)
+	}
+}
@@ -0,0 +1,36 @@
+package cost
+
+// Names of the series of a forecast's time series
+const (
+	SeriesHistory  = "history"
+	SeriesForecast = "forecast"
+	SeriesLower    = "forecast_lower"
+	SeriesUpper    = "forecast_upper"
+)
+
+// TimeSeries is a series in the format of Grafana's JSON data source: each
+// datapoint is a [value, Unix time in milliseconds] pair
+type TimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// TimeSeries returns the forecast as separate history, forecast and lower
+// and upper confidence bound series, for plotting the band around the
+// forecast
+func (f *Forecast) TimeSeries() []TimeSeries {
+	return []TimeSeries{
+		newTimeSeries(SeriesHistory, f.History),
+		newTimeSeries(SeriesForecast, f.Daily),
+		newTimeSeries(SeriesLower, f.Lower),
+		newTimeSeries(SeriesUpper, f.Upper),
+	}
+}
+
+func newTimeSeries(target string, days []DailyCost) TimeSeries {
+	series := TimeSeries{Target: target, Datapoints: make([][2]float64, len(days))}
+	for i, d := range days {
+		series.Datapoints[i] = [2]float64{d.Cost, float64(d.Date.UnixMilli())}
+	}
+	return series
+}
@@ -0,0 +1,56 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestForecastTimeSeries(t *testing.T) {
+	s, f := forecastService(t, []float64{10, 12, 14, 17})
+	forecast, err := s.Forecast(context.Background(), f, 2, ForecastOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series := forecast.TimeSeries()
+	wantTargets := []string{SeriesHistory, SeriesForecast, SeriesLower, SeriesUpper}
+	wantDays := []int{4, 2, 2, 2}
+	if len(series) != len(wantTargets) {
+		t.Fatalf("%d series, want %d", len(series), len(wantTargets))
+	}
+	for i, ts := range series {
+		if ts.Target != wantTargets[i] || len(ts.Datapoints) != wantDays[i] {
+			t.Errorf("series %d is %s with %d points, want %s with %d", i, ts.Target, len(ts.Datapoints), wantTargets[i], wantDays[i])
+		}
+	}
+
+	history := series[0].Datapoints
+	for i, want := range []float64{10, 12, 14, 17} {
+		day := forecastStart.AddDate(0, 0, i)
+		if history[i] != [2]float64{want, float64(day.UnixMilli())} {
+			t.Errorf("history point %d = %v, want [%g %d]", i, history[i], want, day.UnixMilli())
+		}
+	}
+
+	// The bounds are their own series, on the forecast's timestamps, either
+	// side of it
+	forecastPoints, lower, upper := series[1].Datapoints, series[2].Datapoints, series[3].Datapoints
+	for i, p := range forecastPoints {
+		if p[1] != float64(f.End.AddDate(0, 0, i).UnixMilli()) || lower[i][1] != p[1] || upper[i][1] != p[1] {
+			t.Errorf("day %d timestamps %g, %g, %g, want the forecast day", i, p[1], lower[i][1], upper[i][1])
+		}
+		if !(lower[i][0] < p[0] && p[0] < upper[i][0]) {
+			t.Errorf("day %d: forecast %g not strictly inside [%g, %g]", i, p[0], lower[i][0], upper[i][0])
+		}
+	}
+
+	data, err := json.Marshal(series[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"target":"history","datapoints":[[10,1788220800000],`; !strings.HasPrefix(string(data), want) {
+		t.Errorf("JSON %s, want it to start %s", data, want)
+	}
+}
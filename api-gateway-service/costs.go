@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+	"api-gateway-service/locale"
+)
+
+const (
+	costDateLayout         = "2006-01-02"
+	defaultCostPeriodDays  = 30
+	defaultForecastHorizon = 30
+
+	// maxCostImportBytes bounds the size of an uploaded cost CSV
+	maxCostImportBytes = 32 << 20
+
+	// mimeNDJSON is the content type of streamed cost line items
+	mimeNDJSON = "application/x-ndjson"
+	// ndjsonFlushEvery is the number of streamed line items between flushes
+	ndjsonFlushEvery = 500
+
+	// Response formats of the cost forecast
+	forecastFormatJSON    = "json"
+	forecastFormatGrafana = "grafana"
+)
+
+var costService = cost.NewService()
+
+func getCosts(c *gin.Context) {
+	filter, err := parseCostFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if c.NegotiateFormat(gin.MIMEJSON, mimeNDJSON) == mimeNDJSON {
+		streamCosts(c, filter)
+		return
+	}
+
+	analysis := costService.Costs(c.Request.Context(), filter)
+	respondLocalized(c, analysis, func(l locale.Locale) map[string]string {
+		return map[string]string{
+			"total_cost":   l.Money(analysis.TotalCost, analysis.Currency),
+			"period_start": l.Date(analysis.PeriodStart),
+			"period_end":   l.Date(analysis.PeriodEnd),
+		}
+	})
+}
+
+// streamCosts writes the line items matching the filter as newline-delimited
+// JSON, one item per line, flushing every ndjsonFlushEvery items so clients
+// can process them as they arrive. The write deadline is extended at each
+// flush, so server.write_timeout bounds each chunk rather than the whole
+// stream.
+func streamCosts(c *gin.Context, filter cost.Filter) {
+	rc := http.NewResponseController(c.Writer)
+
+	c.Header("Content-Type", mimeNDJSON)
+	c.Status(http.StatusOK)
+	extendWriteDeadline(rc)
+
+	enc := json.NewEncoder(c.Writer)
+	n := 0
+	err := costService.Each(c.Request.Context(), filter, func(item cost.LineItem) error {
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		n++
+		if n%ndjsonFlushEvery == 0 {
+			extendWriteDeadline(rc)
+			return rc.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// The status is already sent, so the client sees a truncated stream
+		log.Printf("Cost stream ended after %d items: %v", n, err)
+		return
+	}
+	_ = rc.Flush()
+}
+
+func getCostSummary(c *gin.Context) {
+	filter, err := parseCostFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := costService.Summary(c.Request.Context(), filter, c.DefaultQuery("group_by", cost.GroupByService))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondLocalized(c, summary, func(l locale.Locale) map[string]string {
+		formatted := map[string]string{
+			"total_cost":   l.Money(summary.TotalCost, summary.Currency),
+			"period_start": l.Date(summary.PeriodStart),
+			"period_end":   l.Date(summary.PeriodEnd),
+		}
+		for _, g := range summary.Groups {
+			formatted["groups."+g.Key] = l.Money(g.Cost, summary.Currency)
+		}
+		return formatted
+	})
+}
+
+func getCostForecast(c *gin.Context) {
+	filter, err := parseCostFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	horizon := defaultForecastHorizon
+	if v := c.Query("horizon"); v != "" {
+		horizon, err = strconv.Atoi(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid horizon: %s", v))
+			return
+		}
+	}
+
+	opts := cost.ForecastOptions{Model: c.DefaultQuery("model", cost.ModelLinear)}
+	if v := c.Query("alpha"); v != "" {
+		opts.Alpha, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid alpha: %s", v))
+			return
+		}
+	}
+	opts.Smoothing, err = strconv.ParseBool(c.DefaultQuery("smoothing", "false"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid smoothing: must be true or false")
+		return
+	}
+
+	format := c.DefaultQuery("format", forecastFormatJSON)
+	if format != forecastFormatJSON && format != forecastFormatGrafana {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid format: %s (must be %s or %s)", format, forecastFormatJSON, forecastFormatGrafana))
+		return
+	}
+
+	forecast, err := costService.Forecast(c.Request.Context(), filter, horizon, opts)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if format == forecastFormatGrafana {
+		c.JSON(http.StatusOK, forecast.TimeSeries())
+		return
+	}
+
+	respondLocalized(c, forecast, func(l locale.Locale) map[string]string {
+		return map[string]string{
+			"forecast_total": l.Money(forecast.ForecastTotal, forecast.Currency),
+		}
+	})
+}
+
+func getCostDiff(c *gin.Context) {
+	from, err := parseCostPeriod(c, "from")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := parseCostPeriod(c, "to")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	top := cost.DefaultTopIncreases
+	if v := c.Query("top"); v != "" {
+		top, err = strconv.Atoi(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid top: %s", v))
+			return
+		}
+	}
+
+	diff, err := costService.Diff(c.Request.Context(), from, to, c.DefaultQuery("group_by", cost.GroupByService), top)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// getCostAttribution attributes spend across every provider to the values of
+// the dimension tag, in the currency configured under costs.currency
+func getCostAttribution(c *gin.Context) {
+	filter, err := parseCostFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dimension := c.Query("dimension")
+	if dimension == "" {
+		respondError(c, http.StatusBadRequest, "dimension is required")
+		return
+	}
+
+	attribution, err := costService.Attribution(c.Request.Context(), filter, dimension, exchangeRates())
+	if err != nil {
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	respondLocalized(c, attribution, func(l locale.Locale) map[string]string {
+		return map[string]string{
+			"total_cost":   l.Money(attribution.TotalCost, attribution.Currency),
+			"period_start": l.Date(attribution.PeriodStart),
+			"period_end":   l.Date(attribution.PeriodEnd),
+		}
+	})
+}
+
+// exchangeRates returns the rates configured under costs.currency.rates,
+// keyed by upper-case currency code
+func exchangeRates() cost.ExchangeRates {
+	rates := cost.ExchangeRates{
+		Base:  viper.GetString("costs.currency.base"),
+		Rates: make(map[string]float64),
+	}
+	for code := range viper.GetStringMap("costs.currency.rates") {
+		rates.Rates[strings.ToUpper(code)] = viper.GetFloat64("costs.currency.rates." + code)
+	}
+	return rates
+}
+
+// importCosts ingests line items from an exported billing CSV in the request
+// body. The column mapping defaults to cost.DefaultCSVMapping and each column
+// can be renamed with a <field>_column query parameter. Malformed rows are
+// skipped and listed in the response.
+func importCosts(c *gin.Context) {
+	mapping := cost.DefaultCSVMapping()
+	for param, column := range map[string]*string{
+		"date_column":        &mapping.Date,
+		"service_column":     &mapping.Service,
+		"region_column":      &mapping.Region,
+		"amount_column":      &mapping.Amount,
+		"currency_column":    &mapping.Currency,
+		"provider_column":    &mapping.Provider,
+		"resource_id_column": &mapping.ResourceID,
+		"tag_prefix":         &mapping.TagPrefix,
+		"date_format":        &mapping.DateFormat,
+	} {
+		if v, ok := c.GetQuery(param); ok {
+			*column = v
+		}
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxCostImportBytes)
+	provider := cost.NewCSVProvider(body, mapping, c.Query("provider"))
+	report, err := costService.Import(c.Request.Context(), provider)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseCostPeriod reads a required period query parameter as a filter,
+// sharing the provider and region filters of the request
+func parseCostPeriod(c *gin.Context, name string) (cost.Filter, error) {
+	filter := cost.Filter{
+		Provider: c.Query("provider"),
+		Region:   c.Query("region"),
+	}
+
+	v := c.Query(name)
+	if v == "" {
+		return filter, fmt.Errorf("%s is required", name)
+	}
+
+	var err error
+	filter.Start, filter.End, err = cost.ParsePeriod(v)
+	if err != nil {
+		return filter, fmt.Errorf("%s: %v", name, err)
+	}
+	return filter, nil
+}
+
+// runCostRollup rolls up old cost line items according to the configured
+// retention policy, checking every interval until ctx is done
+func runCostRollup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			policy := cost.RetentionPolicy{
+				DailyAfter:   viper.GetDuration("costs.retention.daily_after"),
+				MonthlyAfter: viper.GetDuration("costs.retention.monthly_after"),
+			}
+			report, err := costService.Rollup(now.UTC(), policy)
+			if err != nil {
+				log.Printf("Cost rollup failed: %v", err)
+				continue
+			}
+			if report.Rolled > 0 {
+				log.Printf("Rolled up %d cost line items into %d", report.Rolled, report.Created)
+			}
+		}
+	}
+}
+
+// parseCostFilter reads the cost period and filters from the query string
+func parseCostFilter(c *gin.Context) (cost.Filter, error) {
+	return newCostFilter(c.Query("provider"), c.Query("region"), c.Query("start_date"), c.Query("end_date"))
+}
+
+// newCostFilter builds the filter of a cost query, shared by the REST and
+// gRPC handlers. endDate is inclusive; the period defaults to the last 30 days.
+func newCostFilter(provider, region, startDate, endDate string) (cost.Filter, error) {
+	filter := cost.Filter{
+		Provider: provider,
+		Region:   region,
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	filter.End = today.AddDate(0, 0, 1)
+	if endDate != "" {
+		end, err := time.Parse(costDateLayout, endDate)
+		if err != nil {
+			return filter, fmt.Errorf("invalid end_date: %s (expected YYYY-MM-DD)", endDate)
+		}
+		filter.End = end.AddDate(0, 0, 1)
+	}
+
+	filter.Start = filter.End.AddDate(0, 0, -defaultCostPeriodDays)
+	if startDate != "" {
+		start, err := time.Parse(costDateLayout, startDate)
+		if err != nil {
+			return filter, fmt.Errorf("invalid start_date: %s (expected YYYY-MM-DD)", startDate)
+		}
+		filter.Start = start
+	}
+
+	if !filter.Start.Before(filter.End) {
+		return filter, fmt.Errorf("start_date must not be after end_date")
+	}
+	return filter, nil
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+	"api-gateway-service/tenant"
+)
+
+// flushRecorder records how many lines of the body were written at each flush
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes = append(r.flushes, bytes.Count(r.Body.Bytes(), []byte("\n")))
+	r.ResponseRecorder.Flush()
+}
+
+// A negotiated NDJSON response holds one line item per line and is flushed
+// every ndjsonFlushEvery items, before the stream completes
+func TestGetCostsNDJSON(t *testing.T) {
+	const total = ndjsonFlushEvery*2 + 10
+
+	prev := costService
+	costService = cost.NewService()
+	t.Cleanup(func() { costService = prev })
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -10)
+	items := make([]cost.LineItem, total)
+	for i := range items {
+		items[i] = cost.LineItem{Date: start.AddDate(0, 0, i%10), Provider: "aws", Service: "ec2", Region: "us-east-1", Amount: float64(i), Currency: "USD"}
+	}
+	if err := costService.Ingest(tenant.NewContext(context.Background(), "acme"), items); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), "acme"))
+	})
+	router.GET("/costs", getCosts)
+
+	req := httptest.NewRequest(http.MethodGet, "/costs", nil)
+	req.Header.Set("Accept", mimeNDJSON)
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != mimeNDJSON {
+		t.Errorf("content type %q, want %q", ct, mimeNDJSON)
+	}
+
+	n := 0
+	var last time.Time
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var item cost.LineItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("line %d %q is not a line item: %v", n+1, scanner.Text(), err)
+		}
+		if item.Date.Before(last) {
+			t.Errorf("line %d dated %v before the previous %v", n+1, item.Date, last)
+		}
+		last = item.Date
+		n++
+	}
+	if n != total {
+		t.Errorf("%d lines, want %d", n, total)
+	}
+
+	want := []int{ndjsonFlushEvery, ndjsonFlushEvery * 2, total}
+	if len(w.flushes) != len(want) {
+		t.Fatalf("flushed after lines %v, want %v", w.flushes, want)
+	}
+	for i := range want {
+		if w.flushes[i] != want[i] {
+			t.Fatalf("flushed after lines %v, want %v", w.flushes, want)
+		}
+	}
+}
+
+// Without the NDJSON media type the response stays a single JSON analysis
+func TestGetCostsJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/costs", getCosts)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/costs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var analysis cost.Analysis
+	if err := json.Unmarshal(w.Body.Bytes(), &analysis); err != nil {
+		t.Errorf("body is not an analysis: %v", err)
+	}
+}
+
+// The attribution report converts spend with the configured exchange rates
+func TestGetCostAttribution(t *testing.T) {
+	viper.Set("costs.currency.base", "EUR")
+	viper.Set("costs.currency.rates", map[string]interface{}{"usd": 0.5})
+	t.Cleanup(func() {
+		viper.Set("costs.currency.base", nil)
+		viper.Set("costs.currency.rates", nil)
+	})
+
+	prev := costService
+	costService = cost.NewService()
+	t.Cleanup(func() { costService = prev })
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := costService.Ingest(tenant.NewContext(context.Background(), "acme"), []cost.LineItem{
+		{Date: today, Provider: "aws", Amount: 100, Currency: "USD", Tags: map[string]string{"team": "web"}},
+		{Date: today, Provider: "azure", Amount: 20, Currency: "EUR", Tags: map[string]string{"team": "web"}},
+		{Date: today, Provider: "gcp", Amount: 10, Currency: "GBP"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), c.GetHeader("X-Tenant-ID")))
+	})
+	router.GET("/costs/attribution", getCostAttribution)
+	get := func(tenantID, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/costs/attribution"+query, nil)
+		req.Header.Set("X-Tenant-ID", tenantID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("acme", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("without a dimension: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := get("acme", "?dimension=team"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("without a GBP rate: status %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+	}
+
+	if err := costService.Ingest(tenant.NewContext(context.Background(), "globex"), []cost.LineItem{
+		{Date: today, Provider: "aws", Amount: 100, Currency: "USD", Tags: map[string]string{"team": "web"}},
+		{Date: today, Provider: "azure", Amount: 20, Currency: "EUR"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	w := get("globex", "?dimension=team")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var a cost.Attribution
+	if err := json.Unmarshal(w.Body.Bytes(), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Currency != "EUR" || a.TotalCost != 70 || len(a.Values) != 2 || a.Values[0].Value != "web" || a.Values[0].Cost != 50 {
+		t.Errorf("attribution %+v, want 50 EUR for web of 70", a)
+	}
+}
+
+// A cost response gains formatted display strings for the locale query
+// parameter or the Accept-Language header, and stays plain without either
+func TestGetCostsLocalized(t *testing.T) {
+	prev := costService
+	costService = cost.NewService()
+	t.Cleanup(func() { costService = prev })
+	if err := costService.Ingest(context.Background(), []cost.LineItem{
+		{Date: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), Provider: "aws", Amount: 1234.5, Currency: "EUR"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/costs/summary", getCostSummary)
+	get := func(query, acceptLanguage string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/costs/summary?start_date=2026-03-01&end_date=2026-03-31&group_by=provider"+query, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		acceptLanguage string
+		wantLanguage   string
+		wantTotal      string
+		wantStart      string
+	}{
+		{name: "plain"},
+		{name: "query", query: "&locale=de-DE", acceptLanguage: "en-US", wantLanguage: "de-DE", wantTotal: "1.234,50\u00a0€", wantStart: "01.03.2026"},
+		{name: "header", acceptLanguage: "fr;q=0.5, en-US", wantLanguage: "en-US", wantTotal: "€1,234.50", wantStart: "03/01/2026"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.query, tt.acceptLanguage)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body)
+			}
+			var body struct {
+				TotalCost float64           `json:"total_cost"`
+				Formatted map[string]string `json:"formatted"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.TotalCost != 1234.5 {
+				t.Errorf("total_cost %v, want the plain amount kept", body.TotalCost)
+			}
+			if got := w.Header().Get("Content-Language"); got != tt.wantLanguage {
+				t.Errorf("Content-Language %q, want %q", got, tt.wantLanguage)
+			}
+			if tt.wantLanguage == "" {
+				if body.Formatted != nil {
+					t.Errorf("formatted %v without a locale", body.Formatted)
+				}
+				return
+			}
+			if body.Formatted["total_cost"] != tt.wantTotal || body.Formatted["period_start"] != tt.wantStart || body.Formatted["groups.aws"] != tt.wantTotal {
+				t.Errorf("formatted %v, want total %q from %q", body.Formatted, tt.wantTotal, tt.wantStart)
+			}
+		})
+	}
+
+	if w := get("&locale=xx-XX", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("unsupported locale: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+// format=grafana returns the forecast as Grafana time series, with the
+// history and each confidence bound as a series of its own
+func TestGetCostForecastGrafana(t *testing.T) {
+	prev := costService
+	costService = cost.NewService()
+	t.Cleanup(func() { costService = prev })
+	start := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	var items []cost.LineItem
+	for i, amount := range []float64{10, 12, 14, 17} {
+		items = append(items, cost.LineItem{Date: start.AddDate(0, 0, i), Provider: "aws", Amount: amount, Currency: "USD"})
+	}
+	if err := costService.Ingest(context.Background(), items); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/costs/forecast", getCostForecast)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/costs/forecast?start_date=2026-09-01&end_date=2026-09-04&horizon=3"+query, nil))
+		return w
+	}
+
+	w := get("&format=grafana")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var series []cost.TimeSeries
+	if err := json.Unmarshal(w.Body.Bytes(), &series); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range series {
+		got = append(got, fmt.Sprintf("%s:%d", s.Target, len(s.Datapoints)))
+	}
+	if want := "history:4 forecast:3 forecast_lower:3 forecast_upper:3"; strings.Join(got, " ") != want {
+		t.Errorf("series %v, want %s", got, want)
+	}
+
+	w = get("")
+	var forecast cost.Forecast
+	if err := json.Unmarshal(w.Body.Bytes(), &forecast); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || len(forecast.Daily) != 3 || len(forecast.Lower) != 3 || forecast.ConfidenceLevel != cost.ConfidenceLevel {
+		t.Errorf("JSON forecast status %d: %s", w.Code, w.Body)
+	}
+
+	if w := get("&format=csv"); w.Code != http.StatusBadRequest {
+		t.Errorf("format=csv: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
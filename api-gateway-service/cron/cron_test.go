@@ -0,0 +1,85 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "*/15 * * * *"},
+		{spec: "0 9 * * mon-fri"},
+		{spec: "30 2 1,15 jan,jul *"},
+		{spec: "0 0 * * 7"},
+		{spec: "@daily"},
+		{spec: "@hourly"},
+		{spec: "* * * *", wantErr: "expected 5 fields, got 4"},
+		{spec: "60 * * * *", wantErr: "minute"},
+		{spec: "0 24 * * *", wantErr: "hour"},
+		{spec: "0 0 0 * *", wantErr: "day of month"},
+		{spec: "0 0 * 13 *", wantErr: "month"},
+		{spec: "0 0 * * 5-1", wantErr: "backwards"},
+		{spec: "*/0 * * * *", wantErr: "minute"},
+		{spec: "0 0 * * fun", wantErr: "day of week"},
+		{spec: "@fortnightly", wantErr: "invalid cron expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse(%q) = %v, want nil", tt.spec, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) = %v, want an error about %s", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	// A Wednesday
+	from := time.Date(2026, 9, 16, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{spec: "*/15 * * * *", from: from, want: time.Date(2026, 9, 16, 10, 15, 0, 0, time.UTC)},
+		{spec: "@daily", from: from, want: time.Date(2026, 9, 17, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", from: time.Date(2026, 9, 16, 10, 0, 0, 0, time.UTC), want: time.Date(2026, 9, 16, 11, 0, 0, 0, time.UTC)},
+		{spec: "0 9 * * mon-fri", from: time.Date(2026, 9, 18, 9, 0, 0, 0, time.UTC), want: time.Date(2026, 9, 21, 9, 0, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", from: from, want: time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 jan *", from: from, want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 1st of the month or any Friday
+		{spec: "0 0 1 * fri", from: from, want: time.Date(2026, 9, 18, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1 * fri", from: time.Date(2026, 9, 26, 0, 0, 0, 0, time.UTC), want: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		// Evaluated in the location of the time passed in
+		{spec: "0 9 * * *", from: from.In(newYork), want: time.Date(2026, 9, 16, 9, 0, 0, 0, newYork)},
+		{spec: "0 0 30 2 *", from: from},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			expr, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := expr.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+	"api-gateway-service/store"
+)
+
+const (
+	defaultDashboardTop = 5
+	maxDashboardTop     = 50
+)
+
+// errBudgetsUnavailable is reported for the budgets section, since the
+// gateway does not track budgets
+var errBudgetsUnavailable = errors.New("budget tracking is not available")
+
+// dashboardSection builds one section of the dashboard payload
+type dashboardSection struct {
+	name  string
+	build func(ctx context.Context) (interface{}, error)
+}
+
+// FrameworkCompliance counts the placements requiring a compliance framework
+// and those placed in a region that supports it
+type FrameworkCompliance struct {
+	Framework  string `json:"framework"`
+	Placements int    `json:"placements"`
+	Compliant  int    `json:"compliant"`
+}
+
+// ComplianceSummary summarizes how well the tenant's placements meet their
+// compliance frameworks. A placement is fully compliant when its region
+// supports every framework it requires.
+type ComplianceSummary struct {
+	Placements     int                   `json:"placements"`
+	FullyCompliant int                   `json:"fully_compliant"`
+	Frameworks     []FrameworkCompliance `json:"frameworks"`
+}
+
+// getDashboard returns the spend, spend trend, top recommendations, budgets
+// and compliance of the caller's tenant in one payload. Sections are built
+// concurrently; a section that fails or exceeds dashboard.section_timeout is
+// null, with its error listed under errors, rather than failing the request.
+func getDashboard(c *gin.Context) {
+	filter, err := parseCostFilter(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	top := defaultDashboardTop
+	if v := c.Query("top"); v != "" {
+		top, err = strconv.Atoi(v)
+		if err != nil || top < 1 || top > maxDashboardTop {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid top: %s (must be 1-%d)", v, maxDashboardTop))
+			return
+		}
+	}
+
+	previous := filter
+	previous.End = filter.Start
+	previous.Start = filter.Start.Add(-filter.End.Sub(filter.Start))
+
+	sections := []dashboardSection{
+		{"total_spend", func(ctx context.Context) (interface{}, error) {
+			return costService.Summary(ctx, filter, cost.GroupByProvider)
+		}},
+		{"spend_trend", func(ctx context.Context) (interface{}, error) {
+			return costService.Diff(ctx, previous, filter, cost.GroupByProvider, cost.DefaultTopIncreases)
+		}},
+		{"top_recommendations", func(ctx context.Context) (interface{}, error) {
+			return topRecommendations(ctx, top), nil
+		}},
+		{"budgets", func(ctx context.Context) (interface{}, error) {
+			return nil, errBudgetsUnavailable
+		}},
+		{"compliance", func(ctx context.Context) (interface{}, error) {
+			return summarizeCompliance(ctx), nil
+		}},
+	}
+
+	c.JSON(http.StatusOK, assembleDashboard(c.Request.Context(), sections))
+}
+
+// assembleDashboard builds the sections concurrently and returns them keyed by
+// name, with an errors object naming each section that failed, panicked or
+// did not finish within dashboard.section_timeout. Failed sections are null.
+func assembleDashboard(ctx context.Context, sections []dashboardSection) gin.H {
+	type result struct {
+		value interface{}
+		err   error
+	}
+
+	results := make([]result, len(sections))
+	var wg sync.WaitGroup
+	for i, s := range sections {
+		wg.Add(1)
+		go func(i int, s dashboardSection) {
+			defer wg.Done()
+
+			var sctx context.Context
+			var cancel context.CancelFunc
+			if timeout := viper.GetDuration("dashboard.section_timeout"); timeout > 0 {
+				sctx, cancel = context.WithTimeout(ctx, timeout)
+			} else {
+				sctx, cancel = context.WithCancel(ctx)
+			}
+			defer cancel()
+
+			// The section runs on its own so a slow one is abandoned at the
+			// timeout; the buffered channel lets it finish without a reader
+			done := make(chan result, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Printf("Dashboard section %s panicked: %v", s.name, r)
+						done <- result{err: fmt.Errorf("section failed")}
+					}
+				}()
+				v, err := s.build(sctx)
+				done <- result{value: v, err: err}
+			}()
+
+			select {
+			case r := <-done:
+				results[i] = r
+			case <-sctx.Done():
+				results[i] = result{err: fmt.Errorf("section timed out")}
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	payload := gin.H{}
+	errs := make(map[string]string)
+	for i, s := range sections {
+		if results[i].err != nil {
+			payload[s.name] = nil
+			errs[s.name] = results[i].err.Error()
+			continue
+		}
+		payload[s.name] = results[i].value
+	}
+	if len(errs) > 0 {
+		payload["errors"] = errs
+	}
+	return payload
+}
+
+// topRecommendations returns at most n open recommendations, largest
+// estimated savings first
+func topRecommendations(ctx context.Context, n int) []*store.Recommendation {
+	recs := openRecommendations(ctx, time.Now().UTC())
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].EstimatedSavings > recs[j].EstimatedSavings
+	})
+	if len(recs) > n {
+		recs = recs[:n]
+	}
+	return recs
+}
+
+// summarizeCompliance checks every placement's required compliance frameworks
+// against those supported by its selected region
+func summarizeCompliance(ctx context.Context) *ComplianceSummary {
+	placements := placementStore.List(ctx, "", "", false)
+	summary := &ComplianceSummary{
+		Placements: len(placements),
+		Frameworks: make([]FrameworkCompliance, 0),
+	}
+
+	counts := make(map[string]*FrameworkCompliance)
+	for _, p := range placements {
+		supported := regionFrameworks(p.SelectedProvider, p.SelectedRegion)
+
+		compliant := true
+		for _, id := range requiredFrameworks(p) {
+			fc, ok := counts[id]
+			if !ok {
+				fc = &FrameworkCompliance{Framework: id}
+				counts[id] = fc
+			}
+			fc.Placements++
+			if supported[id] {
+				fc.Compliant++
+			} else {
+				compliant = false
+			}
+		}
+		if compliant {
+			summary.FullyCompliant++
+		}
+	}
+
+	for _, fc := range counts {
+		summary.Frameworks = append(summary.Frameworks, *fc)
+	}
+	sort.Slice(summary.Frameworks, func(i, j int) bool {
+		return summary.Frameworks[i].Framework < summary.Frameworks[j].Framework
+	})
+	return summary
+}
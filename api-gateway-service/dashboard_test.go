@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// A failing, panicking or slow section is null with its error listed, and
+// the others are still returned
+func TestAssembleDashboard(t *testing.T) {
+	viper.Set("dashboard.section_timeout", 50*time.Millisecond)
+	t.Cleanup(func() { viper.Set("dashboard.section_timeout", nil) })
+
+	release := make(chan struct{})
+	defer close(release)
+	payload := assembleDashboard(context.Background(), []dashboardSection{
+		{"ok", func(ctx context.Context) (interface{}, error) { return 42, nil }},
+		{"failing", func(ctx context.Context) (interface{}, error) { return nil, errors.New("cost service down") }},
+		{"panicking", func(ctx context.Context) (interface{}, error) { panic("boom") }},
+		{"slow", func(ctx context.Context) (interface{}, error) {
+			<-release
+			return 1, nil
+		}},
+	})
+
+	if payload["ok"] != 42 {
+		t.Errorf("ok section %v, want 42", payload["ok"])
+	}
+	wantErrs := map[string]string{"failing": "cost service down", "panicking": "section failed", "slow": "section timed out"}
+	errs, _ := payload["errors"].(map[string]string)
+	if len(errs) != len(wantErrs) {
+		t.Fatalf("errors %v, want %v", errs, wantErrs)
+	}
+	for name, want := range wantErrs {
+		if v, ok := payload[name]; !ok || v != nil {
+			t.Errorf("%s section %v, want null", name, v)
+		}
+		if errs[name] != want {
+			t.Errorf("%s error %q, want %q", name, errs[name], want)
+		}
+	}
+
+	if payload := assembleDashboard(context.Background(), []dashboardSection{
+		{"ok", func(ctx context.Context) (interface{}, error) { return 1, nil }},
+	}); payload["errors"] != nil {
+		t.Errorf("errors %v listed with every section built", payload["errors"])
+	}
+}
+
+// The dashboard combines the tenant's spend, trend, recommendations and
+// compliance, and reports the unavailable budgets section without failing
+func TestGetDashboard(t *testing.T) {
+	router := tenantRouter(t, "acme")
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	prevCosts, prevRecs := costService, recommendationStore
+	costService, recommendationStore = cost.NewService(), store.NewRecommendationStore()
+	t.Cleanup(func() { costService, recommendationStore = prevCosts, prevRecs })
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := costService.Ingest(ctx, []cost.LineItem{
+		{Date: today.AddDate(0, 0, -3), Provider: "aws", Amount: 300, Currency: "USD"},
+		{Date: today.AddDate(0, 0, -40), Provider: "aws", Amount: 100, Currency: "USD"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []*store.Recommendation{
+		{ID: "rec-1", Type: "cost", Priority: "low", EstimatedSavings: 10},
+		{ID: "rec-2", Type: "cost", Priority: "high", EstimatedSavings: 300},
+		{ID: "rec-3", Type: "cost", Priority: "medium", EstimatedSavings: 50},
+	} {
+		if err := recommendationStore.Save(ctx, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gdpr := map[string]interface{}{"compliance_frameworks": []interface{}{"GDPR"}}
+	for _, p := range []*store.Placement{
+		{ID: "plc-1", ResourceType: "compute", SelectedProvider: "aws", SelectedRegion: "eu-west-1", Requirements: gdpr},
+		{ID: "plc-2", ResourceType: "compute", SelectedProvider: "aws", SelectedRegion: "us-east-1", Requirements: gdpr},
+		{ID: "plc-3", ResourceType: "compute", SelectedProvider: "aws", SelectedRegion: "us-east-1"},
+	} {
+		if err := placementStore.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := callAs(router, "acme", http.MethodGet, "/api/v1/dashboard?top=2", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var dashboard struct {
+		TotalSpend         *cost.Summary          `json:"total_spend"`
+		SpendTrend         *cost.Diff             `json:"spend_trend"`
+		TopRecommendations []store.Recommendation `json:"top_recommendations"`
+		Budgets            json.RawMessage        `json:"budgets"`
+		Compliance         *ComplianceSummary     `json:"compliance"`
+		Errors             map[string]string      `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &dashboard); err != nil {
+		t.Fatal(err)
+	}
+
+	if dashboard.TotalSpend == nil || dashboard.TotalSpend.TotalCost != 300 {
+		t.Errorf("total spend %+v, want 300 over the last 30 days", dashboard.TotalSpend)
+	}
+	if dashboard.SpendTrend == nil || dashboard.SpendTrend.FromTotal != 100 || dashboard.SpendTrend.ToTotal != 300 {
+		t.Errorf("spend trend %+v, want 100 to 300", dashboard.SpendTrend)
+	}
+	if len(dashboard.TopRecommendations) != 2 || dashboard.TopRecommendations[0].EstimatedSavings != 300 || dashboard.TopRecommendations[1].EstimatedSavings != 50 {
+		t.Errorf("top recommendations %+v, want the 300 and 50 savings", dashboard.TopRecommendations)
+	}
+	want := ComplianceSummary{Placements: 3, FullyCompliant: 2, Frameworks: []FrameworkCompliance{{Framework: "GDPR", Placements: 2, Compliant: 1}}}
+	if c := dashboard.Compliance; c == nil || c.Placements != want.Placements || c.FullyCompliant != want.FullyCompliant || len(c.Frameworks) != 1 || c.Frameworks[0] != want.Frameworks[0] {
+		t.Errorf("compliance %+v, want %+v", c, want)
+	}
+	if string(dashboard.Budgets) != "null" || dashboard.Errors["budgets"] != errBudgetsUnavailable.Error() || len(dashboard.Errors) != 1 {
+		t.Errorf("budgets %s with errors %v, want null with only the budgets error", dashboard.Budgets, dashboard.Errors)
+	}
+
+	for _, query := range []string{"?top=0", "?top=51", "?start_date=yesterday"} {
+		if w := callAs(router, "acme", http.MethodGet, "/api/v1/dashboard"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+}
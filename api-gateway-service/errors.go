@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes of the error envelope. Statuses without a code of their own
+// use their lower-cased status text.
+const (
+	codeInvalidRequest   = "invalid_request"
+	codeValidationFailed = "validation_failed"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeNotFound         = "not_found"
+	codeConflict         = "conflict"
+	codeUnprocessable    = "unprocessable"
+	codeNotImplemented   = "not_implemented"
+	codeInternal         = "internal_error"
+	// codeComplianceViolation rejects an apply that would break a
+	// placement's required compliance frameworks
+	codeComplianceViolation = "compliance_violation"
+)
+
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          codeInvalidRequest,
+	http.StatusUnauthorized:        codeUnauthorized,
+	http.StatusForbidden:           codeForbidden,
+	http.StatusNotFound:            codeNotFound,
+	http.StatusConflict:            codeConflict,
+	http.StatusUnprocessableEntity: codeUnprocessable,
+	http.StatusInternalServerError: codeInternal,
+	http.StatusNotImplemented:      codeNotImplemented,
+}
+
+// FieldError is a validation failure of one request field, named by its JSON
+// path, e.g. placements[0].name
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ErrorBody describes why a request failed. Fields lists the failing fields
+// of a validation error, if known; Details holds data specific to the code.
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	Details interface{}  `json:"details,omitempty"`
+}
+
+// ErrorResponse is the envelope every handler error is written in
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// respondError writes an error envelope with the code of the status
+func respondError(c *gin.Context, status int, message string) {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	}
+	c.JSON(status, ErrorResponse{Error: ErrorBody{Code: code, Message: message}})
+}
+
+// respondValidationError writes a 400 error envelope for a request that could
+// not be bound or failed validation. Binding validation failures, JSON type
+// mismatches and a *FieldError list the failing fields; a malformed or missing
+// body is an invalid_request rather than a validation_failed error.
+func respondValidationError(c *gin.Context, err error) {
+	body := ErrorBody{Code: codeValidationFailed, Message: err.Error()}
+
+	var validationErrs validator.ValidationErrors
+	var fieldErr *FieldError
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		body.Message = "request validation failed"
+		for _, fe := range validationErrs {
+			body.Fields = append(body.Fields, FieldError{Field: fieldPath(fe), Message: fieldMessage(fe)})
+		}
+	case errors.As(err, &fieldErr):
+		body.Fields = []FieldError{*fieldErr}
+	case errors.As(err, &typeErr):
+		body.Message = "request validation failed"
+		body.Fields = []FieldError{{Field: typeErr.Field, Message: fmt.Sprintf("must be of type %s", typeErr.Type)}}
+	case errors.Is(err, io.EOF):
+		body.Code = codeInvalidRequest
+		body.Message = "request body is required"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		body.Code = codeInvalidRequest
+		body.Message = "request body is not valid JSON: " + err.Error()
+	}
+	c.JSON(http.StatusBadRequest, ErrorResponse{Error: body})
+}
+
+// registerValidationFieldNames makes binding validation errors name fields by
+// their JSON key rather than their Go field name
+func registerValidationFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+}
+
+// fieldPath returns the JSON path of the failing field, without the name of
+// the request type it belongs to
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return ns
+}
+
+// fieldMessage describes the validation rule the field failed
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("must have %s %s entries", bound, fe.Param())
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", bound, fe.Param())
+		}
+		return fmt.Sprintf("must be %s %s", bound, fe.Param())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	}
+	return fmt.Sprintf("failed the %s check", fe.Tag())
+}
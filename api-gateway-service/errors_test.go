@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// A request failing validation on several fields lists each by its JSON path
+func TestValidationErrorEnvelope(t *testing.T) {
+	router := tenantRouter(t, "acme")
+	useInventory(t)
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/resources/import",
+		`{"resources":[{"id":"i-1","type":"compute"},{"type":"storage","provider":"aws","region":"us-east-1"}]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400: %s", w.Code, w.Body)
+	}
+
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := ErrorBody{
+		Code:    codeValidationFailed,
+		Message: "request validation failed",
+		Fields: []FieldError{
+			{Field: "resources[0].provider", Message: "is required"},
+			{Field: "resources[0].region", Message: "is required"},
+			{Field: "resources[1].id", Message: "is required"},
+		},
+	}
+	if !reflect.DeepEqual(resp.Error, want) {
+		t.Errorf("error %+v, want %+v", resp.Error, want)
+	}
+
+	// Nothing but the envelope is written
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil || len(raw) != 1 {
+		t.Errorf("body %s, want only the error object", w.Body)
+	}
+}
+
+func TestRespondValidationError(t *testing.T) {
+	type request struct {
+		Name   string   `json:"name" binding:"required"`
+		Count  int      `json:"count" binding:"min=1,max=10"`
+		Labels []string `json:"labels" binding:"min=1"`
+		Mode   string   `json:"mode" binding:"oneof=fast slow"`
+	}
+	registerValidationFieldNames()
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantCode   string
+		wantFields []FieldError
+	}{
+		{
+			name:     "validation",
+			body:     `{"count":11,"labels":[],"mode":"medium"}`,
+			wantCode: codeValidationFailed,
+			wantFields: []FieldError{
+				{Field: "name", Message: "is required"},
+				{Field: "count", Message: "must be at most 10"},
+				{Field: "labels", Message: "must have at least 1 entries"},
+				{Field: "mode", Message: "must be one of fast, slow"},
+			},
+		},
+		{
+			name:       "type mismatch",
+			body:       `{"name":"a","count":"many"}`,
+			wantCode:   codeValidationFailed,
+			wantFields: []FieldError{{Field: "count", Message: "must be of type int"}},
+		},
+		{name: "missing body", wantCode: codeInvalidRequest},
+		{name: "malformed", body: `{"name":`, wantCode: codeInvalidRequest},
+		{
+			name:       "field error",
+			err:        &FieldError{Field: "window.end", Message: "must be after start"},
+			wantCode:   codeValidationFailed,
+			wantFields: []FieldError{{Field: "window.end", Message: "must be after start"}},
+		},
+		{name: "other", err: errors.New("invalid cron"), wantCode: codeValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			err := tt.err
+			if err == nil {
+				if tt.body != "" {
+					c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+				}
+				var req request
+				err = c.ShouldBindJSON(&req)
+				if err == nil {
+					t.Fatal("request bound")
+				}
+			}
+			respondValidationError(c, err)
+
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusBadRequest || resp.Error.Code != tt.wantCode || resp.Error.Message == "" {
+				t.Errorf("status %d, error %+v; want 400 with code %s", w.Code, resp.Error, tt.wantCode)
+			}
+			if !reflect.DeepEqual(resp.Error.Fields, tt.wantFields) {
+				t.Errorf("fields %+v, want %+v", resp.Error.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestRespondErrorCodes(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusNotFound, codeNotFound},
+		{http.StatusConflict, codeConflict},
+		{http.StatusUnprocessableEntity, codeUnprocessable},
+		{http.StatusTooManyRequests, "too_many_requests"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		respondError(c, tt.status, "message")
+
+		var resp ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != tt.status || resp.Error.Code != tt.want || resp.Error.Message != "message" {
+			t.Errorf("status %d: %d %+v, want code %s", tt.status, w.Code, resp.Error, tt.want)
+		}
+	}
+}
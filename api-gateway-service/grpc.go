@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"api-gateway-service/audit"
+	"api-gateway-service/auth"
+	"api-gateway-service/cost"
+	"api-gateway-service/placement"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// grpcServiceName is the gRPC service defined in docs/cloudoptimizer.proto
+const grpcServiceName = "cloudoptimizer.v1.CloudOptimizer"
+
+// jsonCodec encodes gRPC messages as the JSON of the REST API's structs, so
+// both APIs share one set of types. Clients select it with the
+// application/grpc+json content type.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// CostQuery selects the period and line items of a cost call, like the query
+// parameters of the REST cost endpoints
+type CostQuery struct {
+	Provider  string `json:"provider,omitempty"`
+	Region    string `json:"region,omitempty"`
+	StartDate string `json:"start_date,omitempty"`
+	EndDate   string `json:"end_date,omitempty"`
+}
+
+// CostSummaryRequest is the request of GetCostSummary
+type CostSummaryRequest struct {
+	CostQuery
+	GroupBy string `json:"group_by,omitempty"`
+}
+
+// CostForecastRequest is the request of GetCostForecast
+type CostForecastRequest struct {
+	CostQuery
+	Horizon   int     `json:"horizon,omitempty"`
+	Model     string  `json:"model,omitempty"`
+	Alpha     float64 `json:"alpha,omitempty"`
+	Smoothing bool    `json:"smoothing,omitempty"`
+}
+
+// ResourceQuery filters ListResources
+type ResourceQuery struct {
+	Provider string `json:"provider,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+// ResourceRequest names one inventory resource
+type ResourceRequest struct {
+	ID string `json:"id"`
+}
+
+// ResourceList is the response of ListResources
+type ResourceList struct {
+	Resources []*store.Resource `json:"resources"`
+}
+
+// RecommendationList is the response of ListRecommendations
+type RecommendationList struct {
+	Recommendations []*store.Recommendation `json:"recommendations"`
+}
+
+// Empty is the request of calls without parameters
+type Empty struct{}
+
+// grpcServer implements the gRPC service on top of the same services and
+// stores as the REST handlers
+type grpcServer struct{}
+
+// newGRPCServer creates the gRPC server with the interceptor chain of the
+// REST router: the rate limit (when enabled), authentication, auditing, the
+// tier limit and the global concurrency limit (when enabled)
+func newGRPCServer() *grpc.Server {
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+	)
+	if rateLimiter != nil {
+		unary = append(unary, rateLimiter.UnaryServerInterceptor())
+		stream = append(stream, rateLimiter.StreamServerInterceptor())
+	}
+	unary = append(unary, auth.UnaryServerInterceptor(), auditUnaryInterceptor())
+	stream = append(stream, auth.StreamServerInterceptor(), auditStreamInterceptor())
+	if tierLimiter != nil {
+		unary = append(unary, tierLimiter.UnaryServerInterceptor())
+		stream = append(stream, tierLimiter.StreamServerInterceptor())
+	}
+	if globalLimiter != nil {
+		unary = append(unary, globalLimiter.UnaryServerInterceptor())
+		stream = append(stream, globalLimiter.StreamServerInterceptor())
+	}
+
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	s.RegisterService(&grpcServiceDesc, grpcServer{})
+	return s
+}
+
+// grpcReadMethods are the methods that only read, which are not audited,
+// like GET requests. Every other method is audited.
+var grpcReadMethods = map[string]bool{
+	"GetCosts":            true,
+	"StreamCosts":         true,
+	"GetCostSummary":      true,
+	"GetCostForecast":     true,
+	"ListRecommendations": true,
+	"ListProviders":       true,
+	"ListResources":       true,
+	"GetResource":         true,
+	"EvaluateResource":    true,
+}
+
+// auditUnaryInterceptor records each call to a method that is not read-only
+// with its outcome, as auditMiddleware does for REST requests. It must run
+// after authentication.
+func auditUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		recordGRPCAudit(ctx, info.FullMethod, req, err)
+		return resp, err
+	}
+}
+
+// auditStreamInterceptor records each streaming call to a method that is not
+// read-only once it ends. The request message is not recorded, since the
+// interceptor runs before it is received.
+func auditStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		recordGRPCAudit(ss.Context(), info.FullMethod, nil, err)
+		return err
+	}
+}
+
+// recordGRPCAudit records a call to the audit sink and log, under the GRPC
+// method and the full method name as path, with the HTTP status matching
+// the call's code
+func recordGRPCAudit(ctx context.Context, fullMethod string, req interface{}, callErr error) {
+	if auditSink == nil && auditLog == nil {
+		return
+	}
+	if grpcReadMethods[strings.TrimPrefix(fullMethod, "/"+grpcServiceName+"/")] {
+		return
+	}
+
+	entry := audit.Entry{
+		Time:     time.Now().UTC(),
+		TenantID: tenant.FromContext(ctx),
+		Method:   "GRPC",
+		Path:     fullMethod,
+		Status:   grpcHTTPStatus(status.Code(callErr)),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(entry.ClientIP); err == nil {
+			entry.ClientIP = host
+		}
+	}
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		entry.UserID = claims.UserID
+	}
+	if req != nil {
+		if body, err := json.Marshal(req); err == nil {
+			entry.Request = body
+		}
+	}
+
+	if auditSink != nil {
+		if err := auditSink.Record(entry); err != nil {
+			log.Printf("Failed to audit %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}
+	if auditLog != nil {
+		if err := auditLog.Record(entry); err != nil {
+			log.Printf("Failed to audit %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}
+}
+
+// grpcHTTPStatus returns the HTTP status a REST request failing like a call
+// with the code gets
+func grpcHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusUnprocessableEntity
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Canceled:
+		// The client closed the request, as proxies log it
+		return 499
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// stopGRPCServer stops the server gracefully, closing any calls still
+// running when ctx is done
+func stopGRPCServer(ctx context.Context, s *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.Stop()
+	}
+}
+
+func (grpcServer) GetCosts(ctx context.Context, req *CostQuery) (*cost.Analysis, error) {
+	filter, err := newCostFilter(req.Provider, req.Region, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return costService.Costs(ctx, filter), nil
+}
+
+// StreamCosts sends the matching line items one message at a time, like the
+// REST NDJSON stream
+func (grpcServer) StreamCosts(req *CostQuery, stream grpc.ServerStream) error {
+	filter, err := newCostFilter(req.Provider, req.Region, req.StartDate, req.EndDate)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return costService.Each(stream.Context(), filter, func(item cost.LineItem) error {
+		return stream.SendMsg(&item)
+	})
+}
+
+func (grpcServer) GetCostSummary(ctx context.Context, req *CostSummaryRequest) (*cost.Summary, error) {
+	filter, err := newCostFilter(req.Provider, req.Region, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	groupBy := req.GroupBy
+	if groupBy == "" {
+		groupBy = cost.GroupByService
+	}
+	summary, err := costService.Summary(ctx, filter, groupBy)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return summary, nil
+}
+
+func (grpcServer) GetCostForecast(ctx context.Context, req *CostForecastRequest) (*cost.Forecast, error) {
+	filter, err := newCostFilter(req.Provider, req.Region, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	horizon := req.Horizon
+	if horizon == 0 {
+		horizon = defaultForecastHorizon
+	}
+	opts := cost.ForecastOptions{Model: req.Model, Alpha: req.Alpha, Smoothing: req.Smoothing}
+	if opts.Model == "" {
+		opts.Model = cost.ModelLinear
+	}
+	forecast, err := costService.Forecast(ctx, filter, horizon, opts)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return forecast, nil
+}
+
+func (grpcServer) ListRecommendations(ctx context.Context, _ *Empty) (*RecommendationList, error) {
+	return &RecommendationList{Recommendations: openRecommendations(ctx, time.Now().UTC())}, nil
+}
+
+// ListProviders is not implemented yet, like GET /providers
+func (grpcServer) ListProviders(ctx context.Context, _ *Empty) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (grpcServer) ListResources(ctx context.Context, req *ResourceQuery) (*ResourceList, error) {
+	return &ResourceList{Resources: listResources(ctx, req.Provider, req.Region, req.Type)}, nil
+}
+
+func (grpcServer) GetResource(ctx context.Context, req *ResourceRequest) (*store.Resource, error) {
+	r, err := resourceStore.Get(ctx, req.ID)
+	if err != nil {
+		return nil, grpcStoreError(err, "resource not found")
+	}
+	return r, nil
+}
+
+func (grpcServer) EvaluateResource(ctx context.Context, req *ResourceRequest) (*placement.Evaluation, error) {
+	r, err := resourceStore.Get(ctx, req.ID)
+	if err != nil {
+		return nil, grpcStoreError(err, "resource not found")
+	}
+
+	eval, err := tenantEngine(ctx).Evaluate(r.Provider, r.Region, r.InstanceType)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return eval, nil
+}
+
+// grpcStoreError maps a store error to a gRPC status, as respondStoreError
+// does to an HTTP status
+func grpcStoreError(err error, notFoundMsg string) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return status.Error(codes.NotFound, notFoundMsg)
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// grpcServiceDesc describes the service for grpc.Server. Messages are plain
+// structs encoded by jsonCodec, so there is no generated code; each handler
+// decodes its request and runs the unary interceptor chain like generated
+// handlers do.
+var grpcServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCosts",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(CostQuery)
+				return unaryCall(srv, ctx, dec, interceptor, "GetCosts", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).GetCosts(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "GetCostSummary",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(CostSummaryRequest)
+				return unaryCall(srv, ctx, dec, interceptor, "GetCostSummary", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).GetCostSummary(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "GetCostForecast",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(CostForecastRequest)
+				return unaryCall(srv, ctx, dec, interceptor, "GetCostForecast", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).GetCostForecast(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "ListRecommendations",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(Empty)
+				return unaryCall(srv, ctx, dec, interceptor, "ListRecommendations", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).ListRecommendations(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "ListProviders",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(Empty)
+				return unaryCall(srv, ctx, dec, interceptor, "ListProviders", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).ListProviders(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "ListResources",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(ResourceQuery)
+				return unaryCall(srv, ctx, dec, interceptor, "ListResources", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).ListResources(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "GetResource",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(ResourceRequest)
+				return unaryCall(srv, ctx, dec, interceptor, "GetResource", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).GetResource(ctx, req)
+				})
+			},
+		},
+		{
+			MethodName: "EvaluateResource",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(ResourceRequest)
+				return unaryCall(srv, ctx, dec, interceptor, "EvaluateResource", req, func(ctx context.Context) (interface{}, error) {
+					return srv.(grpcServer).EvaluateResource(ctx, req)
+				})
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamCosts",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := new(CostQuery)
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(grpcServer).StreamCosts(req, stream)
+			},
+		},
+	},
+	Metadata: "docs/cloudoptimizer.proto",
+}
+
+// unaryCall decodes a unary request into req and calls the method, through
+// the interceptor chain when there is one. call reads req, which the
+// interceptors receive but do not replace.
+func unaryCall(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor,
+	method string, req interface{}, call func(context.Context) (interface{}, error)) (interface{}, error) {
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return call(ctx)
+	}
+
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + grpcServiceName + "/" + method}
+	return interceptor(ctx, req, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return call(ctx)
+	})
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"api-gateway-service/audit"
+	"api-gateway-service/auth"
+	"api-gateway-service/cost"
+	"api-gateway-service/redact"
+	"api-gateway-service/tenant"
+)
+
+const testGRPCAPIKey = "parity-key"
+
+// startGRPCParity serves the REST router and the gRPC server over the same
+// cost data, authenticating testGRPCAPIKey as tenant acme
+func startGRPCParity(t *testing.T) (*httptest.Server, *grpc.ClientConn) {
+	t.Helper()
+	viper.Set("concurrency.enabled", true)
+	viper.Set("concurrency.global.max_in_flight", 10)
+	t.Cleanup(func() {
+		viper.Set("concurrency.enabled", nil)
+		viper.Set("concurrency.global.max_in_flight", nil)
+	})
+
+	keys := auth.NewMemoryAPIKeyStore()
+	if err := keys.AddAPIKey(&auth.APIKey{ID: "k1", Hash: auth.HashAPIKey(testGRPCAPIKey), Tier: "pro", TenantID: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	auth.SetAPIKeyStore(keys)
+
+	prev := costService
+	costService = cost.NewService()
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	ingest := func(tenantID string, items ...cost.LineItem) {
+		if err := costService.Ingest(tenant.NewContext(context.Background(), tenantID), items); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ingest("acme",
+		cost.LineItem{Date: day, Provider: "aws", Service: "ec2", Region: "us-east-1", Amount: 120, Currency: "USD"},
+		cost.LineItem{Date: day.AddDate(0, 0, 1), Provider: "aws", Service: "s3", Region: "us-east-1", Amount: 30, Currency: "USD"},
+		cost.LineItem{Date: day.AddDate(0, 0, 2), Provider: "gcp", Service: "gce", Region: "us-east1", Amount: 50, Currency: "USD"},
+	)
+	ingest("globex", cost.LineItem{Date: day, Provider: "aws", Service: "ec2", Region: "us-east-1", Amount: 999, Currency: "USD"})
+
+	rest := httptest.NewServer(setupRouter())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newGRPCServer()
+	go srv.Serve(lis)
+
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsonCodec{}.Name())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		srv.Stop()
+		rest.Close()
+		costService = prev
+		auth.SetAPIKeyStore(nil)
+		globalLimiter = nil
+	})
+	return rest, conn
+}
+
+// GetCostSummary answers as GET /api/v1/costs/summary does for the same
+// caller and query, including the errors
+func TestGRPCCostSummaryParity(t *testing.T) {
+	rest, conn := startGRPCParity(t)
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		req       CostSummaryRequest
+		wantHTTP  int
+		wantCode  codes.Code
+		wantTotal float64
+	}{
+		{name: "grouped by service", apiKey: testGRPCAPIKey, req: CostSummaryRequest{CostQuery: CostQuery{StartDate: "2026-09-01", EndDate: "2026-09-30"}}, wantHTTP: http.StatusOK, wantTotal: 200},
+		{name: "grouped by provider", apiKey: testGRPCAPIKey, req: CostSummaryRequest{CostQuery: CostQuery{StartDate: "2026-09-01", EndDate: "2026-09-30"}, GroupBy: cost.GroupByProvider}, wantHTTP: http.StatusOK, wantTotal: 200},
+		{name: "filtered by provider", apiKey: testGRPCAPIKey, req: CostSummaryRequest{CostQuery: CostQuery{Provider: "aws", StartDate: "2026-09-01", EndDate: "2026-09-30"}}, wantHTTP: http.StatusOK, wantTotal: 150},
+		{name: "invalid group", apiKey: testGRPCAPIKey, req: CostSummaryRequest{CostQuery: CostQuery{StartDate: "2026-09-01", EndDate: "2026-09-30"}, GroupBy: "colour"}, wantHTTP: http.StatusBadRequest, wantCode: codes.InvalidArgument},
+		{name: "invalid date", apiKey: testGRPCAPIKey, req: CostSummaryRequest{CostQuery: CostQuery{StartDate: "September"}}, wantHTTP: http.StatusBadRequest, wantCode: codes.InvalidArgument},
+		{name: "unknown API key", apiKey: "guess", req: CostSummaryRequest{}, wantHTTP: http.StatusUnauthorized, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{}
+			for k, v := range map[string]string{"provider": tt.req.Provider, "start_date": tt.req.StartDate, "end_date": tt.req.EndDate, "group_by": tt.req.GroupBy} {
+				if v != "" {
+					query.Set(k, v)
+				}
+			}
+			httpReq, err := http.NewRequest(http.MethodGet, rest.URL+"/api/v1/costs/summary?"+query.Encode(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			httpReq.Header.Set("X-API-Key", tt.apiKey)
+			resp, err := http.DefaultClient.Do(httpReq)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			var restSummary cost.Summary
+			if err := json.NewDecoder(resp.Body).Decode(&restSummary); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", tt.apiKey)
+			var grpcSummary cost.Summary
+			err = conn.Invoke(ctx, "/"+grpcServiceName+"/GetCostSummary", &tt.req, &grpcSummary)
+
+			if resp.StatusCode != tt.wantHTTP || status.Code(err) != tt.wantCode {
+				t.Fatalf("REST status %d and gRPC code %s, want %d and %s (gRPC error %v)", resp.StatusCode, status.Code(err), tt.wantHTTP, tt.wantCode, err)
+			}
+			if grpcHTTPStatus(status.Code(err)) != resp.StatusCode {
+				t.Errorf("gRPC code %s maps to %d, REST answered %d", status.Code(err), grpcHTTPStatus(status.Code(err)), resp.StatusCode)
+			}
+			if restSummary.TotalCost != tt.wantTotal {
+				t.Errorf("REST total %v, want %v of the caller's tenant only", restSummary.TotalCost, tt.wantTotal)
+			}
+			if err == nil && !reflect.DeepEqual(restSummary, grpcSummary) {
+				t.Errorf("gRPC summary %+v, REST summary %+v", grpcSummary, restSummary)
+			}
+		})
+	}
+}
+
+func TestGRPCAuditsNonReadMethods(t *testing.T) {
+	redactor, err := redact.New(redact.DefaultFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := auditLog
+	auditLog = audit.NewLog(10, redactor)
+	t.Cleanup(func() { auditLog = prev })
+
+	ctx := tenant.NewContext(context.Background(), "acme")
+	interceptor := auditUnaryInterceptor()
+	call := func(method string, handlerErr error) {
+		info := &grpc.UnaryServerInfo{FullMethod: "/" + grpcServiceName + "/" + method}
+		interceptor(ctx, &ResourceRequest{ID: "r1"}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		})
+	}
+	call("GetCostSummary", nil)
+	call("ApplyRecommendation", status.Error(codes.NotFound, "recommendation not found"))
+
+	entries := auditLog.After("acme", 0, "")
+	if len(entries) != 1 {
+		t.Fatalf("audited %d calls, want only the non-read one", len(entries))
+	}
+	e := entries[0]
+	if e.Method != "GRPC" || e.Path != "/"+grpcServiceName+"/ApplyRecommendation" || e.Status != http.StatusNotFound || string(e.Request) != `{"id":"r1"}` {
+		t.Errorf("entry %+v", e)
+	}
+}
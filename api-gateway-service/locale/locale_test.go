@@ -0,0 +1,118 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "de-DE", want: "de-DE"},
+		{tag: "en_gb", want: "en-GB"},
+		{tag: " FR-fr ", want: "fr-FR"},
+		{tag: "de-AT", want: "de-DE"},
+		{tag: "ja", want: "ja-JP"},
+		{tag: "pt-BR", wantErr: true},
+		{tag: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		l, err := Lookup(tt.tag)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Lookup(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			continue
+		}
+		if l.Tag != tt.want {
+			t.Errorf("Lookup(%q) = %s, want %s", tt.tag, l.Tag, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "de-DE,de;q=0.9,en;q=0.8", want: "de-DE"},
+		{header: "pt-BR, en-GB;q=0.7, fr;q=0.9", want: "fr-FR"},
+		{header: "en;q=0.5, ja;q=0.8", want: "ja-JP"},
+		{header: "fr;q=0, en-GB;q=0.1", want: "en-GB"},
+		{header: "*", want: ""},
+		{header: "pt-BR, es", want: ""},
+		{header: "de;q=x, en-GB", want: "en-GB"},
+		{header: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		l, ok := Negotiate(tt.header)
+		if ok != (tt.want != "") || l.Tag != tt.want {
+			t.Errorf("Negotiate(%q) = %q, %v, want %q", tt.header, l.Tag, ok, tt.want)
+		}
+	}
+}
+
+// The same amounts and dates are written in each locale's conventions
+func TestFormat(t *testing.T) {
+	date := time.Date(2026, 3, 4, 17, 5, 0, 0, time.UTC)
+	tests := []struct {
+		tag      string
+		money    []string
+		date     string
+		dateTime string
+	}{
+		{
+			tag:      "en-US",
+			money:    []string{"$1,234,567.89", "-$0.50", "€12.00", "¥1,235", "CHF\u00a010.00", "$0.00"},
+			date:     "03/04/2026",
+			dateTime: "03/04/2026 5:05 PM",
+		},
+		{
+			tag:      "de-DE",
+			money:    []string{"1.234.567,89\u00a0$", "-0,50\u00a0$", "12,00\u00a0€", "1.235\u00a0¥", "10,00\u00a0CHF", "0,00\u00a0$"},
+			date:     "04.03.2026",
+			dateTime: "04.03.2026 17:05",
+		},
+		{
+			tag:      "fr-FR",
+			money:    []string{"1\u202f234\u202f567,89\u00a0$", "-0,50\u00a0$", "12,00\u00a0€", "1\u202f235\u00a0¥", "10,00\u00a0CHF", "0,00\u00a0$"},
+			date:     "04/03/2026",
+			dateTime: "04/03/2026 17:05",
+		},
+	}
+	amounts := []struct {
+		amount   float64
+		currency string
+	}{
+		{1234567.891, "USD"},
+		{-0.5, ""},
+		{12, "eur"},
+		{1234.6, "JPY"},
+		{10, "CHF"},
+		// Rounds to zero, so no sign is written
+		{-0.001, "USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			l, err := Lookup(tt.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, a := range amounts {
+				if got := l.Money(a.amount, a.currency); got != tt.money[i] {
+					t.Errorf("Money(%v, %q) = %q, want %q", a.amount, a.currency, got, tt.money[i])
+				}
+			}
+			if got := l.Date(date); got != tt.date {
+				t.Errorf("Date() = %q, want %q", got, tt.date)
+			}
+			if got := l.DateTime(date); got != tt.dateTime {
+				t.Errorf("DateTime() = %q, want %q", got, tt.dateTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/locale"
+)
+
+// responseLocale returns the locale cost responses are formatted for: the
+// locale query parameter, else the best match for the Accept-Language header,
+// else locale.default. ok is false when none applies, in which case responses
+// keep their plain, unformatted form.
+func responseLocale(c *gin.Context) (l locale.Locale, ok bool, err error) {
+	if tag := c.Query("locale"); tag != "" {
+		l, err = locale.Lookup(tag)
+		return l, err == nil, err
+	}
+	if l, ok := locale.Negotiate(c.GetHeader("Accept-Language")); ok {
+		return l, true, nil
+	}
+	if tag := viper.GetString("locale.default"); tag != "" {
+		l, err = locale.Lookup(tag)
+		return l, err == nil, err
+	}
+	return locale.Locale{}, false, nil
+}
+
+// respondLocalized writes v as JSON. When the request has a locale, the
+// response also carries a "formatted" object holding the display strings built
+// by format, and a Content-Language header naming the locale.
+func respondLocalized(c *gin.Context, v interface{}, format func(l locale.Locale) map[string]string) {
+	l, ok, err := responseLocale(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusOK, v)
+		return
+	}
+
+	m := requirementsMap(v)
+	if m == nil {
+		c.JSON(http.StatusOK, v)
+		return
+	}
+	m["formatted"] = format(l)
+	c.Header("Content-Language", l.Tag)
+	c.JSON(http.StatusOK, m)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/audit"
+	"api-gateway-service/auth"
+	"api-gateway-service/redact"
+	"api-gateway-service/tenant"
+)
+
+var (
+	// redactor masks the fields matching redaction.fields wherever request and
+	// response payloads are logged or audited
+	redactor *redact.Redactor
+	// auditSink records mutating API requests; it is nil when audit.file is
+	// not set
+	auditSink *audit.FileSink
+	// auditLog keeps recent audit entries for the audit API; it is nil when
+	// audit.retain is 0
+	auditLog *audit.Log
+)
+
+// setupLogging builds the redactor and installs a default slog logger that
+// masks sensitive attributes, logging at debug level in debug mode
+func setupLogging() error {
+	r, err := redact.New(viper.GetStringSlice("redaction.fields"))
+	if err != nil {
+		return err
+	}
+	redactor = r
+
+	level := slog.LevelInfo
+	if viper.GetBool("debug") {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactor.ReplaceAttr,
+	})))
+
+	if path := viper.GetString("audit.file"); path != "" {
+		sink, err := audit.NewFileSink(path, redactor)
+		if err != nil {
+			return err
+		}
+		auditSink = sink
+	}
+	if retain := viper.GetInt("audit.retain"); retain > 0 {
+		auditLog = audit.NewLog(retain, redactor)
+	}
+	return nil
+}
+
+// bodyWriter captures the response body as it is written
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// bodyLogMiddleware logs redacted request and response bodies at debug level.
+// It is a no-op unless logging.bodies is set, since buffering every body is
+// costly.
+func bodyLogMiddleware() gin.HandlerFunc {
+	if !viper.GetBool("logging.bodies") {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		reqBody := readRequestBody(c)
+		w := &bodyWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		slog.Debug("request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.String("request_body", string(redactor.JSON(reqBody))),
+			slog.String("response_body", string(redactor.JSON(w.body.Bytes()))),
+		)
+	}
+}
+
+// auditMiddleware records each mutating request with its outcome to the audit
+// sink and log. It is a no-op when both are disabled.
+func auditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if (auditSink == nil && auditLog == nil) || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead ||
+			c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		reqBody := readRequestBody(c)
+		c.Next()
+
+		entry := audit.Entry{
+			Time:     time.Now().UTC(),
+			TenantID: tenant.FromContext(c.Request.Context()),
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			ClientIP: c.ClientIP(),
+			Request:  reqBody,
+		}
+		if claims, ok := c.Get("claims"); ok {
+			if userClaims, ok := claims.(*auth.Claims); ok {
+				entry.UserID = userClaims.UserID
+			}
+		}
+		if auditSink != nil {
+			if err := auditSink.Record(entry); err != nil {
+				log.Printf("Failed to audit %s %s: %v", entry.Method, entry.Path, err)
+			}
+		}
+		if auditLog != nil {
+			if err := auditLog.Record(entry); err != nil {
+				log.Printf("Failed to audit %s %s: %v", entry.Method, entry.Path, err)
+			}
+		}
+	}
+}
+
+// readRequestBody reads the request body and replaces it so handlers can still
+// bind it
+func readRequestBody(c *gin.Context) []byte {
+	if c.Request.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return body
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/audit"
+	"api-gateway-service/redact"
+	"api-gateway-service/tenant"
+)
+
+// Request and response bodies reach neither the debug log nor the audit sink
+// and log with their secrets
+func TestLoggingRedactsBodies(t *testing.T) {
+	r, err := redact.New(redact.DefaultFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := audit.NewFileSink(path, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logged bytes.Buffer
+	prevRedactor, prevSink, prevLog, prevLogger := redactor, auditSink, auditLog, slog.Default()
+	redactor, auditSink, auditLog = r, sink, audit.NewLog(10, r)
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: r.ReplaceAttr})))
+	viper.Set("logging.bodies", true)
+	t.Cleanup(func() {
+		sink.Close()
+		redactor, auditSink, auditLog = prevRedactor, prevSink, prevLog
+		slog.SetDefault(prevLogger)
+		viper.Set("logging.bodies", nil)
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(bodyLogMiddleware(), auditMiddleware())
+	router.POST("/keys", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "k1", "api_key": "k-456"})
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/keys", strings.NewReader(`{"name":"ci","client_secret":"s-123"}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d", w.Code)
+	}
+
+	audited, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := auditLog.After(tenant.DefaultID, 0, "")
+	if len(entries) != 1 {
+		t.Fatalf("%d audit log entries, want 1", len(entries))
+	}
+	for name, out := range map[string]string{"debug log": logged.String(), "audit file": string(audited), "audit log": string(entries[0].Request)} {
+		for _, secret := range []string{"s-123", "k-456"} {
+			if strings.Contains(out, secret) {
+				t.Errorf("%s %q contains %q", name, out, secret)
+			}
+		}
+		if !strings.Contains(out, "ci") {
+			t.Errorf("%s %q, want the request recorded", name, out)
+		}
+	}
+}
@@ -3,22 +3,140 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+
+	"api-gateway-service/auth"
+	"api-gateway-service/locale"
+	"api-gateway-service/middleware"
+	"api-gateway-service/placement"
+	"api-gateway-service/redact"
+	"api-gateway-service/signing"
+	"api-gateway-service/store"
 )
 
+// apiVersion is the API version served by this gateway; supportedAPIVersions
+// lists every version clients may pin to.
+const apiVersion = "v1"
+
+var supportedAPIVersions = []string{"v1"}
+
+// rateLimiter limits requests per client when rate limiting is enabled
+var rateLimiter *middleware.RateLimiter
+
+// globalLimiter bounds the requests in flight over REST and gRPC alike when
+// concurrency limiting is enabled
+var globalLimiter *middleware.ConcurrencyLimiter
+
+// tierLimiter limits authenticated callers by their API key tier or roles
+// when rate limiting is enabled, over REST and gRPC alike
+var tierLimiter *middleware.TierRateLimiter
+
 func main() {
 	// Load configuration
 	if err := loadConfig(); err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Mask sensitive fields in logs and open the audit log
+	if err := setupLogging(); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	if auditSink != nil {
+		defer auditSink.Close()
+	}
+
+	// Reject an unsupported default locale before serving requests in it
+	if tag := viper.GetString("locale.default"); tag != "" {
+		if _, err := locale.Lookup(tag); err != nil {
+			log.Fatalf("Invalid locale.default: %v", err)
+		}
+	}
+
+	// Accept the API keys listed in config
+	if err := loadAPIKeys(); err != nil {
+		log.Fatalf("Failed to load API keys: %v", err)
+	}
+
+	// Order placement options with equal scores deterministically
+	tieBreak, err := placement.ParseTieBreak(viper.GetStringSlice("placements.tie_break"))
+	if err != nil {
+		log.Fatalf("Invalid placements.tie_break: %v", err)
+	}
+	var priceList placement.PriceListSource = placement.BuiltinPriceList{}
+	if path := viper.GetString("placements.price_list.file"); path != "" {
+		priceList = placement.FilePriceList{Path: path}
+	}
+	catalog, priceListDate, err := priceList.Load()
+	if err != nil {
+		log.Fatalf("Failed to load placement price list: %v", err)
+	}
+	if staleAfter := viper.GetDuration("placements.price_list.stale_after"); placement.PricesStale(priceListDate, staleAfter, time.Now()) {
+		log.Printf("Placement price list was last updated %s, more than %s ago; cost estimates may be outdated", priceListDate.Format(time.RFC3339), staleAfter)
+	}
+	placementEngine = placement.NewEngine(catalog,
+		placement.WithTieBreak(tieBreak),
+		placement.WithPriceList(priceListDate, viper.GetDuration("placements.price_list.stale_after")),
+		placement.WithScoreNormalization(viper.GetBool("placements.normalize_scores")))
+	if path := viper.GetString("placements.pricing_overrides_file"); path != "" {
+		overrides, err := placement.LoadPricingOverrides(path)
+		if err != nil {
+			log.Fatalf("Failed to load placement pricing overrides: %v", err)
+		}
+		pricingOverrides = overrides
+	}
+
+	// Sign placement results when a signing key is configured
+	if path := viper.GetString("placements.signing.key_file"); path != "" {
+		signer, err := signing.LoadSigner(path)
+		if err != nil {
+			log.Fatalf("Failed to load placement signing key: %v", err)
+		}
+		placementSigner = signer
+	}
+
+	// Load the analysis schedules saved before the last shutdown
+	schedules, err := store.NewScheduleStore(viper.GetString("schedules.state_file"), viper.GetInt("schedules.run_history"))
+	if err != nil {
+		log.Fatalf("Failed to load schedules: %v", err)
+	}
+	scheduleStore = schedules
+	webhookStore = store.NewWebhookStore(viper.GetInt("webhooks.delivery_history"))
+
+	// Resolve token signing keys and keep them refreshed for rotation
+	secretsCtx, stopSecrets := context.WithCancel(context.Background())
+	defer stopSecrets()
+	if err := auth.InitSecrets(secretsCtx); err != nil {
+		log.Fatalf("Failed to resolve auth secrets: %v", err)
+	}
+
+	// Apply deferred recommendations as their maintenance windows open, purge
+	// soft-deleted placements past retention, roll up old cost data and run
+	// scheduled analyses
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go runScheduledApplications(schedulerCtx, viper.GetDuration("recommendations.schedule_interval"))
+	go runPlacementPurge(schedulerCtx, viper.GetDuration("placements.purge_interval"))
+	go runCostRollup(schedulerCtx, viper.GetDuration("costs.retention.interval"))
+	go runSchedules(schedulerCtx, viper.GetDuration("schedules.interval"))
+	go runApprovalExpiry(schedulerCtx, viper.GetDuration("recommendations.approval.check_interval"))
+	if viper.GetBool("metrics.costs.enabled") {
+		// The metrics expose spend outside the API's authentication
+		if viper.GetString("metrics.costs.bearer_token") == "" {
+			log.Fatalf("metrics.costs.enabled requires metrics.costs.bearer_token")
+		}
+		go runCostMetrics(schedulerCtx, viper.GetDuration("metrics.costs.refresh_interval"))
+	}
+
 	// Initialize router
 	router := setupRouter()
 
@@ -37,6 +155,21 @@ func main() {
 		}
 	}()
 
+	// Serve the gRPC API on its own port
+	var grpcServer *grpc.Server
+	if viper.GetBool("grpc.enabled") {
+		lis, err := net.Listen("tcp", viper.GetString("grpc.address"))
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		grpcServer = newGRPCServer()
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -49,6 +182,10 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	stopDeliveries()
+	if grpcServer != nil {
+		stopGRPCServer(ctx, grpcServer)
+	}
 
 	log.Println("Server exited")
 }
@@ -63,10 +200,85 @@ func loadConfig() error {
 	viper.SetDefault("server.address", ":8080")
 	viper.SetDefault("server.read_timeout", 10*time.Second)
 	viper.SetDefault("server.write_timeout", 10*time.Second)
+	viper.SetDefault("grpc.enabled", false)
+	viper.SetDefault("grpc.address", ":9090")
 	viper.SetDefault("rate_limit.enabled", true)
 	viper.SetDefault("rate_limit.requests_per_second", 10)
+	viper.SetDefault("rate_limit.warning_threshold", 0.2)
+	viper.SetDefault("rate_limit.tiers.free.requests_per_second", 1)
+	viper.SetDefault("rate_limit.tiers.free.burst_size", 5)
+	viper.SetDefault("rate_limit.tiers.pro.requests_per_second", 20)
+	viper.SetDefault("rate_limit.tiers.pro.burst_size", 40)
+	viper.SetDefault("rate_limit.tiers.enterprise.requests_per_second", 100)
+	viper.SetDefault("rate_limit.tiers.enterprise.burst_size", 200)
+	viper.SetDefault("rate_limit.roles.admin.requests_per_second", 100)
+	viper.SetDefault("rate_limit.roles.admin.burst_size", 200)
+	viper.SetDefault("rate_limit.roles.standard.requests_per_second", 10)
+	viper.SetDefault("rate_limit.roles.standard.burst_size", 20)
+	viper.SetDefault("concurrency.enabled", true)
+	viper.SetDefault("concurrency.global.max_in_flight", 100)
+	viper.SetDefault("concurrency.global.max_queue", 200)
+	viper.SetDefault("concurrency.global.queue_timeout", 5*time.Second)
+	viper.SetDefault("concurrency.global.retry_after", time.Second)
+	viper.SetDefault("concurrency.analyze.max_in_flight", 10)
+	viper.SetDefault("concurrency.analyze.max_queue", 20)
+	viper.SetDefault("concurrency.analyze.queue_timeout", 10*time.Second)
+	viper.SetDefault("concurrency.analyze.retry_after", 5*time.Second)
+	viper.SetDefault("concurrency.scan.max_in_flight", 2)
+	viper.SetDefault("concurrency.scan.max_queue", 5)
+	viper.SetDefault("concurrency.scan.queue_timeout", 30*time.Second)
+	viper.SetDefault("concurrency.scan.retry_after", 30*time.Second)
 	viper.SetDefault("auth.jwt_secret", "")
 	viper.SetDefault("auth.token_expiry", 24*time.Hour)
+	viper.SetDefault("auth.clock_skew_leeway", 30*time.Second)
+	viper.SetDefault("auth.signing_method", "HS256")
+	viper.SetDefault("auth.bcrypt_cost", 10)
+	viper.SetDefault("auth.cookie_name", "")
+	viper.SetDefault("auth.csrf_cookie_name", "cloudopt_csrf")
+	viper.SetDefault("auth.csrf_header", "X-CSRF-Token")
+	viper.SetDefault("auth.secrets.provider", "config")
+	viper.SetDefault("auth.secrets.env_prefix", "CLOUDOPT_")
+	viper.SetDefault("auth.secrets.refresh_interval", 5*time.Minute)
+	viper.SetDefault("auth.secrets.rotation_overlap", time.Hour)
+	viper.SetDefault("recommendations.snooze_period", 7*24*time.Hour)
+	viper.SetDefault("recommendations.schedule_interval", time.Minute)
+	viper.SetDefault("recommendations.approval.threshold", 0)
+	viper.SetDefault("recommendations.approval.cost_increase_threshold", 0)
+	viper.SetDefault("recommendations.approval.expiry", 72*time.Hour)
+	viper.SetDefault("recommendations.approval.check_interval", time.Minute)
+	viper.SetDefault("placements.soft_delete_retention", 30*24*time.Hour)
+	viper.SetDefault("placements.purge_interval", time.Hour)
+	viper.SetDefault("placements.signing.key_file", "")
+	viper.SetDefault("placements.tie_break", placement.DefaultTieBreak)
+	viper.SetDefault("placements.price_list.file", "")
+	viper.SetDefault("placements.price_list.stale_after", 30*24*time.Hour)
+	viper.SetDefault("placements.normalize_scores", true)
+	viper.SetDefault("placements.pricing_overrides_file", "")
+	viper.SetDefault("costs.retention.daily_after", 7*24*time.Hour)
+	viper.SetDefault("costs.retention.monthly_after", 90*24*time.Hour)
+	viper.SetDefault("costs.retention.interval", time.Hour)
+	viper.SetDefault("costs.currency.base", "USD")
+	viper.SetDefault("schedules.state_file", "data/schedules.json")
+	viper.SetDefault("schedules.interval", 30*time.Second)
+	viper.SetDefault("schedules.run_history", 50)
+	viper.SetDefault("schedules.notification_timeout", 10*time.Second)
+	viper.SetDefault("webhooks.timeout", 10*time.Second)
+	viper.SetDefault("webhooks.max_attempts", 4)
+	viper.SetDefault("webhooks.initial_backoff", time.Second)
+	viper.SetDefault("webhooks.delivery_history", 100)
+	viper.SetDefault("webhooks.allow_private_targets", false)
+	viper.SetDefault("redaction.fields", redact.DefaultFields)
+	viper.SetDefault("logging.bodies", false)
+	viper.SetDefault("audit.file", "")
+	viper.SetDefault("audit.retain", 10000)
+	viper.SetDefault("locale.default", "")
+	viper.SetDefault("dashboard.section_timeout", 5*time.Second)
+	viper.SetDefault("metrics.costs.enabled", false)
+	viper.SetDefault("metrics.costs.refresh_interval", time.Minute)
+	viper.SetDefault("metrics.costs.window", 30*24*time.Hour)
+	viper.SetDefault("metrics.costs.max_label_values", 50)
+	viper.SetDefault("metrics.costs.bearer_token", "")
+	viper.SetDefault("metrics.costs.per_tenant", false)
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
@@ -77,42 +289,115 @@ func loadConfig() error {
 	return nil
 }
 
+// loadAPIKeys accepts the keys configured under auth.api_keys, each listed
+// with its id, tier, tenant_id, roles and the hex SHA-256 of the key as
+// key_sha256. API keys are not accepted when none are configured.
+func loadAPIKeys() error {
+	var keys []*auth.APIKey
+	if err := viper.UnmarshalKey("auth.api_keys", &keys); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	store := auth.NewMemoryAPIKeyStore()
+	for _, k := range keys {
+		if err := store.AddAPIKey(k); err != nil {
+			return err
+		}
+	}
+	auth.SetAPIKeyStore(store)
+	return nil
+}
+
 func setupRouter() *gin.Engine {
 	if !viper.GetBool("debug") {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	registerValidationFieldNames()
+
 	router := gin.New()
 	router.Use(gin.Recovery())
 
 	// Middleware
 	router.Use(corsMiddleware())
 	router.Use(loggerMiddleware())
+	router.Use(bodyLogMiddleware())
+	router.Use(versionMiddleware())
 	if viper.GetBool("rate_limit.enabled") {
+		rateLimiter = middleware.NewRateLimiter()
 		router.Use(rateLimitMiddleware())
 	}
 
 	// Health check
 	router.GET("/health", healthCheck)
 
+	// Prometheus cost metrics, authenticated by their own bearer token
+	if viper.GetBool("metrics.costs.enabled") {
+		router.GET("/metrics/costs", serveCostMetrics)
+	}
+
 	// API routes
 	api := router.Group("/api/v1")
-	api.Use(authMiddleware())
+	api.Use(auth.AuthMiddleware())
+	api.Use(auditMiddleware())
+	if viper.GetBool("rate_limit.enabled") {
+		tierLimiter = middleware.NewTierRateLimiter()
+		api.Use(tierLimiter.RateLimit())
+	}
+	if globalLimiter = concurrencyLimiter("global"); globalLimiter != nil {
+		api.Use(exceptStreaming(globalLimiter.Limit()))
+	}
 	{
+		api.GET("/version", getAPIVersion)
+		api.POST("/auth/refresh", refreshToken)
+
 		// Cost analysis endpoints
 		costs := api.Group("/costs")
 		{
 			costs.GET("", getCosts)
 			costs.GET("/summary", getCostSummary)
 			costs.GET("/forecast", getCostForecast)
+			costs.GET("/diff", getCostDiff)
+			costs.GET("/attribution", getCostAttribution)
+			costs.POST("/import", importCosts)
 		}
 
 		// Resource optimization endpoints
 		optimize := api.Group("/optimize")
 		{
-			optimize.POST("/analyze", analyzeResources)
+			optimize.POST("/analyze", concurrencyMiddleware("analyze"), analyzeResources)
 			optimize.GET("/recommendations", getRecommendations)
+			optimize.GET("/recommendations/feedback", getRecommendationFeedback)
+			optimize.POST("/recommendations/:id/feedback", recordRecommendationFeedback)
 			optimize.POST("/apply", applyRecommendations)
+			optimize.POST("/apply/:id/approve", auth.AnyRoleMiddleware("admin", "approver"), approveApplyJob)
+			optimize.POST("/apply/:id/reject", auth.AnyRoleMiddleware("admin", "approver"), rejectApplyJob)
+			optimize.GET("/jobs/:id", getApplyJob)
+			optimize.GET("/jobs/:id/events", streamApplyJob)
+		}
+
+		// Scheduled analysis endpoints, scoped to the caller's tenant
+		schedules := api.Group("/schedules")
+		{
+			schedules.GET("", listSchedules)
+			schedules.POST("", createSchedule)
+			schedules.GET("/:id", getSchedule)
+			schedules.DELETE("/:id", deleteSchedule)
+			schedules.GET("/:id/runs", listScheduleRuns)
+			schedules.GET("/:id/runs/:run_id", getScheduleRun)
+		}
+
+		// Placement webhook endpoints, scoped to the caller's tenant
+		webhooks := api.Group("/webhooks")
+		{
+			webhooks.GET("", listWebhooks)
+			webhooks.POST("", createWebhook)
+			webhooks.GET("/:id", getWebhook)
+			webhooks.DELETE("/:id", deleteWebhook)
+			webhooks.GET("/:id/deliveries", listWebhookDeliveries)
 		}
 
 		// Provider management endpoints
@@ -120,6 +405,7 @@ func setupRouter() *gin.Engine {
 		{
 			providers.GET("", getProviders)
 			providers.GET("/:provider", getProviderDetails)
+			providers.GET("/:provider/capabilities", getProviderCapabilities)
 			providers.POST("/:provider/connect", connectProvider)
 			providers.DELETE("/:provider/disconnect", disconnectProvider)
 		}
@@ -129,9 +415,44 @@ func setupRouter() *gin.Engine {
 		{
 			resources.GET("", getResources)
 			resources.GET("/:id", getResource)
-			resources.POST("/scan", scanResources)
+			resources.GET("/:id/evaluation", evaluateResource)
+			resources.POST("/scan", concurrencyMiddleware("scan"), scanResources)
+			resources.POST("/import", importResources)
 			resources.POST("/tag", tagResources)
 		}
+
+		// Dashboard endpoint, aggregating the sections below for the
+		// caller's tenant
+		api.GET("/dashboard", getDashboard)
+
+		// Compliance endpoints
+		api.GET("/compliance/frameworks", listComplianceFrameworks)
+		api.GET("/compliance/evidence", getComplianceEvidence)
+
+		// Audit log endpoints, admin only and scoped to the caller's tenant
+		if auditLog != nil {
+			auditRoutes := api.Group("/audit", auth.RoleMiddleware("admin"))
+			{
+				auditRoutes.GET("", listAuditEntries)
+				auditRoutes.GET("/stream", streamAuditEntries)
+			}
+		}
+
+		// Placement endpoints, scoped to the caller's tenant
+		placements := api.Group("/placements")
+		{
+			placements.GET("", listAllPlacements)
+			placements.GET("/:type", listPlacements)
+			placements.POST("/:type", createPlacement)
+			placements.POST("/:type/adopt", adoptPlacement)
+			placements.POST("/:type/group", createPlacementGroup)
+			placements.POST("/:type/frontier", computeFrontier)
+			placements.GET("/:type/:id", getPlacement)
+			placements.GET("/:type/:id/history", getPlacementHistory)
+			placements.PUT("/:type/:id", updatePlacement)
+			placements.DELETE("/:type/:id", deletePlacement)
+			placements.POST("/:type/:id/restore", restorePlacement)
+		}
 	}
 
 	return router
@@ -142,7 +463,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, X-API-Key, "+viper.GetString("auth.csrf_header"))
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -159,18 +480,40 @@ func loggerMiddleware() gin.HandlerFunc {
 	})
 }
 
-func rateLimitMiddleware() gin.HandlerFunc {
-	// TODO: Implement rate limiting
+func versionMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		c.Header("X-API-Version", apiVersion)
 		c.Next()
 	}
 }
 
-func authMiddleware() gin.HandlerFunc {
-	// TODO: Implement JWT authentication
-	return func(c *gin.Context) {
-		c.Next()
+func rateLimitMiddleware() gin.HandlerFunc {
+	return rateLimiter.RateLimit()
+}
+
+// concurrencyMiddleware limits in-flight requests using the limits configured
+// under concurrency.<name>. It is a no-op when concurrency limiting is disabled.
+func concurrencyMiddleware(name string) gin.HandlerFunc {
+	limiter := concurrencyLimiter(name)
+	if limiter == nil {
+		return func(c *gin.Context) { c.Next() }
 	}
+	return limiter.Limit()
+}
+
+// concurrencyLimiter creates a limiter with the limits configured under
+// concurrency.<name>, or returns nil when concurrency limiting is disabled
+func concurrencyLimiter(name string) *middleware.ConcurrencyLimiter {
+	if !viper.GetBool("concurrency.enabled") {
+		return nil
+	}
+
+	return middleware.NewConcurrencyLimiter(middleware.ConcurrencyConfig{
+		MaxInFlight:  viper.GetInt("concurrency." + name + ".max_in_flight"),
+		MaxQueue:     viper.GetInt("concurrency." + name + ".max_queue"),
+		QueueTimeout: viper.GetDuration("concurrency." + name + ".queue_timeout"),
+		RetryAfter:   viper.GetDuration("concurrency." + name + ".retry_after"),
+	})
 }
 
 // Handler implementations
@@ -181,72 +524,69 @@ func healthCheck(c *gin.Context) {
 	})
 }
 
-func getCosts(c *gin.Context) {
-	// TODO: Implement cost retrieval
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
-
-func getCostSummary(c *gin.Context) {
-	// TODO: Implement cost summary
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
-
-func getCostForecast(c *gin.Context) {
-	// TODO: Implement cost forecast
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
-
-func analyzeResources(c *gin.Context) {
-	// TODO: Implement resource analysis
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+func getAPIVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"version":            apiVersion,
+		"supported_versions": supportedAPIVersions,
+	})
 }
 
-func getRecommendations(c *gin.Context) {
-	// TODO: Implement recommendations retrieval
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
+// refreshToken exchanges the caller's bearer token, while still valid, for one
+// with a fresh expiry
+func refreshToken(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" || token == c.GetHeader("Authorization") {
+		respondError(c, http.StatusBadRequest, "only bearer tokens can be refreshed")
+		return
+	}
 
-func applyRecommendations(c *gin.Context) {
-	// TODO: Implement recommendations application
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	refreshed, err := auth.Refresh(token)
+	if err != nil {
+		respondError(c, http.StatusUnauthorized, "invalid token")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"token": refreshed})
 }
 
 func getProviders(c *gin.Context) {
 	// TODO: Implement providers list
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	respondError(c, http.StatusNotImplemented, "Not implemented")
 }
 
 func getProviderDetails(c *gin.Context) {
 	// TODO: Implement provider details
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	respondError(c, http.StatusNotImplemented, "Not implemented")
+}
+
+// getProviderCapabilities reports the features, instance families,
+// compliance frameworks and spot availability the provider offers, in one
+// region when ?region is set
+func getProviderCapabilities(c *gin.Context) {
+	p, err := placementEngine.Catalog().Provider(c.Param("provider"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	caps, err := p.Capabilities(c.Query("region"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, caps)
 }
 
 func connectProvider(c *gin.Context) {
 	// TODO: Implement provider connection
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	respondError(c, http.StatusNotImplemented, "Not implemented")
 }
 
 func disconnectProvider(c *gin.Context) {
 	// TODO: Implement provider disconnection
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
-
-func getResources(c *gin.Context) {
-	// TODO: Implement resources list
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
-
-func getResource(c *gin.Context) {
-	// TODO: Implement resource details
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	respondError(c, http.StatusNotImplemented, "Not implemented")
 }
 
 func scanResources(c *gin.Context) {
 	// TODO: Implement resource scanning
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
-}
-
-func tagResources(c *gin.Context) {
-	// TODO: Implement resource tagging
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	respondError(c, http.StatusNotImplemented, "Not implemented")
 }
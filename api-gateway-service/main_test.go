@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"api-gateway-service/placement"
+)
+
+func TestGetProviderCapabilities(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantRegions []string
+	}{
+		{path: "/api/v1/providers/gcp/capabilities", wantStatus: http.StatusOK, wantRegions: []string{"us-central1", "us-east1", "europe-west1"}},
+		{path: "/api/v1/providers/gcp/capabilities?region=europe-west1", wantStatus: http.StatusOK, wantRegions: []string{"europe-west1"}},
+		{path: "/api/v1/providers/gcp/capabilities?region=eu-west-1", wantStatus: http.StatusNotFound},
+		{path: "/api/v1/providers/oracle/capabilities", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := callAs(router, "acme", http.MethodGet, tt.path, "")
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var caps placement.Capabilities
+			if err := json.Unmarshal(w.Body.Bytes(), &caps); err != nil {
+				t.Fatal(err)
+			}
+			if caps.Provider != "gcp" || len(caps.InstanceFamilies) == 0 || len(caps.Regions) != len(tt.wantRegions) {
+				t.Fatalf("capabilities %+v, want gcp in %v", caps, tt.wantRegions)
+			}
+			for i, r := range caps.Regions {
+				if r.Region != tt.wantRegions[i] || len(r.ComplianceFrameworks) == 0 {
+					t.Errorf("region %d %+v, want %s with its frameworks", i, r, tt.wantRegions[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Package maintenance models the recurring windows in which disruptive
+// changes may be applied.
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// clockLayout is the time-of-day format of window ranges
+const clockLayout = "15:04"
+
+// Window is a set of weekly recurring time ranges in a timezone. A change is
+// inside the window if it falls within any range.
+type Window struct {
+	Timezone string  `json:"timezone"`
+	Ranges   []Range `json:"ranges"`
+}
+
+// Range is a daily time range on the given weekdays (e.g. "sat", "sun"; empty
+// means every day). End is exclusive; an End at or before Start wraps past
+// midnight into the following day.
+type Range struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Validate checks the timezone, days and clock times of the window
+func (w *Window) Validate() error {
+	if _, err := w.location(); err != nil {
+		return err
+	}
+	if len(w.Ranges) == 0 {
+		return fmt.Errorf("maintenance window requires at least one range")
+	}
+
+	for i, r := range w.Ranges {
+		if _, err := parseClock(r.Start); err != nil {
+			return fmt.Errorf("range %d: invalid start %q (expected HH:MM)", i, r.Start)
+		}
+		if _, err := parseClock(r.End); err != nil {
+			return fmt.Errorf("range %d: invalid end %q (expected HH:MM)", i, r.End)
+		}
+		for _, day := range r.Days {
+			if _, ok := weekdays[strings.ToLower(day)]; !ok {
+				return fmt.Errorf("range %d: invalid day %q (expected sun, mon, ... sat)", i, day)
+			}
+		}
+	}
+	return nil
+}
+
+// Contains reports whether t falls inside the window
+func (w *Window) Contains(t time.Time) bool {
+	loc, err := w.location()
+	if err != nil {
+		return false
+	}
+	t = t.In(loc)
+
+	for _, r := range w.Ranges {
+		// A range that wraps midnight may have started the previous day
+		for _, offset := range []int{0, -1} {
+			start, end, ok := r.occurrence(t.AddDate(0, 0, offset))
+			if ok && !t.Before(start) && t.Before(end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Next returns t if it is inside the window, otherwise the start of the next
+// range occurrence after t. The zero time is returned if the window is invalid.
+func (w *Window) Next(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+
+	loc, err := w.location()
+	if err != nil {
+		return time.Time{}
+	}
+	local := t.In(loc)
+
+	var next time.Time
+	for offset := 0; offset <= 7; offset++ {
+		day := local.AddDate(0, 0, offset)
+		for _, r := range w.Ranges {
+			start, _, ok := r.occurrence(day)
+			if !ok || !start.After(t) {
+				continue
+			}
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+		}
+	}
+	return next
+}
+
+func (w *Window) location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %v", w.Timezone, err)
+	}
+	return loc, nil
+}
+
+// occurrence returns the range's start and end on the day of t, in t's
+// location, or false if the range does not run that day
+func (r *Range) occurrence(t time.Time) (time.Time, time.Time, bool) {
+	if !r.runsOn(t.Weekday()) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	startClock, err := parseClock(r.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endClock, err := parseClock(r.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	start := midnight.Add(startClock)
+	end := midnight.Add(endClock)
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, true
+}
+
+func (r *Range) runsOn(day time.Weekday) bool {
+	if len(r.Days) == 0 {
+		return true
+	}
+	for _, d := range r.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock returns the offset from midnight of an HH:MM time of day
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
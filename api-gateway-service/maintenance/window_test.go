@@ -0,0 +1,61 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  Window
+		wantErr bool
+	}{
+		{name: "valid", window: Window{Timezone: "Europe/Berlin", Ranges: []Range{{Days: []string{"Sat", "sun"}, Start: "22:00", End: "04:00"}}}},
+		{name: "no ranges", window: Window{}, wantErr: true},
+		{name: "unknown timezone", window: Window{Timezone: "Mars/Olympus", Ranges: []Range{{Start: "01:00", End: "02:00"}}}, wantErr: true},
+		{name: "invalid clock", window: Window{Ranges: []Range{{Start: "25:00", End: "02:00"}}}, wantErr: true},
+		{name: "invalid day", window: Window{Ranges: []Range{{Days: []string{"someday"}, Start: "01:00", End: "02:00"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.window.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWindowNext(t *testing.T) {
+	// Saturday 22:00 to Sunday 04:00 Berlin time (UTC+2 in October)
+	window := &Window{Timezone: "Europe/Berlin", Ranges: []Range{{Days: []string{"sat"}, Start: "22:00", End: "04:00"}}}
+	opening := time.Date(2026, 10, 17, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		inside bool
+		want   time.Time
+	}{
+		{name: "before the window", at: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), want: opening},
+		{name: "at its start", at: opening, inside: true},
+		{name: "past midnight", at: time.Date(2026, 10, 18, 1, 30, 0, 0, time.UTC), inside: true},
+		{name: "at its end", at: time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC), want: opening.AddDate(0, 0, 7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := window.Contains(tt.at); got != tt.inside {
+				t.Errorf("Contains(%v) = %v, want %v", tt.at, got, tt.inside)
+			}
+			want := tt.want
+			if tt.inside {
+				want = tt.at
+			}
+			if got := window.Next(tt.at); !got.Equal(want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.at, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+)
+
+const (
+	// mimePrometheusText is the content type of the Prometheus text format
+	mimePrometheusText = "text/plain; version=0.0.4; charset=utf-8"
+	// otherLabelValue replaces the label values beyond the cardinality limit
+	otherLabelValue = "other"
+)
+
+// costMetrics holds the cost gauges last rendered in the Prometheus text
+// format. They are rebuilt on an interval so scrapes never aggregate line
+// items themselves.
+type costMetrics struct {
+	mu   sync.RWMutex
+	text []byte
+}
+
+var costMetricsExporter = &costMetrics{}
+
+// runCostMetrics renders the cost gauges now and then on every interval
+func runCostMetrics(ctx context.Context, interval time.Duration) {
+	costMetricsExporter.refresh(time.Now().UTC())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			costMetricsExporter.refresh(now.UTC())
+		}
+	}
+}
+
+// refresh renders the spend over the trailing metrics.costs.window, summed
+// across tenants unless metrics.costs.per_tenant is set
+func (m *costMetrics) refresh(now time.Time) {
+	window := viper.GetDuration("metrics.costs.window")
+	totals := costService.Totals(cost.Filter{Start: now.Add(-window), End: now})
+	text := renderCostMetrics(totals, viper.GetInt("metrics.costs.max_label_values"), viper.GetBool("metrics.costs.per_tenant"), now)
+
+	m.mu.Lock()
+	m.text = text
+	m.mu.Unlock()
+}
+
+// serveCostMetrics serves the last rendered cost gauges to scrapers
+// presenting metrics.costs.bearer_token. Without a configured token every
+// scrape is refused; main also refuses to enable the endpoint without one.
+func serveCostMetrics(c *gin.Context) {
+	token := viper.GetString("metrics.costs.bearer_token")
+	got, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+		respondError(c, http.StatusUnauthorized, "invalid metrics token")
+		return
+	}
+
+	costMetricsExporter.mu.RLock()
+	text := costMetricsExporter.text
+	costMetricsExporter.mu.RUnlock()
+
+	c.Data(http.StatusOK, mimePrometheusText, text)
+}
+
+// costDimension is a breakdown of spend exported as its own gauge
+type costDimension struct {
+	metric string
+	label  string
+	help   string
+	value  func(cost.SpendTotal) string
+}
+
+var costDimensions = []costDimension{
+	{
+		metric: "cloudopt_cost_spend_by_provider",
+		label:  "provider",
+		help:   "Spend over the metrics window by cloud provider.",
+		value:  func(t cost.SpendTotal) string { return t.Provider },
+	},
+	{
+		metric: "cloudopt_cost_spend_by_service",
+		label:  "service",
+		help:   "Spend over the metrics window by service.",
+		value:  func(t cost.SpendTotal) string { return t.Service },
+	},
+	{
+		metric: "cloudopt_cost_spend_by_region",
+		label:  "region",
+		help:   "Spend over the metrics window by region.",
+		value:  func(t cost.SpendTotal) string { return t.Region },
+	},
+}
+
+// costSeries is one gauge sample
+type costSeries struct {
+	tenant, value, currency string
+	amount                  float64
+}
+
+// renderCostMetrics renders the spend totals as one gauge per dimension plus
+// the total. Spend is summed across tenants unless perTenant is set, when
+// each series is labelled by tenant. Each tenant keeps at most maxValues
+// values of a dimension, and at most maxValues tenants are labelled: the
+// largest maxValues-1 by spend and "other" for the rest, so the number of
+// series stays bounded however many tenants, services or regions appear.
+// maxValues <= 0 disables the limit.
+func renderCostMetrics(totals []cost.SpendTotal, maxValues int, perTenant bool, now time.Time) []byte {
+	if perTenant {
+		totals = limitTenants(totals, maxValues)
+	} else {
+		totals = withoutTenants(totals)
+	}
+	tenantLabel := func(s costSeries) string {
+		if !perTenant {
+			return ""
+		}
+		return "tenant=" + quoteLabel(s.tenant) + ","
+	}
+
+	var b bytes.Buffer
+
+	for _, d := range costDimensions {
+		writeMetricHeader(&b, d.metric, d.help)
+		for _, s := range limitCostSeries(totals, d.value, maxValues) {
+			fmt.Fprintf(&b, "%s{%s%s=%s,currency=%s} %s\n",
+				d.metric, tenantLabel(s), d.label, quoteLabel(s.value), quoteLabel(s.currency), formatSample(s.amount))
+		}
+	}
+
+	writeMetricHeader(&b, "cloudopt_cost_spend_total", "Total spend over the metrics window.")
+	for _, s := range limitCostSeries(totals, func(cost.SpendTotal) string { return "" }, 0) {
+		fmt.Fprintf(&b, "cloudopt_cost_spend_total{%scurrency=%s} %s\n",
+			tenantLabel(s), quoteLabel(s.currency), formatSample(s.amount))
+	}
+
+	writeMetricHeader(&b, "cloudopt_cost_metrics_refreshed_timestamp_seconds", "When the cost metrics were last computed.")
+	fmt.Fprintf(&b, "cloudopt_cost_metrics_refreshed_timestamp_seconds %d\n", now.Unix())
+	return b.Bytes()
+}
+
+// withoutTenants returns the totals with their tenant cleared, so that they
+// are summed across tenants
+func withoutTenants(totals []cost.SpendTotal) []cost.SpendTotal {
+	out := make([]cost.SpendTotal, len(totals))
+	for i, t := range totals {
+		t.TenantID = ""
+		out[i] = t
+	}
+	return out
+}
+
+// limitTenants returns the totals with the tenants beyond the largest
+// maxValues-1 by spend relabelled "other", when there are more than
+// maxValues tenants
+func limitTenants(totals []cost.SpendTotal, maxValues int) []cost.SpendTotal {
+	spend := make(map[string]float64)
+	for _, t := range totals {
+		spend[t.TenantID] += t.Amount
+	}
+	if maxValues <= 0 || len(spend) <= maxValues {
+		return totals
+	}
+
+	tenants := make([]string, 0, len(spend))
+	for id := range spend {
+		tenants = append(tenants, id)
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		if spend[tenants[i]] != spend[tenants[j]] {
+			return spend[tenants[i]] > spend[tenants[j]]
+		}
+		return tenants[i] < tenants[j]
+	})
+	kept := make(map[string]bool, maxValues-1)
+	for _, id := range tenants[:maxValues-1] {
+		kept[id] = true
+	}
+
+	out := make([]cost.SpendTotal, len(totals))
+	for i, t := range totals {
+		if !kept[t.TenantID] {
+			t.TenantID = otherLabelValue
+		}
+		out[i] = t
+	}
+	return out
+}
+
+// limitCostSeries sums the totals by tenant, dimension value and currency,
+// folding each tenant's smallest values into "other" beyond maxValues. The
+// series are sorted by tenant, value and currency.
+func limitCostSeries(totals []cost.SpendTotal, value func(cost.SpendTotal) string, maxValues int) []costSeries {
+	type key struct{ tenant, value, currency string }
+	sums := make(map[key]float64)
+	// spend ranks each tenant's values across currencies
+	spend := make(map[string]map[string]float64)
+	for _, t := range totals {
+		v := value(t)
+		sums[key{t.TenantID, v, t.Currency}] += t.Amount
+		if spend[t.TenantID] == nil {
+			spend[t.TenantID] = make(map[string]float64)
+		}
+		spend[t.TenantID][v] += t.Amount
+	}
+
+	folded := make(map[key]bool)
+	if maxValues > 0 {
+		for tenantID, byValue := range spend {
+			if len(byValue) <= maxValues {
+				continue
+			}
+			values := make([]string, 0, len(byValue))
+			for v := range byValue {
+				values = append(values, v)
+			}
+			sort.Slice(values, func(i, j int) bool {
+				if byValue[values[i]] != byValue[values[j]] {
+					return byValue[values[i]] > byValue[values[j]]
+				}
+				return values[i] < values[j]
+			})
+			for _, v := range values[maxValues-1:] {
+				folded[key{tenant: tenantID, value: v}] = true
+			}
+		}
+	}
+
+	merged := make(map[key]float64, len(sums))
+	for k, amount := range sums {
+		if folded[key{tenant: k.tenant, value: k.value}] {
+			k.value = otherLabelValue
+		}
+		merged[k] += amount
+	}
+
+	series := make([]costSeries, 0, len(merged))
+	for k, amount := range merged {
+		series = append(series, costSeries{tenant: k.tenant, value: k.value, currency: k.currency, amount: amount})
+	}
+	sort.Slice(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.tenant != b.tenant {
+			return a.tenant < b.tenant
+		}
+		if a.value != b.value {
+			return a.value < b.value
+		}
+		return a.currency < b.currency
+	})
+	return series
+}
+
+func writeMetricHeader(b *bytes.Buffer, metric, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", metric, help, metric)
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes and newlines
+// as the text format requires
+func quoteLabel(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
+
+func formatSample(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
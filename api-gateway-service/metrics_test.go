@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+)
+
+func TestServeCostMetricsAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics/costs", serveCostMetrics)
+
+	costMetricsExporter.mu.Lock()
+	costMetricsExporter.text = []byte("cloudopt_cost_spend_total{currency=\"USD\"} 1\n")
+	costMetricsExporter.mu.Unlock()
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{name: "no token configured", authorization: "Bearer ", want: http.StatusUnauthorized},
+		{name: "unauthenticated scrape", token: "scrape-secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "scrape-secret", authorization: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "token without bearer scheme", token: "scrape-secret", authorization: "scrape-secret", want: http.StatusUnauthorized},
+		{name: "valid token", token: "scrape-secret", authorization: "Bearer scrape-secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("metrics.costs.bearer_token", tt.token)
+			t.Cleanup(func() { viper.Set("metrics.costs.bearer_token", nil) })
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics/costs", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d", w.Code, tt.want)
+			}
+			if served := strings.Contains(w.Body.String(), "cloudopt_cost_spend_total"); served != (tt.want == http.StatusOK) {
+				t.Errorf("metrics served = %v with status %d", served, w.Code)
+			}
+		})
+	}
+}
+
+func TestRenderCostMetrics(t *testing.T) {
+	totals := []cost.SpendTotal{
+		{TenantID: "acme", Provider: "aws", Service: "ec2", Region: "us-east-1", Currency: "USD", Amount: 100},
+		{TenantID: "globex", Provider: "aws", Service: "ec2", Region: "us-east-1", Currency: "USD", Amount: 50},
+		{TenantID: "initech", Provider: "gcp", Service: "gce", Region: "us-east1", Currency: "USD", Amount: 10},
+	}
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		perTenant bool
+		maxValues int
+		want      []string
+		wantNot   []string
+	}{
+		{
+			name: "summed across tenants by default",
+			want: []string{
+				`cloudopt_cost_spend_by_provider{provider="aws",currency="USD"} 150`,
+				`cloudopt_cost_spend_total{currency="USD"} 160`,
+			},
+			wantNot: []string{"tenant="},
+		},
+		{
+			name:      "per tenant when enabled",
+			perTenant: true,
+			want: []string{
+				`cloudopt_cost_spend_by_provider{tenant="acme",provider="aws",currency="USD"} 100`,
+				`cloudopt_cost_spend_total{tenant="initech",currency="USD"} 10`,
+			},
+		},
+		{
+			name:      "tenants beyond the limit are summed as other",
+			perTenant: true,
+			maxValues: 2,
+			want: []string{
+				`cloudopt_cost_spend_total{tenant="acme",currency="USD"} 100`,
+				`cloudopt_cost_spend_total{tenant="other",currency="USD"} 60`,
+			},
+			wantNot: []string{`tenant="globex"`, `tenant="initech"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := string(renderCostMetrics(totals, tt.maxValues, tt.perTenant, now))
+			for _, line := range tt.want {
+				if !strings.Contains(text, line+"\n") {
+					t.Errorf("missing %s in:\n%s", line, text)
+				}
+			}
+			for _, s := range tt.wantNot {
+				if strings.Contains(text, s) {
+					t.Errorf("unexpected %s in:\n%s", s, text)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// batchHeader is the request header hinting how many pipelined requests a
+// client is sending together, so they are admitted or rejected as a whole
+const batchHeader = "X-RateLimit-Batch"
+
+// batchCreditTTL is how long tokens reserved for a batch stay available to
+// the batch's remaining requests
+const batchCreditTTL = 10 * time.Second
+
+// batchCredits tracks, per client, the tokens reserved by a batch that its
+// remaining requests have yet to use
+type batchCredits struct {
+	mu      sync.Mutex
+	credits map[string]batchCredit
+}
+
+type batchCredit struct {
+	remaining int
+	expires   time.Time
+}
+
+func newBatchCredits() *batchCredits {
+	return &batchCredits{credits: make(map[string]batchCredit)}
+}
+
+// admit decides whether a client's request may proceed. A request uses a
+// token reserved by the client's batch while any remain; otherwise a batch
+// of size requests reserves all of its tokens at once, and a single request
+// takes one. When a batch is rejected, wait is how long until enough tokens
+// are available.
+func (b *batchCredits) admit(clientID string, l *rate.Limiter, size int, now time.Time) (allowed bool, wait time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if credit, ok := b.credits[clientID]; ok {
+		if credit.remaining > 0 && now.Before(credit.expires) {
+			credit.remaining--
+			if credit.remaining == 0 {
+				delete(b.credits, clientID)
+			} else {
+				b.credits[clientID] = credit
+			}
+			return true, 0
+		}
+		delete(b.credits, clientID)
+	}
+
+	if size <= 1 {
+		return l.AllowN(now, 1), 0
+	}
+	if wait := reserveBatch(l, size, now); wait > 0 {
+		return false, wait
+	}
+	b.credits[clientID] = batchCredit{remaining: size - 1, expires: now.Add(batchCreditTTL)}
+	return true, 0
+}
+
+// expire drops the credits of batches whose reservation has lapsed
+func (b *batchCredits) expire(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for clientID, credit := range b.credits {
+		if !now.Before(credit.expires) {
+			delete(b.credits, clientID)
+		}
+	}
+}
+
+// reserveBatch takes n tokens from the limiter at once if they are all
+// available at now, returning 0. Otherwise it takes none and returns how long
+// until they will be. n must not exceed the limiter's burst.
+func reserveBatch(l *rate.Limiter, n int, now time.Time) time.Duration {
+	r := l.ReserveN(now, n)
+	if !r.OK() {
+		return rate.InfDuration
+	}
+	if wait := r.DelayFrom(now); wait > 0 {
+		r.CancelAt(now)
+		return wait
+	}
+	return 0
+}
+
+// batchSize returns the batch size hinted by the request, or 1 without a
+// hint. A batch may not exceed the burst, since it could never be admitted.
+func batchSize(c *gin.Context, burst int) (int, error) {
+	v := c.GetHeader(batchHeader)
+	if v == "" {
+		return 1, nil
+	}
+
+	size, err := strconv.Atoi(v)
+	if err != nil || size < 1 {
+		return 0, fmt.Errorf("invalid %s: %s (must be a positive integer)", batchHeader, v)
+	}
+	if size > burst {
+		return 0, fmt.Errorf("%s of %d exceeds the burst size of %d", batchHeader, size, burst)
+	}
+	return size, nil
+}
+
+// rejectBatch responds to a batch that cannot be admitted yet with the time
+// until enough tokens are available, in Retry-After rounded up to whole
+// seconds and in retry_after_ms
+func rejectBatch(c *gin.Context, size int, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":          fmt.Sprintf("rate limit exceeded for a batch of %d requests", size),
+		"retry_after_ms": int64(math.Ceil(float64(wait) / float64(time.Millisecond))),
+	})
+}
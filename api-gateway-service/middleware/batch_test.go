@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+func TestBatchCreditsAdmit(t *testing.T) {
+	type step struct {
+		client string
+		size   int
+		// after is the time since the first step
+		after       time.Duration
+		wantAllowed bool
+		wantWait    time.Duration
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "single requests take a token each",
+			steps: []step{
+				{size: 1, wantAllowed: true},
+				{size: 1, wantAllowed: true},
+				{size: 1, wantAllowed: true},
+				{size: 1, wantAllowed: true},
+				{size: 1, wantAllowed: false},
+			},
+		},
+		{
+			name: "a batch reserves its tokens for its remaining requests",
+			steps: []step{
+				{size: 3, wantAllowed: true},
+				{size: 3, wantAllowed: true},
+				{size: 3, wantAllowed: true},
+				{size: 1, wantAllowed: true},
+				{size: 1, wantAllowed: false},
+			},
+		},
+		{
+			name: "a batch larger than the tokens left is rejected whole",
+			steps: []step{
+				{size: 1, wantAllowed: true},
+				{size: 1, wantAllowed: true},
+				{size: 4, wantAllowed: false, wantWait: 2 * time.Second},
+				// The rejected batch took no tokens
+				{size: 2, wantAllowed: true},
+			},
+		},
+		{
+			name: "a rejected batch is admitted once the tokens refill",
+			steps: []step{
+				{size: 4, wantAllowed: true},
+				{size: 4, wantAllowed: true},
+				{size: 4, wantAllowed: true},
+				{size: 4, wantAllowed: true},
+				{size: 4, wantAllowed: false, wantWait: 4 * time.Second},
+				{size: 4, after: 4 * time.Second, wantAllowed: true},
+			},
+		},
+		{
+			name: "unused batch credits lapse",
+			steps: []step{
+				{size: 2, wantAllowed: true},
+				// The lapsed credit is dropped, so only the refilled tokens
+				// are left
+				{size: 1, after: batchCreditTTL, wantAllowed: true},
+				{size: 1, after: batchCreditTTL, wantAllowed: true},
+				{size: 1, after: batchCreditTTL, wantAllowed: true},
+				{size: 1, after: batchCreditTTL, wantAllowed: true},
+				{size: 1, after: batchCreditTTL, wantAllowed: false},
+			},
+		},
+		{
+			name: "credits are kept per client",
+			steps: []step{
+				{client: "a", size: 2, wantAllowed: true},
+				{client: "b", size: 1, wantAllowed: true},
+				{client: "b", size: 1, wantAllowed: true},
+				// b cannot use a's credit, but a still can
+				{client: "b", size: 1, wantAllowed: false},
+				{client: "a", size: 2, wantAllowed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			b := newBatchCredits()
+			// Every client shares one limiter of 4 tokens refilling at 1 per second
+			l := rate.NewLimiter(1, 4)
+			l.AllowN(start, 0)
+
+			for i, s := range tt.steps {
+				client := s.client
+				if client == "" {
+					client = "a"
+				}
+				allowed, wait := b.admit(client, l, s.size, start.Add(s.after))
+				if allowed != s.wantAllowed || wait != s.wantWait {
+					t.Fatalf("step %d: admit = %v, %v; want %v, %v", i+1, allowed, wait, s.wantAllowed, s.wantWait)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchCreditsExpire(t *testing.T) {
+	now := time.Now()
+	b := newBatchCredits()
+	b.credits["lapsed"] = batchCredit{remaining: 2, expires: now}
+	b.credits["live"] = batchCredit{remaining: 2, expires: now.Add(time.Second)}
+
+	b.expire(now)
+
+	if _, ok := b.credits["lapsed"]; ok {
+		t.Error("lapsed credit kept")
+	}
+	if _, ok := b.credits["live"]; !ok {
+		t.Error("live credit dropped")
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{header: "", want: 1},
+		{header: "1", want: 1},
+		{header: "5", want: 5},
+		{header: "6", wantErr: true},
+		{header: "0", wantErr: true},
+		{header: "-2", wantErr: true},
+		{header: "many", wantErr: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set(batchHeader, tt.header)
+			}
+
+			got, err := batchSize(c, 5)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("batchSize(%q) = %d, %v; want %d, error %v", tt.header, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRateLimitRejectsBatch(t *testing.T) {
+	rl := newTestRateLimiter(3)
+	rl.config.RequestsPerSecond = 1
+	r := newTestRouter(t, rl, newTestTierRateLimiter(5))
+
+	send := func(batch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set(batchHeader, batch)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("2"); w.Code != http.StatusOK {
+		t.Fatalf("first request of the batch: status %d, want 200", w.Code)
+	}
+	if w := send("2"); w.Code != http.StatusOK {
+		t.Fatalf("second request of the batch: status %d, want 200", w.Code)
+	}
+	w := send("3")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("batch over the tokens left: status %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, want 2", got)
+	}
+	if w := send("4"); w.Code != http.StatusBadRequest {
+		t.Errorf("batch over the burst: status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyLimiter bounds the number of requests in flight. Requests beyond
+// the limit wait in a bounded queue for a free slot; once the queue is full,
+// or a request has waited too long, it is rejected with 503 and Retry-After.
+type ConcurrencyLimiter struct {
+	slots  chan struct{}
+	queue  chan struct{}
+	config ConcurrencyConfig
+}
+
+// ConcurrencyConfig holds concurrency limiting configuration
+type ConcurrencyConfig struct {
+	MaxInFlight  int           `json:"max_in_flight"`
+	MaxQueue     int           `json:"max_queue"`
+	QueueTimeout time.Duration `json:"queue_timeout"`
+	RetryAfter   time.Duration `json:"retry_after"`
+}
+
+// NewConcurrencyLimiter creates a concurrency limiter. A MaxInFlight below 1
+// is treated as 1, and a zero RetryAfter as one second.
+func NewConcurrencyLimiter(config ConcurrencyConfig) *ConcurrencyLimiter {
+	if config.MaxInFlight < 1 {
+		config.MaxInFlight = 1
+	}
+	if config.MaxQueue < 0 {
+		config.MaxQueue = 0
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+
+	return &ConcurrencyLimiter{
+		slots:  make(chan struct{}, config.MaxInFlight),
+		queue:  make(chan struct{}, config.MaxQueue),
+		config: config,
+	}
+}
+
+// Limit creates a Gin middleware enforcing the concurrency limit
+func (l *ConcurrencyLimiter) Limit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !l.acquire(c.Request.Context()) {
+			retryAfter := int(math.Ceil(l.config.RetryAfter.Seconds()))
+			c.Header("Retry-After", fmt.Sprintf("%d", retryAfter))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error":       "server is at capacity",
+				"retry_after": fmt.Sprintf("%d seconds", retryAfter),
+			})
+			return
+		}
+		defer l.release()
+
+		c.Next()
+	}
+}
+
+// InFlight returns the number of requests currently holding a slot
+func (l *ConcurrencyLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Queued returns the number of requests waiting for a slot
+func (l *ConcurrencyLimiter) Queued() int {
+	return len(l.queue)
+}
+
+// acquire takes a slot, waiting in the queue if none is free. It reports
+// false when the queue is full, the wait times out or ctx is done because the
+// client went away.
+func (l *ConcurrencyLimiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		return false
+	}
+	defer func() { <-l.queue }()
+
+	var timeout <-chan time.Time
+	if l.config.QueueTimeout > 0 {
+		timer := time.NewTimer(l.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *ConcurrencyLimiter) release() {
+	<-l.slots
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newBlockingRouter returns a router whose handler holds each request until
+// release is closed
+func newBlockingRouter(l *ConcurrencyLimiter, release <-chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(l.Limit())
+	r.GET("/work", func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+// serveAsync serves a request in the background and delivers its response
+func serveAsync(r *gin.Engine) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+		done <- w
+	}()
+	return done
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConcurrencyLimiterRejectsWhenQueueFull(t *testing.T) {
+	l := NewConcurrencyLimiter(ConcurrencyConfig{MaxInFlight: 2, MaxQueue: 1, RetryAfter: 1500 * time.Millisecond})
+	release := make(chan struct{})
+	r := newBlockingRouter(l, release)
+
+	var pending []<-chan *httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		pending = append(pending, serveAsync(r))
+	}
+	waitFor(t, "the semaphore to fill", func() bool { return l.InFlight() == 2 })
+	pending = append(pending, serveAsync(r))
+	waitFor(t, "a request to queue", func() bool { return l.Queued() == 1 })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("excess request status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After %q, want 2", got)
+	}
+
+	close(release)
+	for _, done := range pending {
+		if w := <-done; w.Code != http.StatusOK {
+			t.Errorf("admitted request status %d, want %d", w.Code, http.StatusOK)
+		}
+	}
+	if l.InFlight() != 0 || l.Queued() != 0 {
+		t.Errorf("%d in flight and %d queued after the requests finished", l.InFlight(), l.Queued())
+	}
+}
+
+func TestConcurrencyLimiterQueueTimeout(t *testing.T) {
+	l := NewConcurrencyLimiter(ConcurrencyConfig{MaxInFlight: 1, MaxQueue: 1, QueueTimeout: 20 * time.Millisecond})
+	release := make(chan struct{})
+	defer close(release)
+	r := newBlockingRouter(l, release)
+
+	serveAsync(r)
+	waitFor(t, "the semaphore to fill", func() bool { return l.InFlight() == 1 })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("queued request status %d with Retry-After %q, want 503 after its wait times out", w.Code, w.Header().Get("Retry-After"))
+	}
+}
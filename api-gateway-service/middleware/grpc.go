@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"api-gateway-service/auth"
+)
+
+// grpcMethod is logged as the method of rate-limited gRPC calls, whose path
+// is the full method name
+const grpcMethod = "GRPC"
+
+// UnaryServerInterceptor applies the rate limit to gRPC calls, sharing the
+// per-client limiters of the REST middleware
+func (rl *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := rl.allowGRPC(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor applies the rate limit to the start of streaming
+// gRPC calls
+func (rl *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := rl.allowGRPC(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allowGRPC returns a ResourceExhausted error if the calling client is over
+// its rate limit
+func (rl *RateLimiter) allowGRPC(ctx context.Context, fullMethod string) error {
+	clientID := grpcClientIdentity(ctx)
+	limiter := rl.getLimiter(clientID)
+
+	allowed := limiter.Allow()
+	rl.logDecision(ctx, clientID, limiter, allowed, grpcMethod, fullMethod)
+	if !allowed {
+		rl.recordDenial(clientID)
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return nil
+}
+
+// grpcClientIdentity identifies a gRPC client by the x-forwarded-for metadata
+// or, without it, by its peer IP address, as getClientIdentity does for REST
+func grpcClientIdentity(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if forwardedFor := md.Get("x-forwarded-for"); len(forwardedFor) > 0 && forwardedFor[0] != "" {
+			return forwardedFor[0]
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// UnaryServerInterceptor applies the caller's tier limit to gRPC calls,
+// sharing the per-key and per-user limiters of the REST middleware. It must
+// run after auth.UnaryServerInterceptor; unauthenticated calls pass through.
+func (tl *TierRateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := tl.allowGRPC(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor applies the caller's tier limit to the start of
+// streaming gRPC calls
+func (tl *TierRateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := tl.allowGRPC(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allowGRPC returns a ResourceExhausted error if the calling key or user is
+// over its tier limit
+func (tl *TierRateLimiter) allowGRPC(ctx context.Context, fullMethod string) error {
+	apiKey, _ := auth.APIKeyFromContext(ctx)
+	claims, _ := auth.ClaimsFromContext(ctx)
+	clientID, clientKey, limit, ok := tl.caller(apiKey, claims)
+	if !ok {
+		return nil
+	}
+
+	limiter := tl.getLimiter(clientID, limit)
+	allowed, _ := tl.batches.admit(clientID, limiter, 1, time.Now())
+	tl.logDecision(ctx, clientKey, limiter, allowed, grpcMethod, fullMethod)
+	if !allowed {
+		tl.recordDenial(clientKey)
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded for your tier")
+	}
+	return nil
+}
+
+// UnaryServerInterceptor enforces the concurrency limit on gRPC calls,
+// sharing the slots of the REST middleware. Calls turned away get
+// Unavailable, as REST requests get 503.
+func (l *ConcurrencyLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !l.acquire(ctx) {
+			return nil, l.atCapacity()
+		}
+		defer l.release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor enforces the concurrency limit on streaming gRPC
+// calls, holding a slot until the stream ends
+func (l *ConcurrencyLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !l.acquire(ss.Context()) {
+			return l.atCapacity()
+		}
+		defer l.release()
+		return handler(srv, ss)
+	}
+}
+
+// atCapacity returns the error of a gRPC call turned away by the limit
+func (l *ConcurrencyLimiter) atCapacity() error {
+	retryAfter := int(math.Ceil(l.config.RetryAfter.Seconds()))
+	return status.Errorf(codes.Unavailable, "server is at capacity, retry after %d seconds", retryAfter)
+}
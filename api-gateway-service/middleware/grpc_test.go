@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"api-gateway-service/auth"
+)
+
+// tierLimitedCall runs a unary call through authentication then the tier
+// interceptor, as newGRPCServer chains them
+func tierLimitedCall(tl *TierRateLimiter, md metadata.MD) error {
+	authenticate := auth.UnaryServerInterceptor()
+	limit := tl.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/cloudoptimizer.v1.CloudOptimizer/GetCosts"}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	_, err := authenticate(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return limit(ctx, req, info, ok)
+	})
+	return err
+}
+
+func TestTierRateLimiterGRPC(t *testing.T) {
+	keys := auth.NewMemoryAPIKeyStore()
+	for _, k := range []*auth.APIKey{
+		{ID: "k1", Hash: auth.HashAPIKey(testAPIKey), Tier: "standard"},
+		{ID: "k2", Hash: auth.HashAPIKey("other-key"), Tier: "standard"},
+	} {
+		if err := keys.AddAPIKey(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	auth.SetAPIKeyStore(keys)
+	t.Cleanup(func() { auth.SetAPIKeyStore(nil) })
+
+	tl := newTestTierRateLimiter(2)
+	key := metadata.Pairs("x-api-key", testAPIKey)
+	for i := 0; i < 2; i++ {
+		if err := tierLimitedCall(tl, key); err != nil {
+			t.Fatalf("call %d within the tier limit: %v", i+1, err)
+		}
+	}
+	if err := tierLimitedCall(tl, key); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("call over the tier limit got %v, want ResourceExhausted", err)
+	}
+
+	// Each key has its own bucket
+	if err := tierLimitedCall(tl, metadata.Pairs("x-api-key", "other-key")); err != nil {
+		t.Errorf("call with another key: %v", err)
+	}
+	if denials := tl.Denials(); denials["key:k1"] != 1 {
+		t.Errorf("denials %v, want one for key:k1", denials)
+	}
+}
+
+func TestConcurrencyLimiterGRPC(t *testing.T) {
+	l := NewConcurrencyLimiter(ConcurrencyConfig{MaxInFlight: 1, RetryAfter: 2 * time.Second})
+	interceptor := l.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/cloudoptimizer.v1.CloudOptimizer/GetCosts"}
+
+	release := make(chan struct{})
+	held := make(chan error)
+	go func() {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			<-release
+			return nil, nil
+		})
+		held <- err
+	}()
+	for l.InFlight() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Error("call ran beyond the limit")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unavailable || !strings.Contains(err.Error(), "retry after 2 seconds") {
+		t.Errorf("call beyond the limit got %v, want Unavailable with a retry hint", err)
+	}
+
+	close(release)
+	if err := <-held; err != nil {
+		t.Errorf("call within the limit: %v", err)
+	}
+	if l.InFlight() != 0 {
+		t.Errorf("%d calls still in flight, want the slot released", l.InFlight())
+	}
+}
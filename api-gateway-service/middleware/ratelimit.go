@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
@@ -10,30 +13,54 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"golang.org/x/time/rate"
+
+	"api-gateway-service/auth"
 )
 
 // RateLimiter manages rate limiting for API requests
 type RateLimiter struct {
 	mu       sync.RWMutex
-	limiters map[string]*rate.Limiter
+	limiters map[string]*clientLimiter
 	config   RateLimitConfig
+	*decisionLog
+
+	batches *batchCredits
+}
+
+// clientLimiter is a client's token bucket and when the client last used it
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// expireIdle removes the limiters not used since before cutoff
+func expireIdle(limiters map[string]*clientLimiter, cutoff time.Time) {
+	for clientID, l := range limiters {
+		if l.lastSeen.Before(cutoff) {
+			delete(limiters, clientID)
+		}
+	}
 }
 
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
 	RequestsPerSecond float64       `json:"requests_per_second"`
-	BurstSize        int           `json:"burst_size"`
-	ExpiryTime       time.Duration `json:"expiry_time"`
-	CleanupInterval  time.Duration `json:"cleanup_interval"`
+	BurstSize         int           `json:"burst_size"`
+	ExpiryTime        time.Duration `json:"expiry_time"`
+	CleanupInterval   time.Duration `json:"cleanup_interval"`
+	// WarningThreshold is the fraction of the burst below which remaining
+	// tokens are reported as near the limit; 0 disables warnings
+	WarningThreshold float64 `json:"warning_threshold"`
 }
 
 // NewRateLimiter creates a new rate limiter instance
 func NewRateLimiter() *RateLimiter {
 	config := RateLimitConfig{
 		RequestsPerSecond: viper.GetFloat64("rate_limit.requests_per_second"),
-		BurstSize:        viper.GetInt("rate_limit.burst_size"),
-		ExpiryTime:       viper.GetDuration("rate_limit.expiry_time"),
-		CleanupInterval:  viper.GetDuration("rate_limit.cleanup_interval"),
+		BurstSize:         viper.GetInt("rate_limit.burst_size"),
+		ExpiryTime:        viper.GetDuration("rate_limit.expiry_time"),
+		CleanupInterval:   viper.GetDuration("rate_limit.cleanup_interval"),
+		WarningThreshold:  viper.GetFloat64("rate_limit.warning_threshold"),
 	}
 
 	if config.RequestsPerSecond == 0 {
@@ -50,8 +77,10 @@ func NewRateLimiter() *RateLimiter {
 	}
 
 	rl := &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
-		config:   config,
+		limiters:    make(map[string]*clientLimiter),
+		config:      config,
+		decisionLog: newDecisionLog(slog.Default()),
+		batches:     newBatchCredits(),
 	}
 
 	// Start cleanup goroutine
@@ -60,25 +89,49 @@ func NewRateLimiter() *RateLimiter {
 	return rl
 }
 
-// RateLimit creates a Gin middleware for rate limiting
+// RateLimit creates a Gin middleware for rate limiting. Requests presenting
+// a bearer token or API key are limited per key or user by the
+// TierRateLimiter once authenticated, so one address can carry several
+// clients' higher tier limits; see limitCredentialed. A request hinting a
+// batch size in X-RateLimit-Batch reserves tokens for the whole batch at
+// once.
 func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get client identifier (e.g., IP address, API key, or user ID)
-		clientID := getClientID(c)
+		clientID, isUser := getClientIdentity(c)
+		clientKey := logSafeClientID(clientID, isUser)
 
 		// Get or create limiter for this client
 		limiter := rl.getLimiter(clientID)
 
+		if auth.HasCredentials(c.Request) {
+			rl.limitCredentialed(c, clientKey, limiter)
+			return
+		}
+
+		size, err := batchSize(c, limiter.Burst())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		// Check if request is allowed
-		ctx := context.Background()
-		if !limiter.Allow() {
+		allowed, wait := rl.batches.admit(clientID, limiter, size, time.Now())
+		rl.logDecision(c.Request.Context(), clientKey, limiter, allowed, c.Request.Method, c.Request.URL.Path)
+		if !allowed {
+			rl.recordDenial(clientKey)
+			if wait > 0 {
+				rejectBatch(c, size, wait)
+				return
+			}
 			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error": "rate limit exceeded",
 				"retry_after": fmt.Sprintf("%.0f seconds",
-					time.Until(time.Now().Add(time.Second/time.Duration(rl.config.RequestsPerSecond))).Seconds()),
+					tokenInterval(rate.Limit(rl.config.RequestsPerSecond)).Seconds()),
 			})
 			return
 		}
+		warnNearLimit(c, rl.logger, clientKey, limiter, rl.config.WarningThreshold)
 
 		// Continue processing the request
 		c.Next()
@@ -88,18 +141,103 @@ func (rl *RateLimiter) RateLimit() gin.HandlerFunc {
 	}
 }
 
+// limitCredentialed limits a request presenting credentials by its address
+// only until they are verified. It is rejected while the address has no
+// tokens left, and takes one once the rest of the chain has run unless
+// auth.AuthMiddleware authenticated it. Failed and forged credentials so
+// stay limited per address, and a burst of them leaves the address in debt,
+// while authenticated requests count only against their tier limit.
+func (rl *RateLimiter) limitCredentialed(c *gin.Context, clientKey string, limiter *rate.Limiter) {
+	allowed := limiter.Tokens() >= 1
+	rl.logDecision(c.Request.Context(), clientKey, limiter, allowed, c.Request.Method, c.Request.URL.Path)
+	if !allowed {
+		rl.recordDenial(clientKey)
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": "rate limit exceeded",
+			"retry_after": fmt.Sprintf("%.0f seconds",
+				tokenInterval(rate.Limit(rl.config.RequestsPerSecond)).Seconds()),
+		})
+		return
+	}
+
+	c.Next()
+
+	if !auth.Authenticated(c) {
+		limiter.Reserve()
+	}
+}
+
+// decisionLog logs the rate-limit decisions of a limiter and counts its
+// denials per client
+type decisionLog struct {
+	logger *slog.Logger
+
+	// denials counts rejected requests by log-safe client key
+	denialsMu sync.Mutex
+	denials   map[string]uint64
+}
+
+func newDecisionLog(logger *slog.Logger) *decisionLog {
+	return &decisionLog{logger: logger, denials: make(map[string]uint64)}
+}
+
+// SetLogger sets the logger used for rate-limit decisions
+func (d *decisionLog) SetLogger(logger *slog.Logger) {
+	d.logger = logger
+}
+
+// Denials returns the number of rejected requests per client. Keys are the
+// same log-safe client identifiers used in rate-limit logs.
+func (d *decisionLog) Denials() map[string]uint64 {
+	d.denialsMu.Lock()
+	defer d.denialsMu.Unlock()
+
+	denials := make(map[string]uint64, len(d.denials))
+	for client, count := range d.denials {
+		denials[client] = count
+	}
+	return denials
+}
+
+func (d *decisionLog) recordDenial(clientKey string) {
+	d.denialsMu.Lock()
+	defer d.denialsMu.Unlock()
+
+	d.denials[clientKey]++
+}
+
+// logDecision logs an allowed or denied request at debug level, with the
+// rate and burst of the client's limiter
+func (d *decisionLog) logDecision(ctx context.Context, clientKey string, limiter *rate.Limiter, allowed bool, method, path string) {
+	decision := "allowed"
+	if !allowed {
+		decision = "denied"
+	}
+
+	d.logger.LogAttrs(ctx, slog.LevelDebug, "rate limit decision",
+		slog.String("decision", decision),
+		slog.String("client", clientKey),
+		slog.Float64("tokens", limiter.Tokens()),
+		slog.Float64("rate", float64(limiter.Limit())),
+		slog.Int("burst", limiter.Burst()),
+		slog.String("method", method),
+		slog.String("path", path),
+	)
+}
+
 // getLimiter returns an existing limiter for the client or creates a new one
 func (rl *RateLimiter) getLimiter(clientID string) *rate.Limiter {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	limiter, exists := rl.limiters[clientID]
+	l, exists := rl.limiters[clientID]
 	if !exists {
-		limiter = rate.NewLimiter(rate.Limit(rl.config.RequestsPerSecond), rl.config.BurstSize)
-		rl.limiters[clientID] = limiter
+		l = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(rl.config.RequestsPerSecond), rl.config.BurstSize)}
+		rl.limiters[clientID] = l
 	}
+	l.lastSeen = time.Now()
 
-	return limiter
+	return l.limiter
 }
 
 // cleanup periodically removes expired limiters
@@ -107,25 +245,28 @@ func (rl *RateLimiter) cleanup() {
 	ticker := time.NewTicker(rl.config.CleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		rl.mu.Lock()
-		for clientID, limiter := range rl.limiters {
-			// Remove limiter if it hasn't been used recently
-			if time.Since(getLastUseTime(limiter)) > rl.config.ExpiryTime {
-				delete(rl.limiters, clientID)
-			}
-		}
-		rl.mu.Unlock()
+	for now := range ticker.C {
+		rl.expire(now)
 	}
 }
 
-// getClientID returns a unique identifier for the client
-func getClientID(c *gin.Context) string {
+// expire removes the limiters of clients idle for longer than the expiry
+// time, and lapsed batch reservations
+func (rl *RateLimiter) expire(now time.Time) {
+	rl.mu.Lock()
+	expireIdle(rl.limiters, now.Add(-rl.config.ExpiryTime))
+	rl.mu.Unlock()
+	rl.batches.expire(now)
+}
+
+// getClientIdentity returns a unique identifier for the client and whether it
+// is a user ID
+func getClientIdentity(c *gin.Context) (string, bool) {
 	// Try to get user ID from JWT claims
 	if claims, exists := c.Get("claims"); exists {
 		if userClaims, ok := claims.(map[string]interface{}); ok {
 			if userID, ok := userClaims["user_id"].(string); ok {
-				return userID
+				return userID, true
 			}
 		}
 	}
@@ -137,27 +278,59 @@ func getClientID(c *gin.Context) string {
 		clientIP = forwardedFor
 	}
 
-	return clientIP
+	return clientIP, false
+}
+
+// logSafeClientID returns the client identifier to use in logs and metrics.
+// User IDs are replaced by a truncated SHA-256 hash.
+func logSafeClientID(clientID string, isUser bool) string {
+	if !isUser {
+		return clientID
+	}
+
+	sum := sha256.Sum256([]byte(clientID))
+	return "user:" + hex.EncodeToString(sum[:8])
 }
 
-// getLastUseTime returns the last time a limiter was used
-func getLastUseTime(l *rate.Limiter) time.Time {
-	// This is a bit of a hack since rate.Limiter doesn't expose last use time
-	// In a production environment, you might want to track this separately
-	return time.Now()
+// tokenInterval is how long the limit takes to add a token. It is computed
+// in floating point, as rates below one request per second are valid.
+func tokenInterval(limit rate.Limit) time.Duration {
+	return time.Duration(float64(time.Second) / float64(limit))
 }
 
 // setRateLimitHeaders sets rate limit headers in the response
 func setRateLimitHeaders(c *gin.Context, l *rate.Limiter) {
 	limit := l.Limit()
 	remaining := l.Tokens()
-	reset := time.Until(time.Now().Add(time.Second / time.Duration(limit)))
+	reset := tokenInterval(limit)
 
 	c.Header("X-RateLimit-Limit", fmt.Sprintf("%.0f", limit))
 	c.Header("X-RateLimit-Remaining", fmt.Sprintf("%.0f", remaining))
 	c.Header("X-RateLimit-Reset", fmt.Sprintf("%.0f", reset.Seconds()))
 }
 
+// warnNearLimit sets X-RateLimit-Warning and logs a warning when the tokens
+// left in the client's bucket are within threshold, a fraction of the burst,
+// of running out. The request is not blocked.
+func warnNearLimit(c *gin.Context, logger *slog.Logger, clientKey string, l *rate.Limiter, threshold float64) {
+	if threshold <= 0 {
+		return
+	}
+	remaining := l.Tokens()
+	if remaining >= threshold*float64(l.Burst()) {
+		return
+	}
+
+	c.Header("X-RateLimit-Warning", "true")
+	logger.LogAttrs(c.Request.Context(), slog.LevelWarn, "client near rate limit",
+		slog.String("client", clientKey),
+		slog.Float64("tokens", remaining),
+		slog.Int("burst", l.Burst()),
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.Request.URL.Path),
+	)
+}
+
 // RateLimitByPath creates a rate limiter specific to an API path
 func RateLimitByPath(requestsPerSecond float64, burstSize int) gin.HandlerFunc {
 	limiter := rate.NewLimiter(rate.Limit(requestsPerSecond), burstSize)
@@ -0,0 +1,423 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"api-gateway-service/auth"
+)
+
+const testAPIKey = "test-key"
+
+// newTestRateLimiter returns a RateLimiter without a cleanup goroutine that
+// refills too slowly to matter within a test
+func newTestRateLimiter(burst int) *RateLimiter {
+	return &RateLimiter{
+		limiters:    make(map[string]*clientLimiter),
+		config:      RateLimitConfig{RequestsPerSecond: 0.001, BurstSize: burst},
+		decisionLog: newDecisionLog(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		batches:     newBatchCredits(),
+	}
+}
+
+// newTestTierRateLimiter returns a TierRateLimiter without a cleanup
+// goroutine, limiting the "standard" tier to burst requests
+func newTestTierRateLimiter(burst int) *TierRateLimiter {
+	return &TierRateLimiter{
+		tiers:        map[string]Limit{"standard": {RequestsPerSecond: 0.001, BurstSize: burst}},
+		roles:        map[string]Limit{},
+		defaultLimit: Limit{RequestsPerSecond: 0.001, BurstSize: burst},
+		limiters:     make(map[string]*clientLimiter),
+		batches:      newBatchCredits(),
+		decisionLog:  newDecisionLog(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		expiryTime:   time.Hour,
+	}
+}
+
+// newTestRouter mounts the limiters as main.go does: the per-address limiter
+// on every route, and authentication then the tier limiter on /api
+func newTestRouter(t *testing.T, rl *RateLimiter, tl *TierRateLimiter) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	keys := auth.NewMemoryAPIKeyStore()
+	if err := keys.AddAPIKey(&auth.APIKey{ID: "k1", Hash: auth.HashAPIKey(testAPIKey), Tier: "standard"}); err != nil {
+		t.Fatal(err)
+	}
+	auth.SetAPIKeyStore(keys)
+	t.Cleanup(func() { auth.SetAPIKeyStore(nil) })
+
+	r := gin.New()
+	r.Use(rl.RateLimit())
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api := r.Group("/api")
+	api.Use(auth.AuthMiddleware())
+	api.Use(tl.RateLimit())
+	api.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestRateLimitCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		want    []int
+	}{
+		{
+			name: "anonymous requests are limited per address",
+			path: "/health",
+			want: []int{200, 200, 429, 429},
+		},
+		{
+			name:    "forged bearer tokens are limited per address",
+			path:    "/api/ping",
+			headers: map[string]string{"Authorization": "Bearer forged"},
+			want:    []int{401, 401, 429, 429},
+		},
+		{
+			name:    "malformed authorization headers are limited per address",
+			path:    "/api/ping",
+			headers: map[string]string{"Authorization": "Basic abc"},
+			want:    []int{401, 401, 429, 429},
+		},
+		{
+			name:    "unknown API keys are limited per address",
+			path:    "/api/ping",
+			headers: map[string]string{"X-API-Key": "guess"},
+			want:    []int{401, 401, 429, 429},
+		},
+		{
+			name:    "valid API keys are limited by their tier only",
+			path:    "/api/ping",
+			headers: map[string]string{"X-API-Key": testAPIKey},
+			want:    []int{200, 200, 200, 200, 200, 429},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRouter(t, newTestRateLimiter(2), newTestTierRateLimiter(5))
+
+			for i, want := range tt.want {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				for k, v := range tt.headers {
+					req.Header.Set(k, v)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != want {
+					t.Fatalf("request %d: status %d, want %d", i+1, w.Code, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitBadCredentialsDoNotDrainAuthenticatedClients(t *testing.T) {
+	rl := newTestRateLimiter(2)
+	r := newTestRouter(t, rl, newTestTierRateLimiter(5))
+
+	send := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/ping", nil)
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		if code := send(testAPIKey); code != http.StatusOK {
+			t.Fatalf("authenticated request %d: status %d, want 200", i+1, code)
+		}
+	}
+	// Authenticated requests took no tokens from the address, so two failed
+	// attempts are still admitted before the address is limited
+	for i, want := range []int{401, 401, 429} {
+		if code := send("guess"); code != want {
+			t.Fatalf("failed attempt %d: status %d, want %d", i+1, code, want)
+		}
+	}
+}
+
+func TestExpireIdleLimiters(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		lastSeen map[string]time.Duration
+		want     []string
+	}{
+		{
+			name:     "idle limiters are removed",
+			lastSeen: map[string]time.Duration{"a": 2 * time.Hour, "b": time.Minute},
+			want:     []string{"b"},
+		},
+		{
+			name:     "limiters used at the cutoff are kept",
+			lastSeen: map[string]time.Duration{"a": time.Hour},
+			want:     []string{"a"},
+		},
+		{
+			name:     "every limiter idle",
+			lastSeen: map[string]time.Duration{"a": 3 * time.Hour, "b": 2 * time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := newTestRateLimiter(1)
+			rl.config.ExpiryTime = time.Hour
+			tl := newTestTierRateLimiter(1)
+			for id, idle := range tt.lastSeen {
+				rl.limiters[id] = &clientLimiter{limiter: rate.NewLimiter(1, 1), lastSeen: now.Add(-idle)}
+				tl.limiters[id] = &clientLimiter{limiter: rate.NewLimiter(1, 1), lastSeen: now.Add(-idle)}
+			}
+
+			rl.expire(now)
+			tl.expire(now)
+
+			for name, limiters := range map[string]map[string]*clientLimiter{"RateLimiter": rl.limiters, "TierRateLimiter": tl.limiters} {
+				if len(limiters) != len(tt.want) {
+					t.Errorf("%s kept %d limiters, want %v", name, len(limiters), tt.want)
+				}
+				for _, id := range tt.want {
+					if _, ok := limiters[id]; !ok {
+						t.Errorf("%s removed %s", name, id)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTierRateLimiterReplacesLimiterOnTierChange(t *testing.T) {
+	tl := newTestTierRateLimiter(1)
+	first := tl.getLimiter("key:k1", Limit{RequestsPerSecond: 1, BurstSize: 1})
+	if same := tl.getLimiter("key:k1", Limit{RequestsPerSecond: 1, BurstSize: 1}); same != first {
+		t.Error("limiter replaced although the limit is unchanged")
+	}
+	if moved := tl.getLimiter("key:k1", Limit{RequestsPerSecond: 5, BurstSize: 10}); moved == first || moved.Burst() != 10 {
+		t.Error("limiter kept although the tier's limit changed")
+	}
+}
+
+// decisionLogs returns the fields of each rate limit decision logged to buf
+func decisionLogs(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var logs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line %q: %v", line, err)
+		}
+		if entry["msg"] == "rate limit decision" {
+			logs = append(logs, entry)
+		}
+	}
+	return logs
+}
+
+func TestRateLimitDecisionLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+		// requests are sent, and the last one is expected to be denied
+		requests   int
+		client     string
+		rate       float64
+		burst      float64
+		fromTier   bool
+		wantStatus int
+	}{
+		{
+			name:       "anonymous denial is logged by address",
+			path:       "/health",
+			requests:   3,
+			client:     "192.0.2.1",
+			rate:       0.001,
+			burst:      2,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "failed API key denial is logged by address",
+			path:       "/api/ping",
+			headers:    map[string]string{"X-API-Key": "guess"},
+			requests:   3,
+			client:     "192.0.2.1",
+			rate:       0.001,
+			burst:      2,
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "API key denial is logged by key with its tier limit",
+			path:       "/api/ping",
+			headers:    map[string]string{"X-API-Key": testAPIKey},
+			requests:   4,
+			client:     "key:k1",
+			rate:       0.5,
+			burst:      3,
+			fromTier:   true,
+			wantStatus: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ipLogs, tierLogs bytes.Buffer
+			rl := newTestRateLimiter(2)
+			rl.SetLogger(slog.New(slog.NewJSONHandler(&ipLogs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+			tl := newTestTierRateLimiter(3)
+			tl.tiers["standard"] = Limit{RequestsPerSecond: 0.5, BurstSize: 3}
+			tl.SetLogger(slog.New(slog.NewJSONHandler(&tierLogs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+			r := newTestRouter(t, rl, tl)
+
+			var status int
+			for i := 0; i < tt.requests; i++ {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				req.RemoteAddr = "192.0.2.1:1234"
+				for k, v := range tt.headers {
+					req.Header.Set(k, v)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				status = w.Code
+			}
+			if status != tt.wantStatus {
+				t.Fatalf("last request: status %d, want %d", status, tt.wantStatus)
+			}
+
+			buf, limiter := &ipLogs, rl.decisionLog
+			if tt.fromTier {
+				buf, limiter = &tierLogs, tl.decisionLog
+			}
+			logs := decisionLogs(t, buf)
+			if len(logs) != tt.requests {
+				t.Fatalf("logged %d decisions, want %d", len(logs), tt.requests)
+			}
+			last := logs[len(logs)-1]
+			want := map[string]interface{}{
+				"level":    "DEBUG",
+				"decision": "denied",
+				"client":   tt.client,
+				"rate":     tt.rate,
+				"burst":    tt.burst,
+				"method":   http.MethodGet,
+				"path":     tt.path,
+			}
+			for field, value := range want {
+				if last[field] != value {
+					t.Errorf("%s = %v, want %v", field, last[field], value)
+				}
+			}
+			if _, ok := last["tokens"].(float64); !ok {
+				t.Errorf("tokens = %v, want a number", last["tokens"])
+			}
+			if got := limiter.Denials()[tt.client]; got != 1 {
+				t.Errorf("denials of %s = %d, want 1", tt.client, got)
+			}
+		})
+	}
+}
+
+func TestLogSafeClientID(t *testing.T) {
+	tests := []struct {
+		name     string
+		clientID string
+		isUser   bool
+		want     string
+	}{
+		{name: "addresses are logged as is", clientID: "192.0.2.1", want: "192.0.2.1"},
+		{name: "user IDs are hashed", clientID: "alice", isUser: true, want: "user:2bd806c97f0e00af"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := logSafeClientID(tt.clientID, tt.isUser); got != tt.want {
+				t.Errorf("logSafeClientID(%q) = %q, want %q", tt.clientID, got, tt.want)
+			}
+		})
+	}
+}
+
+// Requests leaving less than the warning threshold of the burst are served
+// with a warning header and logged; those below the band are not, and
+// throttling at the limit is unchanged
+func TestRateLimitWarning(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		path      string
+		headers   map[string]string
+		// want is the status of each request, and warn whether it carries
+		// the warning header
+		want []int
+		warn []bool
+	}{
+		{
+			name:      "per address",
+			threshold: 0.2,
+			path:      "/health",
+			want:      []int{200, 200, 200, 200, 200, 200, 200, 200, 200, 200, 429},
+			warn:      []bool{false, false, false, false, false, false, false, false, true, true, false},
+		},
+		{
+			name:      "per tier",
+			threshold: 0.4,
+			path:      "/api/ping",
+			headers:   map[string]string{"X-API-Key": testAPIKey},
+			want:      []int{200, 200, 200, 200, 200, 429},
+			warn:      []bool{false, false, false, true, true, false},
+		},
+		{
+			name: "disabled",
+			path: "/health",
+			want: []int{200, 200, 200, 200, 200, 200, 200, 200, 200, 200, 429},
+			warn: make([]bool, 11),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+			rl, tl := newTestRateLimiter(10), newTestTierRateLimiter(5)
+			rl.config.WarningThreshold, tl.warningThreshold = tt.threshold, tt.threshold
+			rl.SetLogger(logger)
+			tl.SetLogger(logger)
+			r := newTestRouter(t, rl, tl)
+
+			warnings := 0
+			for i, want := range tt.want {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				for k, v := range tt.headers {
+					req.Header.Set(k, v)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != want {
+					t.Fatalf("request %d: status %d, want %d", i+1, w.Code, want)
+				}
+				if got := w.Header().Get("X-RateLimit-Warning") == "true"; got != tt.warn[i] {
+					t.Errorf("request %d: warning %v, want %v", i+1, got, tt.warn[i])
+				}
+				if tt.warn[i] {
+					warnings++
+				}
+			}
+			if got := strings.Count(logs.String(), "client near rate limit"); got != warnings {
+				t.Errorf("%d warnings logged, want %d:\n%s", got, warnings, logs.String())
+			}
+		})
+	}
+}
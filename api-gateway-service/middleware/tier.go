@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
+
+	"api-gateway-service/auth"
+)
+
+// Limit is a request rate and burst
+type Limit struct {
+	RequestsPerSecond float64
+	BurstSize         int
+}
+
+// TierRateLimiter limits authenticated callers. Requests made with an API key
+// are limited by the key's tier; JWT requests by the most generous limit of
+// the caller's roles. Each key or user has its own bucket.
+type TierRateLimiter struct {
+	tiers        map[string]Limit
+	roles        map[string]Limit
+	defaultLimit Limit
+	// warningThreshold is the fraction of the burst below which remaining
+	// tokens are reported as near the limit
+	warningThreshold float64
+
+	mu       sync.Mutex
+	limiters map[string]*clientLimiter
+	batches  *batchCredits
+	*decisionLog
+
+	// expiryTime is how long a key or user's limiter is kept unused
+	expiryTime time.Duration
+}
+
+// NewTierRateLimiter creates a limiter with the limits configured under
+// rate_limit.tiers and rate_limit.roles. Unknown tiers and roles get the
+// default rate_limit.requests_per_second and rate_limit.burst_size. Callers
+// within rate_limit.warning_threshold of their limit are warned. Limiters
+// idle for rate_limit.expiry_time are removed every
+// rate_limit.cleanup_interval, as RateLimiter does.
+func NewTierRateLimiter() *TierRateLimiter {
+	defaultLimit := Limit{
+		RequestsPerSecond: viper.GetFloat64("rate_limit.requests_per_second"),
+		BurstSize:         viper.GetInt("rate_limit.burst_size"),
+	}
+	if defaultLimit.RequestsPerSecond == 0 {
+		defaultLimit.RequestsPerSecond = 10
+	}
+	if defaultLimit.BurstSize == 0 {
+		defaultLimit.BurstSize = 20
+	}
+
+	expiryTime := viper.GetDuration("rate_limit.expiry_time")
+	if expiryTime == 0 {
+		expiryTime = 1 * time.Hour
+	}
+	cleanupInterval := viper.GetDuration("rate_limit.cleanup_interval")
+	if cleanupInterval == 0 {
+		cleanupInterval = 5 * time.Minute
+	}
+
+	tl := &TierRateLimiter{
+		tiers:            configuredLimits("rate_limit.tiers"),
+		roles:            configuredLimits("rate_limit.roles"),
+		defaultLimit:     defaultLimit,
+		warningThreshold: viper.GetFloat64("rate_limit.warning_threshold"),
+		limiters:         make(map[string]*clientLimiter),
+		batches:          newBatchCredits(),
+		decisionLog:      newDecisionLog(slog.Default()),
+		expiryTime:       expiryTime,
+	}
+	go tl.cleanup(cleanupInterval)
+
+	return tl
+}
+
+// configuredLimits reads the limits configured under key, one per name.
+// Names are collected from every config layer, so a config file can add a
+// tier or override one field without replacing the defaults.
+func configuredLimits(key string) map[string]Limit {
+	limits := make(map[string]Limit)
+	for _, k := range viper.AllKeys() {
+		rest, ok := strings.CutPrefix(k, key+".")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, ".")
+		limits[name] = Limit{
+			RequestsPerSecond: viper.GetFloat64(key + "." + name + ".requests_per_second"),
+			BurstSize:         viper.GetInt(key + "." + name + ".burst_size"),
+		}
+	}
+	return limits
+}
+
+// Limit returns the limit of an API key tier, or the default for an unknown
+// tier
+func (tl *TierRateLimiter) Limit(tier string) Limit {
+	if l, ok := tl.tiers[tier]; ok {
+		return l
+	}
+	return tl.defaultLimit
+}
+
+// roleLimit returns the most generous limit among the roles, or the default
+// when none has a configured limit
+func (tl *TierRateLimiter) roleLimit(roles []string) Limit {
+	limit, found := tl.defaultLimit, false
+	for _, role := range roles {
+		l, ok := tl.roles[role]
+		if ok && (!found || l.RequestsPerSecond > limit.RequestsPerSecond) {
+			limit, found = l, true
+		}
+	}
+	return limit
+}
+
+// RateLimit creates a Gin middleware applying the caller's limit. It must run
+// after auth.AuthMiddleware; unauthenticated requests pass through. Batches
+// hinted in X-RateLimit-Batch are admitted as with RateLimiter. Decisions are
+// logged and denials counted per key or user as RateLimiter does.
+func (tl *TierRateLimiter) RateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			apiKey *auth.APIKey
+			claims *auth.Claims
+		)
+		if key, ok := c.Get("api_key"); ok {
+			apiKey = key.(*auth.APIKey)
+		}
+		if userClaims, ok := c.Get("claims"); ok {
+			claims = userClaims.(*auth.Claims)
+		}
+		clientID, clientKey, limit, ok := tl.caller(apiKey, claims)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		limiter := tl.getLimiter(clientID, limit)
+		size, err := batchSize(c, limiter.Burst())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		allowed, wait := tl.batches.admit(clientID, limiter, size, time.Now())
+		tl.logDecision(c.Request.Context(), clientKey, limiter, allowed, c.Request.Method, c.Request.URL.Path)
+		if !allowed {
+			tl.recordDenial(clientKey)
+			if wait > 0 {
+				rejectBatch(c, size, wait)
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": "rate limit exceeded for your tier",
+			})
+			return
+		}
+		warnNearLimit(c, tl.logger, clientKey, limiter, tl.warningThreshold)
+
+		c.Next()
+		setRateLimitHeaders(c, limiter)
+	}
+}
+
+// caller returns the bucket, the log-safe identity and the limit of a caller
+// authenticated with an API key or else a token; ok is false for a caller
+// with neither
+func (tl *TierRateLimiter) caller(apiKey *auth.APIKey, claims *auth.Claims) (clientID, clientKey string, limit Limit, ok bool) {
+	if apiKey != nil {
+		clientID = "key:" + apiKey.ID
+		return clientID, clientID, tl.Limit(apiKey.Tier), true
+	}
+	if claims != nil {
+		return "user:" + claims.UserID, logSafeClientID(claims.UserID, true), tl.roleLimit(claims.Roles), true
+	}
+	return "", "", Limit{}, false
+}
+
+// getLimiter returns the client's limiter, replacing it when its limit has
+// changed (e.g. the key was moved to another tier)
+func (tl *TierRateLimiter) getLimiter(clientID string, limit Limit) *rate.Limiter {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	l, exists := tl.limiters[clientID]
+	if !exists || l.limiter.Limit() != rate.Limit(limit.RequestsPerSecond) || l.limiter.Burst() != limit.BurstSize {
+		l = &clientLimiter{limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.BurstSize)}
+		tl.limiters[clientID] = l
+	}
+	l.lastSeen = time.Now()
+	return l.limiter
+}
+
+// cleanup periodically removes expired limiters
+func (tl *TierRateLimiter) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		tl.expire(now)
+	}
+}
+
+// expire removes the limiters of keys and users idle for longer than the
+// expiry time, and lapsed batch reservations
+func (tl *TierRateLimiter) expire(now time.Time) {
+	tl.mu.Lock()
+	expireIdle(tl.limiters, now.Add(-tl.expiryTime))
+	tl.mu.Unlock()
+	tl.batches.expire(now)
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/auth"
+)
+
+// useTierConfig configures limits that refill too slowly to matter within a
+// test: bursts of 2 for the free tier, 4 for pro and 3 by default
+func useTierConfig(t *testing.T) {
+	t.Helper()
+	values := map[string]interface{}{
+		"rate_limit.requests_per_second":              0.001,
+		"rate_limit.burst_size":                       3,
+		"rate_limit.tiers.free.requests_per_second":   0.001,
+		"rate_limit.tiers.free.burst_size":            2,
+		"rate_limit.tiers.pro.requests_per_second":    0.001,
+		"rate_limit.tiers.pro.burst_size":             4,
+		"rate_limit.roles.admin.requests_per_second":  50,
+		"rate_limit.roles.admin.burst_size":           100,
+		"rate_limit.roles.viewer.requests_per_second": 2,
+		"rate_limit.roles.viewer.burst_size":          4,
+	}
+	for k, v := range values {
+		viper.Set(k, v)
+	}
+	t.Cleanup(func() {
+		for k := range values {
+			viper.Set(k, nil)
+		}
+	})
+}
+
+func TestTierRateLimiterConfiguredLimits(t *testing.T) {
+	useTierConfig(t)
+	tl := NewTierRateLimiter()
+
+	tests := []struct {
+		tier string
+		want Limit
+	}{
+		{tier: "free", want: Limit{RequestsPerSecond: 0.001, BurstSize: 2}},
+		{tier: "pro", want: Limit{RequestsPerSecond: 0.001, BurstSize: 4}},
+		{tier: "platinum", want: Limit{RequestsPerSecond: 0.001, BurstSize: 3}},
+		{tier: "", want: Limit{RequestsPerSecond: 0.001, BurstSize: 3}},
+	}
+	for _, tt := range tests {
+		if got := tl.Limit(tt.tier); got != tt.want {
+			t.Errorf("Limit(%q) = %+v, want %+v", tt.tier, got, tt.want)
+		}
+	}
+
+	roles := []struct {
+		roles []string
+		want  Limit
+	}{
+		{roles: []string{"viewer"}, want: Limit{RequestsPerSecond: 2, BurstSize: 4}},
+		{roles: []string{"viewer", "admin"}, want: Limit{RequestsPerSecond: 50, BurstSize: 100}},
+		{roles: []string{"auditor"}, want: Limit{RequestsPerSecond: 0.001, BurstSize: 3}},
+	}
+	for _, tt := range roles {
+		if got := tl.roleLimit(tt.roles); got != tt.want {
+			t.Errorf("roleLimit(%v) = %+v, want %+v", tt.roles, got, tt.want)
+		}
+	}
+}
+
+// Each API key is limited by its tier's configured limit, and a key of an
+// unknown tier by the default
+func TestTierRateLimitByKeyTier(t *testing.T) {
+	useTierConfig(t)
+	gin.SetMode(gin.TestMode)
+
+	keys := auth.NewMemoryAPIKeyStore()
+	for _, k := range []*auth.APIKey{
+		{ID: "k-free", Hash: auth.HashAPIKey("free-key"), Tier: "free"},
+		{ID: "k-pro", Hash: auth.HashAPIKey("pro-key"), Tier: "pro"},
+		{ID: "k-trial", Hash: auth.HashAPIKey("trial-key"), Tier: "trial"},
+	} {
+		if err := keys.AddAPIKey(k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	auth.SetAPIKeyStore(keys)
+	t.Cleanup(func() { auth.SetAPIKeyStore(nil) })
+
+	r := gin.New()
+	r.Use(auth.AuthMiddleware(), NewTierRateLimiter().RateLimit())
+	r.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		key         string
+		wantAllowed int
+	}{
+		{key: "free-key", wantAllowed: 2},
+		{key: "pro-key", wantAllowed: 4},
+		{key: "trial-key", wantAllowed: 3},
+	}
+	for _, tt := range tests {
+		allowed := 0
+		for i := 0; i < 6; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			switch w.Code {
+			case http.StatusOK:
+				allowed++
+			case http.StatusTooManyRequests:
+			default:
+				t.Fatalf("%s: status %d", tt.key, w.Code)
+			}
+		}
+		if allowed != tt.wantAllowed {
+			t.Errorf("%s: %d requests allowed, want %d", tt.key, allowed, tt.wantAllowed)
+		}
+	}
+}
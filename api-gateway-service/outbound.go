@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// outboundTransport is shared by webhook and schedule notification requests.
+// Its dialer checks the address every connection is made to, so a host
+// that resolves to a public address when registered and to a private one
+// later is still refused. Proxies are not used, as the dialer would only
+// see the proxy's address.
+var outboundTransport = &http.Transport{
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   controlOutboundDial,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: time.Second,
+}
+
+// outboundClient returns a client for requests to user-supplied URLs that
+// gives up after timeout
+func outboundClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: outboundTransport, Timeout: timeout}
+}
+
+// allowPrivateTargets reports whether webhooks.allow_private_targets lets
+// webhooks and notifications reach private, loopback and link-local
+// addresses
+func allowPrivateTargets() bool {
+	return viper.GetBool("webhooks.allow_private_targets")
+}
+
+// controlOutboundDial refuses connections to addresses outboundAddressAllowed
+// rejects. address is the resolved IP and port being dialled.
+func controlOutboundDial(network, address string, _ syscall.RawConn) error {
+	if allowPrivateTargets() {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !outboundAddressAllowed(ip) {
+		return fmt.Errorf("connection to %s refused: not a public address", host)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// net.IP.IsPrivate does not cover but which is not publicly routable
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// outboundAddressAllowed reports whether ip is a public unicast address.
+// Loopback, private, shared (carrier-grade NAT), link-local, multicast and
+// unspecified addresses are not; IPv4-mapped IPv6 addresses are checked as
+// IPv4.
+func outboundAddressAllowed(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || sharedAddressSpace.Contains(ip) ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// checkOutboundHost resolves host and returns an error unless every address
+// it resolves to is allowed
+func checkOutboundHost(ctx context.Context, host string) error {
+	if allowPrivateTargets() {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve %s: %v", host, err)
+	}
+	for _, addr := range addrs {
+		if !outboundAddressAllowed(addr.IP) {
+			return fmt.Errorf("%s resolves to %s, which is not a public address", host, addr.IP)
+		}
+	}
+	return nil
+}
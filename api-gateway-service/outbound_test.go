@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestOutboundAddressAllowed(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "93.184.216.34", want: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{ip: "127.0.0.1"},
+		{ip: "::1"},
+		{ip: "10.1.2.3"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "100.64.0.1"},
+		{ip: "100.127.255.254"},
+		{ip: "100.128.0.1", want: true},
+		{ip: "100.63.255.255", want: true},
+		{ip: "::ffff:100.64.0.1"},
+		{ip: "169.254.169.254"},
+		{ip: "fe80::1"},
+		{ip: "fd00::1"},
+		{ip: "0.0.0.0"},
+		{ip: "::"},
+		{ip: "224.0.0.1"},
+		{ip: "::ffff:127.0.0.1"},
+		{ip: "::ffff:10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := outboundAddressAllowed(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("outboundAddressAllowed(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{name: "public address", url: "https://93.184.216.34/hook"},
+		{name: "not http", url: "ftp://93.184.216.34/hook", wantErr: true},
+		{name: "relative", url: "/hook", wantErr: true},
+		{name: "loopback", url: "http://127.0.0.1:8080/hook", wantErr: true},
+		{name: "localhost", url: "http://localhost/hook", wantErr: true},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "private IPv6", url: "http://[fd00::1]/hook", wantErr: true},
+		{name: "carrier-grade NAT", url: "http://100.100.100.200/hook", wantErr: true},
+		{name: "private allowed by config", url: "http://10.0.0.5/hook", allowPrivate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("webhooks.allow_private_targets", tt.allowPrivate)
+			t.Cleanup(func() { viper.Set("webhooks.allow_private_targets", false) })
+
+			err := validateWebhookURL(context.Background(), tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateWebhookURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// The dialer is checked on every connection, so a host that passed
+// validation and later resolves to a private address is still refused
+func TestOutboundClientRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{name: "refused by default", wantErr: true},
+		{name: "allowed by config", allowPrivate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("webhooks.allow_private_targets", tt.allowPrivate)
+			t.Cleanup(func() { viper.Set("webhooks.allow_private_targets", false) })
+
+			resp, err := outboundClient(5*time.Second).Post(srv.URL, "application/json", nil)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("POST %s error = %v, wantErr %v", srv.URL, err, tt.wantErr)
+			}
+		})
+	}
+}
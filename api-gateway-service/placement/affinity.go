@@ -0,0 +1,162 @@
+package placement
+
+import (
+	"fmt"
+	"math"
+)
+
+// Egress prices per GB of traffic to a related resource
+const (
+	crossRegionEgressPerGB   = 0.02
+	crossProviderEgressPerGB = 0.09
+)
+
+// Latency estimates between resources, in milliseconds
+const (
+	sameRegionLatencyMs   = 1
+	sameLocationLatencyMs = 5
+	// unknownLatencyMs is assumed for locations missing from locationLatency
+	unknownLatencyMs = 150
+	// maxAffinityLatencyMs is the latency at which the affinity score reaches 0
+	maxAffinityLatencyMs = 150
+)
+
+// locationLatency is the round-trip latency between locations in ms, listed
+// once per pair
+var locationLatency = map[[2]string]float64{
+	{"us-east", "us-central"}: 30,
+	{"us-east", "us-west"}:    65,
+	{"us-central", "us-west"}: 40,
+	{"us-east", "eu-west"}:    80,
+	{"us-central", "eu-west"}: 100,
+	{"us-west", "eu-west"}:    140,
+}
+
+// Affinity asks for a resource to be placed close to a related one, such as an
+// application server to its database. The related resource is an existing
+// placement or, within a placement group, another member by name.
+type Affinity struct {
+	PlacementID string `json:"placement_id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	// MonthlyTrafficGB is the traffic exchanged with the related resource,
+	// priced as egress when the two are in different regions
+	MonthlyTrafficGB float64 `json:"monthly_traffic_gb,omitempty"`
+}
+
+// Validate checks that the affinity names exactly one related resource
+func (a Affinity) Validate() error {
+	if (a.PlacementID == "") == (a.Name == "") {
+		return fmt.Errorf("affinity requires exactly one of placement_id or name")
+	}
+	if a.MonthlyTrafficGB < 0 {
+		return fmt.Errorf("affinity monthly_traffic_gb must not be negative")
+	}
+	return nil
+}
+
+// Peer is the location of a related resource an Affinity refers to
+type Peer struct {
+	// Ref is the placement ID or group member name of the resource
+	Ref              string
+	Provider         string
+	Region           string
+	MonthlyTrafficGB float64
+}
+
+// AffinityLink reports the estimated latency and egress cost between an
+// option and one related resource
+type AffinityLink struct {
+	Ref               string  `json:"ref"`
+	Provider          string  `json:"provider"`
+	Region            string  `json:"region"`
+	LatencyMs         float64 `json:"latency_ms"`
+	EgressMonthlyCost float64 `json:"egress_monthly_cost"`
+}
+
+// applyAffinity prices the egress to each peer into the options' costs and
+// scores how close each option is to its peers. Options are left unscored
+// for affinity when there are no peers.
+func (e *Engine) applyAffinity(options []Option, peers []Peer) {
+	if len(peers) == 0 {
+		return
+	}
+
+	for i := range options {
+		o := &options[i]
+		o.Affinity = make([]AffinityLink, len(peers))
+		o.CostBreakdown = copyBreakdown(o.CostBreakdown)
+
+		score := 0.0
+		for j, peer := range peers {
+			latency := e.latency(o.Provider, o.Region, peer.Provider, peer.Region)
+			egress := peer.MonthlyTrafficGB * egressPerGB(o.Provider, o.Region, peer.Provider, peer.Region) * e.priceFactor(o.Provider)
+			o.Affinity[j] = AffinityLink{
+				Ref:               peer.Ref,
+				Provider:          peer.Provider,
+				Region:            peer.Region,
+				LatencyMs:         latency,
+				EgressMonthlyCost: egress,
+			}
+			o.CostBreakdown[CostEgress] += egress
+			score += 1 - math.Min(latency, maxAffinityLatencyMs)/maxAffinityLatencyMs
+		}
+		o.MonthlyCost = SumCosts(o.CostBreakdown)
+		o.AffinityScore = score / float64(len(peers))
+	}
+}
+
+// latency estimates the latency between two provider regions
+func (e *Engine) latency(provider, region, peerProvider, peerRegion string) float64 {
+	if provider == peerProvider && region == peerRegion {
+		return sameRegionLatencyMs
+	}
+
+	from, to := e.location(provider, region), e.location(peerProvider, peerRegion)
+	switch {
+	case from == "" || to == "":
+		return unknownLatencyMs
+	case from == to:
+		return sameLocationLatencyMs
+	}
+	if ms, ok := locationLatency[[2]string{from, to}]; ok {
+		return ms
+	}
+	if ms, ok := locationLatency[[2]string{to, from}]; ok {
+		return ms
+	}
+	return unknownLatencyMs
+}
+
+// location returns the catalog location of a provider region, or "" if it is
+// not in the catalog
+func (e *Engine) location(provider, region string) string {
+	p, err := e.catalog.Provider(provider)
+	if err != nil {
+		return ""
+	}
+	r, err := p.Region(region)
+	if err != nil {
+		return ""
+	}
+	return r.Location
+}
+
+// egressPerGB returns the price of traffic between two provider regions:
+// free within a region, cheaper within a provider than across providers
+func egressPerGB(provider, region, peerProvider, peerRegion string) float64 {
+	switch {
+	case provider != peerProvider:
+		return crossProviderEgressPerGB
+	case region != peerRegion:
+		return crossRegionEgressPerGB
+	}
+	return 0
+}
+
+func copyBreakdown(breakdown map[string]float64) map[string]float64 {
+	copied := make(map[string]float64, len(breakdown))
+	for k, v := range breakdown {
+		copied[k] = v
+	}
+	return copied
+}
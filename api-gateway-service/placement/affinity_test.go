@@ -0,0 +1,99 @@
+package placement
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAffinityValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		affinity Affinity
+		wantErr  bool
+	}{
+		{name: "placement", affinity: Affinity{PlacementID: "plc-1", MonthlyTrafficGB: 100}},
+		{name: "group member", affinity: Affinity{Name: "db"}},
+		{name: "neither", affinity: Affinity{}, wantErr: true},
+		{name: "both", affinity: Affinity{PlacementID: "plc-1", Name: "db"}, wantErr: true},
+		{name: "negative traffic", affinity: Affinity{Name: "db", MonthlyTrafficGB: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.affinity.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLatency(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	tests := []struct {
+		provider, region, peerProvider, peerRegion string
+		want                                       float64
+	}{
+		{"aws", "us-east-1", "aws", "us-east-1", sameRegionLatencyMs},
+		{"aws", "us-east-1", "gcp", "us-east1", sameLocationLatencyMs},
+		{"aws", "us-east-1", "gcp", "us-central1", 30},
+		{"gcp", "us-central1", "aws", "us-east-1", 30},
+		{"azure", "westus2", "gcp", "europe-west1", 140},
+		{"aws", "us-east-1", "aws", "ap-south-1", unknownLatencyMs},
+	}
+	for _, tt := range tests {
+		if got := e.latency(tt.provider, tt.region, tt.peerProvider, tt.peerRegion); got != tt.want {
+			t.Errorf("latency(%s/%s, %s/%s) = %v, want %v", tt.provider, tt.region, tt.peerProvider, tt.peerRegion, got, tt.want)
+		}
+	}
+}
+
+// An affinity to a resource in another region moves the selection to that
+// region and reports the link to it
+func TestPlaceComputeNearBiasesRegion(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	req := &ComputeRequirements{Name: "app", VCPUs: 2, MemoryGB: 4}
+
+	alone, err := e.PlaceCompute(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.location(alone.Selected.Provider, alone.Selected.Region) == "eu-west" {
+		t.Fatalf("selected %s/%s without an affinity; pick a peer elsewhere", alone.Selected.Provider, alone.Selected.Region)
+	}
+	if alone.Selected.AffinityScore != 0 || alone.Selected.Affinity != nil {
+		t.Errorf("option without an affinity scored %v with links %v", alone.Selected.AffinityScore, alone.Selected.Affinity)
+	}
+
+	peers := []Peer{{Ref: "plc-db", Provider: "aws", Region: "eu-west-1", MonthlyTrafficGB: 500}}
+	near, err := e.PlaceComputeNear(req, peers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := near.Selected
+	if sel.Provider != "aws" || sel.Region != "eu-west-1" {
+		t.Errorf("selected %s/%s, want the peer's region aws/eu-west-1", sel.Provider, sel.Region)
+	}
+	if len(sel.Affinity) != 1 || sel.Affinity[0].Ref != "plc-db" || sel.Affinity[0].LatencyMs != sameRegionLatencyMs || sel.Affinity[0].EgressMonthlyCost != 0 {
+		t.Errorf("affinity links %+v, want a free same-region link to plc-db", sel.Affinity)
+	}
+
+	// Other options carry the egress to the peer in their cost
+	for _, o := range near.Alternatives {
+		if o.Region == "eu-west-1" && o.Provider == "aws" {
+			continue
+		}
+		want := 500 * egressPerGB(o.Provider, o.Region, "aws", "eu-west-1") * e.priceFactor(o.Provider)
+		if math.Abs(o.CostBreakdown[CostEgress]-want) > 1e-9 || math.Abs(o.MonthlyCost-SumCosts(o.CostBreakdown)) > 1e-9 {
+			t.Errorf("%s/%s egress %v of %v, want %v", o.Provider, o.Region, o.CostBreakdown[CostEgress], o.MonthlyCost, want)
+		}
+	}
+
+	// The request itself is placed as before once the affinity is removed
+	again, err := e.PlaceComputeNear(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Selected.Provider != alone.Selected.Provider || again.Selected.Region != alone.Selected.Region {
+		t.Errorf("without peers selected %s/%s, want %s/%s", again.Selected.Provider, again.Selected.Region, alone.Selected.Provider, alone.Selected.Region)
+	}
+}
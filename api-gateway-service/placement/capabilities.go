@@ -0,0 +1,69 @@
+package placement
+
+import "sort"
+
+// Region features
+const (
+	FeatureGPU                   = "gpu"
+	FeatureARM                   = "arm"
+	FeatureConfidentialComputing = "confidential_computing"
+	FeatureLocalSSD              = "local_ssd"
+)
+
+// RegionCapabilities is what a provider offers in one region
+type RegionCapabilities struct {
+	Region               string   `json:"region"`
+	Jurisdiction         string   `json:"jurisdiction"`
+	Zones                []string `json:"zones"`
+	Features             []string `json:"features"`
+	ComplianceFrameworks []string `json:"compliance_frameworks"`
+	SpotAvailable        bool     `json:"spot_available"`
+}
+
+// Capabilities is what a provider offers: the instance families available in
+// every region and the features and compliance frameworks of each region
+type Capabilities struct {
+	Provider         string               `json:"provider"`
+	InstanceFamilies []string             `json:"instance_families"`
+	Regions          []RegionCapabilities `json:"regions"`
+}
+
+// Capabilities returns the provider's capabilities in every region, or in the
+// named region only when region is set
+func (p *ProviderCatalog) Capabilities(region string) (*Capabilities, error) {
+	regions := p.Regions
+	if region != "" {
+		r, err := p.Region(region)
+		if err != nil {
+			return nil, err
+		}
+		regions = []Region{*r}
+	}
+
+	caps := &Capabilities{
+		Provider:         p.Name,
+		InstanceFamilies: make([]string, 0),
+		Regions:          make([]RegionCapabilities, 0, len(regions)),
+	}
+
+	seen := make(map[string]bool)
+	for _, it := range p.InstanceTypes {
+		if !seen[it.Family] {
+			seen[it.Family] = true
+			caps.InstanceFamilies = append(caps.InstanceFamilies, it.Family)
+		}
+	}
+	sort.Strings(caps.InstanceFamilies)
+
+	for _, r := range regions {
+		caps.Regions = append(caps.Regions, RegionCapabilities{
+			Region:               r.Name,
+			Jurisdiction:         r.Jurisdiction,
+			Zones:                append(make([]string, 0, len(r.Zones)), r.Zones...),
+			Features:             append(make([]string, 0, len(r.Features)), r.Features...),
+			ComplianceFrameworks: append(make([]string, 0, len(r.ComplianceFrameworks)), r.ComplianceFrameworks...),
+			SpotAvailable:        r.SpotAvailable,
+		})
+	}
+	return caps, nil
+}
@@ -0,0 +1,50 @@
+package placement
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	p, err := DefaultCatalog().Provider("azure")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caps, err := p.Capabilities("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps.Provider != "azure" || len(caps.Regions) != len(p.Regions) {
+		t.Fatalf("capabilities of %s in %d regions, want azure in %d", caps.Provider, len(caps.Regions), len(p.Regions))
+	}
+	// Families are listed once each, sorted
+	if want := []string{"B", "Dsv5", "Esv5", "Fsv2"}; !reflect.DeepEqual(caps.InstanceFamilies, want) {
+		t.Errorf("instance families %v, want %v", caps.InstanceFamilies, want)
+	}
+
+	one, err := p.Capabilities("westeurope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(one.Regions) != 1 {
+		t.Fatalf("%d regions, want westeurope only", len(one.Regions))
+	}
+	r := one.Regions[0]
+	if r.Region != "westeurope" || r.SpotAvailable || !contains(r.Features, FeatureConfidentialComputing) || !contains(r.ComplianceFrameworks, "GDPR") {
+		t.Errorf("westeurope capabilities %+v", r)
+	}
+	if !reflect.DeepEqual(one.InstanceFamilies, caps.InstanceFamilies) {
+		t.Errorf("region instance families %v, want the provider's %v", one.InstanceFamilies, caps.InstanceFamilies)
+	}
+
+	// The capabilities do not share the catalog's slices
+	r.Features[0] = "changed"
+	if again, _ := p.Capabilities("westeurope"); again.Regions[0].Features[0] == "changed" {
+		t.Error("changing the capabilities changed the catalog")
+	}
+
+	if _, err := p.Capabilities("us-east-1"); err == nil {
+		t.Error("another provider's region accepted")
+	}
+}
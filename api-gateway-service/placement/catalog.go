@@ -0,0 +1,154 @@
+// Package placement scores and selects cloud placements for resource requirements.
+package placement
+
+import "fmt"
+
+// HoursPerMonth is used to convert hourly prices to monthly estimates
+const HoursPerMonth = 730
+
+// InstanceType describes a provider compute offering
+type InstanceType struct {
+	Name             string  `json:"name"`
+	Family           string  `json:"family"`
+	VCPUs            int     `json:"vcpus"`
+	MemoryGB         float64 `json:"memory_gb"`
+	HourlyPrice      float64 `json:"hourly_price"`
+	PerformanceScore float64 `json:"performance_score"`
+}
+
+// Region describes a provider region
+type Region struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	// Location is the metro area hosting the region, e.g. us-east; regions
+	// of different providers in one location are a few milliseconds apart
+	Location string `json:"location"`
+	// Jurisdiction is the ISO 3166-1 alpha-2 code of the country hosting the
+	// region, whose laws its data is subject to
+	Jurisdiction string `json:"jurisdiction"`
+	// Zones lists the region's availability zones. Zone names are unique
+	// across the catalog, e.g. us-east-1a.
+	Zones                []string `json:"zones,omitempty"`
+	Availability         float64  `json:"availability"`
+	PriceMultiplier      float64  `json:"price_multiplier"`
+	ComplianceFrameworks []string `json:"compliance_frameworks"`
+	// Features lists the optional capabilities offered in the region, such
+	// as FeatureGPU
+	Features []string `json:"features"`
+	// SpotAvailable reports whether spot (preemptible) capacity is sold in
+	// the region
+	SpotAvailable bool `json:"spot_available"`
+}
+
+// ProviderCatalog holds the regions and instance types offered by a provider
+type ProviderCatalog struct {
+	Name              string         `json:"name"`
+	Regions           []Region       `json:"regions"`
+	InstanceTypes     []InstanceType `json:"instance_types"`
+	StoragePricePerGB float64        `json:"storage_price_per_gb"`
+	// DataTransfer prices expected data transfer; inbound traffic is free
+	// at every built-in provider
+	DataTransfer TransferPricing `json:"data_transfer"`
+}
+
+// Catalog holds the offerings of every supported provider
+type Catalog struct {
+	Providers map[string]*ProviderCatalog `json:"providers"`
+}
+
+// Provider returns the catalog for the named provider
+func (c *Catalog) Provider(name string) (*ProviderCatalog, error) {
+	p, exists := c.Providers[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown provider: %s", name)
+	}
+	return p, nil
+}
+
+// Region returns the named region of the provider
+func (p *ProviderCatalog) Region(name string) (*Region, error) {
+	for i := range p.Regions {
+		if p.Regions[i].Name == name {
+			return &p.Regions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown region %s for provider %s", name, p.Name)
+}
+
+// InstanceType returns the named instance type of the provider
+func (p *ProviderCatalog) InstanceType(name string) (*InstanceType, error) {
+	for i := range p.InstanceTypes {
+		if p.InstanceTypes[i].Name == name {
+			return &p.InstanceTypes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown instance type %s for provider %s", name, p.Name)
+}
+
+// DefaultCatalog returns the built-in catalog of provider offerings
+func DefaultCatalog() *Catalog {
+	return &Catalog{
+		Providers: map[string]*ProviderCatalog{
+			"aws": {
+				Name:              "aws",
+				StoragePricePerGB: 0.08,
+				DataTransfer: TransferPricing{
+					Outbound: TransferRates{IntraRegionPerGB: 0.01, InterRegionPerGB: 0.02, InternetPerGB: 0.09},
+				},
+				Regions: []Region{
+					{Name: "us-east-1", Provider: "aws", Location: "us-east", Jurisdiction: "US", Zones: []string{"us-east-1a", "us-east-1b", "us-east-1c"}, Availability: 99.99, PriceMultiplier: 1.0, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS", "FedRAMP"}, Features: []string{FeatureGPU, FeatureARM, FeatureConfidentialComputing, FeatureLocalSSD}, SpotAvailable: true},
+					{Name: "us-west-2", Provider: "aws", Location: "us-west", Jurisdiction: "US", Zones: []string{"us-west-2a", "us-west-2b", "us-west-2c"}, Availability: 99.99, PriceMultiplier: 1.0, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS", "FedRAMP"}, Features: []string{FeatureGPU, FeatureARM, FeatureLocalSSD}, SpotAvailable: true},
+					{Name: "eu-west-1", Provider: "aws", Location: "eu-west", Jurisdiction: "IE", Zones: []string{"eu-west-1a", "eu-west-1b", "eu-west-1c"}, Availability: 99.99, PriceMultiplier: 1.1, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS", "GDPR"}, Features: []string{FeatureGPU, FeatureARM, FeatureLocalSSD}, SpotAvailable: true},
+				},
+				InstanceTypes: []InstanceType{
+					{Name: "t3.medium", Family: "t3", VCPUs: 2, MemoryGB: 4, HourlyPrice: 0.0416, PerformanceScore: 0.55},
+					{Name: "m5.large", Family: "m5", VCPUs: 2, MemoryGB: 8, HourlyPrice: 0.096, PerformanceScore: 0.7},
+					{Name: "r5.large", Family: "r5", VCPUs: 2, MemoryGB: 16, HourlyPrice: 0.126, PerformanceScore: 0.72},
+					{Name: "c5.xlarge", Family: "c5", VCPUs: 4, MemoryGB: 8, HourlyPrice: 0.17, PerformanceScore: 0.85},
+					{Name: "m5.xlarge", Family: "m5", VCPUs: 4, MemoryGB: 16, HourlyPrice: 0.192, PerformanceScore: 0.8},
+					{Name: "m5.2xlarge", Family: "m5", VCPUs: 8, MemoryGB: 32, HourlyPrice: 0.384, PerformanceScore: 0.9},
+				},
+			},
+			"azure": {
+				Name:              "azure",
+				StoragePricePerGB: 0.075,
+				DataTransfer: TransferPricing{
+					Outbound: TransferRates{IntraRegionPerGB: 0, InterRegionPerGB: 0.02, InternetPerGB: 0.087},
+				},
+				Regions: []Region{
+					{Name: "eastus", Provider: "azure", Location: "us-east", Jurisdiction: "US", Zones: []string{"eastus-1", "eastus-2", "eastus-3"}, Availability: 99.99, PriceMultiplier: 1.0, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS", "FedRAMP"}, Features: []string{FeatureGPU, FeatureARM, FeatureConfidentialComputing, FeatureLocalSSD}, SpotAvailable: true},
+					{Name: "westus2", Provider: "azure", Location: "us-west", Jurisdiction: "US", Zones: []string{"westus2-1", "westus2-2", "westus2-3"}, Availability: 99.95, PriceMultiplier: 1.0, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS"}, Features: []string{FeatureGPU, FeatureLocalSSD}, SpotAvailable: true},
+					{Name: "westeurope", Provider: "azure", Location: "eu-west", Jurisdiction: "NL", Zones: []string{"westeurope-1", "westeurope-2", "westeurope-3"}, Availability: 99.99, PriceMultiplier: 1.12, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS", "GDPR"}, Features: []string{FeatureGPU, FeatureARM, FeatureConfidentialComputing, FeatureLocalSSD}, SpotAvailable: false},
+				},
+				InstanceTypes: []InstanceType{
+					{Name: "Standard_B2s", Family: "B", VCPUs: 2, MemoryGB: 4, HourlyPrice: 0.0416, PerformanceScore: 0.5},
+					{Name: "Standard_D2s_v5", Family: "Dsv5", VCPUs: 2, MemoryGB: 8, HourlyPrice: 0.096, PerformanceScore: 0.72},
+					{Name: "Standard_E2s_v5", Family: "Esv5", VCPUs: 2, MemoryGB: 16, HourlyPrice: 0.126, PerformanceScore: 0.73},
+					{Name: "Standard_F4s_v2", Family: "Fsv2", VCPUs: 4, MemoryGB: 8, HourlyPrice: 0.169, PerformanceScore: 0.84},
+					{Name: "Standard_D4s_v5", Family: "Dsv5", VCPUs: 4, MemoryGB: 16, HourlyPrice: 0.192, PerformanceScore: 0.81},
+					{Name: "Standard_D8s_v5", Family: "Dsv5", VCPUs: 8, MemoryGB: 32, HourlyPrice: 0.384, PerformanceScore: 0.9},
+				},
+			},
+			"gcp": {
+				Name:              "gcp",
+				StoragePricePerGB: 0.04,
+				DataTransfer: TransferPricing{
+					Outbound: TransferRates{IntraRegionPerGB: 0.01, InterRegionPerGB: 0.02, InternetPerGB: 0.12},
+				},
+				Regions: []Region{
+					{Name: "us-central1", Provider: "gcp", Location: "us-central", Jurisdiction: "US", Zones: []string{"us-central1-a", "us-central1-b", "us-central1-c", "us-central1-f"}, Availability: 99.99, PriceMultiplier: 1.0, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS", "FedRAMP"}, Features: []string{FeatureGPU, FeatureARM, FeatureConfidentialComputing, FeatureLocalSSD}, SpotAvailable: true},
+					{Name: "us-east1", Provider: "gcp", Location: "us-east", Jurisdiction: "US", Zones: []string{"us-east1-b", "us-east1-c", "us-east1-d"}, Availability: 99.95, PriceMultiplier: 1.0, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS"}, Features: []string{FeatureGPU, FeatureLocalSSD}, SpotAvailable: true},
+					{Name: "europe-west1", Provider: "gcp", Location: "eu-west", Jurisdiction: "BE", Zones: []string{"europe-west1-b", "europe-west1-c", "europe-west1-d"}, Availability: 99.99, PriceMultiplier: 1.09, ComplianceFrameworks: []string{"SOC2", "ISO27001", "HIPAA", "PCI-DSS", "GDPR"}, Features: []string{FeatureGPU, FeatureARM, FeatureLocalSSD}, SpotAvailable: true},
+				},
+				InstanceTypes: []InstanceType{
+					{Name: "e2-medium", Family: "e2", VCPUs: 2, MemoryGB: 4, HourlyPrice: 0.0335, PerformanceScore: 0.5},
+					{Name: "n2-standard-2", Family: "n2", VCPUs: 2, MemoryGB: 8, HourlyPrice: 0.0971, PerformanceScore: 0.71},
+					{Name: "n2-highmem-2", Family: "n2", VCPUs: 2, MemoryGB: 16, HourlyPrice: 0.131, PerformanceScore: 0.72},
+					{Name: "c2-standard-4", Family: "c2", VCPUs: 4, MemoryGB: 16, HourlyPrice: 0.2088, PerformanceScore: 0.88},
+					{Name: "n2-standard-4", Family: "n2", VCPUs: 4, MemoryGB: 16, HourlyPrice: 0.1942, PerformanceScore: 0.8},
+					{Name: "n2-standard-8", Family: "n2", VCPUs: 8, MemoryGB: 32, HourlyPrice: 0.3885, PerformanceScore: 0.9},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,382 @@
+package placement
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrNoCandidates is returned when no offering satisfies the requirements
+var ErrNoCandidates = errors.New("no placement satisfies the requirements")
+
+// ComputeRequirements describes a compute placement request
+type ComputeRequirements struct {
+	Name            string   `json:"name"`
+	VCPUs           int      `json:"vcpus"`
+	MemoryGB        float64  `json:"memory_gb"`
+	Regions         []string `json:"regions,omitempty"`
+	MinAvailability float64  `json:"min_availability,omitempty"`
+	// SLATier sets availability and redundancy by name; when MinAvailability
+	// or MultiRegion is also set, the stricter requirement applies
+	SLATier           SLATier  `json:"sla_tier,omitempty"`
+	MaxMonthlyBudget  *float64 `json:"max_monthly_budget,omitempty"`
+	ExcludedProviders []string `json:"excluded_providers,omitempty"`
+	ExcludedRegions   []string `json:"excluded_regions,omitempty"`
+	// ExcludedInstanceTypes lists instance types, by exact name, that are
+	// never selected
+	ExcludedInstanceTypes []string     `json:"excluded_instance_types,omitempty"`
+	ComplianceFrameworks  []string     `json:"compliance_frameworks,omitempty"`
+	MultiRegion           *MultiRegion `json:"multi_region,omitempty"`
+	// Tags are cost-allocation tags applied to the provisioned resource
+	Tags map[string]string `json:"tags,omitempty"`
+	// Metadata is opaque data of the caller's, such as ticket IDs or owners,
+	// stored with the placement and echoed back; it does not affect placement
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Affinity lists related resources the placement should be close to
+	Affinity []Affinity `json:"affinity,omitempty"`
+	// DataTransfer is the expected monthly data transfer, priced into each
+	// option's cost
+	DataTransfer *DataTransfer `json:"data_transfer,omitempty"`
+	// AvailabilityZones pins the placement to zones of the selected region
+	AvailabilityZones *ZoneRequirements `json:"availability_zones,omitempty"`
+	// DataResidency lists the jurisdictions the placement's data may reside
+	// in, as country codes (e.g. DE) or groups of them (e.g. EU)
+	DataResidency []string `json:"data_residency,omitempty"`
+	// ResourceGroup is the logical group or project the placement belongs to;
+	// it does not affect placement
+	ResourceGroup string `json:"resource_group,omitempty"`
+	// CurrentMonthlyCost is what the workload costs today, which the
+	// placement's savings are estimated against; it does not affect placement
+	CurrentMonthlyCost *float64 `json:"current_monthly_cost,omitempty"`
+}
+
+// Decision is the outcome of a placement request. For multi-region requests
+// Selected is the primary (highest-weight) region and Allocations lists every
+// region with the combined cost in AggregateMonthlyCost.
+type Decision struct {
+	Selected             Option             `json:"selected"`
+	Alternatives         []Option           `json:"alternatives"`
+	Allocations          []RegionAllocation `json:"allocations,omitempty"`
+	AggregateMonthlyCost float64            `json:"aggregate_monthly_cost,omitempty"`
+	// AchievedSLATier is the strictest SLA tier the placement meets
+	AchievedSLATier SLATier `json:"achieved_sla_tier,omitempty"`
+	// SelectionReason explains why Selected was chosen over the alternatives
+	SelectionReason string `json:"selection_reason"`
+	// PriceListDate is when the prices the costs are estimated from were
+	// last updated, if known; PricesStale is set when they are older than
+	// the engine's staleness threshold
+	PriceListDate *time.Time `json:"price_list_date,omitempty"`
+	PricesStale   bool       `json:"prices_stale,omitempty"`
+	// Savings compares the placement's cost with CurrentMonthlyCost, when
+	// the requirements set it
+	Savings *Savings `json:"savings,omitempty"`
+	// CustomPricing is set when the costs were estimated with the tenant's
+	// negotiated pricing for a provider of the placement instead of list
+	// prices
+	CustomPricing bool `json:"custom_pricing,omitempty"`
+}
+
+// Validate checks that the requirements are well formed
+func (r *ComputeRequirements) Validate() error {
+	if r.SLATier != "" {
+		if _, err := r.SLATier.Requirements(); err != nil {
+			return err
+		}
+	}
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.VCPUs < 1 {
+		return fmt.Errorf("vcpus must be at least 1")
+	}
+	if r.MemoryGB <= 0 {
+		return fmt.Errorf("memory_gb must be greater than 0")
+	}
+	if err := ValidateTags(r.Tags); err != nil {
+		return err
+	}
+	if err := ValidateMetadata(r.Metadata); err != nil {
+		return err
+	}
+	for _, a := range r.Affinity {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.DataTransfer != nil {
+		if err := r.DataTransfer.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.AvailabilityZones != nil {
+		if err := r.AvailabilityZones.Validate(); err != nil {
+			return err
+		}
+		if r.MultiRegion != nil && len(r.AvailabilityZones.Required) > 0 {
+			return fmt.Errorf("availability_zones.required cannot be combined with multi_region")
+		}
+	}
+	if err := ValidateDataResidency(r.DataResidency); err != nil {
+		return err
+	}
+	if err := ValidateCurrentMonthlyCost(r.CurrentMonthlyCost); err != nil {
+		return err
+	}
+	if r.MultiRegion != nil {
+		return r.MultiRegion.Validate(r.Regions)
+	}
+	return nil
+}
+
+// PlaceCompute selects the best-scoring placement for the compute requirements.
+// An SLA tier is resolved against the explicit requirements before placing;
+// req itself is left unchanged.
+func (e *Engine) PlaceCompute(req *ComputeRequirements) (*Decision, error) {
+	return e.PlaceComputeNear(req, nil)
+}
+
+// PlaceComputeNear is PlaceCompute for requirements with an affinity, given
+// the locations of the related resources. Egress to each peer is added to the
+// candidates' costs and closeness to the peers is scored, so placements near
+// them are favored.
+func (e *Engine) PlaceComputeNear(req *ComputeRequirements, peers []Peer) (*Decision, error) {
+	req, err := req.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkZones(req); err != nil {
+		return nil, err
+	}
+	if err := e.checkResidency(req); err != nil {
+		return nil, err
+	}
+
+	candidates := e.ComputeCandidates(req.VCPUs, req.MemoryGB, req.ComplianceFrameworks, req.ExcludedInstanceTypes)
+	e.applyAffinity(candidates, peers)
+	e.applyDataTransfer(candidates, req.DataTransfer)
+	candidates = e.applyZones(e.filter(candidates, req), req.AvailabilityZones)
+	if len(candidates) == 0 {
+		return nil, ErrNoCandidates
+	}
+	e.rank(candidates)
+
+	excluded := e.excludedTypeCandidates(req)
+	e.applyAffinity(excluded, peers)
+	e.applyDataTransfer(excluded, req.DataTransfer)
+	excluded = e.applyZones(e.filter(excluded, req), req.AvailabilityZones)
+	if e.normalize {
+		normalizeScores(excluded, candidates)
+	}
+	score(excluded, minMonthlyCost(candidates))
+
+	d := &Decision{Selected: candidates[0]}
+	if req.MultiRegion != nil {
+		allocations, err := allocateRegions(candidates, req.MultiRegion)
+		if err != nil {
+			return nil, err
+		}
+		d.Allocations = allocations
+		d.AggregateMonthlyCost = AggregateCost(allocations)
+
+		primary := allocations[0]
+		for _, o := range candidates {
+			if o.Provider == primary.Provider && o.Region == primary.Region && o.InstanceType == primary.InstanceType {
+				d.Selected = o
+				break
+			}
+		}
+	}
+
+	d.AchievedSLATier = e.achievedTier(d)
+
+	placed := map[string]bool{d.Selected.Region: true}
+	for _, a := range d.Allocations {
+		placed[a.Region] = true
+	}
+	for _, o := range candidates {
+		if len(d.Alternatives) == maxAlternatives {
+			break
+		}
+		if placed[o.Region] {
+			continue
+		}
+		d.Alternatives = append(d.Alternatives, o)
+	}
+	d.SelectionReason = explainSelection(d.Selected, d.Alternatives, e.tieBreak)
+	d.Alternatives = append(d.Alternatives, bestPerInstanceType(excluded, e.tieBreak)...)
+	if !e.priceListDate.IsZero() {
+		date := e.priceListDate
+		d.PriceListDate = &date
+		d.PricesStale = PricesStale(date, e.staleAfter, time.Now())
+	}
+	if req.CurrentMonthlyCost != nil {
+		d.Savings = EstimateSavings(*req.CurrentMonthlyCost, d.monthlyCost())
+	}
+	d.CustomPricing = e.customPricing(d.Selected.Provider)
+	for _, a := range d.Allocations {
+		d.CustomPricing = d.CustomPricing || e.customPricing(a.Provider)
+	}
+	return d, nil
+}
+
+// excludedTypeCandidates returns an unscored option in every provider region
+// for each excluded instance type satisfying the vCPU and memory
+// requirements, rejected for being excluded
+func (e *Engine) excludedTypeCandidates(req *ComputeRequirements) []Option {
+	excluded := make(map[string]bool, len(req.ExcludedInstanceTypes))
+	for _, t := range req.ExcludedInstanceTypes {
+		excluded[t] = true
+	}
+
+	options := e.instanceOptions(req, func(it *InstanceType) bool { return excluded[it.Name] })
+	for i := range options {
+		options[i].RejectionReason = fmt.Sprintf("%s: %s", ReasonExcludedInstanceType, options[i].InstanceType)
+	}
+	return options
+}
+
+// instanceOptions returns an unscored option in every provider region for
+// each instance type satisfying the vCPU and memory requirements that include
+// accepts
+func (e *Engine) instanceOptions(req *ComputeRequirements, include func(*InstanceType) bool) []Option {
+	var options []Option
+	for _, p := range e.catalog.Providers {
+		for i := range p.InstanceTypes {
+			it := &p.InstanceTypes[i]
+			if it.VCPUs < req.VCPUs || it.MemoryGB < req.MemoryGB || !include(it) {
+				continue
+			}
+			for _, r := range p.Regions {
+				breakdown := e.costBreakdown(p, it, &r)
+				options = append(options, Option{
+					Provider:         p.Name,
+					Region:           r.Name,
+					InstanceType:     it.Name,
+					MonthlyCost:      SumCosts(breakdown),
+					CostBreakdown:    breakdown,
+					PerformanceScore: it.PerformanceScore,
+					ComplianceScore:  complianceScore(&r, req.ComplianceFrameworks),
+				})
+			}
+		}
+	}
+	return options
+}
+
+// bestPerInstanceType returns the highest-scoring of the scored options for
+// each instance type, in score order
+func bestPerInstanceType(options []Option, tieBreak []string) []Option {
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
+		}
+		return lessTied(a, b, tieBreak)
+	})
+
+	seen := make(map[string]bool)
+	var best []Option
+	for _, o := range options {
+		if seen[o.InstanceType] {
+			continue
+		}
+		seen[o.InstanceType] = true
+		best = append(best, o)
+	}
+	return best
+}
+
+// resolve validates the requirements and returns a copy with the SLA tier
+// applied
+func (r *ComputeRequirements) resolve() (*ComputeRequirements, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	resolved := *r
+	if r.MultiRegion != nil {
+		m := *r.MultiRegion
+		resolved.MultiRegion = &m
+	}
+	if err := resolved.applySLATier(); err != nil {
+		return nil, err
+	}
+	if resolved.MultiRegion != nil {
+		if err := resolved.MultiRegion.Validate(resolved.Regions); err != nil {
+			return nil, err
+		}
+	}
+	return &resolved, nil
+}
+
+// filter drops candidates outside the allowed regions or jurisdictions, in
+// excluded providers or regions, or below the availability floor or over
+// budget. Under a data residency constraint the remaining candidates are
+// given their region's jurisdiction.
+func (e *Engine) filter(options []Option, req *ComputeRequirements) []Option {
+	allowed := make(map[string]bool, len(req.Regions))
+	for _, r := range req.Regions {
+		allowed[r] = true
+	}
+	excluded := make(map[string]bool, len(req.ExcludedProviders))
+	for _, p := range req.ExcludedProviders {
+		excluded[p] = true
+	}
+	excludedRegions := make(map[string]bool, len(req.ExcludedRegions))
+	for _, r := range req.ExcludedRegions {
+		excludedRegions[r] = true
+	}
+
+	var filtered []Option
+	for _, o := range options {
+		if len(allowed) > 0 && !allowed[o.Region] {
+			continue
+		}
+		if excluded[o.Provider] || excludedRegions[o.Region] {
+			continue
+		}
+		if req.MaxMonthlyBudget != nil && o.MonthlyCost > *req.MaxMonthlyBudget {
+			continue
+		}
+		if req.MinAvailability > 0 && e.availability(o) < req.MinAvailability {
+			continue
+		}
+		if len(req.DataResidency) > 0 {
+			o.Jurisdiction = e.jurisdiction(o)
+			if !inJurisdiction(o.Jurisdiction, req.DataResidency) {
+				continue
+			}
+		}
+		filtered = append(filtered, o)
+	}
+	return filtered
+}
+
+// availability returns the catalog availability of the option's region
+func (e *Engine) availability(o Option) float64 {
+	p, err := e.catalog.Provider(o.Provider)
+	if err != nil {
+		return 0
+	}
+	r, err := p.Region(o.Region)
+	if err != nil {
+		return 0
+	}
+	return r.Availability
+}
+
+// achievedTier returns the SLA tier met by the decision, rating a multi-region
+// placement by its least available region
+func (e *Engine) achievedTier(d *Decision) SLATier {
+	if len(d.Allocations) == 0 {
+		return AchievedTier(e.availability(d.Selected), 1)
+	}
+
+	lowest := 100.0
+	for _, a := range d.Allocations {
+		if v := e.availability(Option{Provider: a.Provider, Region: a.Region}); v < lowest {
+			lowest = v
+		}
+	}
+	return AchievedTier(lowest, len(d.Allocations))
+}
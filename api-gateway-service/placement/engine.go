@@ -0,0 +1,316 @@
+package placement
+
+import (
+	"sort"
+	"time"
+)
+
+// Weights applied to the component scores when computing the total score
+const (
+	costWeight        = 0.5
+	performanceWeight = 0.3
+	complianceWeight  = 0.2
+	// affinityWeight applies only to requests with an affinity; options are
+	// otherwise unscored for it
+	affinityWeight = 0.3
+)
+
+// maxAlternatives caps the number of alternatives returned with a decision
+const maxAlternatives = 3
+
+// defaultBootDiskGB is the boot volume size assumed for compute placements
+const defaultBootDiskGB = 30
+
+// Cost breakdown categories
+const (
+	CostCompute = "compute"
+	CostStorage = "storage"
+	CostEgress  = "egress"
+	CostLicense = "license"
+)
+
+// Option is a scored placement candidate
+type Option struct {
+	Provider         string             `json:"provider"`
+	Region           string             `json:"region"`
+	InstanceType     string             `json:"instance_type,omitempty"`
+	MonthlyCost      float64            `json:"monthly_cost"`
+	CostBreakdown    map[string]float64 `json:"cost_breakdown,omitempty"`
+	PerformanceScore float64            `json:"performance_score"`
+	ComplianceScore  float64            `json:"compliance_score"`
+	TotalScore       float64            `json:"total_score"`
+	// AffinityScore rates closeness to the related resources of an affinity
+	// request, from 1 (same region) down to 0
+	AffinityScore float64        `json:"affinity_score,omitempty"`
+	Affinity      []AffinityLink `json:"affinity,omitempty"`
+	// DataTransferCost itemizes the data transfer cost included in
+	// MonthlyCost when the requirements set an expected data transfer
+	DataTransferCost *TransferCost `json:"data_transfer_cost,omitempty"`
+	// NormalizedScores are set when the engine normalizes scores; the total
+	// score is then computed from them rather than the raw scores
+	NormalizedScores *NormalizedScores `json:"normalized_scores,omitempty"`
+	// Zones are the availability zones selected in Region when the
+	// requirements set availability zones
+	Zones []string `json:"zones,omitempty"`
+	// Jurisdiction is the jurisdiction of Region when the requirements set a
+	// data residency constraint
+	Jurisdiction string `json:"jurisdiction,omitempty"`
+	// RejectionReason explains why an alternative ranked below the selected
+	// option; it is empty for the selected option itself
+	RejectionReason string `json:"rejection_reason,omitempty"`
+}
+
+// Evaluation is the scored state of an existing deployment together with
+// better-scoring alternatives of equivalent size
+type Evaluation struct {
+	Current      Option   `json:"current"`
+	Alternatives []Option `json:"alternatives"`
+}
+
+// Engine scores placement candidates from a provider catalog
+type Engine struct {
+	catalog *Catalog
+	// tieBreak orders options with equal total scores
+	tieBreak []string
+	// priceListDate is when the catalog's prices were last updated; decisions
+	// are flagged stale once it is older than staleAfter
+	priceListDate time.Time
+	staleAfter    time.Duration
+	// normalize min-max normalizes the component scores across each
+	// candidate set before ranking it
+	normalize bool
+	// pricing overrides the list prices of a tenant's providers
+	pricing Pricing
+}
+
+// EngineOption configures optional Engine behavior
+type EngineOption func(*Engine)
+
+// WithTieBreak orders options with equal total scores by the given keys, as
+// returned by ParseTieBreak
+func WithTieBreak(order []string) EngineOption {
+	return func(e *Engine) {
+		e.tieBreak = order
+	}
+}
+
+// WithPriceList records when the catalog's prices were last updated, flagging
+// decisions as stale when that is more than staleAfter ago. A zero
+// staleAfter never flags them.
+func WithPriceList(updated time.Time, staleAfter time.Duration) EngineOption {
+	return func(e *Engine) {
+		e.priceListDate = updated
+		e.staleAfter = staleAfter
+	}
+}
+
+// NewEngine creates a new placement engine backed by the given catalog
+func NewEngine(catalog *Catalog, opts ...EngineOption) *Engine {
+	e := &Engine{catalog: catalog}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.tieBreak == nil {
+		e.tieBreak, _ = ParseTieBreak(DefaultTieBreak)
+	}
+	return e
+}
+
+// Catalog returns the catalog backing the engine
+func (e *Engine) Catalog() *Catalog {
+	return e.catalog
+}
+
+// ComputeCandidates returns, for every provider region, the cheapest instance
+// type satisfying the vCPU and memory requirements, skipping the excluded
+// instance types. Candidates are unscored.
+func (e *Engine) ComputeCandidates(vcpus int, memoryGB float64, frameworks, excludedTypes []string) []Option {
+	excluded := make(map[string]bool, len(excludedTypes))
+	for _, t := range excludedTypes {
+		excluded[t] = true
+	}
+
+	var options []Option
+	for _, p := range e.catalog.Providers {
+		var cheapest *InstanceType
+		for i := range p.InstanceTypes {
+			it := &p.InstanceTypes[i]
+			if it.VCPUs < vcpus || it.MemoryGB < memoryGB || excluded[it.Name] {
+				continue
+			}
+			if cheapest == nil || e.hourlyPrice(p.Name, it) < e.hourlyPrice(p.Name, cheapest) {
+				cheapest = it
+			}
+		}
+		if cheapest == nil {
+			continue
+		}
+
+		for _, r := range p.Regions {
+			breakdown := e.costBreakdown(p, cheapest, &r)
+			options = append(options, Option{
+				Provider:         p.Name,
+				Region:           r.Name,
+				InstanceType:     cheapest.Name,
+				MonthlyCost:      SumCosts(breakdown),
+				CostBreakdown:    breakdown,
+				PerformanceScore: cheapest.PerformanceScore,
+				ComplianceScore:  complianceScore(&r, frameworks),
+			})
+		}
+	}
+	return options
+}
+
+// EvaluateOptions narrows the alternatives an evaluation considers.
+// Alternatives must support every one of ComplianceFrameworks, against which
+// the current deployment is also scored.
+type EvaluateOptions struct {
+	ExcludedProviders    []string
+	ExcludedRegions      []string
+	ComplianceFrameworks []string
+}
+
+// Evaluate scores an existing deployment of the given instance type and
+// compares it to the best-scoring equivalent candidates across providers
+func (e *Engine) Evaluate(provider, region, instanceType string) (*Evaluation, error) {
+	return e.EvaluateWith(provider, region, instanceType, EvaluateOptions{})
+}
+
+// EvaluateWith evaluates an existing deployment like Evaluate, considering
+// only the alternatives the options allow
+func (e *Engine) EvaluateWith(provider, region, instanceType string, opts EvaluateOptions) (*Evaluation, error) {
+	p, err := e.catalog.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.Region(region)
+	if err != nil {
+		return nil, err
+	}
+	it, err := p.InstanceType(instanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := e.costBreakdown(p, it, r)
+	current := Option{
+		Provider:         provider,
+		Region:           region,
+		InstanceType:     instanceType,
+		MonthlyCost:      SumCosts(breakdown),
+		CostBreakdown:    breakdown,
+		PerformanceScore: it.PerformanceScore,
+		ComplianceScore:  complianceScore(r, opts.ComplianceFrameworks),
+	}
+
+	options := []Option{current}
+	candidates := e.ComputeCandidates(it.VCPUs, it.MemoryGB, opts.ComplianceFrameworks, nil)
+	exclusions := &ComputeRequirements{ExcludedProviders: opts.ExcludedProviders, ExcludedRegions: opts.ExcludedRegions}
+	for _, o := range e.filter(candidates, exclusions) {
+		if o.Provider == provider && o.Region == region && o.InstanceType == instanceType {
+			continue
+		}
+		if o.ComplianceScore < 1 {
+			continue
+		}
+		options = append(options, o)
+	}
+	e.rank(options)
+
+	eval := &Evaluation{}
+	for _, o := range options {
+		if o.Provider == provider && o.Region == region && o.InstanceType == instanceType {
+			eval.Current = o
+			continue
+		}
+		if len(eval.Alternatives) < maxAlternatives {
+			eval.Alternatives = append(eval.Alternatives, o)
+		}
+	}
+	return eval, nil
+}
+
+// Rank scores the options relative to one another and sorts them by total
+// score, highest first, ordering equal scores by the tie-break keys
+func Rank(options []Option, tieBreak []string) {
+	if len(options) == 0 {
+		return
+	}
+
+	score(options, minMonthlyCost(options))
+
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
+		}
+		return lessTied(a, b, tieBreak)
+	})
+}
+
+// minMonthlyCost returns the lowest monthly cost among the options
+func minMonthlyCost(options []Option) float64 {
+	minCost := options[0].MonthlyCost
+	for _, o := range options[1:] {
+		if o.MonthlyCost < minCost {
+			minCost = o.MonthlyCost
+		}
+	}
+	return minCost
+}
+
+// score sets the total score of each option, rating cost relative to minCost.
+// Performance and compliance are rated by their normalized scores, if set.
+func score(options []Option, minCost float64) {
+	for i := range options {
+		costScore := 1.0
+		if options[i].MonthlyCost > 0 {
+			costScore = minCost / options[i].MonthlyCost
+		}
+		options[i].TotalScore = costWeight*costScore +
+			performanceWeight*options[i].performance() +
+			complianceWeight*options[i].compliance() +
+			affinityWeight*options[i].AffinityScore
+	}
+}
+
+// computeCostBreakdown itemizes the monthly cost of running the instance type
+// with a default boot volume in the region
+func computeCostBreakdown(p *ProviderCatalog, it *InstanceType, r *Region) map[string]float64 {
+	return map[string]float64{
+		CostCompute: it.HourlyPrice * r.PriceMultiplier * HoursPerMonth,
+		CostStorage: p.StoragePricePerGB * defaultBootDiskGB * r.PriceMultiplier,
+		CostEgress:  0,
+		CostLicense: 0,
+	}
+}
+
+// SumCosts returns the total of a cost breakdown
+func SumCosts(breakdown map[string]float64) float64 {
+	total := 0.0
+	for _, cost := range breakdown {
+		total += cost
+	}
+	return total
+}
+
+// complianceScore is the fraction of the required frameworks the region supports
+func complianceScore(r *Region, frameworks []string) float64 {
+	if len(frameworks) == 0 {
+		return 1.0
+	}
+
+	supported := make(map[string]bool, len(r.ComplianceFrameworks))
+	for _, f := range r.ComplianceFrameworks {
+		supported[f] = true
+	}
+
+	matched := 0
+	for _, f := range frameworks {
+		if supported[f] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(frameworks))
+}
@@ -0,0 +1,155 @@
+package placement
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluateWith(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+
+	tests := []struct {
+		name        string
+		opts        EvaluateOptions
+		wantCurrent float64
+		allowed     func(o Option) bool
+	}{
+		{
+			name:        "no options",
+			wantCurrent: 1,
+			allowed:     func(o Option) bool { return true },
+		},
+		{
+			name:        "excluded providers and regions",
+			opts:        EvaluateOptions{ExcludedProviders: []string{"azure"}, ExcludedRegions: []string{"us-central1"}},
+			wantCurrent: 1,
+			allowed:     func(o Option) bool { return o.Provider != "azure" && o.Region != "us-central1" },
+		},
+		{
+			name:        "required frameworks",
+			opts:        EvaluateOptions{ComplianceFrameworks: []string{"GDPR"}},
+			wantCurrent: 0,
+			allowed: func(o Option) bool {
+				return o.ComplianceScore == 1 && (o.Region == "eu-west-1" || o.Region == "westeurope" || o.Region == "europe-west1")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := e.EvaluateWith("aws", "us-east-1", "m5.large", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if eval.Current.InstanceType != "m5.large" || eval.Current.ComplianceScore != tt.wantCurrent {
+				t.Errorf("current %s with compliance score %v, want m5.large with %v", eval.Current.InstanceType, eval.Current.ComplianceScore, tt.wantCurrent)
+			}
+			if len(eval.Alternatives) == 0 {
+				t.Fatal("no alternatives")
+			}
+			for _, o := range eval.Alternatives {
+				if !tt.allowed(o) {
+					t.Errorf("alternative %s %s not allowed by the options", o.Provider, o.Region)
+				}
+			}
+		})
+	}
+}
+
+// Every option's monthly cost is the sum of its cost breakdown
+func TestCostBreakdown(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	eval, err := e.Evaluate("aws", "us-east-1", "m5.large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := append([]Option{eval.Current}, e.ComputeCandidates(2, 8, nil, nil)...)
+
+	for _, o := range options {
+		if o.CostBreakdown[CostCompute] <= 0 || o.CostBreakdown[CostStorage] <= 0 {
+			t.Errorf("%s %s %s breakdown %v, want compute and storage costs", o.Provider, o.Region, o.InstanceType, o.CostBreakdown)
+		}
+		if got := SumCosts(o.CostBreakdown); got != o.MonthlyCost {
+			t.Errorf("%s %s %s costs %v, its breakdown sums to %v", o.Provider, o.Region, o.InstanceType, o.MonthlyCost, got)
+		}
+	}
+}
+
+// Excluded providers and regions are never selected nor offered as
+// alternatives
+func TestPlaceComputeExclusions(t *testing.T) {
+	req := &ComputeRequirements{
+		Name:              "web",
+		VCPUs:             2,
+		MemoryGB:          8,
+		ExcludedProviders: []string{"azure"},
+		ExcludedRegions:   []string{"us-east-1", "us-east1"},
+	}
+	d, err := NewEngine(DefaultCatalog()).PlaceCompute(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range append([]Option{d.Selected}, d.Alternatives...) {
+		if o.Provider == "azure" || o.Region == "us-east-1" || o.Region == "us-east1" {
+			t.Errorf("excluded option %s/%s offered", o.Provider, o.Region)
+		}
+	}
+}
+
+// Excluded instance types are never selected and are listed after the ranked
+// alternatives with the reason they were rejected
+func TestPlaceComputeExcludedInstanceTypes(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	req := &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8}
+
+	// Exclude each selected type in turn, so later requests exclude several
+	for round := 0; round < 3; round++ {
+		d, err := e.PlaceCompute(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		excluded := make(map[string]bool)
+		for _, name := range req.ExcludedInstanceTypes {
+			excluded[name] = true
+		}
+		if excluded[d.Selected.InstanceType] {
+			t.Fatalf("excluded instance type %s selected", d.Selected.InstanceType)
+		}
+
+		listed := make(map[string]bool)
+		for i, o := range d.Alternatives {
+			if !excluded[o.InstanceType] {
+				if len(listed) > 0 {
+					t.Errorf("ranked alternative %d %s listed after an excluded one", i, o.InstanceType)
+				}
+				continue
+			}
+			if want := ReasonExcludedInstanceType + ": " + o.InstanceType; o.RejectionReason != want {
+				t.Errorf("alternative %s rejected with %q, want %q", o.InstanceType, o.RejectionReason, want)
+			}
+			if listed[o.InstanceType] {
+				t.Errorf("excluded instance type %s listed twice", o.InstanceType)
+			}
+			listed[o.InstanceType] = true
+		}
+		if len(listed) != len(excluded) {
+			t.Errorf("excluded types %v listed, want %v", listed, req.ExcludedInstanceTypes)
+		}
+
+		req.ExcludedInstanceTypes = append(req.ExcludedInstanceTypes, d.Selected.InstanceType)
+	}
+}
+
+// An excluded type that would not fit the requirements anyway is not listed
+func TestPlaceComputeExcludedInstanceTypeTooSmall(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	d, err := e.PlaceCompute(&ComputeRequirements{Name: "db", VCPUs: 8, MemoryGB: 32, ExcludedInstanceTypes: []string{"m5.large"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range d.Alternatives {
+		if o.InstanceType == "m5.large" || strings.HasPrefix(o.RejectionReason, ReasonExcludedInstanceType) {
+			t.Errorf("alternative %s rejected with %q, want no excluded types listed", o.InstanceType, o.RejectionReason)
+		}
+	}
+}
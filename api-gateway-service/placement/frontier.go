@@ -0,0 +1,99 @@
+package placement
+
+import "sort"
+
+// Frontier dimensions an option can be best on
+const (
+	DimensionCost        = "cost"
+	DimensionPerformance = "performance"
+)
+
+// FrontierOption is a Pareto-optimal option: no other option is both at most
+// as expensive and at least as performant while better on one of the two
+type FrontierOption struct {
+	Option
+	// BestOn lists the dimensions on which no option beats this one
+	BestOn []string `json:"best_on,omitempty"`
+}
+
+// ComputeFrontier returns the Pareto frontier across monthly cost and
+// performance of every instance type and region satisfying the requirements,
+// cheapest first. Unlike PlaceCompute it considers every fitting instance
+// type rather than the cheapest per provider region.
+func (e *Engine) ComputeFrontier(req *ComputeRequirements, peers []Peer) ([]FrontierOption, error) {
+	req, err := req.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkZones(req); err != nil {
+		return nil, err
+	}
+	if err := e.checkResidency(req); err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool, len(req.ExcludedInstanceTypes))
+	for _, t := range req.ExcludedInstanceTypes {
+		excluded[t] = true
+	}
+
+	options := e.instanceOptions(req, func(it *InstanceType) bool { return !excluded[it.Name] })
+	e.applyAffinity(options, peers)
+	e.applyDataTransfer(options, req.DataTransfer)
+	options = e.applyZones(e.filter(options, req), req.AvailabilityZones)
+	if len(options) == 0 {
+		return nil, ErrNoCandidates
+	}
+	e.rank(options)
+
+	return ParetoFrontier(options, e.tieBreak), nil
+}
+
+// ParetoFrontier returns the options not dominated across monthly cost and
+// performance, ordered by cost, then by performance descending, then by the
+// tie-break keys. An option dominates another when it costs no more and
+// performs no worse, and is strictly better on one of the two; options equal
+// on both are all kept.
+func ParetoFrontier(options []Option, tieBreak []string) []FrontierOption {
+	sorted := make([]Option, len(options))
+	copy(sorted, options)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.MonthlyCost != b.MonthlyCost {
+			return a.MonthlyCost < b.MonthlyCost
+		}
+		if a.PerformanceScore != b.PerformanceScore {
+			return a.PerformanceScore > b.PerformanceScore
+		}
+		return lessTied(a, b, tieBreak)
+	})
+
+	// Walking by cost, an option is dominated unless it outperforms every
+	// cheaper option, or matches the cost and performance of the last kept one
+	var frontier []FrontierOption
+	for _, o := range sorted {
+		if n := len(frontier); n > 0 {
+			last := frontier[n-1].Option
+			tied := o.MonthlyCost == last.MonthlyCost && o.PerformanceScore == last.PerformanceScore
+			if !tied && o.PerformanceScore <= last.PerformanceScore {
+				continue
+			}
+		}
+		frontier = append(frontier, FrontierOption{Option: o})
+	}
+	if len(frontier) == 0 {
+		return frontier
+	}
+
+	minCost := frontier[0].MonthlyCost
+	maxPerf := frontier[len(frontier)-1].PerformanceScore
+	for i := range frontier {
+		if frontier[i].MonthlyCost == minCost {
+			frontier[i].BestOn = append(frontier[i].BestOn, DimensionCost)
+		}
+		if frontier[i].PerformanceScore == maxPerf {
+			frontier[i].BestOn = append(frontier[i].BestOn, DimensionPerformance)
+		}
+	}
+	return frontier
+}
@@ -0,0 +1,86 @@
+package placement
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParetoFrontier(t *testing.T) {
+	options := []Option{
+		{Provider: "aws", Region: "a", MonthlyCost: 100, PerformanceScore: 0.7},
+		// Dominated on cost: as performant as a, but dearer
+		{Provider: "aws", Region: "b", MonthlyCost: 120, PerformanceScore: 0.7},
+		// Dominated on performance: as cheap as a, but slower
+		{Provider: "gcp", Region: "c", MonthlyCost: 100, PerformanceScore: 0.6},
+		// Dominated on both by d
+		{Provider: "gcp", Region: "e", MonthlyCost: 210, PerformanceScore: 0.75},
+		{Provider: "azure", Region: "d", MonthlyCost: 200, PerformanceScore: 0.8},
+		// Tied with d on both, so kept alongside it
+		{Provider: "aws", Region: "d2", MonthlyCost: 200, PerformanceScore: 0.8},
+		{Provider: "gcp", Region: "f", MonthlyCost: 400, PerformanceScore: 0.9},
+	}
+
+	frontier := ParetoFrontier(options, DefaultTieBreak)
+	var got []string
+	for _, o := range frontier {
+		got = append(got, o.Region)
+	}
+	if want := []string{"a", "d2", "d", "f"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("frontier %v, want %v", got, want)
+	}
+
+	wantBest := [][]string{{DimensionCost}, nil, nil, {DimensionPerformance}}
+	for i, o := range frontier {
+		if !reflect.DeepEqual(o.BestOn, wantBest[i]) {
+			t.Errorf("%s best on %v, want %v", o.Region, o.BestOn, wantBest[i])
+		}
+	}
+
+	if options[0].Region != "a" || options[1].Region != "b" {
+		t.Error("ParetoFrontier reordered its input")
+	}
+}
+
+func TestParetoFrontierSingleOption(t *testing.T) {
+	frontier := ParetoFrontier([]Option{{Region: "a", MonthlyCost: 10, PerformanceScore: 0.5}}, DefaultTieBreak)
+	if len(frontier) != 1 || !reflect.DeepEqual(frontier[0].BestOn, []string{DimensionCost, DimensionPerformance}) {
+		t.Errorf("frontier %+v, want the option best on both", frontier)
+	}
+	if frontier := ParetoFrontier(nil, DefaultTieBreak); len(frontier) != 0 {
+		t.Errorf("frontier of no options %+v", frontier)
+	}
+}
+
+// No option satisfying the requirements dominates one on the frontier, and
+// excluded instance types are left out
+func TestComputeFrontier(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	req := &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, ExcludedInstanceTypes: []string{"r5.large"}}
+
+	frontier, err := e.ComputeFrontier(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frontier) < 2 {
+		t.Fatalf("frontier of %d options, want a tradeoff", len(frontier))
+	}
+
+	all := e.instanceOptions(req, func(*InstanceType) bool { return true })
+	for i, f := range frontier {
+		if f.InstanceType == "r5.large" {
+			t.Errorf("excluded instance type on the frontier at %d", i)
+		}
+		if i > 0 && f.MonthlyCost < frontier[i-1].MonthlyCost {
+			t.Errorf("frontier not ordered by cost at %d", i)
+		}
+		for _, o := range all {
+			if o.InstanceType == "r5.large" {
+				continue
+			}
+			if o.MonthlyCost <= f.MonthlyCost && o.PerformanceScore >= f.PerformanceScore &&
+				(o.MonthlyCost < f.MonthlyCost || o.PerformanceScore > f.PerformanceScore) {
+				t.Errorf("%s %s/%s on the frontier is dominated by %s %s/%s", f.InstanceType, f.Provider, f.Region, o.InstanceType, o.Provider, o.Region)
+			}
+		}
+	}
+}
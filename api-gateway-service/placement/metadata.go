@@ -0,0 +1,26 @@
+package placement
+
+import "fmt"
+
+// Limits on placement metadata, bounding what each placement stores
+const (
+	MaxMetadataKeyLength = 128
+	// MaxMetadataSize is the combined length in bytes of every key and value
+	MaxMetadataSize = 8192
+)
+
+// ValidateMetadata checks that metadata keys are set and that the metadata
+// fits within MaxMetadataSize. Values are opaque to the optimizer.
+func ValidateMetadata(metadata map[string]string) error {
+	size := 0
+	for key, value := range metadata {
+		if key == "" || len(key) > MaxMetadataKeyLength {
+			return fmt.Errorf("metadata key %q must be 1 to %d characters", key, MaxMetadataKeyLength)
+		}
+		size += len(key) + len(value)
+	}
+	if size > MaxMetadataSize {
+		return fmt.Errorf("metadata must be at most %d bytes in total, got %d", MaxMetadataSize, size)
+	}
+	return nil
+}
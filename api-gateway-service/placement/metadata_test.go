@@ -0,0 +1,37 @@
+package placement
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  string
+	}{
+		{name: "none"},
+		{name: "ticket and owner", metadata: map[string]string{"ticket": "OPS-42", "owner": "platform"}},
+		{name: "at the size limit", metadata: map[string]string{"k": strings.Repeat("v", MaxMetadataSize-1)}},
+		{name: "empty value", metadata: map[string]string{"owner": ""}},
+		{name: "empty key", metadata: map[string]string{"": "x"}, wantErr: "must be 1 to 128 characters"},
+		{name: "long key", metadata: map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "x"}, wantErr: "must be 1 to 128 characters"},
+		{name: "oversized", metadata: map[string]string{"a": strings.Repeat("v", 5000), "b": strings.Repeat("v", 5000)}, wantErr: "at most 8192 bytes in total, got 10002"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMetadata(tt.metadata)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateMetadata() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateMetadata() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package placement
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MultiRegion requests an active-active deployment distributed across several
+// regions. RegionWeights sets the share of traffic routed to each region; when
+// it is empty the engine picks the MinRegions best-scoring regions and weights
+// them equally.
+type MultiRegion struct {
+	RegionWeights map[string]float64 `json:"region_weights,omitempty"`
+	MinRegions    int                `json:"min_regions"`
+}
+
+// RegionAllocation is one region of a multi-region placement. Each region runs
+// a full replica so it can absorb failover from the others; Weight is the
+// normalized share of traffic it serves.
+type RegionAllocation struct {
+	Provider     string  `json:"provider"`
+	Region       string  `json:"region"`
+	InstanceType string  `json:"instance_type,omitempty"`
+	Weight       float64 `json:"weight"`
+	MonthlyCost  float64 `json:"monthly_cost"`
+	// Zones are the availability zones selected in the region
+	Zones []string `json:"zones,omitempty"`
+}
+
+// Validate checks the weights and that the allowed regions can satisfy the
+// minimum region count. An empty allowed list permits every catalog region.
+func (m *MultiRegion) Validate(allowedRegions []string) error {
+	if m.MinRegions < 1 {
+		return fmt.Errorf("multi_region.min_regions must be at least 1")
+	}
+
+	allowed := make(map[string]bool, len(allowedRegions))
+	for _, r := range allowedRegions {
+		allowed[r] = true
+	}
+
+	if len(m.RegionWeights) == 0 {
+		if len(allowedRegions) > 0 && len(allowed) < m.MinRegions {
+			return fmt.Errorf("multi_region.min_regions is %d but only %d regions are allowed", m.MinRegions, len(allowed))
+		}
+		return nil
+	}
+
+	if len(m.RegionWeights) < m.MinRegions {
+		return fmt.Errorf("multi_region.min_regions is %d but only %d regions are weighted", m.MinRegions, len(m.RegionWeights))
+	}
+
+	for region, weight := range m.RegionWeights {
+		if weight <= 0 {
+			return fmt.Errorf("weight for region %s must be greater than 0", region)
+		}
+		if len(allowed) > 0 && !allowed[region] {
+			return fmt.Errorf("weighted region %s is not in the allowed regions", region)
+		}
+	}
+	return nil
+}
+
+// allocateRegions distributes a placement across regions using the best-ranked
+// option in each. Options must already be ranked. Allocations are ordered by
+// weight, highest first, so the first allocation is the primary region.
+func allocateRegions(ranked []Option, m *MultiRegion) ([]RegionAllocation, error) {
+	best := make(map[string]Option)
+	var order []string
+	for _, o := range ranked {
+		if _, seen := best[o.Region]; seen {
+			continue
+		}
+		best[o.Region] = o
+		order = append(order, o.Region)
+	}
+
+	weights := m.RegionWeights
+	if len(weights) == 0 {
+		if len(order) < m.MinRegions {
+			return nil, fmt.Errorf("only %d regions satisfy the requirements but min_regions is %d", len(order), m.MinRegions)
+		}
+		weights = make(map[string]float64, m.MinRegions)
+		for _, region := range order[:m.MinRegions] {
+			weights[region] = 1
+		}
+	}
+
+	total := 0.0
+	for region, weight := range weights {
+		if _, ok := best[region]; !ok {
+			return nil, fmt.Errorf("no candidate satisfies the requirements in region %s", region)
+		}
+		total += weight
+	}
+
+	rank := make(map[string]int, len(order))
+	for i, region := range order {
+		rank[region] = i
+	}
+
+	allocations := make([]RegionAllocation, 0, len(weights))
+	for region, weight := range weights {
+		o := best[region]
+		allocations = append(allocations, RegionAllocation{
+			Provider:     o.Provider,
+			Region:       o.Region,
+			InstanceType: o.InstanceType,
+			Zones:        o.Zones,
+			Weight:       weight / total,
+			MonthlyCost:  o.MonthlyCost,
+		})
+	}
+
+	sort.Slice(allocations, func(i, j int) bool {
+		if allocations[i].Weight != allocations[j].Weight {
+			return allocations[i].Weight > allocations[j].Weight
+		}
+		return rank[allocations[i].Region] < rank[allocations[j].Region]
+	})
+	return allocations, nil
+}
+
+// AggregateCost returns the combined monthly cost of every allocated region
+func AggregateCost(allocations []RegionAllocation) float64 {
+	total := 0.0
+	for _, a := range allocations {
+		total += a.MonthlyCost
+	}
+	return total
+}
@@ -0,0 +1,113 @@
+package placement
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAllocateRegions(t *testing.T) {
+	// Ranked best first; us-east-1 appears twice, its first option is used
+	ranked := []Option{
+		{Provider: "aws", Region: "us-east-1", InstanceType: "m5.large", MonthlyCost: 70},
+		{Provider: "aws", Region: "us-east-1", InstanceType: "m5.xlarge", MonthlyCost: 140},
+		{Provider: "gcp", Region: "us-central1", InstanceType: "n2-standard-2", MonthlyCost: 72},
+		{Provider: "azure", Region: "eastus", InstanceType: "D2s_v3", MonthlyCost: 75},
+	}
+
+	tests := []struct {
+		name        string
+		multiRegion MultiRegion
+		// want lists the expected allocations in order
+		want    []RegionAllocation
+		wantErr bool
+	}{
+		{
+			name:        "weights normalized, highest first",
+			multiRegion: MultiRegion{RegionWeights: map[string]float64{"us-central1": 3, "eastus": 1}, MinRegions: 2},
+			want: []RegionAllocation{
+				{Region: "us-central1", Weight: 0.75, MonthlyCost: 72},
+				{Region: "eastus", Weight: 0.25, MonthlyCost: 75},
+			},
+		},
+		{
+			name:        "equal weights in rank order",
+			multiRegion: MultiRegion{RegionWeights: map[string]float64{"eastus": 1, "us-east-1": 1}, MinRegions: 2},
+			want: []RegionAllocation{
+				{Region: "us-east-1", Weight: 0.5, MonthlyCost: 70},
+				{Region: "eastus", Weight: 0.5, MonthlyCost: 75},
+			},
+		},
+		{
+			name:        "best regions without weights",
+			multiRegion: MultiRegion{MinRegions: 3},
+			want: []RegionAllocation{
+				{Region: "us-east-1", Weight: 1.0 / 3, MonthlyCost: 70},
+				{Region: "us-central1", Weight: 1.0 / 3, MonthlyCost: 72},
+				{Region: "eastus", Weight: 1.0 / 3, MonthlyCost: 75},
+			},
+		},
+		{name: "more regions than candidates", multiRegion: MultiRegion{MinRegions: 4}, wantErr: true},
+		{name: "weighted region without a candidate", multiRegion: MultiRegion{RegionWeights: map[string]float64{"westeurope": 1}, MinRegions: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allocateRegions(ranked, &tt.multiRegion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("allocateRegions() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("allocations %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i].Region != want.Region || math.Abs(got[i].Weight-want.Weight) > 1e-9 || got[i].MonthlyCost != want.MonthlyCost {
+					t.Errorf("allocation %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiRegionValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		multiRegion MultiRegion
+		allowed     []string
+		wantErr     bool
+	}{
+		{name: "unweighted", multiRegion: MultiRegion{MinRegions: 2}},
+		{name: "weighted", multiRegion: MultiRegion{RegionWeights: map[string]float64{"us-east-1": 2, "eastus": 1}, MinRegions: 2}, allowed: []string{"us-east-1", "eastus"}},
+		{name: "no minimum", multiRegion: MultiRegion{}, wantErr: true},
+		{name: "fewer allowed regions than the minimum", multiRegion: MultiRegion{MinRegions: 3}, allowed: []string{"us-east-1", "eastus"}, wantErr: true},
+		{name: "fewer weighted regions than the minimum", multiRegion: MultiRegion{RegionWeights: map[string]float64{"us-east-1": 1}, MinRegions: 2}, wantErr: true},
+		{name: "zero weight", multiRegion: MultiRegion{RegionWeights: map[string]float64{"us-east-1": 0}, MinRegions: 1}, wantErr: true},
+		{name: "weighted region not allowed", multiRegion: MultiRegion{RegionWeights: map[string]float64{"eastus": 1}, MinRegions: 1}, allowed: []string{"us-east-1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.multiRegion.Validate(tt.allowed); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// A multi-region placement spans at least the minimum number of regions and
+// costs the sum of its replicas
+func TestPlaceComputeMultiRegion(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	d, err := e.PlaceCompute(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, MultiRegion: &MultiRegion{MinRegions: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Allocations) != 2 || d.Allocations[0].Region == d.Allocations[1].Region {
+		t.Fatalf("allocations %+v, want two regions", d.Allocations)
+	}
+	if d.AggregateMonthlyCost != AggregateCost(d.Allocations) {
+		t.Errorf("aggregate cost %v, want %v", d.AggregateMonthlyCost, AggregateCost(d.Allocations))
+	}
+	if d.Selected.Region != d.Allocations[0].Region {
+		t.Errorf("selected %s, want the primary region %s", d.Selected.Region, d.Allocations[0].Region)
+	}
+}
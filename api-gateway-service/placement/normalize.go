@@ -0,0 +1,101 @@
+package placement
+
+// NormalizedScores are an option's performance and compliance scores min-max
+// normalized across the candidate set it was ranked in, so the scores of
+// providers rating on different scales are comparable
+type NormalizedScores struct {
+	Performance float64 `json:"performance"`
+	Compliance  float64 `json:"compliance"`
+}
+
+// WithScoreNormalization min-max normalizes the performance and compliance
+// scores across each candidate set before the total scores are computed. The
+// raw scores are kept; the normalized ones are set in NormalizedScores.
+func WithScoreNormalization(enabled bool) EngineOption {
+	return func(e *Engine) {
+		e.normalize = enabled
+	}
+}
+
+// scoreRange is the lowest and highest value of a score across a candidate set
+type scoreRange struct {
+	low, high float64
+}
+
+// rangeOf returns the range of the score across the options, which must not
+// be empty
+func rangeOf(options []Option, score func(Option) float64) scoreRange {
+	r := scoreRange{low: score(options[0]), high: score(options[0])}
+	for _, o := range options[1:] {
+		v := score(o)
+		if v < r.low {
+			r.low = v
+		}
+		if v > r.high {
+			r.high = v
+		}
+	}
+	return r
+}
+
+// normalize maps v into [0,1] by its position in the range, clamping values
+// outside it. Every value of an empty range is normalized to 1, since no
+// candidate scores worse than another.
+func (r scoreRange) normalize(v float64) float64 {
+	if r.high <= r.low {
+		return 1
+	}
+	n := (v - r.low) / (r.high - r.low)
+	if n < 0 {
+		return 0
+	}
+	if n > 1 {
+		return 1
+	}
+	return n
+}
+
+// normalizeScores sets the normalized scores of the options by the range of
+// the raw scores across the reference candidate set. Options are unchanged
+// when the reference set is empty.
+func normalizeScores(options, reference []Option) {
+	if len(reference) == 0 {
+		return
+	}
+
+	performance := rangeOf(reference, func(o Option) float64 { return o.PerformanceScore })
+	compliance := rangeOf(reference, func(o Option) float64 { return o.ComplianceScore })
+	for i := range options {
+		options[i].NormalizedScores = &NormalizedScores{
+			Performance: performance.normalize(options[i].PerformanceScore),
+			Compliance:  compliance.normalize(options[i].ComplianceScore),
+		}
+	}
+}
+
+// performance returns the performance score the total is computed from: the
+// normalized score when the option has one, or else the raw score
+func (o Option) performance() float64 {
+	if o.NormalizedScores != nil {
+		return o.NormalizedScores.Performance
+	}
+	return o.PerformanceScore
+}
+
+// compliance returns the compliance score the total is computed from: the
+// normalized score when the option has one, or else the raw score
+func (o Option) compliance() float64 {
+	if o.NormalizedScores != nil {
+		return o.NormalizedScores.Compliance
+	}
+	return o.ComplianceScore
+}
+
+// rank normalizes the options' scores if the engine is configured to and
+// ranks them with the engine's tie-break order
+func (e *Engine) rank(options []Option) {
+	if e.normalize {
+		normalizeScores(options, options)
+	}
+	Rank(options, e.tieBreak)
+}
@@ -0,0 +1,116 @@
+package placement
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeScores(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []Option
+		reference []Option
+		want      []NormalizedScores
+	}{
+		{
+			name: "scores spread over the candidate range",
+			options: []Option{
+				{PerformanceScore: 0.6, ComplianceScore: 0.5},
+				{PerformanceScore: 0.9, ComplianceScore: 1},
+				{PerformanceScore: 0.75, ComplianceScore: 0.75},
+			},
+			want: []NormalizedScores{{0, 0}, {1, 1}, {0.5, 0.5}},
+		},
+		{
+			name: "providers on different scales become comparable",
+			options: []Option{
+				{PerformanceScore: 40, ComplianceScore: 1},
+				{PerformanceScore: 80, ComplianceScore: 1},
+			},
+			want: []NormalizedScores{{0, 1}, {1, 1}},
+		},
+		{
+			name:    "a single candidate scores 1",
+			options: []Option{{PerformanceScore: 0.3, ComplianceScore: 0.2}},
+			want:    []NormalizedScores{{1, 1}},
+		},
+		{
+			name:      "scores outside the reference range are clamped",
+			options:   []Option{{PerformanceScore: 0.4, ComplianceScore: 1.2}, {PerformanceScore: 0.7, ComplianceScore: 0.9}},
+			reference: []Option{{PerformanceScore: 0.5, ComplianceScore: 0.8}, {PerformanceScore: 0.9, ComplianceScore: 1}},
+			want:      []NormalizedScores{{0, 1}, {0.5, 0.5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reference := tt.reference
+			if reference == nil {
+				reference = tt.options
+			}
+			normalizeScores(tt.options, reference)
+
+			for i, o := range tt.options {
+				got, want := o.NormalizedScores, tt.want[i]
+				if got == nil || math.Abs(got.Performance-want.Performance) > 1e-9 || math.Abs(got.Compliance-want.Compliance) > 1e-9 {
+					t.Errorf("option %d normalized to %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeScoresEmptyReference(t *testing.T) {
+	options := []Option{{PerformanceScore: 0.5}}
+	normalizeScores(options, nil)
+	if options[0].NormalizedScores != nil {
+		t.Errorf("normalized against no candidates: %+v", options[0].NormalizedScores)
+	}
+}
+
+// Normalizing changes which option wins when raw scores barely differ: the
+// best performer gets the full performance weight
+func TestEngineRankNormalization(t *testing.T) {
+	candidates := func() []Option {
+		return []Option{
+			{Provider: "aws", Region: "us-east-1", MonthlyCost: 100, PerformanceScore: 0.6, ComplianceScore: 1},
+			{Provider: "gcp", Region: "us-east1", MonthlyCost: 110, PerformanceScore: 0.7, ComplianceScore: 1},
+		}
+	}
+	tests := []struct {
+		name      string
+		normalize bool
+		want      string
+		wantTotal float64
+	}{
+		// 0.5*1 + 0.3*0.6 + 0.2*1
+		{name: "raw scores", want: "aws", wantTotal: 0.88},
+		// 0.5*100/110 + 0.3*1 + 0.2*1
+		{name: "normalized scores", normalize: true, want: "gcp", wantTotal: 0.5*100/110 + 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{normalize: tt.normalize, tieBreak: DefaultTieBreak}
+			options := candidates()
+			e.rank(options)
+
+			if options[0].Provider != tt.want {
+				t.Errorf("ranked %s first, want %s", options[0].Provider, tt.want)
+			}
+			if math.Abs(options[0].TotalScore-tt.wantTotal) > 1e-9 {
+				t.Errorf("total score %g, want %g", options[0].TotalScore, tt.wantTotal)
+			}
+			// The raw scores are kept either way
+			raw := map[string]float64{"aws": 0.6, "gcp": 0.7}
+			for _, o := range options {
+				if o.PerformanceScore != raw[o.Provider] {
+					t.Errorf("%s raw performance score changed to %g", o.Provider, o.PerformanceScore)
+				}
+			}
+			if (options[0].NormalizedScores != nil) != tt.normalize {
+				t.Errorf("normalized scores = %+v, want set %v", options[0].NormalizedScores, tt.normalize)
+			}
+		})
+	}
+}
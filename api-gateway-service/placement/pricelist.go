@@ -0,0 +1,77 @@
+package placement
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultPriceListDate is when the prices of DefaultCatalog were last updated
+var DefaultPriceListDate = time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+// PriceListSource supplies the catalog placement costs are estimated from
+type PriceListSource interface {
+	// Load returns the catalog and when its prices were last updated
+	Load() (*Catalog, time.Time, error)
+}
+
+// BuiltinPriceList is the source of DefaultCatalog
+type BuiltinPriceList struct{}
+
+// Load returns DefaultCatalog and DefaultPriceListDate
+func (BuiltinPriceList) Load() (*Catalog, time.Time, error) {
+	return DefaultCatalog(), DefaultPriceListDate, nil
+}
+
+// FilePriceList loads a catalog from a JSON file holding the catalog's
+// providers and an updated_at timestamp. Without updated_at, the file's
+// modification time is used.
+type FilePriceList struct {
+	Path string
+}
+
+// Load reads and decodes the price list file
+func (f FilePriceList) Load() (*Catalog, time.Time, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read price list: %v", err)
+	}
+
+	var file struct {
+		UpdatedAt time.Time `json:"updated_at"`
+		Catalog
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to decode price list %s: %v", f.Path, err)
+	}
+	if len(file.Providers) == 0 {
+		return nil, time.Time{}, fmt.Errorf("price list %s has no providers", f.Path)
+	}
+	for name, p := range file.Providers {
+		if p.Name == "" {
+			p.Name = name
+		}
+		for i := range p.Regions {
+			if p.Regions[i].Provider == "" {
+				p.Regions[i].Provider = p.Name
+			}
+		}
+	}
+
+	updated := file.UpdatedAt
+	if updated.IsZero() {
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to read price list: %v", err)
+		}
+		updated = info.ModTime()
+	}
+	return &file.Catalog, updated.UTC(), nil
+}
+
+// PricesStale reports whether a price list updated at updated is older than
+// staleAfter at now. A zero staleAfter disables the check.
+func PricesStale(updated time.Time, staleAfter time.Duration, now time.Time) bool {
+	return staleAfter > 0 && now.Sub(updated) > staleAfter
+}
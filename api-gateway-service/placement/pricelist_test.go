@@ -0,0 +1,106 @@
+package placement
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPricesStale(t *testing.T) {
+	now := time.Date(2026, 9, 16, 0, 0, 0, 0, time.UTC)
+	month := 30 * 24 * time.Hour
+	tests := []struct {
+		name       string
+		updated    time.Time
+		staleAfter time.Duration
+		want       bool
+	}{
+		{name: "fresh", updated: now.AddDate(0, 0, -10), staleAfter: month},
+		{name: "at the threshold", updated: now.Add(-month), staleAfter: month},
+		{name: "past the threshold", updated: now.Add(-month - time.Second), staleAfter: month, want: true},
+		{name: "check disabled", updated: now.AddDate(-1, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		if got := PricesStale(tt.updated, tt.staleAfter, now); got != tt.want {
+			t.Errorf("%s: PricesStale() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Decisions report the price list date and are flagged once it is older
+// than the engine's threshold
+func TestPlaceComputePriceList(t *testing.T) {
+	req := &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8}
+	month := 30 * 24 * time.Hour
+	tests := []struct {
+		name      string
+		opts      []EngineOption
+		wantDate  bool
+		wantStale bool
+	}{
+		{name: "unknown date"},
+		{name: "fresh", opts: []EngineOption{WithPriceList(time.Now().AddDate(0, 0, -10), month)}, wantDate: true},
+		{name: "stale", opts: []EngineOption{WithPriceList(time.Now().AddDate(0, 0, -40), month)}, wantDate: true, wantStale: true},
+		{name: "check disabled", opts: []EngineOption{WithPriceList(time.Now().AddDate(-1, 0, 0), 0)}, wantDate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewEngine(DefaultCatalog(), tt.opts...).PlaceCompute(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (d.PriceListDate != nil) != tt.wantDate || d.PricesStale != tt.wantStale {
+				t.Errorf("price list date %v, stale %v; want date %v, stale %v", d.PriceListDate, d.PricesStale, tt.wantDate, tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestFilePriceList(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	const providers = `"providers":{"aws":{"regions":[{"name":"us-east-1"}],"instance_types":[{"name":"m5.large","vcpus":2,"memory_gb":8}]}}`
+
+	catalog, updated, err := FilePriceList{Path: write("dated.json", `{"updated_at":"2026-08-01T00:00:00Z",`+providers+`}`)}.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Equal(time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("updated %v, want the file's updated_at", updated)
+	}
+	aws, err := catalog.Provider("aws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.Name != "aws" || aws.Regions[0].Provider != "aws" {
+		t.Errorf("provider %q with region of %q, want both named from the key", aws.Name, aws.Regions[0].Provider)
+	}
+
+	// Without updated_at the file's modification time is used
+	path := write("undated.json", `{`+providers+`}`)
+	modTime := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if _, updated, err := (FilePriceList{Path: path}).Load(); err != nil || !updated.Equal(modTime) {
+		t.Errorf("updated %v (%v), want the modification time %v", updated, err, modTime)
+	}
+
+	for name, data := range map[string]string{"empty.json": `{"updated_at":"2026-08-01T00:00:00Z"}`, "invalid.json": `{`} {
+		if _, _, err := (FilePriceList{Path: write(name, data)}).Load(); err == nil {
+			t.Errorf("%s loaded", name)
+		}
+	}
+	if _, _, err := (FilePriceList{Path: filepath.Join(dir, "missing.json")}).Load(); err == nil {
+		t.Error("missing file loaded")
+	}
+}
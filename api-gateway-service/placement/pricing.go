@@ -0,0 +1,103 @@
+package placement
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// PricingOverride is a tenant's negotiated pricing with one provider, such
+// as an enterprise discount program or committed-use discount
+type PricingOverride struct {
+	// DiscountPercent reduces every list price of the provider, including
+	// data transfer and egress, e.g. 20 for a 20% discount
+	DiscountPercent float64 `json:"discount_percent,omitempty"`
+	// InstancePrices are custom hourly prices by instance type, used instead
+	// of the discounted list price. The region price multiplier still
+	// applies.
+	InstancePrices map[string]float64 `json:"instance_prices,omitempty"`
+}
+
+// Pricing is a tenant's pricing overrides by provider
+type Pricing map[string]PricingOverride
+
+// PricingOverrides are pricing overrides by tenant ID
+type PricingOverrides map[string]Pricing
+
+// LoadPricingOverrides reads pricing overrides from a JSON file mapping
+// tenant IDs to providers to overrides
+func LoadPricingOverrides(path string) (PricingOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing overrides: %v", err)
+	}
+
+	var overrides PricingOverrides
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode pricing overrides %s: %v", path, err)
+	}
+	if err := overrides.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pricing overrides %s: %v", path, err)
+	}
+	return overrides, nil
+}
+
+// Validate checks that discounts are below 100% and custom prices are not
+// negative
+func (o PricingOverrides) Validate() error {
+	for tenantID, pricing := range o {
+		for provider, override := range pricing {
+			if override.DiscountPercent < 0 || override.DiscountPercent >= 100 {
+				return fmt.Errorf("tenant %s: %s discount_percent must be at least 0 and below 100", tenantID, provider)
+			}
+			for instanceType, price := range override.InstancePrices {
+				if price < 0 {
+					return fmt.Errorf("tenant %s: %s instance_prices.%s must not be negative", tenantID, provider, instanceType)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// WithPricing returns a copy of the engine that estimates costs with the
+// pricing overrides, for placing a tenant's requests
+func (e *Engine) WithPricing(pricing Pricing) *Engine {
+	priced := *e
+	priced.pricing = pricing
+	return &priced
+}
+
+// customPricing reports whether the engine's costs for the provider are
+// overridden
+func (e *Engine) customPricing(provider string) bool {
+	_, ok := e.pricing[provider]
+	return ok
+}
+
+// priceFactor is the fraction of list prices charged by the provider
+func (e *Engine) priceFactor(provider string) float64 {
+	return 1 - e.pricing[provider].DiscountPercent/100
+}
+
+// hourlyPrice returns the instance type's hourly price before the region
+// price multiplier: its custom price if set, else its discounted list price
+func (e *Engine) hourlyPrice(provider string, it *InstanceType) float64 {
+	if price, ok := e.pricing[provider].InstancePrices[it.Name]; ok {
+		return price
+	}
+	return it.HourlyPrice * e.priceFactor(provider)
+}
+
+// costBreakdown itemizes the monthly cost of running the instance type in
+// the region at the engine's prices
+func (e *Engine) costBreakdown(p *ProviderCatalog, it *InstanceType, r *Region) map[string]float64 {
+	breakdown := computeCostBreakdown(p, it, r)
+	if !e.customPricing(p.Name) {
+		return breakdown
+	}
+
+	breakdown[CostCompute] = e.hourlyPrice(p.Name, it) * r.PriceMultiplier * HoursPerMonth
+	breakdown[CostStorage] *= e.priceFactor(p.Name)
+	return breakdown
+}
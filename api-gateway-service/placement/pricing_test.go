@@ -0,0 +1,111 @@
+package placement
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlaceComputePricing(t *testing.T) {
+	list := NewEngine(DefaultCatalog())
+	req := func() *ComputeRequirements {
+		return &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: []string{"us-east-1"}}
+	}
+	listed, err := list.PlaceCompute(req())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed.CustomPricing {
+		t.Error("list prices noted as custom pricing")
+	}
+
+	// A 20% discount takes 20% off the compute and storage costs
+	discounted, err := list.WithPricing(Pricing{"aws": {DiscountPercent: 20}}).PlaceCompute(req())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if discounted.Selected.InstanceType != listed.Selected.InstanceType {
+		t.Fatalf("discount changed the selection from %s to %s", listed.Selected.InstanceType, discounted.Selected.InstanceType)
+	}
+	if want := listed.Selected.MonthlyCost * 0.8; math.Abs(discounted.Selected.MonthlyCost-want) > 1e-9 {
+		t.Errorf("discounted monthly cost %v, want 80%% of %v", discounted.Selected.MonthlyCost, listed.Selected.MonthlyCost)
+	}
+	for _, item := range []string{CostCompute, CostStorage} {
+		if want := listed.Selected.CostBreakdown[item] * 0.8; math.Abs(discounted.Selected.CostBreakdown[item]-want) > 1e-9 {
+			t.Errorf("discounted %s cost %v, want %v", item, discounted.Selected.CostBreakdown[item], want)
+		}
+	}
+	if !discounted.CustomPricing {
+		t.Error("discounted placement not noted as custom pricing")
+	}
+
+	// A custom instance price replaces the list price, still scaled by the
+	// region
+	custom, err := list.WithPricing(Pricing{"aws": {InstancePrices: map[string]float64{listed.Selected.InstanceType: 0.01}}}).PlaceCompute(req())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.01 * HoursPerMonth; custom.Selected.InstanceType != listed.Selected.InstanceType || math.Abs(custom.Selected.CostBreakdown[CostCompute]-want) > 1e-9 {
+		t.Errorf("%s compute cost %v, want %s at %v", custom.Selected.InstanceType, custom.Selected.CostBreakdown[CostCompute], listed.Selected.InstanceType, want)
+	}
+
+	// Pricing for another provider leaves the placement at list prices
+	other, err := list.WithPricing(Pricing{"azure": {DiscountPercent: 20}}).PlaceCompute(req())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.CustomPricing || other.Selected.MonthlyCost != listed.Selected.MonthlyCost {
+		t.Errorf("azure pricing changed an aws placement: %v, custom %v", other.Selected.MonthlyCost, other.CustomPricing)
+	}
+
+	// The engine the priced copy came from keeps list prices
+	again, err := list.PlaceCompute(req())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Selected.MonthlyCost != listed.Selected.MonthlyCost || again.CustomPricing {
+		t.Errorf("pricing leaked into the list-price engine: %v", again.Selected.MonthlyCost)
+	}
+}
+
+func TestLoadPricingOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "valid", data: `{"acme":{"aws":{"discount_percent":20},"gcp":{"instance_prices":{"n2-standard-2":0.08}}}}`},
+		{name: "malformed", data: `{"acme":`, wantErr: "failed to decode pricing overrides"},
+		{name: "full discount", data: `{"acme":{"aws":{"discount_percent":100}}}`, wantErr: "tenant acme: aws discount_percent must be at least 0 and below 100"},
+		{name: "negative discount", data: `{"acme":{"aws":{"discount_percent":-5}}}`, wantErr: "discount_percent must be at least 0"},
+		{name: "negative price", data: `{"acme":{"gcp":{"instance_prices":{"n2-standard-2":-1}}}}`, wantErr: "gcp instance_prices.n2-standard-2 must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pricing.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			overrides, err := LoadPricingOverrides(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if overrides["acme"]["aws"].DiscountPercent != 20 || overrides["acme"]["gcp"].InstancePrices["n2-standard-2"] != 0.08 {
+					t.Errorf("overrides %+v", overrides)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadPricingOverrides() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadPricingOverrides(filepath.Join(t.TempDir(), "none.json")); err == nil || !strings.Contains(err.Error(), "failed to read pricing overrides") {
+		t.Errorf("missing file: %v", err)
+	}
+}
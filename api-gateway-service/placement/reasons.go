@@ -0,0 +1,115 @@
+package placement
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rejection reason categories, each followed by ": " and details
+const (
+	ReasonHigherCost       = "higher cost"
+	ReasonLowerPerformance = "lower performance"
+	ReasonComplianceGap    = "compliance gap"
+	ReasonFartherAway      = "farther from related resources"
+	ReasonTieBreak         = "tie-break"
+	// ReasonExcludedInstanceType marks alternatives of an instance type the
+	// request excluded; they are listed after the ranked alternatives
+	ReasonExcludedInstanceType = "excluded instance type"
+)
+
+// costScore recovers the relative cost score Rank combined into the total
+func costScore(o Option) float64 {
+	return (o.TotalScore - performanceWeight*o.performance() - complianceWeight*o.compliance() -
+		affinityWeight*o.AffinityScore) / costWeight
+}
+
+// explainSelection sets the rejection reason of each alternative from the
+// score component where it fell furthest behind the selected option, and
+// returns why the selected option was chosen. Options must already be ranked
+// with the tie-break order.
+func explainSelection(selected Option, alternatives []Option, tieBreak []string) string {
+	for i := range alternatives {
+		alternatives[i].RejectionReason = rejectionReason(selected, alternatives[i], tieBreak)
+	}
+
+	if len(alternatives) == 0 {
+		return "only option satisfying the requirements"
+	}
+
+	var strengths []string
+	cheapest, fastest, compliant, closest := true, true, true, len(selected.Affinity) > 0
+	for _, a := range alternatives {
+		cheapest = cheapest && selected.MonthlyCost <= a.MonthlyCost
+		fastest = fastest && selected.PerformanceScore >= a.PerformanceScore
+		compliant = compliant && selected.ComplianceScore >= a.ComplianceScore
+		closest = closest && selected.AffinityScore >= a.AffinityScore
+	}
+	if cheapest {
+		strengths = append(strengths, "lowest cost")
+	}
+	if fastest {
+		strengths = append(strengths, "highest performance")
+	}
+	if compliant {
+		strengths = append(strengths, "best compliance")
+	}
+	if closest {
+		strengths = append(strengths, "closest to related resources")
+	}
+
+	reason := fmt.Sprintf("highest total score (%.2f vs %.2f for the next best option)", selected.TotalScore, alternatives[0].TotalScore)
+	if selected.TotalScore == alternatives[0].TotalScore {
+		reason = fmt.Sprintf("tied for highest total score (%.2f), chosen by %s", selected.TotalScore, describeTieBreak(tieBreak))
+	}
+	if len(strengths) > 0 {
+		reason += "; " + strings.Join(strengths, ", ")
+	}
+	return reason
+}
+
+// rejectionReason explains why a ranked below selected
+func rejectionReason(selected, a Option, tieBreak []string) string {
+	deficits := []struct {
+		deficit float64
+		reason  string
+	}{
+		{
+			costWeight * (costScore(selected) - costScore(a)),
+			fmt.Sprintf("%s: %.2f more per month", ReasonHigherCost, a.MonthlyCost-selected.MonthlyCost),
+		},
+		{
+			performanceWeight * (selected.performance() - a.performance()),
+			fmt.Sprintf("%s: score %.2f vs %.2f", ReasonLowerPerformance, a.PerformanceScore, selected.PerformanceScore),
+		},
+		{
+			complianceWeight * (selected.compliance() - a.compliance()),
+			fmt.Sprintf("%s: score %.2f vs %.2f", ReasonComplianceGap, a.ComplianceScore, selected.ComplianceScore),
+		},
+		{
+			affinityWeight * (selected.AffinityScore - a.AffinityScore),
+			fmt.Sprintf("%s: %.0f ms vs %.0f ms", ReasonFartherAway, maxLatency(a), maxLatency(selected)),
+		},
+	}
+
+	best := -1
+	for i, d := range deficits {
+		if d.deficit > 1e-9 && (best < 0 || d.deficit > deficits[best].deficit) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ReasonTieBreak + ": equal score, ranked by " + describeTieBreak(tieBreak)
+	}
+	return deficits[best].reason
+}
+
+// maxLatency returns the highest latency from the option to a related resource
+func maxLatency(o Option) float64 {
+	highest := 0.0
+	for _, link := range o.Affinity {
+		if link.LatencyMs > highest {
+			highest = link.LatencyMs
+		}
+	}
+	return highest
+}
@@ -0,0 +1,114 @@
+package placement
+
+import (
+	"strings"
+	"testing"
+)
+
+// scored returns an option whose total score combines the given component
+// scores as Rank does
+func scored(monthlyCost, cost, performance, compliance float64) Option {
+	return Option{
+		Provider:         "aws",
+		Region:           "us-east-1",
+		MonthlyCost:      monthlyCost,
+		PerformanceScore: performance,
+		ComplianceScore:  compliance,
+		TotalScore:       costWeight*cost + performanceWeight*performance + complianceWeight*compliance,
+	}
+}
+
+func TestRejectionReason(t *testing.T) {
+	selected := scored(100, 1, 0.8, 1)
+
+	tests := []struct {
+		name        string
+		alternative Option
+		want        string
+	}{
+		{name: "higher cost", alternative: scored(150, 0.6, 0.8, 1), want: "higher cost: 50.00 more per month"},
+		{name: "lower performance", alternative: scored(100, 1, 0.4, 1), want: "lower performance: score 0.40 vs 0.80"},
+		{name: "compliance gap", alternative: scored(100, 1, 0.8, 0.5), want: "compliance gap: score 0.50 vs 1.00"},
+		{name: "largest deficit wins", alternative: scored(110, 0.95, 0.3, 1), want: ReasonLowerPerformance},
+		{name: "equal score", alternative: scored(100, 1, 0.8, 1), want: "tie-break: equal score, ranked by cost and provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rejectionReason(selected, tt.alternative, []string{"cost", "provider"})
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("rejectionReason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainSelection(t *testing.T) {
+	tieBreak := []string{"cost", "provider"}
+
+	tests := []struct {
+		name         string
+		selected     Option
+		alternatives []Option
+		want         []string
+	}{
+		{name: "only option", selected: scored(100, 1, 0.8, 1), want: []string{"only option satisfying the requirements"}},
+		{
+			name:         "cheapest and fastest",
+			selected:     scored(100, 1, 0.9, 0.5),
+			alternatives: []Option{scored(150, 0.6, 0.8, 1)},
+			want:         []string{"highest total score", "lowest cost, highest performance"},
+		},
+		{
+			name:         "tied",
+			selected:     scored(100, 1, 0.8, 1),
+			alternatives: []Option{scored(100, 1, 0.8, 1)},
+			want:         []string{"tied for highest total score", "chosen by cost and provider"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := explainSelection(tt.selected, tt.alternatives, tieBreak)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("explainSelection = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, a := range tt.alternatives {
+				if a.RejectionReason == "" {
+					t.Errorf("alternative %+v has no rejection reason", a)
+				}
+			}
+		})
+	}
+}
+
+// A placement explains its selection, every alternative names why it was not
+// chosen and the selected option has no rejection reason
+func TestPlaceComputeReasons(t *testing.T) {
+	d, err := NewEngine(DefaultCatalog()).PlaceCompute(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.SelectionReason == "" {
+		t.Error("no selection reason")
+	}
+	if d.Selected.RejectionReason != "" {
+		t.Errorf("selected option rejected because %q", d.Selected.RejectionReason)
+	}
+	if len(d.Alternatives) == 0 {
+		t.Fatal("no alternatives")
+	}
+
+	reasons := []string{ReasonHigherCost, ReasonLowerPerformance, ReasonComplianceGap, ReasonFartherAway, ReasonTieBreak, ReasonExcludedInstanceType}
+	for _, a := range d.Alternatives {
+		known := false
+		for _, reason := range reasons {
+			known = known || strings.HasPrefix(a.RejectionReason, reason+": ")
+		}
+		if !known {
+			t.Errorf("alternative %s/%s rejected because %q, want a known reason", a.Provider, a.Region, a.RejectionReason)
+		}
+	}
+}
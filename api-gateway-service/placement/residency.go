@@ -0,0 +1,91 @@
+package placement
+
+import (
+	"fmt"
+	"strings"
+)
+
+// jurisdictionGroups are the jurisdictions data residency may name in place
+// of listing their member countries
+var jurisdictionGroups = map[string][]string{
+	"EU": {
+		"AT", "BE", "BG", "CY", "CZ", "DE", "DK", "EE", "ES", "FI", "FR", "GR", "HR", "HU",
+		"IE", "IT", "LT", "LU", "LV", "MT", "NL", "PL", "PT", "RO", "SE", "SI", "SK",
+	},
+}
+
+// ValidateDataResidency checks that each jurisdiction is a country code or a
+// known group of countries, listed once
+func ValidateDataResidency(jurisdictions []string) error {
+	seen := make(map[string]bool, len(jurisdictions))
+	for _, j := range jurisdictions {
+		code := strings.ToUpper(j)
+		if _, ok := jurisdictionGroups[code]; !ok && !isCountryCode(code) {
+			return fmt.Errorf("data_residency jurisdiction %q must be an ISO 3166-1 alpha-2 country code or EU", j)
+		}
+		if seen[code] {
+			return fmt.Errorf("data_residency lists jurisdiction %s more than once", code)
+		}
+		seen[code] = true
+	}
+	return nil
+}
+
+// isCountryCode reports whether s has the form of an ISO 3166-1 alpha-2 code
+func isCountryCode(s string) bool {
+	return len(s) == 2 && s[0] >= 'A' && s[0] <= 'Z' && s[1] >= 'A' && s[1] <= 'Z'
+}
+
+// inJurisdiction reports whether a region in the country is within one of the
+// allowed jurisdictions
+func inJurisdiction(country string, allowed []string) bool {
+	if country == "" {
+		return false
+	}
+	for _, j := range allowed {
+		code := strings.ToUpper(j)
+		if code == country || contains(jurisdictionGroups[code], country) {
+			return true
+		}
+	}
+	return false
+}
+
+// jurisdiction returns the catalog jurisdiction of the option's region
+func (e *Engine) jurisdiction(o Option) string {
+	p, err := e.catalog.Provider(o.Provider)
+	if err != nil {
+		return ""
+	}
+	r, err := p.Region(o.Region)
+	if err != nil {
+		return ""
+	}
+	return r.Jurisdiction
+}
+
+// checkResidency checks that a provider the requirements allow has a region
+// within the data residency jurisdictions
+func (e *Engine) checkResidency(req *ComputeRequirements) error {
+	if len(req.DataResidency) == 0 {
+		return nil
+	}
+
+	for _, p := range e.catalog.Providers {
+		if contains(req.ExcludedProviders, p.Name) {
+			continue
+		}
+		for _, r := range p.Regions {
+			if len(req.Regions) > 0 && !contains(req.Regions, r.Name) {
+				continue
+			}
+			if contains(req.ExcludedRegions, r.Name) {
+				continue
+			}
+			if inJurisdiction(r.Jurisdiction, req.DataResidency) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no allowed provider has a region within data residency jurisdictions %s", strings.Join(req.DataResidency, ", "))
+}
@@ -0,0 +1,138 @@
+package placement
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDataResidency(t *testing.T) {
+	tests := []struct {
+		jurisdictions []string
+		wantErr       string
+	}{
+		{jurisdictions: nil},
+		{jurisdictions: []string{"EU", "us", "CH"}},
+		{jurisdictions: []string{"Europe"}, wantErr: "must be an ISO 3166-1 alpha-2 country code or EU"},
+		{jurisdictions: []string{"U1"}, wantErr: "must be an ISO 3166-1 alpha-2 country code or EU"},
+		{jurisdictions: []string{"de", "DE"}, wantErr: "lists jurisdiction DE more than once"},
+	}
+
+	for _, tt := range tests {
+		err := ValidateDataResidency(tt.jurisdictions)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateDataResidency(%v) = %v", tt.jurisdictions, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateDataResidency(%v) = %v, want %q", tt.jurisdictions, err, tt.wantErr)
+		}
+	}
+}
+
+func TestInJurisdiction(t *testing.T) {
+	tests := []struct {
+		country string
+		allowed []string
+		want    bool
+	}{
+		{country: "IE", allowed: []string{"EU"}, want: true},
+		{country: "NL", allowed: []string{"us", "nl"}, want: true},
+		{country: "US", allowed: []string{"EU"}},
+		{country: "CH", allowed: []string{"EU"}},
+		{country: "", allowed: []string{"EU"}},
+	}
+
+	for _, tt := range tests {
+		if got := inJurisdiction(tt.country, tt.allowed); got != tt.want {
+			t.Errorf("inJurisdiction(%q, %v) = %v, want %v", tt.country, tt.allowed, got, tt.want)
+		}
+	}
+}
+
+// Placements with data residency are selected and offered only in regions
+// within the jurisdictions, and note the selected region's jurisdiction
+func TestPlaceComputeDataResidency(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+
+	tests := []struct {
+		name      string
+		req       ComputeRequirements
+		wantIn    []string
+		wantJuris []string
+	}{
+		{name: "EU", req: ComputeRequirements{DataResidency: []string{"EU"}}, wantIn: []string{"eu-west-1", "westeurope", "europe-west1"}, wantJuris: []string{"IE", "NL", "BE"}},
+		{name: "country", req: ComputeRequirements{DataResidency: []string{"nl"}}, wantIn: []string{"westeurope"}, wantJuris: []string{"NL"}},
+		{
+			name:      "with excluded provider",
+			req:       ComputeRequirements{DataResidency: []string{"EU"}, ExcludedProviders: []string{"azure", "gcp"}},
+			wantIn:    []string{"eu-west-1"},
+			wantJuris: []string{"IE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Name, req.VCPUs, req.MemoryGB = "web", 2, 8
+			d, err := e.PlaceCompute(&req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, o := range append([]Option{d.Selected}, d.Alternatives...) {
+				i := indexOf(tt.wantIn, o.Region)
+				if i < 0 {
+					t.Errorf("%s %s is outside the data residency jurisdictions", o.Provider, o.Region)
+					continue
+				}
+				if o.Jurisdiction != tt.wantJuris[i] {
+					t.Errorf("%s jurisdiction %q, want %q", o.Region, o.Jurisdiction, tt.wantJuris[i])
+				}
+			}
+		})
+	}
+
+	d, err := e.PlaceCompute(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Selected.Jurisdiction != "" {
+		t.Errorf("jurisdiction %q noted without data residency", d.Selected.Jurisdiction)
+	}
+}
+
+func TestPlaceComputeDataResidencyUnsatisfiable(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+
+	tests := []struct {
+		name string
+		req  ComputeRequirements
+	}{
+		{name: "no region in the jurisdiction", req: ComputeRequirements{DataResidency: []string{"CH"}}},
+		{name: "jurisdiction only at excluded providers", req: ComputeRequirements{DataResidency: []string{"NL"}, ExcludedProviders: []string{"azure"}}},
+		{name: "allowed regions outside the jurisdiction", req: ComputeRequirements{DataResidency: []string{"EU"}, Regions: []string{"us-east-1", "eastus"}}},
+		{name: "jurisdiction region excluded", req: ComputeRequirements{DataResidency: []string{"IE"}, ExcludedRegions: []string{"eu-west-1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Name, req.VCPUs, req.MemoryGB = "web", 2, 8
+			_, err := e.PlaceCompute(&req)
+			if err == nil || !strings.Contains(err.Error(), "no allowed provider has a region within data residency jurisdictions") {
+				t.Errorf("PlaceCompute() = %v, want the unsatisfiable residency error", err)
+			}
+		})
+	}
+}
+
+// indexOf returns the index of s in list, or -1
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
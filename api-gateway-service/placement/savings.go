@@ -0,0 +1,48 @@
+package placement
+
+import "fmt"
+
+// Savings compares a placement's monthly cost with the cost of what the
+// workload runs on today. EstimatedSavings is negative, and CostIncrease
+// set, when the placement costs more.
+type Savings struct {
+	CurrentMonthlyCost float64 `json:"current_monthly_cost"`
+	EstimatedSavings   float64 `json:"estimated_savings"`
+	// SavingsPercent is EstimatedSavings as a percentage of the current
+	// cost; it is nil when the current cost is 0
+	SavingsPercent *float64 `json:"savings_percent,omitempty"`
+	CostIncrease   bool     `json:"cost_increase"`
+}
+
+// ValidateCurrentMonthlyCost checks that a current monthly cost, if set, is
+// not negative
+func ValidateCurrentMonthlyCost(cost *float64) error {
+	if cost != nil && *cost < 0 {
+		return fmt.Errorf("current_monthly_cost must not be negative")
+	}
+	return nil
+}
+
+// EstimateSavings compares the monthly cost of a placement with the current
+// monthly cost
+func EstimateSavings(current, monthlyCost float64) *Savings {
+	s := &Savings{
+		CurrentMonthlyCost: current,
+		EstimatedSavings:   current - monthlyCost,
+		CostIncrease:       monthlyCost > current,
+	}
+	if current > 0 {
+		percent := s.EstimatedSavings / current * 100
+		s.SavingsPercent = &percent
+	}
+	return s
+}
+
+// monthlyCost returns the aggregate cost of a multi-region decision, or else
+// the selected option's cost
+func (d *Decision) monthlyCost() float64 {
+	if d.AggregateMonthlyCost > 0 {
+		return d.AggregateMonthlyCost
+	}
+	return d.Selected.MonthlyCost
+}
@@ -0,0 +1,69 @@
+package placement
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEstimateSavings(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      float64
+		monthlyCost  float64
+		wantSavings  float64
+		wantPercent  *float64
+		wantIncrease bool
+	}{
+		{name: "positive savings", current: 100, monthlyCost: 75, wantSavings: 25, wantPercent: floatPtr(25)},
+		{name: "negative savings", current: 80, monthlyCost: 100, wantSavings: -20, wantPercent: floatPtr(-25), wantIncrease: true},
+		{name: "same cost", current: 50, monthlyCost: 50, wantPercent: floatPtr(0)},
+		{name: "no current cost", current: 0, monthlyCost: 40, wantSavings: -40, wantIncrease: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := EstimateSavings(tt.current, tt.monthlyCost)
+			if s.CurrentMonthlyCost != tt.current || s.EstimatedSavings != tt.wantSavings || s.CostIncrease != tt.wantIncrease {
+				t.Errorf("savings %+v, want %v saved of %v, increase %v", s, tt.wantSavings, tt.current, tt.wantIncrease)
+			}
+			if (s.SavingsPercent == nil) != (tt.wantPercent == nil) || (s.SavingsPercent != nil && math.Abs(*s.SavingsPercent-*tt.wantPercent) > 1e-9) {
+				t.Errorf("savings percent %v, want %v", s.SavingsPercent, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestPlaceComputeSavings(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+
+	d, err := e.PlaceCompute(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Savings != nil {
+		t.Errorf("savings %+v without a current monthly cost", d.Savings)
+	}
+
+	cheaper, dearer := d.Selected.MonthlyCost*2, d.Selected.MonthlyCost/2
+	for _, current := range []float64{cheaper, dearer} {
+		d, err := e.PlaceCompute(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, CurrentMonthlyCost: &current})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := current - d.Selected.MonthlyCost
+		if d.Savings == nil || math.Abs(d.Savings.EstimatedSavings-want) > 1e-9 || d.Savings.CostIncrease != (want < 0) {
+			t.Errorf("current %v: savings %+v, want %v", current, d.Savings, want)
+		}
+	}
+
+	negative := -1.0
+	err = (&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, CurrentMonthlyCost: &negative}).Validate()
+	if err == nil || !strings.Contains(err.Error(), "current_monthly_cost must not be negative") {
+		t.Errorf("Validate() = %v, want a negative current cost rejected", err)
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
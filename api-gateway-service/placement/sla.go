@@ -0,0 +1,83 @@
+package placement
+
+import "fmt"
+
+// SLATier is a named service level that maps to availability and redundancy
+// requirements
+type SLATier string
+
+// Supported SLA tiers, from least to most strict
+const (
+	SLABronze   SLATier = "bronze"
+	SLASilver   SLATier = "silver"
+	SLAGold     SLATier = "gold"
+	SLAPlatinum SLATier = "platinum"
+)
+
+// slaTiers lists the tiers from least to most strict
+var slaTiers = []SLATier{SLABronze, SLASilver, SLAGold, SLAPlatinum}
+
+// SLARequirements are the placement requirements implied by a tier
+type SLARequirements struct {
+	// MinAvailability is the minimum regional availability percentage
+	MinAvailability float64 `json:"min_availability"`
+	// MinRegions is the number of regions the placement must run in; tiers
+	// above one require a multi-region placement
+	MinRegions int `json:"min_regions"`
+}
+
+var slaRequirements = map[SLATier]SLARequirements{
+	SLABronze:   {MinAvailability: 99.0, MinRegions: 1},
+	SLASilver:   {MinAvailability: 99.9, MinRegions: 1},
+	SLAGold:     {MinAvailability: 99.95, MinRegions: 2},
+	SLAPlatinum: {MinAvailability: 99.99, MinRegions: 3},
+}
+
+// Requirements returns the availability and redundancy requirements of the tier
+func (t SLATier) Requirements() (SLARequirements, error) {
+	r, ok := slaRequirements[t]
+	if !ok {
+		return SLARequirements{}, fmt.Errorf("unknown sla_tier %q: must be one of bronze, silver, gold or platinum", t)
+	}
+	return r, nil
+}
+
+// AchievedTier returns the strictest tier met by a placement running in
+// regions regions whose least available region has the given availability,
+// or "" if it meets none
+func AchievedTier(availability float64, regions int) SLATier {
+	var achieved SLATier
+	for _, t := range slaTiers {
+		r := slaRequirements[t]
+		if availability >= r.MinAvailability && regions >= r.MinRegions {
+			achieved = t
+		}
+	}
+	return achieved
+}
+
+// applySLATier tightens the requirements to those of the SLA tier. Where the
+// tier and the explicit requirements differ the stricter one is kept: the
+// higher availability and the larger region count.
+func (r *ComputeRequirements) applySLATier() error {
+	if r.SLATier == "" {
+		return nil
+	}
+	sla, err := r.SLATier.Requirements()
+	if err != nil {
+		return err
+	}
+
+	if sla.MinAvailability > r.MinAvailability {
+		r.MinAvailability = sla.MinAvailability
+	}
+	if sla.MinRegions > 1 {
+		if r.MultiRegion == nil {
+			r.MultiRegion = &MultiRegion{}
+		}
+		if sla.MinRegions > r.MultiRegion.MinRegions {
+			r.MultiRegion.MinRegions = sla.MinRegions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package placement
+
+import "testing"
+
+func TestSLATierRequirements(t *testing.T) {
+	tests := []struct {
+		tier    SLATier
+		want    SLARequirements
+		wantErr bool
+	}{
+		{tier: SLABronze, want: SLARequirements{MinAvailability: 99.0, MinRegions: 1}},
+		{tier: SLASilver, want: SLARequirements{MinAvailability: 99.9, MinRegions: 1}},
+		{tier: SLAGold, want: SLARequirements{MinAvailability: 99.95, MinRegions: 2}},
+		{tier: SLAPlatinum, want: SLARequirements{MinAvailability: 99.99, MinRegions: 3}},
+		{tier: "diamond", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.tier), func(t *testing.T) {
+			got, err := tt.tier.Requirements()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Requirements() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Requirements() = %+v, want %+v", got, tt.want)
+			}
+			if !tt.wantErr && AchievedTier(tt.want.MinAvailability, tt.want.MinRegions) != tt.tier {
+				t.Errorf("AchievedTier of the tier's own requirements = %q, want %q", AchievedTier(tt.want.MinAvailability, tt.want.MinRegions), tt.tier)
+			}
+		})
+	}
+
+	if got := AchievedTier(98.5, 3); got != "" {
+		t.Errorf("AchievedTier(98.5, 3) = %q, want none", got)
+	}
+	if got := AchievedTier(99.99, 1); got != SLASilver {
+		t.Errorf("AchievedTier(99.99, 1) = %q, want silver as gold needs two regions", got)
+	}
+}
+
+// The stricter of the tier and the explicit requirements applies
+func TestApplySLATier(t *testing.T) {
+	tests := []struct {
+		name             string
+		req              ComputeRequirements
+		wantAvailability float64
+		wantMinRegions   int
+	}{
+		{name: "no tier", req: ComputeRequirements{MinAvailability: 99.5}, wantAvailability: 99.5},
+		{name: "tier stricter", req: ComputeRequirements{SLATier: SLASilver, MinAvailability: 99}, wantAvailability: 99.9},
+		{name: "explicit availability stricter", req: ComputeRequirements{SLATier: SLASilver, MinAvailability: 99.99}, wantAvailability: 99.99},
+		{name: "tier adds regions", req: ComputeRequirements{SLATier: SLAGold}, wantAvailability: 99.95, wantMinRegions: 2},
+		{name: "explicit regions stricter", req: ComputeRequirements{SLATier: SLAGold, MultiRegion: &MultiRegion{MinRegions: 4}}, wantAvailability: 99.95, wantMinRegions: 4},
+		{name: "tier regions stricter", req: ComputeRequirements{SLATier: SLAPlatinum, MultiRegion: &MultiRegion{MinRegions: 2}}, wantAvailability: 99.99, wantMinRegions: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			if err := req.applySLATier(); err != nil {
+				t.Fatal(err)
+			}
+			if req.MinAvailability != tt.wantAvailability {
+				t.Errorf("min availability %v, want %v", req.MinAvailability, tt.wantAvailability)
+			}
+			minRegions := 0
+			if req.MultiRegion != nil {
+				minRegions = req.MultiRegion.MinRegions
+			}
+			if minRegions != tt.wantMinRegions {
+				t.Errorf("min regions %d, want %d", minRegions, tt.wantMinRegions)
+			}
+		})
+	}
+}
+
+func TestPlaceComputeSLATier(t *testing.T) {
+	req := &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, SLATier: SLAGold}
+	d, err := NewEngine(DefaultCatalog()).PlaceCompute(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Allocations) < 2 {
+		t.Errorf("%d regions, want the gold tier's two", len(d.Allocations))
+	}
+	if d.AchievedSLATier != SLAGold && d.AchievedSLATier != SLAPlatinum {
+		t.Errorf("achieved tier %q, want at least gold", d.AchievedSLATier)
+	}
+	if req.MinAvailability != 0 || req.MultiRegion != nil {
+		t.Errorf("requirements changed to %+v, want them left as requested", req)
+	}
+}
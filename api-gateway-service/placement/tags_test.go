@@ -0,0 +1,46 @@
+package placement
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string, MaxTags+1)
+	for i := 0; i <= MaxTags; i++ {
+		tooMany[fmt.Sprintf("tag-%d", i)] = "x"
+	}
+
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "valid", tags: map[string]string{"cost-center": "cc-1", "team": "data eng", "owner": "ops@example.com"}},
+		{name: "empty value", tags: map[string]string{"env": ""}},
+		{name: "too many", tags: tooMany, wantErr: "at most 50 tags"},
+		{name: "empty key", tags: map[string]string{"": "x"}, wantErr: "must be 1 to 128 characters"},
+		{name: "long key", tags: map[string]string{strings.Repeat("k", MaxTagKeyLength+1): "x"}, wantErr: "must be 1 to 128 characters"},
+		{name: "long value", tags: map[string]string{"env": strings.Repeat("v", MaxTagValueLength+1)}, wantErr: "at most 256 characters"},
+		{name: "reserved prefix", tags: map[string]string{"AWS:createdBy": "x"}, wantErr: "reserved prefix"},
+		{name: "invalid key character", tags: map[string]string{"env!": "x"}, wantErr: `tag key "env!" contains`},
+		{name: "invalid value character", tags: map[string]string{"env": "prod;drop"}, wantErr: `value of tag "env" contains`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTags(tt.tags)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateTags() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateTags() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package placement
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tie-break keys, compared in the configured order between options with
+// equal total scores
+const (
+	TieBreakCost         = "cost"
+	TieBreakProvider     = "provider"
+	TieBreakRegion       = "region"
+	TieBreakInstanceType = "instance_type"
+)
+
+// DefaultTieBreak orders tied options by cost, then provider, then region
+var DefaultTieBreak = []string{TieBreakCost, TieBreakProvider, TieBreakRegion}
+
+// allTieBreakKeys lists every key in the order they complete a partial
+// configuration
+var allTieBreakKeys = []string{TieBreakCost, TieBreakProvider, TieBreakRegion, TieBreakInstanceType}
+
+// ParseTieBreak validates a tie-break order. Keys it leaves out are appended
+// in their default order, so options with equal scores are always fully
+// ordered and identical inputs yield identical rankings.
+func ParseTieBreak(keys []string) ([]string, error) {
+	seen := make(map[string]bool, len(allTieBreakKeys))
+	order := make([]string, 0, len(allTieBreakKeys))
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch key {
+		case TieBreakCost, TieBreakProvider, TieBreakRegion, TieBreakInstanceType:
+		default:
+			return nil, fmt.Errorf("invalid tie-break key %q (must be cost, provider, region or instance_type)", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate tie-break key %q", key)
+		}
+		seen[key] = true
+		order = append(order, key)
+	}
+
+	for _, key := range allTieBreakKeys {
+		if !seen[key] {
+			order = append(order, key)
+		}
+	}
+	return order, nil
+}
+
+// lessTied reports whether a ranks before b among options with equal total
+// scores
+func lessTied(a, b Option, order []string) bool {
+	for _, key := range order {
+		switch key {
+		case TieBreakCost:
+			if a.MonthlyCost != b.MonthlyCost {
+				return a.MonthlyCost < b.MonthlyCost
+			}
+		case TieBreakProvider:
+			if a.Provider != b.Provider {
+				return a.Provider < b.Provider
+			}
+		case TieBreakRegion:
+			if a.Region != b.Region {
+				return a.Region < b.Region
+			}
+		case TieBreakInstanceType:
+			if a.InstanceType != b.InstanceType {
+				return a.InstanceType < b.InstanceType
+			}
+		}
+	}
+	return false
+}
+
+// describeTieBreak lists the tie-break keys for selection reasons, e.g.
+// "cost, provider, region and instance type"
+func describeTieBreak(order []string) string {
+	names := make([]string, len(order))
+	for i, key := range order {
+		names[i] = strings.ReplaceAll(key, "_", " ")
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
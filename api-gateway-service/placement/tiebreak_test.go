@@ -0,0 +1,134 @@
+package placement
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTieBreak(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "default order completed", keys: DefaultTieBreak, want: []string{"cost", "provider", "region", "instance_type"}},
+		{name: "empty order", want: []string{"cost", "provider", "region", "instance_type"}},
+		{name: "partial order completed in default order", keys: []string{"region"}, want: []string{"region", "cost", "provider", "instance_type"}},
+		{name: "full custom order", keys: []string{"instance_type", "region", "provider", "cost"}, want: []string{"instance_type", "region", "provider", "cost"}},
+		{name: "keys are trimmed and lowercased", keys: []string{" Provider "}, want: []string{"provider", "cost", "region", "instance_type"}},
+		{name: "unknown key", keys: []string{"latency"}, wantErr: true},
+		{name: "duplicate key", keys: []string{"cost", "COST"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTieBreak(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTieBreak(%v) error = %v, wantErr %v", tt.keys, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTieBreak(%v) = %v, want %v", tt.keys, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLessTied(t *testing.T) {
+	a := Option{Provider: "aws", Region: "us-west-2", InstanceType: "m5.large", MonthlyCost: 120}
+	b := Option{Provider: "gcp", Region: "us-east1", InstanceType: "e2-standard-2", MonthlyCost: 100}
+
+	tests := []struct {
+		order []string
+		// wantALess is whether a ranks before b
+		wantALess bool
+	}{
+		{order: []string{"cost", "provider", "region", "instance_type"}},
+		{order: []string{"provider", "cost", "region", "instance_type"}, wantALess: true},
+		{order: []string{"region", "cost", "provider", "instance_type"}},
+		{order: []string{"instance_type", "cost", "provider", "region"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.order, ","), func(t *testing.T) {
+			if got := lessTied(a, b, tt.order); got != tt.wantALess {
+				t.Errorf("lessTied(a, b) = %v, want %v", got, tt.wantALess)
+			}
+			if got := lessTied(b, a, tt.order); got == tt.wantALess {
+				t.Errorf("lessTied(b, a) = %v, want %v", got, !tt.wantALess)
+			}
+		})
+	}
+
+	if lessTied(a, a, DefaultTieBreak) {
+		t.Error("an option ranks before itself")
+	}
+}
+
+// Options with equal scores are ranked by the tie-break order, whatever
+// order they are given in
+func TestRankTieBreak(t *testing.T) {
+	tied := func(provider, region, instanceType string) Option {
+		return Option{Provider: provider, Region: region, InstanceType: instanceType, MonthlyCost: 100, PerformanceScore: 0.8, ComplianceScore: 1}
+	}
+	options := []Option{
+		tied("gcp", "europe-west1", "n2-standard-2"),
+		tied("aws", "us-east-1", "m5.large"),
+		tied("aws", "eu-west-1", "m6i.large"),
+		tied("azure", "eastus", "D2s_v5"),
+	}
+
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{
+			name:  "default order",
+			order: []string{"cost", "provider", "region", "instance_type"},
+			want:  []string{"aws/eu-west-1", "aws/us-east-1", "azure/eastus", "gcp/europe-west1"},
+		},
+		{
+			name:  "region first",
+			order: []string{"region", "cost", "provider", "instance_type"},
+			want:  []string{"azure/eastus", "aws/eu-west-1", "gcp/europe-west1", "aws/us-east-1"},
+		},
+		{
+			name:  "instance type first",
+			order: []string{"instance_type", "cost", "provider", "region"},
+			want:  []string{"azure/eastus", "aws/us-east-1", "aws/eu-west-1", "gcp/europe-west1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every rotation of the input ranks the same
+			for shift := range options {
+				ranked := append(append([]Option(nil), options[shift:]...), options[:shift]...)
+				Rank(ranked, tt.order)
+
+				got := make([]string, len(ranked))
+				for i, o := range ranked {
+					got[i] = o.Provider + "/" + o.Region
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("input rotated by %d: ranked %v, want %v", shift, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// A higher total score outranks any tie-break key
+func TestRankScoreBeforeTieBreak(t *testing.T) {
+	options := []Option{
+		{Provider: "aws", Region: "us-east-1", MonthlyCost: 100, PerformanceScore: 0.5, ComplianceScore: 1},
+		{Provider: "gcp", Region: "us-east1", MonthlyCost: 100, PerformanceScore: 0.9, ComplianceScore: 1},
+	}
+	Rank(options, DefaultTieBreak)
+
+	if options[0].Provider != "gcp" || options[0].TotalScore <= options[1].TotalScore {
+		t.Errorf("ranked %s (%.2f) before %s (%.2f)", options[0].Provider, options[0].TotalScore, options[1].Provider, options[1].TotalScore)
+	}
+}
@@ -0,0 +1,102 @@
+package placement
+
+import "fmt"
+
+// CostIngress is the cost breakdown key of inbound data transfer; outbound
+// data transfer is itemized under CostEgress
+const CostIngress = "ingress"
+
+// TransferVolume is a monthly data transfer volume in GB by destination
+// class
+type TransferVolume struct {
+	IntraRegionGB float64 `json:"intra_region_gb,omitempty"`
+	InterRegionGB float64 `json:"inter_region_gb,omitempty"`
+	InternetGB    float64 `json:"internet_gb,omitempty"`
+}
+
+// DataTransfer is the expected monthly data transfer of a placement by
+// direction
+type DataTransfer struct {
+	Outbound TransferVolume `json:"outbound"`
+	Inbound  TransferVolume `json:"inbound"`
+}
+
+// TransferRates are per-GB data transfer prices by destination class
+type TransferRates struct {
+	IntraRegionPerGB float64 `json:"intra_region_per_gb"`
+	InterRegionPerGB float64 `json:"inter_region_per_gb"`
+	InternetPerGB    float64 `json:"internet_per_gb"`
+}
+
+// TransferPricing is a provider's data transfer prices by direction, before
+// the region price multiplier
+type TransferPricing struct {
+	Outbound TransferRates `json:"outbound"`
+	Inbound  TransferRates `json:"inbound"`
+}
+
+// TransferCost itemizes the monthly data transfer cost of an option: outbound
+// traffic by destination class and inbound traffic in total
+type TransferCost struct {
+	IntraRegion float64 `json:"intra_region"`
+	InterRegion float64 `json:"inter_region"`
+	Internet    float64 `json:"internet"`
+	Inbound     float64 `json:"inbound"`
+}
+
+// Validate checks that no volume is negative
+func (d *DataTransfer) Validate() error {
+	for direction, v := range map[string]TransferVolume{"outbound": d.Outbound, "inbound": d.Inbound} {
+		if v.IntraRegionGB < 0 || v.InterRegionGB < 0 || v.InternetGB < 0 {
+			return fmt.Errorf("data_transfer.%s volumes must not be negative", direction)
+		}
+	}
+	return nil
+}
+
+// cost prices the volume at the rates scaled by multiplier
+func (v TransferVolume) cost(rates TransferRates, multiplier float64) (intra, inter, internet float64) {
+	return v.IntraRegionGB * rates.IntraRegionPerGB * multiplier,
+		v.InterRegionGB * rates.InterRegionPerGB * multiplier,
+		v.InternetGB * rates.InternetPerGB * multiplier
+}
+
+// applyDataTransfer prices the expected data transfer into the options'
+// costs at each provider's rates. Options are unchanged when transfer is nil.
+func (e *Engine) applyDataTransfer(options []Option, transfer *DataTransfer) {
+	if transfer == nil {
+		return
+	}
+
+	for i := range options {
+		o := &options[i]
+		p, err := e.catalog.Provider(o.Provider)
+		if err != nil {
+			continue
+		}
+		r, err := p.Region(o.Region)
+		if err != nil {
+			continue
+		}
+
+		tc := &TransferCost{}
+		tc.IntraRegion, tc.InterRegion, tc.Internet = transfer.Outbound.cost(p.DataTransfer.Outbound, r.PriceMultiplier)
+		inIntra, inInter, inInternet := transfer.Inbound.cost(p.DataTransfer.Inbound, r.PriceMultiplier)
+		tc.Inbound = inIntra + inInter + inInternet
+		if e.customPricing(p.Name) {
+			factor := e.priceFactor(p.Name)
+			tc.IntraRegion *= factor
+			tc.InterRegion *= factor
+			tc.Internet *= factor
+			tc.Inbound *= factor
+		}
+
+		o.DataTransferCost = tc
+		o.CostBreakdown = copyBreakdown(o.CostBreakdown)
+		o.CostBreakdown[CostEgress] += tc.IntraRegion + tc.InterRegion + tc.Internet
+		if tc.Inbound > 0 {
+			o.CostBreakdown[CostIngress] += tc.Inbound
+		}
+		o.MonthlyCost = SumCosts(o.CostBreakdown)
+	}
+}
@@ -0,0 +1,96 @@
+package placement
+
+import (
+	"math"
+	"testing"
+)
+
+// Outbound traffic is priced per destination class at the provider's rates
+// and the region's price multiplier, and counted in the monthly cost
+func TestApplyDataTransfer(t *testing.T) {
+	transfer := &DataTransfer{
+		Outbound: TransferVolume{IntraRegionGB: 1000, InterRegionGB: 500, InternetGB: 200},
+		Inbound:  TransferVolume{InternetGB: 5000},
+	}
+	tests := []struct {
+		name                           string
+		engine                         *Engine
+		provider, region               string
+		intra, inter, internet, egress float64
+	}{
+		// aws: 0.01, 0.02 and 0.09 per GB at a multiplier of 1.0
+		{name: "aws us-east-1", engine: NewEngine(DefaultCatalog()), provider: "aws", region: "us-east-1", intra: 10, inter: 10, internet: 18, egress: 38},
+		// and of 1.1 in eu-west-1
+		{name: "aws eu-west-1", engine: NewEngine(DefaultCatalog()), provider: "aws", region: "eu-west-1", intra: 11, inter: 11, internet: 19.8, egress: 41.8},
+		// azure has free intra-region transfer
+		{name: "azure eastus", engine: NewEngine(DefaultCatalog()), provider: "azure", region: "eastus", intra: 0, inter: 10, internet: 17.4, egress: 27.4},
+		{
+			name:     "discounted",
+			engine:   NewEngine(DefaultCatalog()).WithPricing(Pricing{"aws": {DiscountPercent: 50}}),
+			provider: "aws", region: "us-east-1", intra: 5, inter: 5, internet: 9, egress: 19,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := []Option{{Provider: tt.provider, Region: tt.region, CostBreakdown: map[string]float64{CostCompute: 100}, MonthlyCost: 100}}
+			tt.engine.applyDataTransfer(options, transfer)
+
+			o := options[0]
+			tc := o.DataTransferCost
+			if tc == nil {
+				t.Fatal("no data transfer cost")
+			}
+			for _, c := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"intra-region", tc.IntraRegion, tt.intra},
+				{"inter-region", tc.InterRegion, tt.inter},
+				{"internet", tc.Internet, tt.internet},
+				{"inbound", tc.Inbound, 0},
+				{"egress breakdown", o.CostBreakdown[CostEgress], tt.egress},
+				{"monthly cost", o.MonthlyCost, 100 + tt.egress},
+			} {
+				if math.Abs(c.got-c.want) > 1e-9 {
+					t.Errorf("%s cost %v, want %v", c.name, c.got, c.want)
+				}
+			}
+			if _, ok := o.CostBreakdown[CostIngress]; ok {
+				t.Errorf("free inbound traffic itemized: %v", o.CostBreakdown)
+			}
+		})
+	}
+}
+
+func TestApplyDataTransferNil(t *testing.T) {
+	options := []Option{{Provider: "aws", Region: "us-east-1", CostBreakdown: map[string]float64{CostCompute: 100}, MonthlyCost: 100}}
+	NewEngine(DefaultCatalog()).applyDataTransfer(options, nil)
+	if options[0].DataTransferCost != nil || options[0].MonthlyCost != 100 || len(options[0].CostBreakdown) != 1 {
+		t.Errorf("option %+v changed without a data transfer", options[0])
+	}
+}
+
+// The selected option's estimated cost includes its data transfer
+func TestPlaceComputeDataTransfer(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+	req := &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: []string{"us-east-1"}}
+	base, err := e.PlaceCompute(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.DataTransfer = &DataTransfer{Outbound: TransferVolume{InternetGB: 1000}}
+	d, err := e.PlaceCompute(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Selected.DataTransferCost == nil || math.Abs(d.Selected.MonthlyCost-(base.Selected.MonthlyCost+90)) > 1e-9 {
+		t.Errorf("monthly cost %v with transfer %+v, want %v plus 90 of internet egress", d.Selected.MonthlyCost, d.Selected.DataTransferCost, base.Selected.MonthlyCost)
+	}
+
+	req.DataTransfer = &DataTransfer{Inbound: TransferVolume{InterRegionGB: -1}}
+	if _, err := e.PlaceCompute(req); err == nil {
+		t.Error("negative data transfer accepted")
+	}
+}
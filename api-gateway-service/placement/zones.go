@@ -0,0 +1,174 @@
+package placement
+
+import "fmt"
+
+// ZoneRequirements pins a placement to availability zones of its selected
+// region. Zones are named as in the catalog, e.g. us-east-1a.
+type ZoneRequirements struct {
+	// Required zones are always selected, so they must all be in one region,
+	// to which the placement is then confined
+	Required []string `json:"required,omitempty"`
+	// Preferred zones are selected before the region's other zones; those
+	// outside the selected region are ignored
+	Preferred []string `json:"preferred,omitempty"`
+	// Spread is the number of distinct zones to spread the placement across.
+	// It defaults to the number of required zones, or 1 when none are.
+	Spread int `json:"spread,omitempty"`
+}
+
+// Validate checks the spread and that no zone is listed twice
+func (z *ZoneRequirements) Validate() error {
+	if z.Spread < 0 {
+		return fmt.Errorf("availability_zones.spread must not be negative")
+	}
+	if z.Spread > 0 && z.Spread < len(z.Required) {
+		return fmt.Errorf("availability_zones.spread is %d but %d zones are required", z.Spread, len(z.Required))
+	}
+	for field, zones := range map[string][]string{"required": z.Required, "preferred": z.Preferred} {
+		seen := make(map[string]bool, len(zones))
+		for _, zone := range zones {
+			if seen[zone] {
+				return fmt.Errorf("availability_zones.%s lists zone %s more than once", field, zone)
+			}
+			seen[zone] = true
+		}
+	}
+	return nil
+}
+
+// count returns the number of zones to select
+func (z *ZoneRequirements) count() int {
+	if z.Spread > 0 {
+		return z.Spread
+	}
+	if len(z.Required) > 0 {
+		return len(z.Required)
+	}
+	return 1
+}
+
+// zoneRegion returns the catalog region the zone belongs to
+func (e *Engine) zoneRegion(zone string) (*Region, error) {
+	for _, p := range e.catalog.Providers {
+		for i := range p.Regions {
+			for _, z := range p.Regions[i].Zones {
+				if z == zone {
+					return &p.Regions[i], nil
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown availability zone %s", zone)
+}
+
+// checkZones checks that every zone of the requirements is in the catalog
+// and that the required zones are in a single region the requirements allow
+func (e *Engine) checkZones(req *ComputeRequirements) error {
+	z := req.AvailabilityZones
+	if z == nil {
+		return nil
+	}
+
+	for _, zone := range z.Preferred {
+		if _, err := e.zoneRegion(zone); err != nil {
+			return err
+		}
+	}
+
+	var pinned *Region
+	for _, zone := range z.Required {
+		r, err := e.zoneRegion(zone)
+		if err != nil {
+			return err
+		}
+		if pinned != nil && (r.Provider != pinned.Provider || r.Name != pinned.Name) {
+			return fmt.Errorf("required availability zones %s and %s are in different regions", z.Required[0], zone)
+		}
+		pinned = r
+	}
+	if pinned == nil {
+		return nil
+	}
+
+	if len(req.Regions) > 0 && !contains(req.Regions, pinned.Name) {
+		return fmt.Errorf("required availability zone %s is in region %s, which is not in the allowed regions", z.Required[0], pinned.Name)
+	}
+	if contains(req.ExcludedRegions, pinned.Name) || contains(req.ExcludedProviders, pinned.Provider) {
+		return fmt.Errorf("required availability zone %s is in region %s, which is excluded", z.Required[0], pinned.Name)
+	}
+	if len(req.DataResidency) > 0 && !inJurisdiction(pinned.Jurisdiction, req.DataResidency) {
+		return fmt.Errorf("required availability zone %s is in region %s, which is outside the data residency jurisdictions", z.Required[0], pinned.Name)
+	}
+	if z.count() > len(pinned.Zones) {
+		return fmt.Errorf("availability_zones.spread is %d but region %s has only %d zones", z.count(), pinned.Name, len(pinned.Zones))
+	}
+	return nil
+}
+
+// applyZones selects the zones of each option's region that satisfy the zone
+// requirements and drops the options whose region cannot. Options are
+// unchanged when z is nil.
+func (e *Engine) applyZones(options []Option, z *ZoneRequirements) []Option {
+	if z == nil {
+		return options
+	}
+
+	var zoned []Option
+	for _, o := range options {
+		p, err := e.catalog.Provider(o.Provider)
+		if err != nil {
+			continue
+		}
+		r, err := p.Region(o.Region)
+		if err != nil {
+			continue
+		}
+		zones := selectZones(r.Zones, z)
+		if zones == nil {
+			continue
+		}
+		o.Zones = zones
+		zoned = append(zoned, o)
+	}
+	return zoned
+}
+
+// selectZones picks the zones of a region for the requirements: the required
+// zones, then preferred zones, then the region's other zones in catalog
+// order. It returns nil if a required zone is not in the region or the region
+// has too few zones.
+func selectZones(regionZones []string, z *ZoneRequirements) []string {
+	n := z.count()
+	if len(regionZones) < n {
+		return nil
+	}
+
+	selected := make([]string, 0, n)
+	for _, zone := range z.Required {
+		if !contains(regionZones, zone) {
+			return nil
+		}
+		selected = append(selected, zone)
+	}
+	for _, candidates := range [][]string{z.Preferred, regionZones} {
+		for _, zone := range candidates {
+			if len(selected) == n {
+				return selected
+			}
+			if contains(regionZones, zone) && !contains(selected, zone) {
+				selected = append(selected, zone)
+			}
+		}
+	}
+	return selected
+}
+
+// contains reports whether s is in list
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package placement
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestZoneRequirementsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		zones   ZoneRequirements
+		wantErr string
+	}{
+		{name: "required", zones: ZoneRequirements{Required: []string{"us-east-1a", "us-east-1b"}}},
+		{name: "spread", zones: ZoneRequirements{Required: []string{"us-east-1a"}, Spread: 2}},
+		{name: "negative spread", zones: ZoneRequirements{Spread: -1}, wantErr: "must not be negative"},
+		{name: "spread below required", zones: ZoneRequirements{Required: []string{"us-east-1a", "us-east-1b"}, Spread: 1}, wantErr: "2 zones are required"},
+		{name: "repeated zone", zones: ZoneRequirements{Preferred: []string{"us-east-1a", "us-east-1a"}}, wantErr: "preferred lists zone us-east-1a more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.zones.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := &ComputeRequirements{
+		Name: "web", VCPUs: 2, MemoryGB: 8,
+		MultiRegion:       &MultiRegion{MinRegions: 2},
+		AvailabilityZones: &ZoneRequirements{Required: []string{"us-east-1a"}},
+	}
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "multi_region") {
+		t.Errorf("Validate() = %v, want required zones refused with multi_region", err)
+	}
+}
+
+func TestSelectZones(t *testing.T) {
+	region := []string{"us-central1-a", "us-central1-b", "us-central1-c", "us-central1-f"}
+
+	tests := []struct {
+		name  string
+		zones ZoneRequirements
+		want  []string
+	}{
+		{name: "default", zones: ZoneRequirements{}, want: []string{"us-central1-a"}},
+		{name: "spread", zones: ZoneRequirements{Spread: 3}, want: []string{"us-central1-a", "us-central1-b", "us-central1-c"}},
+		{name: "required", zones: ZoneRequirements{Required: []string{"us-central1-f", "us-central1-b"}}, want: []string{"us-central1-f", "us-central1-b"}},
+		{
+			name:  "preferred after required",
+			zones: ZoneRequirements{Required: []string{"us-central1-c"}, Preferred: []string{"us-east-1a", "us-central1-f"}, Spread: 3},
+			want:  []string{"us-central1-c", "us-central1-f", "us-central1-a"},
+		},
+		{name: "required zone elsewhere", zones: ZoneRequirements{Required: []string{"us-east-1a"}}},
+		{name: "too few zones", zones: ZoneRequirements{Spread: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectZones(region, &tt.zones); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectZones() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceComputeZones(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+
+	tests := []struct {
+		name       string
+		regions    []string
+		zones      ZoneRequirements
+		wantRegion string
+		wantZones  []string
+	}{
+		{name: "required", zones: ZoneRequirements{Required: []string{"eu-west-1b"}}, wantRegion: "eu-west-1", wantZones: []string{"eu-west-1b"}},
+		{name: "required spread", zones: ZoneRequirements{Required: []string{"eu-west-1c"}, Spread: 2}, wantRegion: "eu-west-1", wantZones: []string{"eu-west-1c", "eu-west-1a"}},
+		{
+			name:       "preferred",
+			regions:    []string{"us-central1"},
+			zones:      ZoneRequirements{Preferred: []string{"us-central1-f"}, Spread: 2},
+			wantRegion: "us-central1",
+			wantZones:  []string{"us-central1-f", "us-central1-a"},
+		},
+		{name: "spread beyond three zones", zones: ZoneRequirements{Spread: 4}, wantRegion: "us-central1", wantZones: []string{"us-central1-a", "us-central1-b", "us-central1-c", "us-central1-f"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zones := tt.zones
+			d, err := e.PlaceCompute(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: tt.regions, AvailabilityZones: &zones})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Selected.Region != tt.wantRegion || !reflect.DeepEqual(d.Selected.Zones, tt.wantZones) {
+				t.Errorf("selected %s %v, want %s %v", d.Selected.Region, d.Selected.Zones, tt.wantRegion, tt.wantZones)
+			}
+			for _, o := range d.Alternatives {
+				if len(o.Zones) != len(tt.wantZones) {
+					t.Errorf("alternative %s %s zones %v, want %d", o.Provider, o.Region, o.Zones, len(tt.wantZones))
+				}
+				r, err := e.zoneRegion(o.Zones[0])
+				if err != nil || r.Name != o.Region {
+					t.Errorf("alternative %s zones %v are not in its region", o.Region, o.Zones)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceComputeZonesInvalid(t *testing.T) {
+	e := NewEngine(DefaultCatalog())
+
+	tests := []struct {
+		name    string
+		req     ComputeRequirements
+		wantErr string
+	}{
+		{
+			name:    "unknown zone",
+			req:     ComputeRequirements{AvailabilityZones: &ZoneRequirements{Preferred: []string{"us-east-1z"}}},
+			wantErr: "unknown availability zone us-east-1z",
+		},
+		{
+			name:    "required zones in two regions",
+			req:     ComputeRequirements{AvailabilityZones: &ZoneRequirements{Required: []string{"us-east-1a", "us-west-2a"}}},
+			wantErr: "are in different regions",
+		},
+		{
+			name:    "zone outside the allowed regions",
+			req:     ComputeRequirements{Regions: []string{"us-east-1"}, AvailabilityZones: &ZoneRequirements{Required: []string{"eu-west-1a"}}},
+			wantErr: "is in region eu-west-1, which is not in the allowed regions",
+		},
+		{
+			name:    "zone in an excluded region",
+			req:     ComputeRequirements{ExcludedRegions: []string{"eu-west-1"}, AvailabilityZones: &ZoneRequirements{Required: []string{"eu-west-1a"}}},
+			wantErr: "which is excluded",
+		},
+		{
+			name:    "spread beyond the pinned region",
+			req:     ComputeRequirements{AvailabilityZones: &ZoneRequirements{Required: []string{"eu-west-1a"}, Spread: 4}},
+			wantErr: "region eu-west-1 has only 3 zones",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.Name, req.VCPUs, req.MemoryGB = "web", 2, 8
+			_, err := e.PlaceCompute(&req)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("PlaceCompute() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
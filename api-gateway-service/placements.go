@@ -0,0 +1,742 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/placement"
+	"api-gateway-service/signing"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+var (
+	placementStore  = store.NewPlacementStore()
+	resourceStore   = store.NewResourceStore()
+	placementEngine = placement.NewEngine(placement.DefaultCatalog())
+
+	// placementSigner signs placement results when placements.signing.key_file
+	// is set
+	placementSigner *signing.Signer
+
+	// pricingOverrides are the tenants' negotiated prices, loaded from
+	// placements.pricing_overrides_file
+	pricingOverrides placement.PricingOverrides
+)
+
+// tenantEngine returns the placement engine estimating costs at the caller's
+// tenant's negotiated prices, or at list prices when it has none
+func tenantEngine(ctx context.Context) *placement.Engine {
+	if pricing, ok := pricingOverrides[tenant.FromContext(ctx)]; ok {
+		return placementEngine.WithPricing(pricing)
+	}
+	return placementEngine
+}
+
+// PlacementGroupRequest is the body accepted by the placement group endpoint
+type PlacementGroupRequest struct {
+	Placements []placement.ComputeRequirements `json:"placements" binding:"required,min=1"`
+}
+
+// AdoptRequest is the body accepted by the placement adoption endpoint
+type AdoptRequest struct {
+	ProviderResourceID string `json:"provider_resource_id" binding:"required"`
+	ResourceGroup      string `json:"resource_group,omitempty"`
+}
+
+// ResourceGroupCost is the combined estimated cost of a resource group's
+// placements
+type ResourceGroupCost struct {
+	ResourceGroup        string  `json:"resource_group"`
+	PlacementCount       int     `json:"placement_count"`
+	EstimatedMonthlyCost float64 `json:"estimated_monthly_cost"`
+}
+
+// PlacementListing is the response of the placement listing across types.
+// Placements holds signed placements when a signing key is configured.
+type PlacementListing struct {
+	Placements       interface{}         `json:"placements"`
+	Groups           []ResourceGroupCost `json:"groups"`
+	TotalMonthlyCost float64             `json:"total_monthly_cost"`
+}
+
+func listPlacements(c *gin.Context) {
+	deleted, err := strconv.ParseBool(c.DefaultQuery("deleted", "false"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid deleted: must be true or false")
+		return
+	}
+
+	respondPlacements(c, http.StatusOK, placementStore.List(c.Request.Context(), c.Param("type"), c.Query("group"), deleted))
+}
+
+// listAllPlacements lists placements of every type, optionally of a single
+// resource group, with their estimated monthly cost totalled per group
+func listAllPlacements(c *gin.Context) {
+	deleted, err := strconv.ParseBool(c.DefaultQuery("deleted", "false"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid deleted: must be true or false")
+		return
+	}
+
+	placements := placementStore.List(c.Request.Context(), "", c.Query("group"), deleted)
+	signed, err := signPlacements(placements)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	listing := PlacementListing{Placements: signed, Groups: groupCosts(placements)}
+	for _, g := range listing.Groups {
+		listing.TotalMonthlyCost += g.EstimatedMonthlyCost
+	}
+	c.JSON(http.StatusOK, listing)
+}
+
+// groupCosts totals the placements' estimated monthly cost by resource group,
+// ordered by group name. Placements without a group are totalled under an
+// empty name. Multi-region placements count their aggregate cost.
+func groupCosts(placements []*store.Placement) []ResourceGroupCost {
+	byGroup := make(map[string]*ResourceGroupCost)
+	for _, p := range placements {
+		g, ok := byGroup[p.ResourceGroup]
+		if !ok {
+			g = &ResourceGroupCost{ResourceGroup: p.ResourceGroup}
+			byGroup[p.ResourceGroup] = g
+		}
+		g.PlacementCount++
+		if p.AggregateMonthlyCost > 0 {
+			g.EstimatedMonthlyCost += p.AggregateMonthlyCost
+		} else {
+			g.EstimatedMonthlyCost += p.EstimatedMonthlyCost
+		}
+	}
+
+	groups := make([]ResourceGroupCost, 0, len(byGroup))
+	for _, g := range byGroup {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ResourceGroup < groups[j].ResourceGroup
+	})
+	return groups
+}
+
+func getPlacement(c *gin.Context) {
+	p, err := placementStore.Get(c.Request.Context(), c.Param("type"), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "placement not found")
+		return
+	}
+
+	respondPlacement(c, http.StatusOK, p)
+}
+
+// deletePlacement removes a placement, or with ?soft=true marks it deleted so
+// it can be restored until the retention period passes
+func deletePlacement(c *gin.Context) {
+	soft, err := strconv.ParseBool(c.DefaultQuery("soft", "false"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid soft: must be true or false")
+		return
+	}
+
+	ctx := c.Request.Context()
+	remove := placementStore.Delete
+	if soft {
+		remove = placementStore.SoftDelete
+	}
+	// Soft-deleted placements were reported when first deleted, and are not
+	// found here
+	existing, getErr := placementStore.Get(ctx, c.Param("type"), c.Param("id"))
+	if err := remove(ctx, c.Param("type"), c.Param("id")); err != nil {
+		respondStoreError(c, err, "placement not found")
+		return
+	}
+	if getErr == nil {
+		notifyPlacementEvent(ctx, eventPlacementDeleted, existing)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func restorePlacement(c *gin.Context) {
+	cutoff := time.Now().UTC().Add(-viper.GetDuration("placements.soft_delete_retention"))
+	p, err := placementStore.Restore(c.Request.Context(), c.Param("type"), c.Param("id"), cutoff)
+	if err != nil {
+		respondStoreError(c, err, "deleted placement not found or past retention")
+		return
+	}
+	notifyPlacementEvent(c.Request.Context(), eventPlacementRestored, p)
+
+	respondPlacement(c, http.StatusOK, p)
+}
+
+// runPlacementPurge permanently removes soft-deleted placements once they are
+// past the retention period, checking every interval until ctx is done
+func runPlacementPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			cutoff := now.UTC().Add(-viper.GetDuration("placements.soft_delete_retention"))
+			if n := placementStore.PurgeDeleted(cutoff); n > 0 {
+				log.Printf("Purged %d soft-deleted placements", n)
+			}
+		}
+	}
+}
+
+func createPlacement(c *gin.Context) {
+	p, ok := placeCompute(c)
+	if !ok {
+		return
+	}
+
+	p.ID = newID("plc")
+	if err := placementStore.Save(c.Request.Context(), p); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	notifyPlacementEvent(c.Request.Context(), eventPlacementCreated, p)
+
+	respondPlacement(c, http.StatusCreated, p)
+}
+
+func updatePlacement(c *gin.Context) {
+	ctx := c.Request.Context()
+	existing, err := placementStore.Get(ctx, c.Param("type"), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "placement not found")
+		return
+	}
+
+	p, ok := placeCompute(c)
+	if !ok {
+		return
+	}
+
+	p.ID = existing.ID
+	p.CreatedAt = existing.CreatedAt
+	if err := placementStore.Save(ctx, p); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	notifyPlacementEvent(ctx, eventPlacementUpdated, p)
+
+	respondPlacement(c, http.StatusOK, p)
+}
+
+func getPlacementHistory(c *gin.Context) {
+	versions, err := placementStore.History(c.Request.Context(), c.Param("type"), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "placement not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// placeCompute binds compute requirements from the request and runs them
+// through the placement engine. It writes the error response and returns
+// false when the request cannot be placed.
+func placeCompute(c *gin.Context) (*store.Placement, bool) {
+	resourceType := c.Param("type")
+	if resourceType != "compute" {
+		respondError(c, http.StatusBadRequest, "placement is only supported for compute resources")
+		return nil, false
+	}
+
+	var req placement.ComputeRequirements
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return nil, false
+	}
+	if err := validateComputeRequirements(&req); err != nil {
+		respondValidationError(c, err)
+		return nil, false
+	}
+
+	peers, err := resolveAffinity(c.Request.Context(), req.Affinity, c.Param("id"), nil)
+	if err != nil {
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+
+	p, err := placeComputeNear(c.Request.Context(), &req, peers)
+	if err != nil {
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+	return p, true
+}
+
+// computeFrontier returns the Pareto frontier across cost and performance of
+// the compute requirements without saving a placement. The name is optional
+// since nothing is stored.
+func computeFrontier(c *gin.Context) {
+	if c.Param("type") != "compute" {
+		respondError(c, http.StatusBadRequest, "frontier is only supported for compute resources")
+		return
+	}
+
+	var req placement.ComputeRequirements
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if req.Name == "" {
+		req.Name = "frontier"
+	}
+	if err := validateComputeRequirements(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	peers, err := resolveAffinity(c.Request.Context(), req.Affinity, "", nil)
+	if err != nil {
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	frontier, err := tenantEngine(c.Request.Context()).ComputeFrontier(&req, peers)
+	if err != nil {
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"frontier": frontier})
+}
+
+// validateComputeRequirements checks the requirements and their compliance
+// frameworks
+func validateComputeRequirements(req *placement.ComputeRequirements) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	return complianceCatalog.Validate(req.ComplianceFrameworks)
+}
+
+// resolveAffinity returns the peers the requirements' affinity refers to.
+// Placement IDs are looked up in the caller's tenant and must not be selfID,
+// the placement being updated; names refer to placements of the same group.
+func resolveAffinity(ctx context.Context, affinity []placement.Affinity, selfID string, group map[string]*store.Placement) ([]placement.Peer, error) {
+	peers := make([]placement.Peer, 0, len(affinity))
+	for _, a := range affinity {
+		var related *store.Placement
+		ref := a.PlacementID
+		switch {
+		case a.PlacementID != "":
+			if a.PlacementID == selfID {
+				return nil, fmt.Errorf("affinity placement %q refers to the placement itself", a.PlacementID)
+			}
+			p, err := placementStore.Get(ctx, "compute", a.PlacementID)
+			if err != nil {
+				return nil, fmt.Errorf("affinity placement %q not found", a.PlacementID)
+			}
+			related = p
+		default:
+			ref = a.Name
+			p, ok := group[a.Name]
+			if !ok {
+				return nil, fmt.Errorf("affinity name %q is not a member of the placement group", a.Name)
+			}
+			related = p
+		}
+
+		peers = append(peers, placement.Peer{
+			Ref:              ref,
+			Provider:         related.SelectedProvider,
+			Region:           related.SelectedRegion,
+			MonthlyTrafficGB: a.MonthlyTrafficGB,
+		})
+	}
+	return peers, nil
+}
+
+// placeComputeNear runs validated requirements through the tenant's placement
+// engine and builds the placement from its decision
+func placeComputeNear(ctx context.Context, req *placement.ComputeRequirements, peers []placement.Peer) (*store.Placement, error) {
+	decision, err := tenantEngine(ctx).PlaceComputeNear(req, peers)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &store.Placement{
+		ResourceType:         "compute",
+		Requirements:         requirementsMap(req),
+		SelectedProvider:     decision.Selected.Provider,
+		SelectedRegion:       decision.Selected.Region,
+		InstanceType:         decision.Selected.InstanceType,
+		EstimatedMonthlyCost: decision.Selected.MonthlyCost,
+		CostBreakdown:        decision.Selected.CostBreakdown,
+		PerformanceScore:     decision.Selected.PerformanceScore,
+		ComplianceScore:      decision.Selected.ComplianceScore,
+		TotalScore:           decision.Selected.TotalScore,
+		SelectionReason:      decision.SelectionReason,
+		Recommendations:      toAlternatives(decision.Alternatives),
+		RegionAllocations:    toRegionAllocations(decision.Allocations),
+		AggregateMonthlyCost: decision.AggregateMonthlyCost,
+		AchievedSLATier:      string(decision.AchievedSLATier),
+		PriceListDate:        decision.PriceListDate,
+		PricesStale:          decision.PricesStale,
+		DataTransferCost:     toTransferCost(decision.Selected.DataTransferCost),
+		SelectedZones:        decision.Selected.Zones,
+		NormalizedScores:     toNormalizedScores(decision.Selected.NormalizedScores),
+		SelectedJurisdiction: decision.Selected.Jurisdiction,
+		Tags:                 req.Tags,
+		Metadata:             req.Metadata,
+		Affinity:             toAffinityLinks(decision.Selected.Affinity),
+		ResourceGroup:        req.ResourceGroup,
+		CustomPricing:        decision.CustomPricing,
+	}
+	if s := decision.Savings; s != nil {
+		p.CurrentMonthlyCost = &s.CurrentMonthlyCost
+		p.EstimatedSavings = &s.EstimatedSavings
+		p.SavingsPercent = s.SavingsPercent
+		p.CostIncrease = s.CostIncrease
+	}
+	return p, nil
+}
+
+// createPlacementGroup places a group of related compute requirements together.
+// Members may reference each other by name in their affinity and are placed
+// after the members they reference. The group is saved only if every member
+// can be placed.
+func createPlacementGroup(c *gin.Context) {
+	if c.Param("type") != "compute" {
+		respondError(c, http.StatusBadRequest, "placement is only supported for compute resources")
+		return
+	}
+
+	var req PlacementGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	byName := make(map[string]int, len(req.Placements))
+	for i := range req.Placements {
+		member := &req.Placements[i]
+		if err := validateComputeRequirements(member); err != nil {
+			respondValidationError(c, fmt.Errorf("placement %q: %v", member.Name, err))
+			return
+		}
+		if _, exists := byName[member.Name]; exists {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("duplicate placement name %q in group", member.Name))
+			return
+		}
+		byName[member.Name] = i
+	}
+
+	order, err := groupPlacementOrder(req.Placements, byName)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	placed := make(map[string]*store.Placement, len(order))
+	for _, i := range order {
+		member := &req.Placements[i]
+		peers, err := resolveAffinity(ctx, member.Affinity, "", placed)
+		if err == nil {
+			placed[member.Name], err = placeComputeNear(ctx, member, peers)
+		}
+		if err != nil {
+			respondError(c, http.StatusUnprocessableEntity, fmt.Sprintf("placement %q: %v", member.Name, err))
+			return
+		}
+	}
+
+	result := make([]*store.Placement, len(req.Placements))
+	for i, member := range req.Placements {
+		p := placed[member.Name]
+		p.ID = newID("plc")
+		if err := placementStore.Save(ctx, p); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		notifyPlacementEvent(ctx, eventPlacementCreated, p)
+		result[i] = p
+	}
+
+	respondPlacements(c, http.StatusCreated, result)
+}
+
+// groupPlacementOrder returns the indexes of the group members ordered so each
+// comes after the members its affinity names, failing on unknown names and
+// cycles
+func groupPlacementOrder(members []placement.ComputeRequirements, byName map[string]int) ([]int, error) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(members))
+	order := make([]int, 0, len(members))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("affinity cycle in placement group at %q", members[i].Name)
+		case done:
+			return nil
+		}
+		state[i] = visiting
+		for _, a := range members[i].Affinity {
+			if a.Name == "" {
+				continue
+			}
+			j, ok := byName[a.Name]
+			if !ok {
+				return fmt.Errorf("placement %q: affinity name %q is not a member of the group", members[i].Name, a.Name)
+			}
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		order = append(order, i)
+		return nil
+	}
+
+	for i := range members {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// adoptPlacement registers an existing cloud resource of the inventory as a
+// managed placement, scoring its current deployment against the alternatives
+func adoptPlacement(c *gin.Context) {
+	var req AdoptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	resourceType := c.Param("type")
+	if resourceType != "compute" {
+		respondError(c, http.StatusBadRequest, "adoption is only supported for compute resources")
+		return
+	}
+
+	ctx := c.Request.Context()
+	resource, err := resourceStore.Get(ctx, req.ProviderResourceID)
+	if err == nil && resource.Type != resourceType {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		respondStoreError(c, err, "resource not found in inventory; import it at /resources/import first")
+		return
+	}
+
+	eval, err := tenantEngine(c.Request.Context()).Evaluate(resource.Provider, resource.Region, resource.InstanceType)
+	if err != nil {
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	p := &store.Placement{
+		ID:            newID("plc"),
+		ResourceType:  resourceType,
+		ResourceGroup: req.ResourceGroup,
+		Requirements: map[string]interface{}{
+			"name":                 resource.Name,
+			"regions":              []string{resource.Region},
+			"provider_resource_id": resource.ID,
+		},
+		SelectedProvider:     eval.Current.Provider,
+		SelectedRegion:       eval.Current.Region,
+		InstanceType:         eval.Current.InstanceType,
+		EstimatedMonthlyCost: eval.Current.MonthlyCost,
+		CostBreakdown:        eval.Current.CostBreakdown,
+		PerformanceScore:     eval.Current.PerformanceScore,
+		ComplianceScore:      eval.Current.ComplianceScore,
+		TotalScore:           eval.Current.TotalScore,
+		NormalizedScores:     toNormalizedScores(eval.Current.NormalizedScores),
+		Recommendations:      toAlternatives(eval.Alternatives),
+	}
+	if err := placementStore.Save(ctx, p); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	notifyPlacementEvent(ctx, eventPlacementCreated, p)
+
+	respondPlacement(c, http.StatusCreated, p)
+}
+
+func toAlternatives(options []placement.Option) []store.Alternative {
+	alternatives := make([]store.Alternative, len(options))
+	for i, o := range options {
+		alternatives[i] = store.Alternative{
+			Provider:         o.Provider,
+			Region:           o.Region,
+			InstanceType:     o.InstanceType,
+			MonthlyCost:      o.MonthlyCost,
+			PerformanceScore: o.PerformanceScore,
+			ComplianceScore:  o.ComplianceScore,
+			TotalScore:       o.TotalScore,
+			RejectionReason:  o.RejectionReason,
+			Zones:            o.Zones,
+			NormalizedScores: toNormalizedScores(o.NormalizedScores),
+			Jurisdiction:     o.Jurisdiction,
+		}
+	}
+	return alternatives
+}
+
+func toNormalizedScores(ns *placement.NormalizedScores) *store.NormalizedScores {
+	if ns == nil {
+		return nil
+	}
+	return &store.NormalizedScores{
+		Performance: ns.Performance,
+		Compliance:  ns.Compliance,
+	}
+}
+
+func toAffinityLinks(links []placement.AffinityLink) []store.AffinityLink {
+	if len(links) == 0 {
+		return nil
+	}
+
+	result := make([]store.AffinityLink, len(links))
+	for i, l := range links {
+		result[i] = store.AffinityLink{
+			Ref:               l.Ref,
+			Provider:          l.Provider,
+			Region:            l.Region,
+			LatencyMs:         l.LatencyMs,
+			EgressMonthlyCost: l.EgressMonthlyCost,
+		}
+	}
+	return result
+}
+
+func toTransferCost(tc *placement.TransferCost) *store.TransferCost {
+	if tc == nil {
+		return nil
+	}
+	return &store.TransferCost{
+		IntraRegion: tc.IntraRegion,
+		InterRegion: tc.InterRegion,
+		Internet:    tc.Internet,
+		Inbound:     tc.Inbound,
+	}
+}
+
+func toRegionAllocations(allocations []placement.RegionAllocation) []store.RegionAllocation {
+	if len(allocations) == 0 {
+		return nil
+	}
+
+	result := make([]store.RegionAllocation, len(allocations))
+	for i, a := range allocations {
+		result[i] = store.RegionAllocation{
+			Provider:     a.Provider,
+			Region:       a.Region,
+			InstanceType: a.InstanceType,
+			Weight:       a.Weight,
+			MonthlyCost:  a.MonthlyCost,
+			Zones:        a.Zones,
+		}
+	}
+	return result
+}
+
+// requirementsMap returns the requirements as stored with the placement
+func requirementsMap(req interface{}) map[string]interface{} {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// newID returns a random identifier with the given prefix
+func newID(prefix string) string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return prefix + "-" + hex.EncodeToString(b)
+}
+
+// respondPlacement writes a placement result, signed when a signing key is
+// configured
+func respondPlacement(c *gin.Context, status int, p *store.Placement) {
+	if placementSigner == nil {
+		c.JSON(status, p)
+		return
+	}
+
+	signed, err := placementSigner.Sign(p)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(status, signed)
+}
+
+// respondPlacements writes placement results, each signed separately when a
+// signing key is configured
+func respondPlacements(c *gin.Context, status int, placements []*store.Placement) {
+	signed, err := signPlacements(placements)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(status, signed)
+}
+
+// signPlacements returns the placements, each signed separately when a signing
+// key is configured
+func signPlacements(placements []*store.Placement) (interface{}, error) {
+	if placementSigner == nil {
+		return placements, nil
+	}
+
+	signed := make([]map[string]interface{}, len(placements))
+	for i, p := range placements {
+		var err error
+		if signed[i], err = placementSigner.Sign(p); err != nil {
+			return nil, err
+		}
+	}
+	return signed, nil
+}
+
+// respondStoreError maps store errors to HTTP responses. Records belonging to
+// another tenant are indistinguishable from missing ones and return 404.
+func respondStoreError(c *gin.Context, err error, notFoundMsg string) {
+	if errors.Is(err, store.ErrNotFound) {
+		respondError(c, http.StatusNotFound, notFoundMsg)
+		return
+	}
+	respondError(c, http.StatusInternalServerError, err.Error())
+}
@@ -0,0 +1,629 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"api-gateway-service/auth"
+	"api-gateway-service/placement"
+	"api-gateway-service/signing"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// tenantRouter returns the full router with an API key "key-<tenant>" for each
+// tenant and empty placement store
+func tenantRouter(t *testing.T, tenants ...string) *gin.Engine {
+	t.Helper()
+	keys := auth.NewMemoryAPIKeyStore()
+	for _, id := range tenants {
+		if err := keys.AddAPIKey(&auth.APIKey{ID: id, Hash: auth.HashAPIKey("key-" + id), Tier: "pro", TenantID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	auth.SetAPIKeyStore(keys)
+	prev := placementStore
+	placementStore = store.NewPlacementStore()
+	t.Cleanup(func() {
+		auth.SetAPIKeyStore(nil)
+		placementStore = prev
+	})
+
+	gin.SetMode(gin.TestMode)
+	return setupRouter()
+}
+
+// callAs sends a request to the router with the tenant's API key
+func callAs(router *gin.Engine, tenantID, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-API-Key", "key-"+tenantID)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// A tenant cannot read, list, change or delete another tenant's placement
+func TestPlacementTenantIsolation(t *testing.T) {
+	router := tenantRouter(t, "acme", "globex")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", `{"name":"web","vcpus":2,"memory_gb":8}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status %d: %s", w.Code, w.Body)
+	}
+	var p store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/v1/placements/compute/" + p.ID
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{method: http.MethodGet, path: path},
+		{method: http.MethodGet, path: path + "/history"},
+		{method: http.MethodPut, path: path, body: `{"name":"web","vcpus":4,"memory_gb":16}`},
+		{method: http.MethodDelete, path: path},
+	}
+	for _, tt := range tests {
+		if w := callAs(router, "globex", tt.method, tt.path, tt.body); w.Code != http.StatusNotFound {
+			t.Errorf("globex %s %s: status %d, want %d", tt.method, tt.path, w.Code, http.StatusNotFound)
+		}
+	}
+	if w := callAs(router, "globex", http.MethodGet, "/api/v1/placements/compute", ""); strings.Contains(w.Body.String(), p.ID) {
+		t.Errorf("globex listing %s holds acme's placement", w.Body)
+	}
+
+	w = callAs(router, "acme", http.MethodGet, path, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("acme get status %d: %s", w.Code, w.Body)
+	}
+	var got store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.InstanceType != p.InstanceType {
+		t.Errorf("acme's placement changed to %s by globex", got.InstanceType)
+	}
+}
+
+// Placement results carry a signature only when a signing key is configured
+func TestRespondPlacementSigning(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		signer *signing.Signer
+	}{
+		{name: "no signing key"},
+		{name: "signing key", signer: signing.NewSigner(private)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := placementSigner
+			placementSigner = tt.signer
+			t.Cleanup(func() { placementSigner = prev })
+
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			respondPlacement(c, http.StatusOK, &store.Placement{ID: "plc-1", ResourceType: "compute"})
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if _, signed := body[signing.Field]; signed != (tt.signer != nil) {
+				t.Errorf("response %s signed = %v, want %v", w.Body, signed, tt.signer != nil)
+			}
+			if body["id"] != "plc-1" {
+				t.Errorf("response %s, want the placement", w.Body)
+			}
+		})
+	}
+}
+
+// Placements requiring a framework missing from the catalog are rejected
+// before anything is placed
+func TestCreatePlacementUnknownFramework(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", `{"name":"web","vcpus":2,"memory_gb":8,"compliance_frameworks":["gdpr"]}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `did you mean \"GDPR\"`) {
+		t.Errorf("status %d: %s, want 400 suggesting GDPR", w.Code, w.Body)
+	}
+	if n := len(placementStore.List(tenant.NewContext(context.Background(), "acme"), "", "", false)); n != 0 {
+		t.Errorf("%d placements saved, want none", n)
+	}
+
+	w = callAs(router, "acme", http.MethodGet, "/api/v1/compliance/frameworks", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"GDPR"`) {
+		t.Errorf("frameworks status %d: %s", w.Code, w.Body)
+	}
+}
+
+// Cost-allocation tags are validated and echoed back on the placement
+func TestCreatePlacementTags(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", `{"name":"web","vcpus":2,"memory_gb":8,"tags":{"cost-center":"cc-1"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var p store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tags["cost-center"] != "cc-1" || len(p.Tags) != 1 {
+		t.Errorf("placement tags %v, want the requested tags", p.Tags)
+	}
+
+	w = callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", `{"name":"web","vcpus":2,"memory_gb":8,"tags":{"aws:owner":"x"}}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "reserved prefix") {
+		t.Errorf("status %d: %s, want 400 for a reserved tag key", w.Code, w.Body)
+	}
+}
+
+// Updating a placement appends to the history the endpoint returns
+func TestPlacementHistoryEndpoint(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", `{"name":"web","vcpus":2,"memory_gb":8}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status %d: %s", w.Code, w.Body)
+	}
+	var p store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	w = callAs(router, "acme", http.MethodPut, "/api/v1/placements/compute/"+p.ID, `{"name":"web","vcpus":8,"memory_gb":32}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update status %d: %s", w.Code, w.Body)
+	}
+	var updated store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+
+	w = callAs(router, "acme", http.MethodGet, "/api/v1/placements/compute/"+p.ID+"/history", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("history status %d: %s", w.Code, w.Body)
+	}
+	var versions []store.PlacementVersion
+	if err := json.Unmarshal(w.Body.Bytes(), &versions); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Version != 1 || versions[1].Version != 2 {
+		t.Fatalf("versions %+v, want the created and updated decisions in order", versions)
+	}
+	if versions[1].EstimatedMonthlyCost != updated.EstimatedMonthlyCost ||
+		versions[1].CostDelta != updated.EstimatedMonthlyCost-p.EstimatedMonthlyCost {
+		t.Errorf("latest version %+v, want the update's cost and its delta from %v", versions[1], p.EstimatedMonthlyCost)
+	}
+
+	if w := callAs(router, "acme", http.MethodGet, "/api/v1/placements/compute/plc-missing/history", ""); w.Code != http.StatusNotFound {
+		t.Errorf("history of a missing placement status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+// A placement group places members next to the members their affinity names,
+// and an affinity to an existing placement biases the region toward it
+func TestPlacementGroupAffinity(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute/group", `{"placements":[
+		{"name":"app","vcpus":2,"memory_gb":4,"affinity":[{"name":"db","monthly_traffic_gb":500}]},
+		{"name":"db","vcpus":2,"memory_gb":4,"regions":["eu-west-1"]}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("group status %d: %s", w.Code, w.Body)
+	}
+	var group []store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &group); err != nil {
+		t.Fatal(err)
+	}
+	if len(group) != 2 || group[1].SelectedRegion != "eu-west-1" {
+		t.Fatalf("group %+v, want app then db in eu-west-1", group)
+	}
+	app := group[0]
+	if app.SelectedRegion != "eu-west-1" || len(app.Affinity) != 1 || app.Affinity[0].Ref != "db" {
+		t.Errorf("app placed in %s with links %+v, want next to db", app.SelectedRegion, app.Affinity)
+	}
+
+	w = callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute",
+		`{"name":"worker","vcpus":2,"memory_gb":4,"affinity":[{"placement_id":"`+group[1].ID+`","monthly_traffic_gb":500}]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status %d: %s", w.Code, w.Body)
+	}
+	var worker store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &worker); err != nil {
+		t.Fatal(err)
+	}
+	if worker.SelectedRegion != "eu-west-1" || len(worker.Affinity) != 1 || worker.Affinity[0].Ref != group[1].ID {
+		t.Errorf("worker placed in %s with links %+v, want next to %s", worker.SelectedRegion, worker.Affinity, group[1].ID)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{
+			name:   "cycle",
+			path:   "/api/v1/placements/compute/group",
+			body:   `{"placements":[{"name":"a","vcpus":2,"memory_gb":4,"affinity":[{"name":"b"}]},{"name":"b","vcpus":2,"memory_gb":4,"affinity":[{"name":"a"}]}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown member",
+			path:   "/api/v1/placements/compute/group",
+			body:   `{"placements":[{"name":"a","vcpus":2,"memory_gb":4,"affinity":[{"name":"cache"}]}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "duplicate member",
+			path:   "/api/v1/placements/compute/group",
+			body:   `{"placements":[{"name":"a","vcpus":2,"memory_gb":4},{"name":"a","vcpus":2,"memory_gb":4}]}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown placement",
+			path:   "/api/v1/placements/compute",
+			body:   `{"name":"a","vcpus":2,"memory_gb":4,"affinity":[{"placement_id":"plc-missing"}]}`,
+			status: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		if w := callAs(router, "acme", http.MethodPost, tt.path, tt.body); w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.status, w.Body)
+		}
+	}
+	if w := callAs(router, "acme", http.MethodGet, "/api/v1/placements/compute", ""); strings.Count(w.Body.String(), `"id":"plc`) != 3 {
+		t.Errorf("listing %s, want only the 3 placed resources", w.Body)
+	}
+}
+
+// Placements list by resource group, with their estimated monthly cost
+// totalled per group
+func TestPlacementResourceGroups(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	cost := make(map[string]float64)
+	for _, body := range []string{
+		`{"name":"web-1","vcpus":2,"memory_gb":4,"resource_group":"web"}`,
+		`{"name":"web-2","vcpus":4,"memory_gb":16,"resource_group":"web"}`,
+		`{"name":"db","vcpus":8,"memory_gb":32,"resource_group":"data"}`,
+		`{"name":"batch","vcpus":2,"memory_gb":8}`,
+	} {
+		w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create status %d: %s", w.Code, w.Body)
+		}
+		var p store.Placement
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		cost[p.ResourceGroup] += p.EstimatedMonthlyCost
+	}
+
+	type listing struct {
+		Placements       []store.Placement   `json:"placements"`
+		Groups           []ResourceGroupCost `json:"groups"`
+		TotalMonthlyCost float64             `json:"total_monthly_cost"`
+	}
+	list := func(query string) listing {
+		t.Helper()
+		w := callAs(router, "acme", http.MethodGet, "/api/v1/placements"+query, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("list status %d: %s", w.Code, w.Body)
+		}
+		var l listing
+		if err := json.Unmarshal(w.Body.Bytes(), &l); err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	web := list("?group=web")
+	if len(web.Placements) != 2 || web.Placements[0].ResourceGroup != "web" || web.Placements[1].ResourceGroup != "web" {
+		t.Errorf("web placements %+v, want web-1 and web-2", web.Placements)
+	}
+	if len(web.Groups) != 1 || web.Groups[0] != (ResourceGroupCost{ResourceGroup: "web", PlacementCount: 2, EstimatedMonthlyCost: cost["web"]}) || web.TotalMonthlyCost != cost["web"] {
+		t.Errorf("web groups %+v totalling %v, want 2 placements costing %v", web.Groups, web.TotalMonthlyCost, cost["web"])
+	}
+
+	all := list("")
+	if len(all.Placements) != 4 || len(all.Groups) != 3 {
+		t.Fatalf("listing of %d placements in groups %+v, want 4 in 3 groups", len(all.Placements), all.Groups)
+	}
+	for i, group := range []string{"", "data", "web"} {
+		if g := all.Groups[i]; g.ResourceGroup != group || g.EstimatedMonthlyCost != cost[group] {
+			t.Errorf("group %d %+v, want %q costing %v", i, g, group, cost[group])
+		}
+	}
+	if total := cost[""] + cost["data"] + cost["web"]; all.TotalMonthlyCost != total {
+		t.Errorf("total %v, want %v", all.TotalMonthlyCost, total)
+	}
+
+	if empty := list("?group=missing"); len(empty.Placements) != 0 || len(empty.Groups) != 0 || empty.TotalMonthlyCost != 0 {
+		t.Errorf("unknown group listing %+v, want it empty", empty)
+	}
+	w := callAs(router, "acme", http.MethodGet, "/api/v1/placements/compute?group=data", "")
+	var data []store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &data); err != nil {
+		t.Fatalf("compute listing status %d: %v", w.Code, err)
+	}
+	if len(data) != 1 || data[0].ResourceGroup != "data" {
+		t.Errorf("compute placements in data %+v, want only db", data)
+	}
+}
+
+func TestGroupCosts(t *testing.T) {
+	groups := groupCosts([]*store.Placement{
+		{ResourceGroup: "web", EstimatedMonthlyCost: 10},
+		{ResourceGroup: "web", EstimatedMonthlyCost: 10, AggregateMonthlyCost: 30},
+		{EstimatedMonthlyCost: 5},
+	})
+	want := []ResourceGroupCost{
+		{ResourceGroup: "", PlacementCount: 1, EstimatedMonthlyCost: 5},
+		{ResourceGroup: "web", PlacementCount: 2, EstimatedMonthlyCost: 40},
+	}
+	if len(groups) != len(want) || groups[0] != want[0] || groups[1] != want[1] {
+		t.Errorf("groupCosts() = %+v, want %+v", groups, want)
+	}
+}
+
+// The frontier endpoint returns the Pareto-optimal options without saving a
+// placement
+func TestComputeFrontierEndpoint(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute/frontier", `{"vcpus":2,"memory_gb":8}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Frontier []placement.FrontierOption `json:"frontier"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(resp.Frontier); n < 2 || resp.Frontier[0].BestOn[0] != placement.DimensionCost || resp.Frontier[n-1].PerformanceScore <= resp.Frontier[0].PerformanceScore {
+		t.Errorf("frontier %+v, want the cheapest first and faster options after it", resp.Frontier)
+	}
+	if saved := placementStore.List(tenant.NewContext(context.Background(), "acme"), "", "", false); len(saved) != 0 {
+		t.Errorf("%d placements saved, want none", len(saved))
+	}
+
+	tests := []struct {
+		path, body string
+		want       int
+	}{
+		{"/api/v1/placements/storage/frontier", `{"vcpus":2,"memory_gb":8}`, http.StatusBadRequest},
+		{"/api/v1/placements/compute/frontier", `{"vcpus":0,"memory_gb":8}`, http.StatusBadRequest},
+		{"/api/v1/placements/compute/frontier", `{"vcpus":512,"memory_gb":8}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		if w := callAs(router, "acme", http.MethodPost, tt.path, tt.body); w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d: %s", tt.path, tt.body, w.Code, tt.want, w.Body)
+		}
+	}
+}
+
+// A placement's estimated monthly cost includes its expected data transfer,
+// itemized per destination class
+func TestCreatePlacementDataTransfer(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	create := func(body string) (*httptest.ResponseRecorder, store.Placement) {
+		t.Helper()
+		w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", body)
+		var p store.Placement
+		if w.Code == http.StatusCreated {
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w, p
+	}
+
+	_, base := create(`{"name":"web","vcpus":2,"memory_gb":8,"regions":["us-east-1"]}`)
+	w, p := create(`{"name":"web","vcpus":2,"memory_gb":8,"regions":["us-east-1"],
+		"data_transfer":{"outbound":{"intra_region_gb":1000,"inter_region_gb":500,"internet_gb":200}}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	want := store.TransferCost{IntraRegion: 10, InterRegion: 10, Internet: 18}
+	if p.DataTransferCost == nil || *p.DataTransferCost != want {
+		t.Errorf("data transfer cost %+v, want %+v", p.DataTransferCost, want)
+	}
+	if got := p.EstimatedMonthlyCost - base.EstimatedMonthlyCost; got < 37.99 || got > 38.01 {
+		t.Errorf("estimated monthly cost %v, want %v plus 38 of egress", p.EstimatedMonthlyCost, base.EstimatedMonthlyCost)
+	}
+
+	if w, _ := create(`{"name":"web","vcpus":2,"memory_gb":8,"data_transfer":{"outbound":{"internet_gb":-1}}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("negative data transfer: status %d, want 400", w.Code)
+	}
+}
+
+func TestCreatePlacementZones(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute",
+		`{"name":"web","vcpus":2,"memory_gb":8,"availability_zones":{"required":["eu-west-1b"],"preferred":["eu-west-1c"],"spread":2}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var p store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.SelectedRegion != "eu-west-1" || strings.Join(p.SelectedZones, ",") != "eu-west-1b,eu-west-1c" {
+		t.Errorf("selected %s %v, want eu-west-1 [eu-west-1b eu-west-1c]", p.SelectedRegion, p.SelectedZones)
+	}
+
+	tests := []struct {
+		body       string
+		wantStatus int
+	}{
+		{body: `{"name":"web","vcpus":2,"memory_gb":8,"availability_zones":{"required":["us-east-1a","us-east-1b"],"spread":1}}`, wantStatus: http.StatusBadRequest},
+		{body: `{"name":"web","vcpus":2,"memory_gb":8,"regions":["us-east-1"],"availability_zones":{"required":["eu-west-1a"]}}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		if w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", tt.body); w.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.body, w.Code, tt.wantStatus, w.Body)
+		}
+	}
+}
+
+func TestCreatePlacementDataResidency(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute",
+		`{"name":"web","vcpus":2,"memory_gb":8,"data_residency":["EU"],"excluded_providers":["azure","gcp"]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var p store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.SelectedRegion != "eu-west-1" || p.SelectedJurisdiction != "IE" {
+		t.Errorf("selected %s in %q, want eu-west-1 in IE", p.SelectedRegion, p.SelectedJurisdiction)
+	}
+
+	tests := []struct {
+		body       string
+		wantStatus int
+	}{
+		{body: `{"name":"web","vcpus":2,"memory_gb":8,"data_residency":["Europe"]}`, wantStatus: http.StatusBadRequest},
+		{body: `{"name":"web","vcpus":2,"memory_gb":8,"data_residency":["CH"]}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		if w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", tt.body); w.Code != tt.wantStatus {
+			t.Errorf("%s: status %d, want %d: %s", tt.body, w.Code, tt.wantStatus, w.Body)
+		}
+	}
+}
+
+func TestPlacementMetadata(t *testing.T) {
+	router := tenantRouter(t, "acme")
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute",
+		`{"name":"web","vcpus":2,"memory_gb":8,"metadata":{"ticket":"OPS-42","owner":"platform"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var created store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	w = callAs(router, "acme", http.MethodGet, "/api/v1/placements/compute/"+created.ID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get status %d: %s", w.Code, w.Body)
+	}
+	var read store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &read); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"ticket": "OPS-42", "owner": "platform"}
+	for name, p := range map[string]store.Placement{"created": created, "read": read} {
+		if !reflect.DeepEqual(p.Metadata, want) {
+			t.Errorf("%s metadata %v, want %v", name, p.Metadata, want)
+		}
+	}
+
+	oversized := fmt.Sprintf(`{"name":"web","vcpus":2,"memory_gb":8,"metadata":{"notes":%q}}`, strings.Repeat("x", placement.MaxMetadataSize))
+	if w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", oversized); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "metadata must be at most") {
+		t.Errorf("oversized metadata: status %d, want 400: %s", w.Code, w.Body)
+	}
+}
+
+func TestCreatePlacementSavings(t *testing.T) {
+	router := tenantRouter(t, "acme")
+	create := func(current string) store.Placement {
+		t.Helper()
+		body := `{"name":"web","vcpus":2,"memory_gb":8,"regions":["us-east-1"]` + current + `}`
+		w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", body)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+		var p store.Placement
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	base := create("")
+	if base.CurrentMonthlyCost != nil || base.EstimatedSavings != nil || base.SavingsPercent != nil || base.CostIncrease {
+		t.Errorf("placement without a current cost has savings %+v", base)
+	}
+
+	tests := []struct {
+		name         string
+		current      float64
+		wantIncrease bool
+	}{
+		{name: "positive savings", current: base.EstimatedMonthlyCost * 2},
+		{name: "negative savings", current: base.EstimatedMonthlyCost / 2, wantIncrease: true},
+	}
+	for _, tt := range tests {
+		p := create(fmt.Sprintf(`,"current_monthly_cost":%v`, tt.current))
+		wantSavings := tt.current - p.EstimatedMonthlyCost
+		if p.CurrentMonthlyCost == nil || *p.CurrentMonthlyCost != tt.current || p.EstimatedSavings == nil || *p.EstimatedSavings != wantSavings {
+			t.Errorf("%s: current %v, savings %v, want %v and %v", tt.name, p.CurrentMonthlyCost, p.EstimatedSavings, tt.current, wantSavings)
+		}
+		if p.SavingsPercent == nil || p.CostIncrease != tt.wantIncrease {
+			t.Errorf("%s: percent %v, cost increase %v, want a percent and %v", tt.name, p.SavingsPercent, p.CostIncrease, tt.wantIncrease)
+		}
+	}
+
+	w := callAs(router, "acme", http.MethodPost, "/api/v1/placements/compute", `{"name":"web","vcpus":2,"memory_gb":8,"current_monthly_cost":-5}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("negative current cost: status %d, want 400", w.Code)
+	}
+}
+
+func TestCreatePlacementPricing(t *testing.T) {
+	saved := pricingOverrides
+	pricingOverrides = placement.PricingOverrides{"acme": {"aws": {DiscountPercent: 20}}}
+	t.Cleanup(func() { pricingOverrides = saved })
+	router := tenantRouter(t, "acme", "globex")
+
+	place := func(tenantID string) store.Placement {
+		t.Helper()
+		w := callAs(router, tenantID, http.MethodPost, "/api/v1/placements/compute",
+			`{"name":"web","vcpus":2,"memory_gb":8,"regions":["us-east-1"],"excluded_providers":["azure","gcp"]}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s: status %d: %s", tenantID, w.Code, w.Body)
+		}
+		var p store.Placement
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	negotiated, listed := place("acme"), place("globex")
+	if !negotiated.CustomPricing || listed.CustomPricing {
+		t.Errorf("custom pricing acme %v, globex %v, want true and false", negotiated.CustomPricing, listed.CustomPricing)
+	}
+	if want := listed.EstimatedMonthlyCost * 0.8; negotiated.EstimatedMonthlyCost < want-1e-9 || negotiated.EstimatedMonthlyCost > want+1e-9 {
+		t.Errorf("acme estimate %v, want 80%% of globex's %v", negotiated.EstimatedMonthlyCost, listed.EstimatedMonthlyCost)
+	}
+}
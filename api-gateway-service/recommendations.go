@@ -0,0 +1,544 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/auth"
+	"api-gateway-service/maintenance"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+var (
+	recommendationStore = store.NewRecommendationStore()
+	feedbackStore       = store.NewFeedbackStore()
+	applicationStore    = store.NewApplicationStore()
+	jobStore            = store.NewJobStore()
+)
+
+// jobEventInterval is how often an apply job event stream checks for changes
+const jobEventInterval = 500 * time.Millisecond
+
+// JobEvent reports the new status of one item of an apply job
+type JobEvent struct {
+	Index int `json:"index"`
+	store.JobItem
+}
+
+// ApplyRequest is the body accepted by the apply endpoint. Without a
+// maintenance window every recommendation is applied immediately.
+// OverrideCompliance applies recommendations even when they would move a
+// placement out of compliance.
+type ApplyRequest struct {
+	RecommendationIDs  []string            `json:"recommendation_ids" binding:"required,min=1"`
+	MaintenanceWindow  *maintenance.Window `json:"maintenance_window"`
+	OverrideCompliance bool                `json:"override_compliance"`
+}
+
+// FeedbackRequest is the body accepted by the recommendation feedback endpoint
+type FeedbackRequest struct {
+	Action string `json:"action" binding:"required,oneof=accepted rejected snoozed"`
+	Reason string `json:"reason"`
+}
+
+// Recommendation orderings accepted by the sort parameter
+const (
+	sortCreated          = "created"
+	sortSavings          = "savings"
+	sortSavingsPerEffort = "savings_per_effort"
+	sortEffort           = "effort"
+)
+
+// EffortGroup is the open recommendations of one effort level
+type EffortGroup struct {
+	Effort           string                  `json:"effort"`
+	EstimatedSavings float64                 `json:"estimated_savings"`
+	Recommendations  []*store.Recommendation `json:"recommendations"`
+}
+
+// getRecommendations lists the open recommendations, oldest first or in the
+// order given by ?sort. With ?group_by=effort they are grouped by effort
+// level, least effort first, so ?group_by=effort&sort=savings_per_effort
+// lists the quick wins first.
+func getRecommendations(c *gin.Context) {
+	recs := openRecommendations(c.Request.Context(), time.Now().UTC())
+
+	switch order := c.DefaultQuery("sort", sortCreated); order {
+	case sortCreated:
+	case sortSavings, sortSavingsPerEffort, sortEffort:
+		sortRecommendations(recs, order)
+	default:
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid sort: %s (must be created, savings, savings_per_effort or effort)", order))
+		return
+	}
+
+	switch groupBy := c.Query("group_by"); groupBy {
+	case "":
+		c.JSON(http.StatusOK, recs)
+	case "effort":
+		c.JSON(http.StatusOK, gin.H{"groups": groupByEffort(recs)})
+	default:
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("invalid group_by: %s (must be effort)", groupBy))
+	}
+}
+
+// sortRecommendations orders recommendations by largest estimated savings,
+// largest savings per effort, or least effort then largest savings. The sort
+// is stable, so ties stay oldest first.
+func sortRecommendations(recs []*store.Recommendation, order string) {
+	rank := make(map[string]int, len(store.EffortLevels))
+	for i, level := range store.EffortLevels {
+		rank[level] = i
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		a, b := recs[i], recs[j]
+		switch order {
+		case sortSavingsPerEffort:
+			return a.SavingsPerEffort() > b.SavingsPerEffort()
+		case sortEffort:
+			if rank[a.Effort] != rank[b.Effort] {
+				return rank[a.Effort] < rank[b.Effort]
+			}
+		}
+		return a.EstimatedSavings > b.EstimatedSavings
+	})
+}
+
+// groupByEffort groups the recommendations by effort level, least effort
+// first, keeping their order within each group. Levels without
+// recommendations are omitted.
+func groupByEffort(recs []*store.Recommendation) []EffortGroup {
+	byEffort := make(map[string]*EffortGroup, len(store.EffortLevels))
+	for _, rec := range recs {
+		g, ok := byEffort[rec.Effort]
+		if !ok {
+			g = &EffortGroup{Effort: rec.Effort}
+			byEffort[rec.Effort] = g
+		}
+		g.EstimatedSavings += rec.EstimatedSavings
+		g.Recommendations = append(g.Recommendations, rec)
+	}
+
+	groups := make([]EffortGroup, 0, len(byEffort))
+	for _, level := range store.EffortLevels {
+		if g, ok := byEffort[level]; ok {
+			groups = append(groups, *g)
+		}
+	}
+	return groups
+}
+
+// openRecommendations returns the recommendations that are not snoozed at now
+func openRecommendations(ctx context.Context, now time.Time) []*store.Recommendation {
+	recs := make([]*store.Recommendation, 0)
+	for _, rec := range recommendationStore.List(ctx) {
+		if feedbackStore.IsSnoozed(ctx, rec.ID, now) {
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func recordRecommendationFeedback(c *gin.Context) {
+	var req FeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	rec, err := recommendationStore.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "recommendation not found")
+		return
+	}
+
+	fb := &store.Feedback{
+		RecommendationID:   rec.ID,
+		RecommendationType: rec.Type,
+		Action:             store.FeedbackAction(req.Action),
+		Reason:             req.Reason,
+	}
+	if err := feedbackStore.Record(c.Request.Context(), fb, viper.GetDuration("recommendations.snooze_period")); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, fb)
+}
+
+func getRecommendationFeedback(c *gin.Context) {
+	c.JSON(http.StatusOK, feedbackStore.AcceptanceRates(c.Request.Context()))
+}
+
+// applyRecommendations records an application of each requested
+// recommendation; the gateway does not change resources itself. Disruptive
+// ones requested outside the maintenance window are scheduled for its next
+// opening; non-disruptive ones (e.g. tagging) are recorded as applied
+// immediately. With ?async=true they are recorded by a background job whose
+// progress can be polled or streamed. When any recommendation requires
+// approval (see requiresApproval) the job instead waits for an approver.
+// Recommendations moving a placement to a region that does not support its
+// required compliance frameworks are rejected unless override_compliance is
+// set.
+func applyRecommendations(c *gin.Context) {
+	var req ApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	async, err := strconv.ParseBool(c.DefaultQuery("async", "false"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "invalid async: must be true or false")
+		return
+	}
+	if req.MaintenanceWindow != nil {
+		if err := req.MaintenanceWindow.Validate(); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	recs := make([]*store.Recommendation, len(req.RecommendationIDs))
+	for i, id := range req.RecommendationIDs {
+		rec, err := recommendationStore.Get(ctx, id)
+		if err != nil {
+			respondStoreError(c, err, "recommendation not found: "+id)
+			return
+		}
+		recs[i] = rec
+	}
+
+	// Nothing is applied when any recommendation would break compliance
+	if violations := checkApplyCompliance(ctx, recs); len(violations) > 0 {
+		if !req.OverrideCompliance {
+			c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: ErrorBody{
+				Code:    codeComplianceViolation,
+				Message: "applying would violate required compliance frameworks; set override_compliance to apply anyway",
+				Details: gin.H{"violations": violations},
+			}})
+			return
+		}
+		log.Printf("Apply by %s overrides %d compliance violations", callerID(c), len(violations))
+	}
+
+	// Requests holding a high-impact recommendation wait for approval
+	needsApproval := make([]bool, len(recs))
+	gated := false
+	for i, rec := range recs {
+		needsApproval[i] = requiresApproval(rec)
+		gated = gated || needsApproval[i]
+	}
+
+	if async || gated {
+		job := &store.ApplyJob{
+			ID:                newID("job"),
+			Status:            store.JobRunning,
+			Items:             make([]store.JobItem, len(recs)),
+			MaintenanceWindow: req.MaintenanceWindow,
+		}
+		for i, rec := range recs {
+			job.Items[i] = store.JobItem{
+				RecommendationID: rec.ID,
+				Status:           store.JobItemPending,
+				RequiresApproval: needsApproval[i],
+			}
+		}
+		if gated {
+			now := time.Now().UTC()
+			job.Status = store.JobPendingApproval
+			job.Approval = &store.Approval{
+				RequestedBy: callerID(c),
+				RequestedAt: now,
+				ExpiresAt:   now.Add(viper.GetDuration("recommendations.approval.expiry")),
+			}
+		}
+		if err := jobStore.Save(ctx, job); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if !gated {
+			// The job outlives the request, so it keeps only the caller's tenant
+			jobCtx := tenant.NewContext(context.Background(), tenant.FromContext(ctx))
+			go runApplyJob(jobCtx, job.ID, recs, req.MaintenanceWindow)
+		}
+
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	applications := make([]*store.Application, len(recs))
+	for i, rec := range recs {
+		app, err := recordApplication(ctx, rec, req.MaintenanceWindow)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		applications[i] = app
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+// requiresApproval reports whether applying rec must wait for an approver.
+// Its impact is the change in monthly cost it estimates: a saving
+// (positive estimated savings) requires approval above
+// recommendations.approval.threshold, and a cost increase (negative
+// estimated savings) above recommendations.approval.cost_increase_threshold,
+// which defaults to the same threshold. A threshold of 0 gates nothing.
+func requiresApproval(rec *store.Recommendation) bool {
+	threshold := viper.GetFloat64("recommendations.approval.threshold")
+	if rec.EstimatedSavings < 0 {
+		if increase := viper.GetFloat64("recommendations.approval.cost_increase_threshold"); increase > 0 {
+			threshold = increase
+		}
+		return threshold > 0 && -rec.EstimatedSavings > threshold
+	}
+	return threshold > 0 && rec.EstimatedSavings > threshold
+}
+
+// recordApplication records the application of a recommendation, scheduling
+// it for the window's next opening when it is disruptive and the window is
+// closed. Nothing is changed at the provider; the application is the record
+// an executor acts on.
+func recordApplication(ctx context.Context, rec *store.Recommendation, window *maintenance.Window) (*store.Application, error) {
+	now := time.Now().UTC()
+	app := &store.Application{
+		ID:               newID("app"),
+		RecommendationID: rec.ID,
+		Action:           rec.Action,
+		Disruptive:       rec.IsDisruptive(),
+		Status:           store.ApplicationApplied,
+		AppliedAt:        &now,
+	}
+	if app.Disruptive && window != nil && !window.Contains(now) {
+		scheduled := window.Next(now).UTC()
+		app.Status = store.ApplicationScheduled
+		app.ScheduledFor = &scheduled
+		app.AppliedAt = nil
+	}
+
+	if err := applicationStore.Save(ctx, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// runApplyJob records the application of the job's recommendations in
+// order, recording each item's progress
+func runApplyJob(ctx context.Context, jobID string, recs []*store.Recommendation, window *maintenance.Window) {
+	for i, rec := range recs {
+		item := store.JobItem{RecommendationID: rec.ID, Status: store.JobItemApplying}
+		if err := jobStore.SetItem(ctx, jobID, i, item); err != nil {
+			log.Printf("Apply job %s: %v", jobID, err)
+			return
+		}
+
+		app, err := recordApplication(ctx, rec, window)
+		if err != nil {
+			item.Status = store.JobItemFailed
+			item.Error = err.Error()
+		} else {
+			item.Status = store.JobItemDone
+			item.ApplicationID = app.ID
+		}
+		if err := jobStore.SetItem(ctx, jobID, i, item); err != nil {
+			log.Printf("Apply job %s: %v", jobID, err)
+			return
+		}
+	}
+
+	if err := jobStore.Complete(ctx, jobID); err != nil {
+		log.Printf("Apply job %s: %v", jobID, err)
+	}
+}
+
+// ApprovalDecision is the optional body accepted by the approve and reject
+// endpoints
+type ApprovalDecision struct {
+	Reason string `json:"reason"`
+}
+
+// approveApplyJob releases an apply job pending approval to run
+func approveApplyJob(c *gin.Context) {
+	decideApplyJob(c, true)
+}
+
+// rejectApplyJob cancels an apply job pending approval
+func rejectApplyJob(c *gin.Context) {
+	decideApplyJob(c, false)
+}
+
+// decideApplyJob records an approver's decision on a pending apply job and
+// starts the job when approved. Requesters may not approve their own jobs.
+func decideApplyJob(c *gin.Context, approve bool) {
+	var req ApprovalDecision
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondValidationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	job, err := jobStore.Get(ctx, id)
+	if err != nil {
+		respondStoreError(c, err, "job not found")
+		return
+	}
+
+	approver := callerID(c)
+	if approve && job.Approval != nil && job.Approval.RequestedBy != "" && job.Approval.RequestedBy == approver {
+		respondError(c, http.StatusForbidden, "an apply job cannot be approved by its requester")
+		return
+	}
+
+	recs := make([]*store.Recommendation, len(job.Items))
+	for i, item := range job.Items {
+		if recs[i], err = recommendationStore.Get(ctx, item.RecommendationID); err != nil {
+			respondStoreError(c, err, "recommendation not found: "+item.RecommendationID)
+			return
+		}
+	}
+
+	job, err = jobStore.Decide(ctx, id, approve, approver, req.Reason, time.Now().UTC())
+	if errors.Is(err, store.ErrNotPendingApproval) {
+		respondError(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondStoreError(c, err, "job not found")
+		return
+	}
+
+	if approve {
+		jobCtx := tenant.NewContext(context.Background(), tenant.FromContext(ctx))
+		go runApplyJob(jobCtx, job.ID, recs, job.MaintenanceWindow)
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// callerID identifies the authenticated caller by user ID, falling back to
+// the username
+func callerID(c *gin.Context) string {
+	claims, ok := c.Get("claims")
+	if !ok {
+		return ""
+	}
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		return ""
+	}
+	if userClaims.UserID != "" {
+		return userClaims.UserID
+	}
+	return userClaims.Username
+}
+
+// runApprovalExpiry expires apply jobs left pending approval past
+// recommendations.approval.expiry, checking every interval until ctx is done
+func runApprovalExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, job := range jobStore.ExpirePending(now.UTC()) {
+				log.Printf("Apply job %s expired without approval", job.ID)
+			}
+		}
+	}
+}
+
+func getApplyJob(c *gin.Context) {
+	job, err := jobStore.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "job not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// streamApplyJob streams an apply job's progress as server-sent events: an
+// "item" event with a JobEvent whenever an item changes status, then a "done"
+// event with the completed job. Each event extends the write deadline, and a
+// keepalive comment is sent while the job is idle, for example pending
+// approval. Clients that lose the stream can poll the job instead.
+func streamApplyJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	job, err := jobStore.Get(ctx, id)
+	if err != nil {
+		respondStoreError(c, err, "job not found")
+		return
+	}
+
+	stream := startSSE(c)
+
+	ticker := time.NewTicker(jobEventInterval)
+	defer ticker.Stop()
+
+	sent := make([]store.JobItemStatus, len(job.Items))
+	for {
+		for i, item := range job.Items {
+			if item.Status != sent[i] {
+				stream.event("item", JobEvent{Index: i, JobItem: item})
+				sent[i] = item.Status
+			}
+		}
+		if job.Finished() {
+			stream.event("done", job)
+			stream.flush()
+			return
+		}
+		if err := stream.flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if job, err = jobStore.Get(ctx, id); err != nil {
+			return
+		}
+	}
+}
+
+// runScheduledApplications applies scheduled recommendations once their
+// scheduled time has passed, checking every interval until ctx is done
+func runScheduledApplications(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, app := range applicationStore.MarkDueApplied(now.UTC()) {
+				log.Printf("Applied scheduled recommendation %s (application %s)", app.RecommendationID, app.ID)
+			}
+		}
+	}
+}
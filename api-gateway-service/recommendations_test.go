@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/auth"
+	"api-gateway-service/store"
+)
+
+func TestRequiresApproval(t *testing.T) {
+	tests := []struct {
+		name              string
+		threshold         float64
+		increaseThreshold float64
+		savings           float64
+		want              bool
+	}{
+		{name: "no threshold", savings: 5000},
+		{name: "saving below threshold", threshold: 100, savings: 50},
+		{name: "saving above threshold", threshold: 100, savings: 150, want: true},
+		{name: "cost increase below threshold", threshold: 100, savings: -50},
+		{name: "cost increase above threshold", threshold: 100, savings: -150, want: true},
+		{name: "cost increase below its own threshold", threshold: 100, increaseThreshold: 200, savings: -150},
+		{name: "cost increase above its own threshold", threshold: 100, increaseThreshold: 10, savings: -50, want: true},
+		{name: "saving ignores the cost increase threshold", threshold: 100, increaseThreshold: 10, savings: 50},
+		{name: "cost increase threshold alone", increaseThreshold: 10, savings: -50, want: true},
+		{name: "saving with the cost increase threshold alone", increaseThreshold: 10, savings: 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("recommendations.approval.threshold", tt.threshold)
+			viper.Set("recommendations.approval.cost_increase_threshold", tt.increaseThreshold)
+			t.Cleanup(func() {
+				viper.Set("recommendations.approval.threshold", nil)
+				viper.Set("recommendations.approval.cost_increase_threshold", nil)
+			})
+
+			if got := requiresApproval(&store.Recommendation{EstimatedSavings: tt.savings}); got != tt.want {
+				t.Errorf("requiresApproval with savings %v = %v, want %v", tt.savings, got, tt.want)
+			}
+		})
+	}
+}
+
+// A request holding a recommendation above either threshold waits for
+// approval, whichever way it moves the cost
+func TestApplyRecommendationsApproval(t *testing.T) {
+	viper.Set("recommendations.approval.threshold", 100)
+	viper.Set("recommendations.approval.cost_increase_threshold", 20)
+	t.Cleanup(func() {
+		viper.Set("recommendations.approval.threshold", nil)
+		viper.Set("recommendations.approval.cost_increase_threshold", nil)
+	})
+
+	tests := []struct {
+		name       string
+		savings    float64
+		wantStatus int
+	}{
+		{name: "small saving", savings: 50, wantStatus: http.StatusOK},
+		{name: "large saving", savings: 150, wantStatus: http.StatusAccepted},
+		{name: "small cost increase", savings: -10, wantStatus: http.StatusOK},
+		{name: "large cost increase", savings: -50, wantStatus: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prevRecs, prevApps, prevJobs := recommendationStore, applicationStore, jobStore
+			recommendationStore, applicationStore, jobStore = store.NewRecommendationStore(), store.NewApplicationStore(), store.NewJobStore()
+			t.Cleanup(func() { recommendationStore, applicationStore, jobStore = prevRecs, prevApps, prevJobs })
+			rec := &store.Recommendation{ID: "rec-1", Type: "cost", Action: store.ActionResize, EstimatedSavings: tt.savings}
+			if err := recommendationStore.Save(context.Background(), rec); err != nil {
+				t.Fatal(err)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/optimize/apply", applyRecommendations)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/optimize/apply", bytes.NewBufferString(`{"recommendation_ids":["rec-1"]}`)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+
+			var job store.ApplyJob
+			if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+				t.Fatal(err)
+			}
+			if job.Status != store.JobPendingApproval || len(job.Items) != 1 || !job.Items[0].RequiresApproval {
+				t.Errorf("job %+v, want it pending approval of its item", job)
+			}
+		})
+	}
+}
+
+// Approving a held job runs it, rejecting one or approving one's own request
+// does not, and only admins and approvers may decide
+func TestApplyJobApprovalDecision(t *testing.T) {
+	viper.Set("recommendations.approval.threshold", 100)
+	viper.Set("recommendations.approval.expiry", time.Hour)
+	t.Cleanup(func() {
+		viper.Set("recommendations.approval.threshold", nil)
+		viper.Set("recommendations.approval.expiry", nil)
+	})
+	prevRecs, prevApps, prevJobs := recommendationStore, applicationStore, jobStore
+	recommendationStore, applicationStore, jobStore = store.NewRecommendationStore(), store.NewApplicationStore(), store.NewJobStore()
+	t.Cleanup(func() { recommendationStore, applicationStore, jobStore = prevRecs, prevApps, prevJobs })
+	if err := recommendationStore.Save(context.Background(), &store.Recommendation{ID: "rec-1", Type: "cost", Action: store.ActionResize, EstimatedSavings: 500}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("claims", &auth.Claims{UserID: c.GetHeader("X-User"), Roles: strings.Fields(c.GetHeader("X-Roles"))})
+	})
+	router.POST("/optimize/apply", applyRecommendations)
+	router.POST("/optimize/apply/:id/approve", auth.AnyRoleMiddleware("admin", "approver"), approveApplyJob)
+	router.POST("/optimize/apply/:id/reject", auth.AnyRoleMiddleware("admin", "approver"), rejectApplyJob)
+	post := func(user, roles, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("X-User", user)
+		req.Header.Set("X-Roles", roles)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	request := func() store.ApplyJob {
+		t.Helper()
+		w := post("alice", "user", "/optimize/apply", `{"recommendation_ids":["rec-1"]}`)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("apply status %d, want %d: %s", w.Code, http.StatusAccepted, w.Body)
+		}
+		var job store.ApplyJob
+		if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+			t.Fatal(err)
+		}
+		if job.Approval == nil || job.Approval.RequestedBy != "alice" || job.Approval.ExpiresAt.Sub(job.Approval.RequestedAt) != time.Hour {
+			t.Fatalf("job approval %+v, want alice's request expiring in an hour", job.Approval)
+		}
+		return job
+	}
+
+	job := request()
+	if w := post("bob", "viewer", "/optimize/apply/"+job.ID+"/approve", ""); w.Code != http.StatusForbidden {
+		t.Errorf("approval by a viewer: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if w := post("alice", "approver", "/optimize/apply/"+job.ID+"/approve", ""); w.Code != http.StatusForbidden {
+		t.Errorf("approval by the requester: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if apps := applicationStore.List(context.Background()); len(apps) != 0 {
+		t.Fatalf("%d applications before approval, want none", len(apps))
+	}
+
+	if w := post("bob", "approver", "/optimize/apply/"+job.ID+"/approve", `{"reason":"budgeted"}`); w.Code != http.StatusOK {
+		t.Fatalf("approval status %d: %s", w.Code, w.Body)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := jobStore.Get(context.Background(), job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Finished() {
+			if got.Status != store.JobCompleted || got.Items[0].Status != store.JobItemDone || got.Approval.DecidedBy != "bob" {
+				t.Errorf("approved job %+v, want it completed", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("approved job still %s", got.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if w := post("bob", "approver", "/optimize/apply/"+job.ID+"/reject", ""); w.Code != http.StatusConflict {
+		t.Errorf("decision on a decided job: status %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	job = request()
+	if w := post("carol", "admin", "/optimize/apply/"+job.ID+"/reject", ""); w.Code != http.StatusOK {
+		t.Fatalf("rejection status %d: %s", w.Code, w.Body)
+	}
+	if got, _ := jobStore.Get(context.Background(), job.ID); got.Status != store.JobRejected {
+		t.Errorf("rejected job is %s", got.Status)
+	}
+	if apps := applicationStore.List(context.Background()); len(apps) != 1 {
+		t.Errorf("%d applications, want only the approved job's", len(apps))
+	}
+
+	job = request()
+	jobStore.ExpirePending(time.Now().Add(2 * time.Hour))
+	if w := post("bob", "approver", "/optimize/apply/"+job.ID+"/approve", ""); w.Code != http.StatusConflict {
+		t.Errorf("approval after expiry: status %d, want %d", w.Code, http.StatusConflict)
+	}
+}
+
+// Disruptive recommendations wait for a closed maintenance window while
+// tagging and anything inside an open window are applied immediately
+func TestApplyRecommendationsMaintenanceWindow(t *testing.T) {
+	now := time.Now().UTC()
+	later := now.AddDate(0, 0, 2).Weekday()
+	closed := fmt.Sprintf(`{"ranges":[{"days":[%q],"start":"00:00","end":"01:00"}]}`, strings.ToLower(later.String()[:3]))
+	open := `{"ranges":[{"start":"00:00","end":"00:00"}]}`
+
+	tests := []struct {
+		name          string
+		action        string
+		window        string
+		wantScheduled bool
+	}{
+		{name: "disruptive outside the window", action: store.ActionResize, window: closed, wantScheduled: true},
+		{name: "disruptive inside the window", action: store.ActionResize, window: open},
+		{name: "tagging outside the window", action: store.ActionTag, window: closed},
+		{name: "disruptive without a window", action: store.ActionMigrate, window: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prevRecs, prevApps := recommendationStore, applicationStore
+			recommendationStore, applicationStore = store.NewRecommendationStore(), store.NewApplicationStore()
+			t.Cleanup(func() { recommendationStore, applicationStore = prevRecs, prevApps })
+			if err := recommendationStore.Save(context.Background(), &store.Recommendation{ID: "rec-1", Type: "cost", Action: tt.action}); err != nil {
+				t.Fatal(err)
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/optimize/apply", applyRecommendations)
+			w := httptest.NewRecorder()
+			body := `{"recommendation_ids":["rec-1"],"maintenance_window":` + tt.window + `}`
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/optimize/apply", bytes.NewBufferString(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", w.Code, w.Body)
+			}
+
+			var apps []store.Application
+			if err := json.Unmarshal(w.Body.Bytes(), &apps); err != nil {
+				t.Fatal(err)
+			}
+			if len(apps) != 1 {
+				t.Fatalf("%d applications, want 1", len(apps))
+			}
+			app := apps[0]
+			if !tt.wantScheduled {
+				if app.Status != store.ApplicationApplied || app.AppliedAt == nil || app.ScheduledFor != nil {
+					t.Errorf("application %+v, want it applied immediately", app)
+				}
+				return
+			}
+			if app.Status != store.ApplicationScheduled || app.AppliedAt != nil || app.ScheduledFor == nil {
+				t.Fatalf("application %+v, want it scheduled", app)
+			}
+			if app.ScheduledFor.Weekday() != later || app.ScheduledFor.Hour() != 0 || !app.ScheduledFor.After(now) {
+				t.Errorf("scheduled for %v, want the next %s opening", app.ScheduledFor, later)
+			}
+		})
+	}
+}
+
+// An invalid maintenance window is rejected before anything is applied
+func TestApplyRecommendationsInvalidWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/optimize/apply", applyRecommendations)
+	w := httptest.NewRecorder()
+	body := `{"recommendation_ids":["rec-1"],"maintenance_window":{"timezone":"Mars/Olympus","ranges":[{"start":"01:00","end":"02:00"}]}}`
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/optimize/apply", bytes.NewBufferString(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
+
+// An apply moving a placement to a region without one of its required
+// frameworks is refused with the controls at stake unless overridden
+func TestApplyRecommendationsCompliance(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     map[string]interface{}
+		override   bool
+		wantStatus int
+	}{
+		{name: "blocked", target: map[string]interface{}{store.DetailTargetRegion: "us-east-1"}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "compliant target", target: map[string]interface{}{store.DetailTargetProvider: "gcp", store.DetailTargetRegion: "europe-west1"}, wantStatus: http.StatusOK},
+		{name: "same region", target: map[string]interface{}{}, wantStatus: http.StatusOK},
+		{name: "overridden", target: map[string]interface{}{store.DetailTargetRegion: "us-east-1"}, override: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prevRecs, prevApps, prevPlacements := recommendationStore, applicationStore, placementStore
+			recommendationStore, applicationStore, placementStore = store.NewRecommendationStore(), store.NewApplicationStore(), store.NewPlacementStore()
+			t.Cleanup(func() { recommendationStore, applicationStore, placementStore = prevRecs, prevApps, prevPlacements })
+
+			ctx := context.Background()
+			if err := placementStore.Save(ctx, &store.Placement{
+				ID: "plc-1", ResourceType: "compute", SelectedProvider: "aws", SelectedRegion: "eu-west-1",
+				Requirements: map[string]interface{}{"compliance_frameworks": []interface{}{"GDPR", "SOC2"}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			for _, rec := range []*store.Recommendation{
+				{ID: "rec-1", Type: "cost", Action: store.ActionMigrate, ResourceID: "plc-1", Details: tt.target},
+				{ID: "rec-2", Type: "cost", Action: store.ActionTag},
+			} {
+				if err := recommendationStore.Save(ctx, rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.POST("/optimize/apply", applyRecommendations)
+			w := httptest.NewRecorder()
+			body := fmt.Sprintf(`{"recommendation_ids":["rec-1","rec-2"],"override_compliance":%v}`, tt.override)
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/optimize/apply", bytes.NewBufferString(body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			applied := len(applicationStore.List(ctx))
+			if tt.wantStatus == http.StatusOK {
+				if applied != 2 {
+					t.Errorf("%d recommendations applied, want both", applied)
+				}
+				return
+			}
+			if applied != 0 {
+				t.Errorf("%d recommendations applied, want none", applied)
+			}
+
+			var resp struct {
+				Error struct {
+					Code    string `json:"code"`
+					Details struct {
+						Violations []ComplianceViolation `json:"violations"`
+					} `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			violations := resp.Error.Details.Violations
+			if resp.Error.Code != codeComplianceViolation || len(violations) != 1 {
+				t.Fatalf("error %+v, want one compliance violation", resp.Error)
+			}
+			v := violations[0]
+			if v.RecommendationID != "rec-1" || v.PlacementID != "plc-1" || v.Provider != "aws" || v.Region != "us-east-1" || v.Framework != "GDPR" || len(v.Controls) == 0 {
+				t.Errorf("violation %+v, want GDPR broken by moving plc-1 to aws/us-east-1 with its controls", v)
+			}
+		})
+	}
+}
+
+func TestGetRecommendationsOrdering(t *testing.T) {
+	prev := recommendationStore
+	recommendationStore = store.NewRecommendationStore()
+	t.Cleanup(func() { recommendationStore = prev })
+
+	created := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	for i, rec := range []*store.Recommendation{
+		{ID: "tag-60", Action: store.ActionTag, EstimatedSavings: 60},
+		{ID: "migrate-400", Action: store.ActionMigrate, EstimatedSavings: 400},
+		{ID: "resize-100", Action: store.ActionResize, EstimatedSavings: 100},
+		{ID: "tag-30", Action: store.ActionTag, EstimatedSavings: 30},
+	} {
+		rec.Type = "cost"
+		rec.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+		if err := recommendationStore.Save(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/recommendations", getRecommendations)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recommendations"+query, nil))
+		return w
+	}
+	ids := func(recs []*store.Recommendation) string {
+		names := make([]string, len(recs))
+		for i, rec := range recs {
+			names[i] = rec.ID
+		}
+		return strings.Join(names, " ")
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "", want: "tag-60 migrate-400 resize-100 tag-30"},
+		{query: "?sort=savings", want: "migrate-400 resize-100 tag-60 tag-30"},
+		// 60/1 ahead of 400/8 and 100/2, which tie and stay oldest first
+		{query: "?sort=savings_per_effort", want: "tag-60 migrate-400 resize-100 tag-30"},
+		{query: "?sort=effort", want: "tag-60 tag-30 resize-100 migrate-400"},
+	}
+	for _, tt := range tests {
+		w := get(tt.query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.query, w.Code, w.Body)
+		}
+		var recs []*store.Recommendation
+		if err := json.Unmarshal(w.Body.Bytes(), &recs); err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(recs); got != tt.want {
+			t.Errorf("%q: %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	w := get("?group_by=effort&sort=savings_per_effort")
+	if w.Code != http.StatusOK {
+		t.Fatalf("grouped: status %d: %s", w.Code, w.Body)
+	}
+	var grouped struct {
+		Groups []EffortGroup `json:"groups"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &grouped); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, g := range grouped.Groups {
+		got = append(got, fmt.Sprintf("%s %v: %s", g.Effort, g.EstimatedSavings, ids(g.Recommendations)))
+	}
+	want := []string{"trivial 90: tag-60 tag-30", "low 100: resize-100", "high 400: migrate-400"}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("groups %q, want %q", got, want)
+	}
+
+	for _, query := range []string{"?sort=risk", "?group_by=risk"} {
+		if w := get(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query, w.Code)
+		}
+	}
+}
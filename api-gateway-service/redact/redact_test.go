@@ -0,0 +1,73 @@
+package redact
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	r, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "top-level field", in: `{"username":"ann","password":"hunter2"}`, want: `{"password":"[REDACTED]","username":"ann"}`},
+		{name: "pattern match ignoring case", in: `{"Client_Secret":"s3cret","AccessToken":"t0k"}`, want: `{"AccessToken":"[REDACTED]","Client_Secret":"[REDACTED]"}`},
+		{name: "nested in arrays", in: `{"users":[{"email":"a@example.com","role":"admin"}]}`, want: `{"users":[{"email":"[REDACTED]","role":"admin"}]}`},
+		{name: "map keys", in: `{"tags":{"owner_email":"a@example.com","team":"web"}}`, want: `{"tags":{"owner_email":"[REDACTED]","team":"web"}}`},
+		{name: "numbers kept", in: `{"cost":1.50}`, want: `{"cost":1.50}`},
+		{name: "not JSON", in: `password=hunter2`, want: `"[REDACTED]"`},
+		{name: "empty", in: ``, want: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(r.JSON([]byte(tt.in))); got != tt.want {
+				t.Errorf("JSON(%s) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	r, err := New([]string{" Session* ", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Matches("session_id") || r.Matches("password") {
+		t.Error("configured patterns should replace the defaults")
+	}
+
+	if _, err := New([]string{"[unclosed"}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+// A logger using ReplaceAttr never writes the value of a matching attribute
+func TestReplaceAttr(t *testing.T) {
+	r, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: r.ReplaceAttr}))
+
+	logger.Info("login", slog.String("user", "ann"), slog.String("api_key", "k-123"), slog.Group("req", slog.String("authorization", "Bearer t0k")))
+
+	out := buf.String()
+	for _, secret := range []string{"k-123", "Bearer t0k"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log %q contains %q", out, secret)
+		}
+	}
+	if !strings.Contains(out, "user=ann") || !strings.Contains(out, "api_key="+Mask) {
+		t.Errorf("log %q, want other attributes kept and the key masked", out)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"api-gateway-service/store"
+	"api-gateway-service/tagging"
+)
+
+// TagRequest is the body accepted by the resource tagging endpoint. Tags are
+// canonical and are normalized to each resource provider's native form.
+type TagRequest struct {
+	ResourceIDs []string          `json:"resource_ids" binding:"required,min=1"`
+	Tags        map[string]string `json:"tags" binding:"required"`
+}
+
+// TagResult reports the native tags applied to one resource and the
+// canonical tags its provider cannot represent
+type TagResult struct {
+	ResourceID      string                    `json:"resource_id"`
+	Provider        string                    `json:"provider"`
+	Applied         map[string]string         `json:"applied"`
+	Unrepresentable []tagging.Unrepresentable `json:"unrepresentable"`
+}
+
+// ResourceImportRequest is the body accepted by the resource import endpoint
+type ResourceImportRequest struct {
+	Resources []ImportedResource `json:"resources" binding:"required,min=1,dive"`
+}
+
+// ImportedResource is one resource of an inventory import, as listed by a
+// provider's inventory export
+type ImportedResource struct {
+	ID           string             `json:"id" binding:"required"`
+	Name         string             `json:"name"`
+	Type         string             `json:"type" binding:"required"`
+	Provider     string             `json:"provider" binding:"required"`
+	Region       string             `json:"region" binding:"required"`
+	InstanceType string             `json:"instance_type"`
+	Tags         map[string]string  `json:"tags"`
+	Cost         float64            `json:"cost"`
+	Metrics      map[string]float64 `json:"metrics"`
+}
+
+// ResourceImportReport summarizes an inventory import
+type ResourceImportReport struct {
+	Imported int `json:"imported"`
+}
+
+// importResources adds resources to the caller's tenant inventory, replacing
+// those already imported with the same ID, so that they can be analyzed,
+// tagged and adopted as placements
+func importResources(c *gin.Context) {
+	var req ResourceImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	for _, r := range req.Resources {
+		resource := &store.Resource{
+			ID:           r.ID,
+			Name:         r.Name,
+			Type:         r.Type,
+			Provider:     r.Provider,
+			Region:       r.Region,
+			InstanceType: r.InstanceType,
+			Tags:         r.Tags,
+			Cost:         r.Cost,
+			Metrics:      r.Metrics,
+		}
+		if err := resourceStore.Save(ctx, resource); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, ResourceImportReport{Imported: len(req.Resources)})
+}
+
+func getResources(c *gin.Context) {
+	c.JSON(http.StatusOK, listResources(c.Request.Context(), c.Query("provider"), c.Query("region"), c.Query("type")))
+}
+
+// listResources returns the inventory resources matching the non-empty
+// provider, region and type filters
+func listResources(ctx context.Context, provider, region, resourceType string) []*store.Resource {
+	resources := make([]*store.Resource, 0)
+	for _, r := range resourceStore.List(ctx) {
+		if provider != "" && r.Provider != provider {
+			continue
+		}
+		if region != "" && r.Region != region {
+			continue
+		}
+		if resourceType != "" && r.Type != resourceType {
+			continue
+		}
+		resources = append(resources, r)
+	}
+	return resources
+}
+
+func getResource(c *gin.Context) {
+	r, err := resourceStore.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "resource not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, r)
+}
+
+// evaluateResource scores the resource's current deployment and lists
+// better-scoring alternatives of equivalent size
+func evaluateResource(c *gin.Context) {
+	r, err := resourceStore.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "resource not found")
+		return
+	}
+
+	eval, err := tenantEngine(c.Request.Context()).Evaluate(r.Provider, r.Region, r.InstanceType)
+	if err != nil {
+		respondError(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, eval)
+}
+
+// tagResources merges canonical tags into the tags of inventory resources,
+// normalized per provider. Tags a provider cannot represent are reported
+// rather than truncated; the rest are still applied.
+func tagResources(c *gin.Context) {
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	resources := make([]*store.Resource, len(req.ResourceIDs))
+	normalized := make([]*tagging.Result, len(req.ResourceIDs))
+	for i, id := range req.ResourceIDs {
+		r, err := resourceStore.Get(ctx, id)
+		if err != nil {
+			respondStoreError(c, err, "resource not found: "+id)
+			return
+		}
+
+		result, err := tagging.Normalize(r.Provider, req.Tags)
+		if err != nil {
+			respondError(c, http.StatusUnprocessableEntity, "resource "+id+": "+err.Error())
+			return
+		}
+		resources[i], normalized[i] = r, result
+	}
+
+	results := make([]TagResult, len(resources))
+	for i, r := range resources {
+		updated := *r
+		updated.Tags = make(map[string]string, len(r.Tags)+len(normalized[i].Tags))
+		for k, v := range r.Tags {
+			updated.Tags[k] = v
+		}
+		for k, v := range normalized[i].Tags {
+			updated.Tags[k] = v
+		}
+		if err := resourceStore.Save(ctx, &updated); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		results[i] = TagResult{
+			ResourceID:      r.ID,
+			Provider:        normalized[i].Provider,
+			Applied:         normalized[i].Tags,
+			Unrepresentable: normalized[i].Unrepresentable,
+		}
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"api-gateway-service/auth"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// inventoryRouter serves resource import and placement adoption as the
+// X-Tenant-ID header's tenant
+func inventoryRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	useInventory(t)
+	prev := placementStore
+	placementStore = store.NewPlacementStore()
+	t.Cleanup(func() { placementStore = prev })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.NewContext(c.Request.Context(), c.GetHeader("X-Tenant-ID")))
+	})
+	router.POST("/resources/import", importResources)
+	router.POST("/placements/:type/adopt", adoptPlacement)
+	return router
+}
+
+func postAs(router *gin.Engine, tenantID, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("X-Tenant-ID", tenantID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestImportResources(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       int
+	}{
+		{
+			name:       "resources",
+			body:       `{"resources":[{"id":"i-1","type":"compute","provider":"aws","region":"us-east-1","instance_type":"m5.large"},{"id":"b-1","type":"storage","provider":"aws","region":"us-east-1"}]}`,
+			wantStatus: http.StatusOK,
+			want:       2,
+		},
+		{name: "missing region", body: `{"resources":[{"id":"i-1","type":"compute","provider":"aws"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "no resources", body: `{"resources":[]}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := inventoryRouter(t)
+			w := postAs(router, "acme", "/resources/import", tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var report ResourceImportReport
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.Imported != tt.want {
+				t.Errorf("imported %d, want %d", report.Imported, tt.want)
+			}
+			if got := resourceStore.List(tenant.NewContext(context.Background(), "acme")); len(got) != tt.want {
+				t.Errorf("%d resources in the inventory, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+// An imported compute resource can be adopted by its own tenant only
+func TestImportThenAdoptPlacement(t *testing.T) {
+	router := inventoryRouter(t)
+	w := postAs(router, "acme", "/resources/import",
+		`{"resources":[{"id":"i-1","name":"web","type":"compute","provider":"aws","region":"us-east-1","instance_type":"m5.large"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status %d: %s", w.Code, w.Body)
+	}
+
+	adopt := `{"provider_resource_id":"i-1","resource_group":"web"}`
+	if w := postAs(router, "globex", "/placements/compute/adopt", adopt); w.Code != http.StatusNotFound {
+		t.Errorf("another tenant adopting got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w = postAs(router, "acme", "/placements/compute/adopt", adopt)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("adopt status %d: %s", w.Code, w.Body)
+	}
+	var p store.Placement
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.SelectedProvider != "aws" || p.SelectedRegion != "us-east-1" || p.InstanceType != "m5.large" || p.ResourceGroup != "web" {
+		t.Errorf("adopted placement %+v, want the imported deployment", p)
+	}
+
+	eval, err := tenantEngine(tenant.NewContext(context.Background(), "acme")).Evaluate("aws", "us-east-1", "m5.large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.EstimatedMonthlyCost != eval.Current.MonthlyCost || p.EstimatedMonthlyCost <= 0 {
+		t.Errorf("adopted placement costs %v, want the current deployment's %v", p.EstimatedMonthlyCost, eval.Current.MonthlyCost)
+	}
+	if len(p.Recommendations) == 0 {
+		t.Error("adopted placement has no alternatives")
+	}
+}
+
+// The CLI's analyze --alert-below reads the scores of the evaluation route and
+// runs the analyses of the analyze route; both are checked through the full
+// router with the request and field names the CLI uses
+func TestAnalyzeAlertContract(t *testing.T) {
+	useInventory(t)
+	keys := auth.NewMemoryAPIKeyStore()
+	if err := keys.AddAPIKey(&auth.APIKey{ID: "k1", Hash: auth.HashAPIKey(testGRPCAPIKey), Tier: "pro", TenantID: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	auth.SetAPIKeyStore(keys)
+	t.Cleanup(func() { auth.SetAPIKeyStore(nil) })
+	router := setupRouter()
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-API-Key", testGRPCAPIKey)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := call(http.MethodPost, "/api/v1/resources/import",
+		`{"resources":[{"id":"i-eu","type":"compute","provider":"aws","region":"eu-west-1","instance_type":"m5.xlarge"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status %d: %s", w.Code, w.Body)
+	}
+
+	w = call(http.MethodGet, "/api/v1/resources/i-eu/evaluation", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("evaluation status %d: %s", w.Code, w.Body)
+	}
+	var eval struct {
+		Current map[string]interface{} `json:"current"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &eval); err != nil {
+		t.Fatal(err)
+	}
+	for _, score := range []string{"total_score", "performance_score", "compliance_score"} {
+		v, ok := eval.Current[score].(float64)
+		if !ok || v < 0 || v > 1 {
+			t.Errorf("current %s = %v, want a score from 0 to 1", score, eval.Current[score])
+		}
+	}
+
+	w = call(http.MethodPost, "/api/v1/optimize/analyze",
+		`{"resource_ids":["i-eu"],"analysis_types":["cost","performance","compliance"],"compliance_frameworks":["FedRAMP"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("analyze status %d: %s", w.Code, w.Body)
+	}
+	var recs []map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &recs); err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 {
+		t.Errorf("%d recommendations, want one per analysis type: %s", len(recs), w.Body)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+	"api-gateway-service/cron"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// scheduleStore holds the analysis schedules; it is opened in main from
+// schedules.state_file
+var scheduleStore *store.ScheduleStore
+
+// ScheduleRequest is the body accepted by the create schedule endpoint
+type ScheduleRequest struct {
+	Name         string                   `json:"name" binding:"required"`
+	Cron         string                   `json:"cron" binding:"required"`
+	Timezone     string                   `json:"timezone"`
+	LookbackDays int                      `json:"lookback_days"`
+	Target       store.NotificationTarget `json:"target"`
+}
+
+// ScheduleNotification is the body POSTed to a schedule's webhook after each
+// run
+type ScheduleNotification struct {
+	ScheduleID   string             `json:"schedule_id"`
+	ScheduleName string             `json:"schedule_name"`
+	Run          *store.ScheduleRun `json:"run"`
+}
+
+func createSchedule(c *gin.Context) {
+	var req ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateScheduleTarget(c.Request.Context(), req.Target); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if req.LookbackDays < 0 {
+		respondValidationError(c, &FieldError{Field: "lookback_days", Message: "must not be negative"})
+		return
+	}
+	if req.LookbackDays == 0 {
+		req.LookbackDays = defaultCostPeriodDays
+	}
+
+	sched := &store.Schedule{
+		ID:           newID("sched"),
+		Name:         req.Name,
+		Cron:         req.Cron,
+		Timezone:     req.Timezone,
+		LookbackDays: req.LookbackDays,
+		Target:       req.Target,
+	}
+	next, err := nextScheduleRun(sched, time.Now().UTC())
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if next.IsZero() {
+		respondError(c, http.StatusBadRequest, fmt.Sprintf("cron expression %q never fires", req.Cron))
+		return
+	}
+	sched.NextRunAt = next
+
+	if err := scheduleStore.Save(c.Request.Context(), sched); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, sched)
+}
+
+func listSchedules(c *gin.Context) {
+	c.JSON(http.StatusOK, scheduleStore.List(c.Request.Context()))
+}
+
+func getSchedule(c *gin.Context) {
+	sched, err := scheduleStore.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "schedule not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, sched)
+}
+
+func deleteSchedule(c *gin.Context) {
+	if err := scheduleStore.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondStoreError(c, err, "schedule not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func listScheduleRuns(c *gin.Context) {
+	runs, err := scheduleStore.Runs(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "schedule not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+func getScheduleRun(c *gin.Context) {
+	run, err := scheduleStore.Run(c.Request.Context(), c.Param("id"), c.Param("run_id"))
+	if err != nil {
+		respondStoreError(c, err, "run not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// validateScheduleTarget checks that the target is a webhook with an
+// absolute http or https URL
+func validateScheduleTarget(ctx context.Context, target store.NotificationTarget) error {
+	if target.Type != "webhook" {
+		return fmt.Errorf("invalid target type: %q (must be webhook)", target.Type)
+	}
+	if err := validateWebhookURL(ctx, target.URL); err != nil {
+		return fmt.Errorf("invalid target url: %v", err)
+	}
+	return nil
+}
+
+// validateWebhookURL checks that raw is an absolute http or https URL whose
+// host resolves to public addresses only, unless
+// webhooks.allow_private_targets is set
+func validateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%q must be an http or https URL", raw)
+	}
+	return checkOutboundHost(ctx, u.Hostname())
+}
+
+// nextScheduleRun returns the schedule's first occurrence after t, in UTC.
+// The cron expression is evaluated in the schedule's timezone.
+func nextScheduleRun(sched *store.Schedule, t time.Time) (time.Time, error) {
+	expr, err := cron.Parse(sched.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc := time.UTC
+	if sched.Timezone != "" {
+		loc, err = time.LoadLocation(sched.Timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q: %v", sched.Timezone, err)
+		}
+	}
+	return expr.Next(t.In(loc)).UTC(), nil
+}
+
+// runSchedules starts the runs of due schedules, checking every interval
+// until ctx is done
+func runSchedules(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, due := range startDueSchedules(now.UTC()) {
+				go executeScheduleRun(ctx, due)
+			}
+		}
+	}
+}
+
+// startDueSchedules claims the schedules due at now and returns their runs
+func startDueSchedules(now time.Time) []*store.DueRun {
+	next := func(sched *store.Schedule) time.Time {
+		t, err := nextScheduleRun(sched, now)
+		if err != nil {
+			// Validated on create, so only a timezone removed from the
+			// system's database gets here; the schedule stops firing
+			log.Printf("Schedule %s will not run again: %v", sched.ID, err)
+		}
+		return t
+	}
+
+	due, err := scheduleStore.ClaimDue(now, next, func() string { return newID("run") })
+	if err != nil {
+		log.Printf("Failed to save schedules: %v", err)
+	}
+	return due
+}
+
+// executeScheduleRun analyzes the schedule's tenant, notifies its target and
+// records the outcome of the run
+func executeScheduleRun(ctx context.Context, due *store.DueRun) {
+	runCtx := tenant.NewContext(ctx, due.TenantID)
+	run := due.Run
+
+	run.Report = analyzeForSchedule(runCtx, due.Schedule, run.StartedAt)
+	run.Status = store.RunSucceeded
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+
+	if err := notifyScheduleTarget(runCtx, due.Schedule, run); err != nil {
+		run.NotificationError = err.Error()
+		log.Printf("Failed to notify target of schedule %s: %v", due.Schedule.ID, err)
+	} else {
+		notified := time.Now().UTC()
+		run.NotifiedAt = &notified
+	}
+
+	if err := scheduleStore.FinishRun(runCtx, run); err != nil {
+		log.Printf("Failed to save run %s of schedule %s: %v", run.ID, due.Schedule.ID, err)
+	}
+}
+
+// analyzeForSchedule reports the costs of the schedule's lookback period up
+// to the end of now's day and the open recommendations at now
+func analyzeForSchedule(ctx context.Context, sched *store.Schedule, now time.Time) *store.AnalysisReport {
+	end := now.Truncate(24*time.Hour).AddDate(0, 0, 1)
+	filter := cost.Filter{Start: end.AddDate(0, 0, -sched.LookbackDays), End: end}
+
+	// Grouping by provider cannot fail validation
+	summary, _ := costService.Summary(ctx, filter, cost.GroupByProvider)
+	report := &store.AnalysisReport{
+		PeriodStart:           filter.Start,
+		PeriodEnd:             filter.End,
+		TotalCost:             summary.TotalCost,
+		Currency:              summary.Currency,
+		RecommendationsByType: make(map[string]int),
+	}
+	for _, rec := range openRecommendations(ctx, now) {
+		report.OpenRecommendations++
+		report.EstimatedSavings += rec.EstimatedSavings
+		report.RecommendationsByType[rec.Type]++
+	}
+	return report
+}
+
+// notifyScheduleTarget POSTs the run to the schedule's webhook, failing on a
+// non-2xx response
+func notifyScheduleTarget(ctx context.Context, sched *store.Schedule, run *store.ScheduleRun) error {
+	body, err := json.Marshal(ScheduleNotification{
+		ScheduleID:   sched.ID,
+		ScheduleName: sched.Name,
+		Run:          run,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sched.Target.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := outboundClient(viper.GetDuration("schedules.notification_timeout")).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
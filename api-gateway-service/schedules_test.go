@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"api-gateway-service/cost"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// useScheduleStore replaces the schedule store with an in-memory one for the
+// test
+func useScheduleStore(t *testing.T) {
+	t.Helper()
+	schedules, err := store.NewScheduleStore("", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := scheduleStore
+	scheduleStore = schedules
+	t.Cleanup(func() { scheduleStore = prev })
+}
+
+// A due schedule starts one run that analyzes its tenant's costs, notifies
+// its webhook and is recorded as succeeded
+func TestScheduleDueRun(t *testing.T) {
+	useScheduleStore(t)
+	viper.Set("webhooks.allow_private_targets", true)
+	t.Cleanup(func() { viper.Set("webhooks.allow_private_targets", nil) })
+
+	now := time.Date(2026, 9, 16, 9, 0, 0, 0, time.UTC)
+	ctx := tenant.NewContext(context.Background(), "acme")
+
+	prevCosts := costService
+	costService = cost.NewService()
+	t.Cleanup(func() { costService = prevCosts })
+	if err := costService.Ingest(ctx, []cost.LineItem{
+		{Date: now.AddDate(0, 0, -2), Provider: "aws", Amount: 40, Currency: "USD"},
+		{Date: now.AddDate(0, 0, -20), Provider: "aws", Amount: 1000, Currency: "USD"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	notified := make(chan ScheduleNotification, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n ScheduleNotification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Error(err)
+		}
+		notified <- n
+	}))
+	defer srv.Close()
+
+	sched := &store.Schedule{
+		ID:           "sched-1",
+		Name:         "daily",
+		Cron:         "0 9 * * *",
+		LookbackDays: 7,
+		Target:       store.NotificationTarget{Type: "webhook", URL: srv.URL},
+		NextRunAt:    now,
+	}
+	if err := scheduleStore.Save(ctx, sched); err != nil {
+		t.Fatal(err)
+	}
+
+	if due := startDueSchedules(now.Add(-time.Minute)); len(due) != 0 {
+		t.Fatalf("%d runs started before the schedule was due", len(due))
+	}
+	due := startDueSchedules(now)
+	if len(due) != 1 || due[0].TenantID != "acme" || due[0].Run.Status != store.RunRunning {
+		t.Fatalf("due runs %+v, want one running run for acme", due)
+	}
+
+	executeScheduleRun(context.Background(), due[0])
+
+	n := <-notified
+	if n.ScheduleID != "sched-1" || n.Run == nil || n.Run.Report == nil || n.Run.Report.TotalCost != 40 {
+		t.Errorf("notification %+v, want the run with 7 days of costs", n)
+	}
+
+	runs, err := scheduleStore.Runs(ctx, "sched-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Status != store.RunSucceeded || runs[0].NotifiedAt == nil || runs[0].NotificationError != "" {
+		t.Fatalf("runs %+v, want one succeeded and notified run", runs)
+	}
+	got, err := scheduleStore.Get(ctx, "sched-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.AddDate(0, 0, 1); !got.NextRunAt.Equal(want) || got.LastRunAt == nil {
+		t.Errorf("schedule advanced to %v with last run %v, want %v", got.NextRunAt, got.LastRunAt, want)
+	}
+}
+
+// Ticks that overlap, or come due while the previous run is in progress,
+// never start a second run of the same schedule
+func TestScheduleOverlappingTicks(t *testing.T) {
+	useScheduleStore(t)
+
+	now := time.Date(2026, 9, 16, 9, 0, 0, 0, time.UTC)
+	ctx := tenant.NewContext(context.Background(), "acme")
+	if err := scheduleStore.Save(ctx, &store.Schedule{
+		ID:        "sched-1",
+		Cron:      "* * * * *",
+		Target:    store.NotificationTarget{Type: "webhook", URL: "http://example.invalid"},
+		NextRunAt: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var started []*store.DueRun
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			due := startDueSchedules(now)
+			mu.Lock()
+			started = append(started, due...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(started) != 1 {
+		t.Fatalf("overlapping ticks started %d runs, want 1", len(started))
+	}
+
+	// The next occurrence comes due while the first run is in progress
+	if due := startDueSchedules(now.Add(time.Minute)); len(due) != 0 {
+		t.Fatalf("tick during a run started %d runs, want 0", len(due))
+	}
+	got, err := scheduleStore.Get(ctx, "sched-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SkippedRuns != 1 {
+		t.Errorf("skipped runs %d, want 1", got.SkippedRuns)
+	}
+
+	run := started[0].Run
+	run.Status = store.RunSucceeded
+	if err := scheduleStore.FinishRun(ctx, run); err != nil {
+		t.Fatal(err)
+	}
+	if due := startDueSchedules(now.Add(2 * time.Minute)); len(due) != 1 {
+		t.Errorf("tick after the run finished started %d runs, want 1", len(due))
+	}
+}
@@ -0,0 +1,97 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// verify checks a signed response as a consumer would: decode it, drop the
+// signature field and verify the canonical serialization of the rest
+func verify(t *testing.T, data []byte, key ed25519.PublicKey) bool {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(obj[Field].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(obj, Field)
+	canonical, err := Canonical(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ed25519.Verify(key, canonical, sig)
+}
+
+func TestSign(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(private)
+
+	placement := struct {
+		ID        string  `json:"id"`
+		Name      string  `json:"name"`
+		Cost      float64 `json:"cost"`
+		Signature string  `json:"signature,omitempty"`
+	}{ID: "plc-1", Name: "R&D <eu>", Cost: 120.5, Signature: "stale"}
+
+	signed, err := signer.Sign(placement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verify(t, data, public) {
+		t.Fatalf("signature of %s does not verify", data)
+	}
+
+	tampered := strings.Replace(string(data), `"cost":120.5`, `"cost":12.5`, 1)
+	if tampered == string(data) {
+		t.Fatal("cost not found in the signed response")
+	}
+	if verify(t, []byte(tampered), public) {
+		t.Error("tampered response verifies")
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "sorted keys", in: `{"b": 1, "a": {"d": 2, "c": 3}}`, want: `{"a":{"c":3,"d":2},"b":1}`},
+		{name: "numbers as written", in: `{"cost": 1.50, "big": 1e21}`, want: `{"big":1e21,"cost":1.50}`},
+		{name: "HTML not escaped", in: `{"name": "R&D <eu>"}`, want: `{"name":"R&D <eu>"}`},
+		{name: "control characters escaped", in: `{"name": "a\tb"}`, want: `{"name":"a\tb"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := json.NewDecoder(strings.NewReader(tt.in))
+			dec.UseNumber()
+			var obj map[string]interface{}
+			if err := dec.Decode(&obj); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Canonical(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Canonical(%s) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
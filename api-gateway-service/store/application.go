@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// ApplicationStatus is the state of a recommendation application
+type ApplicationStatus string
+
+// Application statuses
+const (
+	ApplicationApplied   ApplicationStatus = "applied"
+	ApplicationScheduled ApplicationStatus = "scheduled"
+)
+
+// Application records a request to apply a recommendation. Disruptive
+// applications requested outside the maintenance window are scheduled for
+// the window's next opening.
+type Application struct {
+	ID               string            `json:"id"`
+	TenantID         string            `json:"-"`
+	RecommendationID string            `json:"recommendation_id"`
+	Action           string            `json:"action"`
+	Disruptive       bool              `json:"disruptive"`
+	Status           ApplicationStatus `json:"status"`
+	ScheduledFor     *time.Time        `json:"scheduled_for,omitempty"`
+	AppliedAt        *time.Time        `json:"applied_at,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+}
+
+// ApplicationStore holds recommendation applications in memory, partitioned
+// by tenant
+type ApplicationStore struct {
+	mu sync.RWMutex
+	// applications maps tenant ID to that tenant's applications by ID
+	applications map[string]map[string]*Application
+}
+
+// NewApplicationStore creates a new application store
+func NewApplicationStore() *ApplicationStore {
+	return &ApplicationStore{
+		applications: make(map[string]map[string]*Application),
+	}
+}
+
+// Save stores an application for the caller's tenant
+func (s *ApplicationStore) Save(ctx context.Context, a *Application) error {
+	if a.ID == "" {
+		return fmt.Errorf("application ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+
+	a.TenantID = tenant.FromContext(ctx)
+	if s.applications[a.TenantID] == nil {
+		s.applications[a.TenantID] = make(map[string]*Application)
+	}
+	s.applications[a.TenantID][a.ID] = a
+	return nil
+}
+
+// List returns the caller's tenant applications ordered by creation time
+func (s *ApplicationStore) List(ctx context.Context) []*Application {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantApps := s.applications[tenant.FromContext(ctx)]
+	apps := make([]*Application, 0, len(tenantApps))
+	for _, a := range tenantApps {
+		apps = append(apps, a)
+	}
+
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].CreatedAt.Equal(apps[j].CreatedAt) {
+			return apps[i].ID < apps[j].ID
+		}
+		return apps[i].CreatedAt.Before(apps[j].CreatedAt)
+	})
+	return apps
+}
+
+// MarkDueApplied marks every scheduled application of any tenant whose
+// scheduled time is at or before now as applied, and returns them
+func (s *ApplicationStore) MarkDueApplied(now time.Time) []*Application {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var applied []*Application
+	for _, tenantApps := range s.applications {
+		for _, a := range tenantApps {
+			if a.Status != ApplicationScheduled || a.ScheduledFor == nil || a.ScheduledFor.After(now) {
+				continue
+			}
+			appliedAt := now
+			a.Status = ApplicationApplied
+			a.AppliedAt = &appliedAt
+			applied = append(applied, a)
+		}
+	}
+	return applied
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// FeedbackAction is the user's response to a recommendation
+type FeedbackAction string
+
+const (
+	FeedbackAccepted FeedbackAction = "accepted"
+	FeedbackRejected FeedbackAction = "rejected"
+	FeedbackSnoozed  FeedbackAction = "snoozed"
+)
+
+// Feedback records a user's response to a single recommendation
+type Feedback struct {
+	RecommendationID   string         `json:"recommendation_id"`
+	RecommendationType string         `json:"recommendation_type"`
+	Action             FeedbackAction `json:"action"`
+	Reason             string         `json:"reason,omitempty"`
+	UserID             string         `json:"user_id,omitempty"`
+	SnoozedUntil       *time.Time     `json:"snoozed_until,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+}
+
+// AcceptanceRate summarizes feedback for a recommendation type
+type AcceptanceRate struct {
+	Type     string  `json:"type"`
+	Accepted int     `json:"accepted"`
+	Rejected int     `json:"rejected"`
+	Snoozed  int     `json:"snoozed"`
+	Total    int     `json:"total"`
+	Rate     float64 `json:"acceptance_rate"`
+}
+
+// FeedbackStore holds recommendation feedback in memory, partitioned by tenant
+type FeedbackStore struct {
+	mu sync.RWMutex
+	// latest holds the most recent feedback per tenant and recommendation
+	latest map[string]map[string]*Feedback
+	// history holds every feedback entry per tenant in the order it was recorded
+	history map[string][]*Feedback
+}
+
+// NewFeedbackStore creates a new feedback store
+func NewFeedbackStore() *FeedbackStore {
+	return &FeedbackStore{
+		latest:  make(map[string]map[string]*Feedback),
+		history: make(map[string][]*Feedback),
+	}
+}
+
+// Record stores feedback for a recommendation. Snoozed feedback is suppressed
+// until now+snoozePeriod.
+func (s *FeedbackStore) Record(ctx context.Context, fb *Feedback, snoozePeriod time.Duration) error {
+	switch fb.Action {
+	case FeedbackAccepted, FeedbackRejected:
+		fb.SnoozedUntil = nil
+	case FeedbackSnoozed:
+		if snoozePeriod <= 0 {
+			return fmt.Errorf("snooze period must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid feedback action: %s", fb.Action)
+	}
+
+	if fb.CreatedAt.IsZero() {
+		fb.CreatedAt = time.Now().UTC()
+	}
+	if fb.Action == FeedbackSnoozed {
+		until := fb.CreatedAt.Add(snoozePeriod)
+		fb.SnoozedUntil = &until
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	if s.latest[tenantID] == nil {
+		s.latest[tenantID] = make(map[string]*Feedback)
+	}
+	s.latest[tenantID][fb.RecommendationID] = fb
+	s.history[tenantID] = append(s.history[tenantID], fb)
+	return nil
+}
+
+// Get returns the most recent feedback for a recommendation
+func (s *FeedbackStore) Get(ctx context.Context, recommendationID string) (*Feedback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fb, exists := s.latest[tenant.FromContext(ctx)][recommendationID]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return fb, nil
+}
+
+// IsSnoozed reports whether the recommendation is currently snoozed
+func (s *FeedbackStore) IsSnoozed(ctx context.Context, recommendationID string, now time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fb, exists := s.latest[tenant.FromContext(ctx)][recommendationID]
+	if !exists || fb.Action != FeedbackSnoozed || fb.SnoozedUntil == nil {
+		return false
+	}
+	return now.Before(*fb.SnoozedUntil)
+}
+
+// AcceptanceRates aggregates the latest feedback per recommendation by
+// recommendation type. The acceptance rate is accepted / (accepted + rejected);
+// snoozed recommendations have not been decided and are excluded from it.
+func (s *FeedbackStore) AcceptanceRates(ctx context.Context) map[string]*AcceptanceRate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := make(map[string]*AcceptanceRate)
+	for _, fb := range s.latest[tenant.FromContext(ctx)] {
+		rate, exists := rates[fb.RecommendationType]
+		if !exists {
+			rate = &AcceptanceRate{Type: fb.RecommendationType}
+			rates[fb.RecommendationType] = rate
+		}
+
+		switch fb.Action {
+		case FeedbackAccepted:
+			rate.Accepted++
+		case FeedbackRejected:
+			rate.Rejected++
+		case FeedbackSnoozed:
+			rate.Snoozed++
+		}
+		rate.Total++
+	}
+
+	for _, rate := range rates {
+		if decided := rate.Accepted + rate.Rejected; decided > 0 {
+			rate.Rate = float64(rate.Accepted) / float64(decided)
+		}
+	}
+	return rates
+}
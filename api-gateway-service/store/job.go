@@ -0,0 +1,226 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"api-gateway-service/maintenance"
+	"api-gateway-service/tenant"
+)
+
+// ErrNotPendingApproval is returned when deciding on a job that is not
+// awaiting approval
+var ErrNotPendingApproval = errors.New("job is not pending approval")
+
+// JobStatus is the state of an apply job
+type JobStatus string
+
+// Job statuses
+const (
+	JobPendingApproval JobStatus = "pending_approval"
+	JobRunning         JobStatus = "running"
+	JobCompleted       JobStatus = "completed"
+	JobRejected        JobStatus = "rejected"
+	JobExpired         JobStatus = "expired"
+)
+
+// JobItemStatus is the state of one recommendation in an apply job
+type JobItemStatus string
+
+// Job item statuses
+const (
+	JobItemPending  JobItemStatus = "pending"
+	JobItemApplying JobItemStatus = "applying"
+	JobItemDone     JobItemStatus = "done"
+	JobItemFailed   JobItemStatus = "failed"
+)
+
+// JobItem tracks the application of one recommendation in an apply job
+type JobItem struct {
+	RecommendationID string        `json:"recommendation_id"`
+	Status           JobItemStatus `json:"status"`
+	ApplicationID    string        `json:"application_id,omitempty"`
+	Error            string        `json:"error,omitempty"`
+	// RequiresApproval marks items whose estimated impact put the job on hold
+	RequiresApproval bool `json:"requires_approval,omitempty"`
+}
+
+// Approval records who requested a gated apply job and who decided on it
+type Approval struct {
+	RequestedBy string     `json:"requested_by"`
+	RequestedAt time.Time  `json:"requested_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	DecidedBy   string     `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+}
+
+// ApplyJob applies a batch of recommendations in the background, one item at
+// a time. A job holding high-impact items waits in JobPendingApproval until
+// it is approved, rejected or expires.
+type ApplyJob struct {
+	ID                string              `json:"id"`
+	Status            JobStatus           `json:"status"`
+	Items             []JobItem           `json:"items"`
+	MaintenanceWindow *maintenance.Window `json:"maintenance_window,omitempty"`
+	Approval          *Approval           `json:"approval,omitempty"`
+	CreatedAt         time.Time           `json:"created_at"`
+	CompletedAt       *time.Time          `json:"completed_at,omitempty"`
+}
+
+// Finished reports whether the job will not change any more
+func (j *ApplyJob) Finished() bool {
+	return j.Status != JobPendingApproval && j.Status != JobRunning
+}
+
+// JobStore holds apply jobs in memory, partitioned by tenant
+type JobStore struct {
+	mu sync.RWMutex
+	// jobs maps tenant ID to that tenant's jobs by ID
+	jobs map[string]map[string]*ApplyJob
+}
+
+// NewJobStore creates a new job store
+func NewJobStore() *JobStore {
+	return &JobStore{
+		jobs: make(map[string]map[string]*ApplyJob),
+	}
+}
+
+// Save stores a job for the caller's tenant
+func (s *JobStore) Save(ctx context.Context, j *ApplyJob) error {
+	if j.ID == "" {
+		return fmt.Errorf("job ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j.CreatedAt.IsZero() {
+		j.CreatedAt = time.Now().UTC()
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	if s.jobs[tenantID] == nil {
+		s.jobs[tenantID] = make(map[string]*ApplyJob)
+	}
+	s.jobs[tenantID][j.ID] = j
+	return nil
+}
+
+// Get returns a snapshot of the caller's tenant job with the given ID
+func (s *JobStore) Get(ctx context.Context, id string) (*ApplyJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	j, exists := s.jobs[tenant.FromContext(ctx)][id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return j.snapshot(), nil
+}
+
+// snapshot returns a copy of the job that is safe to read without the lock
+func (j *ApplyJob) snapshot() *ApplyJob {
+	snapshot := *j
+	snapshot.Items = append([]JobItem(nil), j.Items...)
+	if j.Approval != nil {
+		approval := *j.Approval
+		snapshot.Approval = &approval
+	}
+	return &snapshot
+}
+
+// SetItem replaces item index of the caller's tenant job, keeping whether the
+// item required approval
+func (s *JobStore) SetItem(ctx context.Context, id string, index int, item JobItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, exists := s.jobs[tenant.FromContext(ctx)][id]
+	if !exists {
+		return ErrNotFound
+	}
+	if index < 0 || index >= len(j.Items) {
+		return fmt.Errorf("job %s has no item %d", id, index)
+	}
+
+	item.RequiresApproval = j.Items[index].RequiresApproval
+	j.Items[index] = item
+	return nil
+}
+
+// Complete marks the caller's tenant job as completed
+func (s *JobStore) Complete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, exists := s.jobs[tenant.FromContext(ctx)][id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	j.Status = JobCompleted
+	j.CompletedAt = &now
+	return nil
+}
+
+// Decide approves or rejects the caller's tenant job if it is pending
+// approval and has not expired at now. An approved job moves to JobRunning
+// and a rejected one to JobRejected; the updated job is returned. A job past
+// its expiry is marked expired and ErrNotPendingApproval is returned.
+func (s *JobStore) Decide(ctx context.Context, id string, approve bool, decidedBy, reason string, now time.Time) (*ApplyJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, exists := s.jobs[tenant.FromContext(ctx)][id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	if j.Status != JobPendingApproval {
+		return nil, ErrNotPendingApproval
+	}
+	if !now.Before(j.Approval.ExpiresAt) {
+		expire(j, now)
+		return nil, ErrNotPendingApproval
+	}
+
+	j.Approval.DecidedBy = decidedBy
+	j.Approval.DecidedAt = &now
+	j.Approval.Reason = reason
+	if approve {
+		j.Status = JobRunning
+	} else {
+		j.Status = JobRejected
+		j.CompletedAt = &now
+	}
+	return j.snapshot(), nil
+}
+
+// ExpirePending marks every tenant's jobs still pending approval at their
+// expiry as expired and returns them
+func (s *JobStore) ExpirePending(now time.Time) []*ApplyJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []*ApplyJob
+	for _, jobs := range s.jobs {
+		for _, j := range jobs {
+			if j.Status == JobPendingApproval && !now.Before(j.Approval.ExpiresAt) {
+				expire(j, now)
+				expired = append(expired, j.snapshot())
+			}
+		}
+	}
+	return expired
+}
+
+func expire(j *ApplyJob, now time.Time) {
+	j.Status = JobExpired
+	j.CompletedAt = &now
+}
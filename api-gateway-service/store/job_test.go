@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// pendingJob saves a job awaiting approval until expires
+func pendingJob(t *testing.T, s *JobStore, ctx context.Context, id string, expires time.Time) {
+	t.Helper()
+	job := &ApplyJob{
+		ID:       id,
+		Status:   JobPendingApproval,
+		Items:    []JobItem{{RecommendationID: "rec-1", Status: JobItemPending, RequiresApproval: true}},
+		Approval: &Approval{RequestedBy: "alice", RequestedAt: expires.Add(-time.Hour), ExpiresAt: expires},
+	}
+	if err := s.Save(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJobStoreDecide(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "acme")
+	now := time.Date(2026, 9, 16, 9, 0, 0, 0, time.UTC)
+	s := NewJobStore()
+	pendingJob(t, s, ctx, "job-approve", now.Add(time.Hour))
+	pendingJob(t, s, ctx, "job-reject", now.Add(time.Hour))
+	pendingJob(t, s, ctx, "job-late", now)
+
+	job, err := s.Decide(ctx, "job-approve", true, "bob", "within budget", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != JobRunning || job.Approval.DecidedBy != "bob" || job.Approval.Reason != "within budget" || job.CompletedAt != nil {
+		t.Errorf("approved job %+v, want it running and decided by bob", job)
+	}
+	if _, err := s.Decide(ctx, "job-approve", false, "carol", "", now); !errors.Is(err, ErrNotPendingApproval) {
+		t.Errorf("second decision error %v, want ErrNotPendingApproval", err)
+	}
+
+	job, err = s.Decide(ctx, "job-reject", false, "bob", "", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != JobRejected || job.CompletedAt == nil || !job.Finished() {
+		t.Errorf("rejected job %+v, want it finished as rejected", job)
+	}
+
+	if _, err := s.Decide(ctx, "job-late", true, "bob", "", now); !errors.Is(err, ErrNotPendingApproval) {
+		t.Errorf("decision at expiry error %v, want ErrNotPendingApproval", err)
+	}
+	if job, _ := s.Get(ctx, "job-late"); job.Status != JobExpired {
+		t.Errorf("job decided at expiry is %s, want expired", job.Status)
+	}
+
+	other := tenant.NewContext(context.Background(), "globex")
+	if _, err := s.Decide(other, "job-reject", true, "mallory", "", now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("decision by another tenant error %v, want ErrNotFound", err)
+	}
+}
+
+func TestJobStoreExpirePending(t *testing.T) {
+	acme := tenant.NewContext(context.Background(), "acme")
+	globex := tenant.NewContext(context.Background(), "globex")
+	now := time.Date(2026, 9, 16, 9, 0, 0, 0, time.UTC)
+	s := NewJobStore()
+	pendingJob(t, s, acme, "job-1", now.Add(time.Hour))
+	pendingJob(t, s, globex, "job-2", now.Add(2*time.Hour))
+	if err := s.Save(acme, &ApplyJob{ID: "job-3", Status: JobRunning}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expired := s.ExpirePending(now); len(expired) != 0 {
+		t.Fatalf("expired %d jobs before any expiry", len(expired))
+	}
+	expired := s.ExpirePending(now.Add(time.Hour))
+	if len(expired) != 1 || expired[0].ID != "job-1" || expired[0].Status != JobExpired || expired[0].CompletedAt == nil {
+		t.Fatalf("expired %+v, want only job-1", expired)
+	}
+	if job, _ := s.Get(globex, "job-2"); job.Status != JobPendingApproval {
+		t.Errorf("job-2 is %s, want it still pending", job.Status)
+	}
+	if job, _ := s.Get(acme, "job-3"); job.Status != JobRunning {
+		t.Errorf("running job-3 is %s, want it untouched", job.Status)
+	}
+	if expired := s.ExpirePending(now.Add(3 * time.Hour)); len(expired) != 1 || expired[0].ID != "job-2" {
+		t.Errorf("expired %+v, want job-2 once", expired)
+	}
+}
@@ -0,0 +1,449 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// Placement represents a resource placement decision
+type Placement struct {
+	ID                   string                 `json:"id"`
+	ResourceType         string                 `json:"resource_type"`
+	ResourceGroup        string                 `json:"resource_group,omitempty"`
+	Requirements         map[string]interface{} `json:"requirements,omitempty"`
+	SelectedProvider     string                 `json:"selected_provider"`
+	SelectedRegion       string                 `json:"selected_region"`
+	InstanceType         string                 `json:"instance_type,omitempty"`
+	EstimatedMonthlyCost float64                `json:"estimated_monthly_cost"`
+	CostBreakdown        map[string]float64     `json:"cost_breakdown,omitempty"`
+	PerformanceScore     float64                `json:"performance_score"`
+	ComplianceScore      float64                `json:"compliance_score"`
+	TotalScore           float64                `json:"total_score"`
+	SelectionReason      string                 `json:"selection_reason,omitempty"`
+	Recommendations      []Alternative          `json:"recommendations"`
+	RegionAllocations    []RegionAllocation     `json:"region_allocations,omitempty"`
+	AggregateMonthlyCost float64                `json:"aggregate_monthly_cost,omitempty"`
+	AchievedSLATier      string                 `json:"achieved_sla_tier,omitempty"`
+	Tags                 map[string]string      `json:"tags,omitempty"`
+	Affinity             []AffinityLink         `json:"affinity,omitempty"`
+	CreatedAt            time.Time              `json:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at"`
+	// DeletedAt is set while the placement is soft-deleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// PriceListDate is when the prices the estimate used were last updated;
+	// PricesStale is set when they were older than the staleness threshold
+	PriceListDate *time.Time `json:"price_list_date,omitempty"`
+	PricesStale   bool       `json:"prices_stale,omitempty"`
+	// DataTransferCost itemizes the expected data transfer cost included in
+	// EstimatedMonthlyCost
+	DataTransferCost *TransferCost `json:"data_transfer_cost,omitempty"`
+	// SelectedZones are the availability zones selected in SelectedRegion
+	// when the requirements set availability zones
+	SelectedZones []string `json:"selected_zones,omitempty"`
+	// NormalizedScores are set when scores are normalized; TotalScore is
+	// then computed from them rather than the raw scores
+	NormalizedScores *NormalizedScores `json:"normalized_scores,omitempty"`
+	// SelectedJurisdiction is the jurisdiction of SelectedRegion when the
+	// requirements set a data residency constraint
+	SelectedJurisdiction string `json:"selected_jurisdiction,omitempty"`
+	// Metadata is the caller's opaque metadata from the requirements
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// CurrentMonthlyCost is the requirements' cost of the workload today.
+	// EstimatedSavings is CurrentMonthlyCost less the placement's monthly
+	// cost, negative with CostIncrease set when the placement costs more;
+	// SavingsPercent is nil when the current cost is 0.
+	CurrentMonthlyCost *float64 `json:"current_monthly_cost,omitempty"`
+	EstimatedSavings   *float64 `json:"estimated_savings,omitempty"`
+	SavingsPercent     *float64 `json:"savings_percent,omitempty"`
+	CostIncrease       bool     `json:"cost_increase,omitempty"`
+	// CustomPricing is set when the costs were estimated with the tenant's
+	// negotiated pricing rather than list prices
+	CustomPricing bool `json:"custom_pricing,omitempty"`
+}
+
+// Alternative represents an alternative placement option
+type Alternative struct {
+	Provider         string   `json:"provider"`
+	Region           string   `json:"region"`
+	InstanceType     string   `json:"instance_type,omitempty"`
+	MonthlyCost      float64  `json:"monthly_cost"`
+	PerformanceScore float64  `json:"performance_score"`
+	ComplianceScore  float64  `json:"compliance_score"`
+	TotalScore       float64  `json:"total_score"`
+	RejectionReason  string   `json:"rejection_reason,omitempty"`
+	Zones            []string `json:"zones,omitempty"`
+	// NormalizedScores are set when scores are normalized
+	NormalizedScores *NormalizedScores `json:"normalized_scores,omitempty"`
+	Jurisdiction     string            `json:"jurisdiction,omitempty"`
+}
+
+// RegionAllocation is one region of a multi-region placement
+type RegionAllocation struct {
+	Provider     string   `json:"provider"`
+	Region       string   `json:"region"`
+	InstanceType string   `json:"instance_type,omitempty"`
+	Weight       float64  `json:"weight"`
+	MonthlyCost  float64  `json:"monthly_cost"`
+	Zones        []string `json:"zones,omitempty"`
+}
+
+// AffinityLink is the estimated latency and egress cost between a placement
+// and a related resource it was placed close to
+type AffinityLink struct {
+	Ref               string  `json:"ref"`
+	Provider          string  `json:"provider"`
+	Region            string  `json:"region"`
+	LatencyMs         float64 `json:"latency_ms"`
+	EgressMonthlyCost float64 `json:"egress_monthly_cost"`
+}
+
+// TransferCost is the monthly data transfer cost of a placement: outbound
+// traffic by destination class and inbound traffic in total
+type TransferCost struct {
+	IntraRegion float64 `json:"intra_region"`
+	InterRegion float64 `json:"inter_region"`
+	Internet    float64 `json:"internet"`
+	Inbound     float64 `json:"inbound"`
+}
+
+// NormalizedScores are the performance and compliance scores normalized
+// across the candidates a placement was chosen from
+type NormalizedScores struct {
+	Performance float64 `json:"performance"`
+	Compliance  float64 `json:"compliance"`
+}
+
+// PlacementVersion is a snapshot of a placement decision, recorded each time
+// the placement is saved. CostDelta is the change in estimated monthly cost
+// from the previous version and is zero for the first.
+type PlacementVersion struct {
+	Version              int       `json:"version"`
+	SelectedProvider     string    `json:"selected_provider"`
+	SelectedRegion       string    `json:"selected_region"`
+	InstanceType         string    `json:"instance_type,omitempty"`
+	EstimatedMonthlyCost float64   `json:"estimated_monthly_cost"`
+	AggregateMonthlyCost float64   `json:"aggregate_monthly_cost,omitempty"`
+	TotalScore           float64   `json:"total_score"`
+	CostDelta            float64   `json:"cost_delta"`
+	RecordedAt           time.Time `json:"recorded_at"`
+}
+
+// PlacementStore holds placements in memory, partitioned by tenant
+type PlacementStore struct {
+	mu sync.RWMutex
+	// placements maps tenant ID to that tenant's placements by ID
+	placements map[string]map[string]*Placement
+	// history maps tenant ID to the versions of each placement, oldest first
+	history map[string]map[string][]PlacementVersion
+}
+
+// NewPlacementStore creates a new placement store
+func NewPlacementStore() *PlacementStore {
+	return &PlacementStore{
+		placements: make(map[string]map[string]*Placement),
+		history:    make(map[string]map[string][]PlacementVersion),
+	}
+}
+
+// Save stores a copy of the placement for the caller's tenant, setting its
+// creation and update times on p
+func (s *PlacementStore) Save(ctx context.Context, p *Placement) error {
+	if p.ID == "" {
+		return fmt.Errorf("placement ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+	p.UpdatedAt = now
+
+	tenantID := tenant.FromContext(ctx)
+	if s.placements[tenantID] == nil {
+		s.placements[tenantID] = make(map[string]*Placement)
+		s.history[tenantID] = make(map[string][]PlacementVersion)
+	}
+	s.placements[tenantID][p.ID] = p.clone()
+	s.recordVersion(tenantID, p)
+	return nil
+}
+
+// clone returns a deep copy of the placement, so that neither the store nor
+// its callers see the other's changes to its maps, slices and pointers
+func (p *Placement) clone() *Placement {
+	c := *p
+	c.Requirements = copyRequirements(p.Requirements)
+	c.CostBreakdown = copyFloats(p.CostBreakdown)
+	c.Tags = copyStrings(p.Tags)
+	c.Metadata = copyStrings(p.Metadata)
+	c.SelectedZones = copyZones(p.SelectedZones)
+	if p.Recommendations != nil {
+		c.Recommendations = make([]Alternative, len(p.Recommendations))
+		for i, alt := range p.Recommendations {
+			alt.Zones = copyZones(alt.Zones)
+			alt.NormalizedScores = copyNormalizedScores(alt.NormalizedScores)
+			c.Recommendations[i] = alt
+		}
+	}
+	if p.RegionAllocations != nil {
+		c.RegionAllocations = make([]RegionAllocation, len(p.RegionAllocations))
+		for i, a := range p.RegionAllocations {
+			a.Zones = copyZones(a.Zones)
+			c.RegionAllocations[i] = a
+		}
+	}
+	if p.Affinity != nil {
+		c.Affinity = append([]AffinityLink(nil), p.Affinity...)
+	}
+	if p.DataTransferCost != nil {
+		transfer := *p.DataTransferCost
+		c.DataTransferCost = &transfer
+	}
+	c.NormalizedScores = copyNormalizedScores(p.NormalizedScores)
+	c.DeletedAt = copyTime(p.DeletedAt)
+	c.PriceListDate = copyTime(p.PriceListDate)
+	c.CurrentMonthlyCost = copyFloat(p.CurrentMonthlyCost)
+	c.EstimatedSavings = copyFloat(p.EstimatedSavings)
+	c.SavingsPercent = copyFloat(p.SavingsPercent)
+	return &c
+}
+
+func copyNormalizedScores(n *NormalizedScores) *NormalizedScores {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	return &c
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func copyFloat(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	return &c
+}
+
+func copyStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func copyFloats(m map[string]float64) map[string]float64 {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]float64, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func copyZones(zones []string) []string {
+	if zones == nil {
+		return nil
+	}
+	return append([]string(nil), zones...)
+}
+
+// copyRequirements copies the decoded JSON requirements, including nested
+// objects and arrays
+func copyRequirements(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		c[k] = copyJSONValue(v)
+	}
+	return c
+}
+
+func copyJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return copyRequirements(v)
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, e := range v {
+			c[i] = copyJSONValue(e)
+		}
+		return c
+	case []string:
+		return copyZones(v)
+	case map[string]string:
+		return copyStrings(v)
+	}
+	return v
+}
+
+// recordVersion appends a snapshot of the placement's decision to its history
+func (s *PlacementStore) recordVersion(tenantID string, p *Placement) {
+	versions := s.history[tenantID][p.ID]
+	v := PlacementVersion{
+		Version:              len(versions) + 1,
+		SelectedProvider:     p.SelectedProvider,
+		SelectedRegion:       p.SelectedRegion,
+		InstanceType:         p.InstanceType,
+		EstimatedMonthlyCost: p.EstimatedMonthlyCost,
+		AggregateMonthlyCost: p.AggregateMonthlyCost,
+		TotalScore:           p.TotalScore,
+		RecordedAt:           p.UpdatedAt,
+	}
+	if len(versions) > 0 {
+		v.CostDelta = p.EstimatedMonthlyCost - versions[len(versions)-1].EstimatedMonthlyCost
+	}
+	s.history[tenantID][p.ID] = append(versions, v)
+}
+
+// History returns the versions of the caller's tenant placement of the given
+// type and ID, oldest first
+func (s *PlacementStore) History(ctx context.Context, resourceType, id string) ([]PlacementVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+	p, exists := s.placements[tenantID][id]
+	if !exists || p.ResourceType != resourceType || p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+
+	versions := s.history[tenantID][id]
+	return append(make([]PlacementVersion, 0, len(versions)), versions...), nil
+}
+
+// Get returns a copy of the caller's tenant placement of the given type and
+// ID. Placements owned by other tenants or soft-deleted are reported as
+// ErrNotFound.
+func (s *PlacementStore) Get(ctx context.Context, resourceType, id string) (*Placement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, exists := s.placements[tenant.FromContext(ctx)][id]
+	if !exists || p.ResourceType != resourceType || p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return p.clone(), nil
+}
+
+// List returns copies of the caller's tenant placements of the given type, or
+// of every type when resourceType is empty, ordered by creation time. A
+// non-empty group lists only the placements of that resource group.
+// Soft-deleted placements are listed only when deleted is set, and then
+// exclusively.
+func (s *PlacementStore) List(ctx context.Context, resourceType, group string, deleted bool) []*Placement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	placements := make([]*Placement, 0)
+	for _, p := range s.placements[tenant.FromContext(ctx)] {
+		if resourceType != "" && p.ResourceType != resourceType {
+			continue
+		}
+		if group != "" && p.ResourceGroup != group {
+			continue
+		}
+		if (p.DeletedAt != nil) != deleted {
+			continue
+		}
+		placements = append(placements, p.clone())
+	}
+
+	sort.Slice(placements, func(i, j int) bool {
+		if placements[i].CreatedAt.Equal(placements[j].CreatedAt) {
+			return placements[i].ID < placements[j].ID
+		}
+		return placements[i].CreatedAt.Before(placements[j].CreatedAt)
+	})
+	return placements
+}
+
+// Delete removes the caller's tenant placement of the given type and ID
+func (s *PlacementStore) Delete(ctx context.Context, resourceType, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	p, exists := s.placements[tenantID][id]
+	if !exists || p.ResourceType != resourceType {
+		return ErrNotFound
+	}
+
+	delete(s.placements[tenantID], id)
+	delete(s.history[tenantID], id)
+	return nil
+}
+
+// SoftDelete marks the caller's tenant placement of the given type and ID as
+// deleted. It is hidden from Get and List until restored or purged.
+func (s *PlacementStore) SoftDelete(ctx context.Context, resourceType, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, exists := s.placements[tenant.FromContext(ctx)][id]
+	if !exists || p.ResourceType != resourceType || p.DeletedAt != nil {
+		return ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	p.DeletedAt = &now
+	return nil
+}
+
+// Restore undeletes the caller's tenant soft-deleted placement of the given
+// type and ID, returning a copy of it. Placements deleted before cutoff are
+// past retention and are reported as ErrNotFound.
+func (s *PlacementStore) Restore(ctx context.Context, resourceType, id string, cutoff time.Time) (*Placement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, exists := s.placements[tenant.FromContext(ctx)][id]
+	if !exists || p.ResourceType != resourceType || p.DeletedAt == nil || p.DeletedAt.Before(cutoff) {
+		return nil, ErrNotFound
+	}
+
+	p.DeletedAt = nil
+	return p.clone(), nil
+}
+
+// PurgeDeleted permanently removes placements of every tenant soft-deleted
+// before cutoff, returning how many were removed
+func (s *PlacementStore) PurgeDeleted(cutoff time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for tenantID, placements := range s.placements {
+		for id, p := range placements {
+			if p.DeletedAt != nil && p.DeletedAt.Before(cutoff) {
+				delete(placements, id)
+				delete(s.history[tenantID], id)
+				purged++
+			}
+		}
+	}
+	return purged
+}
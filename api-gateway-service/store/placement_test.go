@@ -0,0 +1,291 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+func newTestPlacementStore(t *testing.T, ctx context.Context) *PlacementStore {
+	t.Helper()
+	s := NewPlacementStore()
+	if err := s.Save(ctx, &Placement{ID: "plc-1", ResourceType: "compute", SelectedRegion: "us-east-1"}); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPlacementStoreReturnsCopies(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "t1")
+	tests := []struct {
+		name string
+		// modify changes a placement the store handed out, or passed to Save
+		modify func(t *testing.T, s *PlacementStore)
+	}{
+		{
+			name: "saved placement",
+			modify: func(t *testing.T, s *PlacementStore) {
+				p := &Placement{ID: "plc-1", ResourceType: "compute", SelectedRegion: "us-east-1"}
+				if err := s.Save(ctx, p); err != nil {
+					t.Fatal(err)
+				}
+				p.SelectedRegion = "changed"
+			},
+		},
+		{
+			name: "Get",
+			modify: func(t *testing.T, s *PlacementStore) {
+				p, err := s.Get(ctx, "compute", "plc-1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.SelectedRegion = "changed"
+			},
+		},
+		{
+			name: "List",
+			modify: func(t *testing.T, s *PlacementStore) {
+				s.List(ctx, "", "", false)[0].SelectedRegion = "changed"
+			},
+		},
+		{
+			name: "Restore",
+			modify: func(t *testing.T, s *PlacementStore) {
+				if err := s.SoftDelete(ctx, "compute", "plc-1"); err != nil {
+					t.Fatal(err)
+				}
+				p, err := s.Restore(ctx, "compute", "plc-1", time.Time{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.SelectedRegion = "changed"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPlacementStore(t, ctx)
+			tt.modify(t, s)
+
+			p, err := s.Get(ctx, "compute", "plc-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.SelectedRegion != "us-east-1" {
+				t.Errorf("stored region = %q, want us-east-1", p.SelectedRegion)
+			}
+		})
+	}
+}
+
+// fullPlacement returns a placement with every map, slice and pointer set
+func fullPlacement() *Placement {
+	savings := 25.0
+	return &Placement{
+		ID:                 "plc-2",
+		ResourceType:       "compute",
+		Requirements:       map[string]interface{}{"cpu": 2.0, "compliance": []interface{}{"soc2"}, "sla": map[string]interface{}{"tier": "gold"}},
+		CostBreakdown:      map[string]float64{"compute": 80},
+		Tags:               map[string]string{"team": "payments"},
+		Metadata:           map[string]string{"ticket": "OPS-1"},
+		SelectedZones:      []string{"us-east-1a"},
+		Recommendations:    []Alternative{{Provider: "gcp", Zones: []string{"us-east1-b"}, NormalizedScores: &NormalizedScores{Performance: 1}}},
+		RegionAllocations:  []RegionAllocation{{Region: "us-east-1", Zones: []string{"us-east-1a"}}},
+		Affinity:           []AffinityLink{{Ref: "db"}},
+		DataTransferCost:   &TransferCost{Internet: 5},
+		NormalizedScores:   &NormalizedScores{Performance: 0.5},
+		CurrentMonthlyCost: &savings,
+		EstimatedSavings:   &savings,
+	}
+}
+
+func TestPlacementStoreReturnsDeepCopies(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "t1")
+	tests := []struct {
+		name   string
+		modify func(p *Placement)
+	}{
+		{name: "tags", modify: func(p *Placement) { p.Tags["team"] = "changed" }},
+		{name: "metadata", modify: func(p *Placement) { p.Metadata["ticket"] = "changed" }},
+		{name: "cost breakdown", modify: func(p *Placement) { p.CostBreakdown["compute"] = 0 }},
+		{name: "requirements", modify: func(p *Placement) { p.Requirements["cpu"] = 64.0 }},
+		{name: "nested requirements", modify: func(p *Placement) {
+			p.Requirements["sla"].(map[string]interface{})["tier"] = "changed"
+			p.Requirements["compliance"].([]interface{})[0] = "changed"
+		}},
+		{name: "selected zones", modify: func(p *Placement) { p.SelectedZones[0] = "changed" }},
+		{name: "alternatives", modify: func(p *Placement) {
+			p.Recommendations[0].Zones[0] = "changed"
+			p.Recommendations[0].NormalizedScores.Performance = 0
+		}},
+		{name: "region allocations", modify: func(p *Placement) { p.RegionAllocations[0].Zones[0] = "changed" }},
+		{name: "affinity", modify: func(p *Placement) { p.Affinity[0].Ref = "changed" }},
+		{name: "pointers", modify: func(p *Placement) {
+			p.DataTransferCost.Internet = 0
+			p.NormalizedScores.Performance = 0
+			*p.EstimatedSavings = 0
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPlacementStore()
+			saved := fullPlacement()
+			if err := s.Save(ctx, saved); err != nil {
+				t.Fatal(err)
+			}
+			want, err := s.Get(ctx, "compute", "plc-2")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Change the placement passed to Save and each one handed out
+			tt.modify(saved)
+			got, err := s.Get(ctx, "compute", "plc-2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(got)
+			tt.modify(s.List(ctx, "compute", "", false)[0])
+
+			stored, err := s.Get(ctx, "compute", "plc-2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stored, want) {
+				t.Errorf("stored placement changed:\n got %+v\nwant %+v", stored, want)
+			}
+		})
+	}
+}
+
+func TestPlacementStoreSaveSetsTimes(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "t1")
+	s := NewPlacementStore()
+	p := &Placement{ID: "plc-1", ResourceType: "compute"}
+	if err := s.Save(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	if p.CreatedAt.IsZero() || p.UpdatedAt.IsZero() {
+		t.Errorf("Save left created_at %v, updated_at %v unset on the caller's placement", p.CreatedAt, p.UpdatedAt)
+	}
+}
+
+// Run with -race: placements handed out are read while others soft-delete
+// and restore them
+func TestPlacementStoreConcurrentAccess(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "t1")
+	s := newTestPlacementStore(t, ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if p, err := s.Get(ctx, "compute", "plc-1"); err == nil {
+					json.Marshal(p)
+				}
+				for _, p := range s.List(ctx, "", "", true) {
+					json.Marshal(p)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.SoftDelete(ctx, "compute", "plc-1")
+				if p, err := s.Restore(ctx, "compute", "plc-1", time.Time{}); err == nil {
+					json.Marshal(p)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Every save appends a version with the cost change from the one before
+func TestPlacementStoreHistory(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "t1")
+	s := NewPlacementStore()
+	for _, p := range []*Placement{
+		{ID: "plc-1", ResourceType: "compute", SelectedProvider: "aws", SelectedRegion: "us-east-1", EstimatedMonthlyCost: 100},
+		{ID: "plc-1", ResourceType: "compute", SelectedProvider: "gcp", SelectedRegion: "us-east1", EstimatedMonthlyCost: 80},
+		{ID: "plc-1", ResourceType: "compute", SelectedProvider: "gcp", SelectedRegion: "us-east1", EstimatedMonthlyCost: 95},
+	} {
+		if err := s.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := s.History(ctx, "compute", "plc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		provider string
+		cost     float64
+		delta    float64
+	}{{"aws", 100, 0}, {"gcp", 80, -20}, {"gcp", 95, 15}}
+	if len(versions) != len(want) {
+		t.Fatalf("%d versions, want %d", len(versions), len(want))
+	}
+	for i, v := range versions {
+		if v.Version != i+1 || v.SelectedProvider != want[i].provider || v.EstimatedMonthlyCost != want[i].cost || v.CostDelta != want[i].delta {
+			t.Errorf("version %d = %+v, want %+v", i+1, v, want[i])
+		}
+		if i > 0 && v.RecordedAt.Before(versions[i-1].RecordedAt) {
+			t.Errorf("version %d recorded before version %d", i+1, i)
+		}
+	}
+
+	if _, err := s.History(ctx, "storage", "plc-1"); err != ErrNotFound {
+		t.Errorf("History of the wrong type = %v, want ErrNotFound", err)
+	}
+	if _, err := s.History(tenant.NewContext(context.Background(), "t2"), "compute", "plc-1"); err != ErrNotFound {
+		t.Errorf("History from another tenant = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPlacementStoreListByGroup(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "t1")
+	s := NewPlacementStore()
+	for _, p := range []*Placement{
+		{ID: "plc-1", ResourceType: "compute", ResourceGroup: "web"},
+		{ID: "plc-2", ResourceType: "storage", ResourceGroup: "web"},
+		{ID: "plc-3", ResourceType: "compute", ResourceGroup: "data"},
+		{ID: "plc-4", ResourceType: "compute"},
+	} {
+		if err := s.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Save(tenant.NewContext(context.Background(), "t2"), &Placement{ID: "plc-5", ResourceType: "compute", ResourceGroup: "web"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		resourceType, group string
+		want                []string
+	}{
+		{group: "web", want: []string{"plc-1", "plc-2"}},
+		{resourceType: "compute", group: "web", want: []string{"plc-1"}},
+		{resourceType: "compute", want: []string{"plc-1", "plc-3", "plc-4"}},
+		{group: "missing"},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, p := range s.List(ctx, tt.resourceType, tt.group, false) {
+			got = append(got, p.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("List(%q, %q) = %v, want %v", tt.resourceType, tt.group, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// Recommendation represents an optimization recommendation produced by an analysis
+type Recommendation struct {
+	ID                   string                 `json:"id"`
+	Type                 string                 `json:"type"`
+	Action               string                 `json:"action,omitempty"`
+	Priority             string                 `json:"priority"`
+	ResourceID           string                 `json:"resource_id,omitempty"`
+	Description          string                 `json:"description"`
+	EstimatedSavings     float64                `json:"estimated_savings"`
+	ImplementationEffort string                 `json:"implementation_effort,omitempty"`
+	Details              map[string]interface{} `json:"details,omitempty"`
+	CreatedAt            time.Time              `json:"created_at"`
+	// Effort is the remediation effort and Risk the risk of applying the
+	// recommendation; when not set they are derived from the implementation
+	// effort and action
+	Effort string `json:"effort,omitempty"`
+	Risk   string `json:"risk,omitempty"`
+}
+
+// Recommendation actions. Tagging changes only metadata; every other action,
+// including an unspecified one, is treated as disruptive.
+const (
+	ActionTag       = "tag"
+	ActionResize    = "resize"
+	ActionMigrate   = "migrate"
+	ActionTerminate = "terminate"
+)
+
+// IsDisruptive reports whether applying the recommendation may interrupt the
+// resource and so must wait for a maintenance window
+func (r *Recommendation) IsDisruptive() bool {
+	return r.Action != ActionTag
+}
+
+// Remediation effort levels, from least to most work
+const (
+	EffortTrivial = "trivial"
+	EffortLow     = "low"
+	EffortMedium  = "medium"
+	EffortHigh    = "high"
+)
+
+// EffortLevels lists the effort levels from least to most work
+var EffortLevels = []string{EffortTrivial, EffortLow, EffortMedium, EffortHigh}
+
+// effortWeights is the relative work of each effort level; savings are
+// divided by it to rank quick wins
+var effortWeights = map[string]float64{
+	EffortTrivial: 1,
+	EffortLow:     2,
+	EffortMedium:  4,
+	EffortHigh:    8,
+}
+
+// Risk levels of applying a recommendation
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+)
+
+// actionAssessments is the default effort and risk of each action
+var actionAssessments = map[string][2]string{
+	ActionTag:       {EffortTrivial, RiskLow},
+	ActionResize:    {EffortLow, RiskMedium},
+	ActionTerminate: {EffortMedium, RiskHigh},
+	ActionMigrate:   {EffortHigh, RiskHigh},
+}
+
+// implementationEfforts maps the analysis' implementation effort to an effort
+// level
+var implementationEfforts = map[string]string{
+	"easy":    EffortLow,
+	"medium":  EffortMedium,
+	"complex": EffortHigh,
+}
+
+// SavingsPerEffort is the estimated savings divided by the relative work of
+// the recommendation's effort level
+func (r *Recommendation) SavingsPerEffort() float64 {
+	weight, ok := effortWeights[r.Effort]
+	if !ok {
+		weight = effortWeights[EffortMedium]
+	}
+	return r.EstimatedSavings / weight
+}
+
+// assess validates the recommendation's effort and risk, filling in those
+// not set from the implementation effort and action. An unknown action is
+// assessed as medium effort and risk.
+func (r *Recommendation) assess() error {
+	assessment, ok := actionAssessments[r.Action]
+	if !ok {
+		assessment = [2]string{EffortMedium, RiskMedium}
+	}
+	if r.Effort == "" {
+		r.Effort = implementationEfforts[r.ImplementationEffort]
+	}
+	if r.Effort == "" {
+		r.Effort = assessment[0]
+	}
+	if r.Risk == "" {
+		r.Risk = assessment[1]
+	}
+
+	if _, ok := effortWeights[r.Effort]; !ok {
+		return fmt.Errorf("invalid effort %q: must be trivial, low, medium or high", r.Effort)
+	}
+	switch r.Risk {
+	case RiskLow, RiskMedium, RiskHigh:
+		return nil
+	}
+	return fmt.Errorf("invalid risk %q: must be low, medium or high", r.Risk)
+}
+
+// Details keys naming where a recommendation moves its resource
+const (
+	DetailTargetProvider = "target_provider"
+	DetailTargetRegion   = "target_region"
+)
+
+// Target returns the provider and region the recommendation moves its
+// resource to, each empty when it does not change
+func (r *Recommendation) Target() (provider, region string) {
+	provider, _ = r.Details[DetailTargetProvider].(string)
+	region, _ = r.Details[DetailTargetRegion].(string)
+	return provider, region
+}
+
+// RecommendationStore holds recommendations in memory, partitioned by tenant
+type RecommendationStore struct {
+	mu sync.RWMutex
+	// recommendations maps tenant ID to that tenant's recommendations by ID
+	recommendations map[string]map[string]*Recommendation
+}
+
+// NewRecommendationStore creates a new recommendation store
+func NewRecommendationStore() *RecommendationStore {
+	return &RecommendationStore{
+		recommendations: make(map[string]map[string]*Recommendation),
+	}
+}
+
+// Save stores a recommendation for the caller's tenant, replacing any existing
+// one with the same ID
+func (s *RecommendationStore) Save(ctx context.Context, rec *Recommendation) error {
+	if rec.ID == "" {
+		return fmt.Errorf("recommendation ID is required")
+	}
+	if err := rec.assess(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now().UTC()
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	if s.recommendations[tenantID] == nil {
+		s.recommendations[tenantID] = make(map[string]*Recommendation)
+	}
+	s.recommendations[tenantID][rec.ID] = rec
+	return nil
+}
+
+// Get returns the caller's tenant recommendation with the given ID
+func (s *RecommendationStore) Get(ctx context.Context, id string) (*Recommendation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, exists := s.recommendations[tenant.FromContext(ctx)][id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return rec, nil
+}
+
+// List returns the caller's tenant recommendations ordered by creation time
+func (s *RecommendationStore) List(ctx context.Context) []*Recommendation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantRecs := s.recommendations[tenant.FromContext(ctx)]
+	recs := make([]*Recommendation, 0, len(tenantRecs))
+	for _, rec := range tenantRecs {
+		recs = append(recs, rec)
+	}
+
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].CreatedAt.Equal(recs[j].CreatedAt) {
+			return recs[i].ID < recs[j].ID
+		}
+		return recs[i].CreatedAt.Before(recs[j].CreatedAt)
+	})
+	return recs
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRecommendationStoreAssess(t *testing.T) {
+	tests := []struct {
+		name       string
+		rec        Recommendation
+		wantEffort string
+		wantRisk   string
+		wantErr    bool
+	}{
+		{name: "tag", rec: Recommendation{Action: ActionTag}, wantEffort: EffortTrivial, wantRisk: RiskLow},
+		{name: "migrate", rec: Recommendation{Action: ActionMigrate}, wantEffort: EffortHigh, wantRisk: RiskHigh},
+		{name: "implementation effort", rec: Recommendation{Action: ActionMigrate, ImplementationEffort: "easy"}, wantEffort: EffortLow, wantRisk: RiskHigh},
+		{name: "given", rec: Recommendation{Action: ActionResize, Effort: EffortTrivial, Risk: RiskLow}, wantEffort: EffortTrivial, wantRisk: RiskLow},
+		{name: "unknown action", rec: Recommendation{Action: "archive"}, wantEffort: EffortMedium, wantRisk: RiskMedium},
+		{name: "invalid effort", rec: Recommendation{Action: ActionTag, Effort: "huge"}, wantErr: true},
+		{name: "invalid risk", rec: Recommendation{Action: ActionTag, Risk: "none"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewRecommendationStore()
+			rec := tt.rec
+			rec.ID = "rec-1"
+			err := s.Save(context.Background(), &rec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Save() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if _, err := s.Get(context.Background(), "rec-1"); err == nil {
+					t.Error("invalid recommendation was saved")
+				}
+				return
+			}
+			if rec.Effort != tt.wantEffort || rec.Risk != tt.wantRisk {
+				t.Errorf("effort %s risk %s, want %s %s", rec.Effort, rec.Risk, tt.wantEffort, tt.wantRisk)
+			}
+		})
+	}
+}
+
+func TestRecommendationSavingsPerEffort(t *testing.T) {
+	tests := []struct {
+		effort string
+		want   float64
+	}{
+		{effort: EffortTrivial, want: 80},
+		{effort: EffortLow, want: 40},
+		{effort: EffortMedium, want: 20},
+		{effort: EffortHigh, want: 10},
+		{effort: "", want: 20},
+	}
+
+	for _, tt := range tests {
+		rec := Recommendation{Effort: tt.effort, EstimatedSavings: 80}
+		if got := rec.SavingsPerEffort(); got != tt.want {
+			t.Errorf("%q effort: %v, want %v", tt.effort, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// Resource represents a cloud resource discovered by a provider scan
+type Resource struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Type         string             `json:"type"`
+	Provider     string             `json:"provider"`
+	Region       string             `json:"region"`
+	InstanceType string             `json:"instance_type,omitempty"`
+	Tags         map[string]string  `json:"tags,omitempty"`
+	Cost         float64            `json:"cost"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"`
+	DiscoveredAt time.Time          `json:"discovered_at"`
+}
+
+// ResourceStore holds the scanned resource inventory in memory, partitioned
+// by tenant
+type ResourceStore struct {
+	mu sync.RWMutex
+	// resources maps tenant ID to that tenant's resources by ID
+	resources map[string]map[string]*Resource
+}
+
+// NewResourceStore creates a new resource store
+func NewResourceStore() *ResourceStore {
+	return &ResourceStore{
+		resources: make(map[string]map[string]*Resource),
+	}
+}
+
+// Save stores a resource for the caller's tenant
+func (s *ResourceStore) Save(ctx context.Context, r *Resource) error {
+	if r.ID == "" {
+		return fmt.Errorf("resource ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.DiscoveredAt.IsZero() {
+		r.DiscoveredAt = time.Now().UTC()
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	if s.resources[tenantID] == nil {
+		s.resources[tenantID] = make(map[string]*Resource)
+	}
+	s.resources[tenantID][r.ID] = r
+	return nil
+}
+
+// Get returns the caller's tenant resource with the given provider resource ID
+func (s *ResourceStore) Get(ctx context.Context, id string) (*Resource, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, exists := s.resources[tenant.FromContext(ctx)][id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return r, nil
+}
+
+// List returns the caller's tenant resources ordered by ID
+func (s *ResourceStore) List(ctx context.Context) []*Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantResources := s.resources[tenant.FromContext(ctx)]
+	resources := make([]*Resource, 0, len(tenantResources))
+	for _, r := range tenantResources {
+		resources = append(resources, r)
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID < resources[j].ID
+	})
+	return resources
+}
@@ -0,0 +1,377 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// RunStatus is the state of a scheduled analysis run
+type RunStatus string
+
+// Run statuses
+const (
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+)
+
+// NotificationTarget receives the report of each run. Only webhooks are
+// supported: the report is POSTed to URL as JSON.
+type NotificationTarget struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Schedule runs an optimization analysis on a cron schedule and sends the
+// report to its target
+type Schedule struct {
+	ID           string             `json:"id"`
+	TenantID     string             `json:"-"`
+	Name         string             `json:"name"`
+	Cron         string             `json:"cron"`
+	Timezone     string             `json:"timezone,omitempty"`
+	LookbackDays int                `json:"lookback_days"`
+	Target       NotificationTarget `json:"target"`
+	NextRunAt    time.Time          `json:"next_run_at"`
+	LastRunAt    *time.Time         `json:"last_run_at,omitempty"`
+	// SkippedRuns counts occurrences that came due while a run was still in
+	// progress
+	SkippedRuns int       `json:"skipped_runs"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// activeRun is the ID of the run in progress, if any
+	activeRun string
+}
+
+// AnalysisReport summarizes costs and open recommendations at the time of a
+// run
+type AnalysisReport struct {
+	PeriodStart           time.Time      `json:"period_start"`
+	PeriodEnd             time.Time      `json:"period_end"`
+	TotalCost             float64        `json:"total_cost"`
+	Currency              string         `json:"currency"`
+	OpenRecommendations   int            `json:"open_recommendations"`
+	EstimatedSavings      float64        `json:"estimated_savings"`
+	RecommendationsByType map[string]int `json:"recommendations_by_type"`
+}
+
+// ScheduleRun is one execution of a schedule
+type ScheduleRun struct {
+	ID                string          `json:"id"`
+	ScheduleID        string          `json:"schedule_id"`
+	Status            RunStatus       `json:"status"`
+	StartedAt         time.Time       `json:"started_at"`
+	FinishedAt        *time.Time      `json:"finished_at,omitempty"`
+	Report            *AnalysisReport `json:"report,omitempty"`
+	Error             string          `json:"error,omitempty"`
+	NotifiedAt        *time.Time      `json:"notified_at,omitempty"`
+	NotificationError string          `json:"notification_error,omitempty"`
+}
+
+// DueRun is a run started by ClaimDue
+type DueRun struct {
+	TenantID string
+	Schedule *Schedule
+	Run      *ScheduleRun
+}
+
+// ScheduleStore holds schedules and their recent runs, partitioned by tenant.
+// When created with a path every change is written to that file, so
+// schedules and run history survive a restart.
+type ScheduleStore struct {
+	mu sync.RWMutex
+	// schedules maps tenant ID to that tenant's schedules by ID
+	schedules map[string]map[string]*Schedule
+	// runs maps tenant ID to schedule ID to its runs, oldest first
+	runs map[string]map[string][]*ScheduleRun
+
+	path         string
+	historyLimit int
+}
+
+// scheduleState is the file format of a persisted ScheduleStore
+type scheduleState struct {
+	Schedules map[string]map[string]*Schedule      `json:"schedules"`
+	Runs      map[string]map[string][]*ScheduleRun `json:"runs"`
+}
+
+// NewScheduleStore creates a schedule store keeping the last historyLimit
+// runs of each schedule. With a non-empty path the store is loaded from that
+// file if it exists and saved to it on every change. Runs that were still in
+// progress when the state was saved are marked failed.
+func NewScheduleStore(path string, historyLimit int) (*ScheduleStore, error) {
+	s := &ScheduleStore{
+		schedules:    make(map[string]map[string]*Schedule),
+		runs:         make(map[string]map[string][]*ScheduleRun),
+		path:         path,
+		historyLimit: historyLimit,
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %v", err)
+	}
+
+	var state scheduleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules %s: %v", path, err)
+	}
+	for tenantID, schedules := range state.Schedules {
+		for _, sched := range schedules {
+			sched.TenantID = tenantID
+		}
+		s.schedules[tenantID] = schedules
+	}
+	for tenantID, runs := range state.Runs {
+		for _, scheduleRuns := range runs {
+			for _, run := range scheduleRuns {
+				if run.Status == RunRunning {
+					finished := time.Now().UTC()
+					run.Status = RunFailed
+					run.Error = "interrupted by a restart"
+					run.FinishedAt = &finished
+				}
+			}
+		}
+		s.runs[tenantID] = runs
+	}
+	return s, nil
+}
+
+// Save stores a new schedule for the caller's tenant
+func (s *ScheduleStore) Save(ctx context.Context, sched *Schedule) error {
+	if sched.ID == "" {
+		return fmt.Errorf("schedule ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sched.CreatedAt.IsZero() {
+		sched.CreatedAt = time.Now().UTC()
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	sched.TenantID = tenantID
+	if s.schedules[tenantID] == nil {
+		s.schedules[tenantID] = make(map[string]*Schedule)
+	}
+	s.schedules[tenantID][sched.ID] = sched
+	return s.persist()
+}
+
+// Get returns a copy of the caller's tenant schedule with the given ID
+func (s *ScheduleStore) Get(ctx context.Context, id string) (*Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sched, exists := s.schedules[tenant.FromContext(ctx)][id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	copied := *sched
+	return &copied, nil
+}
+
+// List returns copies of the caller's tenant schedules, oldest first
+func (s *ScheduleStore) List(ctx context.Context) []*Schedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schedules := make([]*Schedule, 0, len(s.schedules[tenant.FromContext(ctx)]))
+	for _, sched := range s.schedules[tenant.FromContext(ctx)] {
+		copied := *sched
+		schedules = append(schedules, &copied)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if !schedules[i].CreatedAt.Equal(schedules[j].CreatedAt) {
+			return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+	return schedules
+}
+
+// Delete removes the caller's tenant schedule and its runs. A run in progress
+// finishes but is not recorded.
+func (s *ScheduleStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	if _, exists := s.schedules[tenantID][id]; !exists {
+		return ErrNotFound
+	}
+	delete(s.schedules[tenantID], id)
+	delete(s.runs[tenantID], id)
+	return s.persist()
+}
+
+// Runs returns copies of the recorded runs of the caller's tenant schedule,
+// newest first
+func (s *ScheduleStore) Runs(ctx context.Context, scheduleID string) ([]*ScheduleRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantID := tenant.FromContext(ctx)
+	if _, exists := s.schedules[tenantID][scheduleID]; !exists {
+		return nil, ErrNotFound
+	}
+
+	stored := s.runs[tenantID][scheduleID]
+	runs := make([]*ScheduleRun, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		copied := *stored[i]
+		runs = append(runs, &copied)
+	}
+	return runs, nil
+}
+
+// Run returns a copy of one run of the caller's tenant schedule
+func (s *ScheduleStore) Run(ctx context.Context, scheduleID, runID string) (*ScheduleRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, run := range s.runs[tenant.FromContext(ctx)][scheduleID] {
+		if run.ID == runID {
+			copied := *run
+			return &copied, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ClaimDue starts a run of every schedule of any tenant whose next run is at
+// or before now and advances it to next(schedule). Claiming and advancing
+// happen under one lock, so overlapping calls never start the same
+// occurrence twice, and a schedule whose previous run is still in progress
+// skips the occurrence instead of starting a second run. Occurrences missed
+// while the gateway was down collapse into a single run. newRunID supplies
+// the ID of each started run.
+//
+// The returned runs are in progress; finish each with FinishRun. An error
+// means the new state could not be saved; the runs were still started.
+func (s *ScheduleStore) ClaimDue(now time.Time, next func(*Schedule) time.Time, newRunID func() string) ([]*DueRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []*DueRun
+	changed := false
+	for tenantID, schedules := range s.schedules {
+		for _, sched := range schedules {
+			if sched.NextRunAt.IsZero() || sched.NextRunAt.After(now) {
+				continue
+			}
+			changed = true
+			sched.NextRunAt = next(sched)
+			if sched.activeRun != "" {
+				sched.SkippedRuns++
+				continue
+			}
+
+			run := &ScheduleRun{
+				ID:         newRunID(),
+				ScheduleID: sched.ID,
+				Status:     RunRunning,
+				StartedAt:  now,
+			}
+			sched.activeRun = run.ID
+			s.appendRun(tenantID, run)
+
+			schedCopy, runCopy := *sched, *run
+			due = append(due, &DueRun{TenantID: tenantID, Schedule: &schedCopy, Run: &runCopy})
+		}
+	}
+	if !changed {
+		return nil, nil
+	}
+	return due, s.persist()
+}
+
+// FinishRun records the outcome of a run started by ClaimDue for the
+// caller's tenant. It is a no-op if the schedule has since been deleted.
+func (s *ScheduleStore) FinishRun(ctx context.Context, run *ScheduleRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	sched, exists := s.schedules[tenantID][run.ScheduleID]
+	if !exists {
+		return nil
+	}
+
+	for i, stored := range s.runs[tenantID][run.ScheduleID] {
+		if stored.ID == run.ID {
+			copied := *run
+			s.runs[tenantID][run.ScheduleID][i] = &copied
+			break
+		}
+	}
+	if sched.activeRun == run.ID {
+		sched.activeRun = ""
+	}
+	startedAt := run.StartedAt
+	sched.LastRunAt = &startedAt
+	return s.persist()
+}
+
+// appendRun records a run, dropping the oldest beyond the history limit
+func (s *ScheduleStore) appendRun(tenantID string, run *ScheduleRun) {
+	if s.runs[tenantID] == nil {
+		s.runs[tenantID] = make(map[string][]*ScheduleRun)
+	}
+	runs := append(s.runs[tenantID][run.ScheduleID], run)
+	if s.historyLimit > 0 && len(runs) > s.historyLimit {
+		runs = runs[len(runs)-s.historyLimit:]
+	}
+	s.runs[tenantID][run.ScheduleID] = runs
+}
+
+// persist writes the store to its file, replacing it atomically. The caller
+// must hold the write lock.
+func (s *ScheduleStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(scheduleState{Schedules: s.schedules, Runs: s.runs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schedules: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to save schedules: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save schedules: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save schedules: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save schedules: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save schedules: %v", err)
+	}
+	return nil
+}
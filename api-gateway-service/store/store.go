@@ -0,0 +1,7 @@
+// Package store provides the in-memory stores backing the API gateway handlers.
+package store
+
+import "errors"
+
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("not found")
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"api-gateway-service/tenant"
+)
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+// Delivery statuses
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Webhook receives placement lifecycle events. Events and ResourceTypes
+// filter the events sent; empty lists match every event or resource type.
+// Secret keys the HMAC signature of each delivery and is never returned after
+// creation.
+type Webhook struct {
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Secret        string    `json:"-"`
+	Events        []string  `json:"events,omitempty"`
+	ResourceTypes []string  `json:"resource_types,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Matches reports whether the webhook subscribes to the event for the
+// resource type
+func (w *Webhook) Matches(event, resourceType string) bool {
+	return matchesFilter(w.Events, event) && matchesFilter(w.ResourceTypes, resourceType)
+}
+
+// matchesFilter reports whether v is in filter, or filter is empty
+func matchesFilter(filter []string, v string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, f := range filter {
+		if f == v {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records the attempts to deliver one event to a webhook
+type WebhookDelivery struct {
+	ID           string         `json:"id"`
+	WebhookID    string         `json:"webhook_id"`
+	Event        string         `json:"event"`
+	ResourceType string         `json:"resource_type"`
+	ResourceID   string         `json:"resource_id"`
+	Status       DeliveryStatus `json:"status"`
+	Attempts     int            `json:"attempts"`
+	// ResponseStatus is the HTTP status of the last attempt, if it got a
+	// response
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// WebhookStore holds webhooks and their recent deliveries in memory,
+// partitioned by tenant
+type WebhookStore struct {
+	mu sync.RWMutex
+	// webhooks maps tenant ID to that tenant's webhooks by ID
+	webhooks map[string]map[string]*Webhook
+	// deliveries maps webhook ID to its deliveries, oldest first
+	deliveries   map[string][]*WebhookDelivery
+	historyLimit int
+}
+
+// NewWebhookStore creates a webhook store keeping the last historyLimit
+// deliveries of each webhook
+func NewWebhookStore(historyLimit int) *WebhookStore {
+	return &WebhookStore{
+		webhooks:     make(map[string]map[string]*Webhook),
+		deliveries:   make(map[string][]*WebhookDelivery),
+		historyLimit: historyLimit,
+	}
+}
+
+// Save stores a webhook for the caller's tenant
+func (s *WebhookStore) Save(ctx context.Context, w *Webhook) error {
+	if w.ID == "" {
+		return fmt.Errorf("webhook ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now().UTC()
+	}
+
+	tenantID := tenant.FromContext(ctx)
+	if s.webhooks[tenantID] == nil {
+		s.webhooks[tenantID] = make(map[string]*Webhook)
+	}
+	s.webhooks[tenantID][w.ID] = w
+	return nil
+}
+
+// Get returns the caller's tenant webhook with the given ID
+func (s *WebhookStore) Get(ctx context.Context, id string) (*Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	w, exists := s.webhooks[tenant.FromContext(ctx)][id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return w, nil
+}
+
+// List returns the caller's tenant webhooks ordered by creation time
+func (s *WebhookStore) List(ctx context.Context) []*Webhook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tenantWebhooks := s.webhooks[tenant.FromContext(ctx)]
+	webhooks := make([]*Webhook, 0, len(tenantWebhooks))
+	for _, w := range tenantWebhooks {
+		webhooks = append(webhooks, w)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		if webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].ID < webhooks[j].ID
+		}
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks
+}
+
+// Delete removes the caller's tenant webhook and its deliveries
+func (s *WebhookStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenantID := tenant.FromContext(ctx)
+	if _, exists := s.webhooks[tenantID][id]; !exists {
+		return ErrNotFound
+	}
+	delete(s.webhooks[tenantID], id)
+	delete(s.deliveries, id)
+	return nil
+}
+
+// RecordDelivery stores a copy of the delivery to the caller's tenant
+// webhook, replacing an earlier record with the same ID. Only the last
+// historyLimit deliveries of the webhook are kept; deliveries to a deleted
+// webhook are dropped.
+func (s *WebhookStore) RecordDelivery(ctx context.Context, d *WebhookDelivery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.webhooks[tenant.FromContext(ctx)][d.WebhookID]; !exists {
+		return
+	}
+
+	record := *d
+	deliveries := s.deliveries[d.WebhookID]
+	for i, existing := range deliveries {
+		if existing.ID == d.ID {
+			deliveries[i] = &record
+			return
+		}
+	}
+
+	deliveries = append(deliveries, &record)
+	if s.historyLimit > 0 && len(deliveries) > s.historyLimit {
+		deliveries = deliveries[len(deliveries)-s.historyLimit:]
+	}
+	s.deliveries[d.WebhookID] = deliveries
+}
+
+// Deliveries returns the recent deliveries of the caller's tenant webhook,
+// newest first
+func (s *WebhookStore) Deliveries(ctx context.Context, id string) ([]*WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.webhooks[tenant.FromContext(ctx)][id]; !exists {
+		return nil, ErrNotFound
+	}
+
+	deliveries := s.deliveries[id]
+	result := make([]*WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		record := *d
+		result[len(deliveries)-1-i] = &record
+	}
+	return result, nil
+}
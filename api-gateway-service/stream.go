@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// sseKeepaliveInterval is the longest a server-sent event stream stays
+// silent; a comment line is sent when no event was, so proxies and clients
+// can tell an idle stream from a dead one
+var sseKeepaliveInterval = 15 * time.Second
+
+// streamingRoutes hold their connection for as long as the client follows
+// them, so they are exempt from the global concurrency limit
+var streamingRoutes = map[string]bool{
+	"/api/v1/optimize/jobs/:id/events": true,
+	"/api/v1/audit/stream":             true,
+}
+
+// exceptStreaming runs limit for every route but the streaming ones
+func exceptStreaming(limit gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if streamingRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+		limit(c)
+	}
+}
+
+// extendWriteDeadline moves the response's write deadline server.write_timeout
+// ahead, so that the timeout bounds each write of a long-lived stream rather
+// than the whole stream
+func extendWriteDeadline(rc *http.ResponseController) {
+	if timeout := viper.GetDuration("server.write_timeout"); timeout > 0 {
+		_ = rc.SetWriteDeadline(time.Now().Add(timeout))
+	}
+}
+
+// sseStream writes server-sent events, extending the write deadline before
+// each write and sending a keepalive comment when the stream has been silent
+// for sseKeepaliveInterval
+type sseStream struct {
+	c        *gin.Context
+	rc       *http.ResponseController
+	lastSent time.Time
+}
+
+// startSSE sends the event stream headers
+func startSSE(c *gin.Context) *sseStream {
+	s := &sseStream{c: c, rc: http.NewResponseController(c.Writer), lastSent: time.Now()}
+	extendWriteDeadline(s.rc)
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+	return s
+}
+
+// event sends one event
+func (s *sseStream) event(name string, data interface{}) {
+	extendWriteDeadline(s.rc)
+	s.c.SSEvent(name, data)
+	s.lastSent = time.Now()
+}
+
+// flush sends a keepalive comment if nothing was sent for
+// sseKeepaliveInterval, then flushes the stream
+func (s *sseStream) flush() error {
+	extendWriteDeadline(s.rc)
+	if time.Since(s.lastSent) >= sseKeepaliveInterval {
+		if _, err := fmt.Fprint(s.c.Writer, ": keepalive\n\n"); err != nil {
+			return err
+		}
+		s.lastSent = time.Now()
+	}
+	return s.rc.Flush()
+}
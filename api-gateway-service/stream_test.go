@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/audit"
+	"api-gateway-service/middleware"
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// streamTimeout is the server write timeout in the stream tests, far shorter
+// than the streams are followed for
+const streamTimeout = 200 * time.Millisecond
+
+// startStreamServer serves the handler with a write timeout of streamTimeout
+// and keepalives every 50ms
+func startStreamServer(t *testing.T, path string, handler gin.HandlerFunc) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(path, handler)
+
+	srv := httptest.NewUnstartedServer(router)
+	srv.Config.WriteTimeout = streamTimeout
+	srv.Start()
+
+	viper.Set("server.write_timeout", streamTimeout)
+	prevKeepalive := sseKeepaliveInterval
+	sseKeepaliveInterval = 50 * time.Millisecond
+	t.Cleanup(func() {
+		srv.Close()
+		viper.Set("server.write_timeout", nil)
+		sseKeepaliveInterval = prevKeepalive
+	})
+	return srv
+}
+
+// readStream returns a channel of the stream's lines, closed when the stream
+// ends
+func readStream(t *testing.T, url string) <-chan string {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+
+	// Hanging up ends the handler, which the server waits for on Close
+	t.Cleanup(func() { resp.Body.Close() })
+
+	lines := make(chan string, 100)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+}
+
+// waitForLine reads lines until one has the prefix, failing if the stream
+// ends first. It reports whether a keepalive was seen on the way.
+func waitForLine(t *testing.T, lines <-chan string, prefix string) bool {
+	t.Helper()
+	keepalive := false
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("stream ended before %q", prefix)
+			}
+			if strings.HasPrefix(line, ": keepalive") {
+				keepalive = true
+			}
+			if strings.HasPrefix(line, prefix) {
+				return keepalive
+			}
+		case <-timeout:
+			t.Fatalf("no %q within 10s", prefix)
+		}
+	}
+}
+
+// An apply job pending approval streams nothing for longer than the write
+// timeout; the stream must survive until the job finishes
+func TestStreamApplyJobOutlivesWriteTimeout(t *testing.T) {
+	ctx := context.Background()
+	prev := jobStore
+	jobStore = store.NewJobStore()
+	t.Cleanup(func() { jobStore = prev })
+	job := &store.ApplyJob{
+		ID:     "job-1",
+		Status: store.JobPendingApproval,
+		Items:  []store.JobItem{{RecommendationID: "rec-1", Status: store.JobItemPending}},
+	}
+	if err := jobStore.Save(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := startStreamServer(t, "/jobs/:id/events", streamApplyJob)
+	lines := readStream(t, srv.URL+"/jobs/job-1/events")
+	waitForLine(t, lines, "event:item")
+
+	time.Sleep(3 * streamTimeout)
+	if err := jobStore.Complete(ctx, "job-1"); err != nil {
+		t.Fatal(err)
+	}
+	if !waitForLine(t, lines, "event:done") {
+		t.Error("no keepalive sent while the job was idle")
+	}
+}
+
+func TestStreamAuditEntriesOutlivesWriteTimeout(t *testing.T) {
+	prev := auditLog
+	auditLog = audit.NewLog(10, nil)
+	t.Cleanup(func() { auditLog = prev })
+
+	srv := startStreamServer(t, "/audit/stream", streamAuditEntries)
+	lines := readStream(t, srv.URL+"/audit/stream")
+
+	// Long enough for a check of the log to find nothing
+	time.Sleep(auditStreamInterval + streamTimeout)
+	if err := auditLog.Record(audit.Entry{Time: time.Now(), TenantID: tenant.DefaultID, Method: http.MethodPost, Path: "/api/v1/placements/compute"}); err != nil {
+		t.Fatal(err)
+	}
+	if !waitForLine(t, lines, "event:entry") {
+		t.Error("no keepalive sent while no entries were recorded")
+	}
+}
+
+// Streams stay open for as long as they are followed, so they must not hold
+// the global concurrency slots other requests wait for
+func TestExceptStreaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := middleware.NewConcurrencyLimiter(middleware.ConcurrencyConfig{MaxInFlight: 1}).Limit()
+
+	release := make(chan struct{})
+	streaming := make(chan struct{})
+	router := gin.New()
+	api := router.Group("/api/v1")
+	api.Use(exceptStreaming(limit))
+	api.GET("/audit/stream", func(c *gin.Context) {
+		close(streaming)
+		<-release
+	})
+	api.GET("/version", func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+	api.GET("/busy", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	go router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/audit/stream", nil))
+	<-streaming
+	// The stream holds no slot, so this request takes the only one
+	held := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+		held <- w.Code
+	}()
+
+	// With the slot taken, further requests are turned away
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/busy", nil))
+		if w.Code == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			close(release)
+			t.Fatal("the limit was never reached")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	if code := <-held; code != http.StatusOK {
+		t.Errorf("request alongside the stream got %d, want 200", code)
+	}
+}
@@ -0,0 +1,221 @@
+// Package tagging maps canonical resource tags to the native tag or label
+// forms of each cloud provider.
+package tagging
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Unrepresentable is a canonical tag that cannot be expressed on a provider
+type Unrepresentable struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+// Result is a canonical tag set normalized for one provider
+type Result struct {
+	Provider string `json:"provider"`
+	// Tags holds the provider-native tags
+	Tags map[string]string `json:"tags"`
+	// Keys maps each represented canonical key to its native key
+	Keys map[string]string `json:"keys"`
+	// Unrepresentable lists the canonical tags left out, ordered by key
+	Unrepresentable []Unrepresentable `json:"unrepresentable"`
+}
+
+// rules describes a provider's tag constraints
+type rules struct {
+	maxTags        int
+	maxKeyLength   int
+	maxValueLength int
+	// reservedPrefixes are key prefixes owned by the provider, matched
+	// case-insensitively
+	reservedPrefixes []string
+	// caseInsensitive reports whether keys differing only in case collide
+	caseInsensitive bool
+	// transform rewrites a key or value into native form
+	transform func(string) string
+	// validKey and validValue check a transformed key and value
+	validKey   func(string) error
+	validValue func(string) error
+}
+
+var providerRules = map[string]rules{
+	"aws": {
+		maxTags:          50,
+		maxKeyLength:     128,
+		maxValueLength:   256,
+		reservedPrefixes: []string{"aws:"},
+		validKey:         allowChars(" _.:/=+-@"),
+		validValue:       allowChars(" _.:/=+-@"),
+	},
+	"azure": {
+		maxTags:          50,
+		maxKeyLength:     512,
+		maxValueLength:   256,
+		reservedPrefixes: []string{"microsoft", "azure", "windows"},
+		caseInsensitive:  true,
+		validKey:         denyChars(`<>%&\?/`),
+	},
+	"gcp": {
+		maxTags:          64,
+		maxKeyLength:     63,
+		maxValueLength:   63,
+		reservedPrefixes: []string{"goog"},
+		transform:        gcpLabel,
+		validKey:         gcpLabelKey,
+	},
+}
+
+// Providers returns the providers with tagging rules, sorted
+func Providers() []string {
+	providers := make([]string, 0, len(providerRules))
+	for p := range providerRules {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// Normalize maps canonical tags to the provider's native form. Keys and
+// values are rewritten where the provider requires it, for example GCP
+// labels are lowercased; tags that still violate a limit, collide with
+// another key or exceed the tag count are reported as unrepresentable
+// rather than truncated.
+func Normalize(provider string, tags map[string]string) (*Result, error) {
+	r, ok := providerRules[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s (must be one of %s)", provider, strings.Join(Providers(), ", "))
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := &Result{
+		Provider:        strings.ToLower(provider),
+		Tags:            make(map[string]string),
+		Keys:            make(map[string]string),
+		Unrepresentable: make([]Unrepresentable, 0),
+	}
+	// owners maps a native key, folded if case-insensitive, to the canonical
+	// key that claimed it
+	owners := make(map[string]string)
+	for _, key := range keys {
+		nativeKey, nativeValue := key, tags[key]
+		if r.transform != nil {
+			nativeKey, nativeValue = r.transform(key), r.transform(nativeValue)
+		}
+
+		if reason := r.check(nativeKey, nativeValue); reason != "" {
+			result.Unrepresentable = append(result.Unrepresentable, Unrepresentable{Key: key, Reason: reason})
+			continue
+		}
+
+		owner := nativeKey
+		if r.caseInsensitive {
+			owner = strings.ToLower(nativeKey)
+		}
+		if other, taken := owners[owner]; taken {
+			result.Unrepresentable = append(result.Unrepresentable, Unrepresentable{
+				Key:    key,
+				Reason: fmt.Sprintf("native key %q collides with tag %q", nativeKey, other),
+			})
+			continue
+		}
+		if len(result.Tags) == r.maxTags {
+			result.Unrepresentable = append(result.Unrepresentable, Unrepresentable{
+				Key:    key,
+				Reason: fmt.Sprintf("exceeds the limit of %d tags", r.maxTags),
+			})
+			continue
+		}
+
+		owners[owner] = key
+		result.Tags[nativeKey] = nativeValue
+		result.Keys[key] = nativeKey
+	}
+	return result, nil
+}
+
+// check returns why the native tag is invalid, or "" if it is valid
+func (r rules) check(key, value string) string {
+	if key == "" {
+		return "key is empty"
+	}
+	if n := len([]rune(key)); n > r.maxKeyLength {
+		return fmt.Sprintf("key is %d characters, the limit is %d", n, r.maxKeyLength)
+	}
+	if n := len([]rune(value)); n > r.maxValueLength {
+		return fmt.Sprintf("value is %d characters, the limit is %d", n, r.maxValueLength)
+	}
+
+	lower := strings.ToLower(key)
+	for _, prefix := range r.reservedPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			return fmt.Sprintf("key uses the reserved prefix %q", prefix)
+		}
+	}
+
+	if r.validKey != nil {
+		if err := r.validKey(key); err != nil {
+			return "key " + err.Error()
+		}
+	}
+	if r.validValue != nil {
+		if err := r.validValue(value); err != nil {
+			return "value " + err.Error()
+		}
+	}
+	return ""
+}
+
+// allowChars accepts letters, digits and the given characters
+func allowChars(chars string) func(string) error {
+	return func(s string) error {
+		for _, c := range s {
+			if !unicode.IsLetter(c) && !unicode.IsDigit(c) && !strings.ContainsRune(chars, c) {
+				return fmt.Errorf("contains %q; only letters, digits and %q are allowed", c, chars)
+			}
+		}
+		return nil
+	}
+}
+
+// denyChars rejects the given characters
+func denyChars(chars string) func(string) error {
+	return func(s string) error {
+		if i := strings.IndexAny(s, chars); i >= 0 {
+			return fmt.Errorf("contains %q, which is not allowed", s[i])
+		}
+		return nil
+	}
+}
+
+// gcpLabel lowercases s and replaces every character other than lowercase
+// letters, digits, underscores and hyphens with a hyphen
+func gcpLabel(s string) string {
+	return strings.Map(func(c rune) rune {
+		c = unicode.ToLower(c)
+		if unicode.IsLower(c) || unicode.IsDigit(c) || c == '_' || c == '-' {
+			return c
+		}
+		return '-'
+	}, s)
+}
+
+// gcpLabelKey checks that a label key starts with a lowercase letter
+func gcpLabelKey(key string) error {
+	for _, c := range key {
+		if !unicode.IsLower(c) {
+			return fmt.Errorf("must start with a lowercase letter")
+		}
+		break
+	}
+	return nil
+}
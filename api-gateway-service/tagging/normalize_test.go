@@ -0,0 +1,106 @@
+package tagging
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	canonical := map[string]string{
+		"1st":         "y",
+		"CostCenter":  "CC-1",
+		"costcenter":  "cc-2",
+		"Team Name":   "Data & AI",
+		"aws:created": "ci",
+	}
+
+	tests := []struct {
+		provider        string
+		wantTags        map[string]string
+		wantKeys        map[string]string
+		unrepresentable map[string]string
+	}{
+		{
+			provider: "aws",
+			wantTags: map[string]string{"1st": "y", "CostCenter": "CC-1", "costcenter": "cc-2"},
+			unrepresentable: map[string]string{
+				"Team Name":   "value contains '&'",
+				"aws:created": `reserved prefix "aws:"`,
+			},
+		},
+		{
+			provider:        "azure",
+			wantTags:        map[string]string{"1st": "y", "CostCenter": "CC-1", "Team Name": "Data & AI", "aws:created": "ci"},
+			unrepresentable: map[string]string{"costcenter": `collides with tag "CostCenter"`},
+		},
+		{
+			provider: "GCP",
+			wantTags: map[string]string{"costcenter": "cc-1", "team-name": "data---ai", "aws-created": "ci"},
+			wantKeys: map[string]string{"CostCenter": "costcenter", "Team Name": "team-name", "aws:created": "aws-created"},
+			unrepresentable: map[string]string{
+				"1st":        "must start with a lowercase letter",
+				"costcenter": `native key "costcenter" collides with tag "CostCenter"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			result, err := Normalize(tt.provider, canonical)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Provider != strings.ToLower(tt.provider) {
+				t.Errorf("provider %s", result.Provider)
+			}
+			if !reflect.DeepEqual(result.Tags, tt.wantTags) {
+				t.Errorf("tags %v, want %v", result.Tags, tt.wantTags)
+			}
+			if tt.wantKeys != nil && !reflect.DeepEqual(result.Keys, tt.wantKeys) {
+				t.Errorf("keys %v, want %v", result.Keys, tt.wantKeys)
+			}
+
+			if len(result.Unrepresentable) != len(tt.unrepresentable) {
+				t.Fatalf("unrepresentable %+v, want %v", result.Unrepresentable, tt.unrepresentable)
+			}
+			for i, u := range result.Unrepresentable {
+				if i > 0 && result.Unrepresentable[i-1].Key > u.Key {
+					t.Errorf("unrepresentable tags not ordered by key: %+v", result.Unrepresentable)
+				}
+				if want, ok := tt.unrepresentable[u.Key]; !ok || !strings.Contains(u.Reason, want) {
+					t.Errorf("%s unrepresentable because %q, want %q", u.Key, u.Reason, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeLimits(t *testing.T) {
+	tags := make(map[string]string)
+	for i := 0; i < 52; i++ {
+		tags[fmt.Sprintf("k%02d", i)] = "v"
+	}
+	tags["k00"] = strings.Repeat("v", 64)
+
+	result, err := Normalize("gcp", tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tags) != 51 || len(result.Unrepresentable) != 1 || result.Unrepresentable[0].Key != "k00" {
+		t.Errorf("%d tags and unrepresentable %+v, want only the long value left out", len(result.Tags), result.Unrepresentable)
+	}
+
+	result, err = Normalize("aws", tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tags) != 50 || len(result.Unrepresentable) != 2 || !strings.Contains(result.Unrepresentable[0].Reason, "limit of 50 tags") {
+		t.Errorf("%d tags and unrepresentable %+v, want the tags beyond 50 left out", len(result.Tags), result.Unrepresentable)
+	}
+
+	if _, err := Normalize("oracle", tags); err == nil {
+		t.Error("Normalize for an unsupported provider succeeded")
+	}
+}
@@ -0,0 +1,26 @@
+// Package tenant carries the caller's tenant through request-scoped contexts.
+package tenant
+
+import "context"
+
+// DefaultID is used for callers whose token carries no tenant claim, so
+// single-tenant deployments keep working without issuing tenant-aware tokens.
+const DefaultID = "default"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the given tenant ID
+func NewContext(ctx context.Context, tenantID string) context.Context {
+	if tenantID == "" {
+		tenantID = DefaultID
+	}
+	return context.WithValue(ctx, contextKey{}, tenantID)
+}
+
+// FromContext returns the tenant ID stored in ctx, or DefaultID if none is set
+func FromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(contextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultID
+}
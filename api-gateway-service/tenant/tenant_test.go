@@ -0,0 +1,27 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "unset", ctx: context.Background(), want: DefaultID},
+		{name: "tenant", ctx: NewContext(context.Background(), "acme"), want: "acme"},
+		{name: "empty tenant", ctx: NewContext(context.Background(), ""), want: DefaultID},
+		{name: "overridden", ctx: NewContext(NewContext(context.Background(), "acme"), "globex"), want: "globex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"api-gateway-service/store"
+	"api-gateway-service/tenant"
+)
+
+// webhookStore holds the placement webhooks; it is created in main with
+// webhooks.delivery_history
+var webhookStore *store.WebhookStore
+
+// Placement lifecycle events sent to webhooks
+const (
+	eventPlacementCreated  = "placement.created"
+	eventPlacementUpdated  = "placement.updated"
+	eventPlacementDeleted  = "placement.deleted"
+	eventPlacementRestored = "placement.restored"
+)
+
+var placementEvents = map[string]bool{
+	eventPlacementCreated:  true,
+	eventPlacementUpdated:  true,
+	eventPlacementDeleted:  true,
+	eventPlacementRestored: true,
+}
+
+// Headers sent with each webhook delivery. The signature is the hex
+// HMAC-SHA256 of the body keyed by the webhook's secret, prefixed "sha256=".
+const (
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+	webhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookRequest is the body accepted by the create webhook endpoint. A secret
+// is generated when none is given.
+type WebhookRequest struct {
+	URL           string   `json:"url" binding:"required"`
+	Secret        string   `json:"secret"`
+	Events        []string `json:"events"`
+	ResourceTypes []string `json:"resource_types"`
+}
+
+// CreatedWebhook is a new webhook with its secret, which is returned only
+// on creation
+type CreatedWebhook struct {
+	*store.Webhook
+	Secret string `json:"secret"`
+}
+
+// PlacementEvent is the body POSTed to a webhook. Placement is the full
+// placement result, signed when a signing key is configured; for
+// placement.deleted it is the placement as it was before deletion.
+type PlacementEvent struct {
+	ID         string      `json:"id"`
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Placement  interface{} `json:"placement"`
+}
+
+func createWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := validateWebhookURL(c.Request.Context(), req.URL); err != nil {
+		respondValidationError(c, &FieldError{Field: "url", Message: err.Error()})
+		return
+	}
+	for _, event := range req.Events {
+		if !placementEvents[event] {
+			respondValidationError(c, &FieldError{Field: "events", Message: fmt.Sprintf("unknown event %q", event)})
+			return
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			respondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	w := &store.Webhook{
+		ID:            newID("whk"),
+		URL:           req.URL,
+		Secret:        secret,
+		Events:        req.Events,
+		ResourceTypes: req.ResourceTypes,
+	}
+	if err := webhookStore.Save(c.Request.Context(), w); err != nil {
+		respondError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatedWebhook{Webhook: w, Secret: secret})
+}
+
+func listWebhooks(c *gin.Context) {
+	c.JSON(http.StatusOK, webhookStore.List(c.Request.Context()))
+}
+
+func getWebhook(c *gin.Context) {
+	w, err := webhookStore.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "webhook not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, w)
+}
+
+func deleteWebhook(c *gin.Context) {
+	if err := webhookStore.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondStoreError(c, err, "webhook not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listWebhookDeliveries returns the webhook's recent deliveries, newest first
+func listWebhookDeliveries(c *gin.Context) {
+	deliveries, err := webhookStore.Deliveries(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondStoreError(c, err, "webhook not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// deliveriesCtx is the parent of every background webhook delivery.
+// stopDeliveries cancels it at shutdown, so that deliveries waiting to retry
+// give up instead of holding the process.
+var deliveriesCtx, stopDeliveries = context.WithCancel(context.Background())
+
+// notifyPlacementEvent sends the event to each of the caller's tenant
+// webhooks subscribed to it and the placement's resource type. Deliveries run
+// in the background, outliving the request.
+func notifyPlacementEvent(ctx context.Context, event string, p *store.Placement) {
+	if webhookStore == nil {
+		return
+	}
+
+	var webhooks []*store.Webhook
+	for _, w := range webhookStore.List(ctx) {
+		if w.Matches(event, p.ResourceType) {
+			webhooks = append(webhooks, w)
+		}
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	payload := PlacementEvent{ID: newID("evt"), Event: event, OccurredAt: now, Placement: p}
+	if placementSigner != nil {
+		signed, err := placementSigner.Sign(p)
+		if err != nil {
+			log.Printf("Failed to sign %s event for placement %s: %v", event, p.ID, err)
+			return
+		}
+		payload.Placement = signed
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode %s event for placement %s: %v", event, p.ID, err)
+		return
+	}
+
+	// Deliveries outlive the request, so they keep only the caller's tenant
+	deliveryCtx := tenant.NewContext(deliveriesCtx, tenant.FromContext(ctx))
+	for _, w := range webhooks {
+		d := &store.WebhookDelivery{
+			ID:           newID("dlv"),
+			WebhookID:    w.ID,
+			Event:        event,
+			ResourceType: p.ResourceType,
+			ResourceID:   p.ID,
+			Status:       store.DeliveryPending,
+			CreatedAt:    now,
+		}
+		webhookStore.RecordDelivery(deliveryCtx, d)
+		go deliverWebhook(deliveryCtx, w, d, body)
+	}
+}
+
+// deliverWebhook POSTs the body to the webhook, retrying failed attempts up to
+// webhooks.max_attempts in total with a backoff starting at
+// webhooks.initial_backoff and doubling after each attempt. Each attempt is
+// recorded in the delivery log. A delivery still waiting to retry when ctx is
+// done is recorded as failed.
+func deliverWebhook(ctx context.Context, w *store.Webhook, d *store.WebhookDelivery, body []byte) {
+	maxAttempts := viper.GetInt("webhooks.max_attempts")
+	backoff := viper.GetDuration("webhooks.initial_backoff")
+	for {
+		d.Attempts++
+		status, err := postWebhook(ctx, w, d, body)
+		d.ResponseStatus = status
+		if err == nil {
+			delivered := time.Now().UTC()
+			d.Status = store.DeliverySucceeded
+			d.LastError = ""
+			d.DeliveredAt = &delivered
+			webhookStore.RecordDelivery(ctx, d)
+			return
+		}
+
+		d.LastError = err.Error()
+		if d.Attempts >= maxAttempts {
+			d.Status = store.DeliveryFailed
+			webhookStore.RecordDelivery(ctx, d)
+			log.Printf("Webhook %s: giving up on delivery %s after %d attempts: %v", w.ID, d.ID, d.Attempts, err)
+			return
+		}
+		webhookStore.RecordDelivery(ctx, d)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.Status = store.DeliveryFailed
+			webhookStore.RecordDelivery(ctx, d)
+			log.Printf("Webhook %s: abandoning delivery %s after %d attempts: %v", w.ID, d.ID, d.Attempts, ctx.Err())
+			return
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// postWebhook makes one delivery attempt, returning the response status, if
+// any, and an error unless the webhook responded 2xx
+func postWebhook(ctx context.Context, w *store.Webhook, d *store.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookEventHeader, d.Event)
+	req.Header.Set(webhookDeliveryHeader, d.ID)
+	req.Header.Set(webhookSignatureHeader, "sha256="+webhookSignature(w.Secret, body))
+
+	resp, err := outboundClient(viper.GetDuration("webhooks.timeout")).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookSignature returns the hex HMAC-SHA256 of the body keyed by secret
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"api-gateway-service/store"
+)
+
+// useWebhookDelivery points deliveries at a test server answering with the
+// given statuses in turn, the last one repeating
+func useWebhookDelivery(t *testing.T, statuses ...int) (*store.Webhook, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		w.WriteHeader(statuses[n-1])
+	}))
+
+	prev := webhookStore
+	webhookStore = store.NewWebhookStore(10)
+	viper.Set("webhooks.allow_private_targets", true)
+	viper.Set("webhooks.timeout", 5*time.Second)
+	t.Cleanup(func() {
+		srv.Close()
+		webhookStore = prev
+		viper.Set("webhooks.allow_private_targets", nil)
+		viper.Set("webhooks.timeout", nil)
+		viper.Set("webhooks.max_attempts", nil)
+		viper.Set("webhooks.initial_backoff", nil)
+	})
+	return &store.Webhook{ID: "whk-1", URL: srv.URL, Secret: "s3cret"}, &calls
+}
+
+func TestDeliverWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		maxAttempts int
+		wantStatus  store.DeliveryStatus
+		wantCalls   int32
+	}{
+		{name: "delivered first time", statuses: []int{204}, maxAttempts: 3, wantStatus: store.DeliverySucceeded, wantCalls: 1},
+		{name: "delivered after retries", statuses: []int{500, 502, 200}, maxAttempts: 3, wantStatus: store.DeliverySucceeded, wantCalls: 3},
+		{name: "gives up after max attempts", statuses: []int{500}, maxAttempts: 3, wantStatus: store.DeliveryFailed, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, calls := useWebhookDelivery(t, tt.statuses...)
+			viper.Set("webhooks.max_attempts", tt.maxAttempts)
+			viper.Set("webhooks.initial_backoff", time.Millisecond)
+
+			d := &store.WebhookDelivery{ID: "dlv-1", WebhookID: w.ID, Event: eventPlacementCreated}
+			deliverWebhook(context.Background(), w, d, []byte(`{}`))
+
+			if d.Status != tt.wantStatus || *calls != tt.wantCalls || d.Attempts != int(tt.wantCalls) {
+				t.Errorf("delivery %s after %d attempts (%d requests), want %s after %d", d.Status, d.Attempts, *calls, tt.wantStatus, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// A delivery waiting to retry gives up as soon as its context is done,
+// rather than sleeping through the backoff
+func TestDeliverWebhookStopsOnCancel(t *testing.T) {
+	w, calls := useWebhookDelivery(t, http.StatusServiceUnavailable)
+	viper.Set("webhooks.max_attempts", 5)
+	viper.Set("webhooks.initial_backoff", time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &store.WebhookDelivery{ID: "dlv-1", WebhookID: w.ID, Event: eventPlacementCreated}
+	done := make(chan struct{})
+	go func() {
+		deliverWebhook(ctx, w, d, []byte(`{}`))
+		close(done)
+	}()
+
+	for atomic.LoadInt32(calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery still waiting to retry after its context was cancelled")
+	}
+
+	if d.Status != store.DeliveryFailed || d.Attempts != 1 {
+		t.Errorf("delivery %s after %d attempts, want failed after 1", d.Status, d.Attempts)
+	}
+}
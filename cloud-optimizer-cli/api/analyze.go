@@ -0,0 +1,33 @@
+package api
+
+import "context"
+
+// Analysis types accepted by POST /optimize/analyze; each is also the type of
+// the recommendations it returns. The compliance analysis recommends moving
+// resources only when compliance frameworks are required.
+const (
+	AnalysisCost        = "cost"
+	AnalysisPerformance = "performance"
+	AnalysisCompliance  = "compliance"
+)
+
+// AnalyzeRequest selects the resources and kinds of analysis to run
+type AnalyzeRequest struct {
+	ResourceIDs   []string `json:"resource_ids"`
+	AnalysisTypes []string `json:"analysis_types,omitempty"`
+	// ExcludedProviders and ExcludedRegions are left out of the alternatives
+	// considered for each resource
+	ExcludedProviders []string `json:"excluded_providers,omitempty"`
+	ExcludedRegions   []string `json:"excluded_regions,omitempty"`
+	// ComplianceFrameworks are required of the alternatives considered
+	ComplianceFrameworks []string `json:"compliance_frameworks,omitempty"`
+}
+
+// Analyze runs an optimization analysis of the requested resources
+func (c *Client) Analyze(ctx context.Context, req AnalyzeRequest) ([]Recommendation, error) {
+	var recommendations []Recommendation
+	if err := c.Post(ctx, "/optimize/analyze", req, &recommendations); err != nil {
+		return nil, err
+	}
+	return recommendations, nil
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Apply job and item statuses
+const (
+	JobPendingApproval = "pending_approval"
+	JobRunning         = "running"
+	JobCompleted       = "completed"
+	JobRejected        = "rejected"
+	JobExpired         = "expired"
+
+	JobItemPending  = "pending"
+	JobItemApplying = "applying"
+	JobItemDone     = "done"
+	JobItemFailed   = "failed"
+)
+
+// ErrStreamClosed is returned when a job event stream ends before the job
+// completes
+var ErrStreamClosed = errors.New("event stream closed before the job completed")
+
+// JobItem is the progress of one recommendation in an apply job
+type JobItem struct {
+	RecommendationID string `json:"recommendation_id" yaml:"recommendation_id"`
+	Status           string `json:"status" yaml:"status"`
+	ApplicationID    string `json:"application_id,omitempty" yaml:"application_id,omitempty"`
+	Error            string `json:"error,omitempty" yaml:"error,omitempty"`
+	RequiresApproval bool   `json:"requires_approval,omitempty" yaml:"requires_approval,omitempty"`
+}
+
+// Approval records who requested an apply job held for approval and who
+// decided on it
+type Approval struct {
+	RequestedBy string     `json:"requested_by" yaml:"requested_by"`
+	RequestedAt time.Time  `json:"requested_at" yaml:"requested_at"`
+	ExpiresAt   time.Time  `json:"expires_at" yaml:"expires_at"`
+	DecidedBy   string     `json:"decided_by,omitempty" yaml:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty" yaml:"decided_at,omitempty"`
+	Reason      string     `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// ApplyJob applies a batch of recommendations in the background. Jobs with a
+// high-impact recommendation start in JobPendingApproval.
+type ApplyJob struct {
+	ID          string     `json:"id" yaml:"id"`
+	Status      string     `json:"status" yaml:"status"`
+	Items       []JobItem  `json:"items" yaml:"items"`
+	Approval    *Approval  `json:"approval,omitempty" yaml:"approval,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" yaml:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" yaml:"completed_at,omitempty"`
+}
+
+// Finished reports whether the job will not change any more
+func (j *ApplyJob) Finished() bool {
+	return j.Status != JobPendingApproval && j.Status != JobRunning
+}
+
+// JobEvent reports the new status of the job item at Index
+type JobEvent struct {
+	Index int `json:"index"`
+	JobItem
+}
+
+// CodeComplianceViolation is the error code of an apply rejected because it
+// would move placements out of compliance
+const CodeComplianceViolation = "compliance_violation"
+
+// ComplianceViolation is a compliance framework a placement requires that
+// applying a recommendation would move it out of
+type ComplianceViolation struct {
+	RecommendationID string `json:"recommendation_id"`
+	PlacementID      string `json:"placement_id"`
+	Provider         string `json:"provider"`
+	Region           string `json:"region"`
+	Framework        string `json:"framework"`
+	Controls         []struct {
+		ID          string `json:"id"`
+		Description string `json:"description"`
+	} `json:"controls"`
+}
+
+// ComplianceViolations returns the violations an apply was rejected for, or
+// nil when the error is not a compliance rejection
+func (e *APIError) ComplianceViolations() []ComplianceViolation {
+	if e.Code != CodeComplianceViolation {
+		return nil
+	}
+	var details struct {
+		Violations []ComplianceViolation `json:"violations"`
+	}
+	if err := json.Unmarshal(e.Details, &details); err != nil {
+		return nil
+	}
+	return details.Violations
+}
+
+// StartApply starts a background job applying the recommendations. Unless
+// overrideCompliance is set, the gateway rejects recommendations that would
+// move a placement out of its required compliance frameworks.
+func (c *Client) StartApply(ctx context.Context, recommendationIDs []string, overrideCompliance bool) (*ApplyJob, error) {
+	req := struct {
+		RecommendationIDs  []string `json:"recommendation_ids"`
+		OverrideCompliance bool     `json:"override_compliance,omitempty"`
+	}{recommendationIDs, overrideCompliance}
+
+	var job ApplyJob
+	if err := c.Post(ctx, "/optimize/apply?async=true", req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ApproveApply releases an apply job pending approval to run
+func (c *Client) ApproveApply(ctx context.Context, id, reason string) (*ApplyJob, error) {
+	return c.decideApply(ctx, id, "approve", reason)
+}
+
+// RejectApply cancels an apply job pending approval
+func (c *Client) RejectApply(ctx context.Context, id, reason string) (*ApplyJob, error) {
+	return c.decideApply(ctx, id, "reject", reason)
+}
+
+func (c *Client) decideApply(ctx context.Context, id, decision, reason string) (*ApplyJob, error) {
+	req := map[string]string{"reason": reason}
+
+	var job ApplyJob
+	if err := c.Post(ctx, "/optimize/apply/"+url.PathEscape(id)+"/"+decision, req, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ApplyJob returns the current state of an apply job
+func (c *Client) ApplyJob(ctx context.Context, id string) (*ApplyJob, error) {
+	var job ApplyJob
+	if err := c.Get(ctx, "/optimize/jobs/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// StreamApplyJob follows an apply job's server-sent events, calling fn for
+// every item status change, and returns the completed job. It returns
+// ErrStreamClosed if the stream ends first.
+func (c *Client) StreamApplyJob(ctx context.Context, id string, fn func(JobEvent)) (*ApplyJob, error) {
+	resp, err := c.openStream(ctx, "/optimize/jobs/"+url.PathEscape(id)+"/events", nil, "text/event-stream")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var event, data string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		case line == "":
+			switch event {
+			case "item":
+				var e JobEvent
+				if err := json.Unmarshal([]byte(data), &e); err != nil {
+					return nil, fmt.Errorf("failed to decode job event: %v", err)
+				}
+				fn(e)
+			case "done":
+				var job ApplyJob
+				if err := json.Unmarshal([]byte(data), &job); err != nil {
+					return nil, fmt.Errorf("failed to decode job: %v", err)
+				}
+				return &job, nil
+			}
+			event, data = "", ""
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStreamClosed, err)
+	}
+	return nil, ErrStreamClosed
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+func TestAuditQueryValues(t *testing.T) {
+	since := time.Date(2026, 9, 16, 9, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	tests := []struct {
+		q    AuditQuery
+		want string
+	}{
+		{want: ""},
+		{q: AuditQuery{UserID: "alice"}, want: "user=alice"},
+		{q: AuditQuery{UserID: "alice", Since: since, Limit: 20, PageToken: "41"}, want: "limit=20&page_token=41&since=2026-09-16T07%3A00%3A00Z&user=alice"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.values().Encode(); got != tt.want {
+			t.Errorf("values(%+v) = %s, want %s", tt.q, got, tt.want)
+		}
+	}
+}
+
+// The stream's entry events are decoded in order; comments and other events
+// are skipped, and the end of the stream is reported as closed
+func TestStreamAudit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/audit/stream" || r.URL.Query().Get("after") != "7" || r.URL.Query().Get("user") != "alice" {
+			t.Errorf("request %s", r.URL)
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("accept %q", r.Header.Get("Accept"))
+		}
+		w.Write([]byte(": keepalive\n\n" +
+			"event: entry\ndata: {\"seq\":8,\"user_id\":\"alice\",\"method\":\"POST\",\"path\":\"/api/v1/placements/compute\",\"status\":201}\n\n" +
+			"event: other\ndata: {}\n\n" +
+			"event: entry\ndata: {\"seq\":9,\"user_id\":\"alice\",\"method\":\"DELETE\",\"path\":\"/api/v1/placements/compute/plc-1\",\"status\":204}\n\n"))
+	}))
+	defer srv.Close()
+
+	var seqs []uint64
+	err := NewClient(config.APIEndpoint{URL: srv.URL}, "").StreamAudit(context.Background(), "alice", 7, func(e AuditEntry) {
+		seqs = append(seqs, e.Seq)
+	})
+	if !errors.Is(err, ErrAuditStreamClosed) {
+		t.Errorf("error %v, want the stream closed", err)
+	}
+	if len(seqs) != 2 || seqs[0] != 8 || seqs[1] != 9 {
+		t.Errorf("entries %v, want 8 and 9", seqs)
+	}
+}
+
+func TestStreamAuditForbidden(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"admin role required"}`))
+	}))
+	defer srv.Close()
+
+	err := NewClient(config.APIEndpoint{URL: srv.URL}, "").StreamAudit(context.Background(), "", 0, func(AuditEntry) {
+		t.Error("entry received")
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("error %v, want a 403 API error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cloud-optimizer-cli/cache"
+)
+
+// responseCache stores successful GET responses on disk so commands can run
+// from cached data when the gateway is unreachable. Cost responses go to the
+// cost cache and everything else to the response cache under root.
+type responseCache struct {
+	root string
+}
+
+// path returns the cache file for the request URL
+func (rc *responseCache) path(requestURL string) string {
+	sum := sha256.Sum256([]byte(requestURL))
+	return filepath.Join(rc.dir(requestURL), hex.EncodeToString(sum[:])+".json")
+}
+
+// dir returns the cache directory for the request URL
+func (rc *responseCache) dir(requestURL string) string {
+	typ := cache.TypeResponse
+	if u, err := url.Parse(requestURL); err == nil && strings.Contains(u.Path+"/", "/costs/") {
+		typ = cache.TypeCost
+	}
+	return cache.Path(rc.root, typ)
+}
+
+// load returns the cached response for the request URL and when it was stored
+func (rc *responseCache) load(requestURL string) ([]byte, time.Time, error) {
+	path := rc.path(requestURL)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.ModTime(), nil
+}
+
+// store saves the response for the request URL
+func (rc *responseCache) store(requestURL string, data []byte) error {
+	if err := os.MkdirAll(rc.dir(requestURL), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return os.WriteFile(rc.path(requestURL), data, 0600)
+}
@@ -0,0 +1,303 @@
+// Package api provides a minimal HTTP client for the Cloud Optimizer API gateway.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	apiPrefix      = "/api/v1"
+)
+
+// Client calls the API gateway on behalf of CLI commands
+type Client struct {
+	endpoint   string
+	baseURL    string
+	token      string
+	mediaType  string
+	retry      RetryPolicy
+	httpClient *http.Client
+
+	// cache, if set, keeps successful GET responses for offline use
+	cache   *responseCache
+	offline bool
+
+	// refresh, if set, renews token before it expires
+	refresh *tokenRefresher
+}
+
+// FieldError is a validation failure of one request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is returned when the gateway responds with an error status. Code,
+// Fields and Details are set when the gateway returned its error envelope.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []FieldError
+	Details    json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Message)
+	if len(e.Fields) > 0 {
+		fields := make([]string, len(e.Fields))
+		for i, f := range e.Fields {
+			fields[i] = f.Field + " " + f.Message
+		}
+		msg += " (" + strings.Join(fields, "; ") + ")"
+	}
+	return msg
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithRetryPolicy retries transient failures according to the policy,
+// replacing any policy set earlier
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithCache stores successful GET responses in the response and cost caches
+// under the cache directory dir
+func WithCache(dir string) Option {
+	return func(c *Client) {
+		c.cache = &responseCache{root: dir}
+	}
+}
+
+// WithOffline serves GET requests from the cache without contacting the
+// gateway. Other requests fail with ErrOffline.
+func WithOffline(offline bool) Option {
+	return func(c *Client) {
+		c.offline = offline
+	}
+}
+
+// NewClient creates a client for the given gateway endpoint
+func NewClient(endpoint config.APIEndpoint, token string, opts ...Option) *Client {
+	c := &Client{
+		endpoint:  endpoint.URL,
+		baseURL:   strings.TrimRight(endpoint.URL, "/") + apiPrefix,
+		token:     token,
+		mediaType: endpoint.MediaType(),
+		httpClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.retry.Attempts > 0 {
+		c.httpClient.Transport = newRetryTransport(nil, c.retry)
+	}
+	return c
+}
+
+// NewClientFromConfig creates a client for the optimizer endpoint of the
+// default region, retrying transient failures as set in the preferences
+func NewClientFromConfig(cfg *config.Config, opts ...Option) (*Client, error) {
+	endpoint, err := cfg.RegionalEndpoint("optimizer", cfg.DefaultRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	backoff, err := cfg.Preferences.RetryBackoffDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	policy := RetryPolicy{Attempts: cfg.Preferences.RetryAttempts, Backoff: backoff}
+	opts = append([]Option{WithRetryPolicy(policy)}, opts...)
+	return NewClient(endpoint, cfg.APIToken, opts...), nil
+}
+
+// BaseURL returns the gateway API base URL used by the client
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Get performs a GET request and decodes the JSON response into out
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	if len(query) > 0 {
+		path = path + "?" + query.Encode()
+	}
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+// Post performs a POST request with a JSON body and decodes the JSON response into out
+func (c *Client) Post(ctx context.Context, path string, body, out interface{}) error {
+	return c.do(ctx, http.MethodPost, path, body, out)
+}
+
+// Delete performs a DELETE request with optional query parameters
+func (c *Client) Delete(ctx context.Context, path string, query url.Values) error {
+	if len(query) > 0 {
+		path = path + "?" + query.Encode()
+	}
+	return c.do(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// openStream performs a GET request for a streamed response of the given
+// media type. The stream may outlast the client timeout, so only ctx bounds
+// it; the caller must close the response body.
+func (c *Client) openStream(ctx context.Context, path string, query url.Values, accept string) (*http.Response, error) {
+	if c.offline {
+		return nil, ErrOffline
+	}
+	if len(query) > 0 {
+		path = path + "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", accept)
+	if token := c.authToken(ctx); token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	requestURL := c.baseURL + path
+	if c.offline {
+		return c.loadCached(method, requestURL, out)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %v", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", c.mediaType)
+	if token := c.authToken(ctx); token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if isUnreachable(err) {
+			unreachable := &UnreachableError{Endpoint: c.endpoint, Err: err}
+			if method == http.MethodGet && c.cache != nil {
+				if _, cachedAt, err := c.cache.load(requestURL); err == nil {
+					unreachable.CachedAt = cachedAt
+				}
+			}
+			return unreachable
+		}
+		return fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return newAPIError(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if method == http.MethodGet && c.cache != nil {
+		// A failed cache write only costs offline availability
+		_ = c.cache.store(requestURL, data)
+	}
+
+	return decodeResponse(data, out)
+}
+
+// loadCached serves a GET request from the response cache
+func (c *Client) loadCached(method, requestURL string, out interface{}) error {
+	if method != http.MethodGet || c.cache == nil {
+		return ErrOffline
+	}
+
+	data, _, err := c.cache.load(requestURL)
+	if err != nil {
+		return fmt.Errorf("no cached data for %s; run without --offline to fetch it", requestURL)
+	}
+	return decodeResponse(data, out)
+}
+
+func decodeResponse(data []byte, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	data, _ := io.ReadAll(resp.Body)
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+
+	// Handlers respond with an {"error": {code, message, fields}} envelope;
+	// middleware such as rate limiting still responds with {"error": "..."}
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || len(body.Error) == 0 {
+		return apiErr
+	}
+
+	var envelope struct {
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Fields  []FieldError    `json:"fields"`
+		Details json.RawMessage `json:"details"`
+	}
+	var message string
+	switch {
+	case json.Unmarshal(body.Error, &envelope) == nil && envelope.Message != "":
+		apiErr.Code, apiErr.Message, apiErr.Fields = envelope.Code, envelope.Message, envelope.Fields
+		apiErr.Details = envelope.Details
+	case json.Unmarshal(body.Error, &message) == nil && message != "":
+		apiErr.Message = message
+	}
+	return apiErr
+}
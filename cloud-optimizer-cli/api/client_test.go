@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+// Requests to a pinned endpoint ask for its version's media type
+func TestClientPinnedVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "", want: "application/json"},
+		{version: "2", want: "application/vnd.cloudoptimizer.v2+json"},
+	}
+
+	for _, tt := range tests {
+		var accept string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			accept = r.Header.Get("Accept")
+			w.Write([]byte(`{}`))
+		}))
+
+		c := NewClient(config.APIEndpoint{URL: srv.URL, Version: tt.version}, "")
+		var out map[string]interface{}
+		err := c.Get(context.Background(), "/version", nil, &out)
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accept != tt.want {
+			t.Errorf("version %q: Accept %q, want %q", tt.version, accept, tt.want)
+		}
+	}
+}
+
+// A refused connection is reported as an unreachable gateway, while gateway
+// errors pass through unchanged
+func TestClientUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"code":"invalid_request","message":"regions is required"}}`))
+	}))
+	up := NewClient(config.APIEndpoint{URL: srv.URL}, "", WithRetryPolicy(RetryPolicy{}))
+	err := up.Get(context.Background(), "/placements", nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "regions is required" {
+		t.Errorf("Get from a running gateway = %v, want its 400 error", err)
+	}
+	var unreachable *UnreachableError
+	if errors.As(err, &unreachable) {
+		t.Errorf("Get from a running gateway reported it unreachable: %v", err)
+	}
+
+	srv.Close()
+	down := NewClient(config.APIEndpoint{URL: srv.URL}, "", WithRetryPolicy(RetryPolicy{}))
+	err = down.Get(context.Background(), "/placements", nil, nil)
+	if !errors.As(err, &unreachable) || unreachable.Endpoint != srv.URL {
+		t.Fatalf("Get from a stopped gateway = %v, want it unreachable", err)
+	}
+	want := "Cannot reach optimizer API at " + srv.URL + "; check it's running or set api_endpoints.optimizer"
+	if err.Error() != want {
+		t.Errorf("error %q, want %q", err, want)
+	}
+}
+
+// Offline clients serve reads from the responses cached while online
+func TestClientOffline(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"plc-1"}`))
+	}))
+	online := NewClient(config.APIEndpoint{URL: srv.URL}, "", WithRetryPolicy(RetryPolicy{}), WithCache(dir))
+	var out map[string]interface{}
+	if err := online.Get(context.Background(), "/placements/plc-1", nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	err := online.Get(context.Background(), "/placements/plc-1", nil, &out)
+	if err == nil || !strings.Contains(err.Error(), "is available with --offline") {
+		t.Errorf("Get with a cached response = %v, want --offline suggested", err)
+	}
+
+	offline := NewClient(config.APIEndpoint{URL: srv.URL}, "", WithCache(dir), WithOffline(true))
+	out = nil
+	if err := offline.Get(context.Background(), "/placements/plc-1", nil, &out); err != nil || out["id"] != "plc-1" {
+		t.Errorf("offline Get = %v, %v, want the cached placement", out, err)
+	}
+	if err := offline.Get(context.Background(), "/placements/plc-2", nil, &out); err == nil {
+		t.Error("offline Get of an uncached placement succeeded")
+	}
+	if err := offline.Post(context.Background(), "/placements/compute", map[string]string{}, nil); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline Post = %v, want ErrOffline", err)
+	}
+}
+
+// Streamed cost items reach the callback as each line arrives, before the
+// gateway completes the response
+func TestStreamCosts(t *testing.T) {
+	first := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "application/x-ndjson" {
+			t.Errorf("Accept %q, want application/x-ndjson", accept)
+		}
+		w.Write([]byte(`{"provider":"aws","service":"ec2","amount":1}` + "\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-first:
+		case <-time.After(5 * time.Second):
+			t.Error("first item not received before the stream completed")
+		}
+		w.Write([]byte(`{"provider":"gcp","service":"gce","amount":2}` + "\n"))
+	}))
+	defer srv.Close()
+
+	c := NewClient(config.APIEndpoint{URL: srv.URL}, "")
+	var got []string
+	err := c.StreamCosts(context.Background(), CostQuery{}, func(item CostLineItem) error {
+		if len(got) == 0 {
+			close(first)
+		}
+		got = append(got, item.Provider)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "aws,gcp" {
+		t.Errorf("providers %v, want aws,gcp", got)
+	}
+}
+
+// A malformed line or a callback error ends the stream with an error
+func TestStreamCostsErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"provider":"aws","amount":1}` + "\n" + `{"provider":` + "\n"))
+	}))
+	defer srv.Close()
+	c := NewClient(config.APIEndpoint{URL: srv.URL}, "")
+
+	calls := 0
+	err := c.StreamCosts(context.Background(), CostQuery{}, func(CostLineItem) error {
+		calls++
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to decode cost line item") || calls != 1 {
+		t.Errorf("malformed line: %v after %d items, want a decode error after 1", err, calls)
+	}
+
+	errStop := errors.New("stop")
+	if err := c.StreamCosts(context.Background(), CostQuery{}, func(CostLineItem) error {
+		return errStop
+	}); err != errStop {
+		t.Errorf("callback error: got %v, want %v", err, errStop)
+	}
+}
+
+// Errors are read from the gateway's envelope, from the plain error objects
+// of its middleware, or from the raw body
+func TestAPIErrorEnvelope(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   string
+		wantFields []FieldError
+		wantError  string
+	}{
+		{
+			name:       "envelope",
+			body:       `{"error":{"code":"validation_failed","message":"request validation failed","fields":[{"field":"resources[0].region","message":"is required"},{"field":"resources[1].id","message":"is required"}]}}`,
+			wantCode:   "validation_failed",
+			wantFields: []FieldError{{Field: "resources[0].region", Message: "is required"}, {Field: "resources[1].id", Message: "is required"}},
+			wantError:  "request failed with status 400: request validation failed (resources[0].region is required; resources[1].id is required)",
+		},
+		{name: "plain", body: `{"error":"rate limit exceeded"}`, wantError: "request failed with status 400: rate limit exceeded"},
+		{name: "raw", body: "bad gateway\n", wantError: "request failed with status 400: bad gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			err := NewClient(config.APIEndpoint{URL: srv.URL}, "", WithRetryPolicy(RetryPolicy{})).Get(context.Background(), "/resources", nil, nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error %v, want an API error", err)
+			}
+			if apiErr.Code != tt.wantCode || !reflect.DeepEqual(apiErr.Fields, tt.wantFields) || apiErr.Error() != tt.wantError {
+				t.Errorf("error %q with code %q and fields %+v, want %q with %q and %+v", apiErr, apiErr.Code, apiErr.Fields, tt.wantError, tt.wantCode, tt.wantFields)
+			}
+		})
+	}
+}
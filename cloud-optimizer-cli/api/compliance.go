@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// knownComplianceFrameworks lists the framework IDs in the gateway's
+// compliance catalog at the time of this release
+var knownComplianceFrameworks = []string{"FedRAMP", "GDPR", "HIPAA", "ISO27001", "PCI-DSS", "SOC2"}
+
+// ValidateComplianceFrameworks checks that every ID names a framework in the
+// compliance catalog. IDs are matched exactly, as the gateway does; a
+// case-insensitive match is suggested in the error.
+func ValidateComplianceFrameworks(ids []string) error {
+	for _, id := range ids {
+		known := false
+		suggestion := ""
+		for _, f := range knownComplianceFrameworks {
+			if f == id {
+				known = true
+				break
+			}
+			if strings.EqualFold(f, id) {
+				suggestion = f
+			}
+		}
+		if known {
+			continue
+		}
+
+		if suggestion != "" {
+			return fmt.Errorf("unknown compliance framework %q (did you mean %q?)", id, suggestion)
+		}
+		return fmt.Errorf("unknown compliance framework %q (known: %s)", id, strings.Join(knownComplianceFrameworks, ", "))
+	}
+	return nil
+}
+
+// Results of a compliance control evaluation
+const (
+	ControlPass = "pass"
+	ControlFail = "fail"
+)
+
+// RegionAttestation is a region a placement runs in, with the compliance
+// frameworks it is certified for
+type RegionAttestation struct {
+	Provider   string   `json:"provider" yaml:"provider"`
+	Region     string   `json:"region" yaml:"region"`
+	Frameworks []string `json:"frameworks" yaml:"frameworks"`
+}
+
+// ControlEvidence is the data a control's result is based on. Uncertified
+// lists the regions, as provider/region, not certified for the framework.
+type ControlEvidence struct {
+	Regions     []RegionAttestation `json:"regions" yaml:"regions"`
+	Uncertified []string            `json:"uncertified,omitempty" yaml:"uncertified,omitempty"`
+	Reason      string              `json:"reason" yaml:"reason"`
+}
+
+// ControlResult is the evaluation of one control of a claimed framework
+type ControlResult struct {
+	Framework   string          `json:"framework" yaml:"framework"`
+	ControlID   string          `json:"control_id" yaml:"control_id"`
+	Description string          `json:"description" yaml:"description"`
+	Result      string          `json:"result" yaml:"result"`
+	Evidence    ControlEvidence `json:"evidence" yaml:"evidence"`
+}
+
+// ComplianceEvidence is the compliance evidence bundle of a placement
+type ComplianceEvidence struct {
+	PlacementID  string          `json:"placement_id" yaml:"placement_id"`
+	ResourceType string          `json:"resource_type" yaml:"resource_type"`
+	Frameworks   []string        `json:"frameworks" yaml:"frameworks"`
+	Controls     []ControlResult `json:"controls" yaml:"controls"`
+	Passed       bool            `json:"passed" yaml:"passed"`
+	GeneratedAt  time.Time       `json:"generated_at" yaml:"generated_at"`
+}
+
+// ComplianceEvidence returns the compliance evidence bundle of a placement
+func (c *Client) ComplianceEvidence(ctx context.Context, placementID string) (*ComplianceEvidence, error) {
+	var evidence ComplianceEvidence
+	if err := c.Get(ctx, "/compliance/evidence", url.Values{"placement_id": {placementID}}, &evidence); err != nil {
+		return nil, err
+	}
+	return &evidence, nil
+}
+
+// ComplianceEvidencePDF returns the compliance evidence bundle of a placement
+// rendered by the gateway as a PDF document
+func (c *Client) ComplianceEvidencePDF(ctx context.Context, placementID string) ([]byte, error) {
+	query := url.Values{"placement_id": {placementID}, "format": {"pdf"}}
+	resp, err := c.openStream(ctx, "/compliance/evidence", query, "application/pdf")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateComplianceFrameworks(t *testing.T) {
+	tests := []struct {
+		ids     []string
+		wantErr string
+	}{
+		{},
+		{ids: []string{"GDPR", "SOC2", "PCI-DSS"}},
+		{ids: []string{"GDPR", "hipaa"}, wantErr: `unknown compliance framework "hipaa" (did you mean "HIPAA"?)`},
+		{ids: []string{"SOX"}, wantErr: `unknown compliance framework "SOX" (known: FedRAMP, GDPR`},
+	}
+
+	for _, tt := range tests {
+		err := ValidateComplianceFrameworks(tt.ids)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateComplianceFrameworks(%v) = %v, want nil", tt.ids, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateComplianceFrameworks(%v) = %v, want %s", tt.ids, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CostLineItem is a single cost record for one resource on one day
+type CostLineItem struct {
+	Date       time.Time         `json:"date" yaml:"date"`
+	Provider   string            `json:"provider" yaml:"provider"`
+	Service    string            `json:"service" yaml:"service"`
+	Region     string            `json:"region" yaml:"region"`
+	ResourceID string            `json:"resource_id,omitempty" yaml:"resource_id,omitempty"`
+	Amount     float64           `json:"amount" yaml:"amount"`
+	Currency   string            `json:"currency" yaml:"currency"`
+	Tags       map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// CostAnalysis is the cost of a period returned by GET /costs
+type CostAnalysis struct {
+	TotalCost   float64        `json:"total_cost" yaml:"total_cost"`
+	Currency    string         `json:"currency" yaml:"currency"`
+	PeriodStart time.Time      `json:"period_start" yaml:"period_start"`
+	PeriodEnd   time.Time      `json:"period_end" yaml:"period_end"`
+	Breakdown   CostBreakdown  `json:"breakdown" yaml:"breakdown"`
+	Items       []CostLineItem `json:"items" yaml:"items"`
+}
+
+// CostBreakdown totals costs along several dimensions
+type CostBreakdown struct {
+	ByService map[string]float64 `json:"by_service" yaml:"by_service"`
+	ByRegion  map[string]float64 `json:"by_region" yaml:"by_region"`
+	ByTag     map[string]float64 `json:"by_tag" yaml:"by_tag"`
+}
+
+// CostGroup is the total cost of one summary group
+type CostGroup struct {
+	Key   string  `json:"key" yaml:"key"`
+	Cost  float64 `json:"cost" yaml:"cost"`
+	Share float64 `json:"share" yaml:"share"`
+}
+
+// CostSummary is the cost of a period grouped along one dimension
+type CostSummary struct {
+	GroupBy     string      `json:"group_by" yaml:"group_by"`
+	TotalCost   float64     `json:"total_cost" yaml:"total_cost"`
+	Currency    string      `json:"currency" yaml:"currency"`
+	PeriodStart time.Time   `json:"period_start" yaml:"period_start"`
+	PeriodEnd   time.Time   `json:"period_end" yaml:"period_end"`
+	Groups      []CostGroup `json:"groups" yaml:"groups"`
+}
+
+// DailyCost is the cost of a single day
+type DailyCost struct {
+	Date time.Time `json:"date" yaml:"date"`
+	Cost float64   `json:"cost" yaml:"cost"`
+}
+
+// CostForecast is a projection of daily costs
+type CostForecast struct {
+	Model         string      `json:"model" yaml:"model"`
+	Alpha         float64     `json:"alpha,omitempty" yaml:"alpha,omitempty"`
+	Smoothed      bool        `json:"smoothed" yaml:"smoothed"`
+	Currency      string      `json:"currency" yaml:"currency"`
+	HorizonDays   int         `json:"horizon_days" yaml:"horizon_days"`
+	HistoryDays   int         `json:"history_days" yaml:"history_days"`
+	ForecastTotal float64     `json:"forecast_total" yaml:"forecast_total"`
+	Daily         []DailyCost `json:"daily" yaml:"daily"`
+
+	// Lower and Upper bound each day's forecast at ConfidenceLevel
+	ConfidenceLevel float64     `json:"confidence_level" yaml:"confidence_level"`
+	Lower           []DailyCost `json:"lower" yaml:"lower"`
+	Upper           []DailyCost `json:"upper" yaml:"upper"`
+}
+
+// TimeSeries is a series in the format of Grafana's JSON data source: each
+// datapoint is a [value, Unix time in milliseconds] pair
+type TimeSeries struct {
+	Target     string       `json:"target" yaml:"target"`
+	Datapoints [][2]float64 `json:"datapoints" yaml:"datapoints"`
+}
+
+// CostQuery selects the period and scope of a cost request. Dates are
+// YYYY-MM-DD; empty fields use the gateway defaults.
+type CostQuery struct {
+	StartDate string
+	EndDate   string
+	Provider  string
+	Region    string
+}
+
+// Values encodes the query as URL parameters
+func (q CostQuery) Values() url.Values {
+	values := url.Values{}
+	setIfNotEmpty(values, "start_date", q.StartDate)
+	setIfNotEmpty(values, "end_date", q.EndDate)
+	setIfNotEmpty(values, "provider", q.Provider)
+	setIfNotEmpty(values, "region", q.Region)
+	return values
+}
+
+// Costs returns the cost line items and totals for the query
+func (c *Client) Costs(ctx context.Context, q CostQuery) (*CostAnalysis, error) {
+	var analysis CostAnalysis
+	if err := c.Get(ctx, "/costs", q.Values(), &analysis); err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+// StreamCosts fetches the cost line items for the query as newline-delimited
+// JSON, calling fn for each item as it arrives instead of buffering the whole
+// period. It stops at the first error returned by fn.
+func (c *Client) StreamCosts(ctx context.Context, q CostQuery, fn func(CostLineItem) error) error {
+	resp, err := c.openStream(ctx, "/costs", q.Values(), "application/x-ndjson")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var item CostLineItem
+		if err := dec.Decode(&item); err == io.EOF {
+			return nil
+		} else if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to decode cost line item: %v", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+// CostSummary returns the costs for the query grouped by groupBy
+func (c *Client) CostSummary(ctx context.Context, q CostQuery, groupBy string) (*CostSummary, error) {
+	values := q.Values()
+	setIfNotEmpty(values, "group_by", groupBy)
+
+	var summary CostSummary
+	if err := c.Get(ctx, "/costs/summary", values, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// ForecastOptions selects the forecast model. Zero values use the gateway
+// defaults: the linear model and an alpha of 0.3.
+type ForecastOptions struct {
+	Model     string
+	Alpha     float64
+	Smoothing bool
+}
+
+// CostForecast returns a forecast of the next horizonDays days from the
+// history selected by the query
+func (c *Client) CostForecast(ctx context.Context, q CostQuery, horizonDays int, opts ForecastOptions) (*CostForecast, error) {
+	var forecast CostForecast
+	if err := c.Get(ctx, "/costs/forecast", forecastValues(q, horizonDays, opts), &forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// CostForecastSeries returns the forecast as Grafana time series: the
+// history, the forecast and its lower and upper confidence bounds as
+// separate series
+func (c *Client) CostForecastSeries(ctx context.Context, q CostQuery, horizonDays int, opts ForecastOptions) ([]TimeSeries, error) {
+	values := forecastValues(q, horizonDays, opts)
+	values.Set("format", "grafana")
+
+	var series []TimeSeries
+	if err := c.Get(ctx, "/costs/forecast", values, &series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// forecastValues returns the query parameters of a forecast request
+func forecastValues(q CostQuery, horizonDays int, opts ForecastOptions) url.Values {
+	values := q.Values()
+	if horizonDays > 0 {
+		values.Set("horizon", strconv.Itoa(horizonDays))
+	}
+	setIfNotEmpty(values, "model", opts.Model)
+	if opts.Alpha != 0 {
+		values.Set("alpha", strconv.FormatFloat(opts.Alpha, 'g', -1, 64))
+	}
+	if opts.Smoothing {
+		values.Set("smoothing", "true")
+	}
+	return values
+}
+
+// AttributedCost is the spend attributed to one value of a tag dimension
+type AttributedCost struct {
+	Value      string             `json:"value" yaml:"value"`
+	Cost       float64            `json:"cost" yaml:"cost"`
+	Share      float64            `json:"share" yaml:"share"`
+	ByProvider map[string]float64 `json:"by_provider" yaml:"by_provider"`
+}
+
+// CostAttribution is the spend of a period across all providers attributed
+// to the values of one tag, converted to a single currency
+type CostAttribution struct {
+	Dimension   string             `json:"dimension" yaml:"dimension"`
+	TotalCost   float64            `json:"total_cost" yaml:"total_cost"`
+	Currency    string             `json:"currency" yaml:"currency"`
+	PeriodStart time.Time          `json:"period_start" yaml:"period_start"`
+	PeriodEnd   time.Time          `json:"period_end" yaml:"period_end"`
+	ByProvider  map[string]float64 `json:"by_provider" yaml:"by_provider"`
+	Values      []AttributedCost   `json:"values" yaml:"values"`
+}
+
+// CostAttribution returns the costs for the query attributed to the values
+// of the dimension tag
+func (c *Client) CostAttribution(ctx context.Context, q CostQuery, dimension string) (*CostAttribution, error) {
+	values := q.Values()
+	values.Set("dimension", dimension)
+
+	var attribution CostAttribution
+	if err := c.Get(ctx, "/costs/attribution", values, &attribution); err != nil {
+		return nil, err
+	}
+	return &attribution, nil
+}
+
+func setIfNotEmpty(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
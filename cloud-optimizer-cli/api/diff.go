@@ -0,0 +1,140 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// diffTolerance is the smallest cost or score difference reported as a change
+const diffTolerance = 1e-9
+
+// StringChange is a changed text field of a placement
+type StringChange struct {
+	Old string `json:"old" yaml:"old"`
+	New string `json:"new" yaml:"new"`
+}
+
+// ValueChange is a changed cost or score of a placement. Delta is New - Old.
+type ValueChange struct {
+	Old   float64 `json:"old" yaml:"old"`
+	New   float64 `json:"new" yaml:"new"`
+	Delta float64 `json:"delta" yaml:"delta"`
+}
+
+// PlacementDiff is what changed between two decisions of a placement. Only
+// changed fields are set. Created is set when there was no old decision and
+// Removed when there is no new one; the fields then hold the other side's
+// values against empty ones.
+type PlacementDiff struct {
+	Created          bool          `json:"created,omitempty" yaml:"created,omitempty"`
+	Removed          bool          `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Provider         *StringChange `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Region           *StringChange `json:"region,omitempty" yaml:"region,omitempty"`
+	InstanceType     *StringChange `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+	MonthlyCost      *ValueChange  `json:"monthly_cost,omitempty" yaml:"monthly_cost,omitempty"`
+	PerformanceScore *ValueChange  `json:"performance_score,omitempty" yaml:"performance_score,omitempty"`
+	ComplianceScore  *ValueChange  `json:"compliance_score,omitempty" yaml:"compliance_score,omitempty"`
+	TotalScore       *ValueChange  `json:"total_score,omitempty" yaml:"total_score,omitempty"`
+}
+
+// DiffPlacements compares two decisions of a placement, either of which may
+// be nil. The monthly cost of a multi-region placement is its aggregate cost.
+func DiffPlacements(old, updated *Placement) *PlacementDiff {
+	d := &PlacementDiff{Created: old == nil && updated != nil, Removed: old != nil && updated == nil}
+	if old == nil {
+		old = &Placement{}
+	}
+	if updated == nil {
+		updated = &Placement{}
+	}
+
+	d.Provider = diffString(old.SelectedProvider, updated.SelectedProvider)
+	d.Region = diffString(old.SelectedRegion, updated.SelectedRegion)
+	d.InstanceType = diffString(old.InstanceType, updated.InstanceType)
+	d.MonthlyCost = diffValue(old.monthlyCost(), updated.monthlyCost())
+	d.PerformanceScore = diffValue(old.PerformanceScore, updated.PerformanceScore)
+	d.ComplianceScore = diffValue(old.ComplianceScore, updated.ComplianceScore)
+	d.TotalScore = diffValue(old.TotalScore, updated.TotalScore)
+	return d
+}
+
+// Changed reports whether anything differs between the two decisions
+func (d *PlacementDiff) Changed() bool {
+	return d.Created || d.Removed || d.Provider != nil || d.Region != nil || d.InstanceType != nil ||
+		d.MonthlyCost != nil || d.PerformanceScore != nil || d.ComplianceScore != nil || d.TotalScore != nil
+}
+
+// Summary describes the diff in readable lines, e.g. "provider: aws -> gcp"
+func (d *PlacementDiff) Summary() string {
+	if !d.Changed() {
+		return "no changes"
+	}
+
+	var lines []string
+	switch {
+	case d.Created:
+		lines = append(lines, "new placement")
+	case d.Removed:
+		lines = append(lines, "placement removed")
+	}
+	for _, c := range []struct {
+		name   string
+		change *StringChange
+	}{
+		{"provider", d.Provider},
+		{"region", d.Region},
+		{"instance type", d.InstanceType},
+	} {
+		if c.change != nil {
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", c.name, orNone(c.change.Old), orNone(c.change.New)))
+		}
+	}
+	if c := d.MonthlyCost; c != nil {
+		lines = append(lines, fmt.Sprintf("monthly cost: $%.2f -> $%.2f (%+.2f)", c.Old, c.New, c.Delta))
+	}
+	for _, c := range []struct {
+		name   string
+		change *ValueChange
+	}{
+		{"performance score", d.PerformanceScore},
+		{"compliance score", d.ComplianceScore},
+		{"total score", d.TotalScore},
+	} {
+		if c.change != nil {
+			lines = append(lines, fmt.Sprintf("%s: %.3f -> %.3f (%+.3f)", c.name, c.change.Old, c.change.New, c.change.Delta))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// monthlyCost returns the aggregate cost of a multi-region placement, or
+// else its estimated monthly cost
+func (p *Placement) monthlyCost() float64 {
+	if p.AggregateMonthlyCost > 0 {
+		return p.AggregateMonthlyCost
+	}
+	return p.EstimatedMonthlyCost
+}
+
+func diffString(old, updated string) *StringChange {
+	if old == updated {
+		return nil
+	}
+	return &StringChange{Old: old, New: updated}
+}
+
+func diffValue(old, updated float64) *ValueChange {
+	if math.Abs(updated-old) < diffTolerance {
+		return nil
+	}
+	return &ValueChange{Old: old, New: updated, Delta: updated - old}
+}
+
+// orNone returns s, or "(none)" when it is empty
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
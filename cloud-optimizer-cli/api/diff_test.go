@@ -0,0 +1,82 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffPlacements(t *testing.T) {
+	base := &Placement{SelectedProvider: "aws", SelectedRegion: "us-east-1", InstanceType: "m5.large", EstimatedMonthlyCost: 70, PerformanceScore: 0.8, ComplianceScore: 1, TotalScore: 0.75}
+
+	tests := []struct {
+		name        string
+		old         *Placement
+		updated     *Placement
+		want        *PlacementDiff
+		wantSummary string
+	}{
+		{
+			name:    "provider change",
+			old:     base,
+			updated: &Placement{SelectedProvider: "gcp", SelectedRegion: "us-central1", InstanceType: "n2-standard-2", EstimatedMonthlyCost: 60, PerformanceScore: 0.8, ComplianceScore: 1, TotalScore: 0.875},
+			want: &PlacementDiff{
+				Provider:     &StringChange{Old: "aws", New: "gcp"},
+				Region:       &StringChange{Old: "us-east-1", New: "us-central1"},
+				InstanceType: &StringChange{Old: "m5.large", New: "n2-standard-2"},
+				MonthlyCost:  &ValueChange{Old: 70, New: 60, Delta: -10},
+				TotalScore:   &ValueChange{Old: 0.75, New: 0.875, Delta: 0.125},
+			},
+			wantSummary: "provider: aws -> gcp\nregion: us-east-1 -> us-central1\ninstance type: m5.large -> n2-standard-2\n" +
+				"monthly cost: $70.00 -> $60.00 (-10.00)\ntotal score: 0.750 -> 0.875 (+0.125)",
+		},
+		{
+			name:        "cost only",
+			old:         base,
+			updated:     &Placement{SelectedProvider: "aws", SelectedRegion: "us-east-1", InstanceType: "m5.large", EstimatedMonthlyCost: 72.5, PerformanceScore: 0.8, ComplianceScore: 1, TotalScore: 0.75},
+			want:        &PlacementDiff{MonthlyCost: &ValueChange{Old: 70, New: 72.5, Delta: 2.5}},
+			wantSummary: "monthly cost: $70.00 -> $72.50 (+2.50)",
+		},
+		{
+			name:    "new placement",
+			updated: &Placement{SelectedProvider: "azure", SelectedRegion: "westeurope", EstimatedMonthlyCost: 80},
+			want: &PlacementDiff{
+				Created:     true,
+				Provider:    &StringChange{New: "azure"},
+				Region:      &StringChange{New: "westeurope"},
+				MonthlyCost: &ValueChange{New: 80, Delta: 80},
+			},
+			wantSummary: "new placement\nprovider: (none) -> azure\nregion: (none) -> westeurope\nmonthly cost: $0.00 -> $80.00 (+80.00)",
+		},
+		{
+			name: "removed placement",
+			old:  &Placement{SelectedProvider: "azure", SelectedRegion: "westeurope"},
+			want: &PlacementDiff{
+				Removed:  true,
+				Provider: &StringChange{Old: "azure"},
+				Region:   &StringChange{Old: "westeurope"},
+			},
+			wantSummary: "placement removed\nprovider: azure -> (none)\nregion: westeurope -> (none)",
+		},
+		{
+			name:        "multi-region aggregate cost",
+			old:         &Placement{SelectedProvider: "aws", EstimatedMonthlyCost: 70, AggregateMonthlyCost: 140},
+			updated:     &Placement{SelectedProvider: "aws", EstimatedMonthlyCost: 70, AggregateMonthlyCost: 210},
+			want:        &PlacementDiff{MonthlyCost: &ValueChange{Old: 140, New: 210, Delta: 70}},
+			wantSummary: "monthly cost: $140.00 -> $210.00 (+70.00)",
+		},
+		{name: "unchanged", old: base, updated: base, want: &PlacementDiff{}, wantSummary: "no changes"},
+		{name: "both nil", want: &PlacementDiff{}, wantSummary: "no changes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffPlacements(tt.old, tt.updated)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffPlacements() = %+v, want %+v", got, tt.want)
+			}
+			if summary := got.Summary(); summary != tt.wantSummary {
+				t.Errorf("Summary() =\n%s\nwant\n%s", summary, tt.wantSummary)
+			}
+		})
+	}
+}
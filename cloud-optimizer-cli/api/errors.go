@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+// ErrOffline is returned for requests that cannot be served in offline mode
+var ErrOffline = errors.New("not available in offline mode")
+
+// UnreachableError is returned when the gateway cannot be contacted at all,
+// as opposed to the gateway responding with an error
+type UnreachableError struct {
+	Endpoint string
+	Err      error
+	// CachedAt is when a cached response to the request was stored, or zero
+	// if none is available
+	CachedAt time.Time
+}
+
+func (e *UnreachableError) Error() string {
+	msg := fmt.Sprintf("Cannot reach optimizer API at %s; check it's running or set api_endpoints.optimizer", e.Endpoint)
+	if !e.CachedAt.IsZero() {
+		msg += fmt.Sprintf(" (cached data from %s is available with --offline)", e.CachedAt.Format(time.RFC3339))
+	}
+	return msg
+}
+
+func (e *UnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// isUnreachable reports whether a transport error means the gateway could
+// not be contacted: the connection was refused or reset, the host could not
+// be resolved, or the request timed out
+func isUnreachable(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// MultiRegion requests an active-active deployment across several regions
+type MultiRegion struct {
+	RegionWeights map[string]float64 `json:"region_weights,omitempty" yaml:"region_weights,omitempty"`
+	MinRegions    int                `json:"min_regions" yaml:"min_regions"`
+}
+
+// ComputeRequirements describes a compute placement request
+type ComputeRequirements struct {
+	Name                  string            `json:"name" yaml:"name"`
+	VCPUs                 int               `json:"vcpus" yaml:"vcpus"`
+	MemoryGB              float64           `json:"memory_gb" yaml:"memory_gb"`
+	Regions               []string          `json:"regions,omitempty" yaml:"regions,omitempty"`
+	MinAvailability       float64           `json:"min_availability,omitempty" yaml:"min_availability,omitempty"`
+	SLATier               string            `json:"sla_tier,omitempty" yaml:"sla_tier,omitempty"`
+	MaxMonthlyBudget      *float64          `json:"max_monthly_budget,omitempty" yaml:"max_monthly_budget,omitempty"`
+	ExcludedProviders     []string          `json:"excluded_providers,omitempty" yaml:"excluded_providers,omitempty"`
+	ExcludedRegions       []string          `json:"excluded_regions,omitempty" yaml:"excluded_regions,omitempty"`
+	ExcludedInstanceTypes []string          `json:"excluded_instance_types,omitempty" yaml:"excluded_instance_types,omitempty"`
+	ComplianceFrameworks  []string          `json:"compliance_frameworks,omitempty" yaml:"compliance_frameworks,omitempty"`
+	MultiRegion           *MultiRegion      `json:"multi_region,omitempty" yaml:"multi_region,omitempty"`
+	Tags                  map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	// Metadata is opaque data stored with the placement and echoed back
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	// CurrentMonthlyCost is what the workload costs today, which the
+	// placement's savings are estimated against
+	CurrentMonthlyCost *float64 `json:"current_monthly_cost,omitempty" yaml:"current_monthly_cost,omitempty"`
+}
+
+// validSLATiers are the SLA tiers the gateway accepts
+var validSLATiers = map[string]bool{"bronze": true, "silver": true, "gold": true, "platinum": true}
+
+// Validate checks that the requirements are well formed before they are sent
+func (r *ComputeRequirements) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.VCPUs < 1 {
+		return fmt.Errorf("vcpus must be at least 1")
+	}
+	if r.MemoryGB <= 0 {
+		return fmt.Errorf("memory_gb must be greater than 0")
+	}
+	if r.MinAvailability < 0 || r.MinAvailability > 100 {
+		return fmt.Errorf("min_availability must be between 0 and 100")
+	}
+	if r.SLATier != "" && !validSLATiers[r.SLATier] {
+		return fmt.Errorf("sla_tier must be one of bronze, silver, gold or platinum")
+	}
+	if r.MaxMonthlyBudget != nil && *r.MaxMonthlyBudget < 0 {
+		return fmt.Errorf("max_monthly_budget must not be negative")
+	}
+	if r.CurrentMonthlyCost != nil && *r.CurrentMonthlyCost < 0 {
+		return fmt.Errorf("current_monthly_cost must not be negative")
+	}
+	if r.MultiRegion != nil && r.MultiRegion.MinRegions < 1 {
+		return fmt.Errorf("multi_region.min_regions must be at least 1")
+	}
+	return checkRegionProviders(r.Regions, r.ExcludedProviders, r.ExcludedRegions)
+}
+
+// Alternative is another placement option considered for a placement
+type Alternative struct {
+	Provider         string  `json:"provider" yaml:"provider"`
+	Region           string  `json:"region" yaml:"region"`
+	InstanceType     string  `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+	MonthlyCost      float64 `json:"monthly_cost" yaml:"monthly_cost"`
+	PerformanceScore float64 `json:"performance_score" yaml:"performance_score"`
+	ComplianceScore  float64 `json:"compliance_score" yaml:"compliance_score"`
+	TotalScore       float64 `json:"total_score" yaml:"total_score"`
+	RejectionReason  string  `json:"rejection_reason,omitempty" yaml:"rejection_reason,omitempty"`
+}
+
+// RegionAllocation is one region of a multi-region placement
+type RegionAllocation struct {
+	Provider     string  `json:"provider" yaml:"provider"`
+	Region       string  `json:"region" yaml:"region"`
+	InstanceType string  `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+	Weight       float64 `json:"weight" yaml:"weight"`
+	MonthlyCost  float64 `json:"monthly_cost" yaml:"monthly_cost"`
+}
+
+// Placement is a placement decision returned by the gateway
+type Placement struct {
+	ID                   string                 `json:"id" yaml:"id"`
+	ResourceType         string                 `json:"resource_type" yaml:"resource_type"`
+	Requirements         map[string]interface{} `json:"requirements,omitempty" yaml:"requirements,omitempty"`
+	SelectedProvider     string                 `json:"selected_provider" yaml:"selected_provider"`
+	SelectedRegion       string                 `json:"selected_region" yaml:"selected_region"`
+	InstanceType         string                 `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+	EstimatedMonthlyCost float64                `json:"estimated_monthly_cost" yaml:"estimated_monthly_cost"`
+	CostBreakdown        map[string]float64     `json:"cost_breakdown,omitempty" yaml:"cost_breakdown,omitempty"`
+	PerformanceScore     float64                `json:"performance_score" yaml:"performance_score"`
+	ComplianceScore      float64                `json:"compliance_score" yaml:"compliance_score"`
+	TotalScore           float64                `json:"total_score" yaml:"total_score"`
+	SelectionReason      string                 `json:"selection_reason,omitempty" yaml:"selection_reason,omitempty"`
+	Recommendations      []Alternative          `json:"recommendations" yaml:"recommendations"`
+	RegionAllocations    []RegionAllocation     `json:"region_allocations,omitempty" yaml:"region_allocations,omitempty"`
+	AggregateMonthlyCost float64                `json:"aggregate_monthly_cost,omitempty" yaml:"aggregate_monthly_cost,omitempty"`
+	AchievedSLATier      string                 `json:"achieved_sla_tier,omitempty" yaml:"achieved_sla_tier,omitempty"`
+	Tags                 map[string]string      `json:"tags,omitempty" yaml:"tags,omitempty"`
+	CreatedAt            time.Time              `json:"created_at" yaml:"created_at"`
+	UpdatedAt            time.Time              `json:"updated_at" yaml:"updated_at"`
+	// PriceListDate is when the prices the estimate used were last updated;
+	// PricesStale is set when the gateway considered them outdated
+	PriceListDate *time.Time `json:"price_list_date,omitempty" yaml:"price_list_date,omitempty"`
+	PricesStale   bool       `json:"prices_stale,omitempty" yaml:"prices_stale,omitempty"`
+	// Metadata echoes the requirements' metadata
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	// EstimatedSavings is CurrentMonthlyCost less the placement's monthly
+	// cost; it is negative, with CostIncrease set, when the placement costs
+	// more. SavingsPercent is nil when the current cost is 0.
+	CurrentMonthlyCost *float64 `json:"current_monthly_cost,omitempty" yaml:"current_monthly_cost,omitempty"`
+	EstimatedSavings   *float64 `json:"estimated_savings,omitempty" yaml:"estimated_savings,omitempty"`
+	SavingsPercent     *float64 `json:"savings_percent,omitempty" yaml:"savings_percent,omitempty"`
+	CostIncrease       bool     `json:"cost_increase,omitempty" yaml:"cost_increase,omitempty"`
+	// CustomPricing is set when the costs were estimated with the tenant's
+	// negotiated pricing rather than list prices
+	CustomPricing bool `json:"custom_pricing,omitempty" yaml:"custom_pricing,omitempty"`
+}
+
+// PlacementVersion is a past decision of a placement. CostDelta is the change
+// in estimated monthly cost from the previous version.
+type PlacementVersion struct {
+	Version              int       `json:"version" yaml:"version"`
+	SelectedProvider     string    `json:"selected_provider" yaml:"selected_provider"`
+	SelectedRegion       string    `json:"selected_region" yaml:"selected_region"`
+	InstanceType         string    `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+	EstimatedMonthlyCost float64   `json:"estimated_monthly_cost" yaml:"estimated_monthly_cost"`
+	AggregateMonthlyCost float64   `json:"aggregate_monthly_cost,omitempty" yaml:"aggregate_monthly_cost,omitempty"`
+	TotalScore           float64   `json:"total_score" yaml:"total_score"`
+	CostDelta            float64   `json:"cost_delta" yaml:"cost_delta"`
+	RecordedAt           time.Time `json:"recorded_at" yaml:"recorded_at"`
+}
+
+// PlacementHistory returns the versions of a placement, oldest first
+func (c *Client) PlacementHistory(ctx context.Context, resourceType, id string) ([]PlacementVersion, error) {
+	var versions []PlacementVersion
+	path := fmt.Sprintf("/placements/%s/%s/history", url.PathEscape(resourceType), url.PathEscape(id))
+	if err := c.Get(ctx, path, nil, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// CreateComputePlacement places a compute resource
+func (c *Client) CreateComputePlacement(ctx context.Context, req *ComputeRequirements) (*Placement, error) {
+	var p Placement
+	if err := c.Post(ctx, "/placements/compute", req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeletePlacement deletes a placement. A soft delete can be undone with
+// RestorePlacement until the gateway's retention period passes.
+func (c *Client) DeletePlacement(ctx context.Context, resourceType, id string, soft bool) error {
+	var query url.Values
+	if soft {
+		query = url.Values{"soft": {"true"}}
+	}
+	return c.Delete(ctx, fmt.Sprintf("/placements/%s/%s", url.PathEscape(resourceType), url.PathEscape(id)), query)
+}
+
+// RestorePlacement restores a soft-deleted placement
+func (c *Client) RestorePlacement(ctx context.Context, resourceType, id string) (*Placement, error) {
+	var p Placement
+	path := fmt.Sprintf("/placements/%s/%s/restore", url.PathEscape(resourceType), url.PathEscape(id))
+	if err := c.Post(ctx, path, nil, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"net/url"
+)
+
+// RegionCapabilities is what a provider offers in one region
+type RegionCapabilities struct {
+	Region               string   `json:"region" yaml:"region"`
+	Features             []string `json:"features" yaml:"features"`
+	ComplianceFrameworks []string `json:"compliance_frameworks" yaml:"compliance_frameworks"`
+	SpotAvailable        bool     `json:"spot_available" yaml:"spot_available"`
+}
+
+// ProviderCapabilities is what a provider offers: its instance families and
+// the features and compliance frameworks of each region
+type ProviderCapabilities struct {
+	Provider         string               `json:"provider" yaml:"provider"`
+	InstanceFamilies []string             `json:"instance_families" yaml:"instance_families"`
+	Regions          []RegionCapabilities `json:"regions" yaml:"regions"`
+}
+
+// ProviderCapabilities returns what the provider supports in every region, or
+// in the given region only when it is set
+func (c *Client) ProviderCapabilities(ctx context.Context, provider, region string) (*ProviderCapabilities, error) {
+	var query url.Values
+	if region != "" {
+		query = url.Values{"region": {region}}
+	}
+
+	var caps ProviderCapabilities
+	if err := c.Get(ctx, "/providers/"+url.PathEscape(provider)+"/capabilities", query, &caps); err != nil {
+		return nil, err
+	}
+	return &caps, nil
+}
@@ -0,0 +1,36 @@
+package api
+
+import "context"
+
+// Recommendation actions
+const (
+	ActionTag       = "tag"
+	ActionResize    = "resize"
+	ActionMigrate   = "migrate"
+	ActionTerminate = "terminate"
+)
+
+// Recommendation is an optimization opportunity found by an analysis
+type Recommendation struct {
+	ID                   string                 `json:"id" yaml:"id"`
+	Type                 string                 `json:"type" yaml:"type"`
+	Action               string                 `json:"action,omitempty" yaml:"action,omitempty"`
+	Priority             string                 `json:"priority" yaml:"priority"`
+	ResourceID           string                 `json:"resource_id,omitempty" yaml:"resource_id,omitempty"`
+	Description          string                 `json:"description" yaml:"description"`
+	EstimatedSavings     float64                `json:"estimated_savings" yaml:"estimated_savings"`
+	ImplementationEffort string                 `json:"implementation_effort,omitempty" yaml:"implementation_effort,omitempty"`
+	Details              map[string]interface{} `json:"details,omitempty" yaml:"details,omitempty"`
+	// Effort is trivial, low, medium or high; Risk is low, medium or high
+	Effort string `json:"effort,omitempty" yaml:"effort,omitempty"`
+	Risk   string `json:"risk,omitempty" yaml:"risk,omitempty"`
+}
+
+// Recommendations returns the open (not snoozed) recommendations
+func (c *Client) Recommendations(ctx context.Context) ([]Recommendation, error) {
+	var recommendations []Recommendation
+	if err := c.Get(ctx, "/optimize/recommendations", nil, &recommendations); err != nil {
+		return nil, err
+	}
+	return recommendations, nil
+}
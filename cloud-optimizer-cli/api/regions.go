@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// knownProviderRegions lists the regions of each provider in the gateway's
+// placement catalog at the time of this release
+var knownProviderRegions = map[string][]string{
+	"aws":   {"us-east-1", "us-west-2", "eu-west-1"},
+	"azure": {"eastus", "westus2", "westeurope"},
+	"gcp":   {"us-central1", "us-east1", "europe-west1"},
+}
+
+// checkRegionProviders returns an error listing the conflicts when no
+// requested region (any known region when none are requested) survives the
+// provider and region exclusions. Regions the catalog does not know may exist
+// on a newer gateway, so they are assumed satisfiable.
+func checkRegionProviders(regions, excludedProviders, excludedRegions []string) error {
+	providerOf := make(map[string]string)
+	var all []string
+	for provider, rs := range knownProviderRegions {
+		for _, r := range rs {
+			providerOf[r] = provider
+			all = append(all, r)
+		}
+	}
+	if len(regions) == 0 {
+		sort.Strings(all)
+		regions = all
+	}
+
+	isExcludedProvider := make(map[string]bool, len(excludedProviders))
+	for _, p := range excludedProviders {
+		isExcludedProvider[p] = true
+	}
+	isExcludedRegion := make(map[string]bool, len(excludedRegions))
+	for _, r := range excludedRegions {
+		isExcludedRegion[r] = true
+	}
+
+	var conflicts []string
+	for _, region := range regions {
+		provider, known := providerOf[region]
+		switch {
+		case isExcludedRegion[region]:
+			conflicts = append(conflicts, region+" is excluded")
+		case !known:
+			return nil
+		case isExcludedProvider[provider]:
+			conflicts = append(conflicts, fmt.Sprintf("%s belongs to %s, which is excluded", region, provider))
+		default:
+			return nil
+		}
+	}
+	return fmt.Errorf("no region can be placed with an allowed provider: %s", strings.Join(conflicts, "; "))
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRegionProviders(t *testing.T) {
+	tests := []struct {
+		name              string
+		regions           []string
+		excludedProviders []string
+		excludedRegions   []string
+		wantErr           string
+	}{
+		{name: "no constraints"},
+		{name: "one region satisfiable", regions: []string{"eastus", "us-east-1"}, excludedProviders: []string{"azure"}},
+		{name: "unknown region", regions: []string{"westeurope", "ap-south-1"}, excludedProviders: []string{"azure"}},
+		{name: "some providers left", excludedProviders: []string{"aws", "azure"}},
+		{
+			name:              "excluded provider",
+			regions:           []string{"eastus", "westus2"},
+			excludedProviders: []string{"azure"},
+			wantErr:           "no region can be placed with an allowed provider: eastus belongs to azure, which is excluded; westus2 belongs to azure, which is excluded",
+		},
+		{
+			name:              "excluded region",
+			regions:           []string{"us-east-1", "eastus"},
+			excludedProviders: []string{"azure"},
+			excludedRegions:   []string{"us-east-1"},
+			wantErr:           "us-east-1 is excluded; eastus belongs to azure",
+		},
+		{
+			name:              "every provider excluded",
+			excludedProviders: []string{"aws", "azure", "gcp"},
+			wantErr:           "eu-west-1 belongs to aws, which is excluded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRegionProviders(tt.regions, tt.excludedProviders, tt.excludedRegions)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkRegionProviders = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkRegionProviders = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: []string{"eastus"}, ExcludedProviders: []string{"azure"}}
+	if err := req.Validate(); err == nil || !strings.Contains(err.Error(), "eastus belongs to azure") {
+		t.Errorf("Validate = %v, want the region conflict", err)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"net/url"
+)
+
+// ScoredOption is a deployment option with its cost and scores, from 0 to 1
+type ScoredOption struct {
+	Provider         string  `json:"provider" yaml:"provider"`
+	Region           string  `json:"region" yaml:"region"`
+	InstanceType     string  `json:"instance_type,omitempty" yaml:"instance_type,omitempty"`
+	MonthlyCost      float64 `json:"monthly_cost" yaml:"monthly_cost"`
+	PerformanceScore float64 `json:"performance_score" yaml:"performance_score"`
+	ComplianceScore  float64 `json:"compliance_score" yaml:"compliance_score"`
+	TotalScore       float64 `json:"total_score" yaml:"total_score"`
+}
+
+// ResourceEvaluation is the scored current deployment of a resource with
+// better-scoring alternatives
+type ResourceEvaluation struct {
+	Current      ScoredOption   `json:"current" yaml:"current"`
+	Alternatives []ScoredOption `json:"alternatives" yaml:"alternatives"`
+}
+
+// EvaluateResource scores the current deployment of a resource
+func (c *Client) EvaluateResource(ctx context.Context, id string) (*ResourceEvaluation, error) {
+	var eval ResourceEvaluation
+	if err := c.Get(ctx, "/resources/"+url.PathEscape(id)+"/evaluation", nil, &eval); err != nil {
+		return nil, err
+	}
+	return &eval, nil
+}
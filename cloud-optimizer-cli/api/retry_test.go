@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedTransport answers each request with the next status of its script,
+// where 0 stands for a network error, and records the bodies it received
+type scriptedTransport struct {
+	script []int
+	header http.Header
+	calls  int
+	bodies []string
+}
+
+var errNetwork = errors.New("connection reset")
+
+func (t *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, _ := io.ReadAll(req.Body)
+		t.bodies = append(t.bodies, string(body))
+	}
+	status := t.script[len(t.script)-1]
+	if t.calls < len(t.script) {
+		status = t.script[t.calls]
+	}
+	t.calls++
+	if status == 0 {
+		return nil, errNetwork
+	}
+	header := t.header
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		attempts   int
+		script     []int
+		wantCalls  int
+		wantStatus int
+		wantErr    bool
+	}{
+		{name: "success is not retried", method: http.MethodGet, attempts: 3, script: []int{200}, wantCalls: 1, wantStatus: 200},
+		{name: "client errors are not retried", method: http.MethodGet, attempts: 3, script: []int{404}, wantCalls: 1, wantStatus: 404},
+		{name: "503 retried until success", method: http.MethodGet, attempts: 3, script: []int{503, 503, 200}, wantCalls: 3, wantStatus: 200},
+		{name: "retries are capped", method: http.MethodGet, attempts: 2, script: []int{503}, wantCalls: 3, wantStatus: 503},
+		{name: "retrying disabled", method: http.MethodGet, attempts: 0, script: []int{503}, wantCalls: 1, wantStatus: 503},
+		{name: "network errors retried for idempotent methods", method: http.MethodPut, body: "{}", attempts: 3, script: []int{0, 200}, wantCalls: 2, wantStatus: 200},
+		{name: "network errors not retried for POST", method: http.MethodPost, body: "{}", attempts: 3, script: []int{0}, wantCalls: 1, wantErr: true},
+		{name: "429 retried for POST", method: http.MethodPost, body: "{}", attempts: 3, script: []int{429, 200}, wantCalls: 2, wantStatus: 200},
+		{name: "503 retried for POST", method: http.MethodPost, body: "{}", attempts: 3, script: []int{503, 200}, wantCalls: 2, wantStatus: 200},
+		{name: "502 retried for GET", method: http.MethodGet, attempts: 3, script: []int{502, 200}, wantCalls: 2, wantStatus: 200},
+		{name: "502 not retried for POST", method: http.MethodPost, body: "{}", attempts: 3, script: []int{502}, wantCalls: 1, wantStatus: 502},
+		{name: "504 not retried for POST", method: http.MethodPost, body: "{}", attempts: 3, script: []int{504}, wantCalls: 1, wantStatus: 504},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &scriptedTransport{script: tt.script}
+			transport := newRetryTransport(next, RetryPolicy{Attempts: tt.attempts, Backoff: time.Millisecond})
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req, err := http.NewRequest(tt.method, "http://gateway.test/api/v1/resources", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := transport.RoundTrip(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && resp.StatusCode != tt.wantStatus {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if next.calls != tt.wantCalls {
+				t.Errorf("%d attempts, want %d", next.calls, tt.wantCalls)
+			}
+			// Every attempt sends the whole body
+			for i, b := range next.bodies {
+				if b != tt.body {
+					t.Errorf("attempt %d sent body %q, want %q", i+1, b, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportStopsOnCancel(t *testing.T) {
+	next := &scriptedTransport{script: []int{503}}
+	transport := newRetryTransport(next, RetryPolicy{Attempts: 5, Backoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://gateway.test/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RoundTrip error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if next.calls != 1 {
+		t.Errorf("%d attempts, want 1", next.calls)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		attempt    int
+		retryAfter string
+		want       time.Duration
+	}{
+		{name: "first retry waits the base backoff", attempt: 0, want: time.Second},
+		{name: "backoff doubles", attempt: 3, want: 8 * time.Second},
+		{name: "backoff is capped", attempt: 10, want: maxRetryBackoff},
+		{name: "shift overflow is capped", attempt: 70, want: maxRetryBackoff},
+		{name: "Retry-After is honored", attempt: 3, retryAfter: "2", want: 2 * time.Second},
+		{name: "Retry-After is capped", attempt: 0, retryAfter: "600", want: maxRetryBackoff},
+		{name: "Retry-After dates are ignored", attempt: 1, retryAfter: "Wed, 21 Oct 2026 07:28:00 GMT", want: 2 * time.Second},
+	}
+
+	transport := &retryTransport{policy: RetryPolicy{Attempts: 3, Backoff: time.Second}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			if got := transport.backoff(tt.attempt, resp); got != tt.want {
+				t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefresher renews the client's token shortly before it expires
+type tokenRefresher struct {
+	// mu guards the client's token and serializes refreshes
+	mu      sync.Mutex
+	window  time.Duration
+	persist func(token string) error
+	now     func() time.Time
+	// failed stops further attempts once a refresh fails; the current token
+	// is used until it expires
+	failed bool
+}
+
+// WithTokenRefresh refreshes the token through /auth/refresh once it is
+// within window of the expiry in its JWT exp claim, and passes the new token
+// to persist so later invocations use it. Tokens without an exp claim are
+// never refreshed. A failed refresh or persist is not an error: the current
+// token stays in use until the gateway rejects it.
+func WithTokenRefresh(window time.Duration, persist func(token string) error) Option {
+	return func(c *Client) {
+		c.refresh = &tokenRefresher{window: window, persist: persist, now: time.Now}
+	}
+}
+
+// authToken returns the token to send, first refreshing it if it is about to
+// expire. Concurrent callers wait for a single refresh and share its result.
+func (c *Client) authToken(ctx context.Context) string {
+	r := c.refresh
+	if r == nil {
+		return c.token
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failed || c.token == "" {
+		return c.token
+	}
+	exp, ok := tokenExpiry(c.token)
+	if !ok {
+		return c.token
+	}
+	now := r.now()
+	if !now.Before(exp) || exp.Sub(now) > r.window {
+		return c.token
+	}
+
+	token, err := c.refreshToken(ctx, c.token)
+	if err != nil {
+		r.failed = true
+		return c.token
+	}
+	c.token = token
+	if r.persist != nil {
+		_ = r.persist(token)
+	}
+	return c.token
+}
+
+// refreshToken exchanges a still valid token for one with a fresh expiry
+func (c *Client) refreshToken(ctx context.Context, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/auth/refresh", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", c.mediaType)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", newAPIError(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %v", err)
+	}
+	var out struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("failed to decode response: %v", err)
+	}
+	if out.Token == "" {
+		return "", fmt.Errorf("refresh response has no token")
+	}
+	return out.Token, nil
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it; the gateway
+// verifies tokens, the CLI only needs to know when to renew one
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Exp *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(*claims.Exp), 0), true
+}
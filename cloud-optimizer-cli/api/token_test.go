@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+var tokenNow = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// testJWT returns an unsigned JWT with the given payload; the CLI never
+// verifies signatures
+func testJWT(payload string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	return enc([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc([]byte(payload)) + ".sig"
+}
+
+// expiringJWT returns a token expiring d after tokenNow
+func expiringJWT(d time.Duration) string {
+	return testJWT(fmt.Sprintf(`{"sub":"u1","exp":%d}`, tokenNow.Add(d).Unix()))
+}
+
+func TestTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "exp claim", token: expiringJWT(time.Hour), want: tokenNow.Add(time.Hour), wantOK: true},
+		{name: "fractional exp claim", token: testJWT(fmt.Sprintf(`{"exp":%d.5}`, tokenNow.Unix())), want: tokenNow, wantOK: true},
+		{name: "no exp claim", token: testJWT(`{"sub":"u1"}`)},
+		{name: "padded payload", token: "e30." + base64.URLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, tokenNow.Unix()))) + ".sig", want: tokenNow, wantOK: true},
+		{name: "not a JWT", token: "api-key-value"},
+		{name: "payload not base64", token: "a.!!!.c"},
+		{name: "payload not JSON", token: "a." + base64.RawURLEncoding.EncodeToString([]byte("exp")) + ".c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tokenExpiry(tt.token)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("tokenExpiry = %s, %v; want %s, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// refreshServer is a gateway that issues refreshed tokens and records the
+// token each API request was sent with
+type refreshServer struct {
+	*httptest.Server
+	refreshed  string
+	failStatus int
+	refreshes  int32
+	mu         sync.Mutex
+	sent       []string
+}
+
+func newRefreshServer(t *testing.T) *refreshServer {
+	t.Helper()
+	s := &refreshServer{refreshed: expiringJWT(24 * time.Hour)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == apiPrefix+"/auth/refresh" {
+			atomic.AddInt32(&s.refreshes, 1)
+			if s.failStatus != 0 {
+				w.WriteHeader(s.failStatus)
+				fmt.Fprint(w, `{"error":"invalid token"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"token":%q}`, s.refreshed)
+			return
+		}
+		s.mu.Lock()
+		s.sent = append(s.sent, r.Header.Get("Authorization"))
+		s.mu.Unlock()
+		fmt.Fprint(w, `{}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestTokenRefresh(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		failStatus  int
+		wantRefresh bool
+	}{
+		{name: "token far from expiry is kept", token: expiringJWT(2 * time.Hour)},
+		{name: "token within the window is refreshed", token: expiringJWT(5 * time.Minute), wantRefresh: true},
+		{name: "expired token is not refreshed", token: expiringJWT(-time.Minute)},
+		{name: "token without expiry is not refreshed", token: testJWT(`{"sub":"u1"}`)},
+		{name: "API key is not refreshed", token: "api-key-value"},
+		{name: "failed refresh keeps the token", token: expiringJWT(5 * time.Minute), failStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newRefreshServer(t)
+			srv.failStatus = tt.failStatus
+
+			var persisted []string
+			c := NewClient(config.APIEndpoint{URL: srv.URL}, tt.token, WithTokenRefresh(10*time.Minute, func(token string) error {
+				persisted = append(persisted, token)
+				return nil
+			}))
+			c.refresh.now = func() time.Time { return tokenNow }
+
+			for i := 0; i < 2; i++ {
+				if err := c.Get(context.Background(), "/resources", nil, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want := tt.token
+			if tt.wantRefresh {
+				want = srv.refreshed
+				if len(persisted) != 1 || persisted[0] != want {
+					t.Errorf("persisted %v, want the refreshed token once", persisted)
+				}
+			} else if len(persisted) != 0 {
+				t.Errorf("persisted %v, want nothing", persisted)
+			}
+			for i, sent := range srv.sent {
+				if sent != "Bearer "+want {
+					t.Errorf("request %d sent %q, want %q", i+1, sent, "Bearer "+want)
+				}
+			}
+
+			// A failed refresh is not retried on every request
+			wantRefreshes := int32(0)
+			if tt.wantRefresh || tt.failStatus != 0 {
+				wantRefreshes = 1
+			}
+			if got := atomic.LoadInt32(&srv.refreshes); got != wantRefreshes {
+				t.Errorf("%d refreshes, want %d", got, wantRefreshes)
+			}
+		})
+	}
+}
+
+func TestTokenRefreshConcurrent(t *testing.T) {
+	srv := newRefreshServer(t)
+	c := NewClient(config.APIEndpoint{URL: srv.URL}, expiringJWT(time.Minute), WithTokenRefresh(10*time.Minute, nil))
+	c.refresh.now = func() time.Time { return tokenNow }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Get(context.Background(), "/resources", nil, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&srv.refreshes); got != 1 {
+		t.Errorf("%d refreshes, want 1 shared by every request", got)
+	}
+	for i, sent := range srv.sent {
+		if sent != "Bearer "+srv.refreshed {
+			t.Errorf("request %d sent %q, want the refreshed token", i+1, sent)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Package cache manages the CLI's on-disk caches. Every cache lives in its
+// own subdirectory of a single cache directory so each can be cleared on its
+// own.
+package cache
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cache types. TypeAll selects every type.
+const (
+	TypeCatalog  = "catalog"
+	TypeCost     = "cost"
+	TypeETag     = "etag"
+	TypeResponse = "response"
+	TypeAll      = "all"
+)
+
+// Types lists every cache type
+var Types = []string{TypeCatalog, TypeCost, TypeETag, TypeResponse}
+
+// Usage is the disk usage of one cache type
+type Usage struct {
+	Type  string `json:"type" yaml:"type"`
+	Files int    `json:"files" yaml:"files"`
+	Bytes int64  `json:"bytes" yaml:"bytes"`
+}
+
+// ValidateType checks that typ is a cache type or TypeAll
+func ValidateType(typ string) error {
+	if typ == TypeAll {
+		return nil
+	}
+	for _, t := range Types {
+		if typ == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid cache type: %s (must be %s or %s)", typ, strings.Join(Types, ", "), TypeAll)
+}
+
+// Path returns the directory of the cache type under root
+func Path(root, typ string) string {
+	return filepath.Join(root, typ)
+}
+
+// Clear removes the cached data of the given type, or of every type for
+// TypeAll, and returns the types cleared. Clearing a cache that does not
+// exist is not an error.
+func Clear(root, typ string) ([]string, error) {
+	if err := ValidateType(typ); err != nil {
+		return nil, err
+	}
+
+	types := []string{typ}
+	if typ == TypeAll {
+		types = Types
+	}
+	for _, t := range types {
+		if err := os.RemoveAll(Path(root, t)); err != nil {
+			return nil, fmt.Errorf("failed to clear %s cache: %v", t, err)
+		}
+	}
+	return types, nil
+}
+
+// Info returns the disk usage of every cache type under root
+func Info(root string) ([]Usage, error) {
+	usage := make([]Usage, len(Types))
+	for i, t := range Types {
+		usage[i].Type = t
+		err := filepath.WalkDir(Path(root, t), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			usage[i].Files++
+			usage[i].Bytes += info.Size()
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s cache: %v", t, err)
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// populate writes a file of size bytes into each cache type under root
+func populate(t *testing.T, root string, size int) {
+	t.Helper()
+	for _, typ := range Types {
+		if err := os.MkdirAll(Path(root, typ), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(Path(root, typ), "entry"), make([]byte, size), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	tests := []struct {
+		typ       string
+		wantKept  []string
+		wantError bool
+	}{
+		{typ: TypeCost, wantKept: []string{TypeCatalog, TypeETag, TypeResponse}},
+		{typ: TypeETag, wantKept: []string{TypeCatalog, TypeCost, TypeResponse}},
+		{typ: TypeAll, wantKept: nil},
+		{typ: "tiles", wantKept: Types, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			root := t.TempDir()
+			populate(t, root, 10)
+
+			_, err := Clear(root, tt.typ)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Clear(%s) error = %v, want error %v", tt.typ, err, tt.wantError)
+			}
+
+			var kept []string
+			for _, typ := range Types {
+				if _, err := os.Stat(Path(root, typ)); err == nil {
+					kept = append(kept, typ)
+				}
+			}
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("caches left %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestInfo(t *testing.T) {
+	root := t.TempDir()
+	populate(t, root, 100)
+	if _, err := Clear(root, TypeCatalog); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := Info(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Usage{
+		{Type: TypeCatalog},
+		{Type: TypeCost, Files: 1, Bytes: 100},
+		{Type: TypeETag, Files: 1, Bytes: 100},
+		{Type: TypeResponse, Files: 1, Bytes: 100},
+	}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("Info() = %+v, want %+v", usage, want)
+	}
+}
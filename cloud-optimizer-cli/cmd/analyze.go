@@ -1,20 +1,46 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/inventory"
+	"cloud-optimizer-cli/output"
 )
 
 var (
 	provider    string
 	region      string
-	resourceID  string
+	resourceIDs []string
 	outputType  string
 	timeRange   string
 	costMetrics bool
 	performance bool
 	compliance  bool
+	workers     int
+	alertBelow  float64
+	scoreMetric string
+
+	fromInventory bool
+
+	watch         bool
+	watchInterval time.Duration
+)
+
+// Scores analyze --alert-below can check
+const (
+	metricTotal       = "total"
+	metricPerformance = "performance"
+	metricCompliance  = "compliance"
 )
 
 // analyzeCmd represents the analyze command
@@ -25,8 +51,12 @@ var analyzeCmd = &cobra.Command{
 performance improvements, and compliance requirements. For example:
 
 cloudopt analyze --provider aws --region us-west-2 --resource-id i-1234567890abcdef0
+cloudopt analyze --provider aws --resource-id i-0123,i-4567 --workers 8
+cloudopt analyze --provider aws --alert-below 0.7 --metric performance
+cloudopt analyze --provider aws --inventory
 cloudopt analyze --provider azure --region eastus --output json
-cloudopt analyze --provider gcp --time-range 30d --cost-metrics`,
+cloudopt analyze --provider gcp --time-range 30d --cost-metrics
+cloudopt analyze --provider aws --watch --interval 60s`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Validate flags
 		if err := validateAnalyzeFlags(); err != nil {
@@ -34,22 +64,34 @@ cloudopt analyze --provider gcp --time-range 30d --cost-metrics`,
 		}
 
 		// Initialize the analyzer
-		analyzer, err := initializeAnalyzer()
+		analyzer, err := initializeAnalyzer(cmd)
 		if err != nil {
+			// Invalid configured defaults keep their validation exit code
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				return err
+			}
 			return fmt.Errorf("failed to initialize analyzer: %v", err)
 		}
 
+		if watch {
+			return watchAnalysis(cmd, analyzer, watchInterval)
+		}
+
 		// Run the analysis
 		results, err := analyzer.Analyze(cmd.Context())
 		if err != nil {
-			return fmt.Errorf("analysis failed: %v", err)
+			return err
 		}
 
 		// Format and output results
-		if err := outputResults(results); err != nil {
+		if err := outputResults(cmd, results); err != nil {
 			return fmt.Errorf("failed to output results: %v", err)
 		}
 
+		if analyzer.Scores {
+			return checkScoreThreshold(cmd.ErrOrStderr(), results, scoreMetric, alertBelow)
+		}
 		return nil
 	},
 }
@@ -60,12 +102,19 @@ func init() {
 	// Local flags
 	analyzeCmd.Flags().StringVar(&provider, "provider", "", "cloud provider (aws, azure, gcp)")
 	analyzeCmd.Flags().StringVar(&region, "region", "", "cloud region")
-	analyzeCmd.Flags().StringVar(&resourceID, "resource-id", "", "specific resource ID to analyze")
+	analyzeCmd.Flags().StringSliceVar(&resourceIDs, "resource-id", nil, "resource IDs to analyze (default all resources of the provider and region)")
 	analyzeCmd.Flags().StringVar(&outputType, "output", "text", "output format (text, json, yaml)")
 	analyzeCmd.Flags().StringVar(&timeRange, "time-range", "7d", "time range for analysis (e.g., 7d, 30d, 90d)")
 	analyzeCmd.Flags().BoolVar(&costMetrics, "cost-metrics", false, "include cost metrics in analysis")
 	analyzeCmd.Flags().BoolVar(&performance, "performance", false, "include performance metrics in analysis")
 	analyzeCmd.Flags().BoolVar(&compliance, "compliance", false, "include compliance checks in analysis")
+	analyzeCmd.Flags().IntVar(&workers, "workers", runtime.NumCPU(), "number of resources to analyze concurrently")
+	analyzeCmd.Flags().Float64Var(&alertBelow, "alert-below", 0, "exit with code 6 if any resource scores below this threshold (0-1), or 7 if some could not be scored")
+	addOrgDefaultsFlag(analyzeCmd)
+	analyzeCmd.Flags().StringVar(&scoreMetric, "metric", metricTotal, "score checked by --alert-below (total, performance, compliance)")
+	analyzeCmd.Flags().BoolVar(&fromInventory, "inventory", false, "analyze the resources of the inventory imported with 'cloudopt inventory import' instead of listing them from the gateway")
+	analyzeCmd.Flags().BoolVar(&watch, "watch", false, "re-run the analysis every --interval, redrawing the results and highlighting changes (Ctrl-C to exit)")
+	analyzeCmd.Flags().DurationVar(&watchInterval, "interval", time.Minute, "time between analyses in --watch mode")
 
 	// Required flags
 	analyzeCmd.MarkFlagRequired("provider")
@@ -77,20 +126,45 @@ func validateAnalyzeFlags() error {
 	case "aws", "azure", "gcp":
 		// Valid provider
 	default:
-		return fmt.Errorf("invalid provider: %s (must be aws, azure, or gcp)", provider)
+		return validationErrorf("invalid provider: %s (must be aws, azure, or gcp)", provider)
 	}
 
-	// Validate output type
-	switch outputType {
-	case "text", "json", "yaml":
-		// Valid output type
+	if workers < 1 {
+		return validationErrorf("invalid workers: %d (must be at least 1)", workers)
+	}
+	if fromInventory && len(resourceIDs) > 0 {
+		return validationErrorf("--inventory and --resource-id cannot be used together")
+	}
+
+	switch scoreMetric {
+	case metricTotal, metricPerformance, metricCompliance:
 	default:
-		return fmt.Errorf("invalid output type: %s (must be text, json, or yaml)", outputType)
+		return validationErrorf("invalid metric: %s (must be total, performance, or compliance)", scoreMetric)
+	}
+	if alertBelow < 0 || alertBelow > 1 {
+		return validationErrorf("invalid alert-below: %g (must be between 0 and 1)", alertBelow)
+	}
+
+	// Validate output type
+	if err := output.ValidateFormat(outputType); err != nil {
+		return asValidationError(err)
+	}
+
+	if watch {
+		if watchInterval <= 0 {
+			return validationErrorf("invalid interval: %s (must be positive)", watchInterval)
+		}
+		if outputType != output.FormatText {
+			return validationErrorf("--watch only supports text output, not %s", outputType)
+		}
+		if outputFile != "" {
+			return validationErrorf("--watch redraws the terminal and cannot be used with --output-file")
+		}
 	}
 
 	// Validate time range format
 	if err := validateTimeRange(timeRange); err != nil {
-		return fmt.Errorf("invalid time range: %v", err)
+		return validationErrorf("invalid time range: %v", err)
 	}
 
 	return nil
@@ -102,40 +176,338 @@ func validateTimeRange(tr string) error {
 	return nil
 }
 
+// Analyzer runs the per-resource analysis of a set of resources with a
+// bounded number of concurrent workers
 type Analyzer struct {
 	Provider    string
 	Region      string
-	ResourceID  string
+	ResourceIDs []string
 	TimeRange   string
 	CostMetrics bool
 	Performance bool
 	Compliance  bool
+	Workers     int
+	// Scores fetches the score of each resource's current deployment
+	Scores bool
+	// ExcludedProviders and ExcludedRegions are sent with every analysis
+	ExcludedProviders []string
+	ExcludedRegions   []string
+	// ComplianceFrameworks are the configured default frameworks, sent with
+	// every analysis
+	ComplianceFrameworks []string
+	// Inventory, when set, lists the resources to analyze instead of the
+	// gateway
+	Inventory *inventory.Inventory
+
+	client   *api.Client
+	progress io.Writer
+}
+
+// ResourceAnalysis is the outcome of analyzing one resource
+type ResourceAnalysis struct {
+	ResourceID      string               `json:"resource_id" yaml:"resource_id"`
+	Recommendations []api.Recommendation `json:"recommendations" yaml:"recommendations"`
+	Scores          *api.ScoredOption    `json:"scores,omitempty" yaml:"scores,omitempty"`
+}
+
+// ResourceError records a resource whose analysis failed
+type ResourceError struct {
+	ResourceID string `json:"resource_id" yaml:"resource_id"`
+	Error      string `json:"error" yaml:"error"`
 }
 
-func initializeAnalyzer() (*Analyzer, error) {
+// AnalysisResults aggregates the analysis of every resource. A failure for
+// one resource is recorded in Errors and does not stop the others.
+type AnalysisResults struct {
+	Resources []ResourceAnalysis `json:"resources" yaml:"resources"`
+	Errors    []ResourceError    `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+func initializeAnalyzer(cmd *cobra.Command) (*Analyzer, error) {
+	client, err := newAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	excludedProviders, excludedRegions, err := orgExclusions()
+	if err != nil {
+		return nil, err
+	}
+	frameworks, err := orgComplianceFrameworks()
+	if err != nil {
+		return nil, err
+	}
+	var inv *inventory.Inventory
+	if fromInventory {
+		if inv, err = loadLocalInventory(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &Analyzer{
-		Provider:    provider,
-		Region:      region,
-		ResourceID:  resourceID,
-		TimeRange:   timeRange,
-		CostMetrics: costMetrics,
-		Performance: performance,
-		Compliance:  compliance,
+		Provider:             provider,
+		Region:               region,
+		ResourceIDs:          resourceIDs,
+		TimeRange:            timeRange,
+		CostMetrics:          costMetrics,
+		Performance:          performance,
+		Compliance:           compliance,
+		Workers:              workers,
+		Scores:               cmd.Flags().Changed("alert-below"),
+		ExcludedProviders:    excludedProviders,
+		ExcludedRegions:      excludedRegions,
+		ComplianceFrameworks: frameworks,
+		Inventory:            inv,
+		client:               client,
+		progress:             cmd.ErrOrStderr(),
 	}, nil
 }
 
-func (a *Analyzer) Analyze(ctx context.Context) (interface{}, error) {
-	// TODO: Implement actual analysis logic
-	// This should:
-	// 1. Connect to the appropriate cloud provider
-	// 2. Gather resource information
-	// 3. Analyze costs, performance, and compliance
-	// 4. Generate optimization recommendations
-	return nil, fmt.Errorf("analysis not implemented yet")
+// Analyze analyzes every requested resource, or every resource of the
+// provider and region when none are requested, taken from the inventory when
+// one is set
+func (a *Analyzer) Analyze(ctx context.Context) (*AnalysisResults, error) {
+	ids := a.ResourceIDs
+	switch {
+	case len(ids) > 0:
+	case a.Inventory != nil:
+		ids = a.resourceIDs(a.Inventory.Resources)
+	default:
+		var err error
+		ids, err = a.listResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return analyzeResources(ctx, ids, a.Workers, a.analyzeResource, a.progress)
+}
+
+// listResources returns the IDs of the gateway's resources in the analyzer's
+// provider and region
+func (a *Analyzer) listResources(ctx context.Context) ([]string, error) {
+	var resources []inventory.Resource
+	if err := a.client.Get(ctx, "/resources", nil, &resources); err != nil {
+		return nil, apiFailure("failed to list resources", err)
+	}
+	return a.resourceIDs(resources), nil
+}
+
+// resourceIDs returns the IDs of the resources in the analyzer's provider and
+// region
+func (a *Analyzer) resourceIDs(resources []inventory.Resource) []string {
+	var ids []string
+	for _, r := range resources {
+		if r.Provider != a.Provider || (a.Region != "" && r.Region != a.Region) {
+			continue
+		}
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+// analyzeResource runs the requested analyses of a single resource
+func (a *Analyzer) analyzeResource(ctx context.Context, id string) (*ResourceAnalysis, error) {
+	req := api.AnalyzeRequest{
+		ResourceIDs:          []string{id},
+		ExcludedProviders:    a.ExcludedProviders,
+		ExcludedRegions:      a.ExcludedRegions,
+		ComplianceFrameworks: a.ComplianceFrameworks,
+	}
+	if a.CostMetrics {
+		req.AnalysisTypes = append(req.AnalysisTypes, api.AnalysisCost)
+	}
+	if a.Performance {
+		req.AnalysisTypes = append(req.AnalysisTypes, api.AnalysisPerformance)
+	}
+	if a.Compliance {
+		req.AnalysisTypes = append(req.AnalysisTypes, api.AnalysisCompliance)
+	}
+
+	recommendations, err := a.client.Analyze(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	analysis := &ResourceAnalysis{ResourceID: id, Recommendations: recommendations}
+
+	if a.Scores {
+		eval, err := a.client.EvaluateResource(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to score resource: %v", err)
+		}
+		analysis.Scores = &eval.Current
+	}
+	return analysis, nil
+}
+
+// score returns the named score of a deployment
+func score(o *api.ScoredOption, metric string) float64 {
+	switch metric {
+	case metricPerformance:
+		return o.PerformanceScore
+	case metricCompliance:
+		return o.ComplianceScore
+	}
+	return o.TotalScore
+}
+
+// checkScoreThreshold reports the resources whose metric score is below the
+// threshold, and those that could not be scored, separately. It returns an
+// AlertError if any scored resource is below the threshold, or otherwise an
+// UnscoredError if any resource could not be scored, so a gate never passes
+// on missing data.
+func checkScoreThreshold(w io.Writer, results *AnalysisResults, metric string, threshold float64) error {
+	failed := 0
+	var unscored []string
+	for _, r := range results.Resources {
+		if r.Scores == nil {
+			unscored = append(unscored, r.ResourceID)
+			continue
+		}
+		if s := score(r.Scores, metric); s < threshold {
+			fmt.Fprintf(w, "%s: %s score %.2f is below %.2f\n", r.ResourceID, metric, s, threshold)
+			failed++
+		}
+	}
+	for _, e := range results.Errors {
+		unscored = append(unscored, e.ResourceID)
+	}
+	for _, id := range unscored {
+		fmt.Fprintf(w, "%s: not scored\n", id)
+	}
+
+	switch {
+	case failed > 0:
+		return &AlertError{commandError{msg: fmt.Sprintf("%d resources scored below the %s score threshold of %.2f", failed, metric, threshold)}}
+	case len(unscored) > 0:
+		return &UnscoredError{commandError{msg: fmt.Sprintf("%d resources could not be scored", len(unscored))}}
+	}
+	return nil
+}
+
+// analyzeResources runs analyze for every resource with at most workers
+// running at once, reporting progress as each finishes. Results keep the order
+// of ids; failed resources are collected in Errors. When ctx is cancelled no
+// further resources are started, and ctx's error is returned once the running
+// ones finish.
+func analyzeResources(ctx context.Context, ids []string, workers int, analyze func(context.Context, string) (*ResourceAnalysis, error), progress io.Writer) (*AnalysisResults, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type outcome struct {
+		analysis *ResourceAnalysis
+		err      error
+	}
+	outcomes := make([]outcome, len(ids))
+
+	jobs := make(chan int)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for w := 0; w < workers && w < len(ids); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				analysis, err := analyze(ctx, ids[i])
+				outcomes[i] = outcome{analysis: analysis, err: err}
+
+				mu.Lock()
+				done++
+				if progress != nil {
+					fmt.Fprintf(progress, "\rAnalyzed %d/%d resources", done, len(ids))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
+	for i := range ids {
+		// select picks at random when a worker is also ready, so check first
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if progress != nil && len(ids) > 0 {
+		fmt.Fprintln(progress)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := &AnalysisResults{Resources: make([]ResourceAnalysis, 0, len(ids))}
+	for i, o := range outcomes {
+		if o.err != nil {
+			results.Errors = append(results.Errors, ResourceError{ResourceID: ids[i], Error: o.err.Error()})
+			continue
+		}
+		results.Resources = append(results.Resources, *o.analysis)
+	}
+	return results, nil
+}
+
+func outputResults(cmd *cobra.Command, results *AnalysisResults) error {
+	return writeOutput(cmd, outputType, results, func(w io.Writer) error {
+		return writeAnalysisResults(w, results)
+	})
+}
+
+func writeAnalysisResults(w io.Writer, results *AnalysisResults) error {
+	return writeAnalysis(w, results, nil)
 }
 
-func outputResults(results interface{}) error {
-	// TODO: Implement result formatting and output
-	// Should support different output formats (text, json, yaml)
-	return fmt.Errorf("output formatting not implemented yet")
+// writeAnalysis writes the results as tables. With a diff, every row starts
+// with a marker column flagging what changed since the previous results.
+func writeAnalysis(w io.Writer, results *AnalysisResults, diff *analysisDiff) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, diff.prefix("")+"RESOURCE\tTYPE\tPRIORITY\tSAVINGS\tDESCRIPTION")
+	for _, r := range results.Resources {
+		for _, rec := range r.Recommendations {
+			fmt.Fprintf(tw, "%s%s\t%s\t%s\t%.2f\t%s\n", diff.prefix(diff.recommendation(r.ResourceID, rec)),
+				r.ResourceID, rec.Type, rec.Priority, rec.EstimatedSavings, rec.Description)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if err := writeScores(w, results, diff); err != nil {
+		return err
+	}
+
+	for _, e := range results.Errors {
+		fmt.Fprintf(w, "failed to analyze %s: %s\n", e.ResourceID, e.Error)
+	}
+	return nil
+}
+
+// writeScores writes the scores of the resources that were scored
+func writeScores(w io.Writer, results *AnalysisResults, diff *analysisDiff) error {
+	var scored []ResourceAnalysis
+	for _, r := range results.Resources {
+		if r.Scores != nil {
+			scored = append(scored, r)
+		}
+	}
+	if len(scored) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, diff.prefix("")+"RESOURCE\tTOTAL\tPERFORMANCE\tCOMPLIANCE")
+	for _, r := range scored {
+		fmt.Fprintf(tw, "%s%s\t%.2f\t%.2f\t%.2f\n", diff.prefix(diff.score(r.ResourceID)),
+			r.ResourceID, r.Scores.TotalScore, r.Scores.PerformanceScore, r.Scores.ComplianceScore)
+	}
+	return tw.Flush()
 }
@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/config"
+	"cloud-optimizer-cli/inventory"
+)
+
+func TestAnalyzeResources(t *testing.T) {
+	ids := []string{"r1", "r2", "r3", "r4", "r5", "r6"}
+	tests := []struct {
+		name    string
+		workers int
+		// cancelAfter cancels the context once that many resources started;
+		// 0 never cancels
+		cancelAfter int
+		failing     string
+		wantErr     error
+		// wantStarted is the most resources expected to start: one more than
+		// cancelAfter may already have been handed to a worker
+		wantStarted  int
+		wantAnalyzed int
+		wantErrors   int
+	}{
+		{name: "every resource analyzed", workers: 2, wantStarted: 6, wantAnalyzed: 6},
+		{name: "failures are collected", workers: 3, failing: "r4", wantStarted: 6, wantAnalyzed: 5, wantErrors: 1},
+		{name: "cancelled with one worker", workers: 1, cancelAfter: 2, wantErr: context.Canceled, wantStarted: 3},
+		{name: "cancelled with several workers", workers: 2, cancelAfter: 2, wantErr: context.Canceled, wantStarted: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var started int32
+			analyze := func(ctx context.Context, id string) (*ResourceAnalysis, error) {
+				if n := atomic.AddInt32(&started, 1); tt.cancelAfter > 0 && int(n) >= tt.cancelAfter {
+					cancel()
+				}
+				if id == tt.failing {
+					return nil, fmt.Errorf("analysis of %s failed", id)
+				}
+				return &ResourceAnalysis{ResourceID: id}, nil
+			}
+
+			type result struct {
+				results *AnalysisResults
+				err     error
+			}
+			done := make(chan result, 1)
+			go func() {
+				results, err := analyzeResources(ctx, ids, tt.workers, analyze, nil)
+				done <- result{results, err}
+			}()
+
+			var got result
+			select {
+			case got = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("analyzeResources did not return")
+			}
+
+			if !errors.Is(got.err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", got.err, tt.wantErr)
+			}
+			if n := int(atomic.LoadInt32(&started)); n > tt.wantStarted {
+				t.Errorf("started %d resources, want at most %d", n, tt.wantStarted)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(got.results.Resources) != tt.wantAnalyzed || len(got.results.Errors) != tt.wantErrors {
+				t.Errorf("got %d analyzed and %d errors, want %d and %d",
+					len(got.results.Resources), len(got.results.Errors), tt.wantAnalyzed, tt.wantErrors)
+			}
+			for i, r := range got.results.Resources {
+				if i > 0 && r.ResourceID < got.results.Resources[i-1].ResourceID {
+					t.Errorf("results out of order: %s after %s", r.ResourceID, got.results.Resources[i-1].ResourceID)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckScoreThreshold(t *testing.T) {
+	scored := func(id string, total, perf float64) ResourceAnalysis {
+		return ResourceAnalysis{ResourceID: id, Scores: &api.ScoredOption{TotalScore: total, PerformanceScore: perf}}
+	}
+	tests := []struct {
+		name     string
+		results  *AnalysisResults
+		metric   string
+		wantCode int
+		// wantOutput are the lines expected in the report
+		wantOutput []string
+	}{
+		{
+			name:     "every resource above the threshold",
+			results:  &AnalysisResults{Resources: []ResourceAnalysis{scored("r1", 0.8, 0.9), scored("r2", 0.7, 0.9)}},
+			metric:   metricTotal,
+			wantCode: ExitOK,
+		},
+		{
+			name:       "a resource below the threshold",
+			results:    &AnalysisResults{Resources: []ResourceAnalysis{scored("r1", 0.8, 0.9), scored("r2", 0.6, 0.9)}},
+			metric:     metricTotal,
+			wantCode:   ExitAlert,
+			wantOutput: []string{"r2: total score 0.60 is below 0.70"},
+		},
+		{
+			name:       "the metric checked is the one requested",
+			results:    &AnalysisResults{Resources: []ResourceAnalysis{scored("r1", 0.9, 0.5)}},
+			metric:     metricPerformance,
+			wantCode:   ExitAlert,
+			wantOutput: []string{"r1: performance score 0.50 is below 0.70"},
+		},
+		{
+			name: "unscored resources are reported apart from scored ones",
+			results: &AnalysisResults{
+				Resources: []ResourceAnalysis{scored("r1", 0.8, 0.9), {ResourceID: "r2"}},
+				Errors:    []ResourceError{{ResourceID: "r3", Error: "failed to score resource"}},
+			},
+			metric:     metricTotal,
+			wantCode:   ExitUnscored,
+			wantOutput: []string{"r2: not scored", "r3: not scored"},
+		},
+		{
+			name: "scores below the threshold take precedence over unscored resources",
+			results: &AnalysisResults{
+				Resources: []ResourceAnalysis{scored("r1", 0.5, 0.9)},
+				Errors:    []ResourceError{{ResourceID: "r2", Error: "failed to score resource"}},
+			},
+			metric:     metricTotal,
+			wantCode:   ExitAlert,
+			wantOutput: []string{"r1: total score 0.50 is below 0.70", "r2: not scored"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := checkScoreThreshold(&buf, tt.results, tt.metric, 0.7)
+			if code := ExitCode(err); code != tt.wantCode {
+				t.Errorf("exit code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+
+			var got []string
+			if out := strings.TrimSpace(buf.String()); out != "" {
+				got = strings.Split(out, "\n")
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.wantOutput, "\n") {
+				t.Errorf("report = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestAnalyzeFromInventory(t *testing.T) {
+	inv := inventory.New("https://gateway.example.com", []inventory.Resource{
+		{ID: "i-1", Provider: "aws", Region: "us-east-1"},
+		{ID: "i-2", Provider: "aws", Region: "us-west-2"},
+		{ID: "vm-1", Provider: "azure", Region: "eastus"},
+		{ID: "i-3", Provider: "aws", Region: "us-east-1"},
+	})
+	tests := []struct {
+		name     string
+		provider string
+		region   string
+		want     []string
+	}{
+		{name: "every resource of the provider", provider: "aws", want: []string{"i-1", "i-2", "i-3"}},
+		{name: "resources of the provider in the region", provider: "aws", region: "us-east-1", want: []string{"i-1", "i-3"}},
+		{name: "no resources of the provider", provider: "gcp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			path, err := localInventoryPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := inventory.Save(path, inv); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := loadLocalInventory()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			a := &Analyzer{Provider: tt.provider, Region: tt.region, Inventory: loaded}
+			analyzed := a.resourceIDs(a.Inventory.Resources)
+			if strings.Join(analyzed, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("analyzed %v, want %v", analyzed, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLocalInventoryNotImported(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	_, err := loadLocalInventory()
+	if code := ExitCode(err); code != ExitValidation {
+		t.Errorf("exit code = %d (%v), want %d", code, err, ExitValidation)
+	}
+}
+
+// Each analysis flag requests the gateway analysis type of the same name
+func TestAnalyzeResourceTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		analyzer Analyzer
+		want     []string
+	}{
+		{name: "no flags", want: nil},
+		{name: "cost", analyzer: Analyzer{CostMetrics: true}, want: []string{"cost"}},
+		{name: "compliance", analyzer: Analyzer{Compliance: true}, want: []string{"compliance"}},
+		{name: "every flag", analyzer: Analyzer{CostMetrics: true, Performance: true, Compliance: true}, want: []string{"cost", "performance", "compliance"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got api.AnalyzeRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/optimize/analyze" {
+					t.Errorf("request to %s", r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer srv.Close()
+
+			a := tt.analyzer
+			a.client = api.NewClient(config.APIEndpoint{URL: srv.URL}, "")
+			if _, err := a.analyzeResource(context.Background(), "i-1"); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got.ResourceIDs, ",") != "i-1" || strings.Join(got.AnalysisTypes, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("request %+v, want analysis types %v of i-1", got, tt.want)
+			}
+		})
+	}
+}
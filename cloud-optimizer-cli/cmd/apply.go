@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/output"
+)
+
+// progressBarWidth is the number of cells in the apply progress bar
+const progressBarWidth = 30
+
+var (
+	applyOutput             string
+	applyPollInterval       time.Duration
+	applyReason             string
+	applyOverrideCompliance bool
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply <recommendation-id>...",
+	Short: "Apply recommendations and follow their progress",
+	Long: `Apply recommendations as a background job on the gateway, showing a live
+progress bar with each recommendation's status and a summary when the job
+completes. Progress is streamed from the gateway; if the stream disconnects
+the job is polled instead. A job holding a recommendation above the gateway's
+approval threshold waits for an approver to run "cloudopt apply approve".
+Recommendations that would move a placement to a region not supporting its
+required compliance frameworks are refused, listing the framework controls at
+stake, unless --override-compliance is set. For example:
+
+cloudopt apply rec-1 rec-2 rec-3
+cloudopt apply rec-1 --output json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(applyOutput); err != nil {
+			return asValidationError(err)
+		}
+		if applyPollInterval <= 0 {
+			return validationErrorf("invalid poll interval: %s (must be positive)", applyPollInterval)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		job, err := client.StartApply(ctx, args, applyOverrideCompliance)
+		if err != nil {
+			var apiErr *api.APIError
+			if errors.As(err, &apiErr) {
+				if violations := apiErr.ComplianceViolations(); len(violations) > 0 {
+					writeComplianceViolations(cmd.ErrOrStderr(), violations)
+				}
+			}
+			return apiFailure("failed to start applying recommendations", err)
+		}
+		if job.Status == api.JobPendingApproval {
+			return writeOutput(cmd, applyOutput, job, func(w io.Writer) error {
+				return writeApplyJobApproval(w, job)
+			})
+		}
+
+		progress := newApplyProgress(cmd.ErrOrStderr(), job.Items)
+		progress.render()
+
+		final, err := client.StreamApplyJob(ctx, job.ID, progress.update)
+		if err != nil && ctx.Err() == nil {
+			progress.note("progress stream disconnected (%v); polling instead", err)
+			final, err = pollApplyJob(ctx, client, job.ID, applyPollInterval, progress)
+		}
+		if err != nil {
+			return apiFailure("failed to follow apply job", err)
+		}
+		progress.sync(final)
+		progress.finish()
+
+		if err := writeOutput(cmd, applyOutput, final, func(w io.Writer) error {
+			return writeApplySummary(w, final)
+		}); err != nil {
+			return err
+		}
+
+		if failed := countItems(final.Items, api.JobItemFailed); failed > 0 {
+			return fmt.Errorf("%d of %d recommendations failed to apply", failed, len(final.Items))
+		}
+		return nil
+	},
+}
+
+var applyApproveCmd = &cobra.Command{
+	Use:   "approve <job-id>",
+	Short: "Approve an apply job pending approval so it runs",
+	Long: `Approve an apply job held for approval. The job then runs on the gateway;
+follow it with the job ID. Requires the admin or approver role, and the
+requester of a job cannot approve it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return decideApplyJob(cmd, args[0], true)
+	},
+}
+
+var applyRejectCmd = &cobra.Command{
+	Use:   "reject <job-id>",
+	Short: "Reject an apply job pending approval",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return decideApplyJob(cmd, args[0], false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.AddCommand(applyApproveCmd)
+	applyCmd.AddCommand(applyRejectCmd)
+
+	applyCmd.PersistentFlags().StringVar(&applyOutput, "output", output.FormatText, "output format (text, json, yaml)")
+	applyCmd.Flags().DurationVar(&applyPollInterval, "poll-interval", 2*time.Second, "how often to poll the job if the progress stream disconnects")
+	applyCmd.Flags().BoolVar(&applyOverrideCompliance, "override-compliance", false, "apply even if placements would no longer meet their required compliance frameworks")
+	applyApproveCmd.Flags().StringVar(&applyReason, "reason", "", "reason recorded with the decision")
+	applyRejectCmd.Flags().StringVar(&applyReason, "reason", "", "reason recorded with the decision")
+}
+
+// decideApplyJob approves or rejects a pending apply job and writes the job
+func decideApplyJob(cmd *cobra.Command, id string, approve bool) error {
+	if err := output.ValidateFormat(applyOutput); err != nil {
+		return asValidationError(err)
+	}
+
+	client, err := newAPIClient()
+	if err != nil {
+		return err
+	}
+
+	decide, verb := client.RejectApply, "reject"
+	if approve {
+		decide, verb = client.ApproveApply, "approve"
+	}
+	job, err := decide(cmd.Context(), id, applyReason)
+	if err != nil {
+		return apiFailure(fmt.Sprintf("failed to %s apply job", verb), err)
+	}
+
+	return writeOutput(cmd, applyOutput, job, func(w io.Writer) error {
+		return writeApplyJobApproval(w, job)
+	})
+}
+
+// writeApplyJobApproval describes a job's approval state and the items that
+// required it
+func writeApplyJobApproval(w io.Writer, job *api.ApplyJob) error {
+	fmt.Fprintf(w, "Apply job %s is %s\n", job.ID, strings.ReplaceAll(job.Status, "_", " "))
+	if a := job.Approval; a != nil {
+		if a.RequestedBy != "" {
+			fmt.Fprintf(w, "Requested by %s at %s\n", a.RequestedBy, formatDateTime(a.RequestedAt))
+		}
+		if job.Status == api.JobPendingApproval {
+			fmt.Fprintf(w, "Expires at %s unless approved with: cloudopt apply approve %s\n", formatDateTime(a.ExpiresAt), job.ID)
+		}
+		if a.DecidedBy != "" {
+			fmt.Fprintf(w, "Decided by %s", a.DecidedBy)
+			if a.Reason != "" {
+				fmt.Fprintf(w, ": %s", a.Reason)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
+	fmt.Fprintln(w, "\nRecommendations requiring approval:")
+	for _, item := range job.Items {
+		if item.RequiresApproval {
+			fmt.Fprintf(w, "  %s\n", item.RecommendationID)
+		}
+	}
+	return nil
+}
+
+// writeComplianceViolations lists the compliance frameworks and controls an
+// apply was refused for
+func writeComplianceViolations(w io.Writer, violations []api.ComplianceViolation) {
+	fmt.Fprintln(w, "Refused: these recommendations would break compliance (rerun with --override-compliance to apply anyway):")
+	for _, v := range violations {
+		fmt.Fprintf(w, "  %s: placement %s in %s/%s would not meet %s\n", v.RecommendationID, v.PlacementID, v.Provider, v.Region, v.Framework)
+		for _, c := range v.Controls {
+			fmt.Fprintf(w, "    %s %s\n", c.ID, c.Description)
+		}
+	}
+}
+
+// pollApplyJob polls the job every interval until it completes, reporting
+// status changes to progress
+func pollApplyJob(ctx context.Context, client *api.Client, id string, interval time.Duration, progress *applyProgress) (*api.ApplyJob, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job, err := client.ApplyJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		progress.sync(job)
+		if job.Finished() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// applyProgress renders a live progress bar for an apply job, printing a line
+// for each recommendation as it finishes
+type applyProgress struct {
+	w     io.Writer
+	items []api.JobItem
+	// width is the length of the last rendered bar, cleared before redrawing
+	width int
+}
+
+func newApplyProgress(w io.Writer, items []api.JobItem) *applyProgress {
+	return &applyProgress{w: w, items: append([]api.JobItem(nil), items...)}
+}
+
+// update records a status event and redraws the bar
+func (p *applyProgress) update(e api.JobEvent) {
+	if e.Index < 0 || e.Index >= len(p.items) || p.items[e.Index].Status == e.Status {
+		return
+	}
+	p.items[e.Index] = e.JobItem
+
+	switch e.Status {
+	case api.JobItemDone:
+		p.line("done     %s", e.RecommendationID)
+	case api.JobItemFailed:
+		p.line("failed   %s: %s", e.RecommendationID, e.Error)
+	}
+	p.render()
+}
+
+// sync applies every item of the job that changed as an event
+func (p *applyProgress) sync(job *api.ApplyJob) {
+	for i, item := range job.Items {
+		p.update(api.JobEvent{Index: i, JobItem: item})
+	}
+}
+
+// note prints a message above the bar
+func (p *applyProgress) note(format string, args ...interface{}) {
+	p.line(format, args...)
+	p.render()
+}
+
+// line clears the bar and prints a line in its place
+func (p *applyProgress) line(format string, args ...interface{}) {
+	fmt.Fprintf(p.w, "\r%s\r", strings.Repeat(" ", p.width))
+	fmt.Fprintf(p.w, format+"\n", args...)
+	p.width = 0
+}
+
+func (p *applyProgress) render() {
+	bar := renderProgress(p.items, progressBarWidth)
+	pad := ""
+	if len(bar) < p.width {
+		pad = strings.Repeat(" ", p.width-len(bar))
+	}
+	fmt.Fprintf(p.w, "\r%s%s", bar, pad)
+	p.width = len(bar)
+}
+
+// finish ends the bar's line
+func (p *applyProgress) finish() {
+	fmt.Fprintln(p.w)
+	p.width = 0
+}
+
+// renderProgress draws a bar of width cells filled in proportion to the
+// finished items, followed by the count of items in each status
+func renderProgress(items []api.JobItem, width int) string {
+	finished := countItems(items, api.JobItemDone) + countItems(items, api.JobItemFailed)
+
+	filled := 0
+	if len(items) > 0 {
+		filled = finished * width / len(items)
+	}
+
+	return fmt.Sprintf("[%s%s] %d/%d  done %d  failed %d  applying %d  pending %d",
+		strings.Repeat("#", filled), strings.Repeat("-", width-filled),
+		finished, len(items),
+		countItems(items, api.JobItemDone), countItems(items, api.JobItemFailed),
+		countItems(items, api.JobItemApplying), countItems(items, api.JobItemPending))
+}
+
+func countItems(items []api.JobItem, status string) int {
+	n := 0
+	for _, item := range items {
+		if item.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func writeApplySummary(w io.Writer, job *api.ApplyJob) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RECOMMENDATION\tSTATUS\tAPPLICATION\tERROR")
+	for _, item := range job.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.RecommendationID, item.Status, item.ApplicationID, item.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d applied, %d failed\n",
+		countItems(job.Items, api.JobItemDone), countItems(job.Items, api.JobItemFailed))
+	return err
+}
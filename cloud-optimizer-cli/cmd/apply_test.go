@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/api"
+)
+
+func TestApplyProgress(t *testing.T) {
+	items := []api.JobItem{
+		{RecommendationID: "rec-1", Status: api.JobItemPending},
+		{RecommendationID: "rec-2", Status: api.JobItemPending},
+		{RecommendationID: "rec-3", Status: api.JobItemPending},
+		{RecommendationID: "rec-4", Status: api.JobItemPending},
+	}
+	var buf bytes.Buffer
+	p := newApplyProgress(&buf, items)
+
+	events := []struct {
+		event api.JobEvent
+		want  string
+	}{
+		{
+			event: api.JobEvent{Index: 0, JobItem: api.JobItem{RecommendationID: "rec-1", Status: api.JobItemApplying}},
+			want:  "[----] 0/4  done 0  failed 0  applying 1  pending 3",
+		},
+		{
+			event: api.JobEvent{Index: 0, JobItem: api.JobItem{RecommendationID: "rec-1", Status: api.JobItemDone}},
+			want:  "[#---] 1/4  done 1  failed 0  applying 0  pending 3",
+		},
+		{
+			// A repeated status changes nothing
+			event: api.JobEvent{Index: 0, JobItem: api.JobItem{RecommendationID: "rec-1", Status: api.JobItemDone}},
+			want:  "[#---] 1/4  done 1  failed 0  applying 0  pending 3",
+		},
+		{
+			// An event for an item outside the job is ignored
+			event: api.JobEvent{Index: 7, JobItem: api.JobItem{RecommendationID: "rec-8", Status: api.JobItemDone}},
+			want:  "[#---] 1/4  done 1  failed 0  applying 0  pending 3",
+		},
+		{
+			event: api.JobEvent{Index: 1, JobItem: api.JobItem{RecommendationID: "rec-2", Status: api.JobItemFailed, Error: "quota exceeded"}},
+			want:  "[##--] 2/4  done 1  failed 1  applying 0  pending 2",
+		},
+		{
+			event: api.JobEvent{Index: 3, JobItem: api.JobItem{RecommendationID: "rec-4", Status: api.JobItemDone}},
+			want:  "[###-] 3/4  done 2  failed 1  applying 0  pending 1",
+		},
+	}
+
+	for _, e := range events {
+		p.update(e.event)
+		if got := renderProgress(p.items, 4); got != e.want {
+			t.Errorf("after %s %s: %q, want %q", e.event.RecommendationID, e.event.Status, got, e.want)
+		}
+	}
+	p.finish()
+
+	out := buf.String()
+	for _, want := range []string{"done     rec-1\n", "failed   rec-2: quota exceeded\n", "done     rec-4\n"} {
+		if strings.Count(out, want) != 1 {
+			t.Errorf("output has %q %d times, want once:\n%q", want, strings.Count(out, want), out)
+		}
+	}
+	if strings.Contains(out, "rec-8") {
+		t.Errorf("output reports an item outside the job:\n%q", out)
+	}
+}
+
+// A progress stream that disconnects before the job completes falls back to
+// polling the job
+func TestApplyFallsBackToPolling(t *testing.T) {
+	var polls int
+	out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/optimize/apply":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id":"job-1","status":"running","items":[{"recommendation_id":"rec-1","status":"pending"},{"recommendation_id":"rec-2","status":"pending"}]}`))
+		case "/api/v1/optimize/jobs/job-1/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("event: item\ndata: {\"index\":0,\"recommendation_id\":\"rec-1\",\"status\":\"done\",\"application_id\":\"app-1\"}\n\n"))
+		case "/api/v1/optimize/jobs/job-1":
+			polls++
+			w.Write([]byte(`{"id":"job-1","status":"completed","items":[{"recommendation_id":"rec-1","status":"done","application_id":"app-1"},{"recommendation_id":"rec-2","status":"done","application_id":"app-2"}]}`))
+		default:
+			t.Errorf("request to %s", r.URL.Path)
+		}
+	}), "apply", "rec-1", "rec-2", "--poll-interval", "10ms")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+
+	if polls != 1 {
+		t.Errorf("job polled %d times, want once", polls)
+	}
+	for _, want := range []string{"polling instead", "done     rec-1", "done     rec-2", "rec-2           done    app-2", "2 applied, 0 failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// An apply held for approval reports the job instead of waiting on it, and
+// approve and reject send the decision with its reason
+func TestApplyApproval(t *testing.T) {
+	pending := `{"id":"job-1","status":"pending_approval","items":[{"recommendation_id":"rec-1","status":"pending","requires_approval":true},{"recommendation_id":"rec-2","status":"pending"}],
+		"approval":{"requested_by":"alice","requested_at":"2026-09-16T09:00:00Z","expires_at":"2026-09-19T09:00:00Z"}}`
+	out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/optimize/apply" {
+			t.Errorf("request to %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(pending))
+	}), "apply", "rec-1", "rec-2")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	for _, want := range []string{"Apply job job-1 is pending approval", "Requested by alice", "cloudopt apply approve job-1", "  rec-1\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "  rec-2\n") {
+		t.Errorf("output lists rec-2 as requiring approval:\n%s", out)
+	}
+
+	tests := []struct {
+		decision string
+		status   string
+	}{
+		{decision: "approve", status: "running"},
+		{decision: "reject", status: "rejected"},
+	}
+	for _, tt := range tests {
+		var reason string
+		out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/api/v1/optimize/apply/job-1/"+tt.decision {
+				t.Errorf("request %s %s", r.Method, r.URL.Path)
+			}
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			reason = body["reason"]
+			w.Write([]byte(`{"id":"job-1","status":"` + tt.status + `","items":[{"recommendation_id":"rec-1","status":"pending","requires_approval":true}],
+				"approval":{"requested_by":"alice","decided_by":"bob","reason":"` + reason + `"}}`))
+		}), "apply", tt.decision, "job-1", "--reason", "change 42")
+		if err != nil {
+			t.Fatalf("%s: %v: %s", tt.decision, err, out)
+		}
+		if reason != "change 42" {
+			t.Errorf("%s sent reason %q", tt.decision, reason)
+		}
+		if !strings.Contains(out, "Apply job job-1 is "+tt.status) || !strings.Contains(out, "Decided by bob: change 42") {
+			t.Errorf("%s output:\n%s", tt.decision, out)
+		}
+	}
+
+	out, err = runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"an apply job cannot be approved by its requester"}`))
+	}), "apply", "approve", "job-1")
+	if err == nil || !strings.Contains(err.Error(), "failed to approve apply job") {
+		t.Errorf("error %v, want the approval failure: %s", err, out)
+	}
+}
+
+// An apply refused for compliance lists the frameworks and controls at stake,
+// and --override-compliance asks the gateway to apply anyway
+func TestApplyComplianceViolations(t *testing.T) {
+	refused := `{"error":{"code":"compliance_violation","message":"applying these recommendations would break placement compliance","details":{"violations":[
+		{"recommendation_id":"rec-1","placement_id":"plc-1","provider":"aws","region":"us-east-1","framework":"GDPR","controls":[{"id":"GDPR-44","description":"Transfers outside the EU"}]}]}}}`
+	pending := `{"id":"job-1","status":"pending_approval","items":[{"recommendation_id":"rec-1","status":"pending","requires_approval":true}]}`
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantOverride bool
+	}{
+		{name: "refused", args: []string{"apply", "rec-1"}},
+		{name: "overridden", args: []string{"apply", "rec-1", "--override-compliance"}, wantOverride: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var override bool
+			out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					OverrideCompliance bool `json:"override_compliance"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Error(err)
+				}
+				override = body.OverrideCompliance
+				if !override {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					w.Write([]byte(refused))
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(pending))
+			}), tt.args...)
+			if override != tt.wantOverride {
+				t.Errorf("override_compliance sent as %v, want %v", override, tt.wantOverride)
+			}
+
+			if tt.wantOverride {
+				if err != nil {
+					t.Fatalf("%v: %s", err, out)
+				}
+				if strings.Contains(out, "Refused") {
+					t.Errorf("overridden apply reports violations:\n%s", out)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("refused apply succeeded: %s", out)
+			}
+			for _, want := range []string{
+				"rerun with --override-compliance",
+				"  rec-1: placement plc-1 in aws/us-east-1 would not meet GDPR\n",
+				"    GDPR-44 Transfers outside the EU\n",
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/output"
+)
+
+// maxAuditPageSize is the largest page the gateway serves
+const maxAuditPageSize = 500
+
+var (
+	auditOutput         string
+	auditUser           string
+	auditSince          time.Duration
+	auditLimit          int
+	auditPageToken      string
+	auditAll            bool
+	auditLines          int
+	auditReconnectDelay time.Duration
+)
+
+// auditCmd represents the audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the gateway audit log",
+	Long: `List and follow the mutating requests the gateway audited for your tenant.
+The audit log is admin only: the gateway rejects tokens without the admin
+role. For example:
+
+cloudopt audit list --user alice --since 24h
+cloudopt audit list --all --output json
+cloudopt audit tail --user alice`,
+}
+
+var auditListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List audit entries, newest first",
+	Long: `List audit entries, newest first, one page at a time. When more entries
+remain, the command to fetch the next page is printed to stderr; --all
+fetches every page instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(auditOutput); err != nil {
+			return asValidationError(err)
+		}
+		if auditSince < 0 {
+			return validationErrorf("invalid since: %s (must not be negative)", auditSince)
+		}
+		if auditLimit < 1 || auditLimit > maxAuditPageSize {
+			return validationErrorf("invalid limit: %d (must be 1-%d)", auditLimit, maxAuditPageSize)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		q := api.AuditQuery{UserID: auditUser, Limit: auditLimit, PageToken: auditPageToken}
+		if auditSince > 0 {
+			q.Since = time.Now().Add(-auditSince)
+		}
+		page, err := listAudit(cmd.Context(), client.AuditLog, q, auditAll)
+		if err != nil {
+			return apiFailure("failed to list audit entries", err)
+		}
+
+		if err := writeOutput(cmd, auditOutput, page, func(w io.Writer) error {
+			return writeAuditEntries(w, page.Entries)
+		}); err != nil {
+			return err
+		}
+		if page.NextPageToken != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "More entries remain: rerun with --page-token %s\n", page.NextPageToken)
+		}
+		return nil
+	},
+}
+
+var auditTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Follow audit entries as they are recorded",
+	Long: `Print the most recent audit entries, then follow new ones live until
+interrupted. The gateway closes the stream at its write timeout and on
+restarts; tail reconnects after --reconnect-delay and resumes after the last
+entry it printed, so no entries are lost or repeated.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if auditLines < 0 || auditLines > maxAuditPageSize {
+			return validationErrorf("invalid lines: %d (must be 0-%d)", auditLines, maxAuditPageSize)
+		}
+		if auditReconnectDelay <= 0 {
+			return validationErrorf("invalid reconnect delay: %s (must be positive)", auditReconnectDelay)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// The newest entry marks where the stream resumes, so at least one is
+		// fetched even when none are printed
+		limit := auditLines
+		if limit < 1 {
+			limit = 1
+		}
+		recent, err := client.AuditLog(ctx, api.AuditQuery{UserID: auditUser, Limit: limit})
+		if err != nil {
+			return apiFailure("failed to list audit entries", err)
+		}
+		var after uint64
+		if len(recent.Entries) > 0 {
+			after = recent.Entries[0].Seq
+		}
+
+		out := cmd.OutOrStdout()
+		if auditLines > 0 {
+			for i := len(recent.Entries) - 1; i >= 0; i-- {
+				writeAuditLine(out, recent.Entries[i])
+			}
+		}
+
+		err = tailAudit(ctx, client.StreamAudit, auditUser, after, auditReconnectDelay, func(e api.AuditEntry) {
+			writeAuditLine(out, e)
+		}, cmd.ErrOrStderr())
+		if err != nil {
+			return apiFailure("failed to follow the audit log", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.AddCommand(auditListCmd)
+	auditCmd.AddCommand(auditTailCmd)
+
+	auditCmd.PersistentFlags().StringVar(&auditUser, "user", "", "only entries of this user ID")
+	auditListCmd.Flags().StringVar(&auditOutput, "output", output.FormatText, "output format (text, json, yaml)")
+	auditListCmd.Flags().DurationVar(&auditSince, "since", 0, "only entries recorded within this duration (e.g. 24h)")
+	auditListCmd.Flags().IntVar(&auditLimit, "limit", 50, "entries per page")
+	auditListCmd.Flags().StringVar(&auditPageToken, "page-token", "", "page to fetch, as printed after the previous page")
+	auditListCmd.Flags().BoolVar(&auditAll, "all", false, "fetch every remaining page")
+	auditTailCmd.Flags().IntVar(&auditLines, "lines", 10, "number of recent entries to print before following")
+	auditTailCmd.Flags().DurationVar(&auditReconnectDelay, "reconnect-delay", 2*time.Second, "time to wait before reconnecting a closed stream")
+}
+
+// auditPager fetches one page of the audit log, as api.Client.AuditLog does
+type auditPager func(ctx context.Context, q api.AuditQuery) (*api.AuditPage, error)
+
+// listAudit fetches the page selected by q, or with all that page and every
+// older one, returning the entries newest first
+func listAudit(ctx context.Context, fetch auditPager, q api.AuditQuery, all bool) (*api.AuditPage, error) {
+	page, err := fetch(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	for all && page.NextPageToken != "" {
+		q.PageToken = page.NextPageToken
+		next, err := fetch(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		page.Entries = append(page.Entries, next.Entries...)
+		page.NextPageToken = next.NextPageToken
+	}
+	return page, nil
+}
+
+// auditStreamer follows the audit log, as api.Client.StreamAudit does
+type auditStreamer func(ctx context.Context, userID string, after uint64, fn func(api.AuditEntry)) error
+
+// tailAudit streams the audit entries after the seq after to fn until ctx is
+// done. A closed or failed stream is reopened after delay, resuming after the
+// last entry seen; rejected requests such as a missing admin role are
+// returned instead, since retrying cannot fix them.
+func tailAudit(ctx context.Context, stream auditStreamer, userID string, after uint64, delay time.Duration, fn func(api.AuditEntry), notify io.Writer) error {
+	for {
+		err := stream(ctx, userID, after, func(e api.AuditEntry) {
+			after = e.Seq
+			fn(e)
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+			return err
+		}
+		if !errors.Is(err, api.ErrAuditStreamClosed) {
+			fmt.Fprintf(notify, "audit stream disconnected (%v); reconnecting in %s\n", err, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+	}
+}
+
+// writeAuditEntries writes the entries as a table
+func writeAuditEntries(w io.Writer, entries []api.AuditEntry) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No audit entries")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tUSER\tMETHOD\tPATH\tSTATUS\tCLIENT IP")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			formatDateTime(e.Time), e.UserID, e.Method, e.Path, e.Status, e.ClientIP)
+	}
+	return tw.Flush()
+}
+
+// writeAuditLine writes one followed entry
+func writeAuditLine(w io.Writer, e api.AuditEntry) {
+	fmt.Fprintf(w, "%s  %s  %s %s  %d\n", formatDateTime(e.Time), e.UserID, e.Method, e.Path, e.Status)
+}
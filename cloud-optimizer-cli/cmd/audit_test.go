@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud-optimizer-cli/api"
+)
+
+// audit list sends the filters and page token, and --all follows every
+// remaining page
+func TestAuditList(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantPages []string
+		wantOut   []string
+	}{
+		{
+			name:      "one page",
+			args:      []string{"--user", "alice", "--since", "24h", "--limit", "2"},
+			wantPages: []string{""},
+			wantOut:   []string{"POST", "/api/v1/placements/compute", "More entries remain: rerun with --page-token 9"},
+		},
+		{
+			name:      "page token",
+			args:      []string{"--user", "alice", "--limit", "2", "--page-token", "9"},
+			wantPages: []string{"9"},
+			wantOut:   []string{"/api/v1/placements/storage"},
+		},
+		{
+			name:      "all",
+			args:      []string{"--user", "alice", "--limit", "2", "--all"},
+			wantPages: []string{"", "9"},
+			wantOut:   []string{"/api/v1/placements/compute", "/api/v1/placements/storage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages []string
+			out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if r.URL.Path != "/api/v1/audit" || q.Get("user") != "alice" || q.Get("limit") != "2" {
+					t.Errorf("request %s", r.URL)
+				}
+				if tt.name == "one page" {
+					since, err := time.Parse(time.RFC3339, q.Get("since"))
+					if err != nil || time.Since(since) < 24*time.Hour || time.Since(since) > 25*time.Hour {
+						t.Errorf("since %q, want 24 hours ago", q.Get("since"))
+					}
+				} else if q.Has("since") {
+					t.Errorf("since %q sent without --since", q.Get("since"))
+				}
+
+				pages = append(pages, q.Get("page_token"))
+				if q.Get("page_token") == "" {
+					w.Write([]byte(`{"entries":[{"seq":10,"user_id":"alice","method":"POST","path":"/api/v1/placements/compute","status":201},
+						{"seq":9,"user_id":"alice","method":"PUT","path":"/api/v1/placements/compute/plc-1","status":200}],"next_page_token":"9"}`))
+					return
+				}
+				w.Write([]byte(`{"entries":[{"seq":4,"user_id":"alice","method":"POST","path":"/api/v1/placements/storage","status":201}]}`))
+			}), append([]string{"audit", "list"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			if strings.Join(pages, ",") != strings.Join(tt.wantPages, ",") {
+				t.Errorf("pages %q, want %q", pages, tt.wantPages)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			if tt.name == "all" && strings.Contains(out, "More entries remain") {
+				t.Errorf("--all printed a next page:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestAuditListInvalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"audit", "list", "--limit", "0"},
+		{"audit", "list", "--limit", "501"},
+		{"audit", "list", "--since", "-1h"},
+		{"audit", "tail", "--lines", "-1"},
+		{"audit", "tail", "--reconnect-delay", "0s"},
+	} {
+		out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("request sent to %s", r.URL.Path)
+		}), args...)
+		if ExitCode(err) != ExitValidation {
+			t.Errorf("%v: exit code %d (%v), want %d\n%s", args, ExitCode(err), err, ExitValidation, out)
+		}
+	}
+}
+
+// A non-admin token is reported as an authorization failure
+func TestAuditListForbidden(t *testing.T) {
+	_, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"admin role required"}`))
+	}), "audit", "list")
+	if ExitCode(err) != ExitAuth {
+		t.Errorf("exit code %d (%v), want %d", ExitCode(err), err, ExitAuth)
+	}
+}
+
+// tailAudit reopens a closed or dropped stream after the last entry it saw,
+// and gives up on rejected requests
+func TestTailAuditReconnects(t *testing.T) {
+	var afters []uint64
+	var seen []uint64
+	calls := 0
+	stream := func(ctx context.Context, userID string, after uint64, fn func(api.AuditEntry)) error {
+		if userID != "alice" {
+			t.Errorf("user %q, want alice", userID)
+		}
+		afters = append(afters, after)
+		calls++
+		switch calls {
+		case 1:
+			fn(api.AuditEntry{Seq: 5})
+			fn(api.AuditEntry{Seq: 6})
+			return api.ErrAuditStreamClosed
+		case 2:
+			return fmt.Errorf("%w: connection reset", api.ErrAuditStreamClosed)
+		case 3:
+			fn(api.AuditEntry{Seq: 9})
+			return errors.New("failed to open stream: connection refused")
+		case 4:
+			return &api.APIError{StatusCode: http.StatusServiceUnavailable}
+		}
+		return &api.APIError{StatusCode: http.StatusForbidden, Message: "admin role required"}
+	}
+
+	var notify bytes.Buffer
+	err := tailAudit(context.Background(), stream, "alice", 4, time.Millisecond, func(e api.AuditEntry) {
+		seen = append(seen, e.Seq)
+	}, &notify)
+
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("error %v, want the 403 returned", err)
+	}
+	if want := []uint64{4, 6, 6, 9, 9}; fmt.Sprint(afters) != fmt.Sprint(want) {
+		t.Errorf("streams opened after %v, want %v", afters, want)
+	}
+	if fmt.Sprint(seen) != "[5 6 9]" {
+		t.Errorf("entries %v, want 5, 6 and 9 once each", seen)
+	}
+	// A stream closed at the write timeout is reopened quietly
+	if got := strings.Count(notify.String(), "reconnecting"); got != 2 {
+		t.Errorf("%d disconnections reported, want 2:\n%s", got, notify.String())
+	}
+}
+
+func TestTailAuditStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := tailAudit(ctx, func(ctx context.Context, userID string, after uint64, fn func(api.AuditEntry)) error {
+		calls++
+		if calls == 2 {
+			cancel()
+			return ctx.Err()
+		}
+		return api.ErrAuditStreamClosed
+	}, "", 0, time.Millisecond, func(api.AuditEntry) {}, &bytes.Buffer{})
+	if err != nil || calls != 2 {
+		t.Errorf("tailAudit() = %v after %d streams, want nil after 2", err, calls)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/cache"
+	"cloud-optimizer-cli/output"
+)
+
+var (
+	cacheType   string
+	cacheOutput string
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the local caches",
+	Long: `Inspect and clear the on-disk caches kept in cache_dir (default
+~/.cloudopt/cache, overridable with CLOUDOPT_CACHE_DIR). For example:
+
+cloudopt cache info
+cloudopt cache clear --type cost
+cloudopt cache clear`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached data",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := cache.ValidateType(cacheType); err != nil {
+			return asValidationError(err)
+		}
+
+		dir, err := cacheDir()
+		if err != nil {
+			return err
+		}
+
+		cleared, err := cache.Clear(dir, cacheType)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Cleared %s cache in %s\n", strings.Join(cleared, ", "), dir)
+		return nil
+	},
+}
+
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show the size of each cache",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(cacheOutput); err != nil {
+			return asValidationError(err)
+		}
+
+		dir, err := cacheDir()
+		if err != nil {
+			return err
+		}
+
+		usage, err := cache.Info(dir)
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(cmd, cacheOutput, usage, func(w io.Writer) error {
+			return writeCacheInfo(w, dir, usage)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cacheInfoCmd)
+
+	cacheClearCmd.Flags().StringVar(&cacheType, "type", cache.TypeAll,
+		fmt.Sprintf("cache to clear (%s or %s)", strings.Join(cache.Types, ", "), cache.TypeAll))
+	cacheInfoCmd.Flags().StringVar(&cacheOutput, "output", output.FormatText, "output format (text, json, yaml)")
+}
+
+// cacheDir returns the configured cache directory
+func cacheDir() (string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	return cfg.ResolveCacheDir()
+}
+
+func writeCacheInfo(w io.Writer, dir string, usage []cache.Usage) error {
+	fmt.Fprintf(w, "Cache directory: %s\n\n", dir)
+
+	var files int
+	var bytes int64
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tFILES\tSIZE")
+	for _, u := range usage {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", u.Type, u.Files, formatBytes(u.Bytes))
+		files += u.Files
+		bytes += u.Bytes
+	}
+	fmt.Fprintf(tw, "total\t%d\t%s\n", files, formatBytes(bytes))
+	return tw.Flush()
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
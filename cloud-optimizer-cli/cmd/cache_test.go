@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/cache"
+)
+
+// cache clear removes only the requested cache type from CLOUDOPT_CACHE_DIR
+func TestCacheClear(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CLOUDOPT_CACHE_DIR", dir)
+	for _, typ := range cache.Types {
+		if err := os.MkdirAll(cache.Path(dir, typ), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cache.Path(dir, typ), "entry"), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	noAPI := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request to %s", r.URL.Path)
+	})
+
+	out, err := runCLI(t, noAPI, "cache", "clear", "--type", "cost")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if !strings.Contains(out, "Cleared cost cache in "+dir) {
+		t.Errorf("output %q", out)
+	}
+	for _, typ := range cache.Types {
+		_, err := os.Stat(cache.Path(dir, typ))
+		if exists := err == nil; exists == (typ == cache.TypeCost) {
+			t.Errorf("%s cache exists: %v", typ, exists)
+		}
+	}
+
+	out, err = runCLI(t, noAPI, "cache", "info")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if !strings.Contains(out, "Cache directory: "+dir) || !strings.Contains(out, "cost      0      0 B") {
+		t.Errorf("info output missing the remaining caches:\n%s", out)
+	}
+
+	if _, err := runCLI(t, noAPI, "cache", "clear", "--type", "tiles"); ExitCode(err) != ExitValidation {
+		t.Errorf("clearing an unknown cache type = %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/config"
+	"cloud-optimizer-cli/output"
+)
+
+// loadConfig loads the CLI configuration and applies environment and flag overrides
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Update(); err != nil {
+		return nil, fmt.Errorf("failed to apply config overrides: %v", err)
+	}
+
+	return cfg, nil
+}
+
+// newAPIClient creates a gateway client from the CLI configuration. The
+// endpoint is the one for default_region unless --api-endpoint is set. Retries
+// follow the configured preferences unless --no-retry is set. Responses are
+// cached so --offline can serve reads from the last successful fetch. The
+// locale and pager of text output are selected here too.
+func newAPIClient() (*api.Client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := setOutputLocale(cfg); err != nil {
+		return nil, err
+	}
+	setOutputPager(cfg)
+
+	if apiEndpoint != "" {
+		if cfg.APIEndpoints == nil {
+			cfg.APIEndpoints = make(map[string]config.APIEndpoint)
+		}
+		pinned := cfg.APIEndpoints["optimizer"].Version
+		cfg.APIEndpoints["optimizer"] = config.APIEndpoint{URL: apiEndpoint, Version: pinned}
+	}
+
+	dir, err := cfg.ResolveCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []api.Option{
+		api.WithCache(dir),
+		api.WithOffline(offline),
+	}
+	if noRetry {
+		opts = append(opts, api.WithRetryPolicy(api.RetryPolicy{}))
+	}
+
+	window, err := cfg.Preferences.TokenRefreshWindowDuration()
+	if err != nil {
+		return nil, err
+	}
+	if window > 0 {
+		opts = append(opts, api.WithTokenRefresh(window, persistToken))
+	}
+	return api.NewClientFromConfig(cfg, opts...)
+}
+
+// persistToken saves a refreshed API token to the config file. A token from
+// CLOUDOPT_API_TOKEN is not saved, since the file does not supply it.
+func persistToken(token string) error {
+	if os.Getenv("CLOUDOPT_API_TOKEN") != "" {
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return err
+	}
+	cfg.APIToken = token
+	return cfg.Save()
+}
+
+// writeOutput renders a command result in the given format to stdout, or to
+// the file set with --output-file. Long text output to a terminal is paged.
+func writeOutput(cmd *cobra.Command, format string, v interface{}, text output.TextFunc) error {
+	if paged, err := writePaged(cmd, format, v, text); paged {
+		return err
+	}
+
+	target, err := openOutput(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := target.Write(format, v, text); err != nil {
+		target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+// openOutput returns the target for command output: stdout, or the file set
+// with --output-file
+func openOutput(cmd *cobra.Command) (*output.Target, error) {
+	if outputFile != "" {
+		return output.OpenFile(outputFile, appendOutput)
+	}
+	return output.NewTarget(cmd.OutOrStdout()), nil
+}
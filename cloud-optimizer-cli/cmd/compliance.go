@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/output"
+)
+
+// evidenceFormatPDF exports compliance evidence as the gateway's PDF document
+const evidenceFormatPDF = "pdf"
+
+var (
+	complianceOutput      string
+	compliancePlacementID string
+	complianceFormat      string
+)
+
+// complianceCmd represents the compliance command
+var complianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Export compliance evidence for placements",
+}
+
+var complianceEvidenceCmd = &cobra.Command{
+	Use:   "evidence",
+	Short: "Export the compliance evidence bundle of a placement",
+	Long: `Export the evidence that a placement meets the compliance frameworks it
+required, for auditors: every control of each framework with its pass or fail
+result and the region certifications it is based on. For example:
+
+cloudopt compliance evidence --placement-id pl-123
+cloudopt compliance evidence --placement-id pl-123 --output json
+cloudopt compliance evidence --placement-id pl-123 --format pdf --output-file evidence.pdf`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if compliancePlacementID == "" {
+			return validationErrorf("--placement-id is required")
+		}
+		if complianceFormat != "" && complianceFormat != evidenceFormatPDF {
+			return validationErrorf("invalid format: %s (must be %s)", complianceFormat, evidenceFormatPDF)
+		}
+		if complianceFormat == evidenceFormatPDF && outputFile == "" && isTerminal(cmd.OutOrStdout()) {
+			return validationErrorf("a PDF is not written to a terminal: set --output-file or redirect stdout")
+		}
+		if err := output.ValidateFormat(complianceOutput); err != nil {
+			return asValidationError(err)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		if complianceFormat == evidenceFormatPDF {
+			data, err := client.ComplianceEvidencePDF(cmd.Context(), compliancePlacementID)
+			if err != nil {
+				return apiFailure("failed to export compliance evidence", err)
+			}
+			return writePDF(cmd, data)
+		}
+
+		evidence, err := client.ComplianceEvidence(cmd.Context(), compliancePlacementID)
+		if err != nil {
+			return apiFailure("failed to export compliance evidence", err)
+		}
+
+		return writeOutput(cmd, complianceOutput, evidence, func(w io.Writer) error {
+			return writeComplianceEvidence(w, evidence)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(complianceCmd)
+	complianceCmd.AddCommand(complianceEvidenceCmd)
+
+	complianceEvidenceCmd.Flags().StringVar(&compliancePlacementID, "placement-id", "", "placement to export evidence for")
+	complianceEvidenceCmd.Flags().StringVar(&complianceOutput, "output", output.FormatText, "output format (text, json, yaml)")
+	complianceEvidenceCmd.Flags().StringVar(&complianceFormat, "format", "", "export format (pdf: the gateway's PDF document, written as is)")
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && readline.IsTerminal(int(f.Fd()))
+}
+
+// writePDF writes a PDF document to the file set with --output-file, or to
+// stdout
+func writePDF(cmd *cobra.Command, data []byte) error {
+	if outputFile == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %v", err)
+	}
+	return nil
+}
+
+// writeComplianceEvidence writes the evidence's summary and a table of its
+// controls, followed by the regions they were evaluated against
+func writeComplianceEvidence(w io.Writer, e *api.ComplianceEvidence) error {
+	result := "PASS"
+	if !e.Passed {
+		result = "FAIL"
+	}
+	frameworks := strings.Join(e.Frameworks, ", ")
+	if frameworks == "" {
+		frameworks = "(none)"
+	}
+	fmt.Fprintf(w, "Placement:    %s (%s)\n", e.PlacementID, e.ResourceType)
+	fmt.Fprintf(w, "Frameworks:   %s\n", frameworks)
+	fmt.Fprintf(w, "Result:       %s\n", result)
+	fmt.Fprintf(w, "Generated at: %s\n", formatDateTime(e.GeneratedAt))
+
+	if len(e.Controls) == 0 {
+		fmt.Fprintln(w, "\nNo controls evaluated: the placement requires no compliance frameworks")
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FRAMEWORK\tCONTROL\tDESCRIPTION\tRESULT\tEVIDENCE")
+	for _, c := range e.Controls {
+		control := c.ControlID
+		if control == "" {
+			control = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Framework, control, c.Description, strings.ToUpper(c.Result), c.Evidence.Reason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Every control is evaluated against the same regions
+	fmt.Fprintln(w, "\nRegions:")
+	for _, r := range e.Controls[0].Evidence.Regions {
+		certified := strings.Join(r.Frameworks, ", ")
+		if certified == "" {
+			certified = "(none)"
+		}
+		fmt.Fprintf(w, "  %s/%s certified for: %s\n", r.Provider, r.Region, certified)
+	}
+	return nil
+}
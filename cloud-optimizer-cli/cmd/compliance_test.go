@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComplianceEvidence(t *testing.T) {
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/compliance/evidence" {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case r.URL.Query().Get("placement_id") != "pl-123":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"placement not found"}`))
+		case r.URL.Query().Get("format") == "pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4\n%%EOF\n"))
+		default:
+			w.Write([]byte(`{"placement_id":"pl-123","resource_type":"compute","frameworks":["GDPR"],"passed":false,
+				"generated_at":"2026-03-01T12:00:00Z","controls":[
+				{"framework":"GDPR","control_id":"Art.32","description":"Security of processing","result":"fail",
+				 "evidence":{"regions":[{"provider":"aws","region":"us-east-1","frameworks":["SOC2"]}],"uncertified":["aws/us-east-1"],"reason":"not every region is certified for GDPR"}},
+				{"framework":"GDPR","control_id":"Art.44","description":"Transfers of personal data to third countries","result":"fail",
+				 "evidence":{"regions":[{"provider":"aws","region":"us-east-1","frameworks":["SOC2"]}],"uncertified":["aws/us-east-1"],"reason":"not every region is certified for GDPR"}}]}`))
+		}
+	})
+
+	out, err := runCLI(t, gateway, "compliance", "evidence", "--placement-id", "pl-123")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	for _, want := range []string{
+		"Placement:    pl-123 (compute)\n",
+		"Result:       FAIL\n",
+		"GDPR       Art.32   Security of processing",
+		"GDPR       Art.44   Transfers of personal data to third countries  FAIL",
+		"  aws/us-east-1 certified for: SOC2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = runCLI(t, gateway, "compliance", "evidence", "--placement-id", "pl-123", "--output", "json")
+	if err != nil || !strings.Contains(out, `"control_id": "Art.44"`) {
+		t.Errorf("json output: %v:\n%s", err, out)
+	}
+
+	path := filepath.Join(t.TempDir(), "evidence.pdf")
+	if out, err := runCLI(t, gateway, "compliance", "evidence", "--placement-id", "pl-123", "--format", "pdf", "--output-file", path); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "%PDF-1.4\n%%EOF\n" {
+		t.Errorf("PDF file %q: %v", data, err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantExit int
+	}{
+		{name: "missing placement id", args: []string{"compliance", "evidence"}, wantExit: ExitValidation},
+		{name: "invalid format", args: []string{"compliance", "evidence", "--placement-id", "pl-123", "--format", "docx"}, wantExit: ExitValidation},
+		{name: "unknown placement", args: []string{"compliance", "evidence", "--placement-id", "pl-9"}, wantExit: ExitNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runCLI(t, gateway, tt.args...); ExitCode(err) != tt.wantExit {
+				t.Errorf("error %v (exit %d), want exit %d", err, ExitCode(err), tt.wantExit)
+			}
+		})
+	}
+}
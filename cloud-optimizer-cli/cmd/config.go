@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/config"
+	"cloud-optimizer-cli/doctor"
+	"cloud-optimizer-cli/output"
+)
+
+var (
+	doctorProviders []string
+	doctorOutput    string
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the CLI configuration",
+}
+
+var configDoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the configured credentials have the permissions cloudopt needs",
+	Long: `Probe each provider's configured credentials for the permissions cloudopt
+needs: describing instances, reading billing data and applying tags. Each
+permission is checked with a minimal call; EC2 permissions are checked with dry
+runs, so nothing is changed. The AWS billing check makes one Cost Explorer
+query, which AWS bills at $0.01.
+
+Missing permissions are reported with the IAM actions to grant, and exit with
+status 3. Providers are those with credentials configured unless --provider is
+set. For example:
+
+cloudopt config doctor
+cloudopt config doctor --provider aws --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(doctorOutput); err != nil {
+			return asValidationError(err)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		providers := doctorProviders
+		if len(providers) == 0 {
+			providers = configuredProviders(cfg)
+		}
+		if len(providers) == 0 {
+			return validationErrorf("no cloud credentials configured; set credentials or --provider")
+		}
+
+		var reports []*doctor.Report
+		for _, provider := range providers {
+			prober, err := newProber(cfg, provider)
+			if err != nil {
+				return err
+			}
+			reports = append(reports, doctor.Run(cmd.Context(), prober))
+		}
+
+		if err := writeOutput(cmd, doctorOutput, reports, func(w io.Writer) error {
+			return writeDoctorReports(w, reports)
+		}); err != nil {
+			return err
+		}
+		return doctorResult(reports)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configDoctorCmd)
+
+	configDoctorCmd.Flags().StringSliceVar(&doctorProviders, "provider", nil, "providers to check (aws, azure, gcp; default those with credentials configured)")
+	configDoctorCmd.Flags().StringVar(&doctorOutput, "output", output.FormatText, "output format (text, json, yaml)")
+}
+
+// configuredProviders returns the providers that have credentials configured
+func configuredProviders(cfg *config.Config) []string {
+	var providers []string
+	if c := cfg.Credentials.AWS; c.AccessKeyID != "" || c.Profile != "" {
+		providers = append(providers, "aws")
+	}
+	if c := cfg.Credentials.Azure; c.ClientID != "" {
+		providers = append(providers, "azure")
+	}
+	if c := cfg.Credentials.GCP; c.CredentialFile != "" {
+		providers = append(providers, "gcp")
+	}
+	return providers
+}
+
+// newProber returns the permission prober for a provider's credentials
+func newProber(cfg *config.Config, provider string) (doctor.Prober, error) {
+	switch provider {
+	case "aws":
+		return doctor.NewAWSProber(cfg.Credentials.AWS), nil
+	case "azure":
+		return doctor.NewAzureProber(cfg.Credentials.Azure), nil
+	case "gcp":
+		return doctor.NewGCPProber(cfg.Credentials.GCP), nil
+	}
+	return nil, validationErrorf("invalid provider: %s (must be aws, azure or gcp)", provider)
+}
+
+func writeDoctorReports(w io.Writer, reports []*doctor.Report) error {
+	for i, report := range reports {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, strings.ToUpper(report.Provider))
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, c := range report.Checks {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", strings.ToUpper(c.Status), c.Capability, c.Description)
+			switch c.Status {
+			case doctor.StatusMissing:
+				fmt.Fprintf(tw, "  \t\tfix: %s\n", c.Remediation)
+			case doctor.StatusUnknown:
+				fmt.Fprintf(tw, "  \t\terror: %s\n", c.Error)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// doctorResult fails the command when a permission is missing or could not be
+// checked
+func doctorResult(reports []*doctor.Report) error {
+	missing, unknown := 0, 0
+	for _, r := range reports {
+		missing += r.Count(doctor.StatusMissing)
+		unknown += r.Count(doctor.StatusUnknown)
+	}
+
+	switch {
+	case missing > 0:
+		return &AuthError{commandError{msg: fmt.Sprintf("%d required permissions are missing", missing)}}
+	case unknown > 0:
+		return fmt.Errorf("%d permissions could not be checked", unknown)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/doctor"
+)
+
+func TestDoctorReports(t *testing.T) {
+	ok := doctor.Check{Capability: doctor.CapabilityDescribeInstances, Description: "Describe EC2 instances", Status: doctor.StatusOK}
+	missing := doctor.Check{Capability: doctor.CapabilityApplyTags, Description: "Tag EC2 resources", Status: doctor.StatusMissing, Remediation: "allow ec2:CreateTags"}
+	unknown := doctor.Check{Capability: doctor.CapabilityReadBilling, Description: "Read cost and usage", Status: doctor.StatusUnknown, Error: "ce request failed"}
+
+	tests := []struct {
+		name     string
+		checks   []doctor.Check
+		wantCode int
+		want     []string
+	}{
+		{name: "granted", checks: []doctor.Check{ok}, wantCode: ExitOK, want: []string{"AWS", "OK", "describe_instances"}},
+		{name: "missing", checks: []doctor.Check{ok, missing, unknown}, wantCode: ExitAuth, want: []string{"MISSING", "fix: allow ec2:CreateTags", "error: ce request failed"}},
+		{name: "unknown", checks: []doctor.Check{ok, unknown}, wantCode: ExitError, want: []string{"UNKNOWN", "read_billing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := []*doctor.Report{{Provider: "aws", Checks: tt.checks}}
+			var buf bytes.Buffer
+			if err := writeDoctorReports(&buf, reports); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("report missing %q:\n%s", want, buf.String())
+				}
+			}
+			if code := ExitCode(doctorResult(reports)); code != tt.wantCode {
+				t.Errorf("exit code %d, want %d", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestConfigDoctorInvalidFlags(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request to %s", r.URL.Path)
+	})
+	for _, args := range [][]string{
+		{"config", "doctor"},
+		{"config", "doctor", "--provider", "oracle"},
+		{"config", "doctor", "--provider", "aws", "--output", "xml"},
+	} {
+		if _, err := runCLI(t, handler, args...); ExitCode(err) != ExitValidation {
+			t.Errorf("%v: exit code %d (%v), want %d", args, ExitCode(err), err, ExitValidation)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/output"
+)
+
+var (
+	costsOutput    string
+	costsStart     string
+	costsEnd       string
+	costsProvider  string
+	costsRegion    string
+	costsGroupBy   string
+	costsHorizon   int
+	costsStream    bool
+	costsDimension string
+
+	costsModel     string
+	costsAlpha     float64
+	costsSmoothing bool
+	costsFormat    string
+)
+
+// costsCmd represents the costs command
+var costsCmd = &cobra.Command{
+	Use:   "costs",
+	Short: "Query cloud costs from the gateway",
+	Long: `Query cost line items, grouped summaries and forecasts from the API
+gateway. For example:
+
+cloudopt costs list --start 2024-01-01 --end 2024-01-31 --provider aws
+cloudopt costs list --stream --output json > costs.ndjson
+cloudopt costs summary --group-by service
+cloudopt costs forecast --horizon 30 --output json
+cloudopt costs forecast --format grafana > forecast.json
+cloudopt costs attribution --dimension cost-center`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return asValidationError(output.ValidateFormat(costsOutput))
+	},
+}
+
+var costsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cost line items for a period",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		if costsStream {
+			return streamCostList(cmd, client)
+		}
+
+		analysis, err := client.Costs(cmd.Context(), costQuery())
+		if err != nil {
+			return apiFailure("failed to fetch costs", err)
+		}
+
+		return writeOutput(cmd, costsOutput, analysis, func(w io.Writer) error {
+			return writeCostList(w, analysis)
+		})
+	},
+}
+
+var costsSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize costs grouped by service, region, provider or tag",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		summary, err := client.CostSummary(cmd.Context(), costQuery(), costsGroupBy)
+		if err != nil {
+			return apiFailure("failed to fetch cost summary", err)
+		}
+
+		return writeOutput(cmd, costsOutput, summary, func(w io.Writer) error {
+			return writeCostSummary(w, summary)
+		})
+	},
+}
+
+// forecastFormatGrafana exports the forecast as Grafana time series
+const forecastFormatGrafana = "grafana"
+
+var costsForecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Forecast daily costs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if costsHorizon < 1 {
+			return validationErrorf("invalid horizon: %d (must be at least 1)", costsHorizon)
+		}
+		if costsModel != "linear" && costsModel != "ema" {
+			return validationErrorf("invalid model: %s (must be linear or ema)", costsModel)
+		}
+		if cmd.Flags().Changed("alpha") && !(costsAlpha > 0 && costsAlpha <= 1) {
+			return validationErrorf("invalid alpha: %g (must be greater than 0 and at most 1)", costsAlpha)
+		}
+
+		if costsFormat != "" && costsFormat != forecastFormatGrafana {
+			return validationErrorf("invalid format: %s (must be %s)", costsFormat, forecastFormatGrafana)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		opts := api.ForecastOptions{Model: costsModel, Alpha: costsAlpha, Smoothing: costsSmoothing}
+		if costsFormat == forecastFormatGrafana {
+			series, err := client.CostForecastSeries(cmd.Context(), costQuery(), costsHorizon, opts)
+			if err != nil {
+				return apiFailure("failed to fetch cost forecast", err)
+			}
+			// Grafana reads the series as JSON whatever --output says
+			return writeOutput(cmd, output.FormatJSON, series, nil)
+		}
+
+		forecast, err := client.CostForecast(cmd.Context(), costQuery(), costsHorizon, opts)
+		if err != nil {
+			return apiFailure("failed to fetch cost forecast", err)
+		}
+
+		return writeOutput(cmd, costsOutput, forecast, func(w io.Writer) error {
+			return writeCostForecast(w, forecast)
+		})
+	},
+}
+
+var costsAttributionCmd = &cobra.Command{
+	Use:   "attribution",
+	Short: "Attribute spend across providers to the values of a tag",
+	Long: `Attribute the spend of every provider to the values of one tag, such as
+cost-center, with each value broken down by provider. Spend without the tag
+is reported as "(untagged)". Amounts are converted by the gateway to its
+configured reporting currency.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if costsDimension == "" {
+			return validationErrorf("--dimension is required")
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		attribution, err := client.CostAttribution(cmd.Context(), costQuery(), costsDimension)
+		if err != nil {
+			return apiFailure("failed to fetch cost attribution", err)
+		}
+
+		return writeOutput(cmd, costsOutput, attribution, func(w io.Writer) error {
+			return writeCostAttribution(w, attribution)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(costsCmd)
+	costsCmd.AddCommand(costsListCmd)
+	costsCmd.AddCommand(costsSummaryCmd)
+	costsCmd.AddCommand(costsForecastCmd)
+	costsCmd.AddCommand(costsAttributionCmd)
+
+	costsCmd.PersistentFlags().StringVar(&costsOutput, "output", output.FormatText, "output format (text, json, yaml)")
+	costsCmd.PersistentFlags().StringVar(&costsStart, "start", "", "start date (YYYY-MM-DD, default 30 days before end)")
+	costsCmd.PersistentFlags().StringVar(&costsEnd, "end", "", "end date, inclusive (YYYY-MM-DD, default today)")
+	costsCmd.PersistentFlags().StringVar(&costsProvider, "provider", "", "only include costs from this provider")
+	costsCmd.PersistentFlags().StringVar(&costsRegion, "region", "", "only include costs from this region")
+
+	costsListCmd.Flags().BoolVar(&costsStream, "stream", false, "stream line items as they arrive instead of buffering the period; json output is one item per line")
+	costsSummaryCmd.Flags().StringVar(&costsGroupBy, "group-by", "service", "grouping (service, region, provider, tag:<key>)")
+	costsForecastCmd.Flags().IntVar(&costsHorizon, "horizon", 30, "number of days to forecast")
+	costsForecastCmd.Flags().StringVar(&costsModel, "model", "linear", "forecast model (linear, ema)")
+	costsForecastCmd.Flags().Float64Var(&costsAlpha, "alpha", 0, "EMA smoothing factor in (0, 1]; lower smooths more (default 0.3)")
+	costsAttributionCmd.Flags().StringVar(&costsDimension, "dimension", "", "tag key to attribute spend to, e.g. cost-center")
+	costsForecastCmd.Flags().BoolVar(&costsSmoothing, "smoothing", false, "smooth the history with an EMA before applying the model")
+	costsForecastCmd.Flags().StringVar(&costsFormat, "format", "", "export format (grafana: history, forecast and confidence bound time series as JSON)")
+}
+
+func costQuery() api.CostQuery {
+	return api.CostQuery{
+		StartDate: costsStart,
+		EndDate:   costsEnd,
+		Provider:  costsProvider,
+		Region:    costsRegion,
+	}
+}
+
+func writeCostList(w io.Writer, analysis *api.CostAnalysis) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tPROVIDER\tSERVICE\tREGION\tRESOURCE\tAMOUNT")
+	for _, item := range analysis.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			formatDate(item.Date), item.Provider, item.Service, item.Region, item.ResourceID, formatMoney(item.Amount, item.Currency))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\nTotal %s to %s: %s\n",
+		formatDate(analysis.PeriodStart), lastDay(analysis.PeriodEnd), formatMoney(analysis.TotalCost, analysis.Currency))
+	return err
+}
+
+// costRowFormat lays out streamed cost rows, which cannot be aligned with a
+// tabwriter since the widest value is not known in advance
+const costRowFormat = "%-10s  %-8s  %-20s  %-15s  %-24s  %s\n"
+
+// streamCostList writes cost line items as the gateway streams them, keeping
+// only the running total in memory
+func streamCostList(cmd *cobra.Command, client *api.Client) error {
+	target, err := openOutput(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := writeCostStream(cmd, client, target); err != nil {
+		target.Close()
+		return err
+	}
+	return target.Close()
+}
+
+func writeCostStream(cmd *cobra.Command, client *api.Client, target *output.Target) error {
+	text := costsOutput == output.FormatText
+	if text {
+		if err := target.WriteRecord(output.FormatText, nil, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, costRowFormat, "DATE", "PROVIDER", "SERVICE", "REGION", "RESOURCE", "AMOUNT")
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	var (
+		count    int
+		total    float64
+		currency string
+	)
+	err := client.StreamCosts(cmd.Context(), costQuery(), func(item api.CostLineItem) error {
+		count++
+		total += item.Amount
+		if currency == "" {
+			currency = item.Currency
+		}
+		return target.WriteRecord(costsOutput, item, func(w io.Writer) error {
+			_, err := fmt.Fprintf(w, costRowFormat, formatDate(item.Date), item.Provider, item.Service,
+				item.Region, item.ResourceID, formatMoney(item.Amount, item.Currency))
+			return err
+		})
+	})
+	if err != nil {
+		return apiFailure("failed to stream costs", err)
+	}
+
+	if !text {
+		return nil
+	}
+	return target.WriteRecord(output.FormatText, nil, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "\nTotal of %d items: %s\n", count, formatMoney(total, currency))
+		return err
+	})
+}
+
+func writeCostSummary(w io.Writer, summary *api.CostSummary) error {
+	fmt.Fprintf(w, "Costs by %s, %s to %s (%s)\n\n",
+		summary.GroupBy, formatDate(summary.PeriodStart), lastDay(summary.PeriodEnd), summary.Currency)
+
+	bars := make([]output.Bar, len(summary.Groups))
+	for i, g := range summary.Groups {
+		bars[i] = output.Bar{Label: g.Key, Value: g.Cost}
+	}
+	if err := output.BarChart(w, bars, output.DefaultChartWidth, "%.2f"); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\nTotal: %s\n", formatMoney(summary.TotalCost, summary.Currency))
+	return err
+}
+
+func writeCostAttribution(w io.Writer, attribution *api.CostAttribution) error {
+	fmt.Fprintf(w, "Costs by %s, %s to %s (%s)\n\n",
+		attribution.Dimension, formatDate(attribution.PeriodStart), lastDay(attribution.PeriodEnd), attribution.Currency)
+
+	providers := make([]string, 0, len(attribution.ByProvider))
+	for p := range attribution.ByProvider {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, strings.ToUpper(attribution.Dimension), "\tCOST\tSHARE")
+	for _, p := range providers {
+		fmt.Fprint(tw, "\t", strings.ToUpper(p))
+	}
+	fmt.Fprintln(tw)
+	for _, v := range attribution.Values {
+		fmt.Fprintf(tw, "%s\t%s\t%.1f%%", v.Value, formatAmount(v.Cost), v.Share*100)
+		for _, p := range providers {
+			fmt.Fprintf(tw, "\t%s", formatAmount(v.ByProvider[p]))
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\nTotal: %s\n", formatMoney(attribution.TotalCost, attribution.Currency))
+	return err
+}
+
+func writeCostForecast(w io.Writer, forecast *api.CostForecast) error {
+	daily := append([]api.DailyCost(nil), forecast.Daily...)
+	sort.Slice(daily, func(i, j int) bool {
+		return daily[i].Date.Before(daily[j].Date)
+	})
+
+	lower := dailyByDate(forecast.Lower)
+	upper := dailyByDate(forecast.Upper)
+	bounds := len(lower) > 0 && len(upper) > 0
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if bounds {
+		fmt.Fprintf(tw, "DATE\tFORECAST\tLOWER (%g%%)\tUPPER (%g%%)\n", forecast.ConfidenceLevel*100, forecast.ConfidenceLevel*100)
+	} else {
+		fmt.Fprintln(tw, "DATE\tFORECAST")
+	}
+	for _, d := range daily {
+		fmt.Fprintf(tw, "%s\t%s", formatDate(d.Date), formatAmount(d.Cost))
+		if bounds {
+			fmt.Fprintf(tw, "\t%s\t%s", formatAmount(lower[d.Date.Unix()]), formatAmount(upper[d.Date.Unix()]))
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	model := forecast.Model + " model"
+	if forecast.Alpha != 0 {
+		model += fmt.Sprintf(", alpha %g", forecast.Alpha)
+	}
+	if forecast.Smoothed {
+		model += ", smoothed"
+	}
+	_, err := fmt.Fprintf(w, "\nForecast for the next %d days (%s, %d days of history): %s\n",
+		forecast.HorizonDays, model, forecast.HistoryDays, formatMoney(forecast.ForecastTotal, forecast.Currency))
+	return err
+}
+
+// dailyByDate indexes daily costs by the Unix time of their date
+func dailyByDate(days []api.DailyCost) map[int64]float64 {
+	costs := make(map[int64]float64, len(days))
+	for _, d := range days {
+		costs[d.Date.Unix()] = d.Cost
+	}
+	return costs
+}
+
+// lastDay formats the inclusive last day of a period whose end is exclusive
+func lastDay(end time.Time) string {
+	return formatDate(end.AddDate(0, 0, -1))
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCostsCommands(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantPath  string
+		wantQuery url.Values
+		response  string
+		want      []string
+	}{
+		{
+			name:      "list",
+			args:      []string{"costs", "list", "--start", "2026-09-01", "--end", "2026-09-30", "--provider", "aws"},
+			wantPath:  "/api/v1/costs",
+			wantQuery: url.Values{"start_date": {"2026-09-01"}, "end_date": {"2026-09-30"}, "provider": {"aws"}},
+			response:  `{"total_cost":150,"currency":"USD","period_start":"2026-09-01T00:00:00Z","period_end":"2026-10-01T00:00:00Z","items":[{"date":"2026-09-01T00:00:00Z","provider":"aws","service":"ec2","region":"us-east-1","resource_id":"i-1","amount":150,"currency":"USD"}]}`,
+			want:      []string{"DATE", "ec2", "i-1", "150.00", "2026-09-30"},
+		},
+		{
+			name:      "summary",
+			args:      []string{"costs", "summary", "--group-by", "region", "--region", "us-east-1"},
+			wantPath:  "/api/v1/costs/summary",
+			wantQuery: url.Values{"group_by": {"region"}, "region": {"us-east-1"}},
+			response:  `{"group_by":"region","total_cost":200,"currency":"USD","period_start":"2026-09-01T00:00:00Z","period_end":"2026-10-01T00:00:00Z","groups":[{"key":"us-east-1","cost":200,"share":1}]}`,
+			want:      []string{"Costs by region", "us-east-1", "200.00"},
+		},
+		{
+			name:      "forecast",
+			args:      []string{"costs", "forecast", "--horizon", "2", "--model", "ema", "--alpha", "0.5"},
+			wantPath:  "/api/v1/costs/forecast",
+			wantQuery: url.Values{"horizon": {"2"}, "model": {"ema"}, "alpha": {"0.5"}},
+			response:  `{"model":"ema","alpha":0.5,"currency":"USD","horizon_days":2,"history_days":30,"forecast_total":20,"daily":[{"date":"2026-10-01T00:00:00Z","cost":10},{"date":"2026-10-02T00:00:00Z","cost":10}]}`,
+			want:      []string{"FORECAST", "2026-10-02", "next 2 days (ema model, alpha 0.5, 30 days of history)", "20.00"},
+		},
+		{
+			name:      "forecast as json",
+			args:      []string{"costs", "forecast", "--output", "json"},
+			wantPath:  "/api/v1/costs/forecast",
+			wantQuery: url.Values{"horizon": {"30"}, "model": {"linear"}},
+			response:  `{"model":"linear","currency":"USD","horizon_days":30,"forecast_total":300}`,
+			want:      []string{`"forecast_total": 300`},
+		},
+		{
+			name:     "forecast with bounds",
+			args:     []string{"costs", "forecast", "--horizon", "1"},
+			wantPath: "/api/v1/costs/forecast",
+			response: `{"model":"linear","currency":"USD","horizon_days":1,"history_days":30,"forecast_total":10,"confidence_level":0.95,
+				"daily":[{"date":"2026-10-01T00:00:00Z","cost":10}],"lower":[{"date":"2026-10-01T00:00:00Z","cost":8}],"upper":[{"date":"2026-10-01T00:00:00Z","cost":12.5}]}`,
+			want: []string{"LOWER (95%)", "UPPER (95%)", "8.00", "12.50"},
+		},
+		{
+			name:      "forecast as grafana series",
+			args:      []string{"costs", "forecast", "--format", "grafana", "--output", "yaml"},
+			wantPath:  "/api/v1/costs/forecast",
+			wantQuery: url.Values{"format": {"grafana"}, "horizon": {"30"}},
+			response: `[{"target":"history","datapoints":[[10,1790812800000]]},{"target":"forecast","datapoints":[[10,1790899200000]]},
+				{"target":"forecast_lower","datapoints":[[8,1790899200000]]},{"target":"forecast_upper","datapoints":[[12,1790899200000]]}]`,
+			want: []string{`"target": "forecast_lower"`, `"target": "forecast_upper"`, "1790899200000"},
+		},
+		{
+			name:      "attribution",
+			args:      []string{"costs", "attribution", "--dimension", "cost-center"},
+			wantPath:  "/api/v1/costs/attribution",
+			wantQuery: url.Values{"dimension": {"cost-center"}},
+			response:  `{"dimension":"cost-center","total_cost":100,"currency":"USD","period_start":"2026-09-01T00:00:00Z","period_end":"2026-10-01T00:00:00Z","by_provider":{"aws":60,"gcp":40},"values":[{"value":"cc-1","cost":100,"share":1,"by_provider":{"aws":60,"gcp":40}}]}`,
+			want:      []string{"COST-CENTER", "AWS", "GCP", "cc-1", "100.0%"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			var gotQuery url.Values
+			out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotQuery = r.URL.Path, r.URL.Query()
+				w.Write([]byte(tt.response))
+			}), tt.args...)
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+
+			if gotPath != tt.wantPath {
+				t.Errorf("request to %s, want %s", gotPath, tt.wantPath)
+			}
+			for key, want := range tt.wantQuery {
+				if got := gotQuery.Get(key); got != want[0] {
+					t.Errorf("query %s = %q, want %q", key, got, want[0])
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCostsForecastInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"costs", "forecast", "--horizon", "0"},
+		{"costs", "forecast", "--model", "arima"},
+		{"costs", "forecast", "--model", "ema", "--alpha", "2"},
+		{"costs", "forecast", "--format", "prometheus"},
+		{"costs", "attribution"},
+	} {
+		_, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("%v sent a request", args)
+		}), args...)
+		if code := ExitCode(err); code != ExitValidation {
+			t.Errorf("%v: exit code %d (%v), want %d", args, code, err, ExitValidation)
+		}
+	}
+}
+
+// --output-file writes results to the file instead of stdout, one JSON record
+// per run with --append
+func TestCostsOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "forecast.json")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"model":"linear","currency":"USD","horizon_days":30,"forecast_total":300}`))
+	})
+
+	for i := 0; i < 2; i++ {
+		out, err := runCLI(t, handler, "costs", "forecast", "--output", "json", "--output-file", path, "--append")
+		if err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		if strings.Contains(out, "forecast_total") {
+			t.Errorf("result written to stdout:\n%s", out)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], `{"model":"linear"`) {
+		t.Errorf("file contents %q, want one record per run", data)
+	}
+}
+
+// costs list --stream writes one JSON item per line as json output and
+// totals the items as text
+func TestCostsListStream(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept"); accept != "application/x-ndjson" {
+			t.Errorf("Accept %q, want application/x-ndjson", accept)
+		}
+		w.Write([]byte(`{"date":"2026-09-01T00:00:00Z","provider":"aws","service":"ec2","region":"us-east-1","amount":100,"currency":"USD"}
+{"date":"2026-09-02T00:00:00Z","provider":"aws","service":"s3","region":"us-east-1","amount":50,"currency":"USD"}
+`))
+	})
+
+	out, err := runCLI(t, handler, "costs", "list", "--stream", "--output", "json")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("%d lines, want one per item:\n%s", len(lines), out)
+	}
+	for i, line := range lines {
+		var item map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Errorf("line %d %q is not JSON: %v", i+1, line, err)
+		}
+	}
+
+	out, err = runCLI(t, handler, "costs", "list", "--stream")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	for _, want := range []string{"DATE", "ec2", "s3", "Total of 2 items", "150.00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// Text output formats amounts and dates for --locale, or preferences.locale
+// when the flag is unset, and keeps the plain forms without either
+func TestCostsSummaryLocale(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"group_by":"service","total_cost":1234.5,"currency":"EUR","period_start":"2026-09-01T00:00:00Z","period_end":"2026-10-01T00:00:00Z","groups":[{"key":"ec2","cost":1234.5,"share":1}]}`))
+	})
+
+	tests := []struct {
+		name    string
+		config  string
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{name: "default", want: []string{"1234.50 EUR", "2026-09-01"}, notWant: []string{"1.234,50", "€"}},
+		{name: "flag", args: []string{"--locale", "de-DE"}, want: []string{"1.234,50\u00a0€", "01.09.2026"}},
+		{name: "config", config: "preferences:\n  locale: en-GB\n", want: []string{"€1,234.50", "01/09/2026"}},
+		{name: "flag over config", config: "preferences:\n  locale: en-GB\n", args: []string{"--locale", "fr"}, want: []string{"1\u202f234,50\u00a0€"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.config)
+			out, err := runCLIWithHome(t, handler, append([]string{"costs", "summary"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+
+	if _, err := runCLI(t, handler, "costs", "summary", "--locale", "xx"); ExitCode(err) != ExitValidation {
+		t.Errorf("unsupported locale: exit code %d (%v), want %d", ExitCode(err), err, ExitValidation)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cloud-optimizer-cli/api"
+)
+
+// Exit codes returned by cloudopt, so scripts can tell failures apart:
+//
+//	0  success
+//	1  any other error
+//	2  invalid flags, arguments or input
+//	3  authentication or authorization failed
+//	4  the requested resource does not exist
+//	5  the gateway returned an error or could not be reached
+//	6  analyze --alert-below found resources scoring below the threshold
+//	7  analyze --alert-below could not score some resources, and every
+//	   resource scored is at or above the threshold
+const (
+	ExitOK         = 0
+	ExitError      = 1
+	ExitValidation = 2
+	ExitAuth       = 3
+	ExitNotFound   = 4
+	ExitAPI        = 5
+	ExitAlert      = 6
+	ExitUnscored   = 7
+)
+
+// commandError is an error message with an optional underlying cause
+type commandError struct {
+	msg string
+	err error
+}
+
+func (e *commandError) Error() string {
+	switch {
+	case e.err == nil:
+		return e.msg
+	case e.msg == "":
+		return e.err.Error()
+	}
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *commandError) Unwrap() error {
+	return e.err
+}
+
+// ValidationError is returned for invalid flags, arguments or input
+type ValidationError struct{ commandError }
+
+// AuthError is returned when the gateway rejects the caller's credentials
+type AuthError struct{ commandError }
+
+// NotFoundError is returned when the requested resource does not exist
+type NotFoundError struct{ commandError }
+
+// APIError is returned when the gateway fails a request or cannot be reached
+type APIError struct{ commandError }
+
+// AlertError is returned when analyze finds resources below the alert threshold
+type AlertError struct{ commandError }
+
+// UnscoredError is returned when analyze could not score resources checked
+// against the alert threshold
+type UnscoredError struct{ commandError }
+
+// validationErrorf returns a ValidationError with a formatted message
+func validationErrorf(format string, args ...interface{}) error {
+	return &ValidationError{commandError{msg: fmt.Sprintf(format, args...)}}
+}
+
+// notFoundErrorf returns a NotFoundError with a formatted message
+func notFoundErrorf(format string, args ...interface{}) error {
+	return &NotFoundError{commandError{msg: fmt.Sprintf(format, args...)}}
+}
+
+// asValidationError marks err as a validation failure
+func asValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValidationError{commandError{err: err}}
+}
+
+// apiFailure classifies an error returned by the gateway client by its
+// response status, prefixing it with msg
+func apiFailure(msg string, err error) error {
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return &AuthError{commandError{msg: msg, err: err}}
+		case http.StatusNotFound:
+			return &NotFoundError{commandError{msg: msg, err: err}}
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return &ValidationError{commandError{msg: msg, err: err}}
+		}
+	}
+	return &APIError{commandError{msg: msg, err: err}}
+}
+
+// ExitCode returns the process exit code for an error returned by a command
+func ExitCode(err error) int {
+	var (
+		validationErr *ValidationError
+		authErr       *AuthError
+		notFoundErr   *NotFoundError
+		apiErr        *APIError
+		alertErr      *AlertError
+		unscoredErr   *UnscoredError
+	)
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &validationErr):
+		return ExitValidation
+	case errors.As(err, &authErr):
+		return ExitAuth
+	case errors.As(err, &notFoundErr):
+		return ExitNotFound
+	case errors.As(err, &apiErr):
+		return ExitAPI
+	case errors.As(err, &alertErr):
+		return ExitAlert
+	case errors.As(err, &unscoredErr):
+		return ExitUnscored
+	}
+	return ExitError
+}
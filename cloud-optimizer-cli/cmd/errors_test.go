@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"cloud-optimizer-cli/api"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", want: ExitOK},
+		{name: "untyped", err: errors.New("boom"), want: ExitError},
+		{name: "validation", err: validationErrorf("invalid --horizon"), want: ExitValidation},
+		{name: "wrapped validation", err: fmt.Errorf("costs: %w", asValidationError(errors.New("bad flag"))), want: ExitValidation},
+		{name: "not found", err: notFoundErrorf("placement %s not found", "plc-1"), want: ExitNotFound},
+		{name: "unauthorized", err: apiFailure("failed", &api.APIError{StatusCode: http.StatusUnauthorized}), want: ExitAuth},
+		{name: "forbidden", err: apiFailure("failed", &api.APIError{StatusCode: http.StatusForbidden}), want: ExitAuth},
+		{name: "missing", err: apiFailure("failed", &api.APIError{StatusCode: http.StatusNotFound}), want: ExitNotFound},
+		{name: "rejected", err: apiFailure("failed", &api.APIError{StatusCode: http.StatusUnprocessableEntity}), want: ExitValidation},
+		{name: "server error", err: apiFailure("failed", &api.APIError{StatusCode: http.StatusInternalServerError}), want: ExitAPI},
+		{name: "unreachable", err: apiFailure("failed", &api.UnreachableError{Endpoint: "http://localhost:8080"}), want: ExitAPI},
+		{name: "alert", err: &AlertError{commandError{msg: "2 resources below 0.5"}}, want: ExitAlert},
+		{name: "unscored", err: &UnscoredError{commandError{msg: "1 resource not scored"}}, want: ExitUnscored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// Commands exit with the code of the gateway's response status, and with the
+// validation code for flags cobra rejects
+func TestCommandExitCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		status int
+		want   int
+	}{
+		{name: "unknown flag", args: []string{"costs", "list", "--bogus"}, want: ExitValidation},
+		{name: "unauthorized", args: []string{"costs", "list"}, status: http.StatusUnauthorized, want: ExitAuth},
+		{name: "not found", args: []string{"costs", "list"}, status: http.StatusNotFound, want: ExitNotFound},
+		{name: "bad request", args: []string{"costs", "list"}, status: http.StatusBadRequest, want: ExitValidation},
+		{name: "server error", args: []string{"costs", "list"}, status: http.StatusInternalServerError, want: ExitAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"error":"rejected"}`))
+			}), tt.args...)
+			if code := ExitCode(err); code != tt.want {
+				t.Errorf("exit code %d (%v), want %d", code, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+)
+
+// ignoreOrgDefaults skips the organization-wide exclusions and compliance
+// frameworks from the config
+var ignoreOrgDefaults bool
+
+// addOrgDefaultsFlag registers --ignore-org-defaults on a command whose
+// requests receive the configured exclusions and compliance frameworks
+func addOrgDefaultsFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&ignoreOrgDefaults, "ignore-org-defaults", false, "do not add the configured exclude_providers, exclude_regions and default_compliance_frameworks to the request")
+}
+
+// orgExclusions returns the providers and regions excluded by the config's
+// preferences, or none when --ignore-org-defaults is set
+func orgExclusions() (providers, regions []string, err error) {
+	if ignoreOrgDefaults {
+		return nil, nil, nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg.Preferences.ExcludeProviders, cfg.Preferences.ExcludeRegions, nil
+}
+
+// orgComplianceFrameworks returns the compliance frameworks the config's
+// preferences require by default, or none when --ignore-org-defaults is set.
+// They are checked against the compliance catalog.
+func orgComplianceFrameworks() ([]string, error) {
+	if ignoreOrgDefaults {
+		return nil, nil
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	frameworks := cfg.Preferences.DefaultComplianceFrameworks
+	if err := api.ValidateComplianceFrameworks(frameworks); err != nil {
+		return nil, validationErrorf("invalid preferences.default_compliance_frameworks: %v", err)
+	}
+	return frameworks, nil
+}
+
+// mergeExclusions appends the values of extra missing from list
+func mergeExclusions(list, extra []string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		seen[v] = true
+	}
+	for _, v := range extra {
+		if !seen[v] {
+			seen[v] = true
+			list = append(list, v)
+		}
+	}
+	return list
+}
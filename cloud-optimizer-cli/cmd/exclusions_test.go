@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/api"
+)
+
+const orgExclusionsConfig = `preferences:
+  exclude_providers: [azure]
+  exclude_regions: [us-west-1, ap-south-1]
+`
+
+func TestMergeExclusions(t *testing.T) {
+	tests := []struct {
+		list  []string
+		extra []string
+		want  []string
+	}{
+		{want: nil},
+		{list: []string{"aws"}, want: []string{"aws"}},
+		{extra: []string{"azure"}, want: []string{"azure"}},
+		{list: []string{"aws", "azure"}, extra: []string{"azure", "gcp", "gcp"}, want: []string{"aws", "azure", "gcp"}},
+	}
+
+	for _, tt := range tests {
+		if got := mergeExclusions(tt.list, tt.extra); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mergeExclusions(%v, %v) = %v, want %v", tt.list, tt.extra, got, tt.want)
+		}
+	}
+}
+
+// placement create adds the configured exclusions to the request's own unless
+// --ignore-org-defaults is set
+func TestPlacementCreateOrgExclusions(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantProviders []string
+		wantRegions   []string
+	}{
+		{
+			name:          "injected",
+			wantProviders: []string{"azure"},
+			wantRegions:   []string{"us-west-1", "ap-south-1"},
+		},
+		{
+			name:          "merged with the request's",
+			args:          []string{"--excluded-providers", "gcp,azure"},
+			wantProviders: []string{"gcp", "azure"},
+			wantRegions:   []string{"us-west-1", "ap-south-1"},
+		},
+		{
+			name:          "ignored",
+			args:          []string{"--excluded-providers", "gcp", "--ignore-org-defaults"},
+			wantProviders: []string{"gcp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, orgExclusionsConfig)
+			var got api.ComputeRequirements
+			args := append([]string{"placement", "create", "--name", "web", "--vcpus", "2", "--memory-gb", "8"}, tt.args...)
+			out, err := runCLIWithHome(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"plc-1"}`))
+			}), args...)
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			if !reflect.DeepEqual(got.ExcludedProviders, tt.wantProviders) || !reflect.DeepEqual(got.ExcludedRegions, tt.wantRegions) {
+				t.Errorf("excluded providers %v and regions %v, want %v and %v",
+					got.ExcludedProviders, got.ExcludedRegions, tt.wantProviders, tt.wantRegions)
+			}
+		})
+	}
+}
+
+// analyze sends the configured exclusions with every analysis unless
+// --ignore-org-defaults is set
+func TestAnalyzeOrgExclusions(t *testing.T) {
+	for _, ignore := range []bool{false, true} {
+		useConfig(t, orgExclusionsConfig)
+		var got api.AnalyzeRequest
+		args := []string{"analyze", "--provider", "aws", "--region", "us-east-1", "--resource-id", "i-1", "--cost-metrics"}
+		if ignore {
+			args = append(args, "--ignore-org-defaults")
+		}
+		out, err := runCLIWithHome(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v1/optimize/analyze" {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+			}
+			w.Write([]byte(`[]`))
+		}), args...)
+		if err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+
+		want := "azure|us-west-1,ap-south-1"
+		if ignore {
+			want = "|"
+		}
+		if gotExclusions := strings.Join(got.ExcludedProviders, ",") + "|" + strings.Join(got.ExcludedRegions, ","); gotExclusions != want {
+			t.Errorf("ignore %v: exclusions %s, want %s", ignore, gotExclusions, want)
+		}
+	}
+}
+
+// placement create and analyze require the configured compliance frameworks
+// alongside the request's own unless --ignore-org-defaults is set
+func TestOrgComplianceFrameworks(t *testing.T) {
+	const config = "preferences:\n  default_compliance_frameworks: [GDPR, SOC2]\n"
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "injected", want: []string{"GDPR", "SOC2"}},
+		{name: "merged with the request's", args: []string{"--compliance-frameworks", "HIPAA,GDPR"}, want: []string{"HIPAA", "GDPR", "SOC2"}},
+		{name: "ignored", args: []string{"--compliance-frameworks", "HIPAA", "--ignore-org-defaults"}, want: []string{"HIPAA"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, config)
+			var got api.ComputeRequirements
+			args := append([]string{"placement", "create", "--name", "web", "--vcpus", "2", "--memory-gb", "8"}, tt.args...)
+			out, err := runCLIWithHome(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"plc-1"}`))
+			}), args...)
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			if !reflect.DeepEqual(got.ComplianceFrameworks, tt.want) {
+				t.Errorf("compliance frameworks %v, want %v", got.ComplianceFrameworks, tt.want)
+			}
+		})
+	}
+
+	for _, ignore := range []bool{false, true} {
+		useConfig(t, config)
+		var got api.AnalyzeRequest
+		args := []string{"analyze", "--provider", "aws", "--region", "us-east-1", "--resource-id", "i-1", "--cost-metrics"}
+		if ignore {
+			args = append(args, "--ignore-org-defaults")
+		}
+		out, err := runCLIWithHome(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/v1/optimize/analyze" {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+			}
+			w.Write([]byte(`[]`))
+		}), args...)
+		if err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		want := "GDPR,SOC2"
+		if ignore {
+			want = ""
+		}
+		if frameworks := strings.Join(got.ComplianceFrameworks, ","); frameworks != want {
+			t.Errorf("ignore %v: analysis compliance frameworks %q, want %q", ignore, frameworks, want)
+		}
+	}
+}
+
+// Unknown default frameworks fail validation before any request is sent
+func TestOrgComplianceFrameworksInvalid(t *testing.T) {
+	for _, args := range [][]string{
+		{"placement", "create", "--name", "web", "--vcpus", "2", "--memory-gb", "8"},
+		{"analyze", "--provider", "aws", "--region", "us-east-1", "--resource-id", "i-1", "--cost-metrics"},
+	} {
+		useConfig(t, "preferences:\n  default_compliance_frameworks: [gdpr]\n")
+		out, err := runCLIWithHome(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("request sent to %s", r.URL.Path)
+		}), args...)
+		if ExitCode(err) != ExitValidation || !strings.Contains(err.Error(), `did you mean "GDPR"`) {
+			t.Errorf("%s: error %v (%s), want a validation error suggesting GDPR", args[0], err, out)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/export"
+	"cloud-optimizer-cli/inventory"
+	"cloud-optimizer-cli/output"
+)
+
+var (
+	exportRecommendationIDs []string
+	exportIssuesRepo        string
+	exportIssuesAPIURL      string
+	exportIssuesTitle       string
+	exportIssuesBodyFile    string
+	exportIssuesOutput      string
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export recommendations as infrastructure code",
+}
+
+var exportTerraformCmd = &cobra.Command{
+	Use:   "terraform",
+	Short: "Export recommendations as Terraform placement resources",
+	Long: `Generate HCL for the cloudoptimizer placement resources that codify the
+recommended configuration, with a provider block stub, ready to commit.
+Terminate recommendations are written as comments. For example:
+
+cloudopt export terraform --recommendation-id rec-123 --recommendation-id rec-456
+cloudopt export terraform --recommendation-id rec-123 --output-file placements.tf`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(exportRecommendationIDs) == 0 {
+			return validationErrorf("at least one --recommendation-id is required")
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		recs, err := selectRecommendations(cmd, client, exportRecommendationIDs)
+		if err != nil {
+			return err
+		}
+
+		var resources []inventory.Resource
+		if err := client.Get(cmd.Context(), "/resources", nil, &resources); err != nil {
+			return apiFailure("failed to fetch resources", err)
+		}
+		byID := make(map[string]inventory.Resource, len(resources))
+		for _, r := range resources {
+			byID[r.ID] = r
+		}
+
+		return writeOutput(cmd, output.FormatText, recs, func(w io.Writer) error {
+			return asValidationError(export.Terraform(w, client.BaseURL(), recs, byID))
+		})
+	},
+}
+
+var exportIssuesCmd = &cobra.Command{
+	Use:   "issues",
+	Short: "Export recommendations as GitHub issues",
+	Long: `Open a GitHub issue for each recommendation, with its savings and the steps
+to carry it out, and print the issue URLs. Each issue is labeled
+cloudopt:<recommendation-id>; recommendations that already have an issue,
+open or closed, are skipped and its URL printed instead. The token is read from
+GITHUB_TOKEN. The title and body are Go templates executed with the
+recommendation and its remediation steps. For example:
+
+cloudopt export issues --repo acme/infra --recommendation-id rec-123 --recommendation-id rec-456
+cloudopt export issues --repo acme/infra --recommendation-id rec-123 --title-template "Save {{.Recommendation.EstimatedSavings}}"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(exportIssuesOutput); err != nil {
+			return asValidationError(err)
+		}
+		if len(exportRecommendationIDs) == 0 {
+			return validationErrorf("at least one --recommendation-id is required")
+		}
+
+		var body string
+		if exportIssuesBodyFile != "" {
+			data, err := os.ReadFile(exportIssuesBodyFile)
+			if err != nil {
+				return validationErrorf("failed to read body template: %v", err)
+			}
+			body = string(data)
+		}
+		tmpl, err := export.NewIssueTemplate(exportIssuesTitle, body)
+		if err != nil {
+			return asValidationError(err)
+		}
+
+		exporter, err := export.NewGitHubIssues(exportIssuesAPIURL, exportIssuesRepo, os.Getenv("GITHUB_TOKEN"))
+		if err != nil {
+			return asValidationError(err)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		recs, err := selectRecommendations(cmd, client, exportRecommendationIDs)
+		if err != nil {
+			return err
+		}
+
+		results, exportErr := export.ExportIssues(cmd.Context(), exporter, tmpl, recs)
+		if err := writeOutput(cmd, exportIssuesOutput, results, func(w io.Writer) error {
+			return writeIssueResults(w, results)
+		}); err != nil {
+			return err
+		}
+		return exportErr
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportTerraformCmd)
+	exportCmd.AddCommand(exportIssuesCmd)
+
+	exportTerraformCmd.Flags().StringSliceVar(&exportRecommendationIDs, "recommendation-id", nil, "recommendation to export (repeatable)")
+
+	exportIssuesCmd.Flags().StringSliceVar(&exportRecommendationIDs, "recommendation-id", nil, "recommendation to export (repeatable)")
+	exportIssuesCmd.Flags().StringVar(&exportIssuesRepo, "repo", "", "GitHub repository to open issues in, as owner/name")
+	exportIssuesCmd.Flags().StringVar(&exportIssuesAPIURL, "github-api-url", export.DefaultGitHubAPIURL, "GitHub REST API url, for GitHub Enterprise Server")
+	exportIssuesCmd.Flags().StringVar(&exportIssuesTitle, "title-template", "", "Go template for issue titles (default a summary of the recommendation)")
+	exportIssuesCmd.Flags().StringVar(&exportIssuesBodyFile, "body-template", "", "file holding a Go template for issue bodies")
+	exportIssuesCmd.Flags().StringVar(&exportIssuesOutput, "output", output.FormatText, "output format (text, json, yaml)")
+	exportIssuesCmd.MarkFlagRequired("repo")
+}
+
+// writeIssueResults lists the issue of each exported recommendation
+func writeIssueResults(w io.Writer, results []export.IssueResult) error {
+	for _, r := range results {
+		status := "created"
+		if !r.Created {
+			status = "exists"
+		}
+		if _, err := fmt.Fprintf(w, "%-7s  %s  %s\n", status, r.RecommendationID, r.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectRecommendations returns the recommendations with the given IDs, in
+// the order requested
+func selectRecommendations(cmd *cobra.Command, client *api.Client, ids []string) ([]api.Recommendation, error) {
+	all, err := client.Recommendations(cmd.Context())
+	if err != nil {
+		return nil, apiFailure("failed to fetch recommendations", err)
+	}
+
+	byID := make(map[string]api.Recommendation, len(all))
+	for _, rec := range all {
+		byID[rec.ID] = rec
+	}
+
+	var missing []string
+	recs := make([]api.Recommendation, 0, len(ids))
+	for _, id := range ids {
+		rec, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	if len(missing) > 0 {
+		return nil, notFoundErrorf("recommendations not found: %s", strings.Join(missing, ", "))
+	}
+	return recs, nil
+}
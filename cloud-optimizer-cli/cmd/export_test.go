@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExportIssues(t *testing.T) {
+	var titles []string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			if r.URL.Query().Get("labels") == "cloudopt:rec-1" {
+				w.Write([]byte(`[{"html_url":"https://github.com/acme/infra/issues/7"}]`))
+				return
+			}
+			w.Write([]byte(`[]`))
+			return
+		}
+		var issue struct {
+			Title string `json:"title"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+			t.Error(err)
+		}
+		titles = append(titles, issue.Title)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url":"https://github.com/acme/infra/issues/8"}`))
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_TOKEN", "token")
+
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"rec-1","type":"cost","action":"tag","description":"Tag logs"},
+			{"id":"rec-2","type":"cost","action":"resize","description":"Downsize web","estimated_savings":42.5}]`))
+	})
+	out, err := runCLI(t, gateway, "export", "issues", "--repo", "acme/infra", "--github-api-url", github.URL,
+		"--recommendation-id", "rec-1", "--recommendation-id", "rec-2", "--title-template", "Save {{.Recommendation.EstimatedSavings}}")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	for _, want := range []string{
+		"exists   rec-1  https://github.com/acme/infra/issues/7\n",
+		"created  rec-2  https://github.com/acme/infra/issues/8\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if len(titles) != 1 || titles[0] != "Save 42.5" {
+		t.Errorf("issues filed with titles %q, want one from the title template", titles)
+	}
+
+	_, err = runCLI(t, gateway, "export", "issues", "--repo", "acme/infra", "--github-api-url", github.URL, "--recommendation-id", "rec-9")
+	if ExitCode(err) != ExitNotFound {
+		t.Errorf("unknown recommendation: error %v (exit %d), want not found", err, ExitCode(err))
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	_, err = runCLI(t, gateway, "export", "issues", "--repo", "acme/infra", "--recommendation-id", "rec-1")
+	if ExitCode(err) != ExitValidation || !strings.Contains(err.Error(), "GitHub token is required") {
+		t.Errorf("no token: error %v, want a validation error", err)
+	}
+}
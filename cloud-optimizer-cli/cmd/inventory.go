@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/config"
+	"cloud-optimizer-cli/inventory"
+)
+
+var inventoryOutputFile string
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Export and import the scanned resource inventory",
+	Long: `Export the resource inventory scanned by the gateway for backup and offline
+analysis, or import a previously exported inventory so that analyze --inventory
+takes the resources to analyze from it rather than the gateway's live
+inventory. For example:
+
+cloudopt inventory export --output inventory.json
+cloudopt inventory import inventory.json
+cloudopt analyze --provider aws --inventory`,
+}
+
+var inventoryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the gateway's resource inventory to a file",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		var resources []inventory.Resource
+		if err := client.Get(cmd.Context(), "/resources", nil, &resources); err != nil {
+			return apiFailure("failed to fetch inventory", err)
+		}
+
+		inv := inventory.New(client.BaseURL(), resources)
+		if inventoryOutputFile == "" || inventoryOutputFile == "-" {
+			return inventory.Write(os.Stdout, inv)
+		}
+
+		if err := inventory.Save(inventoryOutputFile, inv); err != nil {
+			return err
+		}
+
+		fmt.Printf("Exported %d resources to %s\n", len(inv.Resources), inventoryOutputFile)
+		return nil
+	},
+}
+
+var inventoryImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import an exported inventory for offline analysis",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inv, err := inventory.Load(args[0])
+		if err != nil {
+			return err
+		}
+
+		path, err := localInventoryPath()
+		if err != nil {
+			return err
+		}
+
+		if err := inventory.Save(path, inv); err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported %d resources from %s\n", len(inv.Resources), args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+	inventoryCmd.AddCommand(inventoryExportCmd)
+	inventoryCmd.AddCommand(inventoryImportCmd)
+
+	inventoryExportCmd.Flags().StringVarP(&inventoryOutputFile, "output", "o", "", "file to write the inventory to (default stdout)")
+}
+
+// localInventoryPath returns where imported inventories are kept for analyze
+// --inventory
+func localInventoryPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "inventory.json"), nil
+}
+
+// loadLocalInventory reads the inventory imported with inventory import
+func loadLocalInventory() (*inventory.Inventory, error) {
+	path, err := localInventoryPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, validationErrorf("no inventory imported: run 'cloudopt inventory import <file>' first")
+	}
+	return inventory.Load(path)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"cloud-optimizer-cli/config"
+	"cloud-optimizer-cli/output"
+)
+
+// outputLocale formats amounts and dates in text output. It is nil unless a
+// locale is selected, keeping the default "1234.56 USD" and 2006-01-02 forms.
+var outputLocale *output.Locale
+
+// setOutputLocale selects the locale of text output from --locale or, when
+// that is unset, from CLOUDOPT_LOCALE or preferences.locale
+func setOutputLocale(cfg *config.Config) error {
+	tag := localeTag
+	if tag == "" {
+		tag = cfg.Preferences.Locale
+	}
+
+	outputLocale = nil
+	if tag == "" {
+		return nil
+	}
+	l, err := output.LookupLocale(tag)
+	if err != nil {
+		return asValidationError(err)
+	}
+	outputLocale = &l
+	return nil
+}
+
+// formatMoney formats an amount with its currency in the output locale
+func formatMoney(amount float64, currency string) string {
+	if outputLocale == nil {
+		return fmt.Sprintf("%.2f %s", amount, currency)
+	}
+	return outputLocale.Money(amount, currency)
+}
+
+// formatAmount formats an amount shown without its currency, such as a table
+// cell under a heading naming the currency
+func formatAmount(amount float64) string {
+	if outputLocale == nil {
+		return fmt.Sprintf("%.2f", amount)
+	}
+	return outputLocale.Number(amount, 2)
+}
+
+// formatDate formats the calendar date of t in the output locale
+func formatDate(t time.Time) string {
+	if outputLocale == nil {
+		return t.Format("2006-01-02")
+	}
+	return outputLocale.Date(t)
+}
+
+// formatDateTime formats a timestamp in the output locale
+func formatDateTime(t time.Time) string {
+	if outputLocale == nil {
+		return t.Format(time.RFC3339)
+	}
+	return outputLocale.DateTime(t)
+}
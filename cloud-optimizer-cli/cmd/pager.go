@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/chzyer/readline"
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/config"
+	"cloud-optimizer-cli/output"
+)
+
+// outputPager is the configured pager command line, and outputNoPager
+// disables paging as preferences.no_pager does
+var (
+	outputPager   string
+	outputNoPager bool
+)
+
+// setOutputPager selects the pager of text output from preferences.pager
+// and preferences.no_pager
+func setOutputPager(cfg *config.Config) {
+	outputPager = cfg.Preferences.Pager
+	outputNoPager = cfg.Preferences.NoPager
+}
+
+// writePaged renders text output through the pager when stdout is a
+// terminal the output is taller than. It reports false, having written
+// nothing, when the output isn't paged.
+func writePaged(cmd *cobra.Command, format string, v interface{}, text output.TextFunc) (bool, error) {
+	if noPager || outputNoPager || outputFile != "" || format != output.FormatText {
+		return false, nil
+	}
+	stdout, ok := cmd.OutOrStdout().(*os.File)
+	if !ok {
+		return false, nil
+	}
+	fd := int(stdout.Fd())
+	terminal := readline.IsTerminal(fd)
+	if !terminal {
+		return false, nil
+	}
+	_, height, err := readline.GetSize(fd)
+	if err != nil {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	if err := output.Write(&buf, format, v, text); err != nil {
+		return true, err
+	}
+	data := buf.Bytes()
+	if !output.ShouldPage(format, terminal, output.CountLines(data), height) {
+		_, err := stdout.Write(data)
+		return true, err
+	}
+
+	if err := output.Page(output.PagerCommand(outputPager), data, stdout, cmd.ErrOrStderr()); err != nil {
+		// Without a pager the output is still shown, unpaged
+		if verbose {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+		}
+		_, err := stdout.Write(data)
+		return true, err
+	}
+	return true, nil
+}
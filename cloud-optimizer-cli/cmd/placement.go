@@ -0,0 +1,507 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/config"
+	"cloud-optimizer-cli/output"
+)
+
+var (
+	placementType     string
+	placementFile     string
+	placementTemplate string
+	placementOutput   string
+	placementSoft     bool
+
+	// Requirement overrides for placement create
+	placementName            string
+	placementVCPUs           int
+	placementMemoryGB        float64
+	placementRegions         []string
+	placementMinAvailability float64
+	placementSLATier         string
+	placementMaxBudget       float64
+	placementCurrentCost     float64
+	placementExcluded        []string
+	placementExcludedTypes   []string
+	placementCompliance      []string
+	placementTags            map[string]string
+)
+
+// placementCmd represents the placement command
+var placementCmd = &cobra.Command{
+	Use:   "placement",
+	Short: "Create and manage resource placements",
+}
+
+var placementDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a placement",
+	Long: `Delete a placement permanently, or with --soft mark it deleted so it can
+be brought back with placement restore until the gateway's retention period
+passes.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeletePlacement(cmd.Context(), placementType, args[0], placementSoft); err != nil {
+			return apiFailure("failed to delete placement", err)
+		}
+
+		if placementSoft {
+			fmt.Fprintf(cmd.OutOrStdout(), "Soft-deleted placement %s\n", args[0])
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted placement %s\n", args[0])
+		}
+		return nil
+	},
+}
+
+var placementRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a soft-deleted placement",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(placementOutput); err != nil {
+			return asValidationError(err)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		p, err := client.RestorePlacement(cmd.Context(), placementType, args[0])
+		if err != nil {
+			return apiFailure("failed to restore placement", err)
+		}
+		warnStalePrices(cmd.ErrOrStderr(), p)
+		warnCostIncrease(cmd.ErrOrStderr(), p)
+
+		return writeOutput(cmd, placementOutput, p, func(w io.Writer) error {
+			return writePlacement(w, p)
+		})
+	},
+}
+
+var placementHistoryCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Show how a placement's decision changed over time",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(placementOutput); err != nil {
+			return asValidationError(err)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		versions, err := client.PlacementHistory(cmd.Context(), placementType, args[0])
+		if err != nil {
+			return apiFailure("failed to fetch placement history", err)
+		}
+
+		return writeOutput(cmd, placementOutput, versions, func(w io.Writer) error {
+			return writePlacementHistory(w, versions)
+		})
+	},
+}
+
+var placementDiffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Show what changed between two saved placement decisions",
+	Long: `Compare two placement decisions saved with --output json or yaml, such as
+one from before and one from after re-optimizing, and show the changes to
+provider, region, instance type, monthly cost and scores. For example:
+
+cloudopt placement create -f requirements.yaml --output json > old.json
+cloudopt placement create -f requirements.yaml --output json > new.json
+cloudopt placement diff old.json new.json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(placementOutput); err != nil {
+			return asValidationError(err)
+		}
+
+		old, err := readPlacement(args[0])
+		if err != nil {
+			return err
+		}
+		updated, err := readPlacement(args[1])
+		if err != nil {
+			return err
+		}
+
+		diff := api.DiffPlacements(old, updated)
+		return writeOutput(cmd, placementOutput, diff, func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, diff.Summary())
+			return err
+		})
+	},
+}
+
+var placementCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Place a resource from a requirements file or template",
+	Long: `Build a requirements payload, validate it and create the placement.
+
+Requirements are merged from, in increasing precedence: the named template
+from the config file's templates section (--template), a JSON or YAML file
+chosen by its extension or stdin with -f -, and the requirement flags.
+Templates may extend another template with extends. Tags and other mappings
+are merged key by key; lists and other values are replaced. The config's
+exclude_providers and exclude_regions preferences are then added to the
+exclusions, and its default_compliance_frameworks to the required
+frameworks, unless --ignore-org-defaults is set. For example:
+
+cloudopt placement create --type compute -f requirements.json
+cloudopt placement create --type compute -f requirements.yaml --output json
+cloudopt placement create --template prod-base --type compute --name api --vcpus 4 --memory-gb 16
+generate-requirements | cloudopt placement create --type compute -f -`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(placementOutput); err != nil {
+			return asValidationError(err)
+		}
+		if placementType != "compute" {
+			return validationErrorf("invalid type: %s (only compute placements are supported)", placementType)
+		}
+
+		fields, err := requirementFields(cmd)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return validationErrorf("no requirements: set --file, --template or requirement flags")
+		}
+
+		var req api.ComputeRequirements
+		if err := decodeRequirementFields(fields, &req); err != nil {
+			return validationErrorf("invalid requirements: %v", err)
+		}
+
+		providers, regions, err := orgExclusions()
+		if err != nil {
+			return err
+		}
+		req.ExcludedProviders = mergeExclusions(req.ExcludedProviders, providers)
+		req.ExcludedRegions = mergeExclusions(req.ExcludedRegions, regions)
+		frameworks, err := orgComplianceFrameworks()
+		if err != nil {
+			return err
+		}
+		req.ComplianceFrameworks = mergeExclusions(req.ComplianceFrameworks, frameworks)
+		if err := req.Validate(); err != nil {
+			return validationErrorf("invalid requirements: %v", err)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		p, err := client.CreateComputePlacement(cmd.Context(), &req)
+		if err != nil {
+			return apiFailure("failed to create placement", err)
+		}
+		warnStalePrices(cmd.ErrOrStderr(), p)
+		warnCostIncrease(cmd.ErrOrStderr(), p)
+
+		return writeOutput(cmd, placementOutput, p, func(w io.Writer) error {
+			return writePlacement(w, p)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(placementCmd)
+	placementCmd.AddCommand(placementCreateCmd)
+	placementCmd.AddCommand(placementHistoryCmd)
+	placementCmd.AddCommand(placementDiffCmd)
+	placementCmd.AddCommand(placementDeleteCmd)
+	placementCmd.AddCommand(placementRestoreCmd)
+
+	placementCmd.PersistentFlags().StringVar(&placementType, "type", "compute", "placement type (compute)")
+	placementCmd.PersistentFlags().StringVar(&placementOutput, "output", output.FormatText, "output format (text, json, yaml)")
+	placementCreateCmd.Flags().StringVarP(&placementFile, "file", "f", "", "requirements file (.json, .yaml or .yml), or - for stdin")
+	placementCreateCmd.Flags().StringVar(&placementTemplate, "template", "", "requirements template from the config file to start from")
+	placementCreateCmd.Flags().StringVar(&placementName, "name", "", "resource name")
+	placementCreateCmd.Flags().IntVar(&placementVCPUs, "vcpus", 0, "number of virtual CPUs")
+	placementCreateCmd.Flags().Float64Var(&placementMemoryGB, "memory-gb", 0, "memory in GB")
+	placementCreateCmd.Flags().StringSliceVar(&placementRegions, "regions", nil, "allowed regions")
+	placementCreateCmd.Flags().Float64Var(&placementMinAvailability, "min-availability", 0, "minimum availability percentage")
+	placementCreateCmd.Flags().StringVar(&placementSLATier, "sla-tier", "", "SLA tier (bronze, silver, gold, platinum)")
+	placementCreateCmd.Flags().Float64Var(&placementMaxBudget, "max-monthly-budget", 0, "maximum monthly budget in USD")
+	placementCreateCmd.Flags().Float64Var(&placementCurrentCost, "current-monthly-cost", 0, "what the workload costs today in USD per month, to estimate savings against")
+	placementCreateCmd.Flags().StringSliceVar(&placementExcluded, "excluded-providers", nil, "providers to exclude")
+	placementCreateCmd.Flags().StringSliceVar(&placementExcludedTypes, "excluded-instance-types", nil, "instance types to exclude, by exact name")
+	placementCreateCmd.Flags().StringSliceVar(&placementCompliance, "compliance-frameworks", nil, "required compliance frameworks")
+	addOrgDefaultsFlag(placementCreateCmd)
+	placementCreateCmd.Flags().StringToStringVar(&placementTags, "tags", nil, "cost-allocation tags as key=value, merged over template and file tags")
+	placementDeleteCmd.Flags().BoolVar(&placementSoft, "soft", false, "soft-delete so the placement can be restored")
+}
+
+// requirementFields merges the template, the requirements file and the
+// requirement flags, each present one taking precedence over the last
+func requirementFields(cmd *cobra.Command) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if placementTemplate != "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return nil, err
+		}
+		fields, err = cfg.ResolveTemplate(placementTemplate)
+		if err != nil {
+			return nil, validationErrorf("invalid template: %v", err)
+		}
+	}
+
+	if placementFile != "" {
+		data, err := readRequirements(cmd, placementFile)
+		if err != nil {
+			return nil, err
+		}
+		var file map[string]interface{}
+		if err := decodeRequirements(data, requirementsFormat(placementFile, data), &file); err != nil {
+			return nil, validationErrorf("invalid requirements: %v", err)
+		}
+		config.MergeRequirements(fields, file)
+	}
+
+	config.MergeRequirements(fields, requirementFlags(cmd))
+	return fields, nil
+}
+
+// requirementFlags returns the requirement fields set with flags
+func requirementFlags(cmd *cobra.Command) map[string]interface{} {
+	fields := make(map[string]interface{})
+	flags := cmd.Flags()
+	set := func(flag, field string, v interface{}) {
+		if flags.Changed(flag) {
+			fields[field] = v
+		}
+	}
+
+	set("name", "name", placementName)
+	set("vcpus", "vcpus", placementVCPUs)
+	set("memory-gb", "memory_gb", placementMemoryGB)
+	set("regions", "regions", placementRegions)
+	set("min-availability", "min_availability", placementMinAvailability)
+	set("sla-tier", "sla_tier", placementSLATier)
+	set("max-monthly-budget", "max_monthly_budget", placementMaxBudget)
+	set("current-monthly-cost", "current_monthly_cost", placementCurrentCost)
+	set("excluded-providers", "excluded_providers", placementExcluded)
+	set("excluded-instance-types", "excluded_instance_types", placementExcludedTypes)
+	set("compliance-frameworks", "compliance_frameworks", placementCompliance)
+	if flags.Changed("tags") {
+		tags := make(map[string]interface{}, len(placementTags))
+		for k, v := range placementTags {
+			tags[k] = v
+		}
+		fields["tags"] = tags
+	}
+	return fields
+}
+
+// decodeRequirementFields decodes merged requirement fields, rejecting
+// unknown fields so typos in templates are caught as in files
+func decodeRequirementFields(fields map[string]interface{}, v interface{}) error {
+	data, err := yaml.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(data, v)
+}
+
+// readRequirements reads the requirements payload from the file, or stdin for -
+func readRequirements(cmd *cobra.Command, path string) ([]byte, error) {
+	if path == "-" {
+		data, err := io.ReadAll(bufio.NewReader(cmd.InOrStdin()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read requirements from stdin: %v", err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, validationErrorf("failed to read requirements file: %v", err)
+	}
+	return data, nil
+}
+
+// requirementsFormat picks the payload format from the file extension. Stdin
+// and unknown extensions are read as JSON if the payload looks like a JSON
+// object and as YAML otherwise.
+func requirementsFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return output.FormatJSON
+	case ".yaml", ".yml":
+		return output.FormatYAML
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return output.FormatJSON
+	}
+	return output.FormatYAML
+}
+
+// decodeRequirements decodes a JSON or YAML payload, rejecting unknown fields
+// when v is a struct
+func decodeRequirements(data []byte, format string, v interface{}) error {
+	if format == output.FormatYAML {
+		return yaml.UnmarshalStrict(data, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// readPlacement reads a placement saved as JSON or YAML. Unknown fields are
+// ignored, since the gateway may return fields this version doesn't know.
+func readPlacement(path string) (*api.Placement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, validationErrorf("failed to read placement file: %v", err)
+	}
+
+	var p api.Placement
+	if requirementsFormat(path, data) == output.FormatYAML {
+		err = yaml.Unmarshal(data, &p)
+	} else {
+		err = json.Unmarshal(data, &p)
+	}
+	if err != nil {
+		return nil, validationErrorf("invalid placement file %s: %v", path, err)
+	}
+	return &p, nil
+}
+
+func writePlacement(w io.Writer, p *api.Placement) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%s\n", p.ID)
+	fmt.Fprintf(tw, "Provider:\t%s\n", p.SelectedProvider)
+	fmt.Fprintf(tw, "Region:\t%s\n", p.SelectedRegion)
+	if p.InstanceType != "" {
+		fmt.Fprintf(tw, "Instance type:\t%s\n", p.InstanceType)
+	}
+	pricing := ""
+	if p.CustomPricing {
+		pricing = " (negotiated pricing)"
+	}
+	fmt.Fprintf(tw, "Monthly cost:\t%.2f%s\n", p.EstimatedMonthlyCost, pricing)
+	fmt.Fprintf(tw, "Total score:\t%.2f\n", p.TotalScore)
+	if p.AchievedSLATier != "" {
+		fmt.Fprintf(tw, "SLA tier:\t%s\n", p.AchievedSLATier)
+	}
+	if len(p.RegionAllocations) > 0 {
+		fmt.Fprintf(tw, "Aggregate monthly cost:\t%.2f\n", p.AggregateMonthlyCost)
+	}
+	if p.EstimatedSavings != nil {
+		if p.CurrentMonthlyCost != nil {
+			fmt.Fprintf(tw, "Current monthly cost:\t%.2f\n", *p.CurrentMonthlyCost)
+		}
+		fmt.Fprintf(tw, "Estimated savings:\t%s\n", formatSavings(p))
+	}
+	if p.PriceListDate != nil {
+		stale := ""
+		if p.PricesStale {
+			stale = " (stale)"
+		}
+		fmt.Fprintf(tw, "Prices as of:\t%s%s\n", formatDate(*p.PriceListDate), stale)
+	}
+	if p.SelectionReason != "" {
+		fmt.Fprintf(tw, "Selected because:\t%s\n", p.SelectionReason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(p.RegionAllocations) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "REGION\tPROVIDER\tINSTANCE TYPE\tWEIGHT\tMONTHLY COST")
+		for _, a := range p.RegionAllocations {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%.2f\t%.2f\n", a.Region, a.Provider, a.InstanceType, a.Weight, a.MonthlyCost)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(p.Recommendations) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ALTERNATIVE\tINSTANCE TYPE\tMONTHLY COST\tSCORE\tNOT CHOSEN BECAUSE")
+		for _, a := range p.Recommendations {
+			fmt.Fprintf(tw, "%s/%s\t%s\t%.2f\t%.2f\t%s\n", a.Provider, a.Region, a.InstanceType, a.MonthlyCost, a.TotalScore, a.RejectionReason)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warnStalePrices warns when the placement's cost estimate was made from
+// prices the gateway considers outdated
+func warnStalePrices(w io.Writer, p *api.Placement) {
+	if !p.PricesStale {
+		return
+	}
+	if p.PriceListDate != nil {
+		fmt.Fprintf(w, "Warning: the cost estimate uses prices last updated %s and may be outdated\n", formatDate(*p.PriceListDate))
+		return
+	}
+	fmt.Fprintln(w, "Warning: the cost estimate uses stale prices and may be outdated")
+}
+
+// warnCostIncrease warns when the placement costs more than the workload's
+// current monthly cost
+func warnCostIncrease(w io.Writer, p *api.Placement) {
+	if !p.CostIncrease || p.EstimatedSavings == nil {
+		return
+	}
+	fmt.Fprintf(w, "Warning: the placement costs %.2f more per month than the current monthly cost\n", -*p.EstimatedSavings)
+}
+
+// formatSavings formats the savings against the current monthly cost,
+// flagging a placement that costs more
+func formatSavings(p *api.Placement) string {
+	savings := fmt.Sprintf("%.2f", *p.EstimatedSavings)
+	if p.SavingsPercent != nil {
+		savings += fmt.Sprintf(" (%.1f%%)", *p.SavingsPercent)
+	}
+	if p.CostIncrease {
+		savings += " COST INCREASE"
+	}
+	return savings
+}
+
+func writePlacementHistory(w io.Writer, versions []api.PlacementVersion) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tRECORDED\tPROVIDER\tREGION\tINSTANCE TYPE\tMONTHLY COST\tDELTA\tSCORE")
+	for _, v := range versions {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%.2f\t%+.2f\t%.2f\n",
+			v.Version, v.RecordedAt.Format("2006-01-02 15:04"), v.SelectedProvider, v.SelectedRegion, v.InstanceType, v.EstimatedMonthlyCost, v.CostDelta, v.TotalScore)
+	}
+	return tw.Flush()
+}
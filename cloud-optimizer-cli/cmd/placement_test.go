@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/api"
+)
+
+func TestPlacementCreate(t *testing.T) {
+	budget := 300.0
+	want := api.ComputeRequirements{
+		Name:             "web",
+		VCPUs:            4,
+		MemoryGB:         16,
+		Regions:          []string{"eu-west-1", "eu-central-1"},
+		MaxMonthlyBudget: &budget,
+		Tags:             map[string]string{"team": "web"},
+	}
+
+	tests := []struct {
+		name  string
+		file  string
+		data  string
+		stdin bool
+	}{
+		{
+			name: "json",
+			file: "requirements.json",
+			data: `{"name":"web","vcpus":4,"memory_gb":16,"regions":["eu-west-1","eu-central-1"],"max_monthly_budget":300,"tags":{"team":"web"}}`,
+		},
+		{
+			name: "yaml",
+			file: "requirements.yml",
+			data: "name: web\nvcpus: 4\nmemory_gb: 16\nregions: [eu-west-1, eu-central-1]\nmax_monthly_budget: 300\ntags:\n  team: web\n",
+		},
+		{
+			name:  "yaml from stdin",
+			data:  "name: web\nvcpus: 4\nmemory_gb: 16\nregions: [eu-west-1, eu-central-1]\nmax_monthly_budget: 300\ntags:\n  team: web\n",
+			stdin: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "-"
+			if tt.stdin {
+				rootCmd.SetIn(strings.NewReader(tt.data))
+				t.Cleanup(func() { rootCmd.SetIn(nil) })
+			} else {
+				path = filepath.Join(t.TempDir(), tt.file)
+				if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var gotPath string
+			var got api.ComputeRequirements
+			out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"eu-west-1","estimated_monthly_cost":140}`))
+			}), "placement", "create", "--type", "compute", "-f", path)
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+
+			if gotPath != "/api/v1/placements/compute" {
+				t.Errorf("request to %s, want the compute placement", gotPath)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("requirements %+v, want %+v", got, want)
+			}
+			if !strings.Contains(out, "plc-1") || !strings.Contains(out, "140.00") {
+				t.Errorf("output missing the placement:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestPlacementCreateInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		args []string
+	}{
+		{name: "unknown field", file: "r.json", data: `{"name":"web","vcpus":4,"memory_gb":16,"cpus":4}`},
+		{name: "unknown yaml field", file: "r.yaml", data: "name: web\nvcpus: 4\nmemory_gb: 16\nmemory: 16\n"},
+		{name: "missing name", file: "r.json", data: `{"vcpus":4,"memory_gb":16}`},
+		{name: "unsupported type", file: "r.json", data: `{"name":"web","vcpus":4,"memory_gb":16}`, args: []string{"--type", "storage"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			args := append([]string{"placement", "create", "-f", path}, tt.args...)
+			_, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Errorf("invalid requirements sent to %s", r.URL.Path)
+			}), args...)
+			if code := ExitCode(err); code != ExitValidation {
+				t.Errorf("exit code %d (%v), want %d", code, err, ExitValidation)
+			}
+		})
+	}
+}
+
+func TestPlacementHistory(t *testing.T) {
+	var gotPath string
+	out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`[{"version":1,"selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":100,"cost_delta":0,"recorded_at":"2026-09-01T10:00:00Z"},` +
+			`{"version":2,"selected_provider":"gcp","selected_region":"us-east1","estimated_monthly_cost":80,"cost_delta":-20,"recorded_at":"2026-09-15T10:00:00Z"}]`))
+	}), "placement", "history", "plc-1")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+
+	if gotPath != "/api/v1/placements/compute/plc-1/history" {
+		t.Errorf("request to %s", gotPath)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "1 ") || !strings.HasPrefix(lines[2], "2 ") || !strings.Contains(lines[2], "-20.00") {
+		t.Errorf("output does not list the versions in order:\n%s", out)
+	}
+}
+
+// placement create merges the template, the file and the flags, each taking
+// precedence over the last
+func TestPlacementCreateTemplate(t *testing.T) {
+	useConfig(t, `templates:
+  base:
+    regions: [eu-west-1]
+    max_monthly_budget: 500
+    tags: {team: platform, env: dev}
+  prod-base:
+    extends: base
+    memory_gb: 8
+    tags: {env: prod}
+  loop:
+    extends: loop
+`)
+	file := filepath.Join(t.TempDir(), "r.yaml")
+	if err := os.WriteFile(file, []byte("name: web\nmemory_gb: 16\ntags: {owner: alice}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got api.ComputeRequirements
+	out, err := runCLIWithHome(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"plc-1"}`))
+	}), "placement", "create", "--template", "prod-base", "-f", file, "--name", "api", "--vcpus", "4", "--tags", "env=staging")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+
+	budget := 500.0
+	want := api.ComputeRequirements{
+		Name:             "api",
+		VCPUs:            4,
+		MemoryGB:         16,
+		Regions:          []string{"eu-west-1"},
+		MaxMonthlyBudget: &budget,
+		Tags:             map[string]string{"team": "platform", "env": "staging", "owner": "alice"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("requirements %+v, want %+v", got, want)
+	}
+
+	for _, template := range []string{"loop", "missing"} {
+		_, err := runCLIWithHome(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("template %s sent a request", template)
+		}), "placement", "create", "--template", template, "--name", "api", "--vcpus", "4", "--memory-gb", "8")
+		if code := ExitCode(err); code != ExitValidation {
+			t.Errorf("template %s: exit code %d (%v), want %d", template, code, err, ExitValidation)
+		}
+	}
+}
+
+// Text output explains the selection and why each alternative was not chosen
+func TestPlacementReasonsOutput(t *testing.T) {
+	rootCmd.SetIn(strings.NewReader(`{"name":"web","vcpus":2,"memory_gb":8}`))
+	t.Cleanup(func() { rootCmd.SetIn(nil) })
+	out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"eu-west-1","instance_type":"m5.large",
+			"selection_reason":"highest total score (0.85 vs 0.75 for the next best option); lowest cost",
+			"recommendations":[{"provider":"gcp","region":"europe-west1","instance_type":"n2-standard-2","monthly_cost":140,"total_score":0.75,"rejection_reason":"higher cost: 12.00 more per month"}]}`))
+	}), "placement", "create", "-f", "-")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	for _, want := range []string{
+		"Selected because:",
+		"lowest cost",
+		"NOT CHOSEN BECAUSE",
+		"gcp/europe-west1",
+		"higher cost: 12.00 more per month",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// --excluded-instance-types is sent with the requirements and an excluded
+// alternative is shown with its rejection reason
+func TestPlacementCreateExcludedInstanceTypes(t *testing.T) {
+	var got api.ComputeRequirements
+	out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"plc-1","selected_provider":"gcp","selected_region":"us-east1","instance_type":"n2-standard-2","estimated_monthly_cost":71,
+			"recommendations":[{"provider":"aws","region":"us-east-1","instance_type":"m5.large","monthly_cost":70,"total_score":0.8,"rejection_reason":"excluded instance type: m5.large"}]}`))
+	}), "placement", "create", "--name", "web", "--vcpus", "2", "--memory-gb", "8", "--excluded-instance-types", "m5.large,Standard_D2s_v5")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if want := []string{"m5.large", "Standard_D2s_v5"}; !reflect.DeepEqual(got.ExcludedInstanceTypes, want) {
+		t.Errorf("excluded instance types %v, want %v", got.ExcludedInstanceTypes, want)
+	}
+	if !strings.Contains(out, "excluded instance type: m5.large") {
+		t.Errorf("output missing the excluded alternative:\n%s", out)
+	}
+}
+
+// placement create warns when the gateway flags its prices as stale
+func TestPlacementCreateStalePrices(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		wantWarn  string
+		wantTable string
+	}{
+		{
+			name:      "fresh",
+			response:  `{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":70,"price_list_date":"2026-09-01T00:00:00Z"}`,
+			wantTable: "2026-09-01",
+		},
+		{
+			name:      "stale",
+			response:  `{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":70,"price_list_date":"2026-06-01T00:00:00Z","prices_stale":true}`,
+			wantWarn:  "Warning: the cost estimate uses prices last updated 2026-06-01 and may be outdated",
+			wantTable: "(stale)",
+		},
+		{
+			name:     "stale without a date",
+			response: `{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":70,"prices_stale":true}`,
+			wantWarn: "Warning: the cost estimate uses stale prices",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(tt.response))
+			}), "placement", "create", "--name", "web", "--vcpus", "2", "--memory-gb", "8")
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			if tt.wantWarn == "" && strings.Contains(out, "Warning") {
+				t.Errorf("fresh prices warned:\n%s", out)
+			}
+			if !strings.Contains(out, tt.wantWarn) || !strings.Contains(out, tt.wantTable) {
+				t.Errorf("output missing %q or %q:\n%s", tt.wantWarn, tt.wantTable, out)
+			}
+		})
+	}
+}
+
+// placement diff compares saved decisions in JSON or YAML, ignoring fields
+// it doesn't know
+func TestPlacementDiff(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"old.json": `{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","instance_type":"m5.large","estimated_monthly_cost":70,"added_later":true}`,
+		"new.yaml": "id: plc-1\nselected_provider: gcp\nselected_region: us-central1\ninstance_type: m5.large\nestimated_monthly_cost: 60\n",
+		"bad.json": `{"selected_provider":`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := runCLI(t, http.NotFoundHandler(), "placement", "diff", filepath.Join(dir, "old.json"), filepath.Join(dir, "new.yaml"))
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	want := "provider: aws -> gcp\nregion: us-east-1 -> us-central1\nmonthly cost: $70.00 -> $60.00 (-10.00)\n"
+	if out != want {
+		t.Errorf("output\n%s\nwant\n%s", out, want)
+	}
+
+	out, err = runCLI(t, http.NotFoundHandler(), "placement", "diff", filepath.Join(dir, "old.json"), filepath.Join(dir, "new.yaml"), "--output", "json")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	var diff api.PlacementDiff
+	if err := json.Unmarshal([]byte(out), &diff); err != nil {
+		t.Fatal(err)
+	}
+	if diff.Provider == nil || diff.Provider.New != "gcp" || diff.InstanceType != nil || diff.MonthlyCost == nil || diff.MonthlyCost.Delta != -10 {
+		t.Errorf("diff %+v, want the provider, region and cost changes", diff)
+	}
+
+	for _, name := range []string{"bad.json", "missing.json"} {
+		_, err := runCLI(t, http.NotFoundHandler(), "placement", "diff", filepath.Join(dir, "old.json"), filepath.Join(dir, name))
+		if ExitCode(err) != ExitValidation {
+			t.Errorf("%s: error %v, want a validation error", name, err)
+		}
+	}
+}
+
+// placement create sends the current monthly cost and shows the savings
+// against it, flagging a placement that costs more
+func TestPlacementCreateSavings(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		response string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "positive savings",
+			args:     []string{"--current-monthly-cost", "100"},
+			response: `"current_monthly_cost":100,"estimated_savings":30,"savings_percent":30`,
+			want:     []string{"Current monthly cost:", "100.00", "Estimated savings:", "30.00 (30.0%)"},
+			notWant:  []string{"COST INCREASE", "Warning"},
+		},
+		{
+			name:     "negative savings",
+			args:     []string{"--current-monthly-cost", "50"},
+			response: `"current_monthly_cost":50,"estimated_savings":-20,"savings_percent":-40,"cost_increase":true`,
+			want:     []string{"-20.00 (-40.0%) COST INCREASE", "Warning: the placement costs 20.00 more per month than the current monthly cost"},
+		},
+		{
+			name:    "no current cost",
+			notWant: []string{"Estimated savings", "Current monthly cost"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got api.ComputeRequirements
+			response := `{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","instance_type":"m5.large","estimated_monthly_cost":70`
+			if tt.response != "" {
+				response += "," + tt.response
+			}
+			out, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(response + "}"))
+			}), append([]string{"placement", "create", "--name", "web", "--vcpus", "2", "--memory-gb", "8"}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("%v: %s", err, out)
+			}
+			if sent := got.CurrentMonthlyCost != nil; sent != (len(tt.args) > 0) {
+				t.Errorf("current monthly cost sent as %v", got.CurrentMonthlyCost)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("output has %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+
+	_, err := runCLI(t, http.NotFoundHandler(), "placement", "create", "--name", "web", "--vcpus", "2", "--memory-gb", "8", "--current-monthly-cost", "-1")
+	if ExitCode(err) != ExitValidation {
+		t.Errorf("negative current cost: error %v, want a validation error", err)
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/output"
+)
+
+var (
+	providersOutput string
+	providersRegion string
+)
+
+// providersCmd represents the providers command
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Inspect the cloud providers known to the gateway",
+}
+
+var providersCapabilitiesCmd = &cobra.Command{
+	Use:   "capabilities <provider>",
+	Short: "Show the features, instance families and compliance of a provider",
+	Long: `Show what a provider supports before requiring it in a placement: its
+instance families and, per region, the available features (gpu, arm,
+confidential_computing, local_ssd), compliance frameworks and whether spot
+capacity is sold. For example:
+
+cloudopt providers capabilities aws
+cloudopt providers capabilities gcp --region europe-west1 --output json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(providersOutput); err != nil {
+			return asValidationError(err)
+		}
+
+		client, err := newAPIClient()
+		if err != nil {
+			return err
+		}
+
+		caps, err := client.ProviderCapabilities(cmd.Context(), args[0], providersRegion)
+		if err != nil {
+			return apiFailure("failed to fetch provider capabilities", err)
+		}
+
+		return writeOutput(cmd, providersOutput, caps, func(w io.Writer) error {
+			return writeProviderCapabilities(w, caps)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+	providersCmd.AddCommand(providersCapabilitiesCmd)
+
+	providersCmd.PersistentFlags().StringVar(&providersOutput, "output", output.FormatText, "output format (text, json, yaml)")
+	providersCapabilitiesCmd.Flags().StringVar(&providersRegion, "region", "", "show only this region")
+}
+
+// writeProviderCapabilities writes the instance families and a table of the
+// regions' capabilities
+func writeProviderCapabilities(w io.Writer, caps *api.ProviderCapabilities) error {
+	fmt.Fprintf(w, "Provider: %s\n", caps.Provider)
+	fmt.Fprintf(w, "Instance families: %s\n\n", strings.Join(caps.InstanceFamilies, ", "))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REGION\tSPOT\tFEATURES\tCOMPLIANCE")
+	for _, r := range caps.Regions {
+		spot := "no"
+		if r.SpotAvailable {
+			spot = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.Region, spot, listOrDash(r.Features), listOrDash(r.ComplianceFrameworks))
+	}
+	return tw.Flush()
+}
+
+// listOrDash joins the values with commas, or returns "-" when there are none
+func listOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
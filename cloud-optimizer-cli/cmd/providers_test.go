@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/api"
+)
+
+const capabilitiesResponse = `{"provider":"gcp","instance_families":["c2","e2","n2"],"regions":[
+	{"region":"europe-west1","features":["gpu","arm"],"compliance_frameworks":["SOC2","GDPR"],"spot_available":true}]}`
+
+func TestProvidersCapabilities(t *testing.T) {
+	var gotPath, gotRegion string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotRegion = r.URL.Path, r.URL.Query().Get("region")
+		w.Write([]byte(capabilitiesResponse))
+	})
+
+	out, err := runCLI(t, handler, "providers", "capabilities", "gcp", "--region", "europe-west1")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if gotPath != "/api/v1/providers/gcp/capabilities" || gotRegion != "europe-west1" {
+		t.Errorf("request to %s for region %q", gotPath, gotRegion)
+	}
+	for _, want := range []string{"Instance families: c2, e2, n2", "europe-west1", "yes", "gpu,arm", "SOC2,GDPR"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = runCLI(t, handler, "providers", "capabilities", "gcp", "--output", "json")
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	var caps api.ProviderCapabilities
+	if err := json.Unmarshal([]byte(out), &caps); err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	if gotRegion != "" || caps.Provider != "gcp" || len(caps.Regions) != 1 || !caps.Regions[0].SpotAvailable {
+		t.Errorf("capabilities %+v for region %q", caps, gotRegion)
+	}
+}
+
+func TestProvidersCapabilitiesNotFound(t *testing.T) {
+	_, err := runCLI(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"unknown region: eu-west-1"}`))
+	}), "providers", "capabilities", "gcp", "--region", "eu-west-1")
+	if ExitCode(err) != ExitNotFound {
+		t.Errorf("exit code %d (%v), want %d", ExitCode(err), err, ExitNotFound)
+	}
+}
@@ -11,6 +11,15 @@ import (
 var (
 	cfgFile string
 	verbose bool
+	noRetry bool
+	noPager bool
+	offline bool
+
+	apiEndpoint string
+	localeTag   string
+
+	outputFile   string
+	appendOutput bool
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -24,23 +33,44 @@ cloud resources across multiple providers. It provides capabilities for:
 - Resource placement recommendations
 - Performance analysis
 - Compliance checking
-- Migration planning`,
+- Migration planning
+
+Exit codes:
+  0  success
+  1  any other error
+  2  invalid flags, arguments or input
+  3  authentication or authorization failed
+  4  the requested resource does not exist
+  5  the gateway returned an error or could not be reached
+  6  analyze --alert-below found resources scoring below the threshold
+  7  analyze --alert-below could not score some resources, and every
+     resource scored is at or above the threshold`,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(ExitCode(err))
 	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return asValidationError(err)
+	})
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.cloudopt.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&outputFile, "output-file", "", "write results to this file instead of stdout")
+	rootCmd.PersistentFlags().BoolVar(&appendOutput, "append", false, "append to --output-file instead of truncating it (json is written as one record per line)")
+	rootCmd.PersistentFlags().StringVar(&apiEndpoint, "api-endpoint", "", "optimizer API url, overriding the regional endpoint selected from default_region")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "serve gateway reads from cached data without contacting the API")
+	rootCmd.PersistentFlags().StringVar(&localeTag, "locale", "", "format amounts and dates in text output for this locale (en-US, en-GB, de-DE, fr-FR or ja-JP), overriding preferences.locale")
+	rootCmd.PersistentFlags().BoolVar(&noRetry, "no-retry", false, "fail immediately on transient gateway errors instead of retrying")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "write long text output directly instead of through $PAGER or less")
 
 	// Environment variables
 	viper.SetEnvPrefix("CLOUDOPT")
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runCLI runs the command line against a test gateway serving handler, with
+// the default config in a temporary home directory, and returns its output.
+// Flags are reset afterwards so tests do not leak them into each other.
+func runCLI(t *testing.T, handler http.Handler, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	return runCLIWithHome(t, handler, args...)
+}
+
+// useConfig writes the config file of a temporary home directory, for
+// runCLIWithHome
+func useConfig(t *testing.T, data string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".cloudopt"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".cloudopt", "config.yaml"), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runCLIWithHome is runCLI with the home directory the test has set, for
+// tests that write their own config file
+func runCLIWithHome(t *testing.T, handler http.Handler, args ...string) (string, error) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	defer resetFlags(rootCmd)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(append(args, "--api-endpoint", srv.URL, "--no-retry", "--no-pager"))
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+// resetFlags restores every flag of cmd and its subcommands to its default
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			v.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/export"
+	"cloud-optimizer-cli/inventory"
+	"cloud-optimizer-cli/output"
+)
+
+// Formats of tf import output
+const (
+	tfImportCommands = "commands"
+	tfImportBlocks   = "blocks"
+)
+
+var (
+	tfImportFrom   string
+	tfImportFormat string
+)
+
+// tfCmd represents the tf command
+var tfCmd = &cobra.Command{
+	Use:   "tf",
+	Short: "Terraform helpers for the cloudoptimizer provider",
+}
+
+var tfImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Generate Terraform imports for inventoried resources",
+	Long: `Map each resource of an exported inventory to a cloudoptimizer placement
+resource address and write the terraform import commands, or import blocks for
+a bulk import, that bring them under management. On import the provider adopts
+each resource as a placement. Only compute resources can be imported; others
+are skipped with a warning. For example:
+
+cloudopt tf import --from inventory.json > import.sh
+cloudopt tf import --from inventory.json --format blocks --output-file imports.tf
+terraform plan -generate-config-out=placements.tf`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var write func(io.Writer, []export.ImportMapping) error
+		switch tfImportFormat {
+		case tfImportCommands:
+			write = export.ImportCommands
+		case tfImportBlocks:
+			write = export.ImportBlocks
+		default:
+			return validationErrorf("unsupported format %q (expected %s or %s)", tfImportFormat, tfImportCommands, tfImportBlocks)
+		}
+
+		inv, err := inventory.Load(tfImportFrom)
+		if err != nil {
+			return asValidationError(err)
+		}
+
+		mappings, skipped := export.ImportMappings(inv.Resources)
+		for _, s := range skipped {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Skipping %s: %s\n", s.ResourceID, s.Reason)
+		}
+		if len(mappings) == 0 {
+			return validationErrorf("no importable resources in %s", tfImportFrom)
+		}
+
+		return writeOutput(cmd, output.FormatText, mappings, func(w io.Writer) error {
+			return write(w, mappings)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tfCmd)
+	tfCmd.AddCommand(tfImportCmd)
+
+	tfImportCmd.Flags().StringVar(&tfImportFrom, "from", "", "inventory file written by cloudopt inventory export")
+	tfImportCmd.Flags().StringVar(&tfImportFormat, "format", tfImportCommands, "output format: commands (terraform import commands) or blocks (import blocks)")
+	tfImportCmd.MarkFlagRequired("from")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeInventory writes an inventory file of the given resources JSON
+func writeInventory(t *testing.T, resources string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	data := `{"schema_version":1,"exported_at":"2026-09-01T00:00:00Z","resources":` + resources + `}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTFImport(t *testing.T) {
+	path := writeInventory(t, `[
+		{"id":"i-1","name":"web","type":"instance","provider":"aws"},
+		{"id":"b-1","name":"logs","type":"bucket","provider":"aws"},
+		{"id":"i-2","name":"web","type":"vm","provider":"azure"}]`)
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{format: "commands", want: []string{
+			"terraform import cloudoptimizer_compute_placement.web 'i-1'\n",
+			"terraform import cloudoptimizer_compute_placement.web_2 'i-2'\n",
+		}},
+		{format: "blocks", want: []string{
+			"to = cloudoptimizer_compute_placement.web\n",
+			`id = "i-2"`,
+		}},
+	}
+	for _, tt := range tests {
+		out, err := runCLI(t, http.NotFoundHandler(), "tf", "import", "--from", path, "--format", tt.format)
+		if err != nil {
+			t.Fatalf("%s: %v: %s", tt.format, err, out)
+		}
+		for _, want := range append(tt.want, "Skipping b-1: storage placements cannot be imported") {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s output missing %q:\n%s", tt.format, want, out)
+			}
+		}
+	}
+}
+
+func TestTFImportInvalid(t *testing.T) {
+	storageOnly := writeInventory(t, `[{"id":"b-1","type":"bucket","provider":"aws"}]`)
+	valid := writeInventory(t, `[{"id":"i-1","type":"instance","provider":"aws"}]`)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "format", args: []string{"--from", valid, "--format", "hcl"}, want: "unsupported format"},
+		{name: "missing file", args: []string{"--from", filepath.Join(t.TempDir(), "none.json")}, want: "failed to open inventory file"},
+		{name: "nothing importable", args: []string{"--from", storageOnly}, want: "no importable resources"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := runCLI(t, http.NotFoundHandler(), append([]string{"tf", "import"}, tt.args...)...)
+			if ExitCode(err) != ExitValidation || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v (exit %d), want a validation error with %q: %s", err, ExitCode(err), tt.want, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"cloud-optimizer-cli/api"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// Markers flagging rows that changed since the previous analysis
+const (
+	markNew     = "+"
+	markChanged = "~"
+)
+
+// recommendationKey identifies a recommendation across analyses. The gateway
+// may not return an ID, so the type and description stand in for it.
+type recommendationKey struct {
+	resourceID string
+	id         string
+	typ        string
+	desc       string
+}
+
+func keyOf(resourceID string, rec api.Recommendation) recommendationKey {
+	if rec.ID != "" {
+		return recommendationKey{resourceID: resourceID, id: rec.ID}
+	}
+	return recommendationKey{resourceID: resourceID, typ: rec.Type, desc: rec.Description}
+}
+
+// resolvedRecommendation is a recommendation that is no longer reported
+type resolvedRecommendation struct {
+	ResourceID     string
+	Recommendation api.Recommendation
+}
+
+// analysisDiff is what changed between two analyses. A nil diff marks
+// nothing, so the tables are written without a marker column.
+type analysisDiff struct {
+	added    map[recommendationKey]bool
+	changed  map[recommendationKey]bool
+	scores   map[string]bool
+	resolved []resolvedRecommendation
+}
+
+// diffAnalysis compares the current analysis with the previous one. It
+// returns nil when there is no previous analysis to compare with.
+func diffAnalysis(prev, cur *AnalysisResults) *analysisDiff {
+	if prev == nil {
+		return nil
+	}
+
+	before := make(map[recommendationKey]api.Recommendation)
+	scores := make(map[string]*api.ScoredOption)
+	for _, r := range prev.Resources {
+		for _, rec := range r.Recommendations {
+			before[keyOf(r.ResourceID, rec)] = rec
+		}
+		scores[r.ResourceID] = r.Scores
+	}
+
+	d := &analysisDiff{
+		added:   make(map[recommendationKey]bool),
+		changed: make(map[recommendationKey]bool),
+		scores:  make(map[string]bool),
+	}
+	seen := make(map[recommendationKey]bool)
+	for _, r := range cur.Resources {
+		for _, rec := range r.Recommendations {
+			key := keyOf(r.ResourceID, rec)
+			seen[key] = true
+			old, ok := before[key]
+			switch {
+			case !ok:
+				d.added[key] = true
+			case old.Priority != rec.Priority || old.EstimatedSavings != rec.EstimatedSavings ||
+				old.Type != rec.Type || old.Description != rec.Description:
+				d.changed[key] = true
+			}
+		}
+		if r.Scores != nil && !sameScores(scores[r.ResourceID], r.Scores) {
+			d.scores[r.ResourceID] = true
+		}
+	}
+
+	// Resolved recommendations are listed in the previous analysis' order
+	for _, r := range prev.Resources {
+		for _, rec := range r.Recommendations {
+			if !seen[keyOf(r.ResourceID, rec)] {
+				d.resolved = append(d.resolved, resolvedRecommendation{ResourceID: r.ResourceID, Recommendation: rec})
+			}
+		}
+	}
+	return d
+}
+
+func sameScores(a, b *api.ScoredOption) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.TotalScore == b.TotalScore && a.PerformanceScore == b.PerformanceScore &&
+		a.ComplianceScore == b.ComplianceScore
+}
+
+// recommendation returns the marker of a recommendation row
+func (d *analysisDiff) recommendation(resourceID string, rec api.Recommendation) string {
+	if d == nil {
+		return ""
+	}
+	key := keyOf(resourceID, rec)
+	switch {
+	case d.added[key]:
+		return markNew
+	case d.changed[key]:
+		return markChanged
+	}
+	return ""
+}
+
+// score returns the marker of a resource's score row
+func (d *analysisDiff) score(resourceID string) string {
+	if d != nil && d.scores[resourceID] {
+		return markChanged
+	}
+	return ""
+}
+
+// prefix returns the marker column of a row, or nothing without a diff
+func (d *analysisDiff) prefix(mark string) string {
+	if d == nil {
+		return ""
+	}
+	if mark == "" {
+		mark = " "
+	}
+	return mark + "\t"
+}
+
+// empty reports whether nothing changed
+func (d *analysisDiff) empty() bool {
+	return len(d.added) == 0 && len(d.changed) == 0 && len(d.scores) == 0 && len(d.resolved) == 0
+}
+
+// writeResolved lists the recommendations resolved since the last analysis
+func writeResolved(w io.Writer, d *analysisDiff) error {
+	if d == nil || len(d.resolved) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "\nResolved since last run:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range d.resolved {
+		fmt.Fprintf(tw, "-\t%s\t%s\t%s\n", r.ResourceID, r.Recommendation.Type, r.Recommendation.Description)
+	}
+	return tw.Flush()
+}
+
+// watchAnalysis re-runs the analysis every interval, redrawing the terminal
+// with the latest results until interrupted
+func watchAnalysis(cmd *cobra.Command, analyzer *Analyzer, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The progress counter would scroll the redrawn screen
+	analyzer.progress = nil
+	out := cmd.OutOrStdout()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev *AnalysisResults
+	for {
+		results, err := analyzer.Analyze(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var buf bytes.Buffer
+		fmt.Fprint(&buf, clearScreen)
+		fmt.Fprintf(&buf, "Every %s: analyzing %s resources (Ctrl-C to exit)    %s\n\n",
+			interval, analyzer.Provider, time.Now().Format("2006-01-02 15:04:05"))
+		if err != nil {
+			// Keep watching; the previous results stay the baseline for the next run
+			fmt.Fprintf(&buf, "analysis failed: %v\n", err)
+		} else {
+			if err := writeWatchFrame(&buf, prev, results, analyzer.Scores); err != nil {
+				return err
+			}
+			prev = results
+		}
+		if _, err := out.Write(buf.Bytes()); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeWatchFrame writes one redraw of the watched analysis, highlighting the
+// changes since prev
+func writeWatchFrame(w io.Writer, prev, results *AnalysisResults, scores bool) error {
+	diff := diffAnalysis(prev, results)
+	if diff != nil {
+		if diff.empty() {
+			fmt.Fprintln(w, "No changes since last run")
+		} else {
+			fmt.Fprintf(w, "Changes since last run: %d new, %d changed, %d resolved, %d scores changed (%s new, %s changed)\n",
+				len(diff.added), len(diff.changed), len(diff.resolved), len(diff.scores), markNew, markChanged)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if err := writeAnalysis(w, results, diff); err != nil {
+		return err
+	}
+	if err := writeResolved(w, diff); err != nil {
+		return err
+	}
+
+	// Alerts are shown instead of ending the watch
+	if !scores {
+		return nil
+	}
+	var alerts bytes.Buffer
+	if err := checkScoreThreshold(&alerts, results, scoreMetric, alertBelow); err != nil {
+		fmt.Fprintf(w, "\n%s%v\n", alerts.String(), err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/api"
+)
+
+func TestDiffAnalysis(t *testing.T) {
+	prev := &AnalysisResults{Resources: []ResourceAnalysis{
+		{
+			ResourceID: "i-1",
+			Recommendations: []api.Recommendation{
+				{ID: "rec-1", Type: "cost", Priority: "high", EstimatedSavings: 100, Description: "resize"},
+				{ID: "rec-2", Type: "cost", Priority: "low", EstimatedSavings: 10, Description: "tag"},
+				{Type: "performance", Priority: "medium", Description: "move region"},
+			},
+			Scores: &api.ScoredOption{TotalScore: 0.8, PerformanceScore: 0.7, ComplianceScore: 1},
+		},
+		{
+			ResourceID: "i-2",
+			Recommendations: []api.Recommendation{
+				{ID: "rec-3", Type: "compliance", Priority: "high", Description: "encrypt"},
+			},
+			Scores: &api.ScoredOption{TotalScore: 0.5},
+		},
+	}}
+	cur := &AnalysisResults{Resources: []ResourceAnalysis{
+		{
+			ResourceID: "i-1",
+			Recommendations: []api.Recommendation{
+				{ID: "rec-1", Type: "cost", Priority: "high", EstimatedSavings: 120, Description: "resize"},
+				{ID: "rec-2", Type: "cost", Priority: "low", EstimatedSavings: 10, Description: "tag"},
+				{Type: "performance", Priority: "medium", Description: "move region"},
+				{ID: "rec-4", Type: "cost", Priority: "medium", Description: "reserve"},
+			},
+			Scores: &api.ScoredOption{TotalScore: 0.8, PerformanceScore: 0.7, ComplianceScore: 1},
+		},
+		{
+			ResourceID: "i-2",
+			Scores:     &api.ScoredOption{TotalScore: 0.6},
+		},
+	}}
+
+	if d := diffAnalysis(nil, cur); d != nil {
+		t.Fatalf("diff without a previous analysis = %+v, want nil", d)
+	}
+	if d := diffAnalysis(cur, cur); !d.empty() {
+		t.Errorf("diff of an analysis with itself = %+v, want it empty", d)
+	}
+
+	d := diffAnalysis(prev, cur)
+	marks := map[string]string{}
+	for _, rec := range cur.Resources[0].Recommendations {
+		marks[rec.Description] = d.recommendation("i-1", rec)
+	}
+	want := map[string]string{"resize": markChanged, "tag": "", "move region": "", "reserve": markNew}
+	for desc, mark := range want {
+		if marks[desc] != mark {
+			t.Errorf("%s marked %q, want %q", desc, marks[desc], mark)
+		}
+	}
+	if d.score("i-1") != "" || d.score("i-2") != markChanged {
+		t.Errorf("score marks %q and %q, want only i-2's changed", d.score("i-1"), d.score("i-2"))
+	}
+	if len(d.resolved) != 1 || d.resolved[0].ResourceID != "i-2" || d.resolved[0].Recommendation.ID != "rec-3" {
+		t.Errorf("resolved %+v, want rec-3 of i-2", d.resolved)
+	}
+
+	// A recommendation that moves to another resource is a different one
+	moved := &AnalysisResults{Resources: []ResourceAnalysis{{ResourceID: "i-2", Recommendations: prev.Resources[0].Recommendations[:1]}}}
+	d = diffAnalysis(&AnalysisResults{Resources: prev.Resources[:1]}, moved)
+	if d.recommendation("i-2", moved.Resources[0].Recommendations[0]) != markNew || len(d.resolved) != 3 {
+		t.Errorf("moved recommendation diff %+v, want it new and the old ones resolved", d)
+	}
+}
+
+func TestWriteWatchFrame(t *testing.T) {
+	prev := &AnalysisResults{Resources: []ResourceAnalysis{{
+		ResourceID:      "i-1",
+		Recommendations: []api.Recommendation{{ID: "rec-1", Type: "cost", Priority: "high", Description: "resize"}},
+	}}}
+	cur := &AnalysisResults{Resources: []ResourceAnalysis{{
+		ResourceID:      "i-1",
+		Recommendations: []api.Recommendation{{ID: "rec-2", Type: "cost", Priority: "low", Description: "tag"}},
+	}}}
+
+	tests := []struct {
+		name    string
+		prev    *AnalysisResults
+		want    []string
+		notWant []string
+	}{
+		{name: "first run", want: []string{"RESOURCE", "tag"}, notWant: []string{"since last run", "+"}},
+		{name: "unchanged", prev: cur, want: []string{"No changes since last run", "  i-1"}},
+		{
+			name: "changed",
+			prev: prev,
+			want: []string{"1 new, 0 changed, 1 resolved", "+  i-1", "Resolved since last run:", "-  i-1  cost  resize"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeWatchFrame(&buf, tt.prev, cur, false); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("frame missing %q:\n%s", want, out)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out, notWant) {
+					t.Errorf("frame contains %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
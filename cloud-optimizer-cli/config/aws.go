@@ -0,0 +1,375 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSessionName is used when assume_role has no session_name
+	defaultSessionName = "cloudopt"
+	// assumeRoleDuration is how long assumed credentials are requested for
+	assumeRoleDuration = time.Hour
+	// defaultRefreshWindow is how long before expiry assumed credentials are
+	// refreshed, so an operation never starts with credentials about to lapse
+	defaultRefreshWindow = 5 * time.Minute
+)
+
+var sessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+// Validate checks the role to assume
+func (r *AssumeRoleConfig) Validate() error {
+	if !strings.HasPrefix(r.RoleARN, "arn:") || !strings.Contains(r.RoleARN, ":role/") {
+		return fmt.Errorf("invalid credentials.aws.assume_role.role_arn: %q", r.RoleARN)
+	}
+	if r.SessionName != "" && !sessionNamePattern.MatchString(r.SessionName) {
+		return fmt.Errorf("invalid credentials.aws.assume_role.session_name: %q (2-64 letters, digits or +=,.@_-)", r.SessionName)
+	}
+	if r.ExternalID != "" && (len(r.ExternalID) < 2 || len(r.ExternalID) > 1224) {
+		return fmt.Errorf("invalid credentials.aws.assume_role.external_id: must be 2-1224 characters")
+	}
+	return nil
+}
+
+// AWSCredentials are resolved credentials to sign AWS requests with.
+// Expires is zero for long-lived credentials.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// expiresWithin reports whether the credentials expire within d of now
+func (c *AWSCredentials) expiresWithin(now time.Time, d time.Duration) bool {
+	return !c.Expires.IsZero() && !now.Add(d).Before(c.Expires)
+}
+
+// AssumeRoleInput is a request to assume a role
+type AssumeRoleInput struct {
+	RoleARN     string
+	ExternalID  string
+	SessionName string
+	Duration    time.Duration
+}
+
+// STSClient assumes roles on behalf of a set of base credentials
+type STSClient interface {
+	AssumeRole(ctx context.Context, base AWSCredentials, in AssumeRoleInput) (*AWSCredentials, error)
+}
+
+// AWSCredentialProvider resolves the credentials AWS operations are signed
+// with. With assume_role configured it assumes the role and caches the
+// result, assuming it again shortly before it expires.
+type AWSCredentialProvider struct {
+	creds         AWSCreds
+	sts           STSClient
+	refreshWindow time.Duration
+	now           func() time.Time
+
+	mu     sync.Mutex
+	base   *AWSCredentials
+	cached *AWSCredentials
+}
+
+// AWSProviderOption configures an AWSCredentialProvider
+type AWSProviderOption func(*AWSCredentialProvider)
+
+// WithSTSClient assumes roles with client instead of calling the STS API
+func WithSTSClient(client STSClient) AWSProviderOption {
+	return func(p *AWSCredentialProvider) {
+		p.sts = client
+	}
+}
+
+// WithRefreshWindow refreshes assumed credentials when they expire within d
+func WithRefreshWindow(d time.Duration) AWSProviderOption {
+	return func(p *AWSCredentialProvider) {
+		p.refreshWindow = d
+	}
+}
+
+// NewAWSCredentialProvider creates a provider for the configured credentials
+func NewAWSCredentialProvider(creds AWSCreds, opts ...AWSProviderOption) *AWSCredentialProvider {
+	p := &AWSCredentialProvider{
+		creds:         creds,
+		refreshWindow: defaultRefreshWindow,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.sts == nil {
+		p.sts = NewSTSClient(creds.Region)
+	}
+	return p
+}
+
+// Credentials returns credentials for an AWS operation, assuming the
+// configured role if the cached credentials are missing or about to expire
+func (p *AWSCredentialProvider) Credentials(ctx context.Context) (*AWSCredentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.base == nil {
+		base, err := p.creds.baseCredentials()
+		if err != nil {
+			return nil, err
+		}
+		p.base = base
+	}
+
+	role := p.creds.AssumeRole
+	if role == nil {
+		return p.base, nil
+	}
+	if p.cached != nil && !p.cached.expiresWithin(p.now(), p.refreshWindow) {
+		return p.cached, nil
+	}
+
+	sessionName := role.SessionName
+	if sessionName == "" {
+		sessionName = defaultSessionName
+	}
+	assumed, err := p.sts.AssumeRole(ctx, *p.base, AssumeRoleInput{
+		RoleARN:     role.RoleARN,
+		ExternalID:  role.ExternalID,
+		SessionName: sessionName,
+		Duration:    assumeRoleDuration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %v", role.RoleARN, err)
+	}
+	p.cached = assumed
+	return assumed, nil
+}
+
+// baseCredentials returns the configured access keys, or those of the
+// configured profile in the shared credentials file
+func (c AWSCreds) baseCredentials() (*AWSCredentials, error) {
+	if c.AccessKeyID != "" && c.SecretAccessKey != "" {
+		return &AWSCredentials{AccessKeyID: c.AccessKeyID, SecretAccessKey: c.SecretAccessKey}, nil
+	}
+	if c.Profile == "" {
+		return nil, fmt.Errorf("AWS credentials not configured")
+	}
+	return profileCredentials(c.Profile)
+}
+
+// profileCredentials reads a profile's keys from the shared credentials file
+func profileCredentials(profile string) (*AWSCredentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %v", err)
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AWS credentials file: %v", err)
+	}
+	defer f.Close()
+
+	var (
+		creds   AWSCredentials
+		section string
+		found   bool
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			found = found || section == profile
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != profile {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read AWS credentials file: %v", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("AWS profile %q not found in %s", profile, path)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS profile %q has no access keys", profile)
+	}
+	return &creds, nil
+}
+
+// httpSTSClient calls the STS query API, signing requests with Signature
+// Version 4
+type httpSTSClient struct {
+	endpoint   string
+	region     string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewSTSClient returns an STS client for the region's endpoint, or the
+// global endpoint if region is empty
+func NewSTSClient(region string) STSClient {
+	c := &httpSTSClient{
+		endpoint:   "https://sts.amazonaws.com/",
+		region:     "us-east-1",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}
+	if region != "" {
+		c.endpoint = fmt.Sprintf("https://sts.%s.amazonaws.com/", region)
+		c.region = region
+	}
+	return c
+}
+
+type assumeRoleResponse struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"AssumeRoleResult>Credentials"`
+}
+
+type stsErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+func (c *httpSTSClient) AssumeRole(ctx context.Context, base AWSCredentials, in AssumeRoleInput) (*AWSCredentials, error) {
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {"2011-06-15"},
+		"RoleArn":         {in.RoleARN},
+		"RoleSessionName": {in.SessionName},
+		"DurationSeconds": {fmt.Sprint(int(in.Duration.Seconds()))},
+	}
+	if in.ExternalID != "" {
+		form.Set("ExternalId", in.ExternalID)
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	c.sign(req, base, body)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("STS request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read STS response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e stsErrorResponse
+		if xml.Unmarshal(data, &e) == nil && e.Code != "" {
+			return nil, fmt.Errorf("%s: %s", e.Code, e.Message)
+		}
+		return nil, fmt.Errorf("STS returned status %d", resp.StatusCode)
+	}
+
+	var out assumeRoleResponse
+	if err := xml.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode STS response: %v", err)
+	}
+	return &AWSCredentials{
+		AccessKeyID:     out.Credentials.AccessKeyID,
+		SecretAccessKey: out.Credentials.SecretAccessKey,
+		SessionToken:    out.Credentials.SessionToken,
+		Expires:         out.Credentials.Expiration,
+	}, nil
+}
+
+// sign adds a Signature Version 4 Authorization header to the request
+func (c *httpSTSClient) sign(req *http.Request, creds AWSCredentials, body string) {
+	SignAWSRequest(req, creds, c.region, "sts", body, c.now())
+}
+
+// SignAWSRequest adds a Signature Version 4 Authorization header to a request
+// to an AWS query or JSON API, which are served at the root path without a
+// query string. The Content-Type header must already be set.
+func SignAWSRequest(req *http.Request, creds AWSCredentials, region, service, body string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	signed := []string{"content-type", "host", "x-amz-date"}
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+		signed = append(signed, "x-amz-security-token")
+	}
+
+	var headers strings.Builder
+	for _, h := range signed {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		fmt.Fprintf(&headers, "%s:%s\n", h, strings.TrimSpace(value))
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonical := strings.Join([]string{
+		req.Method, "/", "", headers.String(), signedHeaders, sha256Hex(body),
+	}, "\n")
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonical)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	for _, part := range []string{region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssumeRoleConfigValidate(t *testing.T) {
+	arn := "arn:aws:iam::123456789012:role/cloudopt"
+	tests := []struct {
+		name    string
+		role    AssumeRoleConfig
+		wantErr string
+	}{
+		{name: "role", role: AssumeRoleConfig{RoleARN: arn}},
+		{name: "every field", role: AssumeRoleConfig{RoleARN: arn, ExternalID: "ext-123", SessionName: "ops@example.com"}},
+		{name: "not an ARN", role: AssumeRoleConfig{RoleARN: "cloudopt"}, wantErr: "role_arn"},
+		{name: "user ARN", role: AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:user/ops"}, wantErr: "role_arn"},
+		{name: "invalid session name", role: AssumeRoleConfig{RoleARN: arn, SessionName: "has space"}, wantErr: "session_name"},
+		{name: "short external ID", role: AssumeRoleConfig{RoleARN: arn, ExternalID: "x"}, wantErr: "external_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.role.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error about %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeSTS issues numbered credentials valid for an hour of the test's clock
+type fakeSTS struct {
+	now   func() time.Time
+	err   error
+	calls int
+	base  AWSCredentials
+	in    AssumeRoleInput
+}
+
+func (s *fakeSTS) AssumeRole(ctx context.Context, base AWSCredentials, in AssumeRoleInput) (*AWSCredentials, error) {
+	s.calls++
+	s.base, s.in = base, in
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &AWSCredentials{
+		AccessKeyID:     fmt.Sprintf("ASIA%d", s.calls),
+		SecretAccessKey: "secret",
+		SessionToken:    fmt.Sprintf("token-%d", s.calls),
+		Expires:         s.now().Add(in.Duration),
+	}, nil
+}
+
+// The assumed credentials are used until they are about to expire and then
+// assumed again
+func TestAWSCredentialProviderAssumeRole(t *testing.T) {
+	now := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	sts := &fakeSTS{now: clock}
+	creds := AWSCreds{
+		AccessKeyID:     "AKIAHUB",
+		SecretAccessKey: "hub-secret",
+		AssumeRole:      &AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/cloudopt", ExternalID: "ext-123"},
+	}
+	p := NewAWSCredentialProvider(creds, WithSTSClient(sts), WithRefreshWindow(5*time.Minute))
+	p.now = clock
+
+	got, err := p.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AccessKeyID != "ASIA1" || got.SessionToken != "token-1" {
+		t.Errorf("credentials %+v, want the assumed ones", got)
+	}
+	if sts.base.AccessKeyID != "AKIAHUB" {
+		t.Errorf("role assumed with %s, want the hub account's keys", sts.base.AccessKeyID)
+	}
+	want := AssumeRoleInput{RoleARN: creds.AssumeRole.RoleARN, ExternalID: "ext-123", SessionName: defaultSessionName, Duration: assumeRoleDuration}
+	if sts.in != want {
+		t.Errorf("assumed %+v, want %+v", sts.in, want)
+	}
+
+	now = now.Add(50 * time.Minute)
+	if got, err = p.Credentials(context.Background()); err != nil || got.AccessKeyID != "ASIA1" || sts.calls != 1 {
+		t.Errorf("credentials %+v (%v) after %d assumptions, want the cached ones", got, err, sts.calls)
+	}
+
+	now = now.Add(6 * time.Minute)
+	if got, err = p.Credentials(context.Background()); err != nil || got.AccessKeyID != "ASIA2" || sts.calls != 2 {
+		t.Errorf("credentials %+v (%v) after %d assumptions, want them refreshed near expiry", got, err, sts.calls)
+	}
+}
+
+func TestAWSCredentialProviderErrors(t *testing.T) {
+	sts := &fakeSTS{now: time.Now, err: errors.New("AccessDenied: not authorized")}
+
+	p := NewAWSCredentialProvider(AWSCreds{AccessKeyID: "AKIA", SecretAccessKey: "secret"}, WithSTSClient(sts))
+	got, err := p.Credentials(context.Background())
+	if err != nil || got.AccessKeyID != "AKIA" || sts.calls != 0 {
+		t.Errorf("without assume_role got %+v (%v) after %d assumptions, want the base keys", got, err, sts.calls)
+	}
+
+	role := &AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/cloudopt"}
+	p = NewAWSCredentialProvider(AWSCreds{AccessKeyID: "AKIA", SecretAccessKey: "secret", AssumeRole: role}, WithSTSClient(sts))
+	if _, err := p.Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), "failed to assume role arn:aws:iam::123456789012:role/cloudopt: AccessDenied") {
+		t.Errorf("error %v, want the STS error", err)
+	}
+
+	p = NewAWSCredentialProvider(AWSCreds{AssumeRole: role}, WithSTSClient(sts))
+	if _, err := p.Credentials(context.Background()); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("error %v, want missing credentials", err)
+	}
+}
+
+func TestProfileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	data := `# shared credentials
+[default]
+aws_access_key_id = AKIADEFAULT
+aws_secret_access_key = default-secret
+
+[hub]
+aws_access_key_id=AKIAHUB
+aws_secret_access_key=hub-secret
+aws_session_token=hub-token
+
+[empty]
+region = us-east-1
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+
+	got, err := AWSCreds{Profile: "hub"}.baseCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != (AWSCredentials{AccessKeyID: "AKIAHUB", SecretAccessKey: "hub-secret", SessionToken: "hub-token"}) {
+		t.Errorf("hub profile %+v", got)
+	}
+
+	for profile, want := range map[string]string{"missing": "not found", "empty": "no access keys"} {
+		if _, err := profileCredentials(profile); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("profile %s: error %v, want %q", profile, err, want)
+		}
+	}
+}
+
+// The STS client sends a signed AssumeRole request and decodes the assumed
+// credentials or the STS error
+func TestSTSClientAssumeRole(t *testing.T) {
+	var form map[string]string
+	var auth, token string
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		form = make(map[string]string)
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		auth, token = r.Header.Get("Authorization"), r.Header.Get("X-Amz-Security-Token")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`<ErrorResponse><Error><Code>AccessDenied</Code><Message>not authorized</Message></Error></ErrorResponse>`))
+			return
+		}
+		w.Write([]byte(`<AssumeRoleResponse><AssumeRoleResult><Credentials>
+<AccessKeyId>ASIAMEMBER</AccessKeyId><SecretAccessKey>member-secret</SecretAccessKey>
+<SessionToken>member-token</SessionToken><Expiration>2026-09-01T13:00:00Z</Expiration>
+</Credentials></AssumeRoleResult></AssumeRoleResponse>`))
+	}))
+	defer srv.Close()
+
+	c := NewSTSClient("eu-west-1").(*httpSTSClient)
+	c.endpoint = srv.URL + "/"
+	base := AWSCredentials{AccessKeyID: "AKIAHUB", SecretAccessKey: "hub-secret", SessionToken: "hub-token"}
+	in := AssumeRoleInput{RoleARN: "arn:aws:iam::123456789012:role/cloudopt", ExternalID: "ext-123", SessionName: "cloudopt", Duration: time.Hour}
+
+	got, err := c.AssumeRole(context.Background(), base, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := AWSCredentials{AccessKeyID: "ASIAMEMBER", SecretAccessKey: "member-secret", SessionToken: "member-token", Expires: time.Date(2026, 9, 1, 13, 0, 0, 0, time.UTC)}
+	if *got != want {
+		t.Errorf("credentials %+v, want %+v", got, want)
+	}
+	if form["Action"] != "AssumeRole" || form["RoleArn"] != in.RoleARN || form["ExternalId"] != "ext-123" || form["DurationSeconds"] != "3600" {
+		t.Errorf("request form %v", form)
+	}
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIAHUB/") || !strings.Contains(auth, "/eu-west-1/sts/aws4_request") || token != "hub-token" {
+		t.Errorf("request signed with %q and token %q, want the base credentials", auth, token)
+	}
+
+	status = http.StatusForbidden
+	if _, err := c.AssumeRole(context.Background(), base, in); err == nil || err.Error() != "AccessDenied: not authorized" {
+		t.Errorf("error %v, want the STS error", err)
+	}
+}
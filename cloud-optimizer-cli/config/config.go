@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
@@ -11,12 +13,28 @@ import (
 
 // Config represents the CLI configuration
 type Config struct {
-	DefaultProvider string            `yaml:"default_provider"`
-	DefaultRegion   string            `yaml:"default_region"`
-	Credentials     ProviderCreds     `yaml:"credentials"`
-	OutputFormat    string            `yaml:"output_format"`
-	Preferences     UserPreferences   `yaml:"preferences"`
-	APIEndpoints    map[string]string `yaml:"api_endpoints"`
+	DefaultProvider string                 `yaml:"default_provider"`
+	DefaultRegion   string                 `yaml:"default_region"`
+	Credentials     ProviderCreds          `yaml:"credentials"`
+	OutputFormat    string                 `yaml:"output_format"`
+	Preferences     UserPreferences        `yaml:"preferences"`
+	APIEndpoints    map[string]APIEndpoint `yaml:"api_endpoints"`
+	APIToken        string                 `yaml:"api_token,omitempty"`
+	// CacheDir holds every on-disk cache; it defaults to the cache
+	// subdirectory of the config directory
+	CacheDir string `yaml:"cache_dir,omitempty"`
+	// Templates are named base requirements for placement create --template
+	Templates map[string]RequirementsTemplate `yaml:"templates,omitempty"`
+}
+
+// APIEndpoint is a service base URL with an optional pinned API version. It
+// may be written in config either as a plain URL string or as a mapping with
+// url and version keys. Regions maps a region to a regional endpoint of the
+// same service, used instead of URL when that region is selected.
+type APIEndpoint struct {
+	URL     string                 `yaml:"url"`
+	Version string                 `yaml:"version,omitempty"`
+	Regions map[string]APIEndpoint `yaml:"regions,omitempty"`
 }
 
 // ProviderCreds holds cloud provider credentials
@@ -32,6 +50,16 @@ type AWSCreds struct {
 	SecretAccessKey string `yaml:"secret_access_key"`
 	Region          string `yaml:"region"`
 	Profile         string `yaml:"profile"`
+	// AssumeRole, if set, is assumed with STS using the credentials above
+	// before any AWS operation
+	AssumeRole *AssumeRoleConfig `yaml:"assume_role,omitempty"`
+}
+
+// AssumeRoleConfig selects a role, typically in a member account, to assume
+type AssumeRoleConfig struct {
+	RoleARN     string `yaml:"role_arn"`
+	ExternalID  string `yaml:"external_id,omitempty"`
+	SessionName string `yaml:"session_name,omitempty"`
 }
 
 // AzureCreds holds Azure credentials
@@ -54,6 +82,57 @@ type UserPreferences struct {
 	CostThreshold  float64  `yaml:"cost_threshold"`
 	NotifyEmail    string   `yaml:"notify_email"`
 	ExcludeRegions []string `yaml:"exclude_regions"`
+	// ExcludeProviders, like ExcludeRegions, is excluded from every placement
+	// and analysis request unless --ignore-org-defaults is set
+	ExcludeProviders []string `yaml:"exclude_providers,omitempty"`
+	// DefaultComplianceFrameworks are required by every placement and analysis
+	// request, alongside any the request names, unless --ignore-org-defaults
+	// is set
+	DefaultComplianceFrameworks []string `yaml:"default_compliance_frameworks,omitempty"`
+	// RetryAttempts is how many times a failed gateway call is retried; 0 disables retries
+	RetryAttempts int `yaml:"retry_attempts"`
+	// RetryBackoff is the initial delay between retries as a duration (e.g. 500ms), doubled after each retry
+	RetryBackoff string `yaml:"retry_backoff"`
+	// Locale formats amounts and dates in text output (e.g. de-DE); when
+	// empty they keep the plain "1234.56 USD" and 2006-01-02 forms
+	Locale string `yaml:"locale,omitempty"`
+	// TokenRefreshWindow is how long before its expiry the API token is
+	// refreshed, as a duration (e.g. 10m); it defaults to 5m and 0 disables
+	// refreshing
+	TokenRefreshWindow string `yaml:"token_refresh_window,omitempty"`
+	// Pager is the command long text output on a terminal is paged through,
+	// defaulting to $PAGER or less; NoPager disables paging
+	Pager   string `yaml:"pager,omitempty"`
+	NoPager bool   `yaml:"no_pager,omitempty"`
+}
+
+// RetryBackoffDuration parses the configured retry backoff
+func (p UserPreferences) RetryBackoffDuration() (time.Duration, error) {
+	if p.RetryBackoff == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(p.RetryBackoff)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid preferences.retry_backoff: %s", p.RetryBackoff)
+	}
+	return d, nil
+}
+
+// defaultTokenRefreshWindow is used when token_refresh_window is not set
+const defaultTokenRefreshWindow = 5 * time.Minute
+
+// TokenRefreshWindowDuration parses the configured token refresh window
+func (p UserPreferences) TokenRefreshWindowDuration() (time.Duration, error) {
+	if p.TokenRefreshWindow == "" {
+		return defaultTokenRefreshWindow, nil
+	}
+
+	d, err := time.ParseDuration(p.TokenRefreshWindow)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid preferences.token_refresh_window: %s", p.TokenRefreshWindow)
+	}
+	return d, nil
 }
 
 // DefaultConfig returns a default configuration
@@ -65,10 +144,12 @@ func DefaultConfig() *Config {
 		Preferences: UserPreferences{
 			AutoConfirm:   false,
 			CostThreshold: 100.0,
+			RetryAttempts: 3,
+			RetryBackoff:  "500ms",
 		},
-		APIEndpoints: map[string]string{
-			"optimizer": "http://localhost:8080",
-			"analyzer":  "http://localhost:8081",
+		APIEndpoints: map[string]APIEndpoint{
+			"optimizer": {URL: "http://localhost:8080"},
+			"analyzer":  {URL: "http://localhost:8081"},
 		},
 	}
 }
@@ -90,7 +171,8 @@ func LoadConfig() (*Config, error) {
 		return config, nil
 	}
 
-	var config Config
+	// Preferences missing from older config files keep their defaults
+	config := Config{Preferences: DefaultConfig().Preferences}
 	data, err := os.ReadFile(configFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %v", err)
@@ -136,6 +218,15 @@ func (c *Config) Update() error {
 	if region := os.Getenv("CLOUDOPT_REGION"); region != "" {
 		c.DefaultRegion = region
 	}
+	if token := os.Getenv("CLOUDOPT_API_TOKEN"); token != "" {
+		c.APIToken = token
+	}
+	if dir := os.Getenv("CLOUDOPT_CACHE_DIR"); dir != "" {
+		c.CacheDir = dir
+	}
+	if locale := os.Getenv("CLOUDOPT_LOCALE"); locale != "" {
+		c.Preferences.Locale = locale
+	}
 
 	// Update from viper (flags)
 	if provider := viper.GetString("provider"); provider != "" {
@@ -169,6 +260,13 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid output format: %s", c.OutputFormat)
 	}
 
+	if c.Preferences.RetryAttempts < 0 {
+		return fmt.Errorf("invalid preferences.retry_attempts: %d", c.Preferences.RetryAttempts)
+	}
+	if _, err := c.Preferences.RetryBackoffDuration(); err != nil {
+		return err
+	}
+
 	// Validate credentials based on provider
 	switch c.DefaultProvider {
 	case "aws":
@@ -193,6 +291,9 @@ func (c *Config) validateAWSCreds() error {
 	if creds.Profile == "" && (creds.AccessKeyID == "" || creds.SecretAccessKey == "") {
 		return fmt.Errorf("AWS credentials not configured")
 	}
+	if creds.AssumeRole != nil {
+		return creds.AssumeRole.Validate()
+	}
 	return nil
 }
 
@@ -213,6 +314,121 @@ func (c *Config) validateGCPCreds() error {
 	return nil
 }
 
+// UnmarshalYAML accepts both the plain URL form and the url/version mapping
+func (e *APIEndpoint) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var url string
+	if err := unmarshal(&url); err == nil {
+		e.URL = url
+		e.Version = ""
+		e.Regions = nil
+		return nil
+	}
+
+	type plain APIEndpoint
+	var endpoint plain
+	if err := unmarshal(&endpoint); err != nil {
+		return err
+	}
+	*e = APIEndpoint(endpoint)
+	return nil
+}
+
+// MarshalYAML writes unpinned endpoints without regions in the plain URL form
+func (e APIEndpoint) MarshalYAML() (interface{}, error) {
+	if e.Version == "" && len(e.Regions) == 0 {
+		return e.URL, nil
+	}
+
+	type plain APIEndpoint
+	return plain(e), nil
+}
+
+// NormalizedVersion returns the pinned version in "vN" form, or an empty
+// string if the endpoint is not pinned
+func (e APIEndpoint) NormalizedVersion() string {
+	v := strings.TrimSpace(strings.ToLower(e.Version))
+	if v == "" {
+		return ""
+	}
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return v
+}
+
+// MediaType returns the Accept header value that pins requests to the
+// endpoint's API version, or plain JSON if the endpoint is not pinned
+func (e APIEndpoint) MediaType() string {
+	if v := e.NormalizedVersion(); v != "" {
+		return fmt.Sprintf("application/vnd.cloudoptimizer.%s+json", v)
+	}
+	return "application/json"
+}
+
+// Endpoint returns the configured API endpoint with the given name
+func (c *Config) Endpoint(name string) (APIEndpoint, error) {
+	endpoint, exists := c.APIEndpoints[name]
+	if !exists || endpoint.URL == "" {
+		return APIEndpoint{}, fmt.Errorf("API endpoint %q not configured", name)
+	}
+	return endpoint, nil
+}
+
+// RegionalEndpoint returns the endpoint with the given name for a region. The
+// regional entry is used when one is configured, inheriting the pinned version
+// if it sets none; otherwise the global endpoint is returned.
+func (c *Config) RegionalEndpoint(name, region string) (APIEndpoint, error) {
+	endpoint, exists := c.APIEndpoints[name]
+	if !exists {
+		return APIEndpoint{}, fmt.Errorf("API endpoint %q not configured", name)
+	}
+
+	if regional, ok := endpoint.Regions[region]; ok && regional.URL != "" {
+		if regional.Version == "" {
+			regional.Version = endpoint.Version
+		}
+		regional.Regions = nil
+		return regional, nil
+	}
+
+	if endpoint.URL == "" {
+		return APIEndpoint{}, fmt.Errorf("API endpoint %q not configured for region %s and has no global url", name, region)
+	}
+	return endpoint, nil
+}
+
+// Dir returns the directory holding the CLI configuration and local data
+func Dir() (string, error) {
+	return getConfigDir()
+}
+
+// ResolveCacheDir returns the absolute cache directory. A leading ~ in
+// cache_dir is expanded to the home directory and relative paths are resolved
+// against the config directory.
+func (c *Config) ResolveCacheDir() (string, error) {
+	configDir, err := getConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := c.CacheDir
+	if dir == "" {
+		return filepath.Join(configDir, "cache"), nil
+	}
+
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %v", err)
+		}
+		dir = filepath.Join(homeDir, strings.TrimPrefix(dir, "~"))
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(configDir, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
 func getConfigDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
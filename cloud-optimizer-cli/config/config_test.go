@@ -0,0 +1,136 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestAPIEndpointYAML(t *testing.T) {
+	tests := []struct {
+		name          string
+		yaml          string
+		want          APIEndpoint
+		wantMediaType string
+	}{
+		{
+			name:          "plain URL",
+			yaml:          `optimizer: https://api.example.com`,
+			want:          APIEndpoint{URL: "https://api.example.com"},
+			wantMediaType: "application/json",
+		},
+		{
+			name:          "pinned version",
+			yaml:          "optimizer:\n  url: https://api.example.com\n  version: \"2\"",
+			want:          APIEndpoint{URL: "https://api.example.com", Version: "2"},
+			wantMediaType: "application/vnd.cloudoptimizer.v2+json",
+		},
+		{
+			name:          "pinned version with prefix",
+			yaml:          "optimizer:\n  url: https://api.example.com\n  version: V1",
+			want:          APIEndpoint{URL: "https://api.example.com", Version: "V1"},
+			wantMediaType: "application/vnd.cloudoptimizer.v1+json",
+		},
+		{
+			name:          "regional endpoints",
+			yaml:          "optimizer:\n  url: https://api.example.com\n  regions:\n    eu-west-1: https://eu.api.example.com",
+			want:          APIEndpoint{URL: "https://api.example.com", Regions: map[string]APIEndpoint{"eu-west-1": {URL: "https://eu.api.example.com"}}},
+			wantMediaType: "application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var endpoints map[string]APIEndpoint
+			if err := yaml.Unmarshal([]byte(tt.yaml), &endpoints); err != nil {
+				t.Fatal(err)
+			}
+			got := endpoints["optimizer"]
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("endpoint %+v, want %+v", got, tt.want)
+			}
+			if mt := got.MediaType(); mt != tt.wantMediaType {
+				t.Errorf("MediaType() = %s, want %s", mt, tt.wantMediaType)
+			}
+
+			data, err := yaml.Marshal(endpoints)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var again map[string]APIEndpoint
+			if err := yaml.Unmarshal(data, &again); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again["optimizer"], tt.want) {
+				t.Errorf("endpoint after a round trip %+v, want %+v", again["optimizer"], tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionalEndpoint(t *testing.T) {
+	cfg := &Config{APIEndpoints: map[string]APIEndpoint{
+		"optimizer": {
+			URL:     "https://api.example.com",
+			Version: "2",
+			Regions: map[string]APIEndpoint{
+				"eu-west-1": {URL: "https://eu.api.example.com"},
+				"us-east-1": {URL: "https://us.api.example.com", Version: "1"},
+			},
+		},
+		"regional-only": {Regions: map[string]APIEndpoint{"eu-west-1": {URL: "https://eu.example.com"}}},
+	}}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		region   string
+		want     APIEndpoint
+		wantErr  bool
+	}{
+		{name: "regional", endpoint: "optimizer", region: "eu-west-1", want: APIEndpoint{URL: "https://eu.api.example.com", Version: "2"}},
+		{name: "regional with its own version", endpoint: "optimizer", region: "us-east-1", want: APIEndpoint{URL: "https://us.api.example.com", Version: "1"}},
+		{name: "falls back to global", endpoint: "optimizer", region: "ap-south-1", want: APIEndpoint{URL: "https://api.example.com", Version: "2", Regions: cfg.APIEndpoints["optimizer"].Regions}},
+		{name: "no global fallback", endpoint: "regional-only", region: "us-east-1", wantErr: true},
+		{name: "not configured", endpoint: "billing", region: "eu-west-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.RegionalEndpoint(tt.endpoint, tt.region)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RegionalEndpoint(%s, %s) error = %v, want error %v", tt.endpoint, tt.region, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RegionalEndpoint(%s, %s) = %+v, want %+v", tt.endpoint, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCacheDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		cacheDir string
+		want     string
+	}{
+		{cacheDir: "", want: filepath.Join(home, ".cloudopt", "cache")},
+		{cacheDir: "~/tmp/cloudopt", want: filepath.Join(home, "tmp", "cloudopt")},
+		{cacheDir: "scratch/../cache2", want: filepath.Join(home, ".cloudopt", "cache2")},
+		{cacheDir: "/var/cache/cloudopt/", want: "/var/cache/cloudopt"},
+	}
+
+	for _, tt := range tests {
+		got, err := (&Config{CacheDir: tt.cacheDir}).ResolveCacheDir()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("ResolveCacheDir() with cache_dir %q = %s, want %s", tt.cacheDir, got, tt.want)
+		}
+	}
+}
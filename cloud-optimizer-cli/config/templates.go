@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RequirementsTemplate is a named set of base placement requirements. Fields
+// holds the requirement fields, written inline in the template. Extends names
+// a parent template whose fields apply underneath this template's own.
+type RequirementsTemplate struct {
+	Extends string                 `yaml:"extends,omitempty"`
+	Fields  map[string]interface{} `yaml:",inline"`
+}
+
+// ResolveTemplate returns the requirement fields of the named template with
+// its ancestors merged in, nearer templates taking precedence. Nested
+// mappings such as tags are merged key by key; other values are replaced.
+func (c *Config) ResolveTemplate(name string) (map[string]interface{}, error) {
+	return c.resolveTemplate(name, nil)
+}
+
+func (c *Config) resolveTemplate(name string, chain []string) (map[string]interface{}, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return nil, fmt.Errorf("template cycle: %s", strings.Join(append(chain, name), " -> "))
+		}
+	}
+
+	t, ok := c.Templates[name]
+	if !ok {
+		if len(chain) > 0 {
+			return nil, fmt.Errorf("template %s extends unknown template %s", chain[len(chain)-1], name)
+		}
+		return nil, fmt.Errorf("unknown template: %s", name)
+	}
+
+	fields := make(map[string]interface{})
+	if t.Extends != "" {
+		parent, err := c.resolveTemplate(t.Extends, append(chain, name))
+		if err != nil {
+			return nil, err
+		}
+		fields = parent
+	}
+	MergeRequirements(fields, t.Fields)
+	return fields, nil
+}
+
+// MergeRequirements merges src into dst, with src taking precedence. Values
+// that are mappings in both are merged recursively; any other value in src
+// replaces the one in dst.
+func MergeRequirements(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if merged, ok := mergeMaps(dst[k], v); ok {
+			dst[k] = merged
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// mergeMaps merges src over dst when both are mappings, reporting false
+// otherwise. YAML decodes nested mappings with interface{} keys, so both key
+// types are accepted.
+func mergeMaps(dst, src interface{}) (map[string]interface{}, bool) {
+	d, ok := stringMap(dst)
+	if !ok {
+		return nil, false
+	}
+	s, ok := stringMap(src)
+	if !ok {
+		return nil, false
+	}
+
+	merged := make(map[string]interface{}, len(d)+len(s))
+	for k, v := range d {
+		merged[k] = v
+	}
+	MergeRequirements(merged, s)
+	return merged, true
+}
+
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			result[fmt.Sprint(k)] = v
+		}
+		return result, true
+	}
+	return nil, false
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const testTemplates = `
+templates:
+  base:
+    regions: [eu-west-1]
+    compliance_frameworks: [GDPR]
+    max_monthly_budget: 500
+    tags:
+      team: platform
+      env: dev
+  prod-base:
+    extends: base
+    min_availability: 99.9
+    max_monthly_budget: 1000
+    tags:
+      env: prod
+  loop-a:
+    extends: loop-b
+  loop-b:
+    extends: loop-c
+  loop-c:
+    extends: loop-a
+  orphan:
+    extends: missing
+`
+
+func TestResolveTemplate(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(testTemplates), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			// Unmerged mappings keep the types YAML decoded them with
+			name: "base",
+			want: map[string]interface{}{
+				"regions":               []interface{}{"eu-west-1"},
+				"compliance_frameworks": []interface{}{"GDPR"},
+				"max_monthly_budget":    500,
+				"tags":                  map[interface{}]interface{}{"team": "platform", "env": "dev"},
+			},
+		},
+		{
+			name: "prod-base",
+			want: map[string]interface{}{
+				"regions":               []interface{}{"eu-west-1"},
+				"compliance_frameworks": []interface{}{"GDPR"},
+				"min_availability":      99.9,
+				"max_monthly_budget":    1000,
+				"tags":                  map[string]interface{}{"team": "platform", "env": "prod"},
+			},
+		},
+		{name: "loop-a", wantErr: "template cycle: loop-a -> loop-b -> loop-c -> loop-a"},
+		{name: "orphan", wantErr: "template orphan extends unknown template missing"},
+		{name: "missing", wantErr: "unknown template: missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cfg.ResolveTemplate(tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveTemplate(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// Resolving a template leaves the templates it extends unchanged
+func TestResolveTemplateDoesNotModifyParents(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(testTemplates), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.ResolveTemplate("prod-base"); err != nil {
+		t.Fatal(err)
+	}
+	base, err := cfg.ResolveTemplate("base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base["max_monthly_budget"] != 500 || base["tags"].(map[interface{}]interface{})["env"] != "dev" {
+		t.Errorf("base template changed by resolving prod-base: %v", base)
+	}
+}
+
+func TestMergeRequirements(t *testing.T) {
+	dst := map[string]interface{}{
+		"vcpus":   2,
+		"regions": []interface{}{"eu-west-1", "eu-central-1"},
+		"tags":    map[interface{}]interface{}{"team": "platform", "env": "dev"},
+	}
+	MergeRequirements(dst, map[string]interface{}{
+		"vcpus":   4,
+		"regions": []interface{}{"us-east-1"},
+		"tags":    map[string]interface{}{"env": "prod"},
+		"name":    "api",
+	})
+
+	want := map[string]interface{}{
+		"vcpus":   4,
+		"regions": []interface{}{"us-east-1"},
+		"tags":    map[string]interface{}{"team": "platform", "env": "prod"},
+		"name":    "api",
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("merged %v, want %v", dst, want)
+	}
+}
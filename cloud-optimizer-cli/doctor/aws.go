@@ -0,0 +1,206 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+const (
+	ec2APIVersion = "2016-11-15"
+	// probeInstanceID is a well-formed instance ID that does not exist. EC2
+	// dry runs check permissions before looking the resource up, so tagging
+	// it probes ec2:CreateTags without touching anything.
+	probeInstanceID = "i-00000000000000000"
+	// costExplorerRegion is the region of the single Cost Explorer endpoint
+	costExplorerRegion = "us-east-1"
+)
+
+// awsDeniedCodes are the AWS error codes for calls refused by IAM
+var awsDeniedCodes = map[string]bool{
+	"UnauthorizedOperation": true,
+	"AccessDenied":          true,
+	"AccessDeniedException": true,
+}
+
+// AWSProber probes the configured AWS credentials, assuming the configured
+// role first if there is one. EC2 permissions are probed with dry runs;
+// billing is probed with a one-day Cost Explorer query, which AWS bills at
+// $0.01.
+type AWSProber struct {
+	creds      *config.AWSCredentialProvider
+	region     string
+	httpClient *http.Client
+	now        func() time.Time
+	// endpoint returns the URL of a service's API in a region
+	endpoint func(service, region string) string
+}
+
+// NewAWSProber creates a prober for the configured AWS credentials
+func NewAWSProber(creds config.AWSCreds) *AWSProber {
+	region := creds.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &AWSProber{
+		creds:      config.NewAWSCredentialProvider(creds),
+		region:     region,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+		endpoint: func(service, region string) string {
+			return fmt.Sprintf("https://%s.%s.amazonaws.com/", service, region)
+		},
+	}
+}
+
+// Provider returns aws
+func (p *AWSProber) Provider() string {
+	return "aws"
+}
+
+// Permissions lists the IAM actions cloudopt needs
+func (p *AWSProber) Permissions() []Permission {
+	return []Permission{
+		{Capability: CapabilityDescribeInstances, Description: "Describe EC2 instances", Actions: []string{"ec2:DescribeInstances"}},
+		{Capability: CapabilityReadBilling, Description: "Read cost and usage", Actions: []string{"ce:GetCostAndUsage"}},
+		{Capability: CapabilityApplyTags, Description: "Tag EC2 resources", Actions: []string{"ec2:CreateTags"}},
+	}
+}
+
+// Remediation names the IAM actions to allow
+func (p *AWSProber) Remediation(perm Permission) string {
+	return fmt.Sprintf("allow %s in a policy attached to the configured IAM user or role", actionList(perm.Actions))
+}
+
+// Probe makes the minimal call exercising the permission
+func (p *AWSProber) Probe(ctx context.Context, perm Permission) error {
+	switch perm.Capability {
+	case CapabilityDescribeInstances:
+		return p.ec2DryRun(ctx, url.Values{
+			"Action":     {"DescribeInstances"},
+			"MaxResults": {"5"},
+		})
+	case CapabilityApplyTags:
+		return p.ec2DryRun(ctx, url.Values{
+			"Action":       {"CreateTags"},
+			"ResourceId.1": {probeInstanceID},
+			"Tag.1.Key":    {"cloudopt:doctor"},
+			"Tag.1.Value":  {"probe"},
+		})
+	case CapabilityReadBilling:
+		return p.costAndUsage(ctx)
+	}
+	return fmt.Errorf("no AWS probe for %s", perm.Capability)
+}
+
+// ec2DryRun calls an EC2 action with DryRun set, which answers
+// DryRunOperation when the caller is allowed to perform it
+func (p *AWSProber) ec2DryRun(ctx context.Context, form url.Values) error {
+	form.Set("Version", ec2APIVersion)
+	form.Set("DryRun", "true")
+
+	code, message, err := p.call(ctx, "ec2", p.region, "application/x-www-form-urlencoded; charset=utf-8", nil, form.Encode())
+	if err != nil || code == "" || code == "DryRunOperation" {
+		return err
+	}
+	return awsError(form.Get("Action"), code, message)
+}
+
+// costAndUsage queries yesterday's total cost
+func (p *AWSProber) costAndUsage(ctx context.Context) error {
+	today := p.now().UTC().Truncate(24 * time.Hour)
+	body, err := json.Marshal(map[string]interface{}{
+		"TimePeriod": map[string]string{
+			"Start": today.AddDate(0, 0, -1).Format("2006-01-02"),
+			"End":   today.Format("2006-01-02"),
+		},
+		"Granularity": "DAILY",
+		"Metrics":     []string{"UnblendedCost"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %v", err)
+	}
+
+	code, message, err := p.call(ctx, "ce", costExplorerRegion, "application/x-amz-json-1.1",
+		map[string]string{"X-Amz-Target": "AWSInsightsIndexService.GetCostAndUsage"}, string(body))
+	if err != nil || code == "" {
+		return err
+	}
+	return awsError("GetCostAndUsage", code, message)
+}
+
+// awsErrorResponse is an error from the EC2 query API
+type awsErrorResponse struct {
+	Code    string `xml:"Errors>Error>Code"`
+	Message string `xml:"Errors>Error>Message"`
+}
+
+// awsJSONError is an error from an AWS JSON API
+type awsJSONError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// call signs and sends a request, returning the error code and message of a
+// failed response or empty strings on success. err is set only when no
+// response was received.
+func (p *AWSProber) call(ctx context.Context, service, region, contentType string, headers map[string]string, body string) (code, message string, err error) {
+	creds, err := p.creds.Credentials(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint(service, region), strings.NewReader(body))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	config.SignAWSRequest(req, *creds, region, service, body, p.now())
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("%s request failed: %v", service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return "", "", nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s response: %v", service, err)
+	}
+
+	var xmlErr awsErrorResponse
+	if xml.Unmarshal(data, &xmlErr) == nil && xmlErr.Code != "" {
+		return xmlErr.Code, xmlErr.Message, nil
+	}
+	var jsonErr awsJSONError
+	if json.Unmarshal(data, &jsonErr) == nil && jsonErr.Type != "" {
+		// The type may be qualified, e.g. "com.amazon.coral.service#AccessDeniedException"
+		if i := strings.LastIndex(jsonErr.Type, "#"); i >= 0 {
+			jsonErr.Type = jsonErr.Type[i+1:]
+		}
+		return jsonErr.Type, jsonErr.Message, nil
+	}
+	return fmt.Sprintf("HTTP %d", resp.StatusCode), strings.TrimSpace(string(data)), nil
+}
+
+// awsError classifies an AWS error code as a denial or a failure to probe
+func awsError(action, code, message string) error {
+	if awsDeniedCodes[code] {
+		return deniedf("%s: %s: %s", action, code, message)
+	}
+	return fmt.Errorf("%s: %s: %s", action, code, message)
+}
@@ -0,0 +1,90 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+// A mock AWS that refuses tagging and Cost Explorer is reported as missing
+// those permissions only
+func TestAWSProber(t *testing.T) {
+	var targets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIATEST/") {
+			t.Errorf("unsigned request to %s", r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/ec2/us-west-2/":
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			if r.PostForm.Get("DryRun") != "true" {
+				t.Errorf("%s sent without DryRun", r.PostForm.Get("Action"))
+			}
+			targets = append(targets, r.PostForm.Get("Action"))
+			status, code := http.StatusPreconditionFailed, "DryRunOperation"
+			if r.PostForm.Get("Action") == "CreateTags" {
+				status, code = http.StatusForbidden, "UnauthorizedOperation"
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(`<Response><Errors><Error><Code>` + code + `</Code><Message>dry run</Message></Error></Errors></Response>`))
+		case "/ce/us-east-1/":
+			targets = append(targets, r.Header.Get("X-Amz-Target"))
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"com.amazon.coral.service#AccessDeniedException","message":"not authorized to perform ce:GetCostAndUsage"}`))
+		default:
+			t.Errorf("request to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewAWSProber(config.AWSCreds{AccessKeyID: "AKIATEST", SecretAccessKey: "secret", Region: "us-west-2"})
+	p.endpoint = func(service, region string) string { return srv.URL + "/" + service + "/" + region + "/" }
+	p.now = func() time.Time { return time.Date(2026, 9, 16, 9, 0, 0, 0, time.UTC) }
+
+	report := Run(context.Background(), p)
+	status := map[string]string{}
+	for _, c := range report.Checks {
+		status[c.Capability] = c.Status
+	}
+	want := map[string]string{CapabilityDescribeInstances: StatusOK, CapabilityReadBilling: StatusMissing, CapabilityApplyTags: StatusMissing}
+	for capability, s := range want {
+		if status[capability] != s {
+			t.Errorf("%s is %s, want %s", capability, status[capability], s)
+		}
+	}
+	for _, c := range report.Checks {
+		if c.Status == StatusMissing && !strings.Contains(c.Remediation, "allow "+c.Actions[0]) {
+			t.Errorf("%s remediation %q, want it to name %s", c.Capability, c.Remediation, c.Actions[0])
+		}
+	}
+	if strings.Join(targets, ",") != "DescribeInstances,AWSInsightsIndexService.GetCostAndUsage,CreateTags" {
+		t.Errorf("calls %v", targets)
+	}
+}
+
+func TestAWSError(t *testing.T) {
+	tests := []struct {
+		code       string
+		wantDenied bool
+	}{
+		{code: "UnauthorizedOperation", wantDenied: true},
+		{code: "AccessDenied", wantDenied: true},
+		{code: "AccessDeniedException", wantDenied: true},
+		{code: "AuthFailure"},
+		{code: "HTTP 500"},
+	}
+	for _, tt := range tests {
+		err := awsError("CreateTags", tt.code, "message")
+		if err == nil || errors.Is(err, ErrDenied) != tt.wantDenied {
+			t.Errorf("awsError(%s) = %v, want denied %v", tt.code, err, tt.wantDenied)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+const (
+	azureLoginEndpoint      = "https://login.microsoftonline.com"
+	azureManagementEndpoint = "https://management.azure.com"
+	azurePermissionsVersion = "2022-04-01"
+)
+
+// AzureProber probes the configured Azure service principal. A single call
+// lists the principal's effective permissions on the subscription, and every
+// permission is checked against that listing.
+type AzureProber struct {
+	creds              config.AzureCreds
+	httpClient         *http.Client
+	loginEndpoint      string
+	managementEndpoint string
+
+	// grants is the permission listing, fetched by the first probe
+	loaded bool
+	grants []azureGrant
+	err    error
+}
+
+// azureGrant is one entry of a permission listing: the actions granted by a
+// role assignment less its not-actions
+type azureGrant struct {
+	Actions    []string `json:"actions"`
+	NotActions []string `json:"notActions"`
+}
+
+// NewAzureProber creates a prober for the configured Azure credentials
+func NewAzureProber(creds config.AzureCreds) *AzureProber {
+	return &AzureProber{
+		creds:              creds,
+		httpClient:         &http.Client{Timeout: 30 * time.Second},
+		loginEndpoint:      azureLoginEndpoint,
+		managementEndpoint: azureManagementEndpoint,
+	}
+}
+
+// Provider returns azure
+func (p *AzureProber) Provider() string {
+	return "azure"
+}
+
+// Permissions lists the Azure RBAC actions cloudopt needs
+func (p *AzureProber) Permissions() []Permission {
+	return []Permission{
+		{Capability: CapabilityDescribeInstances, Description: "Read virtual machines", Actions: []string{"Microsoft.Compute/virtualMachines/read"}},
+		{Capability: CapabilityReadBilling, Description: "Read usage details", Actions: []string{"Microsoft.Consumption/usageDetails/read"}},
+		{Capability: CapabilityApplyTags, Description: "Write resource tags", Actions: []string{"Microsoft.Resources/tags/write"}},
+	}
+}
+
+// Remediation names the RBAC actions to grant the service principal
+func (p *AzureProber) Remediation(perm Permission) string {
+	return fmt.Sprintf("grant %s to service principal %s on subscription %s, e.g. with a custom role",
+		actionList(perm.Actions), p.creds.ClientID, p.creds.SubscriptionID)
+}
+
+// Probe checks the permission's actions against the principal's listing
+func (p *AzureProber) Probe(ctx context.Context, perm Permission) error {
+	if !p.loaded {
+		p.grants, p.err = p.listPermissions(ctx)
+		p.loaded = true
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	var missing []string
+	for _, action := range perm.Actions {
+		if !azureAllows(p.grants, action) {
+			missing = append(missing, action)
+		}
+	}
+	if len(missing) > 0 {
+		return deniedf("not granted: %s", actionList(missing))
+	}
+	return nil
+}
+
+// listPermissions signs in as the service principal and lists its
+// permissions on the subscription
+func (p *AzureProber) listPermissions(ctx context.Context) ([]azureGrant, error) {
+	c := p.creds
+	if c.TenantID == "" || c.ClientID == "" || c.ClientSecret == "" || c.SubscriptionID == "" {
+		return nil, fmt.Errorf("Azure credentials not configured (tenant_id, client_id, client_secret and subscription_id are required)")
+	}
+
+	token, err := p.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Authorization/permissions?api-version=%s",
+		p.managementEndpoint, url.PathEscape(c.SubscriptionID), azurePermissionsVersion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var out struct {
+		Value []azureGrant `json:"value"`
+	}
+	if err := doJSON(p.httpClient, req, &out); err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %v", err)
+	}
+	return out.Value, nil
+}
+
+// token obtains a management API token with the client credentials grant
+func (p *AzureProber) token(ctx context.Context) (string, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {p.creds.ClientID},
+		"client_secret": {p.creds.ClientSecret},
+		"scope":         {azureManagementEndpoint + "/.default"},
+	}
+	u := fmt.Sprintf("%s/%s/oauth2/v2.0/token", p.loginEndpoint, url.PathEscape(p.creds.TenantID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(p.httpClient, req, &out); err != nil {
+		return "", fmt.Errorf("failed to sign in to Azure: %v", err)
+	}
+	return out.AccessToken, nil
+}
+
+// doJSON sends a request and decodes its JSON response, failing on any
+// status but 200
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+// azureAllows reports whether any grant allows the action. Within a grant,
+// not-actions take precedence over actions.
+func azureAllows(grants []azureGrant, action string) bool {
+	for _, g := range grants {
+		if matchesAny(g.Actions, action) && !matchesAny(g.NotActions, action) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, action string) bool {
+	for _, p := range patterns {
+		if wildcardMatch(p, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches an RBAC action pattern, in which * matches any run of
+// characters including /, ignoring case
+func wildcardMatch(pattern, s string) bool {
+	pattern, s = strings.ToLower(pattern), strings.ToLower(s)
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
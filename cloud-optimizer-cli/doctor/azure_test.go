@@ -0,0 +1,101 @@
+package doctor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/config"
+)
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, action string
+		want            bool
+	}{
+		{"*", "Microsoft.Compute/virtualMachines/read", true},
+		{"Microsoft.Compute/*", "Microsoft.Compute/virtualMachines/read", true},
+		{"Microsoft.Compute/*/read", "Microsoft.Compute/virtualMachines/read", true},
+		{"microsoft.compute/virtualmachines/READ", "Microsoft.Compute/virtualMachines/read", true},
+		{"*/read", "Microsoft.Resources/tags/write", false},
+		{"Microsoft.Compute/*", "Microsoft.Consumption/usageDetails/read", false},
+		{"Microsoft.Resources/tags/write", "Microsoft.Resources/tags/writeAll", false},
+	}
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.action); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.action, got, tt.want)
+		}
+	}
+}
+
+// A principal whose listing excludes tag writes is reported as missing only
+// that permission, with one sign-in and one listing for every probe
+func TestAzureProber(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.URL.Path)
+		switch r.URL.Path {
+		case "/tenant-1/oauth2/v2.0/token":
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			if r.PostForm.Get("client_id") != "app-1" || r.PostForm.Get("client_secret") != "secret" {
+				t.Errorf("sign-in form %v", r.PostForm)
+			}
+			w.Write([]byte(`{"access_token":"token-1"}`))
+		case "/subscriptions/sub-1/providers/Microsoft.Authorization/permissions":
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				t.Errorf("listing authorized with %q", r.Header.Get("Authorization"))
+			}
+			w.Write([]byte(`{"value":[
+				{"actions":["*/read"],"notActions":[]},
+				{"actions":["Microsoft.Resources/*"],"notActions":["Microsoft.Resources/tags/*"]}]}`))
+		default:
+			t.Errorf("request to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewAzureProber(config.AzureCreds{TenantID: "tenant-1", ClientID: "app-1", ClientSecret: "secret", SubscriptionID: "sub-1"})
+	p.loginEndpoint, p.managementEndpoint = srv.URL, srv.URL
+
+	report := Run(context.Background(), p)
+	for _, c := range report.Checks {
+		want := StatusOK
+		if c.Capability == CapabilityApplyTags {
+			want = StatusMissing
+		}
+		if c.Status != want {
+			t.Errorf("%s is %s (%s), want %s", c.Capability, c.Status, c.Error, want)
+		}
+	}
+	tags := report.Checks[2]
+	if !strings.Contains(tags.Error, "Microsoft.Resources/tags/write") || !strings.Contains(tags.Remediation, "app-1") || !strings.Contains(tags.Remediation, "sub-1") {
+		t.Errorf("tags check %+v, want the action and principal named", tags)
+	}
+	if len(calls) != 2 {
+		t.Errorf("calls %v, want one sign-in and one listing", calls)
+	}
+}
+
+// Missing or rejected credentials leave every permission unknown
+func TestAzureProberUnknown(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer srv.Close()
+
+	for name, creds := range map[string]config.AzureCreds{
+		"unconfigured": {ClientID: "app-1"},
+		"rejected":     {TenantID: "tenant-1", ClientID: "app-1", ClientSecret: "wrong", SubscriptionID: "sub-1"},
+	} {
+		p := NewAzureProber(creds)
+		p.loginEndpoint, p.managementEndpoint = srv.URL, srv.URL
+		if report := Run(context.Background(), p); report.Count(StatusUnknown) != 3 {
+			t.Errorf("%s: report %+v, want every permission unknown", name, report)
+		}
+	}
+}
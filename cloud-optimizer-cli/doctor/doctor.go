@@ -0,0 +1,116 @@
+// Package doctor probes whether the configured cloud credentials hold the
+// permissions cloudopt needs, reporting the missing ones with the provider
+// actions to grant.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Capabilities cloudopt needs from every provider
+const (
+	CapabilityDescribeInstances = "describe_instances"
+	CapabilityReadBilling       = "read_billing"
+	CapabilityApplyTags         = "apply_tags"
+)
+
+// Check statuses
+const (
+	StatusOK      = "ok"
+	StatusMissing = "missing"
+	// StatusUnknown means the probe could not tell, e.g. the credentials were
+	// rejected or the provider could not be reached
+	StatusUnknown = "unknown"
+)
+
+// ErrDenied is wrapped by probe errors when the provider refused a call for
+// lack of permission
+var ErrDenied = errors.New("permission denied")
+
+// Permission is a capability cloudopt needs and the provider actions that
+// grant it
+type Permission struct {
+	Capability  string
+	Description string
+	Actions     []string
+}
+
+// Prober checks a provider's credentials for permissions with minimal calls
+type Prober interface {
+	// Provider names the cloud provider, e.g. aws
+	Provider() string
+	// Permissions lists the permissions cloudopt needs from the provider
+	Permissions() []Permission
+	// Probe returns nil when the permission is granted, an error wrapping
+	// ErrDenied when it is not, and any other error when it cannot tell
+	Probe(ctx context.Context, perm Permission) error
+	// Remediation tells the user how to grant the permission's actions
+	Remediation(perm Permission) string
+}
+
+// Check is the outcome of probing one permission
+type Check struct {
+	Capability  string   `json:"capability" yaml:"capability"`
+	Description string   `json:"description" yaml:"description"`
+	Status      string   `json:"status" yaml:"status"`
+	Actions     []string `json:"actions" yaml:"actions"`
+	Remediation string   `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+	Error       string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Report is the outcome of probing every permission of one provider
+type Report struct {
+	Provider string  `json:"provider" yaml:"provider"`
+	Checks   []Check `json:"checks" yaml:"checks"`
+}
+
+// Run probes each of the prober's permissions in turn
+func Run(ctx context.Context, p Prober) *Report {
+	report := &Report{Provider: p.Provider()}
+	for _, perm := range p.Permissions() {
+		check := Check{
+			Capability:  perm.Capability,
+			Description: perm.Description,
+			Status:      StatusOK,
+			Actions:     perm.Actions,
+		}
+
+		err := p.Probe(ctx, perm)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrDenied):
+			check.Status = StatusMissing
+			check.Remediation = p.Remediation(perm)
+			check.Error = err.Error()
+		default:
+			check.Status = StatusUnknown
+			check.Error = err.Error()
+		}
+		report.Checks = append(report.Checks, check)
+	}
+	return report
+}
+
+// Count returns the number of checks with the given status
+func (r *Report) Count(status string) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// deniedf returns an error wrapping ErrDenied
+func deniedf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrDenied, fmt.Sprintf(format, args...))
+}
+
+// actionList joins actions for a remediation hint
+func actionList(actions []string) string {
+	return strings.Join(actions, ", ")
+}
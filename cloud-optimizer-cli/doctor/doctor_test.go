@@ -0,0 +1,73 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fakeProber denies the capabilities in denied and fails to probe those in
+// broken
+type fakeProber struct {
+	denied map[string]bool
+	broken map[string]bool
+}
+
+func (p *fakeProber) Provider() string {
+	return "fake"
+}
+
+func (p *fakeProber) Permissions() []Permission {
+	return []Permission{
+		{Capability: CapabilityDescribeInstances, Description: "Describe instances", Actions: []string{"fake:Describe"}},
+		{Capability: CapabilityReadBilling, Description: "Read billing", Actions: []string{"fake:GetCost", "fake:GetUsage"}},
+		{Capability: CapabilityApplyTags, Description: "Apply tags", Actions: []string{"fake:Tag"}},
+	}
+}
+
+func (p *fakeProber) Probe(ctx context.Context, perm Permission) error {
+	switch {
+	case p.denied[perm.Capability]:
+		return deniedf("%s refused", perm.Actions[0])
+	case p.broken[perm.Capability]:
+		return errors.New("connection reset")
+	}
+	return nil
+}
+
+func (p *fakeProber) Remediation(perm Permission) string {
+	return fmt.Sprintf("allow %s", actionList(perm.Actions))
+}
+
+func TestRun(t *testing.T) {
+	p := &fakeProber{
+		denied: map[string]bool{CapabilityReadBilling: true},
+		broken: map[string]bool{CapabilityApplyTags: true},
+	}
+	report := Run(context.Background(), p)
+
+	want := &Report{Provider: "fake", Checks: []Check{
+		{Capability: CapabilityDescribeInstances, Description: "Describe instances", Status: StatusOK, Actions: []string{"fake:Describe"}},
+		{
+			Capability:  CapabilityReadBilling,
+			Description: "Read billing",
+			Status:      StatusMissing,
+			Actions:     []string{"fake:GetCost", "fake:GetUsage"},
+			Remediation: "allow fake:GetCost, fake:GetUsage",
+			Error:       "permission denied: fake:GetCost refused",
+		},
+		{Capability: CapabilityApplyTags, Description: "Apply tags", Status: StatusUnknown, Actions: []string{"fake:Tag"}, Error: "connection reset"},
+	}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report %+v, want %+v", report, want)
+	}
+	if report.Count(StatusOK) != 1 || report.Count(StatusMissing) != 1 || report.Count(StatusUnknown) != 1 {
+		t.Errorf("counts %d ok, %d missing, %d unknown, want one of each", report.Count(StatusOK), report.Count(StatusMissing), report.Count(StatusUnknown))
+	}
+
+	if report := Run(context.Background(), &fakeProber{}); report.Count(StatusOK) != 3 {
+		t.Errorf("report %+v, want every permission granted", report)
+	}
+}
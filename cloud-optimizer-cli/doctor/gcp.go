@@ -0,0 +1,245 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"cloud-optimizer-cli/config"
+)
+
+const (
+	gcpResourceManagerEndpoint = "https://cloudresourcemanager.googleapis.com"
+	gcpDefaultTokenURI         = "https://oauth2.googleapis.com/token"
+	gcpScope                   = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// GCPProber probes the configured GCP service account. A single
+// testIamPermissions call on the project reports which of the needed
+// permissions the account holds.
+type GCPProber struct {
+	creds      config.GCPCreds
+	httpClient *http.Client
+	endpoint   string
+	now        func() time.Time
+
+	// project is the project probed, from project_id or the key file
+	project string
+	// granted holds the permissions the account has, fetched by the first
+	// probe
+	loaded  bool
+	granted map[string]bool
+	err     error
+}
+
+// gcpServiceAccountKey is the part of a service account key file used to sign
+// in
+type gcpServiceAccountKey struct {
+	Type        string `json:"type"`
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// NewGCPProber creates a prober for the configured GCP credentials
+func NewGCPProber(creds config.GCPCreds) *GCPProber {
+	return &GCPProber{
+		creds:      creds,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		endpoint:   gcpResourceManagerEndpoint,
+		now:        time.Now,
+	}
+}
+
+// Provider returns gcp
+func (p *GCPProber) Provider() string {
+	return "gcp"
+}
+
+// Permissions lists the IAM permissions cloudopt needs on the project
+func (p *GCPProber) Permissions() []Permission {
+	return []Permission{
+		{Capability: CapabilityDescribeInstances, Description: "List Compute Engine instances", Actions: []string{"compute.instances.list"}},
+		{Capability: CapabilityReadBilling, Description: "Read resource costs", Actions: []string{"billing.resourceCosts.get"}},
+		{Capability: CapabilityApplyTags, Description: "Label Compute Engine instances", Actions: []string{"compute.instances.setLabels"}},
+	}
+}
+
+// Remediation names the permissions to grant the service account
+func (p *GCPProber) Remediation(perm Permission) string {
+	return fmt.Sprintf("grant %s to the service account on project %s, e.g. with a custom role",
+		actionList(perm.Actions), p.project)
+}
+
+// Probe checks the permission against those the account holds
+func (p *GCPProber) Probe(ctx context.Context, perm Permission) error {
+	if !p.loaded {
+		p.granted, p.err = p.testPermissions(ctx)
+		p.loaded = true
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	var missing []string
+	for _, permission := range perm.Actions {
+		if !p.granted[permission] {
+			missing = append(missing, permission)
+		}
+	}
+	if len(missing) > 0 {
+		return deniedf("not granted: %s", actionList(missing))
+	}
+	return nil
+}
+
+// testPermissions signs in as the service account and asks which of the
+// needed permissions it holds on the project
+func (p *GCPProber) testPermissions(ctx context.Context) (map[string]bool, error) {
+	if p.creds.CredentialFile == "" {
+		return nil, fmt.Errorf("GCP credentials not configured (credential_file is required)")
+	}
+	data, err := os.ReadFile(p.creds.CredentialFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GCP credential file: %v", err)
+	}
+	var key gcpServiceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse GCP credential file: %v", err)
+	}
+	if key.Type != "service_account" {
+		return nil, fmt.Errorf("GCP credential file is a %q key; a service_account key is required", key.Type)
+	}
+
+	project := p.creds.ProjectID
+	if project == "" {
+		project = key.ProjectID
+	}
+	if project == "" {
+		return nil, fmt.Errorf("GCP project not configured (project_id is required)")
+	}
+	p.project = project
+
+	token, err := p.token(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var permissions []string
+	for _, perm := range p.Permissions() {
+		permissions = append(permissions, perm.Actions...)
+	}
+	body, err := json.Marshal(map[string][]string{"permissions": permissions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %v", err)
+	}
+
+	u := fmt.Sprintf("%s/v1/projects/%s:testIamPermissions", p.endpoint, url.PathEscape(project))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var out struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := doJSON(p.httpClient, req, &out); err != nil {
+		return nil, fmt.Errorf("failed to test permissions on project %s: %v", project, err)
+	}
+
+	granted := make(map[string]bool, len(out.Permissions))
+	for _, permission := range out.Permissions {
+		granted[permission] = true
+	}
+	return granted, nil
+}
+
+// token exchanges a JWT signed with the service account key for an access
+// token
+func (p *GCPProber) token(ctx context.Context, key gcpServiceAccountKey) (string, error) {
+	tokenURI := key.TokenURI
+	if tokenURI == "" {
+		tokenURI = gcpDefaultTokenURI
+	}
+
+	assertion, err := signJWT(key, tokenURI, p.now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(p.httpClient, req, &out); err != nil {
+		return "", fmt.Errorf("failed to sign in to GCP: %v", err)
+	}
+	return out.AccessToken, nil
+}
+
+// signJWT builds the RS256-signed assertion of the OAuth JWT bearer grant
+func signJWT(key gcpServiceAccountKey, audience string, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("GCP credential file has no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse GCP private key: %v", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("GCP private key is not an RSA key")
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": gcpScope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GCP token request: %v", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
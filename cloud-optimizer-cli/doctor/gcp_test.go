@@ -0,0 +1,123 @@
+package doctor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud-optimizer-cli/config"
+)
+
+// writeGCPKey writes a service account key file signing in at tokenURI
+func writeGCPKey(t *testing.T, tokenURI string) string {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(gcpServiceAccountKey{
+		Type:        "service_account",
+		ProjectID:   "proj-1",
+		ClientEmail: "cloudopt@proj-1.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    tokenURI,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// An account holding only the compute permissions is reported as missing
+// billing access
+func TestGCPProber(t *testing.T) {
+	var tested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			if parts := strings.Split(r.PostForm.Get("assertion"), "."); len(parts) != 3 {
+				t.Errorf("assertion %q is not a signed JWT", r.PostForm.Get("assertion"))
+			}
+			w.Write([]byte(`{"access_token":"token-1"}`))
+		case "/v1/projects/proj-1:testIamPermissions":
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				t.Errorf("test authorized with %q", r.Header.Get("Authorization"))
+			}
+			var body struct {
+				Permissions []string `json:"permissions"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			tested = body.Permissions
+			w.Write([]byte(`{"permissions":["compute.instances.list","compute.instances.setLabels"]}`))
+		default:
+			t.Errorf("request to %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewGCPProber(config.GCPCreds{CredentialFile: writeGCPKey(t, srv.URL+"/token")})
+	p.endpoint = srv.URL
+
+	report := Run(context.Background(), p)
+	for _, c := range report.Checks {
+		want := StatusOK
+		if c.Capability == CapabilityReadBilling {
+			want = StatusMissing
+		}
+		if c.Status != want {
+			t.Errorf("%s is %s (%s), want %s", c.Capability, c.Status, c.Error, want)
+		}
+	}
+	if billing := report.Checks[1]; !strings.Contains(billing.Remediation, "billing.resourceCosts.get") || !strings.Contains(billing.Remediation, "proj-1") {
+		t.Errorf("billing remediation %q, want the permission and project named", billing.Remediation)
+	}
+	if len(tested) != 3 {
+		t.Errorf("tested %v, want every needed permission in one call", tested)
+	}
+}
+
+func TestGCPProberUnknown(t *testing.T) {
+	dir := t.TempDir()
+	userKey := filepath.Join(dir, "user.json")
+	if err := os.WriteFile(userKey, []byte(`{"type":"authorized_user"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		creds   config.GCPCreds
+		wantErr string
+	}{
+		{name: "unconfigured", wantErr: "credential_file is required"},
+		{name: "missing file", creds: config.GCPCreds{CredentialFile: filepath.Join(dir, "missing.json")}, wantErr: "failed to read"},
+		{name: "user key", creds: config.GCPCreds{CredentialFile: userKey}, wantErr: "service_account key is required"},
+	}
+	for _, tt := range tests {
+		report := Run(context.Background(), NewGCPProber(tt.creds))
+		if report.Count(StatusUnknown) != 3 || !strings.Contains(report.Checks[0].Error, tt.wantErr) {
+			t.Errorf("%s: report %+v, want every permission unknown with %q", tt.name, report, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPIURL is the GitHub REST API of github.com
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// GitHubIssues files issues in a GitHub repository through the REST API
+type GitHubIssues struct {
+	baseURL    string
+	owner      string
+	repo       string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGitHubIssues creates an exporter for the repository, given as
+// owner/name, authenticating with token. baseURL is the REST API root, such
+// as DefaultGitHubAPIURL or https://github.example.com/api/v3.
+func NewGitHubIssues(baseURL, repository, token string) (*GitHubIssues, error) {
+	owner, repo, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return nil, fmt.Errorf("repository must be owner/name, got %q", repository)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a GitHub token is required")
+	}
+
+	return &GitHubIssues{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		owner:      owner,
+		repo:       repo,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// githubIssue is the part of a GitHub issue the exporter reads
+type githubIssue struct {
+	HTMLURL string `json:"html_url"`
+}
+
+// FindIssue returns the URL of the repository's most recent issue, open or
+// closed, carrying the label
+func (g *GitHubIssues) FindIssue(ctx context.Context, label string) (string, error) {
+	query := url.Values{"labels": {label}, "state": {"all"}, "per_page": {"1"}}
+	var issues []githubIssue
+	if err := g.do(ctx, http.MethodGet, g.issuesPath()+"?"+query.Encode(), nil, &issues); err != nil {
+		return "", err
+	}
+	if len(issues) == 0 {
+		return "", nil
+	}
+	return issues[0].HTMLURL, nil
+}
+
+// CreateIssue opens the issue and returns its URL. GitHub creates labels the
+// repository doesn't have yet.
+func (g *GitHubIssues) CreateIssue(ctx context.Context, issue Issue) (string, error) {
+	body := map[string]interface{}{
+		"title":  issue.Title,
+		"body":   issue.Body,
+		"labels": issue.Labels,
+	}
+	var created githubIssue
+	if err := g.do(ctx, http.MethodPost, g.issuesPath(), body, &created); err != nil {
+		return "", err
+	}
+	return created.HTMLURL, nil
+}
+
+func (g *GitHubIssues) issuesPath() string {
+	return fmt.Sprintf("/repos/%s/%s/issues", url.PathEscape(g.owner), url.PathEscape(g.repo))
+}
+
+// do sends a request to the API and decodes the response into out
+func (g *GitHubIssues) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var reqBody io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %v", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("github request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read github response: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("github returned %s: %s", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("github returned %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode github response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"cloud-optimizer-cli/inventory"
+)
+
+// importableTypes are the placement types the gateway can adopt existing
+// resources as, which is what the provider's importer does
+var importableTypes = map[string]bool{
+	"compute": true,
+}
+
+// ImportMapping maps an inventoried resource to the placement resource
+// address it is imported to. The import ID is the resource's provider ID; the
+// provider adopts it as a placement on import.
+type ImportMapping struct {
+	ResourceID   string `json:"resource_id"`
+	ResourceType string `json:"resource_type"`
+	Address      string `json:"address"`
+}
+
+// SkippedResource is an inventoried resource that cannot be imported
+type SkippedResource struct {
+	ResourceID string `json:"resource_id"`
+	Reason     string `json:"reason"`
+}
+
+// ImportMappings maps each importable resource of the inventory to a unique
+// cloudoptimizer placement address, labeled by its name or else its ID, in
+// inventory order. Resources of types the gateway cannot adopt are skipped.
+func ImportMappings(resources []inventory.Resource) ([]ImportMapping, []SkippedResource) {
+	var mappings []ImportMapping
+	var skipped []SkippedResource
+	labels := make(map[string]int)
+	for _, r := range resources {
+		placementType, ok := placementTypes[strings.ToLower(r.Type)]
+		if !ok {
+			skipped = append(skipped, SkippedResource{ResourceID: r.ID, Reason: fmt.Sprintf("unknown resource type %q", r.Type)})
+			continue
+		}
+		if !importableTypes[placementType] {
+			skipped = append(skipped, SkippedResource{ResourceID: r.ID, Reason: fmt.Sprintf("%s placements cannot be imported", placementType)})
+			continue
+		}
+
+		name := r.Name
+		if name == "" {
+			name = r.ID
+		}
+		mappings = append(mappings, ImportMapping{
+			ResourceID:   r.ID,
+			ResourceType: placementType,
+			Address:      "cloudoptimizer_" + placementType + "_placement." + uniqueLabel(name, labels),
+		})
+	}
+	return mappings, skipped
+}
+
+// ImportCommands writes a terraform import command for each mapping, with
+// the import ID quoted for POSIX shells
+func ImportCommands(w io.Writer, mappings []ImportMapping) error {
+	for _, m := range mappings {
+		if _, err := fmt.Fprintf(w, "terraform import %s %s\n", m.Address, shellQuote(m.ResourceID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportBlocks writes a Terraform import block for each mapping, for bulk
+// import with terraform plan -generate-config-out
+func ImportBlocks(w io.Writer, mappings []ImportMapping) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	appendComment(body, "Generated by cloudopt tf import. Each resource is adopted as a placement on import.")
+	for i, m := range mappings {
+		if i > 0 {
+			body.AppendNewline()
+		}
+		block := body.AppendNewBlock("import", nil).Body()
+		resourceType, label, _ := strings.Cut(m.Address, ".")
+		block.SetAttributeTraversal("to", hcl.Traversal{
+			hcl.TraverseRoot{Name: resourceType},
+			hcl.TraverseAttr{Name: label},
+		})
+		block.SetAttributeValue("id", cty.StringVal(m.ResourceID))
+	}
+
+	_, err := f.WriteTo(w)
+	return err
+}
+
+// shellQuote single-quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"cloud-optimizer-cli/inventory"
+)
+
+func TestImportMappings(t *testing.T) {
+	resources := []inventory.Resource{
+		{ID: "i-1", Name: "web", Type: "instance"},
+		{ID: "b-1", Name: "logs", Type: "bucket"},
+		{ID: "i-2", Name: "web", Type: "VM"},
+		{ID: "projects/p/zones/z/instances/it's", Type: "compute"},
+		{ID: "x-1", Name: "queue", Type: "queue"},
+		{ID: "i-3", Name: "9lives", Type: "compute"},
+	}
+
+	mappings, skipped := ImportMappings(resources)
+	wantMappings := []ImportMapping{
+		{ResourceID: "i-1", ResourceType: "compute", Address: "cloudoptimizer_compute_placement.web"},
+		{ResourceID: "i-2", ResourceType: "compute", Address: "cloudoptimizer_compute_placement.web_2"},
+		{ResourceID: "projects/p/zones/z/instances/it's", ResourceType: "compute", Address: "cloudoptimizer_compute_placement.projects_p_zones_z_instances_it_s"},
+		{ResourceID: "i-3", ResourceType: "compute", Address: "cloudoptimizer_compute_placement.r_9lives"},
+	}
+	if !reflect.DeepEqual(mappings, wantMappings) {
+		t.Errorf("mappings\n%+v\nwant\n%+v", mappings, wantMappings)
+	}
+	wantSkipped := []SkippedResource{
+		{ResourceID: "b-1", Reason: "storage placements cannot be imported"},
+		{ResourceID: "x-1", Reason: `unknown resource type "queue"`},
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped %+v, want %+v", skipped, wantSkipped)
+	}
+}
+
+func TestImportCommands(t *testing.T) {
+	mappings := []ImportMapping{
+		{ResourceID: "i-1", Address: "cloudoptimizer_compute_placement.web"},
+		{ResourceID: "it's", Address: "cloudoptimizer_compute_placement.it_s"},
+	}
+
+	var buf bytes.Buffer
+	if err := ImportCommands(&buf, mappings); err != nil {
+		t.Fatal(err)
+	}
+	want := "terraform import cloudoptimizer_compute_placement.web 'i-1'\n" +
+		`terraform import cloudoptimizer_compute_placement.it_s 'it'\''s'` + "\n"
+	if buf.String() != want {
+		t.Errorf("commands\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestImportBlocks(t *testing.T) {
+	mappings := []ImportMapping{
+		{ResourceID: "i-1", Address: "cloudoptimizer_compute_placement.web"},
+		{ResourceID: `say "hi"`, Address: "cloudoptimizer_compute_placement.web_2"},
+	}
+
+	var buf bytes.Buffer
+	if err := ImportBlocks(&buf, mappings); err != nil {
+		t.Fatal(err)
+	}
+	file, diags := hclparse.NewParser().ParseHCL(buf.Bytes(), "imports.tf")
+	if diags.HasErrors() {
+		t.Fatalf("generated HCL does not parse: %v\n%s", diags, buf.String())
+	}
+
+	content, diags := file.Body.Content(&hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: "import"}}})
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	if len(content.Blocks) != len(mappings) {
+		t.Fatalf("%d import blocks, want %d:\n%s", len(content.Blocks), len(mappings), buf.String())
+	}
+	for i, block := range content.Blocks {
+		attrs, diags := block.Body.JustAttributes()
+		if diags.HasErrors() {
+			t.Fatal(diags)
+		}
+		to, diags := hcl.AbsTraversalForExpr(attrs["to"].Expr)
+		if diags.HasErrors() {
+			t.Fatal(diags)
+		}
+		if got := to.RootName() + "." + to[1].(hcl.TraverseAttr).Name; got != mappings[i].Address {
+			t.Errorf("block %d imports to %s, want %s", i, got, mappings[i].Address)
+		}
+		id, diags := attrs["id"].Expr.Value(nil)
+		if diags.HasErrors() || id.AsString() != mappings[i].ResourceID {
+			t.Errorf("block %d id %#v, want %q", i, id, mappings[i].ResourceID)
+		}
+	}
+}
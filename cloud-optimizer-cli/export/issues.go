@@ -0,0 +1,210 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"cloud-optimizer-cli/api"
+)
+
+// recommendationLabelPrefix prefixes the label tagging an issue with the
+// recommendation it was filed for
+const recommendationLabelPrefix = "cloudopt:"
+
+// Issue is a ticket to file for a recommendation
+type Issue struct {
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// IssueExporter files issues in a ticketing system
+type IssueExporter interface {
+	// FindIssue returns the URL of an issue carrying the label, open or
+	// closed, or "" when there is none
+	FindIssue(ctx context.Context, label string) (string, error)
+	// CreateIssue files the issue and returns its URL
+	CreateIssue(ctx context.Context, issue Issue) (string, error)
+}
+
+// IssueResult is the issue of one exported recommendation. Created is false
+// when an issue had already been filed for it.
+type IssueResult struct {
+	RecommendationID string `json:"recommendation_id" yaml:"recommendation_id"`
+	URL              string `json:"url" yaml:"url"`
+	Created          bool   `json:"created" yaml:"created"`
+}
+
+// DefaultIssueTitle and DefaultIssueBody are the templates issues are
+// rendered from unless others are given. Templates are executed with an
+// IssueData.
+const (
+	DefaultIssueTitle = `[cloudopt] {{with .Recommendation.Action}}{{.}}: {{end}}{{.Recommendation.Description}}`
+	DefaultIssueBody  = `Cloud Optimizer recommendation ` + "`{{.Recommendation.ID}}`" + `
+
+| | |
+|---|---|
+| Type | {{.Recommendation.Type}} |
+| Priority | {{.Recommendation.Priority}} |
+{{- with .Recommendation.ResourceID}}
+| Resource | ` + "`{{.}}`" + ` |
+{{- end}}
+| Estimated savings | ${{printf "%.2f" .Recommendation.EstimatedSavings}}/month |
+{{- with .Recommendation.Effort}}
+| Effort | {{.}} |
+{{- end}}
+{{- with .Recommendation.Risk}}
+| Risk | {{.}} |
+{{- end}}
+
+{{.Recommendation.Description}}
+{{- if .Remediation}}
+
+## Remediation
+{{range $i, $step := .Remediation}}
+{{inc $i}}. {{$step}}
+{{- end}}
+{{- end}}
+`
+)
+
+// IssueData is what issue templates are executed with
+type IssueData struct {
+	Recommendation api.Recommendation
+	// Remediation lists the steps to carry out the recommendation
+	Remediation []string
+}
+
+// IssueTemplate renders recommendations as issues
+type IssueTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// NewIssueTemplate parses the title and body templates; empty ones fall back
+// to DefaultIssueTitle and DefaultIssueBody
+func NewIssueTemplate(title, body string) (*IssueTemplate, error) {
+	if title == "" {
+		title = DefaultIssueTitle
+	}
+	if body == "" {
+		body = DefaultIssueBody
+	}
+
+	funcs := template.FuncMap{"inc": func(i int) int { return i + 1 }}
+	t := &IssueTemplate{}
+	var err error
+	if t.title, err = template.New("title").Funcs(funcs).Parse(title); err != nil {
+		return nil, fmt.Errorf("invalid issue title template: %v", err)
+	}
+	if t.body, err = template.New("body").Funcs(funcs).Parse(body); err != nil {
+		return nil, fmt.Errorf("invalid issue body template: %v", err)
+	}
+	return t, nil
+}
+
+// Render renders the issue for the recommendation, labeled with its ID
+func (t *IssueTemplate) Render(rec api.Recommendation) (Issue, error) {
+	data := IssueData{Recommendation: rec, Remediation: remediationSteps(rec)}
+
+	var title, body bytes.Buffer
+	if err := t.title.Execute(&title, data); err != nil {
+		return Issue{}, fmt.Errorf("failed to render issue title: %v", err)
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return Issue{}, fmt.Errorf("failed to render issue body: %v", err)
+	}
+
+	return Issue{
+		Title:  strings.TrimSpace(title.String()),
+		Body:   body.String(),
+		Labels: []string{RecommendationLabel(rec.ID)},
+	}, nil
+}
+
+// RecommendationLabel returns the label tagging the issue filed for a
+// recommendation, by which existing issues are found
+func RecommendationLabel(id string) string {
+	return recommendationLabelPrefix + id
+}
+
+// ExportIssues files an issue for each recommendation, in order, skipping
+// those already filed. It stops at the first error, returning the results so
+// far.
+func ExportIssues(ctx context.Context, exporter IssueExporter, tmpl *IssueTemplate, recs []api.Recommendation) ([]IssueResult, error) {
+	results := make([]IssueResult, 0, len(recs))
+	for _, rec := range recs {
+		url, err := exporter.FindIssue(ctx, RecommendationLabel(rec.ID))
+		if err != nil {
+			return results, fmt.Errorf("recommendation %s: %v", rec.ID, err)
+		}
+		if url != "" {
+			results = append(results, IssueResult{RecommendationID: rec.ID, URL: url})
+			continue
+		}
+
+		issue, err := tmpl.Render(rec)
+		if err != nil {
+			return results, fmt.Errorf("recommendation %s: %v", rec.ID, err)
+		}
+		url, err = exporter.CreateIssue(ctx, issue)
+		if err != nil {
+			return results, fmt.Errorf("recommendation %s: %v", rec.ID, err)
+		}
+		results = append(results, IssueResult{RecommendationID: rec.ID, URL: url, Created: true})
+	}
+	return results, nil
+}
+
+// remediationSteps returns the steps to carry out the recommendation's
+// action, from its details where known
+func remediationSteps(rec api.Recommendation) []string {
+	resource := "the resource"
+	if rec.ResourceID != "" {
+		resource = "`" + rec.ResourceID + "`"
+	}
+	detail := func(key string) string {
+		if s, ok := rec.Details[key].(string); ok && s != "" {
+			return "`" + s + "`"
+		}
+		return ""
+	}
+
+	switch rec.Action {
+	case api.ActionTag:
+		return []string{
+			fmt.Sprintf("Apply the recommended tags to %s.", resource),
+			"Confirm the tags appear in the cost allocation report.",
+		}
+	case api.ActionResize:
+		target := "the recommended instance type"
+		if t := detail("instance_type"); t != "" {
+			target = t
+		}
+		return []string{
+			fmt.Sprintf("Schedule a maintenance window for %s.", resource),
+			fmt.Sprintf("Resize %s to %s.", resource, target),
+			"Monitor utilization and latency for a week after the change.",
+		}
+	case api.ActionMigrate:
+		target := "the recommended provider and region"
+		if p, r := detail("provider"), detail("region"); p != "" && r != "" {
+			target = p + " " + r
+		}
+		return []string{
+			fmt.Sprintf("Provision a replacement for %s in %s.", resource, target),
+			"Migrate data and traffic, then verify the replacement.",
+			fmt.Sprintf("Decommission %s.", resource),
+		}
+	case api.ActionTerminate:
+		return []string{
+			fmt.Sprintf("Confirm with the owners that %s is no longer needed.", resource),
+			"Snapshot or back up any data to keep.",
+			fmt.Sprintf("Terminate %s and remove its placement.", resource),
+		}
+	}
+	return nil
+}
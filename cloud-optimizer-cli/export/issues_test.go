@@ -0,0 +1,167 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud-optimizer-cli/api"
+)
+
+// mockIssueAPI serves the GitHub issues endpoints of acme/infra from memory
+type mockIssueAPI struct {
+	t      *testing.T
+	mu     sync.Mutex
+	issues []map[string]interface{}
+}
+
+func (m *mockIssueAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/repos/acme/infra/issues" {
+		m.t.Errorf("request to %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	if got := r.Header.Get("Authorization"); got != "Bearer token" {
+		m.t.Errorf("authorization %q", got)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("state") != "all" {
+			m.t.Errorf("search for %s issues, want all", r.URL.Query().Get("state"))
+		}
+		label := r.URL.Query().Get("labels")
+		found := make([]map[string]interface{}, 0)
+		for _, issue := range m.issues {
+			for _, l := range issue["labels"].([]interface{}) {
+				if l == label {
+					found = append(found, issue)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(found)
+	case http.MethodPost:
+		var issue map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&issue); err != nil {
+			m.t.Error(err)
+		}
+		issue["html_url"] = fmt.Sprintf("https://github.com/acme/infra/issues/%d", len(m.issues)+1)
+		m.issues = append(m.issues, issue)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(issue)
+	}
+}
+
+func TestExportIssues(t *testing.T) {
+	mock := &mockIssueAPI{t: t}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+
+	exporter, err := NewGitHubIssues(srv.URL+"/", "acme/infra", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := NewIssueTemplate("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs := []api.Recommendation{
+		{ID: "rec-1", Action: api.ActionResize, ResourceID: "i-1", Description: "Downsize web", EstimatedSavings: 42.5, Details: map[string]interface{}{"instance_type": "m5.large"}},
+		{ID: "rec-2", Action: api.ActionTag, Description: "Tag logs", EstimatedSavings: 3},
+	}
+
+	results, err := ExportIssues(context.Background(), exporter, tmpl, recs[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []IssueResult{{RecommendationID: "rec-1", URL: "https://github.com/acme/infra/issues/1", Created: true}}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("first export %+v, want %+v", results, want)
+	}
+
+	// The second run files only the recommendation not yet exported
+	results, err = ExportIssues(context.Background(), exporter, tmpl, recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []IssueResult{
+		{RecommendationID: "rec-1", URL: "https://github.com/acme/infra/issues/1"},
+		{RecommendationID: "rec-2", URL: "https://github.com/acme/infra/issues/2", Created: true},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("second export %+v, want %+v", results, want)
+	}
+	if len(mock.issues) != 2 {
+		t.Fatalf("%d issues filed, want 2", len(mock.issues))
+	}
+
+	issue := mock.issues[0]
+	if issue["title"] != "[cloudopt] resize: Downsize web" || !reflect.DeepEqual(issue["labels"], []interface{}{"cloudopt:rec-1"}) {
+		t.Errorf("issue %v, want the rendered title labeled cloudopt:rec-1", issue)
+	}
+	for _, wantBody := range []string{"| Estimated savings | $42.50/month |", "| Resource | `i-1` |", "2. Resize `i-1` to `m5.large`."} {
+		if !strings.Contains(issue["body"].(string), wantBody) {
+			t.Errorf("issue body missing %q:\n%s", wantBody, issue["body"])
+		}
+	}
+}
+
+func TestExportIssuesError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	exporter, err := NewGitHubIssues(srv.URL, "acme/infra", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := NewIssueTemplate("{{.Recommendation.ID}}", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := ExportIssues(context.Background(), exporter, tmpl, []api.Recommendation{{ID: "rec-1"}, {ID: "rec-2"}})
+	if len(results) != 0 || err == nil || !strings.Contains(err.Error(), "recommendation rec-1: github returned 403 Forbidden: Resource not accessible by integration") {
+		t.Errorf("results %+v, error %v, want the first failure", results, err)
+	}
+}
+
+func TestNewGitHubIssuesInvalid(t *testing.T) {
+	tests := []struct {
+		repository string
+		token      string
+	}{
+		{repository: "infra", token: "token"},
+		{repository: "acme/", token: "token"},
+		{repository: "acme/infra/extra", token: "token"},
+		{repository: "acme/infra"},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewGitHubIssues(DefaultGitHubAPIURL, tt.repository, tt.token); err == nil {
+			t.Errorf("NewGitHubIssues(%q, %q) succeeded", tt.repository, tt.token)
+		}
+	}
+}
+
+func TestNewIssueTemplateInvalid(t *testing.T) {
+	if _, err := NewIssueTemplate("{{.Recommendation", ""); err == nil || !strings.Contains(err.Error(), "invalid issue title template") {
+		t.Errorf("NewIssueTemplate() = %v, want the title template error", err)
+	}
+	if _, err := NewIssueTemplate("", "{{end}}"); err == nil || !strings.Contains(err.Error(), "invalid issue body template") {
+		t.Errorf("NewIssueTemplate() = %v, want the body template error", err)
+	}
+}
@@ -0,0 +1,282 @@
+// Package export renders optimization recommendations and inventories as
+// infrastructure code and issues.
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/inventory"
+)
+
+// attrKind is the Terraform type of a placement attribute
+type attrKind int
+
+const (
+	kindNumber attrKind = iota
+	kindString
+	kindBool
+)
+
+// placementAttr is a type-specific placement attribute, read from the
+// recommendation details or else the resource metrics
+type placementAttr struct {
+	name     string
+	kind     attrKind
+	required bool
+}
+
+// placementSchema describes the attributes of one placement resource type
+type placementSchema struct {
+	attrs []placementAttr
+	// regions reports whether the resource accepts regions and preferred_providers
+	regions bool
+}
+
+var placementSchemas = map[string]placementSchema{
+	"compute": {
+		attrs: []placementAttr{
+			{name: "vcpus", kind: kindNumber, required: true},
+			{name: "memory_gb", kind: kindNumber, required: true},
+		},
+		regions: true,
+	},
+	"storage": {
+		attrs: []placementAttr{
+			{name: "capacity_gb", kind: kindNumber, required: true},
+			{name: "iops", kind: kindNumber},
+			{name: "throughput_mbps", kind: kindNumber},
+		},
+	},
+	"network": {
+		attrs: []placementAttr{
+			{name: "bandwidth_gbps", kind: kindNumber, required: true},
+			{name: "cross_region", kind: kindBool},
+		},
+	},
+	"database": {
+		attrs: []placementAttr{
+			{name: "engine", kind: kindString, required: true},
+			{name: "version", kind: kindString, required: true},
+		},
+	},
+}
+
+// placementTypes maps inventory resource types to placement resource types
+var placementTypes = map[string]string{
+	"compute":  "compute",
+	"instance": "compute",
+	"vm":       "compute",
+	"storage":  "storage",
+	"bucket":   "storage",
+	"disk":     "storage",
+	"volume":   "storage",
+	"network":  "network",
+	"vpc":      "network",
+	"database": "database",
+	"db":       "database",
+}
+
+var invalidLabelChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// Terraform writes HCL for the cloudoptimizer placement resources that codify
+// the recommendations, preceded by a provider block for endpoint. resources
+// holds the recommended resources by ID. Terminate recommendations produce a
+// comment instead of a resource, since the placement should be removed.
+func Terraform(w io.Writer, endpoint string, recs []api.Recommendation, resources map[string]inventory.Resource) error {
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	appendComment(body, "Generated by cloudopt export terraform. The API key is read from CLOUDOPTIMIZER_API_KEY.")
+	provider := body.AppendNewBlock("provider", []string{"cloudoptimizer"}).Body()
+	provider.SetAttributeValue("api_endpoint", cty.StringVal(endpoint))
+
+	labels := make(map[string]int)
+	for _, rec := range recs {
+		body.AppendNewline()
+
+		resource, ok := resources[rec.ResourceID]
+		if !ok {
+			resource = inventory.Resource{ID: rec.ResourceID}
+		}
+
+		if rec.Action == api.ActionTerminate {
+			appendComment(body, recommendationComment(rec))
+			appendComment(body, fmt.Sprintf("Terminate %s: remove its placement rather than adding one.", resourceName(rec, resource)))
+			continue
+		}
+
+		if err := appendPlacement(body, rec, resource, labels); err != nil {
+			return fmt.Errorf("recommendation %s: %v", rec.ID, err)
+		}
+	}
+
+	_, err := f.WriteTo(w)
+	return err
+}
+
+// appendPlacement adds the placement resource reflecting the recommended
+// configuration of the resource
+func appendPlacement(body *hclwrite.Body, rec api.Recommendation, resource inventory.Resource, labels map[string]int) error {
+	placementType, ok := placementTypes[strings.ToLower(resource.Type)]
+	if !ok {
+		placementType = "compute"
+	}
+	s := placementSchemas[placementType]
+
+	name := resourceName(rec, resource)
+	if name == "" {
+		return fmt.Errorf("resource has no name or ID")
+	}
+
+	appendComment(body, recommendationComment(rec))
+	block := body.AppendNewBlock("resource", []string{
+		"cloudoptimizer_" + placementType + "_placement",
+		uniqueLabel(name, labels),
+	}).Body()
+	block.SetAttributeValue("name", cty.StringVal(name))
+
+	for _, attr := range s.attrs {
+		v, ok, err := attrValue(attr, rec.Details, resource.Metrics)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if attr.required {
+				return fmt.Errorf("no value for required attribute %s", attr.name)
+			}
+			continue
+		}
+		block.SetAttributeValue(attr.name, v)
+	}
+
+	if s.regions {
+		region := resource.Region
+		if r, ok := rec.Details["region"].(string); ok && r != "" {
+			region = r
+		}
+		if region == "" {
+			return fmt.Errorf("no value for required attribute regions")
+		}
+		block.SetAttributeValue("regions", cty.SetVal([]cty.Value{cty.StringVal(region)}))
+
+		if p, ok := rec.Details["provider"].(string); ok && p != "" && rec.Action == api.ActionMigrate {
+			block.SetAttributeValue("preferred_providers", cty.SetVal([]cty.Value{cty.StringVal(p)}))
+		}
+	}
+
+	tags, err := recommendedTags(rec, resource)
+	if err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		block.SetAttributeValue("tags", cty.MapVal(tags))
+	}
+	return nil
+}
+
+// attrValue reads an attribute from the details, falling back to the metrics
+// for numbers
+func attrValue(attr placementAttr, details map[string]interface{}, metrics map[string]float64) (cty.Value, bool, error) {
+	raw, ok := details[attr.name]
+	if !ok {
+		if m, ok := metrics[attr.name]; ok && attr.kind == kindNumber {
+			return cty.NumberFloatVal(m), true, nil
+		}
+		return cty.NilVal, false, nil
+	}
+
+	switch attr.kind {
+	case kindNumber:
+		switch n := raw.(type) {
+		case float64:
+			return cty.NumberFloatVal(n), true, nil
+		case int:
+			return cty.NumberIntVal(int64(n)), true, nil
+		}
+	case kindString:
+		if s, ok := raw.(string); ok {
+			return cty.StringVal(s), true, nil
+		}
+	case kindBool:
+		if b, ok := raw.(bool); ok {
+			return cty.BoolVal(b), true, nil
+		}
+	}
+	return cty.NilVal, false, fmt.Errorf("invalid value for %s: %v", attr.name, raw)
+}
+
+// recommendedTags returns the resource's tags, with the recommended tags
+// applied for tag recommendations
+func recommendedTags(rec api.Recommendation, resource inventory.Resource) (map[string]cty.Value, error) {
+	tags := make(map[string]cty.Value, len(resource.Tags))
+	for k, v := range resource.Tags {
+		tags[k] = cty.StringVal(v)
+	}
+
+	if rec.Action != api.ActionTag {
+		return tags, nil
+	}
+
+	raw, ok := rec.Details["tags"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tag recommendation has no tags")
+	}
+	for k, v := range raw {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid value for tag %s: %v", k, v)
+		}
+		tags[k] = cty.StringVal(s)
+	}
+	return tags, nil
+}
+
+// recommendationComment describes the recommendation a block comes from
+func recommendationComment(rec api.Recommendation) string {
+	text := fmt.Sprintf("Recommendation %s (%s)", rec.ID, rec.Action)
+	if rec.Description != "" {
+		text += ": " + rec.Description
+	}
+	return text
+}
+
+// resourceName returns the name for the placement of the resource
+func resourceName(rec api.Recommendation, resource inventory.Resource) string {
+	if n, ok := rec.Details["name"].(string); ok && n != "" {
+		return n
+	}
+	if resource.Name != "" {
+		return resource.Name
+	}
+	return resource.ID
+}
+
+// uniqueLabel turns name into a valid Terraform resource label, numbering
+// repeats so every label is unique
+func uniqueLabel(name string, labels map[string]int) string {
+	label := invalidLabelChars.ReplaceAllString(name, "_")
+	if label == "" || (label[0] >= '0' && label[0] <= '9') || label[0] == '-' {
+		label = "r_" + label
+	}
+
+	labels[label]++
+	if n := labels[label]; n > 1 {
+		return fmt.Sprintf("%s_%d", label, n)
+	}
+	return label
+}
+
+// appendComment adds a line comment to the body
+func appendComment(body *hclwrite.Body, text string) {
+	body.AppendUnstructuredTokens(hclwrite.Tokens{
+		{Type: hclsyntax.TokenComment, Bytes: []byte("# " + text + "\n")},
+	})
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"cloud-optimizer-cli/api"
+	"cloud-optimizer-cli/inventory"
+)
+
+func TestTerraform(t *testing.T) {
+	resources := map[string]inventory.Resource{
+		"i-1": {ID: "i-1", Name: "web", Type: "instance", Region: "us-east-1", Tags: map[string]string{"team": "web"}, Metrics: map[string]float64{"vcpus": 8, "memory_gb": 32}},
+		"b-1": {ID: "b-1", Name: "logs", Type: "bucket", Metrics: map[string]float64{"capacity_gb": 500}},
+		"i-2": {ID: "i-2", Name: "batch", Type: "vm", Region: "eu-west-1"},
+	}
+	recs := []api.Recommendation{
+		{ID: "rec-1", Action: api.ActionResize, ResourceID: "i-1", Description: "Downsize web", Details: map[string]interface{}{"vcpus": 4.0, "memory_gb": 16.0}},
+		{ID: "rec-2", Action: api.ActionMigrate, ResourceID: "i-1", Details: map[string]interface{}{"provider": "gcp", "region": "europe-west3"}},
+		{ID: "rec-3", Action: api.ActionTag, ResourceID: "b-1", Details: map[string]interface{}{"tags": map[string]interface{}{"cost-center": "cc-1"}}},
+		{ID: "rec-4", Action: api.ActionTerminate, ResourceID: "i-2"},
+	}
+
+	var buf bytes.Buffer
+	if err := Terraform(&buf, "https://api.example.com", recs, resources); err != nil {
+		t.Fatal(err)
+	}
+
+	file, diags := hclparse.NewParser().ParseHCL(buf.Bytes(), "main.tf")
+	if diags.HasErrors() {
+		t.Fatalf("generated HCL does not parse: %v\n%s", diags, buf.String())
+	}
+
+	want := []struct {
+		labels []string
+		attrs  map[string]cty.Value
+	}{
+		{labels: []string{"cloudoptimizer"}, attrs: map[string]cty.Value{"api_endpoint": cty.StringVal("https://api.example.com")}},
+		{labels: []string{"cloudoptimizer_compute_placement", "web"}, attrs: map[string]cty.Value{
+			"name":      cty.StringVal("web"),
+			"vcpus":     cty.NumberIntVal(4),
+			"memory_gb": cty.NumberIntVal(16),
+			"regions":   cty.TupleVal([]cty.Value{cty.StringVal("us-east-1")}),
+			"tags":      cty.ObjectVal(map[string]cty.Value{"team": cty.StringVal("web")}),
+		}},
+		{labels: []string{"cloudoptimizer_compute_placement", "web_2"}, attrs: map[string]cty.Value{
+			"vcpus":               cty.NumberIntVal(8),
+			"regions":             cty.TupleVal([]cty.Value{cty.StringVal("europe-west3")}),
+			"preferred_providers": cty.TupleVal([]cty.Value{cty.StringVal("gcp")}),
+		}},
+		{labels: []string{"cloudoptimizer_storage_placement", "logs"}, attrs: map[string]cty.Value{
+			"capacity_gb": cty.NumberIntVal(500),
+			"tags":        cty.ObjectVal(map[string]cty.Value{"cost-center": cty.StringVal("cc-1")}),
+		}},
+	}
+
+	blocks := file.Body.(*hclsyntax.Body).Blocks
+	if len(blocks) != len(want) {
+		t.Fatalf("%d blocks, want %d:\n%s", len(blocks), len(want), buf.String())
+	}
+	for i, w := range want {
+		block := blocks[i]
+		if strings.Join(block.Labels, " ") != strings.Join(w.labels, " ") {
+			t.Errorf("block %d labels %v, want %v", i, block.Labels, w.labels)
+			continue
+		}
+		for name, wantValue := range w.attrs {
+			attr, ok := block.Body.Attributes[name]
+			if !ok {
+				t.Errorf("%v has no %s", w.labels, name)
+				continue
+			}
+			v, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				t.Errorf("%v %s: %v", w.labels, name, diags)
+				continue
+			}
+			if !v.Equals(wantValue).True() {
+				t.Errorf("%v %s = %#v, want %#v", w.labels, name, v, wantValue)
+			}
+		}
+	}
+
+	if !strings.Contains(buf.String(), "# Terminate batch") {
+		t.Errorf("terminate recommendation has no comment:\n%s", buf.String())
+	}
+}
+
+func TestTerraformMissingAttribute(t *testing.T) {
+	recs := []api.Recommendation{{ID: "rec-1", Action: api.ActionResize, ResourceID: "db-1"}}
+	resources := map[string]inventory.Resource{"db-1": {ID: "db-1", Type: "database"}}
+	err := Terraform(&bytes.Buffer{}, "https://api.example.com", recs, resources)
+	if err == nil || !strings.Contains(err.Error(), "engine") {
+		t.Errorf("Terraform() = %v, want the missing engine reported", err)
+	}
+}
@@ -0,0 +1,118 @@
+// Package inventory defines the versioned file format used to export and
+// import scanned cloud resource inventories.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SchemaVersion is the inventory file schema written by this CLI. Fields may
+// be added within a version; renaming or removing fields requires a bump.
+const SchemaVersion = 1
+
+// Inventory is the exported resource inventory
+type Inventory struct {
+	SchemaVersion int        `json:"schema_version"`
+	ExportedAt    time.Time  `json:"exported_at"`
+	Source        string     `json:"source,omitempty"`
+	Resources     []Resource `json:"resources"`
+}
+
+// Resource is a scanned cloud resource
+type Resource struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Type         string             `json:"type"`
+	Provider     string             `json:"provider"`
+	Region       string             `json:"region"`
+	InstanceType string             `json:"instance_type,omitempty"`
+	Tags         map[string]string  `json:"tags,omitempty"`
+	Cost         float64            `json:"cost"`
+	Metrics      map[string]float64 `json:"metrics,omitempty"`
+	DiscoveredAt time.Time          `json:"discovered_at"`
+}
+
+// New creates an inventory of the given resources at the current schema version
+func New(source string, resources []Resource) *Inventory {
+	if resources == nil {
+		resources = []Resource{}
+	}
+	return &Inventory{
+		SchemaVersion: SchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Source:        source,
+		Resources:     resources,
+	}
+}
+
+// Write encodes the inventory as indented JSON
+func Write(w io.Writer, inv *Inventory) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(inv); err != nil {
+		return fmt.Errorf("failed to encode inventory: %v", err)
+	}
+	return nil
+}
+
+// Read decodes an inventory and validates its schema version
+func Read(r io.Reader) (*Inventory, error) {
+	var inv Inventory
+	if err := json.NewDecoder(r).Decode(&inv); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory: %v", err)
+	}
+
+	if err := inv.Validate(); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// Validate checks that the inventory was written with a compatible schema
+// and that every resource is identifiable
+func (inv *Inventory) Validate() error {
+	if inv.SchemaVersion != SchemaVersion {
+		return fmt.Errorf("incompatible inventory schema version %d (expected %d)", inv.SchemaVersion, SchemaVersion)
+	}
+
+	for i, r := range inv.Resources {
+		if r.ID == "" {
+			return fmt.Errorf("resource %d has no id", i)
+		}
+		if r.Provider == "" {
+			return fmt.Errorf("resource %s has no provider", r.ID)
+		}
+	}
+	return nil
+}
+
+// Save writes the inventory to path, creating parent directories as needed
+func Save(path string, inv *Inventory) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create inventory directory: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create inventory file: %v", err)
+	}
+	defer f.Close()
+
+	return Write(f, inv)
+}
+
+// Load reads and validates the inventory at path
+func Load(path string) (*Inventory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inventory file: %v", err)
+	}
+	defer f.Close()
+
+	return Read(f)
+}
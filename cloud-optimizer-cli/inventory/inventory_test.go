@@ -0,0 +1,72 @@
+package inventory
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoad(t *testing.T) {
+	inv := New("aws", []Resource{
+		{ID: "i-1", Name: "web", Type: "instance", Provider: "aws", Region: "us-east-1", Tags: map[string]string{"team": "web"}, Cost: 70.08,
+			Metrics: map[string]float64{"vcpus": 2}, DiscoveredAt: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	path := filepath.Join(t.TempDir(), "exports", "inventory.json")
+	if err := Save(path, inv); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, inv) {
+		t.Errorf("loaded %+v, want %+v", got, inv)
+	}
+
+	if empty := New("", nil); empty.Resources == nil || empty.SchemaVersion != SchemaVersion {
+		t.Errorf("New() = %+v, want an empty resource list at the current schema", empty)
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "valid", data: `{"schema_version":1,"resources":[{"id":"i-1","provider":"aws"}],"added":true}`},
+		{name: "malformed", data: `{"schema_version":`, wantErr: "failed to parse inventory"},
+		{name: "newer schema", data: `{"schema_version":2,"resources":[]}`, wantErr: "incompatible inventory schema version 2"},
+		{name: "no id", data: `{"schema_version":1,"resources":[{"provider":"aws"}]}`, wantErr: "resource 0 has no id"},
+		{name: "no provider", data: `{"schema_version":1,"resources":[{"id":"i-1"}]}`, wantErr: "resource i-1 has no provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Read() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Read() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, New("gcp", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\n  \"schema_version\": 1,\n") || !strings.Contains(buf.String(), `"resources": []`) {
+		t.Errorf("written inventory is not indented JSON with an empty resource list:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultChartWidth is the width in characters of the longest bar
+const DefaultChartWidth = 40
+
+// Bar is one labelled value of a bar chart
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// BarChart renders horizontal bars scaled to the largest value, each followed
+// by its value formatted with valueFormat (e.g. "%.2f")
+func BarChart(w io.Writer, bars []Bar, width int, valueFormat string) error {
+	if width <= 0 {
+		width = DefaultChartWidth
+	}
+
+	labelWidth := 0
+	max := 0.0
+	for _, b := range bars {
+		if len(b.Label) > labelWidth {
+			labelWidth = len(b.Label)
+		}
+		if b.Value > max {
+			max = b.Value
+		}
+	}
+
+	for _, b := range bars {
+		length := 0
+		if max > 0 && b.Value > 0 {
+			length = int(b.Value / max * float64(width))
+			if length == 0 {
+				length = 1
+			}
+		}
+
+		bar := strings.Repeat("█", length) + strings.Repeat(" ", width-length)
+		if _, err := fmt.Fprintf(w, "%-*s │%s "+valueFormat+"\n", labelWidth, b.Label, bar, b.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
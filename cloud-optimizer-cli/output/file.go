@@ -0,0 +1,88 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Target is a destination for command output: stdout or a file. JSON written
+// to a file in append mode is emitted as one record per line so repeated runs
+// produce newline-delimited JSON.
+type Target struct {
+	w      io.Writer
+	closer io.Closer
+	ndjson bool
+}
+
+// NewTarget returns a target writing to w
+func NewTarget(w io.Writer) *Target {
+	return &Target{w: w}
+}
+
+// OpenFile returns a target writing to path, creating parent directories as
+// needed. The file is truncated unless appendMode is set.
+func OpenFile(path string, appendMode bool) (*Target, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %v", err)
+	}
+	return &Target{w: f, closer: f, ndjson: appendMode}, nil
+}
+
+// Write renders v to the target in the given format
+func (t *Target) Write(format string, v interface{}, text TextFunc) error {
+	if format == FormatJSON && t.ndjson {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode json: %v", err)
+		}
+		_, err = t.w.Write(append(data, '\n'))
+		return err
+	}
+	return Write(t.w, format, v, text)
+}
+
+// WriteRecord renders v as one record of a stream written a record at a time:
+// json as a single line, yaml as an element of a top-level sequence and text
+// with text
+func (t *Target) WriteRecord(format string, v interface{}, text TextFunc) error {
+	switch format {
+	case FormatJSON:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode json: %v", err)
+		}
+		_, err = t.w.Write(append(data, '\n'))
+		return err
+	case FormatYAML:
+		data, err := yaml.Marshal([]interface{}{v})
+		if err != nil {
+			return fmt.Errorf("failed to encode yaml: %v", err)
+		}
+		_, err = t.w.Write(data)
+		return err
+	}
+	return Write(t.w, format, v, text)
+}
+
+// Close closes the underlying file, if any
+func (t *Target) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
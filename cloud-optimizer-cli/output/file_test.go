@@ -0,0 +1,59 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenFile(t *testing.T) {
+	type record struct {
+		ID   string `json:"id"`
+		Cost int    `json:"cost"`
+	}
+
+	tests := []struct {
+		name       string
+		appendMode bool
+		format     string
+		want       string
+	}{
+		{name: "truncate json", format: FormatJSON, want: "{\n  \"id\": \"b\",\n  \"cost\": 2\n}\n"},
+		{name: "append json", appendMode: true, format: FormatJSON, want: "{\"id\":\"a\",\"cost\":1}\n{\"id\":\"b\",\"cost\":2}\n"},
+		{name: "truncate yaml", format: FormatYAML, want: "id: b\ncost: 2\n"},
+		{name: "append yaml", appendMode: true, format: FormatYAML, want: "id: a\ncost: 1\nid: b\ncost: 2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "reports", "sept", "costs.out")
+			for _, r := range []record{{ID: "a", Cost: 1}, {ID: "b", Cost: 2}} {
+				target, err := OpenFile(path, tt.appendMode)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := target.Write(tt.format, r, nil); err != nil {
+					t.Fatal(err)
+				}
+				if err := target.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("file contents %q, want %q", data, tt.want)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm&^0644 != 0 {
+				t.Errorf("file mode %v, want at most 0644", perm)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale holds the number, currency and date conventions used to format text
+// output in one locale
+type Locale struct {
+	// Tag is the BCP 47 language tag, e.g. "de-DE"
+	Tag     string
+	decimal string
+	group   string
+	// symbolAfter places the currency symbol after the amount ("1.234,56 €")
+	// rather than before it ("€1,234.56"), separated by symbolSpace
+	symbolAfter    bool
+	symbolSpace    string
+	dateLayout     string
+	dateTimeLayout string
+}
+
+var locales = map[string]Locale{
+	"en-US": {Tag: "en-US", decimal: ".", group: ",", dateLayout: "01/02/2006", dateTimeLayout: "01/02/2006 3:04 PM"},
+	"en-GB": {Tag: "en-GB", decimal: ".", group: ",", dateLayout: "02/01/2006", dateTimeLayout: "02/01/2006 15:04"},
+	"de-DE": {Tag: "de-DE", decimal: ",", group: ".", symbolAfter: true, symbolSpace: "\u00a0", dateLayout: "02.01.2006", dateTimeLayout: "02.01.2006 15:04"},
+	"fr-FR": {Tag: "fr-FR", decimal: ",", group: "\u202f", symbolAfter: true, symbolSpace: "\u00a0", dateLayout: "02/01/2006", dateTimeLayout: "02/01/2006 15:04"},
+	"ja-JP": {Tag: "ja-JP", decimal: ".", group: ",", dateLayout: "2006/01/02", dateTimeLayout: "2006/01/02 15:04"},
+}
+
+// languageDefaults maps a bare language to the locale used for it
+var languageDefaults = map[string]string{
+	"en": "en-US",
+	"de": "de-DE",
+	"fr": "fr-FR",
+	"ja": "ja-JP",
+}
+
+// currencySymbols are the symbols of well-known currencies; other currencies
+// are written with their ISO code
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+	"CAD": "CA$",
+	"AUD": "A$",
+}
+
+// currencyDigits overrides the two fraction digits used for most currencies
+var currencyDigits = map[string]int{
+	"JPY": 0,
+}
+
+// SupportedLocales returns the tags of the supported locales, sorted
+func SupportedLocales() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// LookupLocale returns the locale for a tag, matching case-insensitively and
+// falling back from an unsupported region (e.g. "de-AT") to the language's
+// default locale
+func LookupLocale(tag string) (Locale, error) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	for t, l := range locales {
+		if strings.EqualFold(t, tag) {
+			return l, nil
+		}
+	}
+
+	language, _, _ := strings.Cut(tag, "-")
+	if t, ok := languageDefaults[strings.ToLower(language)]; ok {
+		return locales[t], nil
+	}
+	return Locale{}, fmt.Errorf("unsupported locale: %q (supported: %s)", tag, strings.Join(SupportedLocales(), ", "))
+}
+
+// Number formats v with the locale's separators and the given number of
+// fraction digits
+func (l Locale) Number(v float64, digits int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', digits, 64)
+	whole, fraction, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteString("-")
+	}
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.group)
+		}
+		b.WriteRune(r)
+	}
+	if fraction != "" {
+		b.WriteString(l.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// Money formats an amount in the currency, e.g. "$1,234.56" for en-US or
+// "1.234,56 €" for de-DE. An empty currency is treated as USD.
+func (l Locale) Money(amount float64, currency string) string {
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = "USD"
+	}
+
+	digits, ok := currencyDigits[currency]
+	if !ok {
+		digits = 2
+	}
+	number := l.Number(amount, digits)
+
+	symbol, known := currencySymbols[currency]
+	space := l.symbolSpace
+	if !known {
+		// ISO codes are always set apart from the amount
+		symbol, space = currency, "\u00a0"
+	}
+
+	if l.symbolAfter {
+		return number + space + symbol
+	}
+	if rest, negative := strings.CutPrefix(number, "-"); negative {
+		return "-" + symbol + space + rest
+	}
+	return symbol + space + number
+}
+
+// Date formats the calendar date of t
+func (l Locale) Date(t time.Time) string {
+	return t.Format(l.dateLayout)
+}
+
+// DateTime formats the date and time of day of t
+func (l Locale) DateTime(t time.Time) string {
+	return t.Format(l.dateTimeLayout)
+}
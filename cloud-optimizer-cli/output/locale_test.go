@@ -0,0 +1,72 @@
+package output
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookupLocale(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "en-GB", want: "en-GB"},
+		{tag: "de_de", want: "de-DE"},
+		{tag: "fr-CA", want: "fr-FR"},
+		{tag: "es-ES", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		l, err := LookupLocale(tt.tag)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LookupLocale(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			continue
+		}
+		if l.Tag != tt.want {
+			t.Errorf("LookupLocale(%q) = %s, want %s", tt.tag, l.Tag, tt.want)
+		}
+	}
+}
+
+// The same amount and date are written in each locale's conventions
+func TestLocaleFormat(t *testing.T) {
+	date := time.Date(2026, 3, 4, 17, 5, 0, 0, time.UTC)
+	tests := []struct {
+		tag      string
+		money    string
+		negative string
+		number   string
+		date     string
+		dateTime string
+	}{
+		{tag: "en-US", money: "$1,234,567.89", negative: "-£12.50", number: "1,234,567.89", date: "03/04/2026", dateTime: "03/04/2026 5:05 PM"},
+		{tag: "en-GB", money: "$1,234,567.89", negative: "-£12.50", number: "1,234,567.89", date: "04/03/2026", dateTime: "04/03/2026 17:05"},
+		{tag: "de-DE", money: "1.234.567,89\u00a0$", negative: "-12,50\u00a0£", number: "1.234.567,89", date: "04.03.2026", dateTime: "04.03.2026 17:05"},
+		{tag: "ja-JP", money: "$1,234,567.89", negative: "-£12.50", number: "1,234,567.89", date: "2026/03/04", dateTime: "2026/03/04 17:05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			l, err := LookupLocale(tt.tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := l.Money(1234567.891, "usd"); got != tt.money {
+				t.Errorf("Money() = %q, want %q", got, tt.money)
+			}
+			if got := l.Money(-12.5, "GBP"); got != tt.negative {
+				t.Errorf("Money() = %q, want %q", got, tt.negative)
+			}
+			if got := l.Number(1234567.891, 2); got != tt.number {
+				t.Errorf("Number() = %q, want %q", got, tt.number)
+			}
+			if got := l.Date(date); got != tt.date {
+				t.Errorf("Date() = %q, want %q", got, tt.date)
+			}
+			if got := l.DateTime(date); got != tt.dateTime {
+				t.Errorf("DateTime() = %q, want %q", got, tt.dateTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Package output renders command results in the formats supported by the CLI.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// TextFunc renders a result as human-readable text
+type TextFunc func(w io.Writer) error
+
+// ValidateFormat checks that format is a supported output format
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON, FormatYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output type: %s (must be text, json, or yaml)", format)
+}
+
+// Write renders v in the given format. Text output is produced by text; json
+// and yaml output encode v directly.
+func Write(w io.Writer, format string, v interface{}, text TextFunc) error {
+	switch format {
+	case FormatText:
+		return text(w)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode json: %v", err)
+		}
+		return nil
+	case FormatYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode yaml: %v", err)
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return ValidateFormat(format)
+}
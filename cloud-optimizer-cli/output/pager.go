@@ -0,0 +1,71 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultPager is the pager used when neither preferences.pager nor $PAGER
+// is set
+const DefaultPager = "less"
+
+// defaultLessOptions make less quit when the output fits on one screen,
+// keep colors and leave the output on screen after quitting, as git does
+const defaultLessOptions = "FRX"
+
+// PagerCommand returns the pager command line: the configured one, else
+// $PAGER, else DefaultPager
+func PagerCommand(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if pager := os.Getenv("PAGER"); pager != "" {
+		return pager
+	}
+	return DefaultPager
+}
+
+// ShouldPage reports whether output is paged: only text output to a
+// terminal, and only when it has more lines than the terminal's height. json
+// and yaml output is never paged so it can be read by other programs.
+func ShouldPage(format string, terminal bool, lines, height int) bool {
+	return format == FormatText && terminal && height > 0 && lines > height
+}
+
+// CountLines returns the number of lines data takes, counting a final line
+// without a newline
+func CountLines(data []byte) int {
+	lines := bytes.Count(data, []byte("\n"))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// Page writes data through the pager command, which reads it on stdin and
+// writes to stdout and stderr. LESS defaults to FRX when unset. An error
+// means the pager could not be started and nothing was shown; how the pager
+// exits once the reader quits it is not an error.
+func Page(command string, data []byte, stdout, stderr io.Writer) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return fmt.Errorf("pager command is empty")
+	}
+
+	pager := exec.Command(args[0], args[1:]...)
+	pager.Stdin = bytes.NewReader(data)
+	pager.Stdout = stdout
+	pager.Stderr = stderr
+	if os.Getenv("LESS") == "" {
+		pager.Env = append(os.Environ(), "LESS="+defaultLessOptions)
+	}
+	if err := pager.Start(); err != nil {
+		return fmt.Errorf("failed to start pager %s: %v", args[0], err)
+	}
+	pager.Wait()
+	return nil
+}
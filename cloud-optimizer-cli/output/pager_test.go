@@ -0,0 +1,90 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestShouldPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		terminal bool
+		lines    int
+		height   int
+		want     bool
+	}{
+		{name: "taller than the terminal", format: FormatText, terminal: true, lines: 41, height: 40, want: true},
+		{name: "fits the terminal", format: FormatText, terminal: true, lines: 40, height: 40},
+		{name: "not a terminal", format: FormatText, lines: 100, height: 40},
+		{name: "unknown height", format: FormatText, terminal: true, lines: 100},
+		{name: "json", format: FormatJSON, terminal: true, lines: 100, height: 40},
+		{name: "yaml", format: FormatYAML, terminal: true, lines: 100, height: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldPage(tt.format, tt.terminal, tt.lines, tt.height); got != tt.want {
+				t.Errorf("ShouldPage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{data: "", want: 0},
+		{data: "one\n", want: 1},
+		{data: "one\ntwo", want: 2},
+		{data: "one\n\nthree\n", want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := CountLines([]byte(tt.data)); got != tt.want {
+			t.Errorf("CountLines(%q) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestPagerCommand(t *testing.T) {
+	t.Setenv("PAGER", "")
+	if got := PagerCommand(""); got != DefaultPager {
+		t.Errorf("PagerCommand() = %q, want %q", got, DefaultPager)
+	}
+	t.Setenv("PAGER", "more")
+	if got := PagerCommand(""); got != "more" {
+		t.Errorf("PagerCommand() with $PAGER = %q, want more", got)
+	}
+	if got := PagerCommand("less -S"); got != "less -S" {
+		t.Errorf("configured PagerCommand() = %q, want less -S", got)
+	}
+}
+
+func TestPage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Page("cat -", []byte("line 1\nline 2\n"), &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "line 1\nline 2\n" {
+		t.Errorf("pager wrote %q", stdout.String())
+	}
+
+	t.Setenv("LESS", "")
+	stdout.Reset()
+	if err := Page("env", nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "LESS="+defaultLessOptions+"\n") {
+		t.Errorf("pager environment lacks LESS=%s:\n%s", defaultLessOptions, stdout.String())
+	}
+
+	for _, command := range []string{"", "  ", "cloudopt-no-such-pager"} {
+		if err := Page(command, []byte("x"), &stdout, &stderr); err == nil {
+			t.Errorf("Page(%q) succeeded", command)
+		}
+	}
+}
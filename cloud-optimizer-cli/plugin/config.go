@@ -0,0 +1,143 @@
+package plugin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config key types, named like the flag types of the CLI
+const (
+	TypeString      = "string"
+	TypeInt         = "int"
+	TypeFloat       = "float64"
+	TypeBool        = "bool"
+	TypeDuration    = "duration"
+	TypeStringSlice = "stringSlice"
+)
+
+// ConfigSchemaProvider is implemented by plugins that declare the keys of their
+// manifest config, each described like a flag: its name, type, whether it is
+// required and its default. The Manager validates the config against the
+// schema before Initialize, so the plugin only sees declared keys of the
+// declared types.
+type ConfigSchemaProvider interface {
+	ConfigSchema() []Flag
+}
+
+// ValidateConfig checks config against schema. It returns a copy of config
+// with the defaults of absent keys filled in and int values, which JSON
+// decodes as float64, converted to int. Keys the schema does not declare are
+// rejected.
+func ValidateConfig(schema []Flag, config map[string]any) (map[string]any, error) {
+	validated := make(map[string]any, len(schema))
+	declared := make(map[string]bool, len(schema))
+	for _, key := range schema {
+		if !validType(key.Type) {
+			return nil, fmt.Errorf("schema key %q has unsupported type %q", key.Name, key.Type)
+		}
+		declared[key.Name] = true
+
+		value, ok := config[key.Name]
+		if !ok || value == nil {
+			if key.Required {
+				return nil, fmt.Errorf("config.%s is required", key.Name)
+			}
+			if key.Default != nil {
+				validated[key.Name] = key.Default
+			}
+			continue
+		}
+
+		v, err := convertConfigValue(key.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("config.%s: %v", key.Name, err)
+		}
+		validated[key.Name] = v
+	}
+
+	var unknown []string
+	for name := range config {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("config keys not declared by the plugin: %s", strings.Join(unknown, ", "))
+	}
+	return validated, nil
+}
+
+func validType(t string) bool {
+	switch t {
+	case TypeString, TypeInt, TypeFloat, TypeBool, TypeDuration, TypeStringSlice:
+		return true
+	}
+	return false
+}
+
+// convertConfigValue checks that a decoded JSON value has the given type and
+// returns it in the Go type plugins receive for it
+func convertConfigValue(t string, value any) (any, error) {
+	switch t {
+	case TypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case TypeInt:
+		if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) <= math.MaxInt32 {
+			return int(f), nil
+		}
+		if i, ok := value.(int); ok {
+			return i, nil
+		}
+	case TypeFloat:
+		if f, ok := value.(float64); ok {
+			return f, nil
+		}
+	case TypeBool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case TypeDuration:
+		if s, ok := value.(string); ok {
+			if _, err := time.ParseDuration(s); err != nil {
+				return nil, fmt.Errorf("expected duration, got %q", s)
+			}
+			return s, nil
+		}
+	case TypeStringSlice:
+		if items, ok := value.([]any); ok {
+			strs := make([]string, len(items))
+			for i, item := range items {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected stringSlice, got %s at index %d", describeValue(item), i)
+				}
+				strs[i] = s
+			}
+			return strs, nil
+		}
+	}
+	return nil, fmt.Errorf("expected %s, got %s", t, describeValue(value))
+}
+
+// describeValue names a decoded JSON value's type and value for errors
+func describeValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64:
+		return fmt.Sprintf("number %v", v)
+	case bool:
+		return fmt.Sprintf("bool %v", v)
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	schema := []Flag{
+		{Name: "api_endpoint", Type: TypeString, Required: true},
+		{Name: "refresh_interval", Type: TypeInt, Default: 300},
+		{Name: "threshold", Type: TypeFloat},
+		{Name: "verbose", Type: TypeBool, Default: false},
+		{Name: "timeout", Type: TypeDuration},
+		{Name: "regions", Type: TypeStringSlice},
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			config: `{"api_endpoint":"https://api.example.com","refresh_interval":60,"threshold":0.5,"timeout":"30s","regions":["us-east-1","eu-west-1"]}`,
+			want: map[string]any{
+				"api_endpoint":     "https://api.example.com",
+				"refresh_interval": 60,
+				"threshold":        0.5,
+				"verbose":          false,
+				"timeout":          "30s",
+				"regions":          []string{"us-east-1", "eu-west-1"},
+			},
+		},
+		{
+			name:   "defaults",
+			config: `{"api_endpoint":"https://api.example.com","threshold":null}`,
+			want:   map[string]any{"api_endpoint": "https://api.example.com", "refresh_interval": 300, "verbose": false},
+		},
+		{name: "missing required key", config: `{"refresh_interval":60}`, wantErr: "config.api_endpoint is required"},
+		{name: "null required key", config: `{"api_endpoint":null}`, wantErr: "config.api_endpoint is required"},
+		{name: "string for int", config: `{"api_endpoint":"x","refresh_interval":"60"}`, wantErr: `config.refresh_interval: expected int, got string "60"`},
+		{name: "fraction for int", config: `{"api_endpoint":"x","refresh_interval":1.5}`, wantErr: "config.refresh_interval: expected int, got number 1.5"},
+		{name: "number for bool", config: `{"api_endpoint":"x","verbose":1}`, wantErr: "config.verbose: expected bool, got number 1"},
+		{name: "object for string", config: `{"api_endpoint":{"url":"x"}}`, wantErr: "config.api_endpoint: expected string, got object"},
+		{name: "invalid duration", config: `{"api_endpoint":"x","timeout":"soon"}`, wantErr: `config.timeout: expected duration, got "soon"`},
+		{name: "mixed slice", config: `{"api_endpoint":"x","regions":["us-east-1",2]}`, wantErr: "config.regions: expected stringSlice, got number 2 at index 1"},
+		{name: "undeclared keys", config: `{"api_endpoint":"x","b_key":1,"a_key":2}`, wantErr: "config keys not declared by the plugin: a_key, b_key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config map[string]any
+			if err := json.Unmarshal([]byte(tt.config), &config); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ValidateConfig(schema, config)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ValidateConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateConfig() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigSchema(t *testing.T) {
+	_, err := ValidateConfig([]Flag{{Name: "size", Type: "uint"}}, nil)
+	if err == nil || err.Error() != `schema key "size" has unsupported type "uint"` {
+		t.Errorf("ValidateConfig() error = %v, want the unsupported type", err)
+	}
+
+	// Validation leaves the manifest's config untouched
+	config := map[string]any{"refresh_interval": float64(60)}
+	if _, err := ValidateConfig([]Flag{{Name: "refresh_interval", Type: TypeInt}}, config); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := config["refresh_interval"].(float64); !ok {
+		t.Errorf("config changed to %#v", config)
+	}
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// HealthChecker is implemented by plugins that can report whether they are
+// still able to serve requests. Long-lived plugins backed by an external
+// process should implement it so the Manager notices when they die.
+type HealthChecker interface {
+	HealthCheck() error
+}
+
+// Restarter is implemented by plugins that can recover from an unhealthy
+// state, for example by relaunching their backing process
+type Restarter interface {
+	Restart() error
+}
+
+// HealthStatus is the result of a plugin's most recent health check
+type HealthStatus string
+
+// Health statuses. Plugins that do not implement HealthChecker stay unchecked.
+const (
+	HealthUnchecked HealthStatus = "unchecked"
+	HealthHealthy   HealthStatus = "healthy"
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// PluginHealth records the health of a loaded plugin
+type PluginHealth struct {
+	Status    HealthStatus `json:"status"`
+	Error     string       `json:"error,omitempty"`
+	CheckedAt time.Time    `json:"checked_at,omitempty"`
+	// Restarts counts the restarts attempted after failed health checks
+	Restarts int `json:"restarts,omitempty"`
+}
+
+// CheckHealth probes every loaded plugin that implements HealthChecker and
+// records the result. When restart is set, an unhealthy plugin that
+// implements Restarter is restarted and probed again.
+func (m *Manager) CheckHealth(restart bool) {
+	m.mu.RLock()
+	instances := make(map[string]PluginInstance, len(m.plugins))
+	for name, p := range m.plugins {
+		instances[name] = p.Instance
+	}
+	m.mu.RUnlock()
+
+	// Probes run without the lock so a hung plugin cannot block the Manager
+	for name, instance := range instances {
+		checker, ok := instance.(HealthChecker)
+		if !ok {
+			continue
+		}
+
+		err := checker.HealthCheck()
+		restarted := false
+		if err != nil && restart {
+			if r, ok := instance.(Restarter); ok {
+				restarted = true
+				if rerr := r.Restart(); rerr != nil {
+					err = fmt.Errorf("%v (restart failed: %v)", err, rerr)
+				} else {
+					err = checker.HealthCheck()
+				}
+			}
+		}
+
+		m.recordHealth(name, instance, err, restarted)
+	}
+}
+
+// recordHealth stores a health check result, unless the plugin was unloaded
+// or replaced while it was being probed
+func (m *Manager) recordHealth(name string, instance PluginInstance, err error, restarted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, exists := m.plugins[name]
+	if !exists || p.Instance != instance {
+		return
+	}
+
+	p.Health.Status = HealthHealthy
+	p.Health.Error = ""
+	if err != nil {
+		p.Health.Status = HealthUnhealthy
+		p.Health.Error = err.Error()
+	}
+	p.Health.CheckedAt = time.Now().UTC()
+	if restarted {
+		p.Health.Restarts++
+	}
+}
+
+// RunHealthChecks calls CheckHealth every interval until ctx is done
+func (m *Manager) RunHealthChecks(ctx context.Context, interval time.Duration, restart bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.CheckHealth(restart)
+		}
+	}
+}
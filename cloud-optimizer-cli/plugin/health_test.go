@@ -0,0 +1,173 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// basicPlugin implements only PluginInstance
+type basicPlugin struct{}
+
+func (basicPlugin) Initialize(map[string]any) error { return nil }
+func (basicPlugin) Execute([]string) (any, error)   { return nil, nil }
+func (basicPlugin) GetCommands() []Command          { return nil }
+func (basicPlugin) Cleanup() error                  { return nil }
+
+// checkedPlugin reports the health it is given
+type checkedPlugin struct {
+	basicPlugin
+	mu  sync.Mutex
+	err error
+}
+
+func (p *checkedPlugin) HealthCheck() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// grpcPlugin stands in for a plugin backed by a gRPC process, which a restart
+// relaunches
+type grpcPlugin struct {
+	checkedPlugin
+	restartErr error
+	restarts   int
+}
+
+func (p *grpcPlugin) Restart() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.restarts++
+	if p.restartErr != nil {
+		return p.restartErr
+	}
+	p.err = nil
+	return nil
+}
+
+// managerWith returns a Manager holding the instances as loaded plugins
+func managerWith(instances map[string]PluginInstance) *Manager {
+	m := NewManager()
+	for name, instance := range instances {
+		m.plugins[name] = &Plugin{Name: name, Instance: instance, Health: PluginHealth{Status: HealthUnchecked}}
+	}
+	return m
+}
+
+// health returns the health ListPlugins reports for each plugin
+func health(m *Manager) map[string]PluginHealth {
+	result := make(map[string]PluginHealth)
+	for _, p := range m.ListPlugins() {
+		result[p.Name] = p.Health
+	}
+	return result
+}
+
+func TestCheckHealth(t *testing.T) {
+	dead := errors.New("connection refused")
+
+	tests := []struct {
+		name         string
+		instance     PluginInstance
+		restart      bool
+		wantStatus   HealthStatus
+		wantError    string
+		wantRestarts int
+	}{
+		{name: "no health check", instance: basicPlugin{}, wantStatus: HealthUnchecked},
+		{name: "healthy", instance: &checkedPlugin{}, wantStatus: HealthHealthy},
+		{name: "unhealthy", instance: &checkedPlugin{err: dead}, wantStatus: HealthUnhealthy, wantError: "connection refused"},
+		{name: "unhealthy without restarting", instance: &grpcPlugin{checkedPlugin: checkedPlugin{err: dead}}, wantStatus: HealthUnhealthy, wantError: "connection refused"},
+		{name: "cannot restart", instance: &checkedPlugin{err: dead}, restart: true, wantStatus: HealthUnhealthy, wantError: "connection refused"},
+		{name: "restarted", instance: &grpcPlugin{checkedPlugin: checkedPlugin{err: dead}}, restart: true, wantStatus: HealthHealthy, wantRestarts: 1},
+		{
+			name:         "restart failed",
+			instance:     &grpcPlugin{checkedPlugin: checkedPlugin{err: dead}, restartErr: errors.New("binary missing")},
+			restart:      true,
+			wantStatus:   HealthUnhealthy,
+			wantError:    "connection refused (restart failed: binary missing)",
+			wantRestarts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := managerWith(map[string]PluginInstance{"p": tt.instance})
+			m.CheckHealth(tt.restart)
+
+			got := health(m)["p"]
+			if got.Status != tt.wantStatus || got.Error != tt.wantError || got.Restarts != tt.wantRestarts {
+				t.Errorf("health %+v, want status %s, error %q and %d restarts", got, tt.wantStatus, tt.wantError, tt.wantRestarts)
+			}
+			if (got.Status == HealthUnchecked) != got.CheckedAt.IsZero() {
+				t.Errorf("health %+v checked at %v", got, got.CheckedAt)
+			}
+			if g, ok := tt.instance.(*grpcPlugin); ok && g.restarts != tt.wantRestarts {
+				t.Errorf("%d restarts attempted, want %d", g.restarts, tt.wantRestarts)
+			}
+		})
+	}
+}
+
+// A plugin that dies between checks is reported unhealthy by the next one
+func TestRunHealthChecks(t *testing.T) {
+	p := &checkedPlugin{}
+	m := managerWith(map[string]PluginInstance{"p": p})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.RunHealthChecks(ctx, 5*time.Millisecond, false)
+		close(done)
+	}()
+
+	waitForStatus := func(want HealthStatus) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for health(m)["p"].Status != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("status %s, want %s", health(m)["p"].Status, want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitForStatus(HealthHealthy)
+
+	p.mu.Lock()
+	p.err = errors.New("exited")
+	p.mu.Unlock()
+	waitForStatus(HealthUnhealthy)
+	if got := health(m)["p"].Error; !strings.Contains(got, "exited") {
+		t.Errorf("error %q, want the health check's", got)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunHealthChecks did not return after cancellation")
+	}
+}
+
+// A result is dropped when the plugin was unloaded while being probed
+func TestRecordHealthUnloaded(t *testing.T) {
+	p := &checkedPlugin{}
+	m := managerWith(map[string]PluginInstance{"p": p})
+	if err := m.UnloadPlugin("p"); err != nil {
+		t.Fatal(err)
+	}
+	m.recordHealth("p", p, nil, false)
+	if len(m.ListPlugins()) != 0 {
+		t.Error("health of an unloaded plugin recorded")
+	}
+
+	m = managerWith(map[string]PluginInstance{"p": &checkedPlugin{}})
+	m.recordHealth("p", p, errors.New("stale"), false)
+	if got := health(m)["p"]; got.Status != HealthUnchecked {
+		t.Errorf("health of a replaced instance recorded: %+v", got)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"plugin"
+	goplugin "plugin"
 	"sync"
 )
 
@@ -18,6 +18,8 @@ type Plugin struct {
 	EntryPoint  string          `json:"entry_point"`
 	Config      map[string]any  `json:"config"`
 	Instance    PluginInstance  `json:"-"`
+	// Health is maintained by the Manager and not read from the manifest
+	Health PluginHealth `json:"health"`
 }
 
 // PluginInstance represents the interface that all plugins must implement
@@ -86,7 +88,7 @@ func (m *Manager) LoadPlugin(manifestPath string) error {
 
 	// Load the plugin binary
 	pluginPath := filepath.Join(filepath.Dir(manifestPath), plugin.EntryPoint)
-	p, err := plugin.Open(pluginPath)
+	p, err := goplugin.Open(pluginPath)
 	if err != nil {
 		return fmt.Errorf("failed to load plugin binary: %v", err)
 	}
@@ -104,6 +106,16 @@ func (m *Manager) LoadPlugin(manifestPath string) error {
 	}
 
 	plugin.Instance = newPlugin()
+	plugin.Health = PluginHealth{Status: HealthUnchecked}
+
+	// Validate the manifest config against the schema the plugin declares
+	if schema, ok := plugin.Instance.(ConfigSchemaProvider); ok {
+		config, err := ValidateConfig(schema.ConfigSchema(), plugin.Config)
+		if err != nil {
+			return fmt.Errorf("invalid plugin config: %v", err)
+		}
+		plugin.Config = config
+	}
 
 	// Initialize the plugin
 	if err := plugin.Instance.Initialize(plugin.Config); err != nil {
@@ -162,14 +174,16 @@ func (m *Manager) GetPluginCommands(name string) ([]Command, error) {
 	return plugin.Instance.GetCommands(), nil
 }
 
-// ListPlugins returns a list of all loaded plugins
+// ListPlugins returns a snapshot of all loaded plugins, including the result
+// of each plugin's latest health check
 func (m *Manager) ListPlugins() []*Plugin {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	plugins := make([]*Plugin, 0, len(m.plugins))
 	for _, p := range m.plugins {
-		plugins = append(plugins, p)
+		snapshot := *p
+		plugins = append(plugins, &snapshot)
 	}
 	return plugins
 }
@@ -219,6 +233,15 @@ func (p *CostAnalyzerPlugin) Initialize(config map[string]any) error {
     return nil
 }
 
+// ConfigSchema is optional; the manifest config is checked against it before
+// Initialize
+func (p *CostAnalyzerPlugin) ConfigSchema() []plugin.Flag {
+    return []plugin.Flag{
+        {Name: "api_endpoint", Type: "string", Required: true},
+        {Name: "refresh_interval", Type: "int", Default: 300},
+    }
+}
+
 func (p *CostAnalyzerPlugin) Execute(args []string) (any, error) {
     // Plugin logic here
     return nil, nil
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Affinity asks for a placement to be close to a related resource, either an
+// existing placement by ID or, in a placement group, another member by name
+type Affinity struct {
+	PlacementID string `json:"placement_id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	// MonthlyTrafficGB is the traffic exchanged with the related resource,
+	// priced as egress when the two end up in different regions
+	MonthlyTrafficGB float64 `json:"monthly_traffic_gb,omitempty"`
+}
+
+// AffinityLink is the estimated latency and monthly egress cost between a
+// placement and a related resource
+type AffinityLink struct {
+	Ref               string  `json:"ref"`
+	Provider          string  `json:"provider"`
+	Region            string  `json:"region"`
+	LatencyMs         float64 `json:"latency_ms"`
+	EgressMonthlyCost float64 `json:"egress_monthly_cost"`
+}
+
+// CreateComputePlacementGroup places related compute requirements together.
+// Members reference each other by name in their Affinity; either all of them
+// are placed or none is. Results are returned in the order of reqs.
+func (c *Client) CreateComputePlacementGroup(reqs []*ComputeRequirements) ([]*PlacementResult, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("placement group requires at least one placement")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"placements": reqs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	resp, err := c.doRequest(http.MethodPost, "/placements/compute/group", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var raw []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	results := make([]*PlacementResult, len(raw))
+	for i, data := range raw {
+		if results[i], err = c.decodePlacement(data); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateComputePlacementGroup(t *testing.T) {
+	var got struct {
+		Placements []ComputeRequirements `json:"placements"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/placements/compute/group" {
+			t.Errorf("request %s %s", r.Method, r.URL)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`[
+			{"id":"plc-app","selected_provider":"aws","selected_region":"eu-west-1","estimated_monthly_cost":30,
+			 "affinity":[{"ref":"db","provider":"aws","region":"eu-west-1","latency_ms":1,"egress_monthly_cost":0}]},
+			{"id":"plc-db","selected_provider":"aws","selected_region":"eu-west-1","estimated_monthly_cost":60}]`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key")
+
+	reqs := []*ComputeRequirements{
+		{Name: "app", VCPUs: 2, MemoryGB: 4, Affinity: []Affinity{{Name: "db", MonthlyTrafficGB: 500}}},
+		{Name: "db", VCPUs: 2, MemoryGB: 4},
+	}
+	results, err := c.CreateComputePlacementGroup(reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Placements) != 2 || len(got.Placements[0].Affinity) != 1 || got.Placements[0].Affinity[0] != (Affinity{Name: "db", MonthlyTrafficGB: 500}) {
+		t.Errorf("request body %+v, want both members with app's affinity", got)
+	}
+	if len(results) != 2 || results[0].ID != "plc-app" || results[1].ID != "plc-db" {
+		t.Fatalf("results %+v, want app and db in request order", results)
+	}
+	if links := results[0].Affinity; len(links) != 1 || links[0].Ref != "db" || links[0].LatencyMs != 1 {
+		t.Errorf("app affinity links %+v, want the link to db", links)
+	}
+
+	if _, err := c.CreateComputePlacementGroup(nil); err == nil {
+		t.Error("empty placement group accepted")
+	}
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// RegionCapabilities is what a provider offers in one region
+type RegionCapabilities struct {
+	Region               string   `json:"region"`
+	Features             []string `json:"features"`
+	ComplianceFrameworks []string `json:"compliance_frameworks"`
+	SpotAvailable        bool     `json:"spot_available"`
+}
+
+// ProviderCapabilities is what a provider offers: its instance families and
+// the features and compliance frameworks of each region
+type ProviderCapabilities struct {
+	Provider         string               `json:"provider"`
+	InstanceFamilies []string             `json:"instance_families"`
+	Regions          []RegionCapabilities `json:"regions"`
+}
+
+// GetProviderCapabilities returns what the provider supports in every region,
+// or in the given region only when it is set
+func (c *Client) GetProviderCapabilities(provider, region string) (*ProviderCapabilities, error) {
+	path := "/providers/" + url.PathEscape(provider) + "/capabilities"
+	if region != "" {
+		path += "?region=" + url.QueryEscape(region)
+	}
+
+	data, err := c.doSharedRead(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var caps ProviderCapabilities
+	if err := decodeResponse(data, "provider capabilities", &caps, "provider", "regions"); err != nil {
+		return nil, err
+	}
+	return &caps, nil
+}
+
+// Region returns the capabilities of the named region, if reported
+func (p *ProviderCapabilities) Region(name string) (*RegionCapabilities, bool) {
+	for i := range p.Regions {
+		if p.Regions[i].Region == name {
+			return &p.Regions[i], true
+		}
+	}
+	return nil, false
+}
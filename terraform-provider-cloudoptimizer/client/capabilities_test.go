@@ -0,0 +1,47 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetProviderCapabilities(t *testing.T) {
+	c, got := costServer(t, http.StatusOK, `{"provider":"gcp","instance_families":["c2","e2","n2"],"regions":[
+		{"region":"europe-west1","features":["gpu","arm"],"compliance_frameworks":["SOC2","GDPR"],"spot_available":true},
+		{"region":"us-east1","features":[],"compliance_frameworks":["SOC2"],"spot_available":false}]}`)
+
+	caps, err := c.GetProviderCapabilities("gcp", "europe west1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/providers/gcp/capabilities" || got.RawQuery != "region=europe+west1" {
+		t.Errorf("request %s", got)
+	}
+	if caps.Provider != "gcp" || len(caps.InstanceFamilies) != 3 || len(caps.Regions) != 2 {
+		t.Fatalf("capabilities %+v", caps)
+	}
+
+	r, ok := caps.Region("europe-west1")
+	if !ok || !r.SpotAvailable || len(r.Features) != 2 || r.ComplianceFrameworks[1] != "GDPR" {
+		t.Errorf("europe-west1 capabilities %+v, %v", r, ok)
+	}
+	if _, ok := caps.Region("asia-east1"); ok {
+		t.Error("unreported region found")
+	}
+
+	if _, err := c.GetProviderCapabilities("gcp", ""); err != nil || got.RawQuery != "" {
+		t.Errorf("every region sent %q (%v), want no parameters", got.RawQuery, err)
+	}
+}
+
+func TestGetProviderCapabilitiesErrors(t *testing.T) {
+	c, _ := costServer(t, http.StatusNotFound, `{"error":"unknown provider: oracle"}`)
+	if _, err := c.GetProviderCapabilities("oracle", ""); !IsNotFound(err) {
+		t.Errorf("error %v, want not found", err)
+	}
+
+	c, _ = costServer(t, http.StatusOK, `{"instance_families":[]}`)
+	if _, err := c.GetProviderCapabilities("gcp", ""); err == nil {
+		t.Error("response without a provider accepted")
+	}
+}
@@ -1,34 +1,158 @@
+// Package client implements the Cloud Optimizer API client used by the
+// provider.
+//
+// The request and response types form a stable JSON contract with the API:
+// every field is tagged with its snake_case API name, fields the API treats
+// as optional are tagged omitempty so their zero value is never sent or
+// emitted, and required fields are always present, even when zero. Changing
+// a tag is a breaking change for integrators that parse these payloads.
 package client
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"math"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	defaultTimeout = 30 * time.Second
+
+	// costBreakdownTolerance is the absolute difference allowed between the sum
+	// of a cost breakdown and the estimated monthly cost, absorbing rounding
+	costBreakdownTolerance = 0.01
 )
 
 // Client represents a Cloud Optimizer API client
 type Client struct {
 	apiEndpoint string
 	apiKey      string
+	apiVersion  string
+	softDelete  bool
 	httpClient  *http.Client
+
+	// verifyKey, when set, is the public key placement results must be
+	// signed with
+	verifyKey ed25519.PublicKey
+
+	// tieBreak orders recommendations with equal total scores; nil uses
+	// DefaultTieBreak
+	tieBreak []string
+
+	// computeDefaults are applied to compute requirements that don't set
+	// the defaulted fields
+	computeDefaults *ComputeDefaults
+
+	// redactPatterns are the field names masked in debug logs; nil uses
+	// DefaultRedactedFields
+	redactPatterns []string
+
+	// inflight collapses concurrent identical reads into a single request
+	inflight singleflight.Group
+
+	// frameworks caches the compliance framework catalog
+	frameworksMu sync.Mutex
+	frameworks   []ComplianceFramework
+
+	// hooks are the webhook URLs invoked on lifecycle events
+	hooks *LifecycleHooks
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithAPIVersion pins requests to the given API version (e.g. "v2" or "2")
+// by sending a versioned Accept header
+func WithAPIVersion(version string) Option {
+	return func(c *Client) {
+		c.apiVersion = normalizeAPIVersion(version)
+	}
+}
+
+// WithSoftDelete makes placement deletes soft, so deleted placements can be
+// restored until the server's retention period passes
+func WithSoftDelete(soft bool) Option {
+	return func(c *Client) {
+		c.softDelete = soft
+	}
 }
 
 // NewClient creates a new Cloud Optimizer API client
-func NewClient(apiEndpoint, apiKey string) *Client {
-	return &Client{
+func NewClient(apiEndpoint, apiKey string, opts ...Option) *Client {
+	c := &Client{
 		apiEndpoint: apiEndpoint,
 		apiKey:      apiKey,
 		httpClient: &http.Client{
 			Timeout: defaultTimeout,
 		},
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// ServerVersion describes the API versions reported by the server
+type ServerVersion struct {
+	Version           string   `json:"version"`
+	SupportedVersions []string `json:"supported_versions"`
+}
+
+// CheckServerVersion fetches the server's supported API versions and returns a
+// warning message if the client's pinned version is not among them. An empty
+// warning means the versions are compatible or the client is not pinned.
+func (c *Client) CheckServerVersion() (string, error) {
+	if c.apiVersion == "" {
+		return "", nil
+	}
+
+	data, err := c.doSharedRead(http.MethodGet, "/version", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var version ServerVersion
+	if err := decodeResponse(data, "version", &version, "supported_versions"); err != nil {
+		return "", err
+	}
+
+	for _, supported := range version.SupportedVersions {
+		if normalizeAPIVersion(supported) == c.apiVersion {
+			return "", nil
+		}
+	}
+
+	return fmt.Sprintf("API version %s is not supported by the server (server version %s, supported: %s)",
+		c.apiVersion, version.Version, strings.Join(version.SupportedVersions, ", ")), nil
+}
+
+// mediaType returns the Accept header value for the client's pinned version
+func (c *Client) mediaType() string {
+	if c.apiVersion == "" {
+		return "application/json"
+	}
+	return fmt.Sprintf("application/vnd.cloudoptimizer.%s+json", c.apiVersion)
+}
+
+func normalizeAPIVersion(version string) string {
+	v := strings.TrimSpace(strings.ToLower(version))
+	if v != "" && !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return v
 }
 
 // ComputeRequirements represents the requirements for compute resource placement
@@ -37,12 +161,35 @@ type ComputeRequirements struct {
 	VCPUs               int       `json:"vcpus"`
 	MemoryGB           float64   `json:"memory_gb"`
 	Regions            []string  `json:"regions"`
-	MinAvailability    float64   `json:"min_availability"`
+	MinAvailability    float64   `json:"min_availability,omitempty"`
+	// SLATier names a service level (bronze, silver, gold or platinum); the
+	// service applies whichever of it and MinAvailability is stricter
+	SLATier            string    `json:"sla_tier,omitempty"`
 	MaxMonthlyBudget   *float64  `json:"max_monthly_budget,omitempty"`
 	PreferredProviders []string  `json:"preferred_providers,omitempty"`
 	ExcludedProviders  []string  `json:"excluded_providers,omitempty"`
+	// ExcludedInstanceTypes lists instance types, by exact name, never selected
+	ExcludedInstanceTypes []string `json:"excluded_instance_types,omitempty"`
 	RequiredFeatures   []string  `json:"required_features,omitempty"`
 	ComplianceFrameworks []string `json:"compliance_frameworks,omitempty"`
+	MultiRegion        *MultiRegionRequirements `json:"multi_region,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	// Metadata is opaque data stored with the placement and echoed back
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	// Affinity lists related resources the placement should be close to
+	Affinity           []Affinity `json:"affinity,omitempty"`
+	// DataTransfer is the expected monthly data transfer, priced into the
+	// estimated monthly cost
+	DataTransfer       *DataTransfer `json:"data_transfer,omitempty"`
+	// AvailabilityZones pins the placement to zones of the selected region
+	AvailabilityZones  *ZoneRequirements `json:"availability_zones,omitempty"`
+	// DataResidency lists the jurisdictions the placement's data may reside
+	// in, as country codes or EU
+	DataResidency      []string  `json:"data_residency,omitempty"`
+	ResourceGroup      string    `json:"resource_group,omitempty"`
+	// CurrentMonthlyCost is what the workload costs today, which the
+	// placement's savings are estimated against
+	CurrentMonthlyCost *float64 `json:"current_monthly_cost,omitempty"`
 }
 
 // StorageRequirements represents the requirements for storage resource placement
@@ -52,8 +199,9 @@ type StorageRequirements struct {
 	IOPS            *int      `json:"iops,omitempty"`
 	ThroughputMBPS  *int      `json:"throughput_mbps,omitempty"`
 	Regions         []string  `json:"regions"`
-	MinAvailability float64   `json:"min_availability"`
+	MinAvailability float64   `json:"min_availability,omitempty"`
 	MaxMonthlyBudget *float64 `json:"max_monthly_budget,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
 }
 
 // NetworkRequirements represents the requirements for network resource placement
@@ -61,9 +209,11 @@ type NetworkRequirements struct {
 	Name             string    `json:"name"`
 	BandwidthGbps    float64   `json:"bandwidth_gbps"`
 	CrossRegion      bool      `json:"cross_region"`
+	DataTransfer     *DataTransfer `json:"data_transfer,omitempty"`
 	Regions         []string  `json:"regions"`
-	MinAvailability float64   `json:"min_availability"`
+	MinAvailability float64   `json:"min_availability,omitempty"`
 	MaxMonthlyBudget *float64 `json:"max_monthly_budget,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
 }
 
 // DatabaseRequirements represents the requirements for database resource placement
@@ -72,23 +222,139 @@ type DatabaseRequirements struct {
 	Engine           string    `json:"engine"`
 	Version          string    `json:"version"`
 	Regions         []string  `json:"regions"`
-	MinAvailability float64   `json:"min_availability"`
+	MinAvailability float64   `json:"min_availability,omitempty"`
 	MaxMonthlyBudget *float64 `json:"max_monthly_budget,omitempty"`
+	MultiRegion     *MultiRegionRequirements `json:"multi_region,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+}
+
+// MultiRegionRequirements requests an active-active placement distributed
+// across regions. RegionWeights sets each region's share of traffic; when it
+// is empty the service weights the best MinRegions regions equally.
+type MultiRegionRequirements struct {
+	RegionWeights map[string]float64 `json:"region_weights,omitempty"`
+	MinRegions    int                `json:"min_regions"`
+}
+
+// Validate checks the weights and that the allowed regions can satisfy the
+// minimum region count
+func (m *MultiRegionRequirements) Validate(allowedRegions []string) error {
+	if m.MinRegions < 1 {
+		return fmt.Errorf("min_regions must be at least 1")
+	}
+
+	allowed := make(map[string]bool, len(allowedRegions))
+	for _, r := range allowedRegions {
+		allowed[r] = true
+	}
+
+	if len(m.RegionWeights) == 0 {
+		if len(allowed) < m.MinRegions {
+			return fmt.Errorf("min_regions is %d but only %d regions are allowed", m.MinRegions, len(allowed))
+		}
+		return nil
+	}
+
+	if len(m.RegionWeights) < m.MinRegions {
+		return fmt.Errorf("min_regions is %d but only %d regions are weighted", m.MinRegions, len(m.RegionWeights))
+	}
+
+	for region, weight := range m.RegionWeights {
+		if weight <= 0 {
+			return fmt.Errorf("weight for region %s must be greater than 0", region)
+		}
+		if !allowed[region] {
+			return fmt.Errorf("weighted region %s is not in regions", region)
+		}
+	}
+	return nil
+}
+
+// RegionAllocation is one region of a multi-region placement. Weight is the
+// normalized share of traffic served by the region.
+type RegionAllocation struct {
+	Provider     string  `json:"provider"`
+	Region       string  `json:"region"`
+	InstanceType string  `json:"instance_type,omitempty"`
+	Weight       float64 `json:"weight"`
+	MonthlyCost  float64 `json:"monthly_cost"`
+	Zones        []string `json:"zones,omitempty"`
 }
 
 // PlacementResult represents the result of a resource placement decision
 type PlacementResult struct {
 	ID                   string    `json:"id"`
+	ResourceType         string    `json:"resource_type"`
+	ResourceGroup        string    `json:"resource_group,omitempty"`
+	Requirements         map[string]interface{} `json:"requirements,omitempty"`
 	SelectedProvider     string    `json:"selected_provider"`
 	SelectedRegion       string    `json:"selected_region"`
+	// SelectedZones are the availability zones selected in SelectedRegion
+	SelectedZones        []string  `json:"selected_zones,omitempty"`
+	// SelectedJurisdiction is the jurisdiction of SelectedRegion when data
+	// residency is set
+	SelectedJurisdiction string    `json:"selected_jurisdiction,omitempty"`
 	InstanceType         string    `json:"instance_type,omitempty"`
 	EstimatedMonthlyCost float64   `json:"estimated_monthly_cost"`
+	CostBreakdown        map[string]float64 `json:"cost_breakdown,omitempty"`
 	PerformanceScore     float64   `json:"performance_score"`
 	ComplianceScore      float64   `json:"compliance_score"`
 	TotalScore          float64   `json:"total_score"`
+	// NormalizedScores are set when the server normalizes scores; TotalScore
+	// is then computed from them rather than the raw scores
+	NormalizedScores    *NormalizedScores `json:"normalized_scores,omitempty"`
+	SelectionReason     string    `json:"selection_reason,omitempty"`
 	Recommendations     []Alternative `json:"recommendations"`
+	RegionAllocations   []RegionAllocation `json:"region_allocations,omitempty"`
+	AggregateMonthlyCost float64 `json:"aggregate_monthly_cost,omitempty"`
+	AchievedSLATier     string    `json:"achieved_sla_tier,omitempty"`
+	// PriceListDate is when the prices the estimate used were last updated;
+	// PricesStale is set when the server considered them outdated
+	PriceListDate       *time.Time `json:"price_list_date,omitempty"`
+	PricesStale         bool      `json:"prices_stale,omitempty"`
+	DataTransferCost    *TransferCost `json:"data_transfer_cost,omitempty"`
+	Tags                map[string]string `json:"tags,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
+	Affinity            []AffinityLink `json:"affinity,omitempty"`
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
+	// Signature is set when the server signs placement results
+	Signature           string    `json:"signature,omitempty"`
+	// EstimatedSavings is CurrentMonthlyCost less the placement's monthly
+	// cost; it is negative, with CostIncrease set, when the placement costs
+	// more. SavingsPercent is unset when the current cost is 0.
+	CurrentMonthlyCost *float64 `json:"current_monthly_cost,omitempty"`
+	EstimatedSavings   *float64 `json:"estimated_savings,omitempty"`
+	SavingsPercent     *float64 `json:"savings_percent,omitempty"`
+	CostIncrease       bool     `json:"cost_increase,omitempty"`
+	// CustomPricing is set when the costs were estimated with the tenant's
+	// negotiated pricing rather than list prices
+	CustomPricing bool `json:"custom_pricing,omitempty"`
+}
+
+// CostBreakdownTotal returns the sum of the cost breakdown components
+func (r *PlacementResult) CostBreakdownTotal() float64 {
+	total := 0.0
+	for _, cost := range r.CostBreakdown {
+		total += cost
+	}
+	return total
+}
+
+// ValidateCostBreakdown checks that the cost breakdown, if present, sums to
+// the estimated monthly cost
+func (r *PlacementResult) ValidateCostBreakdown() error {
+	if len(r.CostBreakdown) == 0 {
+		return nil
+	}
+
+	total := r.CostBreakdownTotal()
+	if math.Abs(total-r.EstimatedMonthlyCost) > costBreakdownTolerance {
+		return fmt.Errorf("cost breakdown total %.2f does not match estimated monthly cost %.2f",
+			total, r.EstimatedMonthlyCost)
+	}
+	return nil
 }
 
 // Alternative represents an alternative placement recommendation
@@ -100,6 +366,19 @@ type Alternative struct {
 	PerformanceScore  float64 `json:"performance_score"`
 	ComplianceScore   float64 `json:"compliance_score"`
 	TotalScore        float64 `json:"total_score"`
+	RejectionReason   string  `json:"rejection_reason,omitempty"`
+	// Zones are the availability zones the alternative would use in Region
+	Zones             []string `json:"zones,omitempty"`
+	// Jurisdiction is the jurisdiction of Region when data residency is set
+	Jurisdiction      string  `json:"jurisdiction,omitempty"`
+	NormalizedScores  *NormalizedScores `json:"normalized_scores,omitempty"`
+}
+
+// NormalizedScores are the performance and compliance scores normalized
+// across the candidates a placement was chosen from
+type NormalizedScores struct {
+	Performance float64 `json:"performance"`
+	Compliance  float64 `json:"compliance"`
 }
 
 // CreateComputePlacement creates a new compute resource placement
@@ -182,38 +461,63 @@ func (c *Client) DeleteDatabasePlacement(id string) error {
 	return c.deletePlacement("database", id)
 }
 
+// AdoptPlacement registers an existing cloud resource, identified by its
+// provider resource ID (e.g. an EC2 instance ID), as a managed placement. The
+// returned placement reflects the resource's current provider, region and cost.
+func (c *Client) AdoptPlacement(resourceType, providerResourceID string) (*PlacementResult, error) {
+	return c.createPlacementAt(fmt.Sprintf("/placements/%s/adopt", resourceType), map[string]string{
+		"provider_resource_id": providerResourceID,
+	})
+}
+
 func (c *Client) createPlacement(resourceType string, req interface{}) (*PlacementResult, error) {
+	return c.createPlacementAt(fmt.Sprintf("/placements/%s", resourceType), req)
+}
+
+func (c *Client) createPlacementAt(path string, req interface{}) (*PlacementResult, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %v", err)
 	}
 
-	resp, err := c.doRequest(http.MethodPost, fmt.Sprintf("/placements/%s", resourceType), body)
+	resp, err := c.doRequest(http.MethodPost, path, body)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var result PlacementResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
 	}
 
-	return &result, nil
+	return c.decodePlacement(data)
 }
 
 func (c *Client) getPlacement(resourceType, id string) (*PlacementResult, error) {
-	resp, err := c.doRequest(http.MethodGet, fmt.Sprintf("/placements/%s/%s", resourceType, id), nil)
+	data, err := c.doSharedRead(http.MethodGet, fmt.Sprintf("/placements/%s/%s", resourceType, id), nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var result PlacementResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	return c.decodePlacement(data)
+}
+
+// decodePlacement decodes a placement result, first verifying its signature
+// when verification is enabled, and orders its recommendations
+// deterministically
+func (c *Client) decodePlacement(data []byte) (*PlacementResult, error) {
+	if c.verifyKey != nil {
+		if err := VerifyPlacementSignature(data, c.verifyKey); err != nil {
+			return nil, err
+		}
 	}
 
+	var result PlacementResult
+	if err := decodeResponse(data, "placement", &result, "id", "selected_provider", "selected_region", "estimated_monthly_cost"); err != nil {
+		return nil, err
+	}
+	SortAlternatives(result.Recommendations, c.tieBreak)
 	return &result, nil
 }
 
@@ -229,16 +533,21 @@ func (c *Client) updatePlacement(resourceType, id string, req interface{}) (*Pla
 	}
 	defer resp.Body.Close()
 
-	var result PlacementResult
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
 	}
 
-	return &result, nil
+	return c.decodePlacement(data)
 }
 
 func (c *Client) deletePlacement(resourceType, id string) error {
-	resp, err := c.doRequest(http.MethodDelete, fmt.Sprintf("/placements/%s/%s", resourceType, id), nil)
+	path := fmt.Sprintf("/placements/%s/%s", resourceType, id)
+	if c.softDelete {
+		path += "?soft=true"
+	}
+
+	resp, err := c.doRequest(http.MethodDelete, path, nil)
 	if err != nil {
 		return err
 	}
@@ -247,6 +556,53 @@ func (c *Client) deletePlacement(resourceType, id string) error {
 	return nil
 }
 
+// RestorePlacement restores a soft-deleted placement of the given type
+func (c *Client) RestorePlacement(resourceType, id string) (*PlacementResult, error) {
+	resp, err := c.doRequest(http.MethodPost, fmt.Sprintf("/placements/%s/%s/restore", resourceType, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	return c.decodePlacement(data)
+}
+
+// doSharedRead performs an idempotent read and returns the response body.
+// Concurrent calls with the same method, path and body share one in-flight
+// request and its result, so bursts of identical reads (e.g. during a
+// Terraform refresh) reach the API only once.
+func (c *Client) doSharedRead(method, path string, body []byte) ([]byte, error) {
+	v, err, _ := c.inflight.Do(requestKey(method, path, body), func() (interface{}, error) {
+		resp, err := c.doRequest(method, path, body)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %v", err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]byte), nil
+}
+
+// requestKey identifies a request by method, path and a hash of its body
+func requestKey(method, path string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return method + " " + path + " " + hex.EncodeToString(sum[:])
+}
+
 func (c *Client) doRequest(method, path string, body []byte) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.apiEndpoint, path)
 
@@ -261,17 +617,29 @@ func (c *Client) doRequest(method, path string, body []byte) (*http.Response, er
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", c.mediaType())
+	req.Header.Set(schemaVersionHeader, SchemaVersion)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
 
+	log.Printf("[DEBUG] Cloud Optimizer API request: %s %s %s", method, path, c.redacted(body))
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+		// Wrapped so IsTransient can recognize failures in transit
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	// Buffer the body so it can be logged and still be read by the caller
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
 	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	log.Printf("[DEBUG] Cloud Optimizer API response: %s %s %d %s", method, path, resp.StatusCode, c.redacted(respBody))
 
 	if resp.StatusCode >= 400 {
-		defer resp.Body.Close()
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
 	}
 
 	return resp, nil
@@ -0,0 +1,397 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	budget, current, savings := 500.0, 420.0, -30.5
+	listed := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	scores := &NormalizedScores{Performance: 0.8, Compliance: 1}
+	alternative := Alternative{
+		Provider:         "gcp",
+		Region:           "europe-west3",
+		InstanceType:     "n2-standard-4",
+		MonthlyCost:      140.2,
+		PerformanceScore: 0.7,
+		ComplianceScore:  1,
+		TotalScore:       0.75,
+		RejectionReason:  "costs 12.00 more per month",
+		Zones:            []string{"europe-west3-a", "europe-west3-b"},
+		Jurisdiction:     "DE",
+		NormalizedScores: scores,
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		// decoded returns a pointer to a zero value of the same type
+		decoded func() interface{}
+	}{
+		{
+			name: "compute requirements",
+			value: &ComputeRequirements{
+				Name:                  "web",
+				VCPUs:                 4,
+				MemoryGB:              16,
+				Regions:               []string{"eu-central-1", "europe-west3"},
+				MinAvailability:       99.9,
+				SLATier:               "gold",
+				MaxMonthlyBudget:      &budget,
+				ExcludedProviders:     []string{"azure"},
+				ExcludedInstanceTypes: []string{"t3.micro"},
+				ComplianceFrameworks:  []string{"gdpr"},
+				MultiRegion:           &MultiRegionRequirements{RegionWeights: map[string]float64{"eu-central-1": 1}, MinRegions: 1},
+				Tags:                  map[string]string{"team": "web"},
+				Metadata:              map[string]string{"ticket": "OPS-1"},
+				Affinity:              []Affinity{{Name: "db", MonthlyTrafficGB: 100}},
+				DataTransfer:          &DataTransfer{Outbound: TransferVolume{InternetGB: 50}},
+				AvailabilityZones:     &ZoneRequirements{Required: []string{"eu-central-1a"}, Spread: 2},
+				DataResidency:         []string{"EU"},
+				ResourceGroup:         "frontend",
+				CurrentMonthlyCost:    &current,
+			},
+			decoded: func() interface{} { return &ComputeRequirements{} },
+		},
+		{
+			name: "placement result",
+			value: &PlacementResult{
+				ID:                   "plc-1",
+				ResourceType:         "compute",
+				ResourceGroup:        "frontend",
+				Requirements:         map[string]interface{}{"vcpus": 4.0},
+				SelectedProvider:     "aws",
+				SelectedRegion:       "eu-central-1",
+				SelectedZones:        []string{"eu-central-1a"},
+				SelectedJurisdiction: "DE",
+				InstanceType:         "m5.xlarge",
+				EstimatedMonthlyCost: 128.2,
+				CostBreakdown:        map[string]float64{"compute": 120, "egress": 8.2},
+				PerformanceScore:     0.8,
+				ComplianceScore:      1,
+				TotalScore:           0.85,
+				NormalizedScores:     scores,
+				SelectionReason:      "lowest cost",
+				Recommendations:      []Alternative{alternative},
+				RegionAllocations:    []RegionAllocation{{Provider: "aws", Region: "eu-central-1", Weight: 1, MonthlyCost: 128.2, Zones: []string{"eu-central-1a"}}},
+				AggregateMonthlyCost: 128.2,
+				AchievedSLATier:      "gold",
+				PriceListDate:        &listed,
+				PricesStale:          true,
+				DataTransferCost:     &TransferCost{Internet: 8.2},
+				Tags:                 map[string]string{"team": "web"},
+				Metadata:             map[string]string{"ticket": "OPS-1"},
+				Affinity:             []AffinityLink{{Ref: "db", Provider: "aws", Region: "eu-central-1", LatencyMs: 1}},
+				CreatedAt:            created,
+				UpdatedAt:            created,
+				Signature:            "sig",
+				CurrentMonthlyCost:   &current,
+				EstimatedSavings:     &savings,
+				CostIncrease:         true,
+				CustomPricing:        true,
+			},
+			decoded: func() interface{} { return &PlacementResult{} },
+		},
+		{
+			name:    "alternative",
+			value:   &alternative,
+			decoded: func() interface{} { return &Alternative{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := tt.decoded()
+			if err := json.Unmarshal(data, got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("round trip changed the value:\n got %+v\nwant %+v", got, tt.value)
+			}
+		})
+	}
+}
+
+// gatewayPlacement is a placement as the gateway returns it, with every
+// field its placement and alternative responses set
+const gatewayPlacement = `{
+	"id": "plc-1",
+	"resource_type": "compute",
+	"resource_group": "frontend",
+	"requirements": {"vcpus": 4},
+	"selected_provider": "aws",
+	"selected_region": "eu-central-1",
+	"instance_type": "m5.xlarge",
+	"estimated_monthly_cost": 128.2,
+	"cost_breakdown": {"compute": 120, "egress": 8.2},
+	"performance_score": 0.8,
+	"compliance_score": 1,
+	"total_score": 0.85,
+	"selection_reason": "highest total score (0.85 vs 0.75 for the next best option); lowest cost",
+	"recommendations": [{
+		"provider": "gcp",
+		"region": "europe-west3",
+		"instance_type": "n2-standard-4",
+		"monthly_cost": 140.2,
+		"performance_score": 0.7,
+		"compliance_score": 1,
+		"total_score": 0.75,
+		"rejection_reason": "higher cost: 12.00 more per month",
+		"zones": ["europe-west3-a"],
+		"normalized_scores": {"performance": 0.6, "compliance": 1},
+		"jurisdiction": "DE"
+	}],
+	"region_allocations": [{"provider": "aws", "region": "eu-central-1", "instance_type": "m5.xlarge", "weight": 1, "monthly_cost": 128.2, "zones": ["eu-central-1a"]}],
+	"aggregate_monthly_cost": 128.2,
+	"achieved_sla_tier": "gold",
+	"tags": {"team": "web"},
+	"affinity": [{"ref": "db", "provider": "aws", "region": "eu-central-1", "latency_ms": 1, "egress_monthly_cost": 0}],
+	"created_at": "2026-10-01T12:00:00Z",
+	"updated_at": "2026-10-01T12:00:00Z",
+	"deleted_at": "2026-10-02T12:00:00Z",
+	"price_list_date": "2026-09-01T00:00:00Z",
+	"prices_stale": true,
+	"data_transfer_cost": {"intra_region": 0, "inter_region": 0, "internet": 8.2, "inbound": 0},
+	"selected_zones": ["eu-central-1a"],
+	"normalized_scores": {"performance": 0.8, "compliance": 1},
+	"selected_jurisdiction": "DE",
+	"metadata": {"ticket": "OPS-1"},
+	"current_monthly_cost": 100,
+	"estimated_savings": -28.2,
+	"savings_percent": -28.2,
+	"cost_increase": true,
+	"custom_pricing": true,
+	"signature": "sig"
+}`
+
+// jsonKeys returns the keys of a JSON object, and of the first element of
+// each array of objects, as dotted paths
+func jsonKeys(t *testing.T, data []byte) []string {
+	t.Helper()
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, child := range v {
+				keys = append(keys, prefix+k)
+				if prefix == "" && k == "requirements" {
+					continue
+				}
+				walk(prefix+k+".", child)
+			}
+		case []interface{}:
+			if len(v) > 0 {
+				walk(prefix, v[0])
+			}
+		}
+	}
+	walk("", object)
+	sort.Strings(keys)
+	return keys
+}
+
+func TestPlacementResultDecodesGatewayFields(t *testing.T) {
+	var result PlacementResult
+	if err := json.Unmarshal([]byte(gatewayPlacement), &result); err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(&result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A key the gateway sends that does not survive decoding has no field,
+	// or a field with a mismatched tag
+	kept := make(map[string]bool)
+	for _, k := range jsonKeys(t, encoded) {
+		kept[k] = true
+	}
+	for _, k := range jsonKeys(t, []byte(gatewayPlacement)) {
+		if !kept[k] {
+			t.Errorf("gateway field %s is not decoded", k)
+		}
+	}
+
+	alt := result.Recommendations[0]
+	if alt.Jurisdiction != "DE" || len(alt.Zones) != 1 || alt.NormalizedScores == nil || alt.NormalizedScores.Performance != 0.6 {
+		t.Errorf("alternative decoded as %+v", alt)
+	}
+	if !strings.HasSuffix(result.SelectionReason, "lowest cost") || alt.RejectionReason != "higher cost: 12.00 more per month" {
+		t.Errorf("selection reason %q and rejection reason %q not decoded", result.SelectionReason, alt.RejectionReason)
+	}
+}
+
+func TestCheckServerVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantAccept  string
+		wantWarning bool
+	}{
+		{name: "not pinned", wantAccept: "application/json"},
+		{name: "supported version", opts: []Option{WithAPIVersion("V1")}, wantAccept: "application/vnd.cloudoptimizer.v1+json"},
+		{name: "unsupported version", opts: []Option{WithAPIVersion("2")}, wantAccept: "application/vnd.cloudoptimizer.v2+json", wantWarning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accept string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept")
+				switch r.URL.Path {
+				case "/version":
+					w.Write([]byte(`{"version":"v1","supported_versions":["v1"]}`))
+				default:
+					w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":1}`))
+				}
+			}))
+			defer srv.Close()
+
+			c := NewClient(srv.URL, "key", tt.opts...)
+			warning, err := c.CheckServerVersion()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (warning != "") != tt.wantWarning {
+				t.Errorf("warning %q, want one %v", warning, tt.wantWarning)
+			}
+
+			if _, err := c.GetComputePlacement("plc-1"); err != nil {
+				t.Fatal(err)
+			}
+			if accept != tt.wantAccept {
+				t.Errorf("Accept %q, want %q", accept, tt.wantAccept)
+			}
+		})
+	}
+}
+
+func TestAdoptPlacement(t *testing.T) {
+	var gotPath string
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"plc-1","resource_type":"compute","selected_provider":"aws","selected_region":"eu-west-1","instance_type":"m5.large","estimated_monthly_cost":70.08}`))
+	}))
+	defer srv.Close()
+
+	result, err := NewClient(srv.URL, "key").AdoptPlacement("compute", "i-0123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/placements/compute/adopt" || gotBody["provider_resource_id"] != "i-0123" {
+		t.Errorf("request %s %v, want the resource adopted", gotPath, gotBody)
+	}
+	if result.SelectedProvider != "aws" || result.SelectedRegion != "eu-west-1" || result.EstimatedMonthlyCost != 70.08 {
+		t.Errorf("result %+v, want the resource's current deployment", result)
+	}
+}
+
+func TestValidateCostBreakdown(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    float64
+		wantErr bool
+	}{
+		{name: "no breakdown", data: `{"estimated_monthly_cost":120}`},
+		{name: "matching", data: `{"estimated_monthly_cost":128.2,"cost_breakdown":{"compute":120,"storage":3,"egress":5.2,"license":0}}`, want: 128.2},
+		{name: "within rounding", data: `{"estimated_monthly_cost":128.2,"cost_breakdown":{"compute":120.001,"egress":8.2}}`, want: 128.201},
+		{name: "mismatched", data: `{"estimated_monthly_cost":150,"cost_breakdown":{"compute":120,"egress":8.2}}`, want: 128.2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r PlacementResult
+			if err := json.Unmarshal([]byte(tt.data), &r); err != nil {
+				t.Fatal(err)
+			}
+			if total := r.CostBreakdownTotal(); math.Abs(total-tt.want) > 1e-9 {
+				t.Errorf("CostBreakdownTotal() = %v, want %v", total, tt.want)
+			}
+			if err := r.ValidateCostBreakdown(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCostBreakdown() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Concurrent identical reads reach the API once and share its response
+func TestConcurrentReadsShareRequest(t *testing.T) {
+	const readers = 10
+	var requests int32
+	arrived := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			close(arrived)
+		}
+		<-release
+		w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":1}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "key")
+	var wg sync.WaitGroup
+	errs := make(chan error, readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := c.GetComputePlacement("plc-1")
+			if err == nil && result.ID != "plc-1" {
+				err = fmt.Errorf("result %+v", result)
+			}
+			errs <- err
+		}()
+	}
+
+	// Hold the first request until the other readers have joined it
+	<-arrived
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("%d upstream requests for %d concurrent reads, want 1", n, readers)
+	}
+
+	// Reads after the shared one completes are sent again
+	if _, err := c.GetComputePlacement("plc-1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d upstream requests after a later read, want 2", n)
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// KnownComplianceFrameworks lists the framework IDs supported by the API at
+// the time of this release. It backs offline schema validation; the live
+// catalog is checked with ValidateComplianceFrameworks.
+var KnownComplianceFrameworks = []string{"FedRAMP", "GDPR", "HIPAA", "ISO27001", "PCI-DSS", "SOC2"}
+
+// ComplianceControl is a requirement within a compliance framework
+type ComplianceControl struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// ComplianceFramework is a compliance framework supported by the API
+type ComplianceFramework struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name"`
+	Controls []ComplianceControl `json:"controls"`
+}
+
+// ListComplianceFrameworks returns the supported compliance frameworks. The
+// catalog is fetched once and cached for the lifetime of the client.
+func (c *Client) ListComplianceFrameworks() ([]ComplianceFramework, error) {
+	c.frameworksMu.Lock()
+	defer c.frameworksMu.Unlock()
+
+	if c.frameworks != nil {
+		return c.frameworks, nil
+	}
+
+	data, err := c.doSharedRead(http.MethodGet, "/compliance/frameworks", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var frameworks []ComplianceFramework
+	if err := json.Unmarshal(data, &frameworks); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	c.frameworks = frameworks
+	return frameworks, nil
+}
+
+// ValidateComplianceFrameworks checks the framework IDs against the API's
+// catalog. IDs are case-sensitive; a differently-cased match is suggested.
+func (c *Client) ValidateComplianceFrameworks(ids []string) error {
+	frameworks, err := c.ListComplianceFrameworks()
+	if err != nil {
+		return err
+	}
+
+	known := make([]string, len(frameworks))
+	for i, f := range frameworks {
+		known[i] = f.ID
+	}
+
+	for _, id := range ids {
+		if err := checkComplianceFramework(id, known); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkComplianceFramework returns an error if id is not one of known
+func checkComplianceFramework(id string, known []string) error {
+	for _, k := range known {
+		if k == id {
+			return nil
+		}
+	}
+
+	for _, k := range known {
+		if strings.EqualFold(k, id) {
+			return fmt.Errorf("unknown compliance framework %q (did you mean %q?)", id, k)
+		}
+	}
+	return fmt.Errorf("unknown compliance framework %q (supported: %s)", id, strings.Join(known, ", "))
+}
+
+// CheckComplianceFramework validates id against KnownComplianceFrameworks
+func CheckComplianceFramework(id string) error {
+	return checkComplianceFramework(id, KnownComplianceFrameworks)
+}
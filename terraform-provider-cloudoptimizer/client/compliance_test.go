@@ -0,0 +1,69 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestValidateComplianceFrameworks(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/compliance/frameworks" {
+			t.Errorf("request to %s", r.URL.Path)
+		}
+		w.Write([]byte(`[{"id":"GDPR","name":"General Data Protection Regulation","controls":[{"id":"art-44","description":"Transfers"}]},{"id":"SOC2","name":"SOC 2"}]`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key")
+
+	tests := []struct {
+		name    string
+		ids     []string
+		wantErr string
+	}{
+		{name: "known", ids: []string{"GDPR", "SOC2"}},
+		{name: "unknown", ids: []string{"HIPAA"}, wantErr: "(supported: GDPR, SOC2)"},
+		{name: "wrong case", ids: []string{"soc2"}, wantErr: `(did you mean "SOC2"?)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.ValidateComplianceFrameworks(tt.ids)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateComplianceFrameworks(%v) = %v", tt.ids, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateComplianceFrameworks(%v) = %v, want %q", tt.ids, err, tt.wantErr)
+			}
+		})
+	}
+
+	frameworks, err := c.ListComplianceFrameworks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frameworks) != 2 || frameworks[0].Controls[0].ID != "art-44" {
+		t.Errorf("frameworks %+v", frameworks)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("%d catalog requests, want the catalog fetched once", n)
+	}
+}
+
+func TestCheckComplianceFramework(t *testing.T) {
+	for _, id := range KnownComplianceFrameworks {
+		if err := CheckComplianceFramework(id); err != nil {
+			t.Errorf("CheckComplianceFramework(%q) = %v", id, err)
+		}
+	}
+	if err := CheckComplianceFramework("pci-dss"); err == nil || !strings.Contains(err.Error(), `"PCI-DSS"`) {
+		t.Errorf("CheckComplianceFramework(pci-dss) = %v, want PCI-DSS suggested", err)
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CostQuery selects the period and scope of a cost request. Dates are
+// YYYY-MM-DD and EndDate is inclusive; empty fields use the API defaults
+// (the last 30 days across every provider and region).
+type CostQuery struct {
+	StartDate string
+	EndDate   string
+	Provider  string
+	Region    string
+}
+
+// values encodes the query as URL parameters
+func (q CostQuery) values() url.Values {
+	values := url.Values{}
+	setIfNotEmpty(values, "start_date", q.StartDate)
+	setIfNotEmpty(values, "end_date", q.EndDate)
+	setIfNotEmpty(values, "provider", q.Provider)
+	setIfNotEmpty(values, "region", q.Region)
+	return values
+}
+
+// CostLineItem is a single cost record for one resource
+type CostLineItem struct {
+	Date        time.Time         `json:"date"`
+	Provider    string            `json:"provider"`
+	Service     string            `json:"service"`
+	Region      string            `json:"region"`
+	ResourceID  string            `json:"resource_id,omitempty"`
+	Amount      float64           `json:"amount"`
+	Currency    string            `json:"currency"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Granularity string            `json:"granularity,omitempty"`
+}
+
+// CostBreakdown totals costs along several dimensions
+type CostBreakdown struct {
+	ByService map[string]float64 `json:"by_service"`
+	ByRegion  map[string]float64 `json:"by_region"`
+	ByTag     map[string]float64 `json:"by_tag"`
+}
+
+// CostAnalysis is the cost of a period with its line items
+type CostAnalysis struct {
+	TotalCost   float64        `json:"total_cost"`
+	Currency    string         `json:"currency"`
+	PeriodStart time.Time      `json:"period_start"`
+	PeriodEnd   time.Time      `json:"period_end"`
+	Breakdown   CostBreakdown  `json:"breakdown"`
+	Items       []CostLineItem `json:"items"`
+}
+
+// CostGroup is the total cost of one summary group
+type CostGroup struct {
+	Key   string  `json:"key"`
+	Cost  float64 `json:"cost"`
+	Share float64 `json:"share"`
+}
+
+// CostSummary is the cost of a period grouped along one dimension, largest
+// first
+type CostSummary struct {
+	GroupBy     string      `json:"group_by"`
+	TotalCost   float64     `json:"total_cost"`
+	Currency    string      `json:"currency"`
+	PeriodStart time.Time   `json:"period_start"`
+	PeriodEnd   time.Time   `json:"period_end"`
+	Groups      []CostGroup `json:"groups"`
+}
+
+// ForecastRequest selects the history and model of a forecast. Zero values
+// use the API defaults: a 30 day horizon and the linear model.
+type ForecastRequest struct {
+	CostQuery
+	HorizonDays int
+	// Model is linear or ema
+	Model     string
+	Alpha     float64
+	Smoothing bool
+}
+
+// DailyCost is the cost of a single day
+type DailyCost struct {
+	Date time.Time `json:"date"`
+	Cost float64   `json:"cost"`
+}
+
+// CostForecast is a projection of daily costs
+type CostForecast struct {
+	Model         string      `json:"model"`
+	Alpha         float64     `json:"alpha,omitempty"`
+	Smoothed      bool        `json:"smoothed"`
+	Currency      string      `json:"currency"`
+	HorizonDays   int         `json:"horizon_days"`
+	HistoryDays   int         `json:"history_days"`
+	ForecastTotal float64     `json:"forecast_total"`
+	Daily         []DailyCost `json:"daily"`
+}
+
+// GetCosts returns the cost line items and totals for the query
+func (c *Client) GetCosts(q CostQuery) (*CostAnalysis, error) {
+	var analysis CostAnalysis
+	if err := c.getJSON("/costs", q.values(), &analysis); err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+// GetCostSummary returns the costs for the query grouped by groupBy: service
+// (the default when empty), region, provider or tag:<key>
+func (c *Client) GetCostSummary(q CostQuery, groupBy string) (*CostSummary, error) {
+	values := q.values()
+	setIfNotEmpty(values, "group_by", groupBy)
+
+	var summary CostSummary
+	if err := c.getJSON("/costs/summary", values, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// GetForecast returns a forecast of daily costs from the history selected by
+// the request
+func (c *Client) GetForecast(req ForecastRequest) (*CostForecast, error) {
+	values := req.values()
+	if req.HorizonDays > 0 {
+		values.Set("horizon", strconv.Itoa(req.HorizonDays))
+	}
+	setIfNotEmpty(values, "model", req.Model)
+	if req.Alpha != 0 {
+		values.Set("alpha", strconv.FormatFloat(req.Alpha, 'g', -1, 64))
+	}
+	if req.Smoothing {
+		values.Set("smoothing", "true")
+	}
+
+	var forecast CostForecast
+	if err := c.getJSON("/costs/forecast", values, &forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// getJSON performs a shared GET of path with the query values and decodes
+// the response into v
+func (c *Client) getJSON(path string, values url.Values, v interface{}) error {
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	data, err := c.doSharedRead(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
+
+func setIfNotEmpty(values url.Values, key, value string) {
+	if value != "" {
+		values.Set(key, value)
+	}
+}
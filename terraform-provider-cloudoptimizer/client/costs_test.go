@@ -0,0 +1,100 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// costServer answers every request with body and records the request's
+// path and query
+func costServer(t *testing.T, status int, body string) (*Client, *url.URL) {
+	t.Helper()
+	var got url.URL
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("method %s, want GET", r.Method)
+		}
+		got = *r.URL
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, "key"), &got
+}
+
+func TestGetCosts(t *testing.T) {
+	c, got := costServer(t, http.StatusOK, `{"total_cost":42.5,"currency":"USD","breakdown":{"by_service":{"ec2":42.5}},
+		"items":[{"date":"2026-09-01T00:00:00Z","provider":"aws","service":"ec2","region":"us-east-1","amount":42.5,"currency":"USD","tags":{"team":"web"}}]}`)
+
+	analysis, err := c.GetCosts(CostQuery{StartDate: "2026-09-01", EndDate: "2026-09-30", Provider: "aws"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/costs" || got.Query().Encode() != "end_date=2026-09-30&provider=aws&start_date=2026-09-01" {
+		t.Errorf("request %s", got)
+	}
+	if analysis.TotalCost != 42.5 || analysis.Breakdown.ByService["ec2"] != 42.5 || len(analysis.Items) != 1 || analysis.Items[0].Tags["team"] != "web" {
+		t.Errorf("analysis %+v", analysis)
+	}
+
+	if _, err := c.GetCosts(CostQuery{}); err != nil || got.RawQuery != "" {
+		t.Errorf("empty query sent %q (%v), want no parameters", got.RawQuery, err)
+	}
+}
+
+func TestGetCostSummary(t *testing.T) {
+	c, got := costServer(t, http.StatusOK, `{"group_by":"tag:team","total_cost":100,"currency":"USD",
+		"groups":[{"key":"web","cost":75,"share":0.75},{"key":"","cost":25,"share":0.25}]}`)
+
+	summary, err := c.GetCostSummary(CostQuery{Region: "us-east-1"}, "tag:team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/costs/summary" || got.Query().Get("group_by") != "tag:team" || got.Query().Get("region") != "us-east-1" {
+		t.Errorf("request %s", got)
+	}
+	if summary.GroupBy != "tag:team" || len(summary.Groups) != 2 || summary.Groups[0] != (CostGroup{Key: "web", Cost: 75, Share: 0.75}) {
+		t.Errorf("summary %+v", summary)
+	}
+
+	if _, err := c.GetCostSummary(CostQuery{}, ""); err != nil || got.Query().Has("group_by") {
+		t.Errorf("default grouping sent %q (%v), want no group_by", got.RawQuery, err)
+	}
+}
+
+func TestGetForecast(t *testing.T) {
+	c, got := costServer(t, http.StatusOK, `{"model":"ema","alpha":0.3,"smoothed":true,"currency":"USD","horizon_days":7,"history_days":30,
+		"forecast_total":70,"daily":[{"date":"2026-10-01T00:00:00Z","cost":10}]}`)
+
+	forecast, err := c.GetForecast(ForecastRequest{CostQuery: CostQuery{Provider: "gcp"}, HorizonDays: 7, Model: "ema", Alpha: 0.3, Smoothing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/costs/forecast" || got.Query().Encode() != "alpha=0.3&horizon=7&model=ema&provider=gcp&smoothing=true" {
+		t.Errorf("request %s", got)
+	}
+	if forecast.Model != "ema" || forecast.HorizonDays != 7 || forecast.ForecastTotal != 70 || len(forecast.Daily) != 1 {
+		t.Errorf("forecast %+v", forecast)
+	}
+
+	if _, err := c.GetForecast(ForecastRequest{}); err != nil || got.RawQuery != "" {
+		t.Errorf("default forecast sent %q (%v), want no parameters", got.RawQuery, err)
+	}
+}
+
+func TestGetCostsErrors(t *testing.T) {
+	c, _ := costServer(t, http.StatusBadRequest, `{"error":"invalid start_date"}`)
+	_, err := c.GetCosts(CostQuery{StartDate: "yesterday"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("error %v, want a 400 API error", err)
+	}
+
+	c, _ = costServer(t, http.StatusOK, `not json`)
+	if _, err := c.GetCostSummary(CostQuery{}, ""); err == nil {
+		t.Error("undecodable response accepted")
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+// ComputeDefaults are requirements configured once in the provider block and
+// applied to every compute placement that doesn't set them itself
+type ComputeDefaults struct {
+	Regions              []string
+	MinAvailability      *float64
+	SLATier              string
+	MaxMonthlyBudget     *float64
+	ExcludedProviders    []string
+	ComplianceFrameworks []string
+}
+
+// WithComputeDefaults sets the default compute requirements
+func WithComputeDefaults(defaults *ComputeDefaults) Option {
+	return func(c *Client) {
+		c.computeDefaults = defaults
+	}
+}
+
+// ComputeDefaults returns the default compute requirements, or nil when none
+// are configured
+func (c *Client) ComputeDefaults() *ComputeDefaults {
+	return c.computeDefaults
+}
+
+// Apply sets each defaulted field of the requirements that the resource's
+// configuration leaves out, as reported by configured. Nil defaults change
+// nothing.
+func (d *ComputeDefaults) Apply(req *ComputeRequirements, configured func(key string) bool) {
+	if d == nil {
+		return
+	}
+
+	if len(d.Regions) > 0 && !configured("regions") {
+		req.Regions = append([]string(nil), d.Regions...)
+	}
+	if d.MinAvailability != nil && !configured("min_availability") {
+		req.MinAvailability = *d.MinAvailability
+	}
+	if d.SLATier != "" && !configured("sla_tier") {
+		req.SLATier = d.SLATier
+	}
+	if d.MaxMonthlyBudget != nil && !configured("max_monthly_budget") {
+		budget := *d.MaxMonthlyBudget
+		req.MaxMonthlyBudget = &budget
+	}
+	if len(d.ExcludedProviders) > 0 && !configured("excluded_providers") {
+		req.ExcludedProviders = append([]string(nil), d.ExcludedProviders...)
+	}
+	if len(d.ComplianceFrameworks) > 0 && !configured("compliance_frameworks") {
+		req.ComplianceFrameworks = append([]string(nil), d.ComplianceFrameworks...)
+	}
+}
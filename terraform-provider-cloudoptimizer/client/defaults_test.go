@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// A placement inherits every provider default its configuration leaves out
+// and keeps the values it sets, including zero values set explicitly
+func TestComputeDefaultsApply(t *testing.T) {
+	availability, budget := 99.95, 500.0
+	defaults := &ComputeDefaults{
+		Regions:              []string{"eu-west-1", "westeurope"},
+		MinAvailability:      &availability,
+		SLATier:              "gold",
+		MaxMonthlyBudget:     &budget,
+		ExcludedProviders:    []string{"gcp"},
+		ComplianceFrameworks: []string{"GDPR"},
+	}
+	ownBudget := 200.0
+
+	tests := []struct {
+		name       string
+		req        ComputeRequirements
+		configured []string
+		want       ComputeRequirements
+	}{
+		{
+			name: "inherits",
+			req:  ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8},
+			want: ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: []string{"eu-west-1", "westeurope"}, MinAvailability: 99.95,
+				SLATier: "gold", MaxMonthlyBudget: &budget, ExcludedProviders: []string{"gcp"}, ComplianceFrameworks: []string{"GDPR"}},
+		},
+		{
+			name:       "overrides",
+			req:        ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: []string{"us-east-1"}, MaxMonthlyBudget: &ownBudget, ComplianceFrameworks: []string{"HIPAA"}},
+			configured: []string{"regions", "max_monthly_budget", "compliance_frameworks"},
+			want: ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: []string{"us-east-1"}, MinAvailability: 99.95,
+				SLATier: "gold", MaxMonthlyBudget: &ownBudget, ExcludedProviders: []string{"gcp"}, ComplianceFrameworks: []string{"HIPAA"}},
+		},
+		{
+			name:       "explicit zero values",
+			req:        ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8},
+			configured: []string{"min_availability", "sla_tier", "excluded_providers"},
+			want: ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Regions: []string{"eu-west-1", "westeurope"},
+				MaxMonthlyBudget: &budget, ComplianceFrameworks: []string{"GDPR"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configured := make(map[string]bool)
+			for _, key := range tt.configured {
+				configured[key] = true
+			}
+			req := tt.req
+			defaults.Apply(&req, func(key string) bool { return configured[key] })
+			if !reflect.DeepEqual(req, tt.want) {
+				t.Errorf("requirements\n%+v\nwant\n%+v", req, tt.want)
+			}
+		})
+	}
+
+	// The requirements don't share the defaults' slices and pointers
+	req := ComputeRequirements{}
+	defaults.Apply(&req, func(string) bool { return false })
+	req.Regions[0] = "us-east-1"
+	*req.MaxMonthlyBudget = 1
+	if defaults.Regions[0] != "eu-west-1" || *defaults.MaxMonthlyBudget != 500 {
+		t.Errorf("changing the requirements changed the defaults to %+v", defaults)
+	}
+}
+
+// The defaults configured on the client reach the request of a placement
+// that leaves them out
+func TestCreateComputePlacementDefaults(t *testing.T) {
+	var sent ComputeRequirements
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"eu-west-1","estimated_monthly_cost":70}`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key", WithComputeDefaults(&ComputeDefaults{Regions: []string{"eu-west-1"}, SLATier: "gold"}))
+
+	req := &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, SLATier: "silver"}
+	c.ComputeDefaults().Apply(req, func(key string) bool { return key == "sla_tier" })
+	if _, err := c.CreateComputePlacement(req); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sent.Regions, []string{"eu-west-1"}) || sent.SLATier != "silver" {
+		t.Errorf("sent regions %v and SLA tier %q, want the default regions and the resource's tier", sent.Regions, sent.SLATier)
+	}
+
+	var none *ComputeDefaults
+	req = &ComputeRequirements{Name: "web"}
+	none.Apply(req, func(string) bool { return false })
+	if !reflect.DeepEqual(req, &ComputeRequirements{Name: "web"}) {
+		t.Errorf("nil defaults changed the requirements to %+v", req)
+	}
+}
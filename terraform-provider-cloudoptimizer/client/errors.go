@@ -0,0 +1,78 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrSignatureInvalid is returned when signature verification is enabled and a
+// placement result's signature is missing or does not match its contents
+var ErrSignatureInvalid = errors.New("placement result signature is invalid")
+
+// APIError is returned when the API responds with an error status
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.message())
+}
+
+// message returns the message of the API's error envelope, with any failing
+// fields, or the raw body when it is not an envelope
+func (e *APIError) message() string {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Fields  []struct {
+				Field   string `json:"field"`
+				Message string `json:"message"`
+			} `json:"fields"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(e.Body), &body); err != nil || body.Error.Message == "" {
+		return e.Body
+	}
+
+	msg := body.Error.Message
+	if len(body.Error.Fields) > 0 {
+		fields := make([]string, len(body.Error.Fields))
+		for i, f := range body.Error.Fields {
+			fields[i] = f.Field + " " + f.Message
+		}
+		msg += " (" + strings.Join(fields, "; ") + ")"
+	}
+	return msg
+}
+
+// IsNotFound reports whether err is an API response saying the requested
+// object does not exist
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsTransient reports whether err may succeed if the request is retried: the
+// request failed in transit, or the API was rate limiting, overloaded or
+// unavailable
+func IsTransient(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
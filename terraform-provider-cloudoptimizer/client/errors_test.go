@@ -0,0 +1,84 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Delete errors are classified so the provider can treat an already deleted
+// placement as gone, retry transient failures and surface the rest
+func TestDeleteErrorClassification(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		wantNotFound  bool
+		wantTransient bool
+	}{
+		{name: "deleted", status: http.StatusNoContent},
+		{name: "already deleted", status: http.StatusNotFound, wantNotFound: true},
+		{name: "rate limited", status: http.StatusTooManyRequests, wantTransient: true},
+		{name: "bad gateway", status: http.StatusBadGateway, wantTransient: true},
+		{name: "unavailable", status: http.StatusServiceUnavailable, wantTransient: true},
+		{name: "gateway timeout", status: http.StatusGatewayTimeout, wantTransient: true},
+		{name: "forbidden", status: http.StatusForbidden},
+		{name: "conflict", status: http.StatusConflict},
+		{name: "internal error", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/placements/compute/plc-1" {
+					t.Errorf("request %s %s", r.Method, r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := NewClient(srv.URL, "key").DeleteComputePlacement("plc-1")
+			if tt.status < 400 {
+				if err != nil {
+					t.Errorf("DeleteComputePlacement() = %v, want nil", err)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("DeleteComputePlacement() = %v, want an API error with status %d", err, tt.status)
+			}
+			if IsNotFound(err) != tt.wantNotFound || IsTransient(err) != tt.wantTransient {
+				t.Errorf("IsNotFound = %v, IsTransient = %v, want %v, %v", IsNotFound(err), IsTransient(err), tt.wantNotFound, tt.wantTransient)
+			}
+		})
+	}
+
+	// A request that never reaches the API may succeed when retried
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	err := NewClient(srv.URL, "key").DeleteComputePlacement("plc-1")
+	if err == nil || !IsTransient(err) || IsNotFound(err) {
+		t.Errorf("unreachable API error %v, want it transient", err)
+	}
+	if IsTransient(errors.New("invalid id")) || IsNotFound(nil) {
+		t.Error("errors other than API and transport failures classified")
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{body: "upstream timed out", want: "request failed with status 503: upstream timed out"},
+		{body: `{"error":{"message":"validation failed","fields":[{"field":"vcpus","message":"must be positive"},{"field":"name","message":"is required"}]}}`,
+			want: "request failed with status 503: validation failed (vcpus must be positive; name is required)"},
+		{body: `{"error":"not an envelope"}`, want: `request failed with status 503: {"error":"not an envelope"}`},
+	}
+	for _, tt := range tests {
+		if got := (&APIError{StatusCode: http.StatusServiceUnavailable, Body: tt.body}).Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
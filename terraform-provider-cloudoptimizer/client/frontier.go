@@ -0,0 +1,43 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// FrontierOption is an option on the cost/performance Pareto frontier. BestOn
+// lists the dimensions, "cost" and/or "performance", on which no option beats
+// it.
+type FrontierOption struct {
+	Provider         string   `json:"provider"`
+	Region           string   `json:"region"`
+	InstanceType     string   `json:"instance_type"`
+	MonthlyCost      float64  `json:"monthly_cost"`
+	PerformanceScore float64  `json:"performance_score"`
+	TotalScore       float64  `json:"total_score"`
+	BestOn           []string `json:"best_on,omitempty"`
+}
+
+// GetComputeFrontier returns the options satisfying the requirements that no
+// other option beats on both monthly cost and performance, cheapest first.
+// Nothing is saved, so the requirements' name may be empty.
+func (c *Client) GetComputeFrontier(req *ComputeRequirements) ([]FrontierOption, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	data, err := c.doSharedRead(http.MethodPost, "/placements/compute/frontier", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Frontier []FrontierOption `json:"frontier"`
+	}
+	if err := decodeResponse(data, "frontier", &result, "frontier"); err != nil {
+		return nil, err
+	}
+	return result.Frontier, nil
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetComputeFrontier(t *testing.T) {
+	var got ComputeRequirements
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/placements/compute/frontier" {
+			t.Errorf("request %s %s", r.Method, r.URL)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(`{"frontier":[
+			{"provider":"gcp","region":"us-central1","instance_type":"e2-standard-2","monthly_cost":49,"performance_score":0.6,"total_score":0.8,"best_on":["cost"]},
+			{"provider":"aws","region":"us-east-1","instance_type":"c5.xlarge","monthly_cost":124,"performance_score":0.85,"total_score":0.7,"best_on":["performance"]}]}`))
+	}))
+	defer srv.Close()
+
+	frontier, err := NewClient(srv.URL, "key").GetComputeFrontier(&ComputeRequirements{VCPUs: 2, MemoryGB: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.VCPUs != 2 || got.MemoryGB != 8 {
+		t.Errorf("requirements sent %+v", got)
+	}
+	if len(frontier) != 2 || frontier[0].BestOn[0] != "cost" || frontier[1].InstanceType != "c5.xlarge" || frontier[1].PerformanceScore != 0.85 {
+		t.Errorf("frontier %+v", frontier)
+	}
+}
+
+func TestGetComputeFrontierErrors(t *testing.T) {
+	status, body := http.StatusUnprocessableEntity, `{"error":"no placement satisfies the requirements"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key")
+
+	if _, err := c.GetComputeFrontier(&ComputeRequirements{VCPUs: 512}); err == nil {
+		t.Error("422 accepted")
+	}
+
+	status, body = http.StatusOK, `{"options":[]}`
+	if _, err := c.GetComputeFrontier(&ComputeRequirements{VCPUs: 2}); err == nil {
+		t.Error("response without a frontier accepted")
+	}
+}
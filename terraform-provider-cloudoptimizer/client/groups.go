@@ -0,0 +1,59 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ResourceGroupCost is the combined estimated cost of a resource group's
+// placements
+type ResourceGroupCost struct {
+	ResourceGroup        string  `json:"resource_group"`
+	PlacementCount       int     `json:"placement_count"`
+	EstimatedMonthlyCost float64 `json:"estimated_monthly_cost"`
+}
+
+// ResourceGroupPlacements is a resource group's placements of every type with
+// their estimated monthly cost
+type ResourceGroupPlacements struct {
+	Placements       []*PlacementResult  `json:"placements"`
+	Groups           []ResourceGroupCost `json:"groups"`
+	TotalMonthlyCost float64             `json:"total_monthly_cost"`
+}
+
+// ListPlacementsByGroup lists the placements of every type in a resource
+// group. An empty group lists every placement, with costs totalled per group.
+func (c *Client) ListPlacementsByGroup(group string) (*ResourceGroupPlacements, error) {
+	path := "/placements"
+	if group != "" {
+		path += "?group=" + url.QueryEscape(group)
+	}
+
+	data, err := c.doSharedRead(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Placements       []json.RawMessage   `json:"placements"`
+		Groups           []ResourceGroupCost `json:"groups"`
+		TotalMonthlyCost float64             `json:"total_monthly_cost"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	listing := &ResourceGroupPlacements{
+		Placements:       make([]*PlacementResult, len(raw.Placements)),
+		Groups:           raw.Groups,
+		TotalMonthlyCost: raw.TotalMonthlyCost,
+	}
+	for i, p := range raw.Placements {
+		if listing.Placements[i], err = c.decodePlacement(p); err != nil {
+			return nil, err
+		}
+	}
+	return listing, nil
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestListPlacementsByGroup(t *testing.T) {
+	c, got := costServer(t, http.StatusOK, `{"placements":[
+		{"id":"plc-1","resource_type":"compute","resource_group":"web tier","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":60},
+		{"id":"plc-2","resource_type":"storage","resource_group":"web tier","selected_provider":"gcp","selected_region":"us-east1","estimated_monthly_cost":15}],
+		"groups":[{"resource_group":"web tier","placement_count":2,"estimated_monthly_cost":75}],"total_monthly_cost":75}`)
+
+	listing, err := c.ListPlacementsByGroup("web tier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/placements" || got.RawQuery != "group=web+tier" {
+		t.Errorf("request %s", got)
+	}
+	if len(listing.Placements) != 2 || listing.Placements[1].ID != "plc-2" || listing.TotalMonthlyCost != 75 {
+		t.Errorf("listing %+v", listing)
+	}
+	if len(listing.Groups) != 1 || listing.Groups[0] != (ResourceGroupCost{ResourceGroup: "web tier", PlacementCount: 2, EstimatedMonthlyCost: 75}) {
+		t.Errorf("groups %+v", listing.Groups)
+	}
+
+	if _, err := c.ListPlacementsByGroup(""); err != nil || got.RawQuery != "" {
+		t.Errorf("listing every group sent %q (%v), want no parameters", got.RawQuery, err)
+	}
+}
+
+func TestListPlacementsByGroupIncomplete(t *testing.T) {
+	c, _ := costServer(t, http.StatusOK, `{"placements":[{"id":"plc-1"}],"groups":[],"total_monthly_cost":0}`)
+	if _, err := c.ListPlacementsByGroup("web"); err == nil {
+		t.Error("placement without a selection accepted")
+	}
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Lifecycle events that hooks are invoked for
+const (
+	HookPreCreate  = "pre_create"
+	HookPostCreate = "post_create"
+	HookPreDelete  = "pre_delete"
+)
+
+// maxHookResponse caps how much of a hook's response is kept for errors
+const maxHookResponse = 1024
+
+// LifecycleHooks are webhook URLs invoked around placement creation and
+// deletion. Empty URLs are skipped.
+type LifecycleHooks struct {
+	PreCreate  string
+	PostCreate string
+	PreDelete  string
+}
+
+// url returns the hook URL configured for the event
+func (h *LifecycleHooks) url(event string) string {
+	if h == nil {
+		return ""
+	}
+	switch event {
+	case HookPreCreate:
+		return h.PreCreate
+	case HookPostCreate:
+		return h.PostCreate
+	case HookPreDelete:
+		return h.PreDelete
+	}
+	return ""
+}
+
+// HookEvent is the JSON body posted to a lifecycle hook
+type HookEvent struct {
+	Event        string               `json:"event"`
+	ResourceType string               `json:"resource_type"`
+	PlacementID  string               `json:"placement_id,omitempty"`
+	Requirements *ComputeRequirements `json:"requirements,omitempty"`
+	Placement    *PlacementResult     `json:"placement,omitempty"`
+	Timestamp    time.Time            `json:"timestamp"`
+}
+
+// HookError is returned when a lifecycle hook cannot be reached or responds
+// with a non-2xx status
+type HookError struct {
+	Event      string
+	URL        string
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *HookError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s hook %s failed: %v", e.Event, e.URL, e.Err)
+	}
+	if e.Body == "" {
+		return fmt.Sprintf("%s hook %s returned status %d", e.Event, e.URL, e.StatusCode)
+	}
+	return fmt.Sprintf("%s hook %s returned status %d: %s", e.Event, e.URL, e.StatusCode, e.Body)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// WithLifecycleHooks sets the webhook URLs invoked on lifecycle events
+func WithLifecycleHooks(hooks *LifecycleHooks) Option {
+	return func(c *Client) {
+		c.hooks = hooks
+	}
+}
+
+// RunHook posts the event to the hook configured for it, doing nothing when
+// none is. The API key is not sent, since hooks are not the API. It returns
+// a *HookError when the hook cannot be reached or responds with a non-2xx
+// status; pre-hooks abort the operation on it.
+func (c *Client) RunHook(ctx context.Context, event HookEvent) error {
+	url := c.hooks.url(event.Event)
+	if url == "" {
+		return nil
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s hook event: %v", event.Event, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return &HookError{Event: event.Event, URL: url, Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Printf("[DEBUG] Cloud Optimizer %s hook request: POST %s %s", event.Event, url, c.redacted(body))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return &HookError{Event: event.Event, URL: url, Err: err}
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxHookResponse))
+	log.Printf("[DEBUG] Cloud Optimizer %s hook response: %d", event.Event, resp.StatusCode)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &HookError{
+			Event:      event.Event,
+			URL:        url,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(respBody)),
+		}
+	}
+	return nil
+}
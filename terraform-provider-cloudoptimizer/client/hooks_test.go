@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRunHook(t *testing.T) {
+	var events []HookEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("hook received Authorization %q", auth)
+		}
+		var event HookEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		events = append(events, event)
+		if r.URL.Path == "/reject" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("change freeze in effect\n"))
+		}
+	}))
+	defer srv.Close()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+
+	c := NewClient("http://api.invalid", "secret-key", WithLifecycleHooks(&LifecycleHooks{
+		PreCreate:  srv.URL + "/reject",
+		PostCreate: srv.URL + "/accept",
+		PreDelete:  unreachable.URL,
+	}))
+	ctx := context.Background()
+	req := &ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8}
+
+	// A rejecting pre_create hook returns its status and body
+	err := c.RunHook(ctx, HookEvent{Event: HookPreCreate, ResourceType: "cloudoptimizer_compute_placement", Requirements: req})
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) {
+		t.Fatalf("pre_create error = %v, want a *HookError", err)
+	}
+	if hookErr.StatusCode != http.StatusForbidden || hookErr.Body != "change freeze in effect" {
+		t.Errorf("pre_create error status %d body %q", hookErr.StatusCode, hookErr.Body)
+	}
+	if want := "pre_create hook " + srv.URL + "/reject returned status 403: change freeze in effect"; err.Error() != want {
+		t.Errorf("error %q, want %q", err, want)
+	}
+
+	// post_create receives the placement once it is created
+	result := &PlacementResult{ID: "plc-1", SelectedProvider: "aws", SelectedRegion: "us-east-1"}
+	if err := c.RunHook(ctx, HookEvent{Event: HookPostCreate, PlacementID: result.ID, Requirements: req, Placement: result}); err != nil {
+		t.Fatalf("post_create: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("hooks received %d events, want 2", len(events))
+	}
+	post := events[1]
+	if post.Event != HookPostCreate || post.PlacementID != "plc-1" || post.Placement == nil || post.Placement.SelectedRegion != "us-east-1" {
+		t.Errorf("post_create event %+v", post)
+	}
+	if post.Timestamp.IsZero() {
+		t.Error("post_create event has no timestamp")
+	}
+
+	// An unreachable hook is an error too
+	err = c.RunHook(ctx, HookEvent{Event: HookPreDelete, PlacementID: "plc-1"})
+	if !errors.As(err, &hookErr) || hookErr.Err == nil {
+		t.Errorf("pre_delete error = %v, want an unreachable *HookError", err)
+	}
+
+	// Without a hook for the event nothing is sent
+	noHooks := NewClient("http://api.invalid", "secret-key")
+	if err := noHooks.RunHook(ctx, HookEvent{Event: HookPreCreate}); err != nil {
+		t.Errorf("unset hook: %v", err)
+	}
+	if len(events) != 2 {
+		t.Errorf("hooks received %d events, want 2", len(events))
+	}
+}
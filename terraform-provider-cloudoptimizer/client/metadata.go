@@ -0,0 +1,26 @@
+package client
+
+import "fmt"
+
+// Limits on placement metadata, matching those enforced by the API
+const (
+	MaxMetadataKeyLength = 128
+	// MaxMetadataSize is the combined length in bytes of every key and value
+	MaxMetadataSize = 8192
+)
+
+// ValidateMetadata checks metadata before it is sent so oversized metadata
+// fails at plan time rather than when the placement is created
+func ValidateMetadata(metadata map[string]string) error {
+	size := 0
+	for key, value := range metadata {
+		if key == "" || len(key) > MaxMetadataKeyLength {
+			return fmt.Errorf("metadata key %q must be 1 to %d characters", key, MaxMetadataKeyLength)
+		}
+		size += len(key) + len(value)
+	}
+	if size > MaxMetadataSize {
+		return fmt.Errorf("metadata must be at most %d bytes in total, got %d", MaxMetadataSize, size)
+	}
+	return nil
+}
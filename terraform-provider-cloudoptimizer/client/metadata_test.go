@@ -0,0 +1,53 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "ticket", metadata: map[string]string{"ticket": "OPS-42"}},
+		{name: "empty key", metadata: map[string]string{"": "x"}, wantErr: true},
+		{name: "oversized", metadata: map[string]string{"notes": strings.Repeat("x", MaxMetadataSize)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateMetadata(tt.metadata); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateComputePlacementMetadata(t *testing.T) {
+	var sent ComputeRequirements
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":70,"metadata":{"ticket":"OPS-42"}}`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key")
+
+	metadata := map[string]string{"ticket": "OPS-42"}
+	result, err := c.CreateComputePlacement(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Metadata: metadata})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sent.Metadata, metadata) || !reflect.DeepEqual(result.Metadata, metadata) {
+		t.Errorf("sent metadata %v, got back %v, want %v both ways", sent.Metadata, result.Metadata, metadata)
+	}
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Recommendation is an optimization recommendation that is not snoozed
+type Recommendation struct {
+	ID                   string                 `json:"id"`
+	Type                 string                 `json:"type"`
+	Action               string                 `json:"action,omitempty"`
+	Priority             string                 `json:"priority"`
+	ResourceID           string                 `json:"resource_id,omitempty"`
+	Description          string                 `json:"description"`
+	EstimatedSavings     float64                `json:"estimated_savings"`
+	ImplementationEffort string                 `json:"implementation_effort,omitempty"`
+	Details              map[string]interface{} `json:"details,omitempty"`
+	CreatedAt            time.Time              `json:"created_at"`
+	// Effort is trivial, low, medium or high; Risk is low, medium or high
+	Effort string `json:"effort,omitempty"`
+	Risk   string `json:"risk,omitempty"`
+}
+
+// MaintenanceWindow is a set of weekly recurring time ranges in a timezone
+// during which disruptive recommendations may be applied
+type MaintenanceWindow struct {
+	Timezone string                   `json:"timezone,omitempty"`
+	Ranges   []MaintenanceWindowRange `json:"ranges"`
+}
+
+// MaintenanceWindowRange is a daily HH:MM range on the given weekdays ("sat",
+// "sun", ...; empty means every day). An End at or before Start wraps past
+// midnight.
+type MaintenanceWindowRange struct {
+	Days  []string `json:"days,omitempty"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+// ApplyRequest selects the recommendations to apply. Without a maintenance
+// window every recommendation is applied immediately; with one, disruptive
+// recommendations are scheduled for the window's next opening if it is
+// closed. OverrideCompliance applies recommendations even when they would move
+// a placement out of its required compliance frameworks.
+type ApplyRequest struct {
+	RecommendationIDs  []string           `json:"recommendation_ids"`
+	MaintenanceWindow  *MaintenanceWindow `json:"maintenance_window,omitempty"`
+	OverrideCompliance bool               `json:"override_compliance,omitempty"`
+}
+
+// Application records the application of one recommendation. Status is
+// applied or scheduled.
+type Application struct {
+	ID               string     `json:"id"`
+	RecommendationID string     `json:"recommendation_id"`
+	Action           string     `json:"action"`
+	Disruptive       bool       `json:"disruptive"`
+	Status           string     `json:"status"`
+	ScheduledFor     *time.Time `json:"scheduled_for,omitempty"`
+	AppliedAt        *time.Time `json:"applied_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ApplyJobApproval records who requested an apply job held for approval and
+// when the request expires
+type ApplyJobApproval struct {
+	RequestedBy string    `json:"requested_by"`
+	RequestedAt time.Time `json:"requested_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ApplyJob is the apply job returned instead of applications when a
+// recommendation's estimated impact requires approval
+type ApplyJob struct {
+	ID       string            `json:"id"`
+	Status   string            `json:"status"`
+	Approval *ApplyJobApproval `json:"approval,omitempty"`
+}
+
+// PendingApprovalError is returned by ApplyRecommendations when the API holds
+// the request for approval; nothing has been applied
+type PendingApprovalError struct {
+	Job ApplyJob
+}
+
+func (e *PendingApprovalError) Error() string {
+	if e.Job.Approval == nil {
+		return fmt.Sprintf("apply job %s is pending approval", e.Job.ID)
+	}
+	return fmt.Sprintf("apply job %s is pending approval until %s", e.Job.ID, e.Job.Approval.ExpiresAt.Format(time.RFC3339))
+}
+
+// GetRecommendations returns the recommendations that are not snoozed
+func (c *Client) GetRecommendations() ([]Recommendation, error) {
+	var recs []Recommendation
+	if err := c.getJSON("/optimize/recommendations", nil, &recs); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// ApplyRecommendations applies the requested recommendations and returns
+// their applications in request order. If the API holds the request for
+// approval, a *PendingApprovalError carrying the job is returned.
+func (c *Client) ApplyRecommendations(req *ApplyRequest) ([]Application, error) {
+	if len(req.RecommendationIDs) == 0 {
+		return nil, fmt.Errorf("at least one recommendation ID is required")
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	resp, err := c.doRequest(http.MethodPost, "/optimize/apply", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusAccepted {
+		var pending PendingApprovalError
+		if err := json.NewDecoder(resp.Body).Decode(&pending.Job); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %v", err)
+		}
+		return nil, &pending
+	}
+
+	var applications []Application
+	if err := json.NewDecoder(resp.Body).Decode(&applications); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return applications, nil
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetRecommendations(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/optimize/recommendations" {
+			t.Errorf("request %s %s", r.Method, r.URL)
+		}
+		w.Write([]byte(`[{"id":"rec-1","type":"cost","action":"resize","priority":"high","resource_id":"i-1",
+			"description":"downsize","estimated_savings":120.5,"effort":"low","risk":"medium","details":{"to":"m5.large"}}]`))
+	}))
+	defer srv.Close()
+
+	recs, err := NewClient(srv.URL, "key").GetRecommendations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 {
+		t.Fatalf("%d recommendations, want 1", len(recs))
+	}
+	rec := recs[0]
+	if rec.ID != "rec-1" || rec.Action != "resize" || rec.EstimatedSavings != 120.5 || rec.Effort != "low" || rec.Risk != "medium" || rec.Details["to"] != "m5.large" {
+		t.Errorf("recommendation %+v", rec)
+	}
+}
+
+func TestApplyRecommendations(t *testing.T) {
+	var got ApplyRequest
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/optimize/apply" {
+			t.Errorf("request %s %s", r.Method, r.URL)
+		}
+		got = ApplyRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+		switch status {
+		case http.StatusAccepted:
+			w.Write([]byte(`{"id":"job-1","status":"pending_approval","approval":{"requested_by":"ops","expires_at":"2026-10-01T00:00:00Z"}}`))
+		case http.StatusOK:
+			w.Write([]byte(`[{"id":"app-1","recommendation_id":"rec-1","action":"resize","disruptive":true,"status":"scheduled","scheduled_for":"2026-09-19T22:00:00Z"},
+				{"id":"app-2","recommendation_id":"rec-2","action":"tag","status":"applied","applied_at":"2026-09-16T09:00:00Z"}]`))
+		default:
+			w.Write([]byte(`{"error":"recommendation rec-9 not found"}`))
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key")
+
+	req := &ApplyRequest{
+		RecommendationIDs: []string{"rec-1", "rec-2"},
+		MaintenanceWindow: &MaintenanceWindow{Timezone: "Europe/Berlin", Ranges: []MaintenanceWindowRange{{Days: []string{"sat"}, Start: "22:00", End: "04:00"}}},
+	}
+	apps, err := c.ApplyRecommendations(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.RecommendationIDs) != 2 || got.MaintenanceWindow == nil || got.MaintenanceWindow.Ranges[0].End != "04:00" {
+		t.Errorf("request body %+v", got)
+	}
+	if len(apps) != 2 || apps[0].Status != "scheduled" || apps[0].ScheduledFor == nil || apps[1].Status != "applied" || apps[1].AppliedAt == nil {
+		t.Errorf("applications %+v, want one scheduled and one applied in request order", apps)
+	}
+
+	status = http.StatusAccepted
+	_, err = c.ApplyRecommendations(&ApplyRequest{RecommendationIDs: []string{"rec-1"}})
+	var pending *PendingApprovalError
+	if !errors.As(err, &pending) || pending.Job.ID != "job-1" || pending.Job.Approval == nil {
+		t.Errorf("error %v, want the job pending approval", err)
+	}
+
+	status = http.StatusNotFound
+	if _, err := c.ApplyRecommendations(&ApplyRequest{RecommendationIDs: []string{"rec-9"}}); !IsNotFound(err) {
+		t.Errorf("error %v, want not found", err)
+	}
+
+	if _, err := c.ApplyRecommendations(&ApplyRequest{}); err == nil {
+		t.Error("request without recommendation IDs accepted")
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// redactionMask replaces the value of a redacted field in debug logs
+const redactionMask = "[REDACTED]"
+
+// DefaultRedactedFields are the field-name patterns masked in debug logs when
+// none are configured. They match the API gateway's defaults.
+var DefaultRedactedFields = []string{
+	"password",
+	"*secret*",
+	"*token*",
+	"*api_key*",
+	"*apikey*",
+	"authorization",
+	"*credential*",
+	"*private_key*",
+	"*email*",
+	"ssn",
+}
+
+// WithRedactedFields replaces the field-name patterns whose values are masked
+// in debug logs. Patterns are shell globs matched case-insensitively against
+// field names; see ValidateRedactPattern. Without patterns the defaults apply.
+func WithRedactedFields(patterns []string) Option {
+	return func(c *Client) {
+		c.redactPatterns = nil
+		for _, p := range patterns {
+			if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+				c.redactPatterns = append(c.redactPatterns, p)
+			}
+		}
+	}
+}
+
+// ValidateRedactPattern checks that a redaction pattern is a valid glob.
+// Invalid patterns never match.
+func ValidateRedactPattern(pattern string) error {
+	if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+		return fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+	}
+	return nil
+}
+
+// redacted returns a JSON body for logging with the values of fields matching
+// the client's patterns masked. A body that is not JSON is masked as a whole.
+func (c *Client) redacted(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return redactionMask
+	}
+
+	data, err := json.Marshal(c.redactValue(v))
+	if err != nil {
+		return redactionMask
+	}
+	return string(data)
+}
+
+func (c *Client) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for k, field := range v {
+			if c.shouldRedact(k) {
+				redacted[k] = redactionMask
+			} else {
+				redacted[k] = c.redactValue(field)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = c.redactValue(item)
+		}
+		return redacted
+	}
+	return v
+}
+
+// shouldRedact reports whether a field's value is masked in debug logs
+func (c *Client) shouldRedact(field string) bool {
+	patterns := c.redactPatterns
+	if patterns == nil {
+		patterns = DefaultRedactedFields
+	}
+
+	field = strings.ToLower(field)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, field); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		in       string
+		want     string
+	}{
+		{name: "default fields", in: `{"name":"web","api_key":"k-123","Password":"hunter2"}`, want: `{"Password":"[REDACTED]","api_key":"[REDACTED]","name":"web"}`},
+		{name: "nested and map keys", in: `{"tags":{"owner_email":"a@example.com"},"items":[{"client_secret":"s"}]}`, want: `{"items":[{"client_secret":"[REDACTED]"}],"tags":{"owner_email":"[REDACTED]"}}`},
+		{name: "configured fields replace the defaults", patterns: []string{" Cost* "}, in: `{"cost_center":"cc-1","password":"hunter2"}`, want: `{"cost_center":"[REDACTED]","password":"hunter2"}`},
+		{name: "not JSON", in: `password=hunter2`, want: redactionMask},
+		{name: "empty", in: ``, want: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.patterns != nil {
+				opts = append(opts, WithRedactedFields(tt.patterns))
+			}
+			c := NewClient("http://localhost", "key", opts...)
+			if got := c.redacted([]byte(tt.in)); got != tt.want {
+				t.Errorf("redacted(%s) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// The debug log of requests and responses never holds a secret
+func TestDebugLogRedacted(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":1,"requirements":{"password":"p-456"}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "key")
+	if _, err := c.CreateComputePlacement(&ComputeRequirements{Name: "web", Tags: map[string]string{"api_token": "t-123"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := logged.String()
+	for _, secret := range []string{"t-123", "p-456"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("debug log %q contains %q", out, secret)
+		}
+	}
+	if !strings.Contains(out, `"name":"web"`) {
+		t.Errorf("debug log %q, want the request logged", out)
+	}
+}
+
+// DefaultRedactedFields must match the API gateway's redact.DefaultFields, so
+// a field masked in the gateway's logs is masked in the provider's too. The
+// check runs when the gateway's source is checked out next to the provider.
+func TestDefaultRedactedFieldsMatchGateway(t *testing.T) {
+	path := filepath.Join("..", "..", "api-gateway-service", "redact", "redact.go")
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("gateway source not available: %v", err)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gateway []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "DefaultFields" || len(spec.Values) != 1 {
+			return true
+		}
+		lit, ok := spec.Values[0].(*ast.CompositeLit)
+		if !ok {
+			t.Fatal("gateway DefaultFields is not a literal")
+		}
+		for _, elt := range lit.Elts {
+			s, err := strconv.Unquote(elt.(*ast.BasicLit).Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gateway = append(gateway, s)
+		}
+		return false
+	})
+
+	if gateway == nil {
+		t.Fatalf("DefaultFields not found in %s", path)
+	}
+	if !reflect.DeepEqual(DefaultRedactedFields, gateway) {
+		t.Errorf("DefaultRedactedFields = %v, gateway DefaultFields = %v", DefaultRedactedFields, gateway)
+	}
+}
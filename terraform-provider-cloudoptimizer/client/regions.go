@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KnownProviderRegions lists the regions of each provider in the API's
+// placement catalog at the time of this release
+var KnownProviderRegions = map[string][]string{
+	"aws":   {"us-east-1", "us-west-2", "eu-west-1"},
+	"azure": {"eastus", "westus2", "westeurope"},
+	"gcp":   {"us-central1", "us-east1", "europe-west1"},
+}
+
+// regionProvider returns the provider of a known region
+func regionProvider(region string) (string, bool) {
+	for provider, regions := range KnownProviderRegions {
+		for _, r := range regions {
+			if r == region {
+				return provider, true
+			}
+		}
+	}
+	return "", false
+}
+
+// CheckRegionProviders returns an error if none of the regions belongs to an
+// allowed provider: one of preferred (when set) that is not excluded. Regions
+// missing from KnownProviderRegions may exist in a newer catalog, so they
+// count as satisfiable.
+func CheckRegionProviders(regions, preferred, excluded []string) error {
+	isExcluded := make(map[string]bool, len(excluded))
+	for _, p := range excluded {
+		isExcluded[p] = true
+	}
+	isPreferred := make(map[string]bool, len(preferred))
+	for _, p := range preferred {
+		isPreferred[p] = true
+	}
+
+	var conflicts []string
+	for _, region := range regions {
+		provider, ok := regionProvider(region)
+		switch {
+		case !ok:
+			return nil
+		case isExcluded[provider]:
+			conflicts = append(conflicts, fmt.Sprintf("%s belongs to %s, which is excluded", region, provider))
+		case len(preferred) > 0 && !isPreferred[provider]:
+			conflicts = append(conflicts, fmt.Sprintf("%s belongs to %s, which is not a preferred provider", region, provider))
+		default:
+			return nil
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("no region can be placed with an allowed provider (%s): %s",
+		allowedProviders(preferred, isExcluded), strings.Join(conflicts, "; "))
+}
+
+// allowedProviders describes the providers left by the preferred and
+// excluded lists
+func allowedProviders(preferred []string, isExcluded map[string]bool) string {
+	candidates := preferred
+	if len(candidates) == 0 {
+		for p := range KnownProviderRegions {
+			candidates = append(candidates, p)
+		}
+	}
+
+	var allowed []string
+	for _, p := range candidates {
+		if !isExcluded[p] {
+			allowed = append(allowed, p)
+		}
+	}
+	if len(allowed) == 0 {
+		return "none"
+	}
+	sort.Strings(allowed)
+	return strings.Join(allowed, ", ")
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckRegionProviders(t *testing.T) {
+	tests := []struct {
+		name      string
+		regions   []string
+		preferred []string
+		excluded  []string
+		wantErr   string
+	}{
+		{name: "no constraints", regions: []string{"eastus"}},
+		{name: "one region satisfiable", regions: []string{"eastus", "us-east-1"}, excluded: []string{"azure"}},
+		{name: "preferred provider", regions: []string{"europe-west1"}, preferred: []string{"gcp", "aws"}},
+		{name: "unknown region", regions: []string{"westeurope", "ap-south-1"}, excluded: []string{"azure"}},
+		{
+			name:     "excluded provider",
+			regions:  []string{"eastus", "westeurope"},
+			excluded: []string{"azure"},
+			wantErr:  "no region can be placed with an allowed provider (aws, gcp): eastus belongs to azure, which is excluded; westeurope belongs to azure, which is excluded",
+		},
+		{
+			name:      "not preferred",
+			regions:   []string{"eastus"},
+			preferred: []string{"aws"},
+			wantErr:   "no region can be placed with an allowed provider (aws): eastus belongs to azure, which is not a preferred provider",
+		},
+		{
+			name:      "every preferred provider excluded",
+			regions:   []string{"us-east-1"},
+			preferred: []string{"aws"},
+			excluded:  []string{"aws"},
+			wantErr:   "allowed provider (none)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRegionProviders(tt.regions, tt.preferred, tt.excluded)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckRegionProviders = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckRegionProviders = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
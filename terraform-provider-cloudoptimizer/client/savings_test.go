@@ -0,0 +1,54 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateComputePlacementSavings(t *testing.T) {
+	tests := []struct {
+		name         string
+		current      *float64
+		response     string
+		wantSavings  *float64
+		wantIncrease bool
+	}{
+		{name: "positive savings", current: floatPtr(100), response: `,"current_monthly_cost":100,"estimated_savings":30,"savings_percent":30`, wantSavings: floatPtr(30)},
+		{name: "negative savings", current: floatPtr(50), response: `,"current_monthly_cost":50,"estimated_savings":-20,"savings_percent":-40,"cost_increase":true`, wantSavings: floatPtr(-20), wantIncrease: true},
+		{name: "no current cost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent map[string]interface{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+					t.Error(err)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":70` + tt.response + `}`))
+			}))
+			defer srv.Close()
+
+			result, err := NewClient(srv.URL, "key").CreateComputePlacement(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, CurrentMonthlyCost: tt.current})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := sent["current_monthly_cost"]; ok != (tt.current != nil) {
+				t.Errorf("request body %v, want current_monthly_cost only when set", sent)
+			}
+			if (result.EstimatedSavings == nil) != (tt.wantSavings == nil) || (result.EstimatedSavings != nil && *result.EstimatedSavings != *tt.wantSavings) {
+				t.Errorf("estimated savings %v, want %v", result.EstimatedSavings, tt.wantSavings)
+			}
+			if result.CostIncrease != tt.wantIncrease {
+				t.Errorf("cost increase %v, want %v", result.CostIncrease, tt.wantIncrease)
+			}
+		})
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
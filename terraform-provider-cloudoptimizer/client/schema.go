@@ -0,0 +1,57 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the response schema version the client understands. It is
+// sent with every request so the server can tell which fields the client
+// relies on. Fields added in later versions are ignored when decoding.
+const SchemaVersion = "1"
+
+// schemaVersionHeader carries SchemaVersion on requests
+const schemaVersionHeader = "X-Schema-Version"
+
+// SchemaError is returned when a response lacks a field the client depends
+// on, meaning the server speaks a schema incompatible with SchemaVersion
+type SchemaError struct {
+	// Response names the decoded response, e.g. "placement"
+	Response string
+	Field    string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("incompatible server: %s response is missing required field %q (client schema version %s)",
+		e.Response, e.Field, SchemaVersion)
+}
+
+// IsIncompatibleSchema reports whether err is a response missing a field the
+// client depends on
+func IsIncompatibleSchema(err error) bool {
+	var schemaErr *SchemaError
+	return errors.As(err, &schemaErr)
+}
+
+// decodeResponse decodes the JSON object data into v, ignoring unknown fields.
+// Each required top-level field must be present and not null; otherwise a
+// SchemaError naming the response and the first missing field is returned.
+func decodeResponse(data []byte, response string, v interface{}, required ...string) error {
+	if len(required) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("failed to decode response: %v", err)
+		}
+		for _, name := range required {
+			if raw, ok := fields[name]; !ok || string(raw) == "null" {
+				return &SchemaError{Response: response, Field: name}
+			}
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nil
+}
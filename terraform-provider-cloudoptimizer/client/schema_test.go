@@ -0,0 +1,74 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	var v struct {
+		ID string `json:"id"`
+	}
+	tests := []struct {
+		name      string
+		data      string
+		wantField string
+		wantErr   bool
+	}{
+		{name: "complete", data: `{"id":"plc-1","region":"us-east-1"}`},
+		{name: "unknown field", data: `{"id":"plc-1","region":"us-east-1","added_in_v2":{"x":1}}`},
+		{name: "missing", data: `{"id":"plc-1"}`, wantField: "region"},
+		{name: "null", data: `{"id":"plc-1","region":null}`, wantField: "region"},
+		{name: "not an object", data: `[]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeResponse([]byte(tt.data), "placement", &v, "id", "region")
+			switch {
+			case tt.wantField != "":
+				var schemaErr *SchemaError
+				if !errors.As(err, &schemaErr) || schemaErr.Field != tt.wantField || schemaErr.Response != "placement" {
+					t.Errorf("error %v, want a schema error for %s", err, tt.wantField)
+				}
+			case tt.wantErr:
+				if err == nil || IsIncompatibleSchema(err) {
+					t.Errorf("error %v, want a decoding error", err)
+				}
+			case err != nil:
+				t.Errorf("error %v, want nil", err)
+			}
+		})
+	}
+}
+
+// Placements are read with the client's schema version; unknown fields are
+// ignored and a missing required field names the incompatibility
+func TestGetPlacementSchema(t *testing.T) {
+	body := `{"id":"plc-1","selected_provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":70,"carbon_intensity":{"grams_per_kwh":390}}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(schemaVersionHeader); got != SchemaVersion {
+			t.Errorf("schema version %q, want %q", got, SchemaVersion)
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key")
+
+	p, err := c.GetComputePlacement("plc-1")
+	if err != nil {
+		t.Fatalf("response with an unknown field rejected: %v", err)
+	}
+	if p.ID != "plc-1" || p.SelectedProvider != "aws" || p.EstimatedMonthlyCost != 70 {
+		t.Errorf("placement %+v", p)
+	}
+
+	body = `{"id":"plc-1","provider":"aws","selected_region":"us-east-1","estimated_monthly_cost":70}`
+	_, err = c.GetComputePlacement("plc-1")
+	if !IsIncompatibleSchema(err) || !strings.Contains(err.Error(), `missing required field "selected_provider"`) {
+		t.Errorf("error %v, want the missing selected_provider reported", err)
+	}
+}
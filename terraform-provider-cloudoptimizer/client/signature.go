@@ -0,0 +1,86 @@
+package client
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+)
+
+// signatureField is the field of a placement result holding its signature
+const signatureField = "signature"
+
+// WithSignatureVerification makes the client verify every placement result it
+// receives against the server's Ed25519 public key. Results whose signature is
+// missing or does not match fail with ErrSignatureInvalid.
+func WithSignatureVerification(key ed25519.PublicKey) Option {
+	return func(c *Client) {
+		c.verifyKey = key
+	}
+}
+
+// ParsePublicKey parses a PEM-encoded PKIX Ed25519 public key, as written by
+// "openssl pkey -pubout"
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %v", err)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an Ed25519 key")
+	}
+	return key, nil
+}
+
+// VerifyPlacementSignature checks the signature of a placement result as
+// returned by the API. The signature covers the result's canonical
+// serialization: the JSON object without its signature field, with keys
+// sorted, no insignificant whitespace, numbers as they appear in data and
+// <, > and & left unescaped.
+func VerifyPlacementSignature(data []byte, key ed25519.PublicKey) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	encoded, ok := obj[signatureField].(string)
+	if !ok {
+		return fmt.Errorf("%w: result is not signed", ErrSignatureInvalid)
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64", ErrSignatureInvalid)
+	}
+	delete(obj, signatureField)
+
+	canonical, err := canonicalJSON(obj)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, canonical, sig) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// canonicalJSON returns the canonical serialization of a decoded JSON object,
+// as the server's signing package produces it
+func canonicalJSON(obj map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(obj); err != nil {
+		return nil, fmt.Errorf("failed to encode canonical form: %v", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// signPlacement signs a placement result as the server does
+func signPlacement(t *testing.T, key ed25519.PrivateKey, result string) string {
+	t.Helper()
+	dec := json.NewDecoder(strings.NewReader(result))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := canonicalJSON(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj[signatureField] = base64.StdEncoding.EncodeToString(ed25519.Sign(key, canonical))
+	signed, err := canonicalJSON(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(signed)
+}
+
+const unsignedPlacement = `{"id":"plc-1","resource_type":"compute","requirements":{"name":"R&D <eu>"},"selected_provider":"aws","selected_region":"eu-west-1","estimated_monthly_cost":120.50}`
+
+func TestVerifyPlacementSignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPublic, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signPlacement(t, private, unsignedPlacement)
+
+	tests := []struct {
+		name    string
+		data    string
+		key     ed25519.PublicKey
+		wantErr bool
+	}{
+		{name: "valid", data: signed, key: public},
+		{name: "reordered and spaced", data: strings.Replace(signed, `{"estimated_monthly_cost":120.50,`, `{ "estimated_monthly_cost" : 120.50 , `, 1), key: public},
+		{name: "tampered", data: strings.Replace(signed, `"eu-west-1"`, `"us-east-1"`, 1), key: public, wantErr: true},
+		{name: "number rewritten", data: strings.Replace(signed, `120.50`, `120.5`, 1), key: public, wantErr: true},
+		{name: "other key", data: signed, key: otherPublic, wantErr: true},
+		{name: "unsigned", data: unsignedPlacement, key: public, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.data == signed && tt.name != "valid" && tt.name != "other key" {
+				t.Fatal("replacement did not change the signed result")
+			}
+			err := VerifyPlacementSignature([]byte(tt.data), tt.key)
+			if tt.wantErr != errors.Is(err, ErrSignatureInvalid) {
+				t.Errorf("VerifyPlacementSignature = %v, want invalid %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("VerifyPlacementSignature = %v", err)
+			}
+		})
+	}
+}
+
+// Without WithSignatureVerification results are accepted whether signed or not
+func TestClientSignatureVerification(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := signPlacement(t, private, unsignedPlacement)
+	tampered := strings.Replace(signed, `"eu-west-1"`, `"us-east-1"`, 1)
+
+	tests := []struct {
+		name    string
+		body    string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "verified", body: signed, opts: []Option{WithSignatureVerification(public)}},
+		{name: "tampered", body: tampered, opts: []Option{WithSignatureVerification(public)}, wantErr: true},
+		{name: "unsigned", body: unsignedPlacement, opts: []Option{WithSignatureVerification(public)}, wantErr: true},
+		{name: "opted out, tampered", body: tampered},
+		{name: "opted out, unsigned", body: unsignedPlacement},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			result, err := NewClient(srv.URL, "key", tt.opts...).GetComputePlacement("plc-1")
+			if tt.wantErr {
+				if !errors.Is(err, ErrSignatureInvalid) {
+					t.Errorf("GetComputePlacement = %v, want ErrSignatureInvalid", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.ID != "plc-1" {
+				t.Errorf("result %+v", result)
+			}
+		})
+	}
+}
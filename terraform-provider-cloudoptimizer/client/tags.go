@@ -0,0 +1,61 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits on the tags of a placement, matching those enforced by the API
+const (
+	MaxTags           = 50
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+// reservedTagPrefixes may not start a tag key
+var reservedTagPrefixes = []string{"aws:", "azure:", "goog", "cloudoptimizer:"}
+
+// tagChars are the punctuation characters allowed in tag keys and values
+const tagChars = "_.:/=+-@"
+
+// ValidateTags checks tags before they are sent so invalid tags fail at plan
+// time rather than when the placement is created
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxTags {
+		return fmt.Errorf("at most %d tags are allowed, got %d", MaxTags, len(tags))
+	}
+
+	for key, value := range tags {
+		if key == "" || len(key) > MaxTagKeyLength {
+			return fmt.Errorf("tag key %q must be 1 to %d characters", key, MaxTagKeyLength)
+		}
+		if len(value) > MaxTagValueLength {
+			return fmt.Errorf("value of tag %q must be at most %d characters", key, MaxTagValueLength)
+		}
+		lower := strings.ToLower(key)
+		for _, prefix := range reservedTagPrefixes {
+			if strings.HasPrefix(lower, prefix) {
+				return fmt.Errorf("tag key %q uses the reserved prefix %q", key, prefix)
+			}
+		}
+		if !validTagString(key) {
+			return fmt.Errorf("tag key %q contains characters other than letters, digits, spaces and %s", key, tagChars)
+		}
+		if !validTagString(value) {
+			return fmt.Errorf("value of tag %q contains characters other than letters, digits, spaces and %s", key, tagChars)
+		}
+	}
+	return nil
+}
+
+func validTagString(s string) bool {
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == ' ':
+		case strings.ContainsRune(tagChars, r):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string, MaxTags+1)
+	for i := 0; i <= MaxTags; i++ {
+		tooMany[fmt.Sprintf("tag-%d", i)] = "x"
+	}
+
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "valid", tags: map[string]string{"cost-center": "cc-1", "team": "data eng", "owner": "ops@example.com"}},
+		{name: "empty value", tags: map[string]string{"env": ""}},
+		{name: "too many", tags: tooMany, wantErr: "at most 50 tags"},
+		{name: "empty key", tags: map[string]string{"": "x"}, wantErr: "must be 1 to 128 characters"},
+		{name: "long key", tags: map[string]string{strings.Repeat("k", MaxTagKeyLength+1): "x"}, wantErr: "must be 1 to 128 characters"},
+		{name: "long value", tags: map[string]string{"env": strings.Repeat("v", MaxTagValueLength+1)}, wantErr: "at most 256 characters"},
+		{name: "reserved prefix", tags: map[string]string{"AWS:createdBy": "x"}, wantErr: "reserved prefix"},
+		{name: "invalid key character", tags: map[string]string{"env!": "x"}, wantErr: `tag key "env!" contains`},
+		{name: "invalid value character", tags: map[string]string{"env": "prod;drop"}, wantErr: `value of tag "env" contains`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTags(tt.tags)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateTags() = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateTags() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Tags are sent with the requirements and echoed back on the result
+func TestTagsRoundTrip(t *testing.T) {
+	var gotTags map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ComputeRequirements
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		gotTags = req.Tags
+		data, _ := json.Marshal(PlacementResult{ID: "plc-1", SelectedProvider: "aws", SelectedRegion: "us-east-1", Tags: req.Tags})
+		w.WriteHeader(http.StatusCreated)
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	tags := map[string]string{"cost-center": "cc-1", "team": "data"}
+	result, err := NewClient(srv.URL, "key").CreateComputePlacement(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, Tags: tags})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotTags, tags) || !reflect.DeepEqual(result.Tags, tags) {
+		t.Errorf("sent tags %v and got back %v, want %v", gotTags, result.Tags, tags)
+	}
+
+	data, err := json.Marshal(&ComputeRequirements{Name: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"tags"`) {
+		t.Errorf("requirements without tags marshal as %s, want tags omitted", data)
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Tie-break keys, compared in order between recommendations with equal total
+// scores. They match the server's placements.tie_break keys.
+const (
+	TieBreakCost         = "cost"
+	TieBreakProvider     = "provider"
+	TieBreakRegion       = "region"
+	TieBreakInstanceType = "instance_type"
+)
+
+// DefaultTieBreak orders tied recommendations by cost, then provider, then
+// region, as the server does by default
+var DefaultTieBreak = []string{TieBreakCost, TieBreakProvider, TieBreakRegion}
+
+var allTieBreakKeys = []string{TieBreakCost, TieBreakProvider, TieBreakRegion, TieBreakInstanceType}
+
+// WithTieBreak orders the recommendations of placement results with equal
+// total scores by the given keys instead of DefaultTieBreak. Keys left out
+// are compared last, so the order is always complete.
+func WithTieBreak(order []string) Option {
+	return func(c *Client) {
+		c.tieBreak = order
+	}
+}
+
+// ValidateTieBreak checks that a tie-break order names known keys at most once
+func ValidateTieBreak(order []string) error {
+	seen := make(map[string]bool, len(order))
+	for _, key := range order {
+		switch key {
+		case TieBreakCost, TieBreakProvider, TieBreakRegion, TieBreakInstanceType:
+		default:
+			return fmt.Errorf("invalid tie-break key %q (must be cost, provider, region or instance_type)", key)
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate tie-break key %q", key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// SortAlternatives orders alternatives by total score, highest first, and
+// alternatives with equal scores by the tie-break keys. Keys missing from
+// order are compared after it in their default order, so identical inputs
+// always sort identically.
+func SortAlternatives(alternatives []Alternative, order []string) {
+	keys := completeTieBreak(order)
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		a, b := alternatives[i], alternatives[j]
+		if a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
+		}
+		for _, key := range keys {
+			switch key {
+			case TieBreakCost:
+				if a.MonthlyCost != b.MonthlyCost {
+					return a.MonthlyCost < b.MonthlyCost
+				}
+			case TieBreakProvider:
+				if a.Provider != b.Provider {
+					return a.Provider < b.Provider
+				}
+			case TieBreakRegion:
+				if a.Region != b.Region {
+					return a.Region < b.Region
+				}
+			case TieBreakInstanceType:
+				if a.InstanceType != b.InstanceType {
+					return a.InstanceType < b.InstanceType
+				}
+			}
+		}
+		return false
+	})
+}
+
+// completeTieBreak appends the keys order leaves out in their default order
+func completeTieBreak(order []string) []string {
+	if len(order) == 0 {
+		order = DefaultTieBreak
+	}
+
+	keys := make([]string, 0, len(allTieBreakKeys))
+	seen := make(map[string]bool, len(allTieBreakKeys))
+	for _, key := range order {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range allTieBreakKeys {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,24 @@
+package client
+
+// TransferVolume is a monthly data transfer volume in GB by destination class
+type TransferVolume struct {
+	IntraRegionGB float64 `json:"intra_region_gb,omitempty"`
+	InterRegionGB float64 `json:"inter_region_gb,omitempty"`
+	InternetGB    float64 `json:"internet_gb,omitempty"`
+}
+
+// DataTransfer is the expected monthly data transfer of a placement by
+// direction
+type DataTransfer struct {
+	Outbound TransferVolume `json:"outbound"`
+	Inbound  TransferVolume `json:"inbound"`
+}
+
+// TransferCost is the monthly data transfer cost of a placement: outbound
+// traffic by destination class and inbound traffic in total
+type TransferCost struct {
+	IntraRegion float64 `json:"intra_region"`
+	InterRegion float64 `json:"inter_region"`
+	Internet    float64 `json:"internet"`
+	Inbound     float64 `json:"inbound"`
+}
@@ -0,0 +1,24 @@
+package client
+
+import "fmt"
+
+// ZoneRequirements pins a placement to availability zones of its selected
+// region. Required zones are always selected and must all be in one region;
+// preferred zones are selected before the region's others. Spread is the
+// number of zones to select, defaulting to the number of required zones or 1.
+type ZoneRequirements struct {
+	Required  []string `json:"required,omitempty"`
+	Preferred []string `json:"preferred,omitempty"`
+	Spread    int      `json:"spread,omitempty"`
+}
+
+// Validate checks that the spread leaves room for every required zone
+func (z *ZoneRequirements) Validate() error {
+	if z.Spread < 0 {
+		return fmt.Errorf("spread must not be negative")
+	}
+	if z.Spread > 0 && z.Spread < len(z.Required) {
+		return fmt.Errorf("spread is %d but %d zones are required", z.Spread, len(z.Required))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestZoneRequirementsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		zones   ZoneRequirements
+		wantErr bool
+	}{
+		{name: "required", zones: ZoneRequirements{Required: []string{"us-east-1a"}}},
+		{name: "spread", zones: ZoneRequirements{Required: []string{"us-east-1a"}, Preferred: []string{"us-east-1c"}, Spread: 2}},
+		{name: "negative spread", zones: ZoneRequirements{Spread: -1}, wantErr: true},
+		{name: "spread below required", zones: ZoneRequirements{Required: []string{"us-east-1a", "us-east-1b"}, Spread: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.zones.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateComputePlacementZones(t *testing.T) {
+	var got ComputeRequirements
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"plc-1","selected_provider":"aws","selected_region":"eu-west-1","estimated_monthly_cost":80,"selected_zones":["eu-west-1b","eu-west-1a"]}`))
+	}))
+	defer srv.Close()
+	c := NewClient(srv.URL, "key")
+
+	zones := &ZoneRequirements{Required: []string{"eu-west-1b"}, Spread: 2}
+	result, err := c.CreateComputePlacement(&ComputeRequirements{Name: "web", VCPUs: 2, MemoryGB: 8, AvailabilityZones: zones})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.AvailabilityZones, zones) {
+		t.Errorf("sent availability zones %+v, want %+v", got.AvailabilityZones, zones)
+	}
+	if want := []string{"eu-west-1b", "eu-west-1a"}; !reflect.DeepEqual(result.SelectedZones, want) {
+		t.Errorf("selected zones %v, want %v", result.SelectedZones, want)
+	}
+}
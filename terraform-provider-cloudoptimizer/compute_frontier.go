@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-cloudoptimizer/client"
+)
+
+func dataSourceComputeFrontier() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceComputeFrontierRead,
+
+		Schema: map[string]*schema.Schema{
+			"vcpus": {
+				Type:        schema.TypeInt,
+				Required:    true,
+				Description: "Number of virtual CPUs required",
+			},
+			"memory_gb": {
+				Type:        schema.TypeFloat,
+				Required:    true,
+				Description: "Amount of memory required in GB",
+			},
+			"regions": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "List of acceptable regions; every region when unset",
+			},
+			"min_availability": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Description: "Minimum availability percentage required",
+			},
+			"max_monthly_budget": {
+				Type:        schema.TypeFloat,
+				Optional:    true,
+				Description: "Maximum monthly budget in USD",
+			},
+			"excluded_providers": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Cloud providers to exclude",
+			},
+			"excluded_instance_types": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Instance types, by exact name, to exclude",
+			},
+			"compliance_frameworks": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Compliance frameworks the options are scored against",
+			},
+			"options": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Options no other option beats on both monthly cost and performance, cheapest first",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"provider": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"region": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"instance_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"monthly_cost": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"performance_score": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"total_score": {
+							Type:     schema.TypeFloat,
+							Computed: true,
+						},
+						"best_on": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+							Description: "Dimensions (cost, performance) on which no option beats this one",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceComputeFrontierRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*client.Client)
+
+	req := &client.ComputeRequirements{
+		VCPUs:    d.Get("vcpus").(int),
+		MemoryGB: d.Get("memory_gb").(float64),
+	}
+
+	if v, ok := d.GetOk("regions"); ok {
+		req.Regions = expandStringSet(v.(*schema.Set))
+	}
+
+	if v, ok := d.GetOk("min_availability"); ok {
+		req.MinAvailability = v.(float64)
+	}
+
+	if v, ok := d.GetOk("max_monthly_budget"); ok {
+		budget := v.(float64)
+		req.MaxMonthlyBudget = &budget
+	}
+
+	if v, ok := d.GetOk("excluded_providers"); ok {
+		req.ExcludedProviders = expandStringSet(v.(*schema.Set))
+	}
+
+	if v, ok := d.GetOk("excluded_instance_types"); ok {
+		req.ExcludedInstanceTypes = expandStringSet(v.(*schema.Set))
+	}
+
+	if v, ok := d.GetOk("compliance_frameworks"); ok {
+		req.ComplianceFrameworks = expandStringSet(v.(*schema.Set))
+	}
+
+	frontier, err := c.GetComputeFrontier(req)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("error reading compute frontier: %v", err))
+	}
+
+	options := make([]interface{}, len(frontier))
+	for i, o := range frontier {
+		options[i] = map[string]interface{}{
+			"provider":          o.Provider,
+			"region":            o.Region,
+			"instance_type":     o.InstanceType,
+			"monthly_cost":      o.MonthlyCost,
+			"performance_score": o.PerformanceScore,
+			"total_score":       o.TotalScore,
+			"best_on":           o.BestOn,
+		}
+	}
+	if err := d.Set("options", options); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The ID identifies the requirements, so it only changes with them
+	body, err := json.Marshal(req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sum := sha256.Sum256(body)
+	d.SetId(hex.EncodeToString(sum[:]))
+
+	return nil
+}
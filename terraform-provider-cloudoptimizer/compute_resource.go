@@ -3,9 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
@@ -27,6 +28,10 @@ func resourceComputePlacementCreate(ctx context.Context, d *schema.ResourceData,
 		req.MinAvailability = v.(float64)
 	}
 
+	if v, ok := d.GetOk("sla_tier"); ok {
+		req.SLATier = v.(string)
+	}
+
 	if v, ok := d.GetOk("max_monthly_budget"); ok {
 		budget := v.(float64)
 		req.MaxMonthlyBudget = &budget
@@ -40,6 +45,10 @@ func resourceComputePlacementCreate(ctx context.Context, d *schema.ResourceData,
 		req.ExcludedProviders = expandStringSet(v.(*schema.Set))
 	}
 
+	if v, ok := d.GetOk("excluded_instance_types"); ok {
+		req.ExcludedInstanceTypes = expandStringSet(v.(*schema.Set))
+	}
+
 	if v, ok := d.GetOk("required_features"); ok {
 		req.RequiredFeatures = expandStringSet(v.(*schema.Set))
 	}
@@ -48,6 +57,70 @@ func resourceComputePlacementCreate(ctx context.Context, d *schema.ResourceData,
 		req.ComplianceFrameworks = expandStringSet(v.(*schema.Set))
 	}
 
+	if v, ok := d.GetOk("data_residency"); ok {
+		req.DataResidency = expandStringSet(v.(*schema.Set))
+	}
+
+	if v, ok := d.GetOk("multi_region"); ok {
+		multiRegion, err := expandMultiRegion(v.([]interface{}), req.Regions)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.MultiRegion = multiRegion
+	}
+
+	if v, ok := d.GetOk("tags"); ok {
+		tags, err := expandTags(v.(map[string]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.Tags = tags
+	}
+
+	if v, ok := d.GetOk("metadata"); ok {
+		metadata, err := expandMetadata(v.(map[string]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.Metadata = metadata
+	}
+
+	if v, ok := d.GetOk("affinity"); ok {
+		req.Affinity = expandAffinity(v.([]interface{}))
+	}
+
+	if v, ok := d.GetOk("data_transfer"); ok {
+		req.DataTransfer = expandDataTransfer(v.([]interface{}))
+	}
+
+	if v, ok := d.GetOk("availability_zones"); ok {
+		zones, err := expandAvailabilityZones(v.([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.AvailabilityZones = zones
+	}
+
+	if v, ok := d.GetOk("resource_group"); ok {
+		req.ResourceGroup = v.(string)
+	}
+
+	if v, ok := d.GetOk("current_monthly_cost"); ok {
+		cost := v.(float64)
+		req.CurrentMonthlyCost = &cost
+	}
+
+	c.ComputeDefaults().Apply(req, configuredAttributes(d))
+
+	// A pre_create hook rejecting the requirements aborts the create
+	if err := c.RunHook(ctx, client.HookEvent{
+		Event:        client.HookPreCreate,
+		ResourceType: computePlacementResourceType,
+		Requirements: req,
+	}); err != nil {
+		return diag.FromErr(fmt.Errorf("compute placement create aborted: %v", err))
+	}
+
 	// Create placement
 	result, err := c.CreateComputePlacement(req)
 	if err != nil {
@@ -60,7 +133,8 @@ func resourceComputePlacementCreate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	return nil
+	diags := append(stalePricesWarning(result), costIncreaseWarning(result)...)
+	return append(diags, postCreateHook(ctx, c, req, result)...)
 }
 
 func resourceComputePlacementRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
@@ -95,6 +169,10 @@ func resourceComputePlacementUpdate(ctx context.Context, d *schema.ResourceData,
 		req.MinAvailability = v.(float64)
 	}
 
+	if v, ok := d.GetOk("sla_tier"); ok {
+		req.SLATier = v.(string)
+	}
+
 	if v, ok := d.GetOk("max_monthly_budget"); ok {
 		budget := v.(float64)
 		req.MaxMonthlyBudget = &budget
@@ -108,6 +186,10 @@ func resourceComputePlacementUpdate(ctx context.Context, d *schema.ResourceData,
 		req.ExcludedProviders = expandStringSet(v.(*schema.Set))
 	}
 
+	if v, ok := d.GetOk("excluded_instance_types"); ok {
+		req.ExcludedInstanceTypes = expandStringSet(v.(*schema.Set))
+	}
+
 	if v, ok := d.GetOk("required_features"); ok {
 		req.RequiredFeatures = expandStringSet(v.(*schema.Set))
 	}
@@ -116,6 +198,61 @@ func resourceComputePlacementUpdate(ctx context.Context, d *schema.ResourceData,
 		req.ComplianceFrameworks = expandStringSet(v.(*schema.Set))
 	}
 
+	if v, ok := d.GetOk("data_residency"); ok {
+		req.DataResidency = expandStringSet(v.(*schema.Set))
+	}
+
+	if v, ok := d.GetOk("multi_region"); ok {
+		multiRegion, err := expandMultiRegion(v.([]interface{}), req.Regions)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.MultiRegion = multiRegion
+	}
+
+	if v, ok := d.GetOk("tags"); ok {
+		tags, err := expandTags(v.(map[string]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.Tags = tags
+	}
+
+	if v, ok := d.GetOk("metadata"); ok {
+		metadata, err := expandMetadata(v.(map[string]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.Metadata = metadata
+	}
+
+	if v, ok := d.GetOk("affinity"); ok {
+		req.Affinity = expandAffinity(v.([]interface{}))
+	}
+
+	if v, ok := d.GetOk("data_transfer"); ok {
+		req.DataTransfer = expandDataTransfer(v.([]interface{}))
+	}
+
+	if v, ok := d.GetOk("availability_zones"); ok {
+		zones, err := expandAvailabilityZones(v.([]interface{}))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		req.AvailabilityZones = zones
+	}
+
+	if v, ok := d.GetOk("resource_group"); ok {
+		req.ResourceGroup = v.(string)
+	}
+
+	if v, ok := d.GetOk("current_monthly_cost"); ok {
+		cost := v.(float64)
+		req.CurrentMonthlyCost = &cost
+	}
+
+	c.ComputeDefaults().Apply(req, configuredAttributes(d))
+
 	// Update placement
 	result, err := c.UpdateComputePlacement(d.Id(), req)
 	if err != nil {
@@ -127,14 +264,35 @@ func resourceComputePlacementUpdate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	return nil
+	return append(stalePricesWarning(result), costIncreaseWarning(result)...)
 }
 
 func resourceComputePlacementDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	c := m.(*client.Client)
 
-	// Delete placement
-	if err := c.DeleteComputePlacement(d.Id()); err != nil {
+	// A pre_delete hook rejecting the deletion aborts it, keeping the
+	// placement in state
+	if err := c.RunHook(ctx, client.HookEvent{
+		Event:        client.HookPreDelete,
+		ResourceType: computePlacementResourceType,
+		PlacementID:  d.Id(),
+	}); err != nil {
+		return diag.FromErr(fmt.Errorf("compute placement delete aborted: %v", err))
+	}
+
+	// Retry transient failures until the delete timeout; a placement that is
+	// already gone counts as deleted
+	err := retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *retry.RetryError {
+		err := c.DeleteComputePlacement(d.Id())
+		switch {
+		case err == nil || client.IsNotFound(err):
+			return nil
+		case client.IsTransient(err):
+			return retry.RetryableError(err)
+		}
+		return retry.NonRetryableError(err)
+	})
+	if err != nil {
 		return diag.FromErr(fmt.Errorf("error deleting compute placement: %v", err))
 	}
 
@@ -150,6 +308,14 @@ func setComputePlacementValues(d *schema.ResourceData, result *client.PlacementR
 		return fmt.Errorf("error setting selected_region: %v", err)
 	}
 
+	if err := d.Set("selected_zones", result.SelectedZones); err != nil {
+		return fmt.Errorf("error setting selected_zones: %v", err)
+	}
+
+	if err := d.Set("selected_jurisdiction", result.SelectedJurisdiction); err != nil {
+		return fmt.Errorf("error setting selected_jurisdiction: %v", err)
+	}
+
 	if err := d.Set("instance_type", result.InstanceType); err != nil {
 		return fmt.Errorf("error setting instance_type: %v", err)
 	}
@@ -158,6 +324,14 @@ func setComputePlacementValues(d *schema.ResourceData, result *client.PlacementR
 		return fmt.Errorf("error setting estimated_monthly_cost: %v", err)
 	}
 
+	if err := result.ValidateCostBreakdown(); err != nil {
+		return err
+	}
+
+	if err := d.Set("cost_breakdown", result.CostBreakdown); err != nil {
+		return fmt.Errorf("error setting cost_breakdown: %v", err)
+	}
+
 	if err := d.Set("performance_score", result.PerformanceScore); err != nil {
 		return fmt.Errorf("error setting performance_score: %v", err)
 	}
@@ -170,6 +344,10 @@ func setComputePlacementValues(d *schema.ResourceData, result *client.PlacementR
 		return fmt.Errorf("error setting total_score: %v", err)
 	}
 
+	if err := d.Set("selection_reason", result.SelectionReason); err != nil {
+		return fmt.Errorf("error setting selection_reason: %v", err)
+	}
+
 	recommendations := make([]interface{}, len(result.Recommendations))
 	for i, rec := range result.Recommendations {
 		recommendations[i] = map[string]interface{}{
@@ -180,6 +358,9 @@ func setComputePlacementValues(d *schema.ResourceData, result *client.PlacementR
 			"performance_score":  rec.PerformanceScore,
 			"compliance_score":   rec.ComplianceScore,
 			"total_score":       rec.TotalScore,
+			"rejection_reason":  rec.RejectionReason,
+			"zones":             rec.Zones,
+			"jurisdiction":      rec.Jurisdiction,
 		}
 	}
 
@@ -187,6 +368,149 @@ func setComputePlacementValues(d *schema.ResourceData, result *client.PlacementR
 		return fmt.Errorf("error setting recommendations: %v", err)
 	}
 
+	allocations := make([]interface{}, len(result.RegionAllocations))
+	for i, a := range result.RegionAllocations {
+		allocations[i] = map[string]interface{}{
+			"provider":      a.Provider,
+			"region":        a.Region,
+			"instance_type": a.InstanceType,
+			"weight":        a.Weight,
+			"monthly_cost":  a.MonthlyCost,
+			"zones":         a.Zones,
+		}
+	}
+
+	if err := d.Set("region_allocations", allocations); err != nil {
+		return fmt.Errorf("error setting region_allocations: %v", err)
+	}
+
+	if err := d.Set("aggregate_monthly_cost", result.AggregateMonthlyCost); err != nil {
+		return fmt.Errorf("error setting aggregate_monthly_cost: %v", err)
+	}
+
+	if err := d.Set("achieved_sla_tier", result.AchievedSLATier); err != nil {
+		return fmt.Errorf("error setting achieved_sla_tier: %v", err)
+	}
+
+	priceListDate := ""
+	if result.PriceListDate != nil {
+		priceListDate = result.PriceListDate.Format(time.RFC3339)
+	}
+	if err := d.Set("price_list_date", priceListDate); err != nil {
+		return fmt.Errorf("error setting price_list_date: %v", err)
+	}
+
+	if err := d.Set("prices_stale", result.PricesStale); err != nil {
+		return fmt.Errorf("error setting prices_stale: %v", err)
+	}
+
+	if err := d.Set("custom_pricing", result.CustomPricing); err != nil {
+		return fmt.Errorf("error setting custom_pricing: %v", err)
+	}
+
+	if err := d.Set("tags", result.Tags); err != nil {
+		return fmt.Errorf("error setting tags: %v", err)
+	}
+
+	if err := d.Set("metadata", result.Metadata); err != nil {
+		return fmt.Errorf("error setting metadata: %v", err)
+	}
+
+	if err := d.Set("resource_group", result.ResourceGroup); err != nil {
+		return fmt.Errorf("error setting resource_group: %v", err)
+	}
+
+	if err := setPlacementSavings(d, result); err != nil {
+		return err
+	}
+
+	links := make([]interface{}, len(result.Affinity))
+	for i, l := range result.Affinity {
+		links[i] = map[string]interface{}{
+			"placement_id":        l.Ref,
+			"provider":            l.Provider,
+			"region":              l.Region,
+			"latency_ms":          l.LatencyMs,
+			"egress_monthly_cost": l.EgressMonthlyCost,
+		}
+	}
+
+	if err := d.Set("affinity_links", links); err != nil {
+		return fmt.Errorf("error setting affinity_links: %v", err)
+	}
+
+	var transferCost []interface{}
+	if tc := result.DataTransferCost; tc != nil {
+		transferCost = []interface{}{map[string]interface{}{
+			"intra_region": tc.IntraRegion,
+			"inter_region": tc.InterRegion,
+			"internet":     tc.Internet,
+			"inbound":      tc.Inbound,
+		}}
+	}
+
+	if err := d.Set("data_transfer_cost", transferCost); err != nil {
+		return fmt.Errorf("error setting data_transfer_cost: %v", err)
+	}
+
+	return nil
+}
+
+// stalePricesWarning warns when the placement's cost estimate was made from
+// prices the server considers outdated
+func stalePricesWarning(result *client.PlacementResult) diag.Diagnostics {
+	if !result.PricesStale {
+		return nil
+	}
+
+	detail := "The estimated monthly cost may be outdated."
+	if result.PriceListDate != nil {
+		detail = fmt.Sprintf("The estimated monthly cost uses prices last updated %s and may be outdated.", result.PriceListDate.Format("2006-01-02"))
+	}
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Stale price list",
+		Detail:   detail,
+	}}
+}
+
+// costIncreaseWarning warns when the placement costs more than the
+// workload's current monthly cost
+func costIncreaseWarning(result *client.PlacementResult) diag.Diagnostics {
+	if !result.CostIncrease || result.EstimatedSavings == nil {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Placement costs more than the current deployment",
+		Detail:   fmt.Sprintf("The placement is estimated to cost %.2f USD per month more than current_monthly_cost.", -*result.EstimatedSavings),
+	}}
+}
+
+// setPlacementSavings sets the savings against current_monthly_cost, which
+// are unset when the requirements don't give a current cost
+func setPlacementSavings(d *schema.ResourceData, result *client.PlacementResult) error {
+	var savings, percent interface{}
+	if result.EstimatedSavings != nil {
+		savings = *result.EstimatedSavings
+	}
+	if result.SavingsPercent != nil {
+		percent = *result.SavingsPercent
+	}
+
+	if err := d.Set("estimated_savings", savings); err != nil {
+		return fmt.Errorf("error setting estimated_savings: %v", err)
+	}
+
+	if err := d.Set("savings_percent", percent); err != nil {
+		return fmt.Errorf("error setting savings_percent: %v", err)
+	}
+
+	if err := d.Set("cost_increase", result.CostIncrease); err != nil {
+		return fmt.Errorf("error setting cost_increase: %v", err)
+	}
+
 	return nil
 }
 
@@ -202,6 +526,162 @@ func expandStringSet(set *schema.Set) []string {
 	return slice
 }
 
+func expandStringList(l []interface{}) []string {
+	slice := make([]string, len(l))
+	for i, v := range l {
+		slice[i] = v.(string)
+	}
+	return slice
+}
+
+// expandMultiRegion builds multi-region requirements from the multi_region
+// block and validates them against the allowed regions
+func expandMultiRegion(l []interface{}, regions []string) (*client.MultiRegionRequirements, error) {
+	if len(l) == 0 || l[0] == nil {
+		return nil, nil
+	}
+
+	raw := l[0].(map[string]interface{})
+	multiRegion := &client.MultiRegionRequirements{
+		MinRegions: raw["min_regions"].(int),
+	}
+
+	if weights, ok := raw["region_weights"].(map[string]interface{}); ok && len(weights) > 0 {
+		multiRegion.RegionWeights = make(map[string]float64, len(weights))
+		for region, weight := range weights {
+			multiRegion.RegionWeights[region] = weight.(float64)
+		}
+	}
+
+	if err := multiRegion.Validate(regions); err != nil {
+		return nil, fmt.Errorf("invalid multi_region: %v", err)
+	}
+	return multiRegion, nil
+}
+
+// expandAvailabilityZones builds zone requirements from the
+// availability_zones block and validates them
+func expandAvailabilityZones(l []interface{}) (*client.ZoneRequirements, error) {
+	if len(l) == 0 || l[0] == nil {
+		return nil, nil
+	}
+
+	raw := l[0].(map[string]interface{})
+	zones := &client.ZoneRequirements{
+		Required:  expandStringList(raw["required"].([]interface{})),
+		Preferred: expandStringList(raw["preferred"].([]interface{})),
+		Spread:    raw["spread"].(int),
+	}
+
+	if err := zones.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid availability_zones: %v", err)
+	}
+	return zones, nil
+}
+
+// expandDataTransfer builds the expected data transfer from the
+// data_transfer block
+func expandDataTransfer(l []interface{}) *client.DataTransfer {
+	if len(l) == 0 || l[0] == nil {
+		return nil
+	}
+
+	raw := l[0].(map[string]interface{})
+	return &client.DataTransfer{
+		Outbound: client.TransferVolume{
+			IntraRegionGB: raw["outbound_intra_region_gb"].(float64),
+			InterRegionGB: raw["outbound_inter_region_gb"].(float64),
+			InternetGB:    raw["outbound_internet_gb"].(float64),
+		},
+		Inbound: client.TransferVolume{
+			IntraRegionGB: raw["inbound_intra_region_gb"].(float64),
+			InterRegionGB: raw["inbound_inter_region_gb"].(float64),
+			InternetGB:    raw["inbound_internet_gb"].(float64),
+		},
+	}
+}
+
+// expandAffinity builds the affinity to related placements from the affinity
+// blocks
+func expandAffinity(l []interface{}) []client.Affinity {
+	affinity := make([]client.Affinity, 0, len(l))
+	for _, v := range l {
+		if v == nil {
+			continue
+		}
+		raw := v.(map[string]interface{})
+		affinity = append(affinity, client.Affinity{
+			PlacementID:      raw["placement_id"].(string),
+			MonthlyTrafficGB: raw["monthly_traffic_gb"].(float64),
+		})
+	}
+	return affinity
+}
+
+// expandTags builds cost-allocation tags from the tags map and validates them
+// before they are sent
+func expandTags(m map[string]interface{}) (map[string]string, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	tags := make(map[string]string, len(m))
+	for k, v := range m {
+		tags[k] = v.(string)
+	}
+
+	if err := client.ValidateTags(tags); err != nil {
+		return nil, fmt.Errorf("invalid tags: %v", err)
+	}
+	return tags, nil
+}
+
+// expandMetadata builds metadata from the metadata map and validates it
+// before it is sent
+func expandMetadata(m map[string]interface{}) (map[string]string, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string, len(m))
+	for k, v := range m {
+		metadata[k] = v.(string)
+	}
+
+	if err := client.ValidateMetadata(metadata); err != nil {
+		return nil, fmt.Errorf("invalid metadata: %v", err)
+	}
+	return metadata, nil
+}
+
+// validateMetadata checks the metadata map against the size limits
+func validateMetadata() schema.SchemaValidateFunc {
+	return func(v interface{}, k string) ([]string, []error) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		if _, err := expandMetadata(m); err != nil {
+			return nil, []error{fmt.Errorf("%s: %v", k, err)}
+		}
+		return nil, nil
+	}
+}
+
+// validateTags checks the tags map against the provider tag limits
+func validateTags() schema.SchemaValidateFunc {
+	return func(v interface{}, k string) ([]string, []error) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		if _, err := expandTags(m); err != nil {
+			return nil, []error{fmt.Errorf("%s: %v", k, err)}
+		}
+		return nil, nil
+	}
+}
+
 func validatePositiveFloat() schema.SchemaValidateFunc {
 	return validation.FloatAtLeast(0.0)
 }
@@ -210,6 +690,66 @@ func validatePositiveInt() schema.SchemaValidateFunc {
 	return validation.IntAtLeast(1)
 }
 
+// validateComplianceFramework rejects framework IDs outside the known catalog
+func validateComplianceFramework() schema.SchemaValidateFunc {
+	return func(v interface{}, k string) ([]string, []error) {
+		if err := client.CheckComplianceFramework(v.(string)); err != nil {
+			return nil, []error{fmt.Errorf("%s: %v", k, err)}
+		}
+		return nil, nil
+	}
+}
+
+// validateRedactPattern rejects malformed redaction glob patterns
+func validateRedactPattern() schema.SchemaValidateFunc {
+	return func(v interface{}, k string) ([]string, []error) {
+		if err := client.ValidateRedactPattern(v.(string)); err != nil {
+			return nil, []error{fmt.Errorf("%s: %v", k, err)}
+		}
+		return nil, nil
+	}
+}
+
+// customizeDiffComplianceFrameworks validates compliance_frameworks against
+// the server's catalog, which may be newer than the built-in list. The check
+// is skipped if the catalog cannot be fetched, leaving the schema validation.
+func customizeDiffComplianceFrameworks(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	set, ok := d.Get("compliance_frameworks").(*schema.Set)
+	if !ok || set.Len() == 0 {
+		return nil
+	}
+
+	c := m.(*client.Client)
+	if _, err := c.ListComplianceFrameworks(); err != nil {
+		return nil
+	}
+	return c.ValidateComplianceFrameworks(expandStringSet(set))
+}
+
+// customizeDiffRegionProviders rejects regions that all belong to excluded
+// or non-preferred providers, which the API could never place. The check
+// waits until the regions and provider lists are known.
+func customizeDiffRegionProviders(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	for _, key := range []string{"regions", "preferred_providers", "excluded_providers"} {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	var preferred, excluded []string
+	if set, ok := d.Get("preferred_providers").(*schema.Set); ok {
+		preferred = expandStringSet(set)
+	}
+	if set, ok := d.Get("excluded_providers").(*schema.Set); ok {
+		excluded = expandStringSet(set)
+	}
+	return client.CheckRegionProviders(expandStringSet(d.Get("regions").(*schema.Set)), preferred, excluded)
+}
+
 func validateAvailability() schema.SchemaValidateFunc {
 	return validation.FloatBetween(0.0, 100.0)
 }
+
+func validateSLATier() schema.SchemaValidateFunc {
+	return validation.StringInSlice([]string{"bronze", "silver", "gold", "platinum"}, false)
+}
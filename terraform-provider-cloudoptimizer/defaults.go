@@ -0,0 +1,127 @@
+package main
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-cloudoptimizer/client"
+)
+
+// defaultsSchema describes the provider's default requirements, one block
+// per resource type
+func defaultsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"compute": {
+					Type:     schema.TypeList,
+					Optional: true,
+					MaxItems: 1,
+					Elem: &schema.Resource{
+						Schema: computeDefaultsSchema(),
+					},
+					Description: "Requirements applied to every cloudoptimizer_compute_placement that doesn't set them",
+				},
+			},
+		},
+		Description: "Default requirements per resource type. Each field applies to resources that don't set it; fields set on a resource take precedence. Changed defaults take effect when a resource is next created or updated.",
+	}
+}
+
+// computeDefaultsSchema describes the compute requirements that can be
+// defaulted in the provider block
+func computeDefaultsSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"regions": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Description: "Default list of acceptable regions",
+		},
+		"min_availability": {
+			Type:         schema.TypeFloat,
+			Optional:     true,
+			ValidateFunc: validateAvailability(),
+			Description:  "Default minimum availability percentage",
+		},
+		"sla_tier": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validateSLATier(),
+			Description:  "Default SLA tier (bronze, silver, gold or platinum)",
+		},
+		"max_monthly_budget": {
+			Type:        schema.TypeFloat,
+			Optional:    true,
+			Description: "Default maximum monthly budget in USD",
+		},
+		"excluded_providers": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+			Description: "Default list of excluded cloud providers",
+		},
+		"compliance_frameworks": {
+			Type:     schema.TypeSet,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateComplianceFramework(),
+			},
+			Description: "Default list of required compliance frameworks",
+		},
+	}
+}
+
+// expandComputeDefaults builds the compute defaults from the provider
+// configuration, or returns nil when none are set
+func expandComputeDefaults(d *schema.ResourceData) *client.ComputeDefaults {
+	const prefix = "defaults.0.compute.0."
+	if _, ok := d.GetOk("defaults.0.compute"); !ok {
+		return nil
+	}
+
+	defaults := &client.ComputeDefaults{}
+	if v, ok := d.GetOk(prefix + "regions"); ok {
+		defaults.Regions = expandStringSet(v.(*schema.Set))
+	}
+	if v, ok := d.GetOk(prefix + "min_availability"); ok {
+		availability := v.(float64)
+		defaults.MinAvailability = &availability
+	}
+	if v, ok := d.GetOk(prefix + "sla_tier"); ok {
+		defaults.SLATier = v.(string)
+	}
+	if v, ok := d.GetOk(prefix + "max_monthly_budget"); ok {
+		budget := v.(float64)
+		defaults.MaxMonthlyBudget = &budget
+	}
+	if v, ok := d.GetOk(prefix + "excluded_providers"); ok {
+		defaults.ExcludedProviders = expandStringSet(v.(*schema.Set))
+	}
+	if v, ok := d.GetOk(prefix + "compliance_frameworks"); ok {
+		defaults.ComplianceFrameworks = expandStringSet(v.(*schema.Set))
+	}
+	return defaults
+}
+
+// configuredAttributes reports whether the resource's configuration sets an
+// attribute, so defaults don't replace explicit values, including those
+// equal to a schema default. Without a raw configuration it falls back to
+// whether the attribute has a non-zero value.
+func configuredAttributes(d *schema.ResourceData) func(key string) bool {
+	config := d.GetRawConfig()
+	return func(key string) bool {
+		if config.IsNull() || !config.IsKnown() || !config.Type().IsObjectType() || !config.Type().HasAttribute(key) {
+			_, ok := d.GetOk(key)
+			return ok
+		}
+		return !config.GetAttr(key).IsNull()
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"terraform-provider-cloudoptimizer/client"
+)
+
+// computePlacementResourceType is the resource type named in hook events
+const computePlacementResourceType = "cloudoptimizer_compute_placement"
+
+// lifecycleHooksSchema describes the webhook URLs invoked by resources on
+// lifecycle events
+func lifecycleHooksSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				client.HookPreCreate: {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+					Description:  "URL posted the requirements before a placement is created; a non-2xx response aborts the create",
+				},
+				client.HookPostCreate: {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+					Description:  "URL posted the requirements and placement after a placement is created; a failure is reported as a warning",
+				},
+				client.HookPreDelete: {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+					Description:  "URL posted the placement ID before a placement is deleted; a non-2xx response aborts the delete",
+				},
+			},
+		},
+		Description: "Webhook URLs invoked with a JSON event on placement lifecycle events. The API key is not sent to them.",
+	}
+}
+
+// expandLifecycleHooks builds the lifecycle hooks from the provider
+// configuration, or returns nil when none are set
+func expandLifecycleHooks(d *schema.ResourceData) *client.LifecycleHooks {
+	const prefix = "lifecycle_hooks.0."
+	if _, ok := d.GetOk("lifecycle_hooks"); !ok {
+		return nil
+	}
+
+	return &client.LifecycleHooks{
+		PreCreate:  d.Get(prefix + client.HookPreCreate).(string),
+		PostCreate: d.Get(prefix + client.HookPostCreate).(string),
+		PreDelete:  d.Get(prefix + client.HookPreDelete).(string),
+	}
+}
+
+// postCreateHook runs the post_create hook once a placement is created. The
+// placement already exists, so a failing hook is a warning rather than an
+// error.
+func postCreateHook(ctx context.Context, c *client.Client, req *client.ComputeRequirements, result *client.PlacementResult) diag.Diagnostics {
+	err := c.RunHook(ctx, client.HookEvent{
+		Event:        client.HookPostCreate,
+		ResourceType: computePlacementResourceType,
+		PlacementID:  result.ID,
+		Requirements: req,
+		Placement:    result,
+	})
+	if err == nil {
+		return nil
+	}
+
+	return diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "post_create hook failed",
+		Detail:   fmt.Sprintf("The placement %s was created, but %v", result.ID, err),
+	}}
+}
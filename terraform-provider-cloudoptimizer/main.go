@@ -1,10 +1,20 @@
 package main
 
 import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+
+	"terraform-provider-cloudoptimizer/client"
+	"terraform-provider-cloudoptimizer/state"
 )
 
+var stateManager = state.NewStateManager()
+
 func main() {
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: Provider,
@@ -29,7 +39,45 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("CLOUDOPTIMIZER_API_KEY", nil),
 				Description: "API key for authentication",
 			},
+			"api_version": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CLOUDOPTIMIZER_API_VERSION", ""),
+				Description: "API version to pin requests to (e.g., v2); a warning is emitted if the server does not support it",
+			},
+			"soft_delete": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Soft-delete placements on destroy so they can be restored within the server's retention period",
+			},
+			"redact_fields": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateRedactPattern(),
+				},
+				Description: "Field-name glob patterns (e.g. *token*) whose values are masked in debug logs of API requests and responses; defaults to common credential, token and email fields",
+			},
+			"tie_break": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Order of keys (cost, provider, region, instance_type) used to order recommendations with equal total scores; defaults to cost, provider, region. Set it to match the service's placements.tie_break",
+			},
+			"signing_public_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("CLOUDOPTIMIZER_SIGNING_PUBLIC_KEY", ""),
+				Description: "PEM-encoded Ed25519 public key of the service; when set, placement results must carry a valid signature from the matching private key",
+			},
+			"defaults": defaultsSchema(),
+			"lifecycle_hooks": lifecycleHooksSchema(),
 		},
+		ConfigureContextFunc: providerConfigure,
 		ResourcesMap: map[string]*schema.Resource{
 			"cloudoptimizer_compute_placement":  resourceComputePlacement(),
 			"cloudoptimizer_storage_placement":  resourceStoragePlacement(),
@@ -44,16 +92,78 @@ func Provider() *schema.Provider {
 			"cloudoptimizer_cost_analysis":          dataSourceCostAnalysis(),
 			"cloudoptimizer_performance_analysis":    dataSourcePerformanceAnalysis(),
 			"cloudoptimizer_compliance_analysis":     dataSourceComplianceAnalysis(),
+			"cloudoptimizer_compute_frontier":        dataSourceComputeFrontier(),
 		},
 	}
 }
 
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var opts []client.Option
+	if v, ok := d.GetOk("api_version"); ok {
+		opts = append(opts, client.WithAPIVersion(v.(string)))
+	}
+	opts = append(opts, client.WithSoftDelete(d.Get("soft_delete").(bool)))
+	if v, ok := d.GetOk("redact_fields"); ok {
+		opts = append(opts, client.WithRedactedFields(expandStringSet(v.(*schema.Set))))
+	}
+	if v, ok := d.GetOk("tie_break"); ok {
+		order := expandStringList(v.([]interface{}))
+		if err := client.ValidateTieBreak(order); err != nil {
+			return nil, diag.Errorf("invalid tie_break: %v", err)
+		}
+		opts = append(opts, client.WithTieBreak(order))
+	}
+	if v, ok := d.GetOk("signing_public_key"); ok {
+		key, err := client.ParsePublicKey([]byte(v.(string)))
+		if err != nil {
+			return nil, diag.Errorf("invalid signing_public_key: %v", err)
+		}
+		opts = append(opts, client.WithSignatureVerification(key))
+	}
+	if defaults := expandComputeDefaults(d); defaults != nil {
+		opts = append(opts, client.WithComputeDefaults(defaults))
+	}
+	if hooks := expandLifecycleHooks(d); hooks != nil {
+		opts = append(opts, client.WithLifecycleHooks(hooks))
+	}
+
+	c := client.NewClient(d.Get("api_endpoint").(string), d.Get("api_key").(string), opts...)
+
+	// Warn rather than fail so a transient outage doesn't block planning
+	warning, err := c.CheckServerVersion()
+	if err != nil {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Unable to verify API version",
+			Detail:   err.Error(),
+		})
+	} else if warning != "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Incompatible API version",
+			Detail:   warning,
+		})
+	}
+
+	return c, diags
+}
+
 func resourceComputePlacement() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceComputePlacementCreate,
-		Read:   resourceComputePlacementRead,
-		Update: resourceComputePlacementUpdate,
-		Delete: resourceComputePlacementDelete,
+		CreateContext: resourceComputePlacementCreate,
+		ReadContext:   resourceComputePlacementRead,
+		UpdateContext: resourceComputePlacementUpdate,
+		DeleteContext: resourceComputePlacementDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: stateManager.ImporterFor("compute"),
+		},
+		CustomizeDiff: customdiff.All(customizeDiffComplianceFrameworks, customizeDiffRegionProviders),
+		Timeouts: &schema.ResourceTimeout{
+			// Deletes retry transient API errors for up to this long
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -73,11 +183,11 @@ func resourceComputePlacement() *schema.Resource {
 			},
 			"regions": {
 				Type:     schema.TypeSet,
-				Required: true,
+				Optional: true,
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
-				Description: "List of acceptable regions",
+				Description: "List of acceptable regions; defaults to the provider's defaults, or any region",
 			},
 			"min_availability": {
 				Type:        schema.TypeFloat,
@@ -85,6 +195,12 @@ func resourceComputePlacement() *schema.Resource {
 				Default:     99.9,
 				Description: "Minimum availability percentage required",
 			},
+			"sla_tier": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateSLATier(),
+				Description:  "Named SLA tier (bronze, silver, gold or platinum) mapping to availability and redundancy requirements; when min_availability or multi_region is also set, the stricter requirement applies",
+			},
 			"max_monthly_budget": {
 				Type:        schema.TypeFloat,
 				Optional:    true,
@@ -106,6 +222,14 @@ func resourceComputePlacement() *schema.Resource {
 				},
 				Description: "List of excluded cloud providers",
 			},
+			"excluded_instance_types": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Instance types, by exact name, never selected for the placement",
+			},
 			"required_features": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -118,10 +242,34 @@ func resourceComputePlacement() *schema.Resource {
 				Type:     schema.TypeSet,
 				Optional: true,
 				Elem: &schema.Schema{
-					Type: schema.TypeString,
+					Type:         schema.TypeString,
+					ValidateFunc: validateComplianceFramework(),
 				},
 				Description: "List of required compliance frameworks",
 			},
+			"multi_region":       multiRegionSchema(),
+			"tags":               tagsSchema(),
+			"metadata":           metadataSchema(),
+			"affinity":           affinitySchema(),
+			"data_transfer":      dataTransferSchema(),
+			"availability_zones": availabilityZonesSchema(),
+			"data_residency": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Jurisdictions the placement's data may reside in, as ISO 3166-1 alpha-2 country codes (e.g. DE) or EU; only regions within them are selected",
+			},
+			"resource_group": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Logical group or project the placement belongs to, for grouped cost and placement views; it does not affect placement",
+			},
+			"current_monthly_cost": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				ValidateFunc: validatePositiveFloat(),
+				Description:  "What the workload costs today in USD per month, which estimated_savings and savings_percent are computed against; it does not affect placement",
+			},
 			// Computed values returned by the provider
 			"selected_provider": {
 				Type:        schema.TypeString,
@@ -133,6 +281,19 @@ func resourceComputePlacement() *schema.Resource {
 				Computed:    true,
 				Description: "Selected region",
 			},
+			"selected_zones": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Availability zones selected in the region when availability_zones is set",
+			},
+			"selected_jurisdiction": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Country code of the selected region's jurisdiction when data_residency is set",
+			},
 			"instance_type": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -143,6 +304,14 @@ func resourceComputePlacement() *schema.Resource {
 				Computed:    true,
 				Description: "Estimated monthly cost in USD",
 			},
+			"cost_breakdown": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeFloat,
+				},
+				Description: "Estimated monthly cost in USD by component (e.g., compute, storage, egress, license)",
+			},
 			"performance_score": {
 				Type:        schema.TypeFloat,
 				Computed:    true,
@@ -191,11 +360,349 @@ func resourceComputePlacement() *schema.Resource {
 							Type:     schema.TypeFloat,
 							Computed: true,
 						},
+						"rejection_reason": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Why the alternative ranked below the selected option",
+						},
+						"zones": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Availability zones the alternative would use in its region",
+						},
+						"jurisdiction": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Jurisdiction of the alternative's region when data_residency is set",
+						},
 					},
 				},
 				Description: "Alternative recommendations",
 			},
+			"selection_reason": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Why the selected provider and region were chosen over the alternatives",
+			},
+			"region_allocations":     regionAllocationsSchema(),
+			"aggregate_monthly_cost": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "Combined estimated monthly cost in USD of every region of a multi-region placement",
+			},
+			"achieved_sla_tier": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Strictest SLA tier the placement meets",
+			},
+			"price_list_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the prices the cost estimate used were last updated (RFC 3339)",
+			},
+			"prices_stale": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the prices the cost estimate used were older than the service's staleness threshold",
+			},
+			"estimated_savings": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "current_monthly_cost less the estimated monthly cost (the aggregate cost for multi-region placements) in USD; negative when the placement costs more",
+			},
+			"savings_percent": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: "estimated_savings as a percentage of current_monthly_cost; unset when it is 0",
+			},
+			"cost_increase": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the placement costs more than current_monthly_cost",
+			},
+			"custom_pricing": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether costs were estimated with the tenant's negotiated pricing instead of list prices",
+			},
+			"affinity_links": affinityLinksSchema(),
+			"data_transfer_cost": dataTransferCostSchema(),
+		},
+	}
+}
+
+// multiRegionSchema describes an active-active distribution across regions
+func multiRegionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"region_weights": {
+					Type:     schema.TypeMap,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeFloat,
+					},
+					Description: "Share of traffic routed to each region, keyed by region; weights are normalized and every region must be listed in regions",
+				},
+				"min_regions": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      2,
+					ValidateFunc: validatePositiveInt(),
+					Description:  "Minimum number of regions to deploy to",
+				},
+			},
+		},
+		Description: "Distribute the placement across multiple regions for high availability",
+	}
+}
+
+// tagsSchema describes the cost-allocation tags applied to the provisioned resource
+func tagsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+		ValidateFunc: validateTags(),
+		Description:  "Cost-allocation tags applied to the provisioned resource",
+	}
+}
+
+// metadataSchema describes opaque metadata stored with a placement
+func metadataSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeMap,
+		Optional: true,
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+		ValidateFunc: validateMetadata(),
+		Description:  "Opaque metadata, such as ticket IDs or owners, stored with the placement; it does not affect placement",
+	}
+}
+
+// affinitySchema describes the related placements a compute placement should
+// be close to
+func affinitySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"placement_id": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "ID of the related compute placement",
+				},
+				"monthly_traffic_gb": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validatePositiveFloat(),
+					Description:  "Monthly traffic in GB exchanged with the related placement, priced as egress when the two are in different regions",
+				},
+			},
+		},
+		Description: "Related placements, such as a database, to favor placing this one close to",
+	}
+}
+
+// affinityLinksSchema describes the latency and egress cost to each related placement
+func affinityLinksSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"placement_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"provider": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"region": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"latency_ms": {
+					Type:     schema.TypeFloat,
+					Computed: true,
+				},
+				"egress_monthly_cost": {
+					Type:     schema.TypeFloat,
+					Computed: true,
+				},
+			},
+		},
+		Description: "Estimated latency and monthly egress cost in USD to each related placement",
+	}
+}
+
+// dataTransferSchema describes the expected monthly data transfer by
+// direction and destination class
+func dataTransferSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"outbound_intra_region_gb": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validatePositiveFloat(),
+					Description:  "Monthly GB sent to resources in the same region",
+				},
+				"outbound_inter_region_gb": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validatePositiveFloat(),
+					Description:  "Monthly GB sent to other regions",
+				},
+				"outbound_internet_gb": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validatePositiveFloat(),
+					Description:  "Monthly GB sent to the internet",
+				},
+				"inbound_intra_region_gb": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validatePositiveFloat(),
+					Description:  "Monthly GB received from resources in the same region",
+				},
+				"inbound_inter_region_gb": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validatePositiveFloat(),
+					Description:  "Monthly GB received from other regions",
+				},
+				"inbound_internet_gb": {
+					Type:         schema.TypeFloat,
+					Optional:     true,
+					ValidateFunc: validatePositiveFloat(),
+					Description:  "Monthly GB received from the internet",
+				},
+			},
+		},
+		Description: "Expected monthly data transfer, priced into the estimated monthly cost at the provider's rates",
+	}
+}
+
+// availabilityZonesSchema pins a placement to availability zones of its
+// selected region
+func availabilityZonesSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"required": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+					Description: "Zones always selected; they must all be in one region, to which the placement is confined",
+				},
+				"preferred": {
+					Type:     schema.TypeList,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+					Description: "Zones selected before the region's other zones; zones outside the selected region are ignored",
+				},
+				"spread": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					ValidateFunc: validatePositiveInt(),
+					Description:  "Number of distinct zones to spread the placement across; defaults to the number of required zones, or 1",
+				},
+			},
+		},
+		Description: "Availability zones of the selected region to place the resource in, named as in the provider capabilities (e.g. us-east-1a)",
+	}
+}
+
+// dataTransferCostSchema describes the monthly data transfer cost of a placement
+func dataTransferCostSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"intra_region": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "Outbound cost to the same region",
+				},
+				"inter_region": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "Outbound cost to other regions",
+				},
+				"internet": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "Outbound cost to the internet",
+				},
+				"inbound": {
+					Type:        schema.TypeFloat,
+					Computed:    true,
+					Description: "Inbound cost",
+				},
+			},
+		},
+		Description: "Monthly data transfer cost in USD included in the estimated monthly cost",
+	}
+}
+
+// regionAllocationsSchema describes the per-region allocation of a multi-region placement
+func regionAllocationsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"provider": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"region": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"instance_type": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"weight": {
+					Type:     schema.TypeFloat,
+					Computed: true,
+				},
+				"monthly_cost": {
+					Type:     schema.TypeFloat,
+					Computed: true,
+				},
+				"zones": {
+					Type:     schema.TypeList,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+			},
 		},
+		Description: "Per-region allocation of a multi-region placement, highest weight first",
 	}
 }
 
@@ -227,6 +734,7 @@ func resourceStoragePlacement() *schema.Resource {
 				Optional:    true,
 				Description: "Required throughput in MB/s",
 			},
+			"tags": tagsSchema(),
 			// Add common fields (regions, availability, budget, etc.)
 			// Add computed fields (selected provider, costs, scores, etc.)
 		},
@@ -257,6 +765,8 @@ func resourceNetworkPlacement() *schema.Resource {
 				Default:     false,
 				Description: "Whether cross-region connectivity is required",
 			},
+			"tags":          tagsSchema(),
+			"data_transfer": dataTransferSchema(),
 			// Add common fields (regions, availability, budget, etc.)
 			// Add computed fields (selected provider, costs, scores, etc.)
 		},
@@ -286,6 +796,8 @@ func resourceDatabasePlacement() *schema.Resource {
 				Required:    true,
 				Description: "Database engine version",
 			},
+			"multi_region": multiRegionSchema(),
+			"tags":         tagsSchema(),
 			// Add common fields (regions, availability, budget, etc.)
 			// Add computed fields (selected provider, costs, scores, etc.)
 		},
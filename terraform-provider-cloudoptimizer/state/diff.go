@@ -0,0 +1,128 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-cloudoptimizer/client"
+)
+
+// importDiffFields are the attributes compared between the resource data and
+// the remote placement on import, in reporting order
+var importDiffFields = []string{"regions", "max_monthly_budget", "selected_provider", "selected_region"}
+
+// FieldDiff is a single attribute that differs between config and remote state.
+// A nil value means the attribute is not set on that side.
+type FieldDiff struct {
+	Field  string      `json:"field"`
+	Config interface{} `json:"config"`
+	Remote interface{} `json:"remote"`
+}
+
+// ImportDiff lists the key attributes that differ between the configuration
+// and the remote placement of an imported resource
+type ImportDiff struct {
+	ResourceType string      `json:"resource_type"`
+	ID           string      `json:"id"`
+	Fields       []FieldDiff `json:"fields"`
+}
+
+// DiffAttributes compares the key import attributes of config and remote and
+// returns those that differ. Values are normalized so that, for example,
+// region order does not produce a difference.
+func DiffAttributes(config, remote map[string]interface{}) []FieldDiff {
+	var diffs []FieldDiff
+	for _, field := range importDiffFields {
+		c, r := normalizeAttribute(config[field]), normalizeAttribute(remote[field])
+		if !reflect.DeepEqual(c, r) {
+			diffs = append(diffs, FieldDiff{Field: field, Config: c, Remote: r})
+		}
+	}
+	return diffs
+}
+
+// configAttributes returns the key import attributes present in the resource data
+func configAttributes(d *schema.ResourceData) map[string]interface{} {
+	attrs := make(map[string]interface{})
+	for _, field := range importDiffFields {
+		if v, ok := d.GetOk(field); ok {
+			attrs[field] = v
+		}
+	}
+	return attrs
+}
+
+// remoteAttributes returns the key import attributes of the remote placement
+func remoteAttributes(result *client.PlacementResult) map[string]interface{} {
+	attrs := map[string]interface{}{
+		"selected_provider": result.SelectedProvider,
+		"selected_region":   result.SelectedRegion,
+	}
+	for _, field := range []string{"regions", "max_monthly_budget"} {
+		if v, ok := result.Requirements[field]; ok {
+			attrs[field] = v
+		}
+	}
+	return attrs
+}
+
+// logImportDiff logs the differences between config and remote state of an
+// imported resource so operators can reconcile their configuration
+func logImportDiff(diff *ImportDiff) {
+	if len(diff.Fields) == 0 {
+		log.Printf("[INFO] Imported %s placement %s matches its configuration", diff.ResourceType, diff.ID)
+		return
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		log.Printf("[WARN] Unable to encode import diff for %s placement %s: %v", diff.ResourceType, diff.ID, err)
+		return
+	}
+	log.Printf("[WARN] Imported %s placement %s differs from its configuration: %s", diff.ResourceType, diff.ID, data)
+}
+
+// normalizeAttribute converts attribute values from resource data and JSON
+// into comparable forms: string collections become sorted []string, numbers
+// float64, and empty values nil
+func normalizeAttribute(v interface{}) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case *schema.Set:
+		return normalizeAttribute(value.List())
+	case []string:
+		if len(value) == 0 {
+			return nil
+		}
+		sorted := append([]string(nil), value...)
+		sort.Strings(sorted)
+		return sorted
+	case []interface{}:
+		strs := make([]string, len(value))
+		for i, item := range value {
+			strs[i] = fmt.Sprint(item)
+		}
+		return normalizeAttribute(strs)
+	case string:
+		if value == "" {
+			return nil
+		}
+		return value
+	case int:
+		return float64(value)
+	case float64:
+		return value
+	case *float64:
+		if value == nil {
+			return nil
+		}
+		return *value
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-cloudoptimizer/client"
+)
+
+// importSchema is the subset of the placement resource schema compared on import
+var importSchema = map[string]*schema.Schema{
+	"regions":            {Type: schema.TypeSet, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	"max_monthly_budget": {Type: schema.TypeFloat, Optional: true},
+	"selected_provider":  {Type: schema.TypeString, Computed: true},
+	"selected_region":    {Type: schema.TypeString, Computed: true},
+}
+
+func TestDiffAttributes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		remote string
+		want   []string
+	}{
+		{
+			name:   "matching",
+			config: map[string]interface{}{"regions": []interface{}{"us-east-1", "eu-west-1"}, "max_monthly_budget": 500},
+			remote: `{"selected_provider":"aws","selected_region":"eu-west-1","requirements":{"regions":["eu-west-1","us-east-1"],"max_monthly_budget":500}}`,
+			want:   []string{"selected_provider", "selected_region"},
+		},
+		{
+			name:   "differing budget and regions",
+			config: map[string]interface{}{"regions": []interface{}{"us-east-1"}, "max_monthly_budget": 500},
+			remote: `{"selected_provider":"aws","selected_region":"eu-west-1","requirements":{"regions":["eu-west-1"],"max_monthly_budget":300}}`,
+			want:   []string{"regions", "max_monthly_budget", "selected_provider", "selected_region"},
+		},
+		{
+			name:   "unset in config",
+			config: map[string]interface{}{},
+			remote: `{"selected_provider":"gcp","selected_region":"europe-west3","requirements":{"max_monthly_budget":300}}`,
+			want:   []string{"max_monthly_budget", "selected_provider", "selected_region"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result client.PlacementResult
+			if err := json.Unmarshal([]byte(tt.remote), &result); err != nil {
+				t.Fatal(err)
+			}
+			d := schema.TestResourceDataRaw(t, importSchema, tt.config)
+
+			var got []string
+			for _, diff := range DiffAttributes(configAttributes(d), remoteAttributes(&result)) {
+				got = append(got, diff.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("differing fields %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffAttributesValues(t *testing.T) {
+	diffs := DiffAttributes(
+		map[string]interface{}{"max_monthly_budget": 500, "selected_provider": "aws"},
+		map[string]interface{}{"max_monthly_budget": 300.0, "selected_provider": "aws"},
+	)
+	want := []FieldDiff{{Field: "max_monthly_budget", Config: 500.0, Remote: 300.0}}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Errorf("DiffAttributes = %+v, want %+v", diffs, want)
+	}
+}
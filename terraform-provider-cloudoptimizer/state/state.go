@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"terraform-provider-cloudoptimizer/client"
 )
 
 // StateManager handles state persistence and management for resources
@@ -114,14 +116,56 @@ func (sm *StateManager) DeleteResourceState(ctx context.Context, d *schema.Resou
 
 // ImportResourceState imports an existing resource's state
 func (sm *StateManager) ImportResourceState(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-	// This would typically make an API call to get the resource's current state
-	// For now, we'll just create a basic state entry
+	resourceType, _ := d.Get("__resource_type").(string)
+	return sm.importResourceState(ctx, d, meta, resourceType)
+}
+
+// ImporterFor returns an import function for resources of the given type. The
+// import ID is the provider's ID for an existing cloud resource, which is
+// adopted as a managed placement; the placement ID replaces the import ID.
+func (sm *StateManager) ImporterFor(resourceType string) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		return sm.importResourceState(ctx, d, meta, resourceType)
+	}
+}
+
+func (sm *StateManager) importResourceState(ctx context.Context, d *schema.ResourceData, meta interface{}, resourceType string) ([]*schema.ResourceData, error) {
+	if resourceType == "" {
+		return nil, fmt.Errorf("resource type not set in resource data")
+	}
+
+	c, ok := meta.(*client.Client)
+	if !ok {
+		return nil, fmt.Errorf("provider not configured")
+	}
+
+	result, err := c.AdoptPlacement(resourceType, d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("error adopting %s resource %s: %v", resourceType, d.Id(), err)
+	}
+
+	logImportDiff(&ImportDiff{
+		ResourceType: resourceType,
+		ID:           result.ID,
+		Fields:       DiffAttributes(configAttributes(d), remoteAttributes(result)),
+	})
+
+	d.SetId(result.ID)
+
 	state := &ResourceState{
-		ID:           d.Id(),
-		ResourceType: d.Get("__resource_type").(string),
-		Attributes:   make(map[string]interface{}),
-		LastUpdated:  time.Now().UTC(),
-		Version:      time.Now().UnixNano(),
+		ID:           result.ID,
+		ResourceType: resourceType,
+		Attributes: map[string]interface{}{
+			"selected_provider":      result.SelectedProvider,
+			"selected_region":        result.SelectedRegion,
+			"instance_type":          result.InstanceType,
+			"estimated_monthly_cost": result.EstimatedMonthlyCost,
+			"performance_score":      result.PerformanceScore,
+			"compliance_score":       result.ComplianceScore,
+			"total_score":            result.TotalScore,
+		},
+		LastUpdated: time.Now().UTC(),
+		Version:     time.Now().UnixNano(),
 	}
 
 	sm.mu.Lock()